@@ -0,0 +1,142 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ClientVersion is the version of this client library.
+const ClientVersion = "1.6.5"
+
+// clientFeatures lists the server features this client knows how to use,
+// sorted by name; see the Feature constants.
+var clientFeatures = []string{
+	FeatureBatchAny,
+	FeatureBatchIndex,
+	FeatureCDTList,
+	FeatureCDTMap,
+	FeatureFloat,
+	FeaturePeers,
+	FeaturePipelining,
+	FeatureReplicas,
+	FeatureTruncateNamespace,
+	FeatureUDF,
+}
+
+// AboutInfo describes the client build and the features it shares with
+// the connected cluster.
+type AboutInfo struct {
+	// ClientVersion is the version of the client library.
+	ClientVersion string
+
+	// GoVersion is the Go runtime version the client was compiled with.
+	GoVersion string
+
+	// BuildOptions holds compile-time and package-level settings.
+	BuildOptions map[string]string
+
+	// ClientFeatures lists the protocol features supported by the client.
+	ClientFeatures []string
+
+	// ServerFeatures maps each node name to the features reported by that node.
+	ServerFeatures map[string][]string
+
+	// ServerBuilds maps each node name to its server build version.
+	ServerBuilds map[string]string
+
+	// CommonFeatures lists the features supported by the client and all nodes.
+	CommonFeatures []string
+}
+
+// About returns the client version and build options, and the features
+// supported by both this client and every node in the cluster.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *Client) About(policy *InfoPolicy) (*AboutInfo, error) {
+	policy = clnt.getUsableInfoPolicy(policy)
+
+	res := &AboutInfo{
+		ClientVersion: ClientVersion,
+		GoVersion:     runtime.Version(),
+		BuildOptions: map[string]string{
+			"GOOS":          runtime.GOOS,
+			"GOARCH":        runtime.GOARCH,
+			"Compiler":      runtime.Compiler,
+			"MaxBufferSize": strconv.Itoa(MaxBufferSize),
+			"MaxOperations": strconv.Itoa(MaxOperations),
+		},
+		ClientFeatures: append([]string(nil), clientFeatures...),
+		ServerFeatures: map[string][]string{},
+		ServerBuilds:   map[string]string{},
+	}
+
+	nodeFeatures := [][]string{clientFeatures}
+	for _, node := range clnt.cluster.GetNodes() {
+		info, err := RequestNodeInfoWithPolicy(policy, node, "features", "build")
+		if err != nil {
+			return nil, err
+		}
+
+		features := parseFeatures(info["features"])
+		res.ServerFeatures[node.GetName()] = features
+		res.ServerBuilds[node.GetName()] = info["build"]
+		nodeFeatures = append(nodeFeatures, features)
+	}
+
+	res.CommonFeatures = intersectFeatures(nodeFeatures...)
+	return res, nil
+}
+
+// parseFeatures parses the `;` separated output of the `features` info command.
+func parseFeatures(v string) []string {
+	res := []string{}
+	for _, f := range strings.Split(v, ";") {
+		if f = strings.TrimSpace(f); f != "" {
+			res = append(res, f)
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+// intersectFeatures returns the sorted list of features present in all lists.
+func intersectFeatures(lists ...[]string) []string {
+	res := []string{}
+	if len(lists) == 0 {
+		return res
+	}
+
+	counts := make(map[string]int, len(lists[0]))
+	for _, list := range lists {
+		seen := make(map[string]struct{}, len(list))
+		for _, f := range list {
+			if _, exists := seen[f]; !exists {
+				seen[f] = struct{}{}
+				counts[f]++
+			}
+		}
+	}
+
+	for f, c := range counts {
+		if c == len(lists) {
+			res = append(res, f)
+		}
+	}
+	sort.Strings(res)
+	return res
+}
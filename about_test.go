@@ -0,0 +1,41 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("About Test", func() {
+
+	It("should parse the `features` info output", func() {
+		Expect(parseFeatures("udf;as_msg;replicas-master;")).To(Equal([]string{"as_msg", "replicas-master", "udf"}))
+		Expect(clientFeatures).To(Equal(parseFeatures(strings.Join(clientFeatures, ";"))))
+		Expect(parseFeatures("")).To(BeEmpty())
+	})
+
+	It("should intersect client and server features", func() {
+		res := intersectFeatures(
+			clientFeatures,
+			[]string{"udf", "as_msg", "float", "peers", "udf"},
+			[]string{"as_msg", "udf", "geo", "peers"},
+		)
+		Expect(res).To(Equal([]string{"peers", "udf"}))
+	})
+
+})
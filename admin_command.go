@@ -15,6 +15,7 @@
 package aerospike
 
 import (
+	"strings"
 	"time"
 
 	"github.com/THE108/aerospike-client-go/pkg/bcrypt"
@@ -24,25 +25,38 @@ import (
 
 const (
 	// Commands
-	_AUTHENTICATE    byte = 0
-	_CREATE_USER     byte = 1
-	_DROP_USER       byte = 2
-	_SET_PASSWORD    byte = 3
-	_CHANGE_PASSWORD byte = 4
-	_GRANT_ROLES     byte = 5
-	_REVOKE_ROLES    byte = 6
-	_REPLACE_ROLES   byte = 7
-	//_CREATE_ROLE byte = 8;
-	_QUERY_USERS byte = 9
-	//_QUERY_ROLES byte =  10;
+	_AUTHENTICATE      byte = 0
+	_CREATE_USER       byte = 1
+	_DROP_USER         byte = 2
+	_SET_PASSWORD      byte = 3
+	_CHANGE_PASSWORD   byte = 4
+	_GRANT_ROLES       byte = 5
+	_REVOKE_ROLES      byte = 6
+	_REPLACE_ROLES     byte = 7
+	_QUERY_USERS       byte = 9
+	_CREATE_ROLE       byte = 10
+	_DROP_ROLE         byte = 11
+	_GRANT_PRIVILEGES  byte = 12
+	_REVOKE_PRIVILEGES byte = 13
+	_SET_WHITELIST     byte = 14
+	_SET_QUOTAS        byte = 15
+	_QUERY_ROLES       byte = 16
+	_LOGIN             byte = 20
 
 	// Field IDs
-	_USER         byte = 0
-	_PASSWORD     byte = 1
-	_OLD_PASSWORD byte = 2
-	_CREDENTIAL   byte = 3
-	_ROLES        byte = 10
-	//_PRIVILEGES byte =  11;
+	_USER           byte = 0
+	_PASSWORD       byte = 1
+	_OLD_PASSWORD   byte = 2
+	_CREDENTIAL     byte = 3
+	_CLEAR_PASSWORD byte = 4
+	_SESSION_TOKEN  byte = 5
+	_SESSION_TTL    byte = 6
+	_ROLES          byte = 10
+	_ROLE           byte = 11
+	_PRIVILEGES     byte = 12
+	_WHITELIST      byte = 13
+	_READ_QUOTA     byte = 14
+	_WRITE_QUOTA    byte = 15
 
 	// Misc
 	_MSG_VERSION int64 = 0
@@ -96,6 +110,110 @@ func (acmd *AdminCommand) setAuthenticate(user string, password []byte) int {
 	return acmd.dataOffset
 }
 
+// authenticateSession authenticates the connection with a session token
+// received from a previous login. The user is empty for PKI authentication.
+func (acmd *AdminCommand) authenticateSession(conn *Connection, user string, token []byte) error {
+	defer bufPool.Put(acmd.dataBuffer)
+
+	if user != "" {
+		acmd.writeHeader(_AUTHENTICATE, 2)
+		acmd.writeFieldStr(_USER, user)
+	} else {
+		acmd.writeHeader(_AUTHENTICATE, 1)
+	}
+	acmd.writeFieldBytes(_SESSION_TOKEN, token)
+	acmd.writeSize()
+
+	if _, err := conn.Write(acmd.dataBuffer[:acmd.dataOffset]); err != nil {
+		return err
+	}
+
+	if _, err := conn.Read(acmd.dataBuffer, _HEADER_SIZE); err != nil {
+		return err
+	}
+
+	result := acmd.dataBuffer[_RESULT_CODE]
+	if result != 0 {
+		return NewAerospikeError(ResultCode(result), "Authentication failed")
+	}
+	return nil
+}
+
+// login authenticates the connection with the user's credentials, and returns
+// the session token and its time to live sent back by the server.
+// The token and ttl are empty if the server does not use sessions.
+// External authentication sends the clear text password along with the hash;
+// PKI authentication sends no credentials, the server uses the TLS certificate.
+func (acmd *AdminCommand) login(conn *Connection, mode AuthMode, user string, password []byte, clearPassword string) (token []byte, ttl time.Duration, err error) {
+	defer func() { bufPool.Put(acmd.dataBuffer) }()
+
+	switch mode {
+	case AuthModeExternal:
+		acmd.writeHeader(_LOGIN, 3)
+		acmd.writeFieldStr(_USER, user)
+		acmd.writeFieldBytes(_CREDENTIAL, password)
+		acmd.writeFieldStr(_CLEAR_PASSWORD, clearPassword)
+	case AuthModePKI:
+		acmd.writeHeader(_LOGIN, 0)
+	default:
+		acmd.writeHeader(_LOGIN, 2)
+		acmd.writeFieldStr(_USER, user)
+		acmd.writeFieldBytes(_CREDENTIAL, password)
+	}
+	acmd.writeSize()
+
+	if _, err := conn.Write(acmd.dataBuffer[:acmd.dataOffset]); err != nil {
+		return nil, 0, err
+	}
+
+	if _, err := conn.Read(acmd.dataBuffer, _HEADER_SIZE); err != nil {
+		return nil, 0, err
+	}
+
+	result := acmd.dataBuffer[_RESULT_CODE]
+	fieldCount := int(acmd.dataBuffer[11])
+	receiveSize := int(Buffer.BytesToInt64(acmd.dataBuffer, 0)&0xFFFFFFFFFFFF) - _HEADER_REMAINING
+
+	if receiveSize > 0 {
+		if receiveSize > len(acmd.dataBuffer) {
+			acmd.dataBuffer = make([]byte, receiveSize)
+		}
+		if _, err := conn.Read(acmd.dataBuffer, receiveSize); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	if result != 0 {
+		return nil, 0, NewAerospikeError(ResultCode(result), "Login failed")
+	}
+
+	offset := 0
+	for i := 0; i < fieldCount; i++ {
+		if offset+5 > receiveSize {
+			return nil, 0, NewAerospikeError(PARSE_ERROR, "Invalid login response")
+		}
+		length := int(Buffer.BytesToUint32(acmd.dataBuffer, offset)) - 1
+		id := acmd.dataBuffer[offset+4]
+		offset += 5
+
+		if length < 0 || offset+length > receiveSize {
+			return nil, 0, NewAerospikeError(PARSE_ERROR, "Invalid login response")
+		}
+
+		switch id {
+		case _SESSION_TOKEN:
+			token = append([]byte(nil), acmd.dataBuffer[offset:offset+length]...)
+		case _SESSION_TTL:
+			if length == 4 {
+				ttl = time.Duration(Buffer.BytesToUint32(acmd.dataBuffer, offset)) * time.Second
+			}
+		}
+		offset += length
+	}
+
+	return token, ttl, nil
+}
+
 func (acmd *AdminCommand) createUser(cluster *Cluster, policy *AdminPolicy, user string, password []byte, roles []string) error {
 	acmd.writeHeader(_CREATE_USER, 3)
 	acmd.writeFieldStr(_USER, user)
@@ -264,10 +382,38 @@ func (acmd *AdminCommand) executeCommand(cluster *Cluster, policy *AdminPolicy)
 }
 
 func (acmd *AdminCommand) readUsers(cluster *Cluster, policy *AdminPolicy) ([]*UserRoles, error) {
+	var list []*UserRoles
+	err := acmd.readBlocks(cluster, policy, func(receiveSize int) (int, error) {
+		status, users, err := acmd.parseUsers(receiveSize)
+		list = append(list, users...)
+		return status, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (acmd *AdminCommand) readRoles(cluster *Cluster, policy *AdminPolicy) ([]*RoleInfo, error) {
+	var list []*RoleInfo
+	err := acmd.readBlocks(cluster, policy, func(receiveSize int) (int, error) {
+		status, roles, err := acmd.parseRoleBlocks(receiveSize)
+		list = append(list, roles...)
+		return status, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// readBlocks sends the query command, and parses the result blocks with parse
+// until it returns a non-zero status.
+func (acmd *AdminCommand) readBlocks(cluster *Cluster, policy *AdminPolicy, parse func(receiveSize int) (int, error)) error {
 	acmd.writeSize()
 	node, err := cluster.GetRandomNode()
 	if err != nil {
-		return nil, err
+		return err
 	}
 	timeout := 1 * time.Second
 	if policy != nil && policy.Timeout > 0 {
@@ -276,34 +422,31 @@ func (acmd *AdminCommand) readUsers(cluster *Cluster, policy *AdminPolicy) ([]*U
 
 	conn, err := node.GetConnection(timeout)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	if _, err := conn.Write(acmd.dataBuffer[:acmd.dataOffset]); err != nil {
 		node.InvalidateConnection(conn)
-		return nil, err
+		return err
 	}
 
-	status, list, err := acmd.readUserBlocks(conn)
+	status, err := acmd.readQueryBlocks(conn, parse)
 	if err != nil {
 		node.InvalidateConnection(conn)
-		return nil, err
+		return err
 	}
 	node.PutConnection(conn)
 
 	if status > 0 {
-		return nil, NewAerospikeError(ResultCode(status))
+		return NewAerospikeError(ResultCode(status))
 	}
-	return list, nil
+	return nil
 }
 
-func (acmd *AdminCommand) readUserBlocks(conn *Connection) (status int, rlist []*UserRoles, err error) {
-
-	var list []*UserRoles
-
+func (acmd *AdminCommand) readQueryBlocks(conn *Connection, parse func(receiveSize int) (int, error)) (status int, err error) {
 	for status == 0 {
 		if _, err = conn.Read(acmd.dataBuffer, 8); err != nil {
-			return -1, nil, err
+			return -1, err
 		}
 
 		size := Buffer.BytesToInt64(acmd.dataBuffer, 0)
@@ -314,18 +457,17 @@ func (acmd *AdminCommand) readUserBlocks(conn *Connection) (status int, rlist []
 				acmd.dataBuffer = make([]byte, receiveSize)
 			}
 			if _, err = conn.Read(acmd.dataBuffer, int(receiveSize)); err != nil {
-				return -1, nil, err
+				return -1, err
 			}
-			status, list, err = acmd.parseUsers(int(receiveSize))
+			status, err = parse(int(receiveSize))
 			if err != nil {
-				return -1, nil, err
+				return -1, err
 			}
-			rlist = append(rlist, list...)
 		} else {
 			break
 		}
 	}
-	return status, rlist, nil
+	return status, nil
 }
 
 func (acmd *AdminCommand) parseUsers(receiveSize int) (int, []*UserRoles, error) {
@@ -390,6 +532,236 @@ func (acmd *AdminCommand) parseRoles(userRoles *UserRoles) {
 	}
 }
 
+func (acmd *AdminCommand) createRole(cluster *Cluster, policy *AdminPolicy, role *RoleInfo) error {
+	fieldCount := 1
+	if len(role.Privileges) > 0 {
+		fieldCount++
+	}
+	if len(role.Whitelist) > 0 {
+		fieldCount++
+	}
+	if role.ReadQuota > 0 {
+		fieldCount++
+	}
+	if role.WriteQuota > 0 {
+		fieldCount++
+	}
+
+	acmd.writeHeader(_CREATE_ROLE, fieldCount)
+	acmd.writeFieldStr(_ROLE, role.Name)
+	if len(role.Privileges) > 0 {
+		if err := acmd.writePrivileges(role.Privileges); err != nil {
+			bufPool.Put(acmd.dataBuffer)
+			return err
+		}
+	}
+	if len(role.Whitelist) > 0 {
+		acmd.writeWhitelist(role.Whitelist)
+	}
+	if role.ReadQuota > 0 {
+		acmd.writeFieldUint32(_READ_QUOTA, role.ReadQuota)
+	}
+	if role.WriteQuota > 0 {
+		acmd.writeFieldUint32(_WRITE_QUOTA, role.WriteQuota)
+	}
+	return acmd.executeCommand(cluster, policy)
+}
+
+func (acmd *AdminCommand) dropRole(cluster *Cluster, policy *AdminPolicy, roleName string) error {
+	acmd.writeHeader(_DROP_ROLE, 1)
+	acmd.writeFieldStr(_ROLE, roleName)
+	return acmd.executeCommand(cluster, policy)
+}
+
+func (acmd *AdminCommand) grantPrivileges(cluster *Cluster, policy *AdminPolicy, roleName string, privileges []Privilege) error {
+	acmd.writeHeader(_GRANT_PRIVILEGES, 2)
+	acmd.writeFieldStr(_ROLE, roleName)
+	if err := acmd.writePrivileges(privileges); err != nil {
+		bufPool.Put(acmd.dataBuffer)
+		return err
+	}
+	return acmd.executeCommand(cluster, policy)
+}
+
+func (acmd *AdminCommand) revokePrivileges(cluster *Cluster, policy *AdminPolicy, roleName string, privileges []Privilege) error {
+	acmd.writeHeader(_REVOKE_PRIVILEGES, 2)
+	acmd.writeFieldStr(_ROLE, roleName)
+	if err := acmd.writePrivileges(privileges); err != nil {
+		bufPool.Put(acmd.dataBuffer)
+		return err
+	}
+	return acmd.executeCommand(cluster, policy)
+}
+
+func (acmd *AdminCommand) setWhitelist(cluster *Cluster, policy *AdminPolicy, roleName string, whitelist []string) error {
+	// an empty whitelist removes it
+	if len(whitelist) > 0 {
+		acmd.writeHeader(_SET_WHITELIST, 2)
+		acmd.writeFieldStr(_ROLE, roleName)
+		acmd.writeWhitelist(whitelist)
+	} else {
+		acmd.writeHeader(_SET_WHITELIST, 1)
+		acmd.writeFieldStr(_ROLE, roleName)
+	}
+	return acmd.executeCommand(cluster, policy)
+}
+
+func (acmd *AdminCommand) setQuotas(cluster *Cluster, policy *AdminPolicy, roleName string, readQuota, writeQuota uint32) error {
+	acmd.writeHeader(_SET_QUOTAS, 3)
+	acmd.writeFieldStr(_ROLE, roleName)
+	acmd.writeFieldUint32(_READ_QUOTA, readQuota)
+	acmd.writeFieldUint32(_WRITE_QUOTA, writeQuota)
+	return acmd.executeCommand(cluster, policy)
+}
+
+func (acmd *AdminCommand) queryRole(cluster *Cluster, policy *AdminPolicy, roleName string) (*RoleInfo, error) {
+	defer bufPool.Put(acmd.dataBuffer)
+
+	acmd.writeHeader(_QUERY_ROLES, 1)
+	acmd.writeFieldStr(_ROLE, roleName)
+	list, err := acmd.readRoles(cluster, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(list) > 0 {
+		return list[0], nil
+	}
+
+	return nil, nil
+}
+
+func (acmd *AdminCommand) queryRoles(cluster *Cluster, policy *AdminPolicy) ([]*RoleInfo, error) {
+	defer bufPool.Put(acmd.dataBuffer)
+
+	acmd.writeHeader(_QUERY_ROLES, 0)
+	return acmd.readRoles(cluster, policy)
+}
+
+func (acmd *AdminCommand) writePrivileges(privileges []Privilege) error {
+	offset := acmd.dataOffset + int(_FIELD_HEADER_SIZE)
+	acmd.dataBuffer[offset] = byte(len(privileges))
+	offset++
+
+	for _, privilege := range privileges {
+		id, exists := privilegeIDs[privilege.Code]
+		if !exists {
+			return NewAerospikeError(INVALID_PRIVILEGE, "Unknown privilege `"+string(privilege.Code)+"`")
+		}
+		acmd.dataBuffer[offset] = id
+		offset++
+
+		if privilege.Code.canScope() {
+			for _, name := range []string{privilege.Namespace, privilege.SetName} {
+				len := copy(acmd.dataBuffer[offset+1:], name)
+				acmd.dataBuffer[offset] = byte(len)
+				offset += len + 1
+			}
+		} else if privilege.Namespace != "" || privilege.SetName != "" {
+			return NewAerospikeError(INVALID_PRIVILEGE, "Privilege `"+string(privilege.Code)+"` can not be limited to a namespace or set")
+		}
+	}
+
+	size := offset - acmd.dataOffset - int(_FIELD_HEADER_SIZE)
+	acmd.writeFieldHeader(_PRIVILEGES, size)
+	acmd.dataOffset = offset
+	return nil
+}
+
+func (acmd *AdminCommand) writeWhitelist(whitelist []string) {
+	acmd.writeFieldStr(_WHITELIST, strings.Join(whitelist, ","))
+}
+
+func (acmd *AdminCommand) writeFieldUint32(id byte, value uint32) {
+	acmd.writeFieldHeader(id, 4)
+	Buffer.Int32ToBytes(int32(value), acmd.dataBuffer, acmd.dataOffset)
+	acmd.dataOffset += 4
+}
+
+func (acmd *AdminCommand) parseRoleBlocks(receiveSize int) (int, []*RoleInfo, error) {
+	acmd.dataOffset = 0
+	list := make([]*RoleInfo, 0, 100)
+
+	for acmd.dataOffset < receiveSize {
+		resultCode := int(acmd.dataBuffer[acmd.dataOffset+1])
+
+		if resultCode != 0 {
+			if resultCode == _QUERY_END {
+				return -1, nil, nil
+			}
+			return resultCode, nil, nil
+		}
+
+		role := &RoleInfo{}
+		fieldCount := int(acmd.dataBuffer[acmd.dataOffset+3])
+		acmd.dataOffset += _HEADER_REMAINING
+
+		for i := 0; i < fieldCount; i++ {
+			len := int(Buffer.BytesToInt32(acmd.dataBuffer, acmd.dataOffset))
+			acmd.dataOffset += 4
+			id := acmd.dataBuffer[acmd.dataOffset]
+			acmd.dataOffset++
+			len--
+
+			switch id {
+			case _ROLE:
+				role.Name = string(acmd.dataBuffer[acmd.dataOffset : acmd.dataOffset+len])
+				acmd.dataOffset += len
+			case _PRIVILEGES:
+				acmd.parsePrivileges(role)
+			case _WHITELIST:
+				role.Whitelist = strings.Split(string(acmd.dataBuffer[acmd.dataOffset:acmd.dataOffset+len]), ",")
+				acmd.dataOffset += len
+			case _READ_QUOTA:
+				role.ReadQuota = Buffer.BytesToUint32(acmd.dataBuffer, acmd.dataOffset)
+				acmd.dataOffset += len
+			case _WRITE_QUOTA:
+				role.WriteQuota = Buffer.BytesToUint32(acmd.dataBuffer, acmd.dataOffset)
+				acmd.dataOffset += len
+			default:
+				acmd.dataOffset += len
+			}
+		}
+
+		if role.Name == "" && role.Privileges == nil {
+			continue
+		}
+
+		if role.Privileges == nil {
+			role.Privileges = make([]Privilege, 0)
+		}
+		list = append(list, role)
+	}
+
+	return 0, list, nil
+}
+
+func (acmd *AdminCommand) parsePrivileges(role *RoleInfo) {
+	size := int(acmd.dataBuffer[acmd.dataOffset])
+	acmd.dataOffset++
+	role.Privileges = make([]Privilege, 0, size)
+
+	for i := 0; i < size; i++ {
+		id := acmd.dataBuffer[acmd.dataOffset]
+		privilege := Privilege{Code: privilegeCodes[id]}
+		acmd.dataOffset++
+
+		// data privileges are scoped, even if unknown to the client
+		if id >= privilegeIDs[PrivilegeRead] {
+			len := int(acmd.dataBuffer[acmd.dataOffset])
+			acmd.dataOffset++
+			privilege.Namespace = string(acmd.dataBuffer[acmd.dataOffset : acmd.dataOffset+len])
+			acmd.dataOffset += len
+
+			len = int(acmd.dataBuffer[acmd.dataOffset])
+			acmd.dataOffset++
+			privilege.SetName = string(acmd.dataBuffer[acmd.dataOffset : acmd.dataOffset+len])
+			acmd.dataOffset += len
+		}
+		role.Privileges = append(role.Privileges, privilege)
+	}
+}
+
 func hashPassword(password string) ([]byte, error) {
 	// Hashing the password with the cost of 10, with a static salt
 	const salt = "$2a$10$7EqJtq98hPqEX7fNZaFWoO"
@@ -30,3 +30,9 @@ func NewAdminPolicy() *AdminPolicy {
 		Timeout: 1 * time.Second,
 	}
 }
+
+// Clone returns a copy of the policy.
+func (p *AdminPolicy) Clone() *AdminPolicy {
+	res := *p
+	return &res
+}
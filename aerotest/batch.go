@@ -0,0 +1,211 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerotest
+
+import (
+	"strings"
+
+	. "github.com/THE108/aerospike-client-go/types"
+	Buffer "github.com/THE108/aerospike-client-go/utils/buffer"
+)
+
+// batchKey is a key of a batch command.
+type batchKey struct {
+	index     int
+	namespace string
+	setName   string
+	digest    [20]byte
+	info1     byte
+	ops       []operation
+
+	// set for keys sent in the batch-any protocol
+	batchAny bool
+	udf      *udfCall
+	ttl      uint32
+}
+
+// parseBatchDirect parses the digests of a batch-direct command. The namespace
+// and operations are sent once for all keys.
+func parseBatchDirect(data []byte) ([]batchKey, ResultCode) {
+	var digest [20]byte
+	if len(data)%len(digest) != 0 {
+		return nil, PARAMETER_ERROR
+	}
+
+	keys := make([]batchKey, len(data)/len(digest))
+	for i := range keys {
+		keys[i].index = i
+		copy(keys[i].digest[:], data[i*len(digest):])
+	}
+	return keys, OK
+}
+
+// parseBatchIndex parses the keys of a batch-index command. Every key carries
+// its index, and either its namespace and operations, or a flag to repeat the
+// ones of the previous key. Keys in the batch-any protocol carry flags for the
+// attributes which follow the read attribute, and may carry a UDF call.
+func parseBatchIndex(data []byte) ([]batchKey, ResultCode) {
+	if len(data) < 5 {
+		return nil, PARAMETER_ERROR
+	}
+
+	count := int(Buffer.BytesToUint32(data, 0))
+	offset := 5
+	keys := make([]batchKey, 0, count)
+	for i := 0; i < count; i++ {
+		if offset+25 > len(data) {
+			return nil, PARAMETER_ERROR
+		}
+
+		var key batchKey
+		key.index = int(Buffer.BytesToUint32(data, offset))
+		copy(key.digest[:], data[offset+4:])
+		flags := data[offset+24]
+		offset += 25
+
+		if flags&_BATCH_MSG_REPEAT != 0 {
+			if len(keys) == 0 {
+				return nil, PARAMETER_ERROR
+			}
+			index, digest := key.index, key.digest
+			key = keys[len(keys)-1]
+			key.index, key.digest = index, digest
+			keys = append(keys, key)
+			continue
+		}
+
+		// the write and info attributes are not needed by the supported commands
+		headerSize := 5
+		if flags&_BATCH_MSG_INFO != 0 {
+			headerSize += 2
+		}
+		if flags&_BATCH_MSG_GEN != 0 {
+			headerSize += 2
+		}
+		if flags&_BATCH_MSG_TTL != 0 {
+			headerSize += 4
+		}
+		if offset+headerSize > len(data) {
+			return nil, PARAMETER_ERROR
+		}
+
+		key.batchAny = flags&(_BATCH_MSG_INFO|_BATCH_MSG_GEN|_BATCH_MSG_TTL) != 0
+		key.info1 = data[offset]
+		offset += headerSize - 4
+		if flags&_BATCH_MSG_TTL != 0 {
+			key.ttl = Buffer.BytesToUint32(data, offset-4)
+		}
+		fieldCount := int(Buffer.BytesToUint16(data, offset))
+		opCount := int(Buffer.BytesToUint16(data, offset+2))
+		offset += 4
+
+		for j := 0; j < fieldCount; j++ {
+			if offset+5 > len(data) {
+				return nil, PARAMETER_ERROR
+			}
+			size := int(Buffer.BytesToUint32(data, offset))
+			begin, end := offset+5, offset+4+size
+			if size < 1 || end > len(data) {
+				return nil, PARAMETER_ERROR
+			}
+			switch fieldType := data[offset+4]; fieldType {
+			case _FIELD_NAMESPACE:
+				key.namespace = string(data[begin:end])
+			case _FIELD_TABLE:
+				key.setName = string(data[begin:end])
+			default:
+				setUDFField(&key.udf, fieldType, data[begin:end])
+			}
+			offset = end
+		}
+
+		var resultCode ResultCode
+		if key.ops, offset, resultCode = parseOps(data, offset, opCount); resultCode != OK {
+			return nil, resultCode
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, OK
+}
+
+// batch executes a batch command in the batch-direct or the batch-index
+// protocol. Like servers predating them, the server refuses batch-index commands
+// unless it reports the batch-index feature, and keys in the batch-any protocol
+// unless it reports the batch-any feature.
+// The results of UDF calls are returned for every key, failed calls included.
+func (srv *Server) batch(req *request) []byte {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	if req.batchIndex {
+		if !hasFeature(srv.features, "batch-index") || req.info1&_INFO1_BATCH == 0 {
+			return newResponse(PARAMETER_ERROR, nil, nil)
+		}
+		srv.batchIndexCommands++
+	} else {
+		srv.batchDirectCommands++
+	}
+
+	for _, key := range req.batch {
+		if key.batchAny && !hasFeature(srv.features, "batch-any") {
+			return newResponse(PARAMETER_ERROR, nil, nil)
+		}
+	}
+
+	var buf []byte
+	for _, key := range req.batch {
+		ns := srv.namespaces[key.namespace]
+		if ns == nil {
+			return newResponse(INVALID_NAMESPACE, nil, nil)
+		}
+
+		if key.udf != nil {
+			resultCode, rec, ops := srv.executeUDF(ns, key.digest, key.setName, key.udf, key.ttl)
+			buf = append(buf, newMessage(resultCode, 0, rec, key.index, nil, ops)...)
+			continue
+		}
+
+		info1 := key.info1
+		if !req.batchIndex && len(key.ops) == 0 {
+			// batch-direct commands read all bins unless bins are requested
+			info1 |= _INFO1_GET_ALL
+		}
+
+		resultCode, rec, ops := readRecord(info1, key.ops, ns.get(key.digest))
+		if resultCode != OK && resultCode != KEY_NOT_FOUND_ERROR {
+			return newResponse(resultCode, nil, nil)
+		}
+
+		// batch-direct records are identified by their digest; batch-index
+		// records by their index, but servers return their key fields too
+		fields := []field{
+			{fieldType: _FIELD_NAMESPACE, data: []byte(key.namespace)},
+			{fieldType: _FIELD_DIGEST_RIPE, data: key.digest[:]},
+		}
+		buf = append(buf, newMessage(resultCode, 0, rec, key.index, fields, ops)...)
+	}
+
+	return append(buf, newResponse(OK, nil, nil)...)
+}
+
+func hasFeature(features, feature string) bool {
+	for _, f := range strings.Split(features, ";") {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,258 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerotest
+
+import (
+	"sort"
+
+	. "github.com/THE108/aerospike-client-go/types"
+	ParticleType "github.com/THE108/aerospike-client-go/types/particle_type"
+	Buffer "github.com/THE108/aerospike-client-go/utils/buffer"
+)
+
+// CDT map commands; these mirror the ones used by the client.
+const (
+	_CDT_MAP_INCREMENT              = 73
+	_CDT_MAP_DECREMENT              = 74
+	_CDT_MAP_REMOVE_BY_KEY_INTERVAL = 84
+	_CDT_MAP_GET_BY_KEY_INTERVAL    = 103
+
+	_MAP_RETURN_NONE      = 0
+	_MAP_RETURN_COUNT     = 5
+	_MAP_RETURN_KEY       = 6
+	_MAP_RETURN_VALUE     = 7
+	_MAP_RETURN_KEY_VALUE = 8
+)
+
+// mapItem is an item of a map bin. Keys are int64 or string.
+type mapItem struct {
+	key, value interface{}
+}
+
+// cdt applies a CDT map operation to the record, and returns its result, if
+// any. Maps are kept sorted by key, whatever the order requested by the
+// client. Only integer and string map keys and the map commands used by the
+// client's map helpers are supported.
+func (rec *record) cdt(op operation) (*particle, ResultCode) {
+	if op.value.particleType != ParticleType.BLOB || len(op.value.data) < 2 {
+		return nil, PARAMETER_ERROR
+	}
+
+	command := int(op.value.data[0])<<8 | int(op.value.data[1])
+	args, err := unpackArgs(op.value.data[2:])
+	if err != nil {
+		return nil, PARAMETER_ERROR
+	}
+
+	var items []mapItem
+	if value, exists := rec.bins[op.binName]; exists {
+		if value.particleType != ParticleType.MAP {
+			return nil, BIN_TYPE_ERROR
+		}
+		if items, err = unpackMap(value.data); err != nil {
+			return nil, PARAMETER_ERROR
+		}
+	}
+
+	modify := op.opType == _OP_CDT_MODIFY
+	switch {
+	case command == _CDT_MAP_INCREMENT && modify, command == _CDT_MAP_DECREMENT && modify:
+		if len(args) < 2 || !validMapKey(args[0]) {
+			return nil, PARAMETER_ERROR
+		}
+		incr, ok := args[1].(int64)
+		if !ok {
+			return nil, PARAMETER_ERROR
+		}
+		if command == _CDT_MAP_DECREMENT {
+			incr = -incr
+		}
+
+		i := sort.Search(len(items), func(i int) bool { return compareMapKeys(items[i].key, args[0]) >= 0 })
+		if i == len(items) || compareMapKeys(items[i].key, args[0]) != 0 {
+			items = append(items, mapItem{})
+			copy(items[i+1:], items[i:])
+			items[i] = mapItem{key: args[0], value: int64(0)}
+		}
+		current, ok := items[i].value.(int64)
+		if !ok {
+			return nil, BIN_TYPE_ERROR
+		}
+		items[i].value = current + incr
+
+		rec.bins[op.binName] = particle{particleType: ParticleType.MAP, data: packMap(items)}
+		result := particle{particleType: ParticleType.INTEGER, data: Buffer.Int64ToBytes(current+incr, nil, 0)}
+		return &result, OK
+
+	case command == _CDT_MAP_REMOVE_BY_KEY_INTERVAL && modify, command == _CDT_MAP_GET_BY_KEY_INTERVAL:
+		if len(args) < 2 || len(args) > 3 {
+			return nil, PARAMETER_ERROR
+		}
+		returnType, ok := args[0].(int64)
+		if !ok {
+			return nil, PARAMETER_ERROR
+		}
+
+		// a missing end selects up to the highest key
+		begin := sort.Search(len(items), func(i int) bool { return compareMapKeys(items[i].key, args[1]) >= 0 })
+		end := len(items)
+		if len(args) == 3 {
+			end = sort.Search(len(items), func(i int) bool { return compareMapKeys(items[i].key, args[2]) >= 0 })
+		}
+		if end < begin {
+			end = begin
+		}
+		selected := append([]mapItem(nil), items[begin:end]...)
+
+		if command == _CDT_MAP_REMOVE_BY_KEY_INTERVAL && len(selected) > 0 {
+			items = append(items[:begin], items[end:]...)
+			rec.bins[op.binName] = particle{particleType: ParticleType.MAP, data: packMap(items)}
+		}
+		return mapResult(int(returnType), selected)
+	}
+
+	return nil, PARAMETER_ERROR
+}
+
+// mapResult returns the result of a map operation that selects items.
+func mapResult(returnType int, selected []mapItem) (*particle, ResultCode) {
+	var result particle
+	switch returnType {
+	case _MAP_RETURN_NONE:
+		return nil, OK
+	case _MAP_RETURN_COUNT:
+		result = particle{particleType: ParticleType.INTEGER, data: Buffer.Int64ToBytes(int64(len(selected)), nil, 0)}
+	case _MAP_RETURN_KEY, _MAP_RETURN_VALUE:
+		list := make([]interface{}, len(selected))
+		for i, item := range selected {
+			if returnType == _MAP_RETURN_KEY {
+				list[i] = item.key
+			} else {
+				list[i] = item.value
+			}
+		}
+		result = particle{particleType: ParticleType.LIST, data: packList(list)}
+	case _MAP_RETURN_KEY_VALUE:
+		result = particle{particleType: ParticleType.MAP, data: packMap(selected)}
+	default:
+		return nil, PARAMETER_ERROR
+	}
+	return &result, OK
+}
+
+func validMapKey(key interface{}) bool {
+	switch key.(type) {
+	case int64, string:
+		return true
+	}
+	return false
+}
+
+// compareMapKeys orders map keys the way the server does: nil sorts before
+// integers, which sort before strings.
+func compareMapKeys(a, b interface{}) int {
+	rank := func(v interface{}) int {
+		switch v.(type) {
+		case nil:
+			return 0
+		case int64:
+			return 1
+		}
+		return 2
+	}
+
+	if ra, rb := rank(a), rank(b); ra != rb {
+		return ra - rb
+	}
+
+	switch a := a.(type) {
+	case int64:
+		switch b := b.(int64); {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		}
+	case string:
+		switch b := b.(string); {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		}
+	}
+	return 0
+}
+
+// unpackMap decodes a msgpack encoded map bin.
+func unpackMap(data []byte) ([]mapItem, error) {
+	u := &unpacker{data: data}
+	var count int
+	switch b := u.byte(); {
+	case b&0xf0 == 0x80:
+		count = int(b & 0x0f)
+	case b == 0xde:
+		count = int(u.uint(2))
+	case b == 0xdf:
+		count = int(u.uint(4))
+	default:
+		return nil, errUnsupportedType
+	}
+
+	items := make([]mapItem, 0, count)
+	for i := 0; i < count && u.err == nil; i++ {
+		key := u.value()
+		items = append(items, mapItem{key: key, value: u.value()})
+	}
+	return items, u.err
+}
+
+func packMap(items []mapItem) []byte {
+	buf := []byte{0xdf, 0, 0, 0, 0}
+	Buffer.Int32ToBytes(int32(len(items)), buf, 1)
+	for _, item := range items {
+		buf = packValue(packValue(buf, item.key), item.value)
+	}
+	return buf
+}
+
+func packList(list []interface{}) []byte {
+	buf := []byte{0xdd, 0, 0, 0, 0}
+	Buffer.Int32ToBytes(int32(len(list)), buf, 1)
+	for _, v := range list {
+		buf = packValue(buf, v)
+	}
+	return buf
+}
+
+// packValue appends the msgpack encoding of an int64, string or []byte
+// value. Strings and blobs are prefixed with their particle type.
+func packValue(buf []byte, v interface{}) []byte {
+	var raw []byte
+	switch v := v.(type) {
+	case int64:
+		return append(buf, 0xd3, byte(v>>56), byte(v>>48), byte(v>>40), byte(v>>32), byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+	case string:
+		raw = append([]byte{ParticleType.STRING}, v...)
+	case []byte:
+		raw = append([]byte{ParticleType.BLOB}, v...)
+	default:
+		return append(buf, 0xc0)
+	}
+
+	n := len(raw)
+	buf = append(buf, 0xc6, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	return append(buf, raw...)
+}
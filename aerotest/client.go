@@ -0,0 +1,44 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerotest
+
+import (
+	as "github.com/THE108/aerospike-client-go"
+)
+
+// NewClientAndServer starts a fake server serving the given namespaces,
+// and connects a client with the default client policy to it.
+// Close the client before the server.
+func NewClientAndServer(namespaces ...string) (*as.Client, *Server, error) {
+	return NewClientAndServerWithPolicy(nil, namespaces...)
+}
+
+// NewClientAndServerWithPolicy starts a fake server serving the given
+// namespaces, and connects a client with the given client policy to it.
+// If the policy is nil, the default client policy will be used.
+// Close the client before the server.
+func NewClientAndServerWithPolicy(policy *as.ClientPolicy, namespaces ...string) (*as.Client, *Server, error) {
+	srv, err := NewServer(namespaces...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	client, err := as.NewClientWithPolicy(policy, srv.Host(), srv.Port())
+	if err != nil {
+		srv.Close()
+		return nil, nil, err
+	}
+	return client, srv, nil
+}
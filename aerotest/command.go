@@ -0,0 +1,474 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerotest
+
+import (
+	"sort"
+	"time"
+
+	. "github.com/THE108/aerospike-client-go/types"
+	ParticleType "github.com/THE108/aerospike-client-go/types/particle_type"
+	Buffer "github.com/THE108/aerospike-client-go/utils/buffer"
+)
+
+// Wire protocol constants; these mirror the ones used by the client.
+const (
+	_INFO1_READ      = (1 << 0)
+	_INFO1_GET_ALL   = (1 << 1)
+	_INFO1_BATCH     = (1 << 3)
+	_INFO1_NOBINDATA = (1 << 5)
+
+	_INFO2_WRITE         = (1 << 0)
+	_INFO2_DELETE        = (1 << 1)
+	_INFO2_GENERATION    = (1 << 2)
+	_INFO2_GENERATION_GT = (1 << 3)
+	_INFO2_CREATE_ONLY   = (1 << 5)
+
+	_INFO3_LAST              = (1 << 0)
+	_INFO3_UPDATE_ONLY       = (1 << 3)
+	_INFO3_CREATE_OR_REPLACE = (1 << 4)
+	_INFO3_REPLACE_ONLY      = (1 << 5)
+
+	_FIELD_NAMESPACE         = 0
+	_FIELD_TABLE             = 1
+	_FIELD_KEY               = 2
+	_FIELD_DIGEST_RIPE       = 4
+	_FIELD_DIGEST_RIPE_ARRAY = 6
+	_FIELD_TRAN_ID           = 7
+	_FIELD_UDF_PACKAGE_NAME  = 30
+	_FIELD_UDF_FUNCTION      = 31
+	_FIELD_UDF_ARGLIST       = 32
+	_FIELD_BATCH_INDEX       = 41
+	_FIELD_PREDEXP           = 43
+
+	_BATCH_MSG_REPEAT = (1 << 0)
+	_BATCH_MSG_INFO   = (1 << 1)
+	_BATCH_MSG_GEN    = (1 << 2)
+	_BATCH_MSG_TTL    = (1 << 3)
+
+	_OP_READ       = 1
+	_OP_WRITE      = 2
+	_OP_CDT_READ   = 3
+	_OP_CDT_MODIFY = 4
+	_OP_ADD        = 5
+	_OP_APPEND     = 9
+	_OP_PREPEND    = 10
+	_OP_TOUCH      = 11
+
+	_TTL_NEVER_EXPIRE = 0xFFFFFFFF
+	_TTL_DONT_UPDATE  = 0xFFFFFFFE
+)
+
+// request is a parsed single record or batch AS_MSG command.
+type request struct {
+	info1, info2, info3 byte
+	generation          uint32
+	ttl                 uint32
+
+	namespace string
+	setName   string
+	digest    [20]byte
+	hasDigest bool
+	supported bool
+
+	ops     []operation
+	udf     *udfCall
+	predExp []byte
+
+	// the keys of batch commands
+	batch      []batchKey
+	batchIndex bool
+}
+
+type operation struct {
+	opType  byte
+	binName string
+	value   particle
+}
+
+func parseRequest(body []byte) (*request, ResultCode) {
+	if len(body) < _MSG_HEADER_SIZE {
+		return nil, PARAMETER_ERROR
+	}
+
+	req := &request{
+		info1:      body[1],
+		info2:      body[2],
+		info3:      body[3],
+		generation: Buffer.BytesToUint32(body, 6),
+		ttl:        Buffer.BytesToUint32(body, 10),
+		supported:  true,
+	}
+
+	fieldCount := int(Buffer.BytesToUint16(body, 18))
+	opCount := int(Buffer.BytesToUint16(body, 20))
+	offset := int(body[0])
+
+	var resultCode ResultCode
+
+	for i := 0; i < fieldCount; i++ {
+		if offset+5 > len(body) {
+			return nil, PARAMETER_ERROR
+		}
+		size := int(Buffer.BytesToUint32(body, offset))
+		fieldType := body[offset+4]
+		begin, end := offset+5, offset+4+size
+		if size < 1 || end > len(body) {
+			return nil, PARAMETER_ERROR
+		}
+
+		switch fieldType {
+		case _FIELD_NAMESPACE:
+			req.namespace = string(body[begin:end])
+		case _FIELD_DIGEST_RIPE:
+			if end-begin != len(req.digest) {
+				return nil, PARAMETER_ERROR
+			}
+			copy(req.digest[:], body[begin:end])
+			req.hasDigest = true
+		case _FIELD_TABLE:
+			req.setName = string(body[begin:end])
+		case _FIELD_DIGEST_RIPE_ARRAY:
+			if req.batch, resultCode = parseBatchDirect(body[begin:end]); resultCode != OK {
+				return nil, resultCode
+			}
+		case _FIELD_BATCH_INDEX:
+			if req.batch, resultCode = parseBatchIndex(body[begin:end]); resultCode != OK {
+				return nil, resultCode
+			}
+			req.batchIndex = true
+		case _FIELD_PREDEXP:
+			req.predExp = body[begin:end]
+		case _FIELD_KEY, _FIELD_TRAN_ID:
+			// not needed to identify the record
+		default:
+			// scan and query fields
+			if !setUDFField(&req.udf, fieldType, body[begin:end]) {
+				req.supported = false
+			}
+		}
+		offset = end
+	}
+
+	if req.ops, offset, resultCode = parseOps(body, offset, opCount); resultCode != OK {
+		return nil, resultCode
+	}
+
+	if req.batch != nil && !req.batchIndex {
+		// batch-direct commands apply the namespace and operations to all keys
+		for i := range req.batch {
+			req.batch[i].namespace = req.namespace
+			req.batch[i].info1 = req.info1
+			req.batch[i].ops = req.ops
+		}
+	}
+
+	return req, OK
+}
+
+// parseOps parses count operations starting at offset, and returns the offset
+// following them.
+func parseOps(body []byte, offset, count int) ([]operation, int, ResultCode) {
+	ops := make([]operation, 0, count)
+	for i := 0; i < count; i++ {
+		if offset+8 > len(body) {
+			return nil, 0, PARAMETER_ERROR
+		}
+		size := int(Buffer.BytesToUint32(body, offset))
+		nameLen := int(body[offset+7])
+		nameBegin := offset + 8
+		valueBegin, end := nameBegin+nameLen, offset+4+size
+		if valueBegin > end || end > len(body) {
+			return nil, 0, PARAMETER_ERROR
+		}
+
+		value := make([]byte, end-valueBegin)
+		copy(value, body[valueBegin:end])
+		ops = append(ops, operation{
+			opType:  body[offset+4],
+			binName: string(body[nameBegin:valueBegin]),
+			value:   particle{particleType: body[offset+5], data: value},
+		})
+		offset = end
+	}
+
+	return ops, offset, OK
+}
+
+// command executes a single record or batch command and returns the response body.
+func (srv *Server) command(body []byte) []byte {
+	req, resultCode := parseRequest(body)
+	if resultCode != OK {
+		return newResponse(resultCode, nil, nil)
+	}
+
+	if req.batch != nil {
+		return srv.batch(req)
+	}
+
+	if !req.supported || !req.hasDigest {
+		return newResponse(PARAMETER_ERROR, nil, nil)
+	}
+
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	ns := srv.namespaces[req.namespace]
+	if ns == nil {
+		return newResponse(INVALID_NAMESPACE, nil, nil)
+	}
+
+	if resultCode := filter(req, ns.get(req.digest)); resultCode != OK {
+		return newResponse(resultCode, nil, nil)
+	}
+
+	if req.udf != nil {
+		return newResponse(srv.executeUDF(ns, req.digest, req.setName, req.udf, req.ttl))
+	}
+
+	rec := ns.get(req.digest)
+
+	switch {
+	case req.info2&_INFO2_DELETE != 0:
+		if rec == nil {
+			return newResponse(KEY_NOT_FOUND_ERROR, nil, nil)
+		}
+		if resultCode := checkGeneration(req, rec); resultCode != OK {
+			return newResponse(resultCode, nil, nil)
+		}
+		ns.delete(req.digest)
+		return newResponse(OK, nil, nil)
+
+	case req.info2&_INFO2_WRITE != 0:
+		return srv.write(ns, req, rec)
+
+	default:
+		return srv.read(req, rec)
+	}
+}
+
+func (srv *Server) read(req *request, rec *record) []byte {
+	return newResponse(readRecord(req.info1, req.ops, rec))
+}
+
+// readRecord returns the result of the read operations on the record, which
+// may be nil.
+func readRecord(info1 byte, ops []operation, rec *record) (ResultCode, *record, []operation) {
+	if rec == nil {
+		return KEY_NOT_FOUND_ERROR, nil, nil
+	}
+
+	if info1&_INFO1_NOBINDATA != 0 {
+		return OK, rec, nil
+	}
+
+	if len(ops) == 0 {
+		if info1&_INFO1_GET_ALL != 0 {
+			return OK, rec, rec.allBins()
+		}
+		return OK, rec, nil
+	}
+
+	results := make([]operation, 0, len(ops))
+	for _, op := range ops {
+		switch op.opType {
+		case _OP_READ:
+			results = append(results, rec.readBin(op.binName)...)
+		case _OP_CDT_READ:
+			// reads don't change the record, so the bins don't need a copy
+			result, resultCode := rec.cdt(op)
+			if resultCode != OK {
+				return resultCode, nil, nil
+			}
+			if result != nil {
+				results = append(results, operation{opType: _OP_READ, binName: op.binName, value: *result})
+			}
+		default:
+			return PARAMETER_ERROR, nil, nil
+		}
+	}
+	return OK, rec, results
+}
+
+func (srv *Server) write(ns *namespace, req *request, rec *record) []byte {
+	if rec == nil {
+		if req.info3&(_INFO3_UPDATE_ONLY|_INFO3_REPLACE_ONLY) != 0 {
+			return newResponse(KEY_NOT_FOUND_ERROR, nil, nil)
+		}
+		for _, op := range req.ops {
+			if op.opType == _OP_TOUCH {
+				return newResponse(KEY_NOT_FOUND_ERROR, nil, nil)
+			}
+		}
+	} else {
+		if req.info2&_INFO2_CREATE_ONLY != 0 {
+			return newResponse(KEY_EXISTS_ERROR, nil, nil)
+		}
+		if resultCode := checkGeneration(req, rec); resultCode != OK {
+			return newResponse(resultCode, nil, nil)
+		}
+	}
+
+	// apply the operations to a copy so a failed command leaves the record intact
+	updated := rec.clone()
+	if rec == nil {
+		updated.setName = req.setName
+	}
+	if req.info3&(_INFO3_CREATE_OR_REPLACE|_INFO3_REPLACE_ONLY) != 0 {
+		updated.bins = map[string]particle{}
+	}
+
+	results := make([]operation, 0, len(req.ops))
+	for _, op := range req.ops {
+		var resultCode ResultCode
+		switch op.opType {
+		case _OP_READ:
+			results = append(results, updated.readBin(op.binName)...)
+		case _OP_WRITE:
+			if op.value.particleType == ParticleType.NULL {
+				delete(updated.bins, op.binName)
+			} else {
+				updated.bins[op.binName] = op.value
+			}
+		case _OP_ADD:
+			resultCode = updated.add(op.binName, op.value)
+		case _OP_APPEND:
+			resultCode = updated.concat(op.binName, op.value, false)
+		case _OP_PREPEND:
+			resultCode = updated.concat(op.binName, op.value, true)
+		case _OP_CDT_READ, _OP_CDT_MODIFY:
+			var result *particle
+			if result, resultCode = updated.cdt(op); result != nil {
+				results = append(results, operation{opType: _OP_READ, binName: op.binName, value: *result})
+			}
+		case _OP_TOUCH:
+		default:
+			resultCode = PARAMETER_ERROR
+		}
+
+		if resultCode != OK {
+			return newResponse(resultCode, nil, nil)
+		}
+	}
+
+	updated.generation++
+	updated.lastUpdate = time.Now().UnixNano()
+	updated.setTTL(req.ttl)
+
+	if len(updated.bins) == 0 {
+		// a record without bins does not exist
+		ns.delete(req.digest)
+	} else {
+		ns.put(req.digest, updated)
+	}
+
+	return newResponse(OK, updated, results)
+}
+
+// filter returns FILTERED_OUT if the record does not match the predicate
+// expressions of the command. Commands with expressions are not applied to
+// records which don't exist.
+func filter(req *request, rec *record) ResultCode {
+	if req.predExp == nil {
+		return OK
+	}
+	if rec == nil {
+		return KEY_NOT_FOUND_ERROR
+	}
+
+	match, ok := matchPredExp(req.predExp, rec)
+	switch {
+	case !ok:
+		return PARAMETER_ERROR
+	case !match:
+		return FILTERED_OUT
+	}
+	return OK
+}
+
+func checkGeneration(req *request, rec *record) ResultCode {
+	if req.info2&_INFO2_GENERATION != 0 && req.generation != rec.generation {
+		return GENERATION_ERROR
+	}
+	if req.info2&_INFO2_GENERATION_GT != 0 && req.generation <= rec.generation {
+		return GENERATION_ERROR
+	}
+	return OK
+}
+
+// newResponse builds the AS_MSG response body. The record may be nil.
+func newResponse(resultCode ResultCode, rec *record, ops []operation) []byte {
+	return newMessage(resultCode, _INFO3_LAST, rec, 0, nil, ops)
+}
+
+type field struct {
+	fieldType byte
+	data      []byte
+}
+
+// newMessage builds an AS_MSG message. Batch responses are made of a message
+// per record, followed by a message flagged with _INFO3_LAST.
+// The batch index is only returned for batch-index commands.
+func newMessage(resultCode ResultCode, info3 byte, rec *record, batchIndex int, fields []field, ops []operation) []byte {
+	size := _MSG_HEADER_SIZE
+	for _, f := range fields {
+		size += 5 + len(f.data)
+	}
+	for _, op := range ops {
+		size += 8 + len(op.binName) + len(op.value.data)
+	}
+
+	buf := make([]byte, size)
+	buf[0] = _MSG_HEADER_SIZE
+	buf[3] = info3
+	buf[5] = byte(resultCode)
+	if rec != nil {
+		Buffer.Int32ToBytes(int32(rec.generation), buf, 6)
+		Buffer.Int32ToBytes(int32(rec.voidTime), buf, 10)
+	}
+	Buffer.Int32ToBytes(int32(batchIndex), buf, 14)
+	Buffer.Int16ToBytes(int16(len(fields)), buf, 18)
+	Buffer.Int16ToBytes(int16(len(ops)), buf, 20)
+
+	offset := _MSG_HEADER_SIZE
+	for _, f := range fields {
+		Buffer.Int32ToBytes(int32(1+len(f.data)), buf, offset)
+		buf[offset+4] = f.fieldType
+		offset += 5
+		offset += copy(buf[offset:], f.data)
+	}
+
+	for _, op := range ops {
+		Buffer.Int32ToBytes(int32(4+len(op.binName)+len(op.value.data)), buf, offset)
+		buf[offset+4] = _OP_READ
+		buf[offset+5] = op.value.particleType
+		buf[offset+7] = byte(len(op.binName))
+		offset += 8
+		offset += copy(buf[offset:], op.binName)
+		offset += copy(buf[offset:], op.value.data)
+	}
+
+	return buf
+}
+
+// sortedBinNames returns the bin names in a stable order.
+func sortedBinNames(bins map[string]particle) []string {
+	names := make([]string, 0, len(bins))
+	for name := range bins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,169 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerotest
+
+import (
+	ParticleType "github.com/THE108/aerospike-client-go/types/particle_type"
+	Buffer "github.com/THE108/aerospike-client-go/utils/buffer"
+)
+
+// Predicate expression tags; these mirror the ones used by the client.
+const (
+	_PREDEXP_AND = 1
+	_PREDEXP_OR  = 2
+	_PREDEXP_NOT = 3
+
+	_PREDEXP_INTEGER_VALUE = 10
+	_PREDEXP_STRING_VALUE  = 11
+
+	_PREDEXP_INTEGER_BIN = 100
+	_PREDEXP_STRING_BIN  = 101
+
+	_PREDEXP_INTEGER_EQUAL     = 200
+	_PREDEXP_INTEGER_UNEQUAL   = 201
+	_PREDEXP_INTEGER_GREATER   = 202
+	_PREDEXP_INTEGER_GREATEREQ = 203
+	_PREDEXP_INTEGER_LESS      = 204
+	_PREDEXP_INTEGER_LESSEQ    = 205
+
+	_PREDEXP_STRING_EQUAL   = 210
+	_PREDEXP_STRING_UNEQUAL = 211
+)
+
+// predExpValue is a value on the stack of a predicate expression. Bins which
+// don't exist or hold a value of another type are unknown, and make any
+// comparison on them false.
+type predExpValue struct {
+	known bool
+	i     int64
+	s     string
+	b     bool
+}
+
+// matchPredExp evaluates the predicate expressions, in postfix notation,
+// against the record. Only integer and string bins and comparisons, and the
+// logical operators are supported; ok is false for malformed or unsupported
+// expressions.
+func matchPredExp(data []byte, rec *record) (match, ok bool) {
+	var stack []predExpValue
+	pop := func(n int) []predExpValue {
+		if len(stack) < n {
+			ok = false
+			return make([]predExpValue, n)
+		}
+		res := stack[len(stack)-n:]
+		stack = stack[:len(stack)-n]
+		return res
+	}
+
+	ok = true
+	for offset := 0; offset < len(data) && ok; {
+		if offset+6 > len(data) {
+			return false, false
+		}
+		tag := int(Buffer.BytesToUint16(data, offset))
+		size := int(Buffer.BytesToUint32(data, offset+2))
+		begin, end := offset+6, offset+6+size
+		if end > len(data) {
+			return false, false
+		}
+		payload := data[begin:end]
+		offset = end
+
+		switch tag {
+		case _PREDEXP_INTEGER_VALUE:
+			if size != 8 {
+				return false, false
+			}
+			stack = append(stack, predExpValue{known: true, i: Buffer.BytesToInt64(payload, 0)})
+
+		case _PREDEXP_STRING_VALUE:
+			stack = append(stack, predExpValue{known: true, s: string(payload)})
+
+		case _PREDEXP_INTEGER_BIN, _PREDEXP_STRING_BIN:
+			stack = append(stack, binValue(tag, rec.bins[string(payload)]))
+
+		case _PREDEXP_INTEGER_EQUAL, _PREDEXP_INTEGER_UNEQUAL,
+			_PREDEXP_INTEGER_GREATER, _PREDEXP_INTEGER_GREATEREQ,
+			_PREDEXP_INTEGER_LESS, _PREDEXP_INTEGER_LESSEQ,
+			_PREDEXP_STRING_EQUAL, _PREDEXP_STRING_UNEQUAL:
+			args := pop(2)
+			stack = append(stack, predExpValue{known: true, b: compare(tag, args[0], args[1])})
+
+		case _PREDEXP_AND, _PREDEXP_OR:
+			if size != 2 {
+				return false, false
+			}
+			args := pop(int(Buffer.BytesToUint16(payload, 0)))
+			res := tag == _PREDEXP_AND
+			for _, arg := range args {
+				if tag == _PREDEXP_AND {
+					res = res && arg.b
+				} else {
+					res = res || arg.b
+				}
+			}
+			stack = append(stack, predExpValue{known: true, b: res})
+
+		case _PREDEXP_NOT:
+			arg := pop(1)[0]
+			stack = append(stack, predExpValue{known: true, b: !arg.b})
+
+		default:
+			return false, false
+		}
+	}
+
+	if !ok || len(stack) != 1 {
+		return false, false
+	}
+	return stack[0].b, true
+}
+
+func binValue(tag int, p particle) predExpValue {
+	switch {
+	case tag == _PREDEXP_INTEGER_BIN && p.particleType == ParticleType.INTEGER:
+		return predExpValue{known: true, i: Buffer.VarBytesToInt64(p.data, 0, len(p.data))}
+	case tag == _PREDEXP_STRING_BIN && p.particleType == ParticleType.STRING:
+		return predExpValue{known: true, s: string(p.data)}
+	}
+	return predExpValue{}
+}
+
+func compare(tag int, left, right predExpValue) bool {
+	if !left.known || !right.known {
+		return false
+	}
+
+	switch tag {
+	case _PREDEXP_INTEGER_EQUAL:
+		return left.i == right.i
+	case _PREDEXP_INTEGER_UNEQUAL:
+		return left.i != right.i
+	case _PREDEXP_INTEGER_GREATER:
+		return left.i > right.i
+	case _PREDEXP_INTEGER_GREATEREQ:
+		return left.i >= right.i
+	case _PREDEXP_INTEGER_LESS:
+		return left.i < right.i
+	case _PREDEXP_INTEGER_LESSEQ:
+		return left.i <= right.i
+	case _PREDEXP_STRING_EQUAL:
+		return left.s == right.s
+	case _PREDEXP_STRING_UNEQUAL:
+		return left.s != right.s
+	}
+	return false
+}
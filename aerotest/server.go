@@ -0,0 +1,510 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package aerotest provides an in-process fake Aerospike server for tests.
+//
+// The server speaks enough of the wire protocol for a client to connect,
+// tend the single node cluster and run single record commands against it:
+// Get, GetHeader, Exists, Put, Add, Append, Prepend, Touch, Delete and
+// Operate with the basic operations and the map increment, decrement and key
+// range operations. Batch reads are served in both
+// the batch-direct and the batch-index protocol; the latter only if the server
+// reports the batch-index feature. Record UDFs registered with RegisterUDF are
+// served in single record commands, and in batch commands if the server
+// reports the batch-any feature. Single record commands may be filtered with
+// predicate expressions on integer and string bins. Scan and query commands
+// are answered with PARAMETER_ERROR.
+//
+// Records are kept in memory only, and are lost once the server is closed.
+package aerotest
+
+import (
+	"encoding/base64"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/THE108/aerospike-client-go/types"
+	Buffer "github.com/THE108/aerospike-client-go/utils/buffer"
+)
+
+const (
+	// NodeName is the node name the fake server reports to clients.
+	NodeName = "BB9AEROTEST0001"
+
+	// ClusterName is the cluster name the fake server reports to clients by default.
+	ClusterName = "aerotest"
+
+	// Build is the server build the fake server reports to clients.
+	Build = "3.6.0"
+
+	// Features are the features the fake server reports to clients by default.
+	Features = "cdt-list;pipelining;replicas-master;udf"
+
+	_PROTO_HEADER_SIZE = 8
+	_MSG_HEADER_SIZE   = 22
+	_PARTITIONS        = 4096
+)
+
+// Server is an in-memory fake Aerospike server node.
+type Server struct {
+	listener net.Listener
+
+	mutex      sync.Mutex
+	namespaces map[string]*namespace
+	features   string
+	conns      map[net.Conn]struct{}
+	closed     bool
+
+	name            string
+	clusterName     string
+	rackId          int
+	peers           []string
+	peersGeneration int
+
+	batchDirectCommands int
+	batchIndexCommands  int
+
+	droppedInfoRequests int
+	infoDelay           time.Duration
+
+	udfs map[string]UDF
+
+	wg sync.WaitGroup
+}
+
+// NewServer starts a fake server on a random local port
+// which serves the given namespaces.
+// If no namespace is passed, the "test" namespace will be served.
+func NewServer(namespaces ...string) (*Server, error) {
+	return NewServerOnAddress("127.0.0.1:0", namespaces...)
+}
+
+// NewServerOnAddress starts a fake server listening on the given address
+// which serves the given namespaces.
+// If no namespace is passed, the "test" namespace will be served.
+func NewServerOnAddress(address string, namespaces ...string) (*Server, error) {
+	if len(namespaces) == 0 {
+		namespaces = []string{"test"}
+	}
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	srv := &Server{
+		listener:    listener,
+		namespaces:  make(map[string]*namespace, len(namespaces)),
+		features:    Features,
+		conns:       map[net.Conn]struct{}{},
+		name:        NodeName,
+		clusterName: ClusterName,
+		udfs:        map[string]UDF{},
+	}
+
+	for _, ns := range namespaces {
+		srv.namespaces[ns] = newNamespace()
+	}
+
+	srv.wg.Add(1)
+	go srv.serve()
+
+	return srv, nil
+}
+
+// Host returns the host address the server is listening on.
+func (srv *Server) Host() string {
+	return srv.listener.Addr().(*net.TCPAddr).IP.String()
+}
+
+// Port returns the port the server is listening on.
+func (srv *Server) Port() int {
+	return srv.listener.Addr().(*net.TCPAddr).Port
+}
+
+// Address returns the host:port the server is listening on.
+func (srv *Server) Address() string {
+	return srv.listener.Addr().String()
+}
+
+// Len returns the number of live records in the namespace.
+func (srv *Server) Len(namespace string) int {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	ns := srv.namespaces[namespace]
+	if ns == nil {
+		return 0
+	}
+	return ns.len()
+}
+
+// SetFeatures changes the features the server reports to clients,
+// e.g. to simulate a server upgrade or downgrade.
+func (srv *Server) SetFeatures(features ...string) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	srv.features = strings.Join(features, ";")
+}
+
+// BatchCommands returns the number of batch commands the server received
+// in the batch-direct and the batch-index protocol.
+func (srv *Server) BatchCommands() (direct, index int) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	return srv.batchDirectCommands, srv.batchIndexCommands
+}
+
+// SetNodeName changes the node name the server reports to clients.
+// It must be called before clients connect to the server.
+func (srv *Server) SetNodeName(name string) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	srv.name = name
+}
+
+// SetClusterName changes the cluster name the server reports to clients.
+func (srv *Server) SetClusterName(name string) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	srv.clusterName = name
+}
+
+// SetRackId changes the rack-id the server reports for its namespaces.
+func (srv *Server) SetRackId(id int) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	srv.rackId = id
+}
+
+// AddPeer adds the other server to the peers the server reports to clients
+// supporting the peers protocol, i.e. if the server reports the `peers` feature.
+func (srv *Server) AddPeer(peer *Server) {
+	peer.mutex.Lock()
+	entry := "[" + peer.name + ",,[" + peer.Address() + "]]"
+	peer.mutex.Unlock()
+
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	srv.peers = append(srv.peers, entry)
+	srv.peersGeneration++
+}
+
+// SetPeers replaces the peers the server reports to clients, e.g. after
+// one of them was restarted on another address.
+func (srv *Server) SetPeers(peers ...*Server) {
+	entries := make([]string, 0, len(peers))
+	for _, peer := range peers {
+		peer.mutex.Lock()
+		entries = append(entries, "["+peer.name+",,["+peer.Address()+"]]")
+		peer.mutex.Unlock()
+	}
+
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	srv.peers = entries
+	srv.peersGeneration++
+}
+
+// DropInfoRequests makes the server close the connections of the next count
+// info requests instead of answering them.
+func (srv *Server) DropInfoRequests(count int) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	srv.droppedInfoRequests = count
+}
+
+// SetInfoDelay delays the answers to info requests by the given duration.
+func (srv *Server) SetInfoDelay(delay time.Duration) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	srv.infoDelay = delay
+}
+
+// infoFault determines if the next info request is dropped,
+// and how long its answer is delayed.
+func (srv *Server) infoFault() (drop bool, delay time.Duration) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	if srv.droppedInfoRequests > 0 {
+		srv.droppedInfoRequests--
+		return true, 0
+	}
+	return false, srv.infoDelay
+}
+
+// Reset removes all records from all namespaces.
+func (srv *Server) Reset() {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	for name := range srv.namespaces {
+		srv.namespaces[name] = newNamespace()
+	}
+}
+
+// Close stops the server, closes all client connections
+// and waits for the connection handlers to return.
+func (srv *Server) Close() error {
+	srv.mutex.Lock()
+	if srv.closed {
+		srv.mutex.Unlock()
+		return nil
+	}
+	srv.closed = true
+	err := srv.listener.Close()
+	for conn := range srv.conns {
+		conn.Close()
+	}
+	srv.mutex.Unlock()
+
+	srv.wg.Wait()
+	return err
+}
+
+func (srv *Server) serve() {
+	defer srv.wg.Done()
+
+	for {
+		conn, err := srv.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		srv.mutex.Lock()
+		if srv.closed {
+			srv.mutex.Unlock()
+			conn.Close()
+			return
+		}
+		srv.conns[conn] = struct{}{}
+		srv.wg.Add(1)
+		srv.mutex.Unlock()
+
+		go srv.handle(conn)
+	}
+}
+
+func (srv *Server) handle(conn net.Conn) {
+	defer srv.wg.Done()
+	defer func() {
+		srv.mutex.Lock()
+		delete(srv.conns, conn)
+		srv.mutex.Unlock()
+		conn.Close()
+	}()
+
+	header := make([]byte, _PROTO_HEADER_SIZE)
+	for {
+		if _, err := io.ReadFull(conn, header); err != nil {
+			return
+		}
+
+		proto := Buffer.BytesToInt64(header, 0)
+		msgType := byte(proto >> 48)
+		body := make([]byte, proto&0xFFFFFFFFFFFF)
+		if _, err := io.ReadFull(conn, body); err != nil {
+			return
+		}
+
+		var response []byte
+		switch msgType {
+		case byte(MSG_INFO):
+			drop, delay := srv.infoFault()
+			if drop {
+				return
+			}
+			time.Sleep(delay)
+			response = newProtoMessage(int64(MSG_INFO), srv.info(body))
+		case byte(MSG_MESSAGE):
+			response = newProtoMessage(int64(MSG_MESSAGE), srv.command(body))
+		default:
+			return
+		}
+
+		if _, err := conn.Write(response); err != nil {
+			return
+		}
+	}
+}
+
+// info answers an info request. Unknown names are answered with empty values.
+// An empty request returns all known values.
+func (srv *Server) info(body []byte) []byte {
+	names := strings.Split(strings.TrimRight(string(body), "\n"), "\n")
+	if len(names) == 1 && names[0] == "" {
+		names = []string{"node", "build", "partition-generation", "services", "features", "namespaces"}
+	}
+
+	res := make([]byte, 0, 256)
+	for _, name := range names {
+		res = append(res, name...)
+		res = append(res, '\t')
+		res = append(res, srv.infoValue(name)...)
+		res = append(res, '\n')
+	}
+	return res
+}
+
+func (srv *Server) infoValue(name string) string {
+	switch {
+	case name == "node":
+		srv.mutex.Lock()
+		defer srv.mutex.Unlock()
+		return srv.name
+	case name == "cluster-name":
+		srv.mutex.Lock()
+		defer srv.mutex.Unlock()
+		return srv.clusterName
+	case name == "build":
+		return Build
+	case name == "version":
+		return "Aerospike Community Edition build " + Build
+	case name == "partition-generation":
+		return "1"
+	case name == "services", name == "services-alumni":
+		return ""
+	case name == "peers-generation":
+		srv.mutex.Lock()
+		defer srv.mutex.Unlock()
+		return strconv.Itoa(srv.peersGeneration)
+	case name == "peers-clear-std":
+		srv.mutex.Lock()
+		defer srv.mutex.Unlock()
+		return strconv.Itoa(srv.peersGeneration) + ",3000,[" + strings.Join(srv.peers, ",") + "]"
+	case name == "features":
+		srv.mutex.Lock()
+		defer srv.mutex.Unlock()
+		return srv.features
+	case name == "namespaces":
+		return strings.Join(srv.namespaceNames(), ";")
+	case name == "replicas-master":
+		// this node is the master for all partitions of all namespaces
+		bitmap := make([]byte, _PARTITIONS/8)
+		for i := range bitmap {
+			bitmap[i] = 0xFF
+		}
+		encoded := base64.StdEncoding.EncodeToString(bitmap)
+
+		names := srv.namespaceNames()
+		for i := range names {
+			names[i] += ":" + encoded
+		}
+		return strings.Join(names, ";")
+	case strings.HasPrefix(name, "get-config:context=namespace;id="):
+		ns := strings.TrimPrefix(name, "get-config:context=namespace;id=")
+		srv.mutex.Lock()
+		defer srv.mutex.Unlock()
+		if srv.namespaces[ns] == nil {
+			return "ERROR::namespace not found"
+		}
+		return "rack-id=" + strconv.Itoa(srv.rackId)
+	case strings.HasPrefix(name, "namespace/"):
+		ns := strings.TrimPrefix(name, "namespace/")
+		srv.mutex.Lock()
+		defer srv.mutex.Unlock()
+		if n := srv.namespaces[ns]; n != nil {
+			return "objects=" + strconv.Itoa(n.len()) + ";replication-factor=2"
+		}
+		return "type=unknown"
+	case strings.HasPrefix(name, "truncate:"), strings.HasPrefix(name, "truncate-namespace:"):
+		return srv.truncate(name)
+	case strings.HasPrefix(name, "sets/"):
+		ns := strings.TrimPrefix(name, "sets/")
+		srv.mutex.Lock()
+		defer srv.mutex.Unlock()
+		n := srv.namespaces[ns]
+		if n == nil {
+			return ""
+		}
+
+		lens := n.setLens()
+		setNames := make([]string, 0, len(lens))
+		for setName := range lens {
+			if setName != "" {
+				setNames = append(setNames, setName)
+			}
+		}
+		sort.Strings(setNames)
+
+		res := ""
+		for _, setName := range setNames {
+			res += "ns=" + ns + ":set=" + setName + ":objects=" + strconv.Itoa(lens[setName]) + ":tombstones=0;"
+		}
+		return res
+	}
+	return ""
+}
+
+// truncate answers the truncate and truncate-namespace info commands.
+func (srv *Server) truncate(command string) string {
+	params := map[string]string{}
+	for _, param := range strings.Split(strings.SplitN(command, ":", 2)[1], ";") {
+		if kv := strings.SplitN(param, "=", 2); len(kv) == 2 {
+			params[kv[0]] = kv[1]
+		}
+	}
+
+	var before int64
+	if lut, exists := params["lut"]; exists {
+		var err error
+		if before, err = strconv.ParseInt(lut, 10, 64); err != nil {
+			return "ERROR::invalid lut"
+		}
+	}
+
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	ns := srv.namespaces[params["namespace"]]
+	if ns == nil {
+		return "ERROR::namespace not found"
+	}
+	ns.truncate(params["set"], strings.HasPrefix(command, "truncate-namespace:"), before)
+	return "ok"
+}
+
+func (srv *Server) namespaceNames() []string {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	names := make([]string, 0, len(srv.namespaces))
+	for name := range srv.namespaces {
+		names = append(names, name)
+	}
+	return names
+}
+
+// newProtoMessage prepends the 8 byte proto header to the message body.
+func newProtoMessage(msgType int64, body []byte) []byte {
+	buf := make([]byte, _PROTO_HEADER_SIZE+len(body))
+	Buffer.Int64ToBytes(2<<56|msgType<<48|int64(len(body)), buf, 0)
+	copy(buf[_PROTO_HEADER_SIZE:], body)
+	return buf
+}
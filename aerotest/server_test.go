@@ -0,0 +1,196 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerotest_test
+
+import (
+	as "github.com/THE108/aerospike-client-go"
+	"github.com/THE108/aerospike-client-go/aerotest"
+	. "github.com/THE108/aerospike-client-go/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Fake server", func() {
+
+	var srv *aerotest.Server
+	var client *as.Client
+	var key *as.Key
+
+	BeforeEach(func() {
+		var err error
+		client, srv, err = aerotest.NewClientAndServer("test")
+		Expect(err).ToNot(HaveOccurred())
+
+		key, err = as.NewKey("test", "aerotest", "key1")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		client.Close()
+		Expect(srv.Close()).ToNot(HaveOccurred())
+	})
+
+	It("must answer info requests", func() {
+		nodes := client.GetNodes()
+		Expect(len(nodes)).To(Equal(1))
+		Expect(nodes[0].GetName()).To(Equal(aerotest.NodeName))
+
+		info, err := as.RequestNodeInfo(nodes[0], "build", "namespaces")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info["build"]).To(Equal(aerotest.Build))
+		Expect(info["namespaces"]).To(Equal("test"))
+	})
+
+	It("must put, get and delete records", func() {
+		err := client.PutBins(nil, key, as.NewBin("str", "value"), as.NewBin("int", 42), as.NewBin("bytes", []byte{1, 2, 3}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(srv.Len("test")).To(Equal(1))
+
+		rec, err := client.Get(nil, key)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rec.Generation).To(Equal(1))
+		Expect(rec.Bins).To(Equal(as.BinMap{"str": "value", "int": 42, "bytes": []byte{1, 2, 3}}))
+
+		rec, err = client.Get(nil, key, "int")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rec.Bins).To(Equal(as.BinMap{"int": 42}))
+
+		rec, err = client.GetHeader(nil, key)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rec.Generation).To(Equal(1))
+		Expect(len(rec.Bins)).To(Equal(0))
+
+		existed, err := client.Delete(nil, key)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(existed).To(BeTrue())
+
+		existed, err = client.Delete(nil, key)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(existed).To(BeFalse())
+
+		exists, err := client.Exists(nil, key)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(exists).To(BeFalse())
+		Expect(srv.Len("test")).To(Equal(0))
+	})
+
+	It("must apply add, append, prepend and touch", func() {
+		Expect(client.PutBins(nil, key, as.NewBin("int", 1), as.NewBin("str", "b"))).ToNot(HaveOccurred())
+		Expect(client.AddBins(nil, key, as.NewBin("int", 2))).ToNot(HaveOccurred())
+		Expect(client.AppendBins(nil, key, as.NewBin("str", "c"))).ToNot(HaveOccurred())
+		Expect(client.PrependBins(nil, key, as.NewBin("str", "a"))).ToNot(HaveOccurred())
+		Expect(client.Touch(nil, key)).ToNot(HaveOccurred())
+
+		rec, err := client.Get(nil, key)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rec.Generation).To(Equal(5))
+		Expect(rec.Bins).To(Equal(as.BinMap{"int": 3, "str": "abc"}))
+
+		err = client.AddBins(nil, key, as.NewBin("str", 1))
+		Expect(err).To(HaveOccurred())
+		Expect(err.(AerospikeError).ResultCode()).To(Equal(BIN_TYPE_ERROR))
+	})
+
+	It("must return the results of Operate", func() {
+		rec, err := client.Operate(nil, key, as.AddOp(as.NewBin("counter", 5)), as.GetOpForBin("counter"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rec.Bins).To(Equal(as.BinMap{"counter": 5}))
+
+		rec, err = client.Operate(nil, key, as.AddOp(as.NewBin("counter", -2)), as.PutOp(as.NewBin("name", "x")), as.GetOp())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rec.Generation).To(Equal(2))
+		Expect(rec.Bins).To(Equal(as.BinMap{"counter": 3, "name": "x"}))
+	})
+
+	It("must return the ordered results of OperateOrdered", func() {
+		rec, err := client.OperateOrdered(nil, key,
+			as.AddOp(as.NewBin("counter", 1)), as.GetOpForBin("counter"),
+			as.AddOp(as.NewBin("counter", 1)), as.GetOpForBin("counter"),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rec.Bins).To(Equal(as.BinMap{"counter": 2}))
+		Expect(rec.OpResults).To(Equal([]*as.OpResult{
+			{BinName: "counter", Value: 1},
+			{BinName: "counter", Value: 2},
+		}))
+
+		rec, err = client.Operate(nil, key, as.GetOpForBin("counter"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rec.OpResults).To(BeNil())
+	})
+
+	It("must honor record exists actions and generation checks", func() {
+		policy := as.NewWritePolicy(0, 0)
+		policy.RecordExistsAction = as.UPDATE_ONLY
+		err := client.PutBins(policy, key, as.NewBin("a", 1))
+		Expect(err.(AerospikeError).ResultCode()).To(Equal(KEY_NOT_FOUND_ERROR))
+
+		policy.RecordExistsAction = as.CREATE_ONLY
+		Expect(client.PutBins(policy, key, as.NewBin("a", 1))).ToNot(HaveOccurred())
+		err = client.PutBins(policy, key, as.NewBin("a", 1))
+		Expect(err.(AerospikeError).ResultCode()).To(Equal(KEY_EXISTS_ERROR))
+
+		policy.RecordExistsAction = as.REPLACE
+		Expect(client.PutBins(policy, key, as.NewBin("b", 2))).ToNot(HaveOccurred())
+		rec, err := client.Get(nil, key)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rec.Bins).To(Equal(as.BinMap{"b": 2}))
+
+		policy = as.NewWritePolicy(1, 0)
+		policy.GenerationPolicy = as.EXPECT_GEN_EQUAL
+		err = client.PutBins(policy, key, as.NewBin("b", 3))
+		Expect(err.(AerospikeError).ResultCode()).To(Equal(GENERATION_ERROR))
+
+		policy.Generation = int32(rec.Generation)
+		Expect(client.PutBins(policy, key, as.NewBin("b", 3))).ToNot(HaveOccurred())
+	})
+
+	It("must report the record expiration", func() {
+		err := client.PutBins(as.NewWritePolicy(0, 100), key, as.NewBin("a", 1))
+		Expect(err).ToNot(HaveOccurred())
+
+		rec, err := client.GetHeader(nil, key)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rec.Expiration).To(BeNumerically("~", 100, 2))
+	})
+
+	It("must reject unsupported commands", func() {
+		Expect(client.PutBins(nil, key, as.NewBin("a", 1))).ToNot(HaveOccurred())
+
+		_, err := client.Execute(nil, key, "pkg", "fn")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("must serve multiple namespaces and reset", func() {
+		Expect(srv.Close()).ToNot(HaveOccurred())
+		client.Close()
+
+		var err error
+		client, srv, err = aerotest.NewClientAndServer("test", "other")
+		Expect(err).ToNot(HaveOccurred())
+
+		otherKey, _ := as.NewKey("other", "aerotest", "key1")
+		Expect(client.PutBins(nil, key, as.NewBin("a", 1))).ToNot(HaveOccurred())
+		Expect(client.PutBins(nil, otherKey, as.NewBin("a", 2))).ToNot(HaveOccurred())
+		Expect(srv.Len("test")).To(Equal(1))
+		Expect(srv.Len("other")).To(Equal(1))
+
+		srv.Reset()
+		Expect(srv.Len("test")).To(Equal(0))
+		Expect(srv.Len("other")).To(Equal(0))
+	})
+
+})
@@ -0,0 +1,199 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerotest
+
+import (
+	"time"
+
+	. "github.com/THE108/aerospike-client-go/types"
+	ParticleType "github.com/THE108/aerospike-client-go/types/particle_type"
+	Buffer "github.com/THE108/aerospike-client-go/utils/buffer"
+)
+
+// particle is a bin value in its wire encoding.
+type particle struct {
+	particleType byte
+	data         []byte
+}
+
+type record struct {
+	setName    string
+	bins       map[string]particle
+	generation uint32
+	voidTime   uint32 // seconds since citrusleaf epoch, 0 for never
+	lastUpdate int64  // nanoseconds since the Unix epoch
+}
+
+// clone returns a copy of the record which can be changed independently.
+// Cloning a nil record returns a new empty record.
+func (rec *record) clone() *record {
+	res := &record{bins: map[string]particle{}}
+	if rec == nil {
+		return res
+	}
+
+	res.setName = rec.setName
+	res.generation = rec.generation
+	res.voidTime = rec.voidTime
+	res.lastUpdate = rec.lastUpdate
+	for name, value := range rec.bins {
+		res.bins[name] = value
+	}
+	return res
+}
+
+func (rec *record) allBins() []operation {
+	res := make([]operation, 0, len(rec.bins))
+	for _, name := range sortedBinNames(rec.bins) {
+		res = append(res, operation{opType: _OP_READ, binName: name, value: rec.bins[name]})
+	}
+	return res
+}
+
+// readBin returns the named bin, or all bins if the name is empty.
+func (rec *record) readBin(name string) []operation {
+	if name == "" {
+		return rec.allBins()
+	}
+
+	if value, exists := rec.bins[name]; exists {
+		return []operation{{opType: _OP_READ, binName: name, value: value}}
+	}
+	return nil
+}
+
+func (rec *record) add(name string, incr particle) ResultCode {
+	if incr.particleType != ParticleType.INTEGER || len(incr.data) > 8 {
+		return PARAMETER_ERROR
+	}
+
+	var current int64
+	if value, exists := rec.bins[name]; exists {
+		if value.particleType != ParticleType.INTEGER {
+			return BIN_TYPE_ERROR
+		}
+		current = Buffer.VarBytesToInt64(value.data, 0, len(value.data))
+	}
+
+	sum := current + Buffer.VarBytesToInt64(incr.data, 0, len(incr.data))
+	rec.bins[name] = particle{particleType: ParticleType.INTEGER, data: Buffer.Int64ToBytes(sum, nil, 0)}
+	return OK
+}
+
+func (rec *record) concat(name string, suffix particle, prepend bool) ResultCode {
+	if suffix.particleType != ParticleType.STRING && suffix.particleType != ParticleType.BLOB {
+		return PARAMETER_ERROR
+	}
+
+	value, exists := rec.bins[name]
+	if !exists {
+		rec.bins[name] = suffix
+		return OK
+	}
+
+	if value.particleType != suffix.particleType {
+		return BIN_TYPE_ERROR
+	}
+
+	data := make([]byte, 0, len(value.data)+len(suffix.data))
+	if prepend {
+		data = append(append(data, suffix.data...), value.data...)
+	} else {
+		data = append(append(data, value.data...), suffix.data...)
+	}
+	rec.bins[name] = particle{particleType: value.particleType, data: data}
+	return OK
+}
+
+// namespace holds the records of a namespace by digest.
+// It is guarded by the server mutex.
+type namespace struct {
+	records map[[20]byte]*record
+}
+
+func newNamespace() *namespace {
+	return &namespace{records: map[[20]byte]*record{}}
+}
+
+// get returns the record for the digest, or nil if it does not exist or has expired.
+func (ns *namespace) get(digest [20]byte) *record {
+	rec := ns.records[digest]
+	if rec != nil && rec.expired(sinceCitrusleafEpoch()) {
+		delete(ns.records, digest)
+		return nil
+	}
+	return rec
+}
+
+func (ns *namespace) put(digest [20]byte, rec *record) {
+	ns.records[digest] = rec
+}
+
+func (ns *namespace) delete(digest [20]byte) {
+	delete(ns.records, digest)
+}
+
+// len returns the number of records which have not expired.
+func (ns *namespace) len() int {
+	now := sinceCitrusleafEpoch()
+	count := 0
+	for _, rec := range ns.records {
+		if !rec.expired(now) {
+			count++
+		}
+	}
+	return count
+}
+
+// setLens returns the number of records which have not expired by set name.
+func (ns *namespace) setLens() map[string]int {
+	now := sinceCitrusleafEpoch()
+	res := map[string]int{}
+	for _, rec := range ns.records {
+		if !rec.expired(now) {
+			res[rec.setName]++
+		}
+	}
+	return res
+}
+
+// truncate deletes the records of the set, or of all sets if truncateAll is
+// set, last updated before the time in nanoseconds; 0 deletes all records.
+func (ns *namespace) truncate(setName string, truncateAll bool, before int64) {
+	for digest, rec := range ns.records {
+		if (truncateAll || rec.setName == setName) && (before == 0 || rec.lastUpdate < before) {
+			delete(ns.records, digest)
+		}
+	}
+}
+
+// setTTL sets the void time of the record from the TTL of a write command.
+func (rec *record) setTTL(ttl uint32) {
+	switch ttl {
+	case _TTL_DONT_UPDATE:
+	case 0, _TTL_NEVER_EXPIRE:
+		rec.voidTime = 0
+	default:
+		rec.voidTime = sinceCitrusleafEpoch() + ttl
+	}
+}
+
+func (rec *record) expired(now uint32) bool {
+	return rec.voidTime != 0 && rec.voidTime <= now
+}
+
+func sinceCitrusleafEpoch() uint32 {
+	return uint32(time.Now().Unix() - CITRUSLEAF_EPOCH)
+}
@@ -0,0 +1,306 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerotest
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	. "github.com/THE108/aerospike-client-go/types"
+	ParticleType "github.com/THE108/aerospike-client-go/types/particle_type"
+	Buffer "github.com/THE108/aerospike-client-go/utils/buffer"
+)
+
+// UDF is a record UDF the fake server runs in place of a Lua function.
+// It is called with the bins of the record, which are empty if the record
+// does not exist, and the arguments of the call. It may change the bins;
+// setting a bin to nil deletes it. Bin values, arguments and results are
+// int64, string, []byte or nil.
+// After a successful call the bins are written back to the record, which is
+// deleted if no bins remain. An error fails the call with UDF_BAD_RESPONSE,
+// and the error message is returned to the client.
+// UDFs are called with the server locked, and must not call the server.
+type UDF func(bins map[string]interface{}, args []interface{}) (interface{}, error)
+
+// udfCall is the UDF of a command and its msgpack encoded argument list.
+type udfCall struct {
+	packageName  string
+	functionName string
+	args         []byte
+}
+
+// setUDFField sets the UDF field on the call, creating the call if needed.
+// It returns false if the field is not a UDF field.
+func setUDFField(call **udfCall, fieldType byte, data []byte) bool {
+	switch fieldType {
+	case _FIELD_UDF_PACKAGE_NAME, _FIELD_UDF_FUNCTION, _FIELD_UDF_ARGLIST:
+	default:
+		return false
+	}
+
+	if *call == nil {
+		*call = &udfCall{}
+	}
+
+	switch fieldType {
+	case _FIELD_UDF_PACKAGE_NAME:
+		(*call).packageName = string(data)
+	case _FIELD_UDF_FUNCTION:
+		(*call).functionName = string(data)
+	case _FIELD_UDF_ARGLIST:
+		(*call).args = append([]byte(nil), data...)
+	}
+	return true
+}
+
+// RegisterUDF registers the UDF under the package and function name clients
+// call it with. Registering a function again replaces it.
+func (srv *Server) RegisterUDF(packageName, functionName string, udf UDF) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	srv.udfs[packageName+"."+functionName] = udf
+}
+
+// executeUDF applies the UDF call to the record of the digest, and returns
+// the result code, the updated record and the result bin of the call.
+// It must be called with the server locked.
+func (srv *Server) executeUDF(ns *namespace, digest [20]byte, setName string, call *udfCall, ttl uint32) (ResultCode, *record, []operation) {
+	udf := srv.udfs[call.packageName+"."+call.functionName]
+	if udf == nil {
+		return udfFailure("function not found")
+	}
+
+	args, err := unpackArgs(call.args)
+	if err != nil {
+		return PARAMETER_ERROR, nil, nil
+	}
+
+	rec := ns.get(digest)
+	bins := map[string]interface{}{}
+	if rec != nil {
+		for name, value := range rec.bins {
+			v, err := particleValue(value)
+			if err != nil {
+				return udfFailure(err.Error())
+			}
+			bins[name] = v
+		}
+	}
+
+	result, err := udf(bins, args)
+	if err != nil {
+		return udfFailure(err.Error())
+	}
+
+	resultParticle, err := newParticle(result)
+	if err != nil {
+		return udfFailure(err.Error())
+	}
+
+	updated := rec.clone()
+	if rec == nil {
+		updated.setName = setName
+	}
+	updated.bins = make(map[string]particle, len(bins))
+	for name, v := range bins {
+		if v == nil {
+			continue
+		}
+		if updated.bins[name], err = newParticle(v); err != nil {
+			return udfFailure(err.Error())
+		}
+	}
+
+	if len(updated.bins) == 0 {
+		ns.delete(digest)
+		updated = nil
+	} else {
+		updated.generation++
+		updated.lastUpdate = time.Now().UnixNano()
+		updated.setTTL(ttl)
+		ns.put(digest, updated)
+	}
+
+	return OK, updated, []operation{{opType: _OP_READ, binName: "SUCCESS", value: resultParticle}}
+}
+
+// udfFailure returns the response of a failed UDF call.
+func udfFailure(message string) (ResultCode, *record, []operation) {
+	value := particle{particleType: ParticleType.STRING, data: []byte(message)}
+	return UDF_BAD_RESPONSE, nil, []operation{{opType: _OP_READ, binName: "FAILURE", value: value}}
+}
+
+var errUnsupportedType = errors.New("unsupported value type")
+
+// particleValue returns the Go value of the particle.
+func particleValue(p particle) (interface{}, error) {
+	switch p.particleType {
+	case ParticleType.NULL:
+		return nil, nil
+	case ParticleType.INTEGER:
+		return Buffer.VarBytesToInt64(p.data, 0, len(p.data)), nil
+	case ParticleType.STRING:
+		return string(p.data), nil
+	case ParticleType.BLOB:
+		return append([]byte(nil), p.data...), nil
+	}
+	return nil, errUnsupportedType
+}
+
+// newParticle returns the particle of the Go value.
+func newParticle(v interface{}) (particle, error) {
+	switch v := v.(type) {
+	case nil:
+		return particle{particleType: ParticleType.NULL}, nil
+	case int:
+		return newParticle(int64(v))
+	case int64:
+		return particle{particleType: ParticleType.INTEGER, data: Buffer.Int64ToBytes(v, nil, 0)}, nil
+	case string:
+		return particle{particleType: ParticleType.STRING, data: []byte(v)}, nil
+	case []byte:
+		return particle{particleType: ParticleType.BLOB, data: append([]byte(nil), v...)}, nil
+	}
+	return particle{}, errUnsupportedType
+}
+
+// unpackArgs decodes the msgpack encoded argument list of a UDF call.
+// Only the types a UDF can be passed are supported.
+func unpackArgs(data []byte) ([]interface{}, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	u := &unpacker{data: data}
+	var count int
+	switch b := u.byte(); {
+	case b&0xf0 == 0x90:
+		count = int(b & 0x0f)
+	case b == 0xdc:
+		count = int(u.uint(2))
+	case b == 0xdd:
+		count = int(u.uint(4))
+	default:
+		return nil, errUnsupportedType
+	}
+
+	args := make([]interface{}, 0, count)
+	for i := 0; i < count && u.err == nil; i++ {
+		args = append(args, u.value())
+	}
+	return args, u.err
+}
+
+// unpacker decodes the scalar msgpack values of UDF arguments. Strings and
+// blobs are msgpack raw values prefixed with their particle type.
+type unpacker struct {
+	data   []byte
+	offset int
+	err    error
+}
+
+var errShortBuffer = errors.New("msgpack value exceeds the buffer")
+
+func (u *unpacker) bytes(n int) []byte {
+	if u.err != nil || u.offset+n > len(u.data) {
+		u.err = errShortBuffer
+		return make([]byte, n)
+	}
+	b := u.data[u.offset : u.offset+n]
+	u.offset += n
+	return b
+}
+
+func (u *unpacker) byte() byte {
+	return u.bytes(1)[0]
+}
+
+func (u *unpacker) uint(n int) uint64 {
+	var v uint64
+	for _, b := range u.bytes(n) {
+		v = v<<8 | uint64(b)
+	}
+	return v
+}
+
+func (u *unpacker) value() interface{} {
+	b := u.byte()
+	switch {
+	case b < 0x80:
+		return int64(b)
+	case b >= 0xe0:
+		return int64(int8(b))
+	case b == 0xc0:
+		return nil
+	case b&0xe0 == 0xa0:
+		return u.raw(int(b & 0x1f))
+	}
+
+	switch b {
+	case 0xcc:
+		return int64(u.uint(1))
+	case 0xcd:
+		return int64(u.uint(2))
+	case 0xce:
+		return int64(u.uint(4))
+	case 0xcf:
+		v := u.uint(8)
+		if v > math.MaxInt64 && u.err == nil {
+			u.err = errUnsupportedType
+		}
+		return int64(v)
+	case 0xd0:
+		return int64(int8(u.uint(1)))
+	case 0xd1:
+		return int64(int16(u.uint(2)))
+	case 0xd2:
+		return int64(int32(u.uint(4)))
+	case 0xd3:
+		return int64(u.uint(8))
+	case 0xd9, 0xc4:
+		return u.raw(int(u.uint(1)))
+	case 0xda, 0xc5:
+		return u.raw(int(u.uint(2)))
+	case 0xdb, 0xc6:
+		return u.raw(int(u.uint(4)))
+	}
+
+	if u.err == nil {
+		u.err = errUnsupportedType
+	}
+	return nil
+}
+
+// raw decodes a raw value of the given size, prefixed with its particle type.
+func (u *unpacker) raw(size int) interface{} {
+	if size == 0 {
+		return ""
+	}
+
+	data := u.bytes(size)
+	switch int(data[0]) {
+	case ParticleType.STRING:
+		return string(data[1:])
+	case ParticleType.BLOB:
+		return append([]byte(nil), data[1:]...)
+	}
+
+	if u.err == nil {
+		u.err = errUnsupportedType
+	}
+	return nil
+}
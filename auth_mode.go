@@ -0,0 +1,34 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+// AuthMode determines how the client authenticates with the server.
+type AuthMode int
+
+const (
+	// AuthModeInternal uses the users and roles defined in the cluster.
+	// The password is sent to the server in hashed format.
+	AuthModeInternal AuthMode = iota
+
+	// AuthModeExternal uses an external authentication service like LDAP.
+	// The password is sent to the server in clear text as well, so it
+	// requires TLS.
+	AuthModeExternal
+
+	// AuthModePKI authenticates the client with the certificate of the
+	// TLS connection. User and password are not used; it requires TLS
+	// with a client certificate.
+	AuthModePKI
+)
@@ -0,0 +1,440 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	. "github.com/THE108/aerospike-client-go/types"
+	ParticleType "github.com/THE108/aerospike-client-go/types/particle_type"
+)
+
+// BackupFormat determines the format of backup files.
+type BackupFormat int
+
+const (
+	// BACKUP_ASB is the text format of the asbackup tool, version 3.1.
+	BACKUP_ASB BackupFormat = iota
+
+	// BACKUP_NDJSON writes one JSON object per record and line.
+	// Blobs are encoded as {"$blob": "<base64>"}, and maps with non-string
+	// keys as {"$map": [[key, value], ...]}.
+	BACKUP_NDJSON
+)
+
+// BackupPolicy encapsulates parameters used in backup operations.
+// Set MaxConcurrentNodes to limit the number of nodes scanned in parallel.
+type BackupPolicy struct {
+	ScanPolicy
+
+	// Format determines the format of the backup. Default is BACKUP_ASB.
+	Format BackupFormat
+
+	// RecordsPerSecond throttles the backup to a maximum number of records
+	// per second. Default (0) is not to throttle.
+	RecordsPerSecond int
+}
+
+// NewBackupPolicy generates a new BackupPolicy instance with default values.
+func NewBackupPolicy() *BackupPolicy {
+	return &BackupPolicy{
+		ScanPolicy: *NewScanPolicy(),
+		Format:     BACKUP_ASB,
+	}
+}
+
+// Clone returns a deep copy of the policy, including its ScanPolicy.
+func (p *BackupPolicy) Clone() *BackupPolicy {
+	res := *p
+	res.ScanPolicy = *p.ScanPolicy.Clone()
+	return &res
+}
+
+// BackupStats contains the statistics of a finished backup.
+type BackupStats struct {
+	Records  int64
+	Bytes    int64
+	Duration time.Duration
+}
+
+// Backup scans the namespace and set, and writes the records to the writer in
+// the format determined by the policy. If the set name is empty, the whole
+// namespace is backed up. If no bin names are passed, all bins are written.
+// Writes to the writer are serialized.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *Client) Backup(policy *BackupPolicy, w io.Writer, namespace string, setName string, binNames ...string) (*BackupStats, error) {
+	if policy == nil {
+		policy = NewBackupPolicy()
+	}
+
+	nodes := clnt.cluster.GetNodes()
+	if len(nodes) == 0 {
+		return nil, NewAerospikeError(SERVER_NOT_AVAILABLE, "Backup failed because cluster is empty.")
+	}
+
+	encoder, err := newBackupEncoder(policy.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	bw := &backupWriter{w: w}
+	if err := bw.write(encoder.header(namespace)); err != nil {
+		return nil, err
+	}
+
+	parallel := policy.MaxConcurrentNodes
+	if parallel <= 0 || parallel > len(nodes) {
+		parallel = len(nodes)
+	}
+	sem := make(chan struct{}, parallel)
+	limiter := newThrottle(policy.RecordsPerSecond)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, len(nodes))
+	for _, node := range nodes {
+		wg.Add(1)
+		go func(node *Node) {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			if err := clnt.backupNode(policy, node, namespace, setName, binNames, encoder, bw, limiter); err != nil {
+				errs <- err
+			}
+		}(node)
+	}
+	wg.Wait()
+	close(errs)
+
+	var errList []error
+	for err := range errs {
+		errList = append(errList, err)
+	}
+
+	stats := &BackupStats{
+		Records:  atomic.LoadInt64(&bw.records),
+		Bytes:    atomic.LoadInt64(&bw.bytes),
+		Duration: time.Since(start),
+	}
+	return stats, mergeErrors(errList)
+}
+
+func (clnt *Client) backupNode(policy *BackupPolicy, node *Node, namespace, setName string, binNames []string, encoder backupEncoder, bw *backupWriter, limiter *throttle) error {
+	recordset, err := clnt.ScanNode(&policy.ScanPolicy, node, namespace, setName, binNames...)
+	if err != nil {
+		return err
+	}
+
+	results := recordset.Results()
+	abort := func(err error) error {
+		// drain the results so the scan goroutines can finish
+		go func() {
+			for range results {
+			}
+		}()
+		recordset.Close()
+		return err
+	}
+
+	var buf bytes.Buffer
+	for res := range results {
+		if res.Err != nil {
+			return abort(res.Err)
+		}
+
+		limiter.wait()
+
+		buf.Reset()
+		if err := encoder.encode(&buf, res.Record); err != nil {
+			return abort(err)
+		}
+		if err := bw.writeRecord(buf.Bytes()); err != nil {
+			return abort(err)
+		}
+	}
+	return nil
+}
+
+// backupWriter serializes writes to the underlying writer.
+type backupWriter struct {
+	mutex   sync.Mutex
+	w       io.Writer
+	records int64
+	bytes   int64
+}
+
+func (bw *backupWriter) write(b []byte) error {
+	bw.mutex.Lock()
+	defer bw.mutex.Unlock()
+
+	n, err := bw.w.Write(b)
+	atomic.AddInt64(&bw.bytes, int64(n))
+	return err
+}
+
+func (bw *backupWriter) writeRecord(b []byte) error {
+	if err := bw.write(b); err != nil {
+		return err
+	}
+	atomic.AddInt64(&bw.records, 1)
+	return nil
+}
+
+// throttle limits the rate of events. A nil throttle does not limit.
+type throttle struct {
+	mutex    sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func newThrottle(perSecond int) *throttle {
+	if perSecond <= 0 {
+		return nil
+	}
+	return &throttle{interval: time.Second / time.Duration(perSecond)}
+}
+
+// wait blocks until the next event is allowed.
+func (t *throttle) wait() {
+	if t == nil {
+		return
+	}
+
+	t.mutex.Lock()
+	now := time.Now()
+	if t.next.Before(now) {
+		t.next = now
+	}
+	delay := t.next.Sub(now)
+	t.next = t.next.Add(t.interval)
+	t.mutex.Unlock()
+
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+}
+
+type backupEncoder interface {
+	header(namespace string) []byte
+	encode(buf *bytes.Buffer, rec *Record) error
+}
+
+func newBackupEncoder(format BackupFormat) (backupEncoder, error) {
+	switch format {
+	case BACKUP_ASB:
+		return asbEncoder{}, nil
+	case BACKUP_NDJSON:
+		return ndjsonEncoder{}, nil
+	}
+	return nil, NewAerospikeError(PARAMETER_ERROR, fmt.Sprintf("Invalid backup format: %d", format))
+}
+
+// recordVoidTime returns the expiration of the record in seconds
+// since citrusleaf epoch, or 0 if the record never expires.
+func recordVoidTime(rec *Record) int64 {
+	if rec.Expiration == 0 {
+		return 0
+	}
+	return time.Now().Unix() - CITRUSLEAF_EPOCH + int64(rec.Expiration)
+}
+
+func sortedBinNames(bins BinMap) []string {
+	names := make([]string, 0, len(bins))
+	for name := range bins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// valueBytes returns the value in wire protocol format.
+func valueBytes(v Value) ([]byte, error) {
+	buf := make([]byte, v.estimateSize())
+	n, err := v.write(buf, 0)
+	if err != nil {
+		return nil, err
+	}
+	return buf[:n], nil
+}
+
+// asbEncoder writes records in asbackup's text format.
+//
+//	Version 3.1
+//	# namespace test
+//	# first-file
+//	+ k S 4 key1
+//	+ n test
+//	+ d <base64 digest>
+//	+ s demo
+//	+ g 1
+//	+ t 0
+//	+ b 2
+//	- I count 10
+//	- S name 4 John
+type asbEncoder struct{}
+
+var asbEscaper = strings.NewReplacer(`\`, `\\`, " ", `\ `, "\n", "\\\n")
+
+func (asbEncoder) header(namespace string) []byte {
+	return []byte("Version 3.1\n# namespace " + asbEscaper.Replace(namespace) + "\n# first-file\n")
+}
+
+func (asbEncoder) encode(buf *bytes.Buffer, rec *Record) error {
+	key := rec.Key
+
+	if userKey := key.Value(); userKey != nil {
+		switch userKey.GetType() {
+		case ParticleType.INTEGER:
+			fmt.Fprintf(buf, "+ k I %v\n", userKey.GetObject())
+		case ParticleType.STRING:
+			s := userKey.String()
+			fmt.Fprintf(buf, "+ k S %d %s\n", len(s), s)
+		case ParticleType.BLOB:
+			b, err := valueBytes(userKey)
+			if err != nil {
+				return err
+			}
+			s := base64.StdEncoding.EncodeToString(b)
+			fmt.Fprintf(buf, "+ k B %d %s\n", len(s), s)
+		}
+	}
+
+	fmt.Fprintf(buf, "+ n %s\n", asbEscaper.Replace(key.namespace))
+	fmt.Fprintf(buf, "+ d %s\n", base64.StdEncoding.EncodeToString(key.digest))
+	if key.setName != "" {
+		fmt.Fprintf(buf, "+ s %s\n", asbEscaper.Replace(key.setName))
+	}
+	fmt.Fprintf(buf, "+ g %d\n", rec.Generation)
+	fmt.Fprintf(buf, "+ t %d\n", recordVoidTime(rec))
+	fmt.Fprintf(buf, "+ b %d\n", len(rec.Bins))
+
+	for _, name := range sortedBinNames(rec.Bins) {
+		escaped := asbEscaper.Replace(name)
+		value := NewValue(rec.Bins[name])
+
+		switch value.GetType() {
+		case ParticleType.NULL:
+			fmt.Fprintf(buf, "- N %s\n", escaped)
+		case ParticleType.INTEGER:
+			fmt.Fprintf(buf, "- I %s %v\n", escaped, value.GetObject())
+		case ParticleType.STRING:
+			s := value.String()
+			fmt.Fprintf(buf, "- S %s %d %s\n", escaped, len(s), s)
+		default:
+			var binType string
+			switch value.GetType() {
+			case ParticleType.BLOB:
+				binType = "B"
+			case ParticleType.LIST:
+				binType = "L"
+			case ParticleType.MAP:
+				binType = "M"
+			default:
+				return NewAerospikeError(TYPE_NOT_SUPPORTED, fmt.Sprintf("Bin `%s` has a type not supported by backups: %d", name, value.GetType()))
+			}
+
+			b, err := valueBytes(value)
+			if err != nil {
+				return err
+			}
+			s := base64.StdEncoding.EncodeToString(b)
+			fmt.Fprintf(buf, "- %s %s %d %s\n", binType, escaped, len(s), s)
+		}
+	}
+	return nil
+}
+
+// ndjsonEncoder writes one JSON object per record and line.
+type ndjsonEncoder struct{}
+
+type ndjsonRecord struct {
+	Namespace  string                 `json:"namespace"`
+	Set        string                 `json:"set,omitempty"`
+	Digest     []byte                 `json:"digest"`
+	Key        interface{}            `json:"key,omitempty"`
+	Generation int                    `json:"generation"`
+	VoidTime   int64                  `json:"void_time"`
+	Bins       map[string]interface{} `json:"bins"`
+}
+
+func (ndjsonEncoder) header(namespace string) []byte {
+	return nil
+}
+
+func (ndjsonEncoder) encode(buf *bytes.Buffer, rec *Record) error {
+	r := ndjsonRecord{
+		Namespace:  rec.Key.namespace,
+		Set:        rec.Key.setName,
+		Digest:     rec.Key.digest,
+		Generation: rec.Generation,
+		VoidTime:   recordVoidTime(rec),
+		Bins:       make(map[string]interface{}, len(rec.Bins)),
+	}
+
+	if userKey := rec.Key.Value(); userKey != nil {
+		r.Key = toJSONValue(userKey.GetObject())
+	}
+	for name, value := range rec.Bins {
+		r.Bins[name] = toJSONValue(value)
+	}
+
+	// Encode appends a new line
+	return json.NewEncoder(buf).Encode(&r)
+}
+
+// toJSONValue converts bin values to values which can be encoded to JSON
+// without loss of type information.
+func toJSONValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case []byte:
+		return map[string]interface{}{"$blob": val}
+	case []interface{}:
+		res := make([]interface{}, len(val))
+		for i := range val {
+			res[i] = toJSONValue(val[i])
+		}
+		return res
+	case map[interface{}]interface{}:
+		strMap := make(map[string]interface{}, len(val))
+		for k, v := range val {
+			s, ok := k.(string)
+			if !ok || strings.HasPrefix(s, "$") {
+				return mapToJSONPairs(val)
+			}
+			strMap[s] = toJSONValue(v)
+		}
+		return strMap
+	}
+	return v
+}
+
+func mapToJSONPairs(m map[interface{}]interface{}) interface{} {
+	pairs := make([]interface{}, 0, len(m))
+	for k, v := range m {
+		pairs = append(pairs, []interface{}{toJSONValue(k), toJSONValue(v)})
+	}
+	return map[string]interface{}{"$map": pairs}
+}
@@ -0,0 +1,135 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	. "github.com/THE108/aerospike-client-go/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Backup Test", func() {
+
+	var key *Key
+
+	BeforeEach(func() {
+		key, _ = NewKey("test", "demo", "key1")
+	})
+
+	It("should encode records in asb format", func() {
+		rec := newRecord(nil, key, BinMap{
+			"count": 10,
+			"name":  "John Doe",
+			"blob":  []byte{1, 2, 3},
+			"my 1":  nil,
+		}, 3, 0)
+
+		var buf bytes.Buffer
+		Expect(asbEncoder{}.encode(&buf, rec)).ToNot(HaveOccurred())
+
+		digest := base64.StdEncoding.EncodeToString(key.Digest())
+		Expect(buf.String()).To(Equal(strings.Join([]string{
+			"+ k S 4 key1",
+			"+ n test",
+			"+ d " + digest,
+			"+ s demo",
+			"+ g 3",
+			"+ t 0",
+			"+ b 4",
+			"- B blob 4 AQID",
+			"- I count 10",
+			`- N my\ 1`,
+			"- S name 8 John Doe",
+			"",
+		}, "\n")))
+	})
+
+	It("should encode lists and maps in asb format as msgpack", func() {
+		list := []interface{}{1, "a"}
+		rec := newRecord(nil, key, BinMap{"list": list}, 1, 0)
+
+		var buf bytes.Buffer
+		Expect(asbEncoder{}.encode(&buf, rec)).ToNot(HaveOccurred())
+
+		packed, err := valueBytes(NewValue(list))
+		Expect(err).ToNot(HaveOccurred())
+		encoded := base64.StdEncoding.EncodeToString(packed)
+		Expect(strings.Contains(buf.String(), "- L list "+strconv.Itoa(len(encoded))+" "+encoded+"\n")).To(BeTrue())
+	})
+
+	It("should write the void time of expiring records", func() {
+		rec := newRecord(nil, key, BinMap{}, 1, 100)
+		voidTime := recordVoidTime(rec)
+		expected := time.Now().Unix() - CITRUSLEAF_EPOCH + 100
+		Expect(voidTime).To(BeNumerically("~", expected, 1))
+
+		rec = newRecord(nil, key, BinMap{}, 1, 0)
+		Expect(recordVoidTime(rec)).To(Equal(int64(0)))
+
+		// expired records keep their void time, and are skipped on restore
+		rec = newRecord(nil, key, BinMap{}, 1, -1000)
+		Expect(recordVoidTime(rec)).To(BeNumerically("~", expected-1100, 1))
+	})
+
+	It("should encode records as NDJSON", func() {
+		rec := newRecord(nil, key, BinMap{
+			"blob": []byte{1, 2, 3},
+			"map":  map[interface{}]interface{}{1: "a"},
+			"obj":  map[interface{}]interface{}{"a": 1},
+		}, 2, 0)
+
+		var buf bytes.Buffer
+		Expect(ndjsonEncoder{}.encode(&buf, rec)).ToNot(HaveOccurred())
+		Expect(strings.Count(buf.String(), "\n")).To(Equal(1))
+
+		var res map[string]interface{}
+		Expect(json.Unmarshal(buf.Bytes(), &res)).ToNot(HaveOccurred())
+		Expect(res["namespace"]).To(Equal("test"))
+		Expect(res["set"]).To(Equal("demo"))
+		Expect(res["key"]).To(Equal("key1"))
+		Expect(res["generation"]).To(Equal(float64(2)))
+		Expect(res["digest"]).To(Equal(base64.StdEncoding.EncodeToString(key.Digest())))
+
+		bins := res["bins"].(map[string]interface{})
+		Expect(bins["blob"]).To(Equal(map[string]interface{}{"$blob": "AQID"}))
+		Expect(bins["map"]).To(Equal(map[string]interface{}{"$map": []interface{}{[]interface{}{float64(1), "a"}}}))
+		Expect(bins["obj"]).To(Equal(map[string]interface{}{"a": float64(1)}))
+	})
+
+	It("should reject unknown formats", func() {
+		_, err := newBackupEncoder(BackupFormat(100))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should throttle events", func() {
+		Expect(newThrottle(0)).To(BeNil())
+
+		t := newThrottle(100)
+		start := time.Now()
+		for i := 0; i < 11; i++ {
+			t.wait()
+		}
+		Expect(time.Since(start)).To(BeNumerically(">=", 100*time.Millisecond))
+	})
+
+})
@@ -0,0 +1,107 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Baggage is per-call metadata, like a tenant ID or a request class,
+// that is attached to a command through its policy's Context.
+// It is reported to ClientPolicy.CommandObserver and written to debug logs.
+type Baggage map[string]string
+
+type baggageKey struct{}
+
+// WithBaggage returns a copy of ctx carrying the given baggage, merged with
+// any baggage already attached to ctx. Values in b take precedence.
+func WithBaggage(ctx context.Context, b Baggage) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	merged := Baggage{}
+	for k, v := range BaggageFromContext(ctx) {
+		merged[k] = v
+	}
+	for k, v := range b {
+		merged[k] = v
+	}
+
+	return context.WithValue(ctx, baggageKey{}, merged)
+}
+
+// BaggageFromContext returns the baggage attached to ctx, or nil.
+func BaggageFromContext(ctx context.Context) Baggage {
+	if ctx == nil {
+		return nil
+	}
+
+	b, _ := ctx.Value(baggageKey{}).(Baggage)
+	return b
+}
+
+// String returns the baggage as a sorted list of key=value pairs.
+func (b Baggage) String() string {
+	keys := make([]string, 0, len(b))
+	for k := range b {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = k + "=" + b[k]
+	}
+	return strings.Join(pairs, ",")
+}
+
+// CommandEvent describes a finished command, and is passed
+// to ClientPolicy.CommandObserver and ClientPolicy.SlowCommandHandler.
+type CommandEvent struct {
+	// Command is the name of the command, eg: read, write, operate.
+	Command string
+
+	// Key is the key of single record commands, or nil.
+	Key *Key
+
+	// Node is the last node the command was sent to.
+	Node *Node
+
+	// Duration is the total time spent, including retries.
+	Duration time.Duration
+
+	// Iterations is the number of attempts made.
+	Iterations int
+
+	// Err is the error returned to the caller, if any.
+	Err error
+
+	// Baggage is the metadata attached to the policy's Context.
+	Baggage Baggage
+}
+
+// commandName returns a short name for the command, eg: *aerospike.readCommand -> read
+func commandName(ifc command) string {
+	name := fmt.Sprintf("%T", ifc)
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	return strings.TrimSuffix(name, "Command")
+}
@@ -0,0 +1,41 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Baggage Test", func() {
+
+	It("should merge baggage attached to a context", func() {
+		ctx := WithBaggage(context.Background(), Baggage{"tenant": "a", "class": "ui"})
+		ctx = WithBaggage(ctx, Baggage{"tenant": "b"})
+
+		Expect(BaggageFromContext(ctx)).To(Equal(Baggage{"tenant": "b", "class": "ui"}))
+		Expect(BaggageFromContext(ctx).String()).To(Equal("class=ui,tenant=b"))
+		Expect(BaggageFromContext(nil)).To(BeNil())
+		Expect(BaggageFromContext(context.Background())).To(BeNil())
+	})
+
+	It("should name commands", func() {
+		Expect(commandName(&readCommand{})).To(Equal("read"))
+		Expect(commandName(&batchCommandGet{})).To(Equal("batchCommandGet"))
+	})
+
+})
@@ -16,6 +16,7 @@ package aerospike
 
 import (
 	"fmt"
+	"time"
 
 	. "github.com/THE108/aerospike-client-go/types"
 	// . "github.com/THE108/aerospike-client-go/types/atomic"
@@ -106,6 +107,30 @@ func (cmd *baseMultiCommand) parseKey(fieldCount int) (*Key, error) {
 	return &Key{namespace: namespace, setName: setName, digest: digest, userKey: userKey}, nil
 }
 
+// sendRecord sends the record to the recordset.
+// If the consumer lags and the record queue is full, the command stops reading
+// from the socket until there is room in the queue. The server cannot send more
+// data than the socket buffers hold in the meantime, so it is throttled to the
+// pace of the consumer, and memory use remains bounded.
+// The time spent waiting for the consumer does not count towards the timeout.
+func (cmd *baseMultiCommand) sendRecord(rec *Record) error {
+	select {
+	case cmd.recordset.Records <- rec:
+		return nil
+	default:
+	}
+
+	// If the channel is full and it blocks, we don't want this command to
+	// block forever, or panic in case the channel is closed in the meantime.
+	start := time.Now()
+	select {
+	case cmd.recordset.Records <- rec:
+		return cmd.conn.extendDeadline(time.Since(start))
+	case <-cmd.recordset.cancelled:
+		return NewAerospikeError(SCAN_TERMINATED)
+	}
+}
+
 func (cmd *baseMultiCommand) readBytes(length int) error {
 	if length > len(cmd.dataBuffer) {
 		// Corrupted data streams can result in a huge length.
@@ -30,6 +30,9 @@ type batchCommandExists struct {
 	keys           []*Key
 	existsArray    []bool
 	index          int
+
+	// batchIndex is set if the node is sent the batch-index protocol
+	batchIndex bool
 }
 
 func newBatchCommandExists(
@@ -45,6 +48,7 @@ func newBatchCommandExists(
 		policy:           policy,
 		keys:             keys,
 		existsArray:      existsArray,
+		batchIndex:       node.supportsBatchIndex(),
 	}
 }
 
@@ -53,6 +57,9 @@ func (cmd *batchCommandExists) getPolicy(ifc command) Policy {
 }
 
 func (cmd *batchCommandExists) writeBuffer(ifc command) error {
+	if cmd.batchIndex {
+		return cmd.setBatchIndex(cmd.policy, cmd.keys, cmd.batchNamespace, nil, nil, _INFO1_READ|_INFO1_NOBINDATA)
+	}
 	return cmd.setBatchExists(cmd.policy, cmd.keys, cmd.batchNamespace)
 }
 
@@ -89,11 +96,27 @@ func (cmd *batchCommandExists) parseRecordResults(ifc command, receiveSize int)
 			return false, NewAerospikeError(PARSE_ERROR, "Received bins that were not requested!")
 		}
 
+		// batch-index records carry the index of their key instead of the digest;
+		// read it before parseKey reuses the buffer
+		batchIndex := int(Buffer.BytesToUint32(cmd.dataBuffer, 14))
+
 		key, err := cmd.parseKey(fieldCount)
 		if err != nil {
 			return false, err
 		}
 
+		if cmd.batchIndex {
+			offset := batchIndex
+			if offset >= len(cmd.keys) {
+				return false, NewAerospikeError(PARSE_ERROR, "Invalid batch index returned")
+			}
+
+			if resultCode == 0 {
+				cmd.existsArray[offset] = true
+			}
+			continue
+		}
+
 		offset := cmd.batchNamespace.offsets[cmd.index]
 		cmd.index++
 
@@ -29,9 +29,13 @@ type batchCommandGet struct {
 	policy         Policy
 	keys           []*Key
 	binNames       map[string]struct{}
+	operations     []*Operation
 	records        []*Record
 	readAttr       int
 	index          int
+
+	// batchIndex is set if the node is sent the batch-index protocol
+	batchIndex bool
 }
 
 func newBatchCommandGet(
@@ -40,6 +44,7 @@ func newBatchCommandGet(
 	policy Policy,
 	keys []*Key,
 	binNames map[string]struct{},
+	operations []*Operation,
 	records []*Record,
 	readAttr int,
 ) *batchCommandGet {
@@ -49,8 +54,10 @@ func newBatchCommandGet(
 		policy:           policy,
 		keys:             keys,
 		binNames:         binNames,
+		operations:       operations,
 		records:          records,
 		readAttr:         readAttr,
+		batchIndex:       node.supportsBatchIndex(),
 	}
 }
 
@@ -59,7 +66,10 @@ func (cmd *batchCommandGet) getPolicy(ifc command) Policy {
 }
 
 func (cmd *batchCommandGet) writeBuffer(ifc command) error {
-	return cmd.setBatchGet(cmd.policy, cmd.keys, cmd.batchNamespace, cmd.binNames, cmd.readAttr)
+	if cmd.batchIndex {
+		return cmd.setBatchIndex(cmd.policy.GetBasePolicy(), cmd.keys, cmd.batchNamespace, cmd.binNames, cmd.operations, cmd.readAttr)
+	}
+	return cmd.setBatchGet(cmd.policy, cmd.keys, cmd.batchNamespace, cmd.binNames, cmd.operations, cmd.readAttr)
 }
 
 // Parse all results in the batch.  Add records to shared list.
@@ -91,11 +101,30 @@ func (cmd *batchCommandGet) parseRecordResults(ifc command, receiveSize int) (bo
 		expiration := TTL(int(Buffer.BytesToUint32(cmd.dataBuffer, 10)))
 		fieldCount := int(Buffer.BytesToUint16(cmd.dataBuffer, 18))
 		opCount := int(Buffer.BytesToUint16(cmd.dataBuffer, 20))
+
+		// batch-index records carry the index of their key instead of the digest;
+		// read it before parseKey reuses the buffer
+		batchIndex := int(Buffer.BytesToUint32(cmd.dataBuffer, 14))
+
 		key, err := cmd.parseKey(fieldCount)
 		if err != nil {
 			return false, err
 		}
 
+		if cmd.batchIndex {
+			offset := batchIndex
+			if offset >= len(cmd.keys) {
+				return false, NewAerospikeError(PARSE_ERROR, "Invalid batch index returned")
+			}
+
+			if resultCode == 0 {
+				if cmd.records[offset], err = cmd.parseRecord(cmd.keys[offset], opCount, generation, expiration); err != nil {
+					return false, err
+				}
+			}
+			continue
+		}
+
 		offset := cmd.batchNamespace.offsets[cmd.index] //cmd.keyMap[string(key.digest)]
 		cmd.index++
 
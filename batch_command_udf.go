@@ -0,0 +1,191 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"fmt"
+
+	. "github.com/THE108/aerospike-client-go/types"
+	Buffer "github.com/THE108/aerospike-client-go/utils/buffer"
+)
+
+type batchCommandUDF struct {
+	*baseMultiCommand
+
+	batchNamespace *batchNamespace
+	policy         *WritePolicy
+	keys           []*Key
+	packageName    string
+	functionName   string
+	args           []Value
+	argBytes       []byte
+	results        []*BatchUDFResult
+
+	// batchAny is set if the node is sent the batch-any protocol;
+	// other nodes are sent a UDF command per key.
+	batchAny bool
+}
+
+func newBatchCommandUDF(
+	node *Node,
+	batchNamespace *batchNamespace,
+	policy *WritePolicy,
+	keys []*Key,
+	packageName string,
+	functionName string,
+	args []Value,
+	argBytes []byte,
+	results []*BatchUDFResult,
+) *batchCommandUDF {
+	return &batchCommandUDF{
+		baseMultiCommand: newMultiCommand(node, nil),
+		batchNamespace:   batchNamespace,
+		policy:           policy,
+		keys:             keys,
+		packageName:      packageName,
+		functionName:     functionName,
+		args:             args,
+		argBytes:         argBytes,
+		results:          results,
+		batchAny:         node.SupportsFeature(FeatureBatchAny),
+	}
+}
+
+func (cmd *batchCommandUDF) getPolicy(ifc command) Policy {
+	return cmd.policy
+}
+
+func (cmd *batchCommandUDF) writeBuffer(ifc command) error {
+	return cmd.setBatchUDF(cmd.policy, cmd.keys, cmd.batchNamespace, cmd.packageName, cmd.functionName, cmd.argBytes)
+}
+
+// Parse all results in the batch. Unlike batch reads, the server returns a
+// record for every key, with the result code of the UDF on that key.
+func (cmd *batchCommandUDF) parseRecordResults(ifc command, receiveSize int) (bool, error) {
+	cmd.dataOffset = 0
+
+	for cmd.dataOffset < receiveSize {
+		if err := cmd.readBytes(int(_MSG_REMAINING_HEADER_SIZE)); err != nil {
+			return false, err
+		}
+		resultCode := ResultCode(cmd.dataBuffer[5] & 0xFF)
+		info3 := int(cmd.dataBuffer[3])
+
+		// If cmd is the end marker of the response, do not proceed further.
+		// Errors of the whole batch are returned in the end marker.
+		if (info3 & _INFO3_LAST) == _INFO3_LAST {
+			if resultCode != OK {
+				return false, NewAerospikeError(resultCode)
+			}
+			return false, nil
+		}
+
+		offset := int(Buffer.BytesToUint32(cmd.dataBuffer, 14))
+		fieldCount := int(Buffer.BytesToUint16(cmd.dataBuffer, 18))
+		opCount := int(Buffer.BytesToUint16(cmd.dataBuffer, 20))
+		if _, err := cmd.parseKey(fieldCount); err != nil {
+			return false, err
+		}
+
+		if offset >= len(cmd.keys) {
+			return false, NewAerospikeError(PARSE_ERROR, "Invalid batch index returned")
+		}
+
+		bins, err := cmd.parseBins(opCount)
+		if err != nil {
+			return false, err
+		}
+
+		res := &BatchUDFResult{Key: cmd.keys[offset]}
+		switch {
+		case resultCode == OK:
+			res.Result, res.Err = udfResult(bins)
+		case bins["FAILURE"] != nil:
+			res.Err = NewAerospikeError(resultCode, fmt.Sprintf("%v", bins["FAILURE"]))
+		default:
+			res.Err = NewAerospikeError(resultCode)
+		}
+		cmd.results[offset] = res
+	}
+	return true, nil
+}
+
+func (cmd *batchCommandUDF) parseBins(opCount int) (BinMap, error) {
+	bins := make(BinMap, opCount)
+
+	for i := 0; i < opCount; i++ {
+		if err := cmd.readBytes(8); err != nil {
+			return nil, err
+		}
+		opSize := int(Buffer.BytesToUint32(cmd.dataBuffer, 0))
+		particleType := int(cmd.dataBuffer[5])
+		nameSize := int(cmd.dataBuffer[7])
+
+		if err := cmd.readBytes(nameSize); err != nil {
+			return nil, err
+		}
+		name := string(cmd.dataBuffer[:nameSize])
+
+		particleBytesSize := int(opSize - (4 + nameSize))
+		if err := cmd.readBytes(particleBytesSize); err != nil {
+			return nil, err
+		}
+		value, err := bytesToParticle(particleType, cmd.dataBuffer, 0, particleBytesSize)
+		if err != nil {
+			return nil, err
+		}
+
+		bins[name] = value
+	}
+
+	return bins, nil
+}
+
+// executeEach applies the UDF to the keys one at a time, for nodes which do
+// not support the batch-any protocol. The error of each key is only set on
+// its result.
+func (cmd *batchCommandUDF) executeEach() {
+	for _, offset := range cmd.batchNamespace.offsets[:cmd.batchNamespace.offsetSize] {
+		key := cmd.keys[offset]
+		command := newExecuteCommand(cmd.node.cluster, cmd.policy, key, cmd.packageName, cmd.functionName, cmd.args)
+
+		res := &BatchUDFResult{Key: key}
+		if res.Err = command.Execute(); res.Err == nil {
+			if rec := command.GetRecord(); rec != nil {
+				res.Result, res.Err = udfResult(rec.Bins)
+			}
+		}
+		res.InDoubt = isInDoubt(res.Err)
+		cmd.results[offset] = res
+	}
+}
+
+func (cmd *batchCommandUDF) Execute() error {
+	if !cmd.batchAny {
+		cmd.executeEach()
+		return nil
+	}
+
+	err := cmd.execute(cmd)
+	if err != nil {
+		// the keys the server did not answer for fail with the command
+		for _, offset := range cmd.batchNamespace.offsets[:cmd.batchNamespace.offsetSize] {
+			if cmd.results[offset] == nil {
+				cmd.results[offset] = &BatchUDFResult{Key: cmd.keys[offset], Err: err, InDoubt: isInDoubt(err)}
+			}
+		}
+	}
+	return err
+}
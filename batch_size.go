@@ -0,0 +1,148 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"time"
+
+	. "github.com/THE108/aerospike-client-go/types"
+)
+
+const (
+	// adaptiveBatchSizeMin is the lower bound of the adaptive batch size,
+	// and the step it grows by.
+	adaptiveBatchSizeMin = 32
+
+	// adaptiveBatchSizeMax is the upper bound of the adaptive batch size
+	// if ClientPolicy.MaxKeysPerBatch is not set.
+	adaptiveBatchSizeMax = 5000
+
+	adaptiveBatchSizeInitial = 256
+
+	// defaultBatchLatencyTarget is used if ClientPolicy.BatchLatencyTarget
+	// is not set.
+	defaultBatchLatencyTarget = 100 * time.Millisecond
+)
+
+// BatchSize returns the number of keys per sub-batch currently used for
+// the node. It is only adapted if ClientPolicy.AdaptiveBatchSize is set.
+func (nd *Node) BatchSize() int {
+	return nd.batchSize.Get()
+}
+
+// batchSizeBounds returns the bounds of the adaptive batch size.
+func batchSizeBounds(policy *ClientPolicy) (min, max int) {
+	max = policy.MaxKeysPerBatch
+	if max <= 0 {
+		max = adaptiveBatchSizeMax
+	}
+
+	min = adaptiveBatchSizeMin
+	if min > max {
+		min = max
+	}
+	return min, max
+}
+
+// initialBatchSize returns the adaptive batch size of new nodes.
+func initialBatchSize(policy *ClientPolicy) int {
+	if _, max := batchSizeBounds(policy); adaptiveBatchSizeInitial > max {
+		return max
+	}
+	return adaptiveBatchSizeInitial
+}
+
+// batchSizeLimit returns the maximum number of keys per batch command
+// sent to the node, or 0 if batches are not split.
+func (nd *Node) batchSizeLimit() int {
+	policy := nd.cluster.policy()
+	if !policy.AdaptiveBatchSize {
+		return policy.MaxKeysPerBatch
+	}
+
+	// MaxKeysPerBatch may have been lowered since the size was adapted
+	size := nd.batchSize.Get()
+	if _, max := batchSizeBounds(policy); size > max {
+		return max
+	}
+	return size
+}
+
+// adaptBatchSize updates the adaptive batch size of the node after a batch
+// command for keyCount keys completed in d (AIMD).
+func (nd *Node) adaptBatchSize(keyCount int, d time.Duration, err error) {
+	policy := nd.cluster.policy()
+	if !policy.AdaptiveBatchSize {
+		return
+	}
+
+	min, max := batchSizeBounds(policy)
+
+	target := policy.BatchLatencyTarget
+	if target <= 0 {
+		target = defaultBatchLatencyTarget
+	}
+
+	timeout := false
+	if ae, ok := err.(AerospikeError); ok {
+		timeout = ae.ResultCode() == TIMEOUT
+	}
+
+	for {
+		size := nd.batchSize.Get()
+		newSize := size
+
+		switch {
+		case timeout || d > target:
+			newSize = size / 2
+		case err == nil && keyCount >= size:
+			// only full sub-batches tell if the size can grow
+			newSize = size + adaptiveBatchSizeMin
+		}
+
+		if newSize < min {
+			newSize = min
+		} else if newSize > max {
+			newSize = max
+		}
+
+		if newSize == size || nd.batchSize.CompareAndSet(size, newSize) {
+			return
+		}
+	}
+}
+
+// split divides the keys of the namespace into sub-batches of up to size keys.
+// Sub-batches share the offsets of the batch.
+func (bn *batchNamespace) split(size int) []*batchNamespace {
+	if size <= 0 || bn.offsetSize <= size {
+		return []*batchNamespace{bn}
+	}
+
+	res := make([]*batchNamespace, 0, (bn.offsetSize+size-1)/size)
+	for i := 0; i < bn.offsetSize; i += size {
+		end := i + size
+		if end > bn.offsetSize {
+			end = bn.offsetSize
+		}
+
+		res = append(res, &batchNamespace{
+			namespace:  bn.namespace,
+			offsets:    bn.offsets[i:end],
+			offsetSize: end - i,
+		})
+	}
+	return res
+}
@@ -0,0 +1,131 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"time"
+
+	. "github.com/THE108/aerospike-client-go/types"
+	. "github.com/THE108/aerospike-client-go/types/atomic"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Adaptive Batch Size Test", func() {
+
+	var node *Node
+	var policy *ClientPolicy
+
+	BeforeEach(func() {
+		policy = NewClientPolicy()
+		policy.AdaptiveBatchSize = true
+		policy.MaxKeysPerBatch = 320
+		policy.BatchLatencyTarget = 10 * time.Millisecond
+
+		node = &Node{
+			cluster:   &Cluster{},
+			batchSize: NewAtomicInt(adaptiveBatchSizeInitial),
+		}
+		node.cluster.setPolicy(policy)
+	})
+
+	It("should split batches into sub-batches", func() {
+		ns := "test"
+		bns := newBatchNamespace(&ns, 10, 0)
+		for i := 1; i < 7; i++ {
+			bns.add(i)
+		}
+
+		subs := bns.split(3)
+		Expect(len(subs)).To(Equal(3))
+		Expect(subs[0].offsets).To(Equal([]int{0, 1, 2}))
+		Expect(subs[2].offsets).To(Equal([]int{6}))
+		Expect(subs[2].offsetSize).To(Equal(1))
+
+		Expect(bns.split(0)).To(Equal([]*batchNamespace{bns}))
+		Expect(bns.split(7)).To(Equal([]*batchNamespace{bns}))
+	})
+
+	It("should grow additively while full sub-batches are fast", func() {
+		node.adaptBatchSize(adaptiveBatchSizeInitial, time.Millisecond, nil)
+		Expect(node.BatchSize()).To(Equal(adaptiveBatchSizeInitial + adaptiveBatchSizeMin))
+
+		// partial sub-batches don't grow the size
+		node.adaptBatchSize(10, time.Millisecond, nil)
+		Expect(node.BatchSize()).To(Equal(adaptiveBatchSizeInitial + adaptiveBatchSizeMin))
+
+		for i := 0; i < 10; i++ {
+			node.adaptBatchSize(node.BatchSize(), time.Millisecond, nil)
+		}
+		Expect(node.BatchSize()).To(Equal(320))
+		Expect(node.batchSizeLimit()).To(Equal(320))
+	})
+
+	It("should halve on slow sub-batches and timeouts", func() {
+		node.adaptBatchSize(10, 20*time.Millisecond, nil)
+		Expect(node.BatchSize()).To(Equal(adaptiveBatchSizeInitial / 2))
+
+		node.adaptBatchSize(10, time.Millisecond, NewAerospikeError(TIMEOUT))
+		Expect(node.BatchSize()).To(Equal(adaptiveBatchSizeInitial / 4))
+
+		// other errors don't change the size
+		node.adaptBatchSize(node.BatchSize(), time.Millisecond, NewAerospikeError(PARAMETER_ERROR))
+		Expect(node.BatchSize()).To(Equal(adaptiveBatchSizeInitial / 4))
+
+		for i := 0; i < 10; i++ {
+			node.adaptBatchSize(10, time.Second, nil)
+		}
+		Expect(node.BatchSize()).To(Equal(adaptiveBatchSizeMin))
+	})
+
+	It("should use the default latency target if none is set", func() {
+		policy.BatchLatencyTarget = 0
+
+		node.adaptBatchSize(adaptiveBatchSizeInitial, time.Millisecond, nil)
+		Expect(node.BatchSize()).To(Equal(adaptiveBatchSizeInitial + adaptiveBatchSizeMin))
+
+		node.adaptBatchSize(10, 2*defaultBatchLatencyTarget, nil)
+		Expect(node.BatchSize()).To(Equal((adaptiveBatchSizeInitial + adaptiveBatchSizeMin) / 2))
+	})
+
+	It("should keep the size within MaxKeysPerBatch", func() {
+		initial := *policy
+		initial.MaxKeysPerBatch = 64
+		Expect(initialBatchSize(&initial)).To(Equal(64))
+
+		initial.MaxKeysPerBatch = 0
+		Expect(initialBatchSize(&initial)).To(Equal(adaptiveBatchSizeInitial))
+
+		// the limit was lowered after the size was adapted
+		policy.MaxKeysPerBatch = 100
+		Expect(node.batchSizeLimit()).To(Equal(100))
+		node.adaptBatchSize(node.BatchSize(), time.Millisecond, nil)
+		Expect(node.BatchSize()).To(Equal(100))
+
+		// the size never exceeds limits below the minimum step
+		policy.MaxKeysPerBatch = 10
+		node.adaptBatchSize(10, time.Second, nil)
+		Expect(node.BatchSize()).To(Equal(10))
+	})
+
+	It("should use MaxKeysPerBatch if not adaptive", func() {
+		policy.AdaptiveBatchSize = false
+		node.adaptBatchSize(10, time.Second, nil)
+		Expect(node.BatchSize()).To(Equal(adaptiveBatchSizeInitial))
+		Expect(node.batchSizeLimit()).To(Equal(320))
+	})
+
+})
@@ -0,0 +1,99 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"bytes"
+	"sort"
+)
+
+// sortedBatchKey is a key with its partition, for sorting.
+type sortedBatchKey struct {
+	key       *Key
+	partition int
+	index     int
+}
+
+type sortedBatchKeys []sortedBatchKey
+
+func (s sortedBatchKeys) Len() int      { return len(s) }
+func (s sortedBatchKeys) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s sortedBatchKeys) Less(i, j int) bool {
+	if s[i].key.namespace != s[j].key.namespace {
+		return s[i].key.namespace < s[j].key.namespace
+	}
+	if s[i].partition != s[j].partition {
+		return s[i].partition < s[j].partition
+	}
+	return bytes.Compare(s[i].key.digest, s[j].key.digest) < 0
+}
+
+// sortBatchKeys sorts the keys by namespace and partition, and removes keys
+// with identical digests. positions maps the index of each original key to
+// the index of its key in the result.
+func sortBatchKeys(keys []*Key) (sorted []*Key, positions []int) {
+	entries := make(sortedBatchKeys, len(keys))
+	for i, key := range keys {
+		entries[i] = sortedBatchKey{key: key, partition: NewPartitionByKey(key).PartitionId, index: i}
+	}
+	sort.Stable(entries)
+
+	sorted = make([]*Key, 0, len(keys))
+	positions = make([]int, len(keys))
+	for i, entry := range entries {
+		if i == 0 || entry.key.namespace != sorted[len(sorted)-1].namespace || !bytes.Equal(entry.key.digest, sorted[len(sorted)-1].digest) {
+			sorted = append(sorted, entry.key)
+		}
+		positions[entry.index] = len(sorted) - 1
+	}
+	return sorted, positions
+}
+
+// batchKeys returns the keys to send in a batch request. If the policy sorts
+// batch keys, the keys are sorted and deduplicated, and the positions of the
+// original keys in the result are returned as well.
+func batchKeys(policy *BasePolicy, keys []*Key) ([]*Key, []int) {
+	if !policy.SortBatchKeys {
+		return keys, nil
+	}
+	return sortBatchKeys(keys)
+}
+
+// unsortRecords maps the records of sorted keys back to the original keys.
+// Duplicate keys share the same record.
+func unsortRecords(records []*Record, positions []int) []*Record {
+	if positions == nil {
+		return records
+	}
+
+	res := make([]*Record, len(positions))
+	for i, pos := range positions {
+		res[i] = records[pos]
+	}
+	return res
+}
+
+// unsortExists maps the results of sorted keys back to the original keys.
+func unsortExists(exists []bool, positions []int) []bool {
+	if positions == nil {
+		return exists
+	}
+
+	res := make([]bool, len(positions))
+	for i, pos := range positions {
+		res[i] = exists[pos]
+	}
+	return res
+}
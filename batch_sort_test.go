@@ -0,0 +1,68 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Batch Key Sorting Test", func() {
+
+	It("should sort keys by namespace and partition, and remove duplicates", func() {
+		var keys []*Key
+		for i := 0; i < 100; i++ {
+			key, _ := NewKey("test", "demo", i%40)
+			keys = append(keys, key)
+		}
+		key, _ := NewKey("bar", "demo", 1)
+		keys = append(keys, key)
+
+		sorted, positions := sortBatchKeys(keys)
+		Expect(len(sorted)).To(Equal(41))
+		Expect(len(positions)).To(Equal(len(keys)))
+
+		Expect(sorted[0].Namespace()).To(Equal("bar"))
+		for i := 2; i < len(sorted); i++ {
+			Expect(NewPartitionByKey(sorted[i-1]).PartitionId <= NewPartitionByKey(sorted[i]).PartitionId).To(BeTrue())
+		}
+
+		for i, pos := range positions {
+			Expect(sorted[pos].Equals(keys[i])).To(BeTrue())
+		}
+	})
+
+	It("should map results back to the original keys", func() {
+		positions := []int{1, 0, 1}
+
+		rec := &Record{}
+		records := unsortRecords([]*Record{nil, rec}, positions)
+		Expect(records).To(Equal([]*Record{rec, nil, rec}))
+
+		Expect(unsortExists([]bool{false, true}, positions)).To(Equal([]bool{true, false, true}))
+
+		Expect(unsortExists([]bool{true}, nil)).To(Equal([]bool{true}))
+	})
+
+	It("should not change the keys unless requested", func() {
+		key, _ := NewKey("test", "demo", 1)
+		keys := []*Key{key, key}
+
+		res, positions := batchKeys(NewPolicy(), keys)
+		Expect(res).To(Equal(keys))
+		Expect(positions).To(BeNil())
+	})
+
+})
@@ -0,0 +1,118 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike_test
+
+import (
+	"time"
+
+	. "github.com/THE108/aerospike-client-go"
+	"github.com/THE108/aerospike-client-go/aerotest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Batch protocols", func() {
+
+	var srv *aerotest.Server
+	var client *Client
+	var keys []*Key
+
+	BeforeEach(func() {
+		var err error
+		policy := NewClientPolicy()
+		policy.TendInterval = 20 * time.Millisecond
+		client, srv, err = aerotest.NewClientAndServerWithPolicy(policy, "test")
+		Expect(err).ToNot(HaveOccurred())
+
+		keys = nil
+		for i := 0; i < 5; i++ {
+			key, _ := NewKey("test", "aerotest", i)
+			keys = append(keys, key)
+			if i%2 == 0 {
+				Expect(client.Put(nil, key, BinMap{"i": i, "s": "str"})).ToNot(HaveOccurred())
+			}
+		}
+	})
+
+	AfterEach(func() {
+		client.Close()
+		Expect(srv.Close()).ToNot(HaveOccurred())
+	})
+
+	var verifyBatches = func() {
+		exists, err := client.BatchExists(nil, keys)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(exists).To(Equal([]bool{true, false, true, false, true}))
+
+		records, err := client.BatchGet(nil, keys)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(len(records)).To(Equal(len(keys)))
+		for i, rec := range records {
+			if i%2 != 0 {
+				Expect(rec).To(BeNil())
+				continue
+			}
+			Expect(rec.Key.Digest()).To(Equal(keys[i].Digest()))
+			Expect(rec.Bins).To(Equal(BinMap{"i": i, "s": "str"}))
+		}
+
+		records, err = client.BatchGet(nil, keys, "s")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(records[2].Bins).To(Equal(BinMap{"s": "str"}))
+
+		records, err = client.BatchGetHeader(nil, keys)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(records[4].Generation).To(Equal(1))
+		Expect(len(records[4].Bins)).To(Equal(0))
+		Expect(records[3]).To(BeNil())
+	}
+
+	It("must use batch-direct on nodes without the batch-index feature", func() {
+		verifyBatches()
+
+		direct, index := srv.BatchCommands()
+		Expect(direct).To(Equal(4))
+		Expect(index).To(Equal(0))
+	})
+
+	It("must switch to batch-index once nodes report the feature", func() {
+		srv.SetFeatures("batch-index", "cdt-list", "pipelining", "replicas-master", "udf")
+		node := client.GetNodes()[0]
+		for i := 0; i < 50 && !node.SupportsFeature(FeatureBatchIndex); i++ {
+			time.Sleep(10 * time.Millisecond)
+		}
+		Expect(node.SupportsFeature(FeatureBatchIndex)).To(BeTrue())
+
+		verifyBatches()
+
+		direct, index := srv.BatchCommands()
+		Expect(direct).To(Equal(0))
+		Expect(index).To(Equal(4))
+
+		// and back to batch-direct after a downgrade
+		srv.SetFeatures("cdt-list", "pipelining", "replicas-master", "udf")
+		for i := 0; i < 50 && node.SupportsFeature(FeatureBatchIndex); i++ {
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		verifyBatches()
+
+		direct, index = srv.BatchCommands()
+		Expect(direct).To(Equal(4))
+		Expect(index).To(Equal(4))
+	})
+
+})
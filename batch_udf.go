@@ -0,0 +1,76 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	. "github.com/THE108/aerospike-client-go/types"
+)
+
+// BatchUDFResult is the result of the UDF applied to a key by BatchUDF.
+type BatchUDFResult struct {
+	Key *Key
+
+	// Result is the value returned by the UDF, if any.
+	Result interface{}
+
+	// Err is the error of the UDF on the key, or of the command to its node
+	// if the node did not answer for the key.
+	Err error
+
+	// InDoubt is set when the UDF failed in a way that it may or may not
+	// have been applied, e.g. on timeouts and network errors.
+	InDoubt bool
+}
+
+// BatchUDF applies the record UDF to the keys, and returns a result per key
+// in the order of the keys.
+// Keys are sent to each node in one batch command, in the batch-any protocol
+// of servers reporting FeatureBatchAny; other nodes are sent a UDF command per
+// key, with the keys of every node processed in parallel.
+//
+// Errors of the UDF on a key are only set on its result. An error is returned
+// if the command to a node failed; the results of the keys the node did not
+// answer for carry the error as well. A batch which failed may have been
+// partially applied, and is not retried beyond the policy.
+// The SortBatchKeys option of the policy is ignored, so the UDF is applied
+// once per key, duplicates included.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *Client) BatchUDF(policy *WritePolicy, keys []*Key, packageName string, functionName string, args ...Value) ([]*BatchUDFResult, error) {
+	policy = clnt.getUsableWritePolicyFor(policy, keysNamespace(keys), "")
+	if policy.Txn != nil {
+		return nil, NewAerospikeError(PARAMETER_ERROR, "Batch UDFs can not be part of a transaction")
+	}
+
+	argBytes, err := packValueArray(args)
+	if err != nil {
+		return nil, err
+	}
+
+	// same array can be used without synchronization;
+	// each key's result is set by the command to its node
+	results := make([]*BatchUDFResult, len(keys))
+
+	err = clnt.batchExecute(keys, func(node *Node, bns *batchNamespace) command {
+		return newBatchCommandUDF(node, bns, policy, keys, packageName, functionName, args, argBytes, results)
+	})
+
+	for i := range results {
+		if results[i] == nil {
+			results[i] = &BatchUDFResult{Key: keys[i], Err: err}
+		}
+	}
+
+	return results, err
+}
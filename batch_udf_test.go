@@ -0,0 +1,126 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike_test
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/THE108/aerospike-client-go"
+	"github.com/THE108/aerospike-client-go/aerotest"
+	. "github.com/THE108/aerospike-client-go/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Batch UDF", func() {
+
+	var srv *aerotest.Server
+	var client *Client
+	var keys []*Key
+
+	BeforeEach(func() {
+		policy := NewClientPolicy()
+		policy.TendInterval = 20 * time.Millisecond
+
+		var err error
+		client, srv, err = aerotest.NewClientAndServerWithPolicy(policy, "test")
+		Expect(err).ToNot(HaveOccurred())
+
+		// adds the argument to bin n and returns the sum,
+		// unless the record is locked
+		srv.RegisterUDF("adjust", "add", func(bins map[string]interface{}, args []interface{}) (interface{}, error) {
+			if bins["locked"] != nil {
+				return nil, errors.New("record is locked")
+			}
+			n, _ := bins["n"].(int64)
+			bins["n"] = n + args[0].(int64)
+			return bins["n"], nil
+		})
+
+		keys = nil
+		for i := 0; i < 4; i++ {
+			key, _ := NewKey("test", "aerotest", i)
+			keys = append(keys, key)
+		}
+		Expect(client.Put(nil, keys[0], BinMap{"n": 10})).ToNot(HaveOccurred())
+		Expect(client.Put(nil, keys[2], BinMap{"n": 1, "locked": 1})).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		client.Close()
+		Expect(srv.Close()).ToNot(HaveOccurred())
+	})
+
+	var verifyBatchUDF = func() {
+		results, err := client.BatchUDF(nil, keys, "adjust", "add", NewValue(5))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(len(results)).To(Equal(len(keys)))
+
+		for i, res := range results {
+			Expect(res.Key).To(Equal(keys[i]))
+			if i == 2 {
+				Expect(res.Err).To(HaveOccurred())
+				Expect(res.Err.(AerospikeError).ResultCode()).To(Equal(UDF_BAD_RESPONSE))
+				Expect(res.Err.Error()).To(Equal("record is locked"))
+				Expect(res.InDoubt).To(BeFalse())
+				continue
+			}
+			Expect(res.Err).ToNot(HaveOccurred())
+		}
+		Expect(results[0].Result).To(Equal(15))
+		Expect(results[1].Result).To(Equal(5))
+
+		rec, err := client.Get(nil, keys[0])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rec.Bins).To(Equal(BinMap{"n": 15}))
+
+		rec, err = client.Get(nil, keys[2])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rec.Bins).To(Equal(BinMap{"n": 1, "locked": 1}))
+	}
+
+	It("must call the UDF per key on nodes without the batch-any feature", func() {
+		verifyBatchUDF()
+
+		_, index := srv.BatchCommands()
+		Expect(index).To(Equal(0))
+	})
+
+	It("must send one batch command per node with the batch-any feature", func() {
+		srv.SetFeatures("batch-any", "batch-index", "cdt-list", "pipelining", "replicas-master", "udf")
+		node := client.GetNodes()[0]
+		for i := 0; i < 50 && !node.SupportsFeature(FeatureBatchAny); i++ {
+			time.Sleep(10 * time.Millisecond)
+		}
+		Expect(node.SupportsFeature(FeatureBatchAny)).To(BeTrue())
+
+		verifyBatchUDF()
+
+		_, index := srv.BatchCommands()
+		Expect(index).To(Equal(1))
+	})
+
+	It("must report unknown functions on every key", func() {
+		results, err := client.BatchUDF(nil, keys, "adjust", "missing")
+		Expect(err).ToNot(HaveOccurred())
+		for _, res := range results {
+			Expect(res.Err).To(HaveOccurred())
+			Expect(res.Err.(AerospikeError).ResultCode()).To(Equal(UDF_BAD_RESPONSE))
+		}
+	})
+
+})
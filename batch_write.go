@@ -0,0 +1,227 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"bytes"
+	"hash/crc32"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	. "github.com/THE108/aerospike-client-go/types"
+	Buffer "github.com/THE108/aerospike-client-go/utils/buffer"
+)
+
+// BatchWriteTokenBin is the name of the bin in which BatchPut stores
+// the token of each write.
+const BatchWriteTokenBin = "_bw_token"
+
+// batchWriteSeq generates the sequence numbers of batch write tokens.
+var batchWriteSeq = time.Now().UnixNano()
+
+// BatchWrite is a record write in a BatchPut command.
+type BatchWrite struct {
+	Key  *Key
+	Bins BinMap
+
+	// Token is written to BatchWriteTokenBin along with the bins.
+	// It consists of a client sequence number and a checksum of
+	// the key and bins, and is assigned on the first attempt.
+	Token []byte
+
+	// Applied is set when the write is known to have been applied.
+	Applied bool
+
+	// InDoubt is set when the write failed in a way that it may or may
+	// not have been applied, e.g. on timeouts and network errors.
+	InDoubt bool
+
+	// Generation is the generation of the record when it was last verified.
+	Generation int
+
+	// Err is the error of the last attempt, if any.
+	Err error
+}
+
+// NewBatchWrite creates a new BatchWrite instance.
+func NewBatchWrite(key *Key, bins BinMap) *BatchWrite {
+	return &BatchWrite{Key: key, Bins: bins}
+}
+
+// batchWriteToken returns a new token for the write: a sequence number
+// followed by a CRC32 checksum of the key digest and bins.
+func batchWriteToken(key *Key, bins BinMap) ([]byte, error) {
+	crc := crc32.NewIEEE()
+	crc.Write(key.digest)
+	for _, name := range sortedBinNames(bins) {
+		if name == BatchWriteTokenBin {
+			continue
+		}
+
+		value := NewValue(bins[name])
+		b, err := valueBytes(value)
+		if err != nil {
+			return nil, err
+		}
+		crc.Write([]byte(name))
+		crc.Write([]byte{byte(value.GetType())})
+		crc.Write(b)
+	}
+
+	token := make([]byte, 12)
+	Buffer.Int64ToBytes(atomic.AddInt64(&batchWriteSeq, 1), token, 0)
+	Buffer.Int32ToBytes(int32(crc.Sum32()), token, 8)
+	return token, nil
+}
+
+// isInDoubt determines if a failed write may have been applied on the server.
+func isInDoubt(err error) bool {
+	if err == nil {
+		return false
+	}
+	if ae, ok := err.(AerospikeError); ok {
+		return ae.ResultCode() == TIMEOUT
+	}
+	// network errors
+	return true
+}
+
+// BatchPut writes the records, running the writes to each node in parallel.
+// The result of each write is set on its BatchWrite; an error is returned if
+// any of them failed.
+//
+// Each record is written with a token in BatchWriteTokenBin. Writes which are
+// InDoubt after a failure can be reconciled with VerifyBatchWrites, or by
+// calling BatchPut again with the same writes: writes which are already
+// Applied are skipped, and writes InDoubt are verified before they are retried,
+// so no write is applied twice.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *Client) BatchPut(policy *WritePolicy, writes []*BatchWrite) error {
+	policy = clnt.getUsableWritePolicy(policy)
+
+	if err := clnt.VerifyBatchWrites(&policy.BasePolicy, writes); err != nil {
+		return err
+	}
+
+	byNode := map[*Node][]*BatchWrite{}
+	var errs []error
+	for _, bw := range writes {
+		if bw.Applied {
+			continue
+		}
+
+		node, err := clnt.cluster.GetNode(NewPartitionByKey(bw.Key))
+		if err != nil {
+			bw.Err = err
+			errs = append(errs, err)
+			continue
+		}
+		byNode[node] = append(byNode[node], bw)
+	}
+
+	var wg sync.WaitGroup
+	errm := new(sync.Mutex)
+
+	wg.Add(len(byNode))
+	for _, nodeWrites := range byNode {
+		go func(nodeWrites []*BatchWrite) {
+			defer wg.Done()
+
+			for _, bw := range nodeWrites {
+				if err := clnt.batchPutRecord(policy, bw); err != nil {
+					errm.Lock()
+					errs = append(errs, err)
+					errm.Unlock()
+				}
+			}
+		}(nodeWrites)
+	}
+	wg.Wait()
+
+	return mergeErrors(errs)
+}
+
+func (clnt *Client) batchPutRecord(policy *WritePolicy, bw *BatchWrite) error {
+	if bw.Token == nil {
+		token, err := batchWriteToken(bw.Key, bw.Bins)
+		if err != nil {
+			bw.Err = err
+			return err
+		}
+		bw.Token = token
+	}
+
+	bins := make([]*Bin, 0, len(bw.Bins)+1)
+	for name, value := range bw.Bins {
+		if name != BatchWriteTokenBin {
+			bins = append(bins, NewBin(name, value))
+		}
+	}
+	bins = append(bins, NewBin(BatchWriteTokenBin, bw.Token))
+
+	bw.Err = clnt.PutBins(policy, bw.Key, bins...)
+	bw.Applied = bw.Err == nil
+	bw.InDoubt = isInDoubt(bw.Err)
+	return bw.Err
+}
+
+// VerifyBatchWrites reads the tokens of the writes which are InDoubt, and
+// determines if they were applied by comparing them to the tokens of the
+// writes. The Applied, InDoubt and Generation fields of the verified writes
+// are updated.
+// A write which has been overwritten by another client in the meantime is
+// reported as not applied.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *Client) VerifyBatchWrites(policy *BasePolicy, writes []*BatchWrite) error {
+	var inDoubt []*BatchWrite
+	var keys []*Key
+	for _, bw := range writes {
+		if bw.InDoubt && !bw.Applied {
+			inDoubt = append(inDoubt, bw)
+			keys = append(keys, bw.Key)
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil
+	}
+
+	records, err := clnt.BatchGet(policy, keys, BatchWriteTokenBin)
+	if err != nil {
+		return err
+	}
+
+	for i, bw := range inDoubt {
+		verifyBatchWrite(bw, records[i])
+	}
+	return nil
+}
+
+func verifyBatchWrite(bw *BatchWrite, rec *Record) {
+	bw.InDoubt = false
+	bw.Applied = false
+	bw.Generation = 0
+
+	if rec == nil {
+		return
+	}
+
+	bw.Generation = rec.Generation
+	if token, ok := rec.Bins[BatchWriteTokenBin].([]byte); ok && bytes.Equal(token, bw.Token) {
+		bw.Applied = true
+		bw.Err = nil
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"bytes"
+	"errors"
+
+	. "github.com/THE108/aerospike-client-go/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Batch Write Test", func() {
+
+	var key *Key
+
+	BeforeEach(func() {
+		key, _ = NewKey("test", "demo", "key1")
+	})
+
+	It("should generate unique tokens with a checksum of the bins", func() {
+		bins := BinMap{"a": 1, "b": "str"}
+
+		t1, err := batchWriteToken(key, bins)
+		Expect(err).ToNot(HaveOccurred())
+		t2, err := batchWriteToken(key, BinMap{"b": "str", "a": 1})
+		Expect(err).ToNot(HaveOccurred())
+		t3, err := batchWriteToken(key, BinMap{"a": 2, "b": "str"})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(len(t1)).To(Equal(12))
+		Expect(bytes.Equal(t1[:8], t2[:8])).To(BeFalse())
+		Expect(t1[8:]).To(Equal(t2[8:]))
+		Expect(t1[8:]).ToNot(Equal(t3[8:]))
+	})
+
+	It("should determine which errors leave writes in doubt", func() {
+		Expect(isInDoubt(nil)).To(BeFalse())
+		Expect(isInDoubt(NewAerospikeError(TIMEOUT))).To(BeTrue())
+		Expect(isInDoubt(errors.New("connection reset by peer"))).To(BeTrue())
+		Expect(isInDoubt(NewAerospikeError(GENERATION_ERROR))).To(BeFalse())
+	})
+
+	It("should verify writes by their tokens", func() {
+		bw := NewBatchWrite(key, BinMap{"a": 1})
+		bw.Token = []byte{1, 2, 3}
+		bw.InDoubt = true
+		bw.Err = NewAerospikeError(TIMEOUT)
+
+		verifyBatchWrite(bw, nil)
+		Expect(bw.Applied).To(BeFalse())
+		Expect(bw.InDoubt).To(BeFalse())
+
+		verifyBatchWrite(bw, newRecord(nil, key, BinMap{BatchWriteTokenBin: []byte{4, 5, 6}}, 3, 0))
+		Expect(bw.Applied).To(BeFalse())
+		Expect(bw.Generation).To(Equal(3))
+
+		verifyBatchWrite(bw, newRecord(nil, key, BinMap{BatchWriteTokenBin: []byte{1, 2, 3}}, 4, 0))
+		Expect(bw.Applied).To(BeTrue())
+		Expect(bw.Generation).To(Equal(4))
+		Expect(bw.Err).ToNot(HaveOccurred())
+	})
+
+})
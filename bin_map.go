@@ -0,0 +1,178 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"fmt"
+	"math"
+
+	. "github.com/THE108/aerospike-client-go/types"
+)
+
+// The typed getters of BinMap return a BIN_NOT_FOUND error if the bin does
+// not exist or is nil, and a BIN_TYPE_ERROR error if the value can not be
+// converted to the requested type.
+
+func (bm BinMap) get(name string) (interface{}, error) {
+	v := bm[name]
+	if v == nil {
+		return nil, NewAerospikeError(BIN_NOT_FOUND, "Bin `"+name+"` not found")
+	}
+	return v, nil
+}
+
+func binTypeError(name string, v interface{}, expected string) error {
+	return NewAerospikeError(BIN_TYPE_ERROR, fmt.Sprintf("Bin `%s` holds a value of type %T, not %s", name, v, expected))
+}
+
+// GetInt64 returns the integer value of the bin.
+func (bm BinMap) GetInt64(name string) (int64, error) {
+	v, err := bm.get(name)
+	if err != nil {
+		return 0, err
+	}
+
+	switch n := v.(type) {
+	case int:
+		return int64(n), nil
+	case int64:
+		return n, nil
+	case int32:
+		return int64(n), nil
+	case int16:
+		return int64(n), nil
+	case int8:
+		return int64(n), nil
+	case uint32:
+		return int64(n), nil
+	case uint16:
+		return int64(n), nil
+	case uint8:
+		return int64(n), nil
+	case uint64:
+		if n <= math.MaxInt64 {
+			return int64(n), nil
+		}
+	}
+	return 0, binTypeError(name, v, "integer")
+}
+
+// GetInt returns the integer value of the bin.
+func (bm BinMap) GetInt(name string) (int, error) {
+	n, err := bm.GetInt64(name)
+	if err != nil {
+		return 0, err
+	}
+
+	if int64(int(n)) != n {
+		return 0, NewAerospikeError(BIN_TYPE_ERROR, "Bin `"+name+"` value overflows int")
+	}
+	return int(n), nil
+}
+
+// GetFloat returns the floating point value of the bin.
+// Integer values are converted to their floating point value.
+// Floats stored in struct fields by PutObject are encoded as integers;
+// read them with Record.ToObject instead.
+func (bm BinMap) GetFloat(name string) (float64, error) {
+	v, err := bm.get(name)
+	if err != nil {
+		return 0, err
+	}
+
+	switch f := v.(type) {
+	case float64:
+		return f, nil
+	case float32:
+		return float64(f), nil
+	}
+
+	n, err := bm.GetInt64(name)
+	if err != nil {
+		return 0, binTypeError(name, v, "float")
+	}
+	return float64(n), nil
+}
+
+// GetBool returns the boolean value of the bin.
+// The integers 0 and 1, which PutObject stores for booleans, are accepted as well.
+func (bm BinMap) GetBool(name string) (bool, error) {
+	v, err := bm.get(name)
+	if err != nil {
+		return false, err
+	}
+
+	switch b := v.(type) {
+	case bool:
+		return b, nil
+	case int:
+		if b == 0 || b == 1 {
+			return b == 1, nil
+		}
+	}
+	return false, binTypeError(name, v, "bool")
+}
+
+// GetString returns the string value of the bin.
+func (bm BinMap) GetString(name string) (string, error) {
+	v, err := bm.get(name)
+	if err != nil {
+		return "", err
+	}
+
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	return "", binTypeError(name, v, "string")
+}
+
+// GetBytes returns the blob value of the bin.
+func (bm BinMap) GetBytes(name string) ([]byte, error) {
+	v, err := bm.get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if b, ok := v.([]byte); ok {
+		return b, nil
+	}
+	return nil, binTypeError(name, v, "[]byte")
+}
+
+// GetList returns the list value of the bin.
+func (bm BinMap) GetList(name string) ([]interface{}, error) {
+	v, err := bm.get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if l, ok := v.([]interface{}); ok {
+		return l, nil
+	}
+	return nil, binTypeError(name, v, "list")
+}
+
+// GetMap returns the map value of the bin.
+func (bm BinMap) GetMap(name string) (map[interface{}]interface{}, error) {
+	v, err := bm.get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if m, ok := v.(map[interface{}]interface{}); ok {
+		return m, nil
+	}
+	return nil, binTypeError(name, v, "map")
+}
@@ -0,0 +1,122 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"math"
+
+	. "github.com/THE108/aerospike-client-go/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BinMap Getters Test", func() {
+
+	bins := BinMap{
+		"int":    42,
+		"long":   int64(math.MaxInt64),
+		"float":  1.5,
+		"bool":   1,
+		"string": "str",
+		"bytes":  []byte{1, 2},
+		"list":   []interface{}{1, "a"},
+		"map":    map[interface{}]interface{}{"a": 1},
+		"nil":    nil,
+	}
+
+	resultCode := func(err error) ResultCode {
+		Expect(err).To(HaveOccurred())
+		return err.(AerospikeError).ResultCode()
+	}
+
+	It("should return values of the requested type", func() {
+		i, err := bins.GetInt("int")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(i).To(Equal(42))
+
+		l, err := bins.GetInt64("long")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(l).To(Equal(int64(math.MaxInt64)))
+
+		f, err := bins.GetFloat("float")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(f).To(Equal(1.5))
+
+		f, err = bins.GetFloat("int")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(f).To(Equal(42.0))
+
+		b, err := bins.GetBool("bool")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(b).To(BeTrue())
+
+		s, err := bins.GetString("string")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(s).To(Equal("str"))
+
+		bs, err := bins.GetBytes("bytes")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(bs).To(Equal([]byte{1, 2}))
+
+		list, err := bins.GetList("list")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(list).To(Equal([]interface{}{1, "a"}))
+
+		m, err := bins.GetMap("map")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(m).To(Equal(map[interface{}]interface{}{"a": 1}))
+	})
+
+	It("should report missing bins and mismatching types", func() {
+		_, err := bins.GetInt("missing")
+		Expect(resultCode(err)).To(Equal(BIN_NOT_FOUND))
+
+		_, err = bins.GetString("nil")
+		Expect(resultCode(err)).To(Equal(BIN_NOT_FOUND))
+
+		_, err = bins.GetInt("string")
+		Expect(resultCode(err)).To(Equal(BIN_TYPE_ERROR))
+
+		_, err = bins.GetFloat("string")
+		Expect(resultCode(err)).To(Equal(BIN_TYPE_ERROR))
+
+		_, err = bins.GetBool("int")
+		Expect(resultCode(err)).To(Equal(BIN_TYPE_ERROR))
+
+		_, err = bins.GetMap("list")
+		Expect(resultCode(err)).To(Equal(BIN_TYPE_ERROR))
+	})
+
+	It("should convert records to structs", func() {
+		type object struct {
+			Name  string `as:"string"`
+			Count int    `as:"int"`
+			Other int
+		}
+
+		rec := newRecord(nil, nil, bins, 1, 0)
+
+		var obj object
+		Expect(rec.ToObject(&obj)).ToNot(HaveOccurred())
+		Expect(obj).To(Equal(object{Name: "str", Count: 42}))
+
+		Expect(resultCode(rec.ToObject(obj))).To(Equal(PARAMETER_ERROR))
+
+		rec = newRecord(nil, nil, BinMap{"string": 1}, 1, 0)
+		Expect(resultCode(rec.ToObject(&obj))).To(Equal(BIN_TYPE_ERROR))
+	})
+
+})
@@ -0,0 +1,118 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"encoding/base64"
+)
+
+const (
+	_CTX_LIST_INDEX = 0x10
+	_CTX_LIST_RANK  = 0x11
+	_CTX_LIST_VALUE = 0x13
+	_CTX_MAP_INDEX  = 0x20
+	_CTX_MAP_RANK   = 0x21
+	_CTX_MAP_KEY    = 0x22
+	_CTX_MAP_VALUE  = 0x23
+)
+
+// CDTContext selects a nested list or map element of a bin. A sequence of
+// contexts selects an element at any depth, starting at the top level list
+// or map of the bin, eg:
+//
+//	// the "tags" value of the first element of the list bin
+//	ctx := []*CDTContext{CtxListIndex(0), CtxMapKey(NewValue("tags"))}
+type CDTContext struct {
+	id    int
+	value Value
+}
+
+// CtxListIndex selects the list element at the index.
+// Negative indexes count from the end of the list.
+func CtxListIndex(index int) *CDTContext {
+	return &CDTContext{id: _CTX_LIST_INDEX, value: IntegerValue(index)}
+}
+
+// CtxListRank selects the list element of the rank, 0 being the lowest value.
+// Negative ranks count from the highest value.
+func CtxListRank(rank int) *CDTContext {
+	return &CDTContext{id: _CTX_LIST_RANK, value: IntegerValue(rank)}
+}
+
+// CtxListValue selects the list element of the value.
+func CtxListValue(value Value) *CDTContext {
+	return &CDTContext{id: _CTX_LIST_VALUE, value: value}
+}
+
+// CtxMapIndex selects the map entry at the index in key order.
+// Negative indexes count from the end of the map.
+func CtxMapIndex(index int) *CDTContext {
+	return &CDTContext{id: _CTX_MAP_INDEX, value: IntegerValue(index)}
+}
+
+// CtxMapRank selects the map entry of the value rank, 0 being the lowest value.
+// Negative ranks count from the highest value.
+func CtxMapRank(rank int) *CDTContext {
+	return &CDTContext{id: _CTX_MAP_RANK, value: IntegerValue(rank)}
+}
+
+// CtxMapKey selects the map entry of the key.
+func CtxMapKey(key Value) *CDTContext {
+	return &CDTContext{id: _CTX_MAP_KEY, value: key}
+}
+
+// CtxMapValue selects the map entry of the value.
+func CtxMapValue(value Value) *CDTContext {
+	return &CDTContext{id: _CTX_MAP_VALUE, value: value}
+}
+
+// String implements the Stringer interface.
+func (ctx *CDTContext) String() string {
+	return ctxNames[ctx.id] + "(" + ctx.value.String() + ")"
+}
+
+var ctxNames = map[int]string{
+	_CTX_LIST_INDEX: "ListIndex",
+	_CTX_LIST_RANK:  "ListRank",
+	_CTX_LIST_VALUE: "ListValue",
+	_CTX_MAP_INDEX:  "MapIndex",
+	_CTX_MAP_RANK:   "MapRank",
+	_CTX_MAP_KEY:    "MapKey",
+	_CTX_MAP_VALUE:  "MapValue",
+}
+
+// packCDTContext packs the contexts in their wire format, a flat list of
+// their ids and values.
+func packCDTContext(ctx []*CDTContext) ([]byte, error) {
+	values := make([]Value, 0, 2*len(ctx))
+	for _, c := range ctx {
+		values = append(values, IntegerValue(c.id), c.value)
+	}
+
+	packer := newPacker()
+	if err := packer.packValueArray(values); err != nil {
+		return nil, err
+	}
+	return packer.buffer.Bytes(), nil
+}
+
+// base64CDTContext returns the packed contexts in base64, as sent in info commands.
+func base64CDTContext(ctx []*CDTContext) (string, error) {
+	b, err := packCDTContext(ctx)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,104 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"encoding/base64"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Complex Index Test", func() {
+
+	ctx := []*CDTContext{CtxListIndex(-1), CtxMapKey(NewValue("tags"))}
+
+	unpack := func(b []byte) interface{} {
+		obj, err := newUnpacker(b, 0, len(b)).unpackObject()
+		Expect(err).ToNot(HaveOccurred())
+		return obj
+	}
+
+	It("should pack CDT contexts as a flat list of ids and values", func() {
+		packed, err := packCDTContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(unpack(packed)).To(Equal([]interface{}{_CTX_LIST_INDEX, -1, _CTX_MAP_KEY, "tags"}))
+
+		Expect(CtxMapKey(NewValue("tags")).String()).To(Equal("MapKey(tags)"))
+	})
+
+	It("should build the index creation command", func() {
+		cmd, err := createIndexCommand("test", "demo", "idx", "bin", NUMERIC, ICT_DEFAULT, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cmd).To(Equal("sindex-create:ns=test;set=demo;indexname=idx;numbins=1;indexdata=bin,NUMERIC;priority=normal"))
+
+		cmd, err = createIndexCommand("test", "", "idx", "bin", STRING, ICT_MAPKEYS, ctx)
+		Expect(err).ToNot(HaveOccurred())
+
+		packed, _ := packCDTContext(ctx)
+		Expect(cmd).To(Equal("sindex-create:ns=test;indexname=idx;context=" + base64.StdEncoding.EncodeToString(packed) +
+			";indextype=MAPKEYS;numbins=1;indexdata=bin,STRING;priority=normal"))
+	})
+
+	It("should send the collection type and context of the filter", func() {
+		stmt := NewStatement("test", "demo")
+		Expect(stmt.Addfilter(NewEqualFilter("bin", 1))).ToNot(HaveOccurred())
+
+		cmd := &baseCommand{}
+		Expect(cmd.setQuery(NewQueryPolicy(), stmt, false)).ToNot(HaveOccurred())
+		size, fields := cmd.dataOffset, cmd.dataBuffer[27]
+
+		stmt.Filters[0] = NewContainsFilter("bin", ICT_LIST, 1)
+		Expect(cmd.setQuery(NewQueryPolicy(), stmt, false)).ToNot(HaveOccurred())
+		Expect(cmd.dataOffset - size).To(Equal(int(_FIELD_HEADER_SIZE) + 1))
+		Expect(cmd.dataBuffer[27]).To(Equal(fields + 1))
+
+		stmt.Filters[0] = NewContainsRangeFilter("bin", ICT_MAPVALUES, 1, 10, ctx...)
+		Expect(stmt.Filters[0].IndexCollectionType()).To(Equal(ICT_MAPVALUES))
+		Expect(cmd.setQuery(NewQueryPolicy(), stmt, false)).ToNot(HaveOccurred())
+
+		packed, _ := packCDTContext(ctx)
+		Expect(cmd.dataOffset - size).To(Equal(2*int(_FIELD_HEADER_SIZE) + 1 + len(packed)))
+		Expect(cmd.dataBuffer[27]).To(Equal(fields + 2))
+	})
+
+	It("should support blob indexes and filters", func() {
+		blob := []byte{0, 1, 0xFF}
+		blobCtx := []*CDTContext{CtxMapKey(NewValue(blob))}
+
+		cmd, err := createIndexCommand("test", "demo", "idx", "bin", BLOB, ICT_LIST, blobCtx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(strings.HasSuffix(cmd, ";indextype=LIST;numbins=1;indexdata=bin,BLOB;priority=normal")).To(BeTrue())
+
+		packed, _ := packCDTContext(blobCtx)
+		Expect(strings.Contains(cmd, ";context="+base64.StdEncoding.EncodeToString(packed)+";")).To(BeTrue())
+		Expect(unpack(packed)).To(Equal([]interface{}{_CTX_MAP_KEY, blob}))
+
+		filter := NewEqualFilter("bin", blob)
+		size, err := filter.estimateSize()
+		Expect(err).ToNot(HaveOccurred())
+
+		buf := make([]byte, size)
+		offset, err := filter.write(buf, 0)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(offset).To(Equal(size))
+		Expect(buf).To(Equal([]byte{3, 'b', 'i', 'n', 4, 0, 0, 0, 3, 0, 1, 0xFF, 0, 0, 0, 3, 0, 1, 0xFF}))
+
+		_, err = NewEqualFilter("bin", []interface{}{1}).estimateSize()
+		Expect(err).To(HaveOccurred())
+	})
+
+})
@@ -0,0 +1,143 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	. "github.com/THE108/aerospike-client-go/types"
+)
+
+// ChangeSequenceSetName is the set holding the counters of change trackers.
+const ChangeSequenceSetName = "change_sequences"
+
+const changeSequenceBin = "seq"
+
+// ChangeTracker stamps records written through it with an increasing change
+// sequence number, stored in a bin of the record in the same Operate command
+// as the write. Consumers read the records changed after the last sequence
+// they have seen with GetChangesSince, which filters on the server with a
+// predicate expression.
+//
+// Sequence numbers are allocated from a counter record before the write, so
+// concurrent writes may become visible out of sequence order. Consumers
+// which need every change should re-read a small margin of sequence numbers
+// below the last one they have seen.
+// Deletes can not be tracked; mark records as deleted with a bin instead.
+// ChangeTracker is safe for concurrent use.
+type ChangeTracker struct {
+	client    *Client
+	namespace string
+	setName   string
+	binName   string
+	seqKey    *Key
+}
+
+// NewChangeTracker returns a change tracker for the records of the set,
+// keeping their change sequence number in the bin binName.
+// The sequence counter is stored in the ChangeSequenceSetName set of the namespace.
+func (clnt *Client) NewChangeTracker(namespace, setName, binName string) (*ChangeTracker, error) {
+	if binName == "" {
+		return nil, NewAerospikeError(PARAMETER_ERROR, "Change sequence bin name must not be empty")
+	}
+
+	seqKey, err := NewKey(namespace, ChangeSequenceSetName, setName+":"+binName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ChangeTracker{
+		client:    clnt,
+		namespace: namespace,
+		setName:   setName,
+		binName:   binName,
+		seqKey:    seqKey,
+	}, nil
+}
+
+// NextSequence allocates the next change sequence number.
+func (ct *ChangeTracker) NextSequence() (int64, error) {
+	rec, err := ct.client.Operate(nil, ct.seqKey, AddOp(NewBin(changeSequenceBin, 1)), GetOp())
+	if err != nil {
+		return 0, err
+	}
+
+	switch seq := rec.Bins[changeSequenceBin].(type) {
+	case int:
+		return int64(seq), nil
+	case int64:
+		return seq, nil
+	}
+	return 0, NewAerospikeError(PARSE_ERROR, "Unexpected change sequence type")
+}
+
+// Put writes the bins to the record, and stamps it with a new change
+// sequence number which is returned.
+// If the policy is nil, the default relevant policy will be used.
+func (ct *ChangeTracker) Put(policy *WritePolicy, key *Key, bins BinMap) (int64, error) {
+	ops := make([]*Operation, 0, len(bins))
+	for name, value := range bins {
+		ops = append(ops, PutOp(NewBin(name, value)))
+	}
+
+	_, seq, err := ct.Operate(policy, key, ops...)
+	return seq, err
+}
+
+// Operate performs the operations on the record, and stamps it with a new
+// change sequence number in the same command. The sequence number is returned
+// along with the result of the operations.
+// If the policy is nil, the default relevant policy will be used.
+func (ct *ChangeTracker) Operate(policy *WritePolicy, key *Key, operations ...*Operation) (*Record, int64, error) {
+	if key.Namespace() != ct.namespace || key.SetName() != ct.setName {
+		return nil, 0, NewAerospikeError(PARAMETER_ERROR, "Key does not belong to the tracked set")
+	}
+
+	seq, err := ct.NextSequence()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// stamp the record first, so reads in operations include the sequence number
+	ops := make([]*Operation, 0, len(operations)+1)
+	ops = append(ops, PutOp(NewBin(ct.binName, seq)))
+	ops = append(ops, operations...)
+
+	rec, err := ct.client.Operate(policy, key, ops...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return rec, seq, nil
+}
+
+// GetChangesSince returns the records of the set stamped with a change
+// sequence number greater than seq. Records are not returned in sequence
+// order; their sequence number is in the tracker's bin.
+// If binNames are given, the tracker's bin is read as well.
+// If the policy is nil, the default relevant policy will be used.
+func (ct *ChangeTracker) GetChangesSince(policy *QueryPolicy, seq int64, binNames ...string) (*Recordset, error) {
+	if len(binNames) > 0 {
+		binNames = append(append([]string(nil), binNames...), ct.binName)
+	}
+
+	stmt := NewStatement(ct.namespace, ct.setName, binNames...)
+	if err := stmt.SetPredExp(
+		NewPredExpIntegerBin(ct.binName),
+		NewPredExpIntegerValue(seq),
+		NewPredExpIntegerGreater(),
+	); err != nil {
+		return nil, err
+	}
+
+	return ct.client.Query(policy, stmt)
+}
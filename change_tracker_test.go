@@ -0,0 +1,73 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike_test
+
+import (
+	. "github.com/THE108/aerospike-client-go"
+	"github.com/THE108/aerospike-client-go/aerotest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Change Tracker", func() {
+
+	var srv *aerotest.Server
+	var client *Client
+	var tracker *ChangeTracker
+
+	BeforeEach(func() {
+		var err error
+		client, srv, err = aerotest.NewClientAndServer("test")
+		Expect(err).ToNot(HaveOccurred())
+
+		tracker, err = client.NewChangeTracker("test", "aerotest", "changeseq")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		client.Close()
+		Expect(srv.Close()).ToNot(HaveOccurred())
+	})
+
+	It("must stamp writes with increasing sequence numbers", func() {
+		key1, _ := NewKey("test", "aerotest", 1)
+		key2, _ := NewKey("test", "aerotest", 2)
+
+		seq, err := tracker.Put(nil, key1, BinMap{"value": 1})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(seq).To(Equal(int64(1)))
+
+		rec, seq, err := tracker.Operate(nil, key2, AddOp(NewBin("value", 5)), GetOp())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(seq).To(Equal(int64(2)))
+		Expect(rec.Bins).To(Equal(BinMap{"value": 5, "changeseq": 2}))
+
+		seq, err = tracker.Put(nil, key1, BinMap{"value": 2})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(seq).To(Equal(int64(3)))
+
+		rec, err = client.Get(nil, key1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rec.Bins).To(Equal(BinMap{"value": 2, "changeseq": 3}))
+	})
+
+	It("must reject keys of other sets", func() {
+		key, _ := NewKey("test", "other", 1)
+		_, err := tracker.Put(nil, key, BinMap{"value": 1})
+		Expect(err).To(HaveOccurred())
+	})
+
+})
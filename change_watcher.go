@@ -0,0 +1,285 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"sync"
+	"time"
+
+	. "github.com/THE108/aerospike-client-go/types"
+)
+
+// ChangeType determines the type of a record change.
+type ChangeType int
+
+const (
+	// RECORD_CREATED means the record did not exist on the previous poll.
+	RECORD_CREATED ChangeType = iota
+
+	// RECORD_UPDATED means the generation of the record has changed.
+	RECORD_UPDATED
+
+	// RECORD_DELETED means the record has been deleted or has expired.
+	RECORD_DELETED
+)
+
+// ChangeEvent describes a change of a record detected by a ChangeWatcher.
+type ChangeEvent struct {
+	Type ChangeType
+	Key  *Key
+
+	// Record is the current record. It is nil for deleted records.
+	// Bins are only set if the policy includes bin data.
+	Record *Record
+
+	// Err is set if a poll failed. Changes are not reported for a failed
+	// poll, and will be detected by the next successful one.
+	Err error
+}
+
+// ChangeWatchPolicy encapsulates parameters for watching records for changes.
+type ChangeWatchPolicy struct {
+	// ScanPolicy is used to poll the set. Its BasePolicy is also used
+	// to poll keys. IncludeBinData is false by default, so only
+	// record headers are polled.
+	ScanPolicy
+
+	// PollInterval is the time between the end of a poll and the start
+	// of the next one. Default is 1 second.
+	PollInterval time.Duration
+
+	// EventQueueSize is the size of the events channel. If the consumer
+	// lags and the channel is full, polling blocks. Default is 1024.
+	EventQueueSize int
+
+	// ReportExisting reports the records found on the first poll as created.
+	// By default, the first poll only records their state.
+	ReportExisting bool
+}
+
+// NewChangeWatchPolicy generates a new ChangeWatchPolicy instance with default values.
+func NewChangeWatchPolicy() *ChangeWatchPolicy {
+	res := &ChangeWatchPolicy{
+		ScanPolicy:     *NewScanPolicy(),
+		PollInterval:   time.Second,
+		EventQueueSize: 1024,
+	}
+	res.IncludeBinData = false
+	return res
+}
+
+// Clone returns a deep copy of the policy, including its ScanPolicy.
+func (p *ChangeWatchPolicy) Clone() *ChangeWatchPolicy {
+	res := *p
+	res.ScanPolicy = *p.ScanPolicy.Clone()
+	return &res
+}
+
+// changeSource returns the current records by their digest.
+type changeSource func() (map[string]*Record, error)
+
+// ChangeWatcher periodically polls records and delivers their changes on a
+// channel. Since records are compared by their generation, changes between
+// two polls are coalesced into one event, and a record deleted and
+// recreated between two polls can be reported as updated.
+// ChangeWatcher is safe for concurrent use.
+type ChangeWatcher struct {
+	policy ChangeWatchPolicy
+	source changeSource
+	events chan *ChangeEvent
+
+	records map[string]*Record
+
+	closeOnce sync.Once
+	cancelled chan struct{}
+	done      chan struct{}
+}
+
+func newChangeWatcher(policy *ChangeWatchPolicy, source changeSource) *ChangeWatcher {
+	if policy == nil {
+		policy = NewChangeWatchPolicy()
+	}
+
+	queueSize := policy.EventQueueSize
+	if queueSize < 0 {
+		queueSize = 0
+	}
+
+	cw := &ChangeWatcher{
+		policy:    *policy,
+		source:    source,
+		events:    make(chan *ChangeEvent, queueSize),
+		cancelled: make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+
+	go cw.run()
+	return cw
+}
+
+// WatchSet polls all records of the set by scanning it, and reports their changes.
+// If the set name is empty, the whole namespace is watched.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *Client) WatchSet(policy *ChangeWatchPolicy, namespace string, setName string, binNames ...string) (*ChangeWatcher, error) {
+	if policy == nil {
+		policy = NewChangeWatchPolicy()
+	}
+	scanPolicy := policy.ScanPolicy
+
+	source := func() (map[string]*Record, error) {
+		recordset, err := clnt.ScanAll(&scanPolicy, namespace, setName, binNames...)
+		if err != nil {
+			return nil, err
+		}
+
+		records := map[string]*Record{}
+		for res := range recordset.Results() {
+			if res.Err != nil {
+				recordset.Close()
+				return nil, res.Err
+			}
+			records[string(res.Record.Key.Digest())] = res.Record
+		}
+		return records, nil
+	}
+
+	return newChangeWatcher(policy, source), nil
+}
+
+// WatchKeys polls the records of the keys with batch commands, and reports their changes.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *Client) WatchKeys(policy *ChangeWatchPolicy, keys []*Key, binNames ...string) (*ChangeWatcher, error) {
+	if policy == nil {
+		policy = NewChangeWatchPolicy()
+	}
+	if len(keys) == 0 {
+		return nil, NewAerospikeError(PARAMETER_ERROR, "No keys to watch.")
+	}
+
+	basePolicy := *policy.GetBasePolicy()
+	includeBins := policy.IncludeBinData
+	keys = append([]*Key(nil), keys...)
+
+	source := func() (map[string]*Record, error) {
+		var batch []*Record
+		var err error
+		if includeBins {
+			batch, err = clnt.BatchGet(&basePolicy, keys, binNames...)
+		} else {
+			batch, err = clnt.BatchGetHeader(&basePolicy, keys)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		records := make(map[string]*Record, len(keys))
+		for i, rec := range batch {
+			if rec != nil {
+				// headers don't carry the key
+				rec.Key = keys[i]
+				records[string(keys[i].Digest())] = rec
+			}
+		}
+		return records, nil
+	}
+
+	return newChangeWatcher(policy, source), nil
+}
+
+// Events returns the channel on which changes are delivered.
+// The channel is closed when the watcher is closed.
+func (cw *ChangeWatcher) Events() <-chan *ChangeEvent {
+	return cw.events
+}
+
+// Close stops polling and closes the events channel.
+func (cw *ChangeWatcher) Close() {
+	cw.closeOnce.Do(func() {
+		close(cw.cancelled)
+	})
+	<-cw.done
+}
+
+func (cw *ChangeWatcher) run() {
+	defer close(cw.done)
+	defer close(cw.events)
+
+	// existing records are only known after the first successful poll
+	first := true
+	for {
+		open, polled := cw.poll(first)
+		if !open {
+			return
+		}
+		if polled {
+			first = false
+		}
+
+		select {
+		case <-time.After(cw.policy.PollInterval):
+		case <-cw.cancelled:
+			return
+		}
+	}
+}
+
+// poll compares the current records to the previous poll and delivers
+// the changes. It returns whether the watcher is still open, and whether
+// the records were polled successfully.
+func (cw *ChangeWatcher) poll(first bool) (open, polled bool) {
+	records, err := cw.source()
+	if err != nil {
+		return cw.send(&ChangeEvent{Err: err}), false
+	}
+
+	previous := cw.records
+	cw.records = records
+
+	if first && !cw.policy.ReportExisting {
+		return true, true
+	}
+
+	for digest, rec := range records {
+		old, exists := previous[digest]
+		switch {
+		case !exists:
+			if !cw.send(&ChangeEvent{Type: RECORD_CREATED, Key: rec.Key, Record: rec}) {
+				return false, true
+			}
+		case old.Generation != rec.Generation:
+			if !cw.send(&ChangeEvent{Type: RECORD_UPDATED, Key: rec.Key, Record: rec}) {
+				return false, true
+			}
+		}
+	}
+
+	for digest, old := range previous {
+		if _, exists := records[digest]; !exists {
+			if !cw.send(&ChangeEvent{Type: RECORD_DELETED, Key: old.Key}) {
+				return false, true
+			}
+		}
+	}
+	return true, true
+}
+
+func (cw *ChangeWatcher) send(event *ChangeEvent) bool {
+	select {
+	case cw.events <- event:
+		return true
+	case <-cw.cancelled:
+		return false
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Change Watcher Test", func() {
+
+	var key1, key2 *Key
+	var polls chan map[string]*Record
+	var policy *ChangeWatchPolicy
+
+	var last map[string]*Record
+
+	// the source returns the next snapshot sent on polls, or the last one
+	// if there is none; a nil snapshot fails the poll
+	var source = func() (map[string]*Record, error) {
+		select {
+		case records, ok := <-polls:
+			if ok && records == nil {
+				return nil, errors.New("poll failed")
+			} else if ok {
+				last = records
+			}
+		default:
+		}
+		return last, nil
+	}
+
+	var snapshot = func(records ...*Record) map[string]*Record {
+		res := map[string]*Record{}
+		for _, rec := range records {
+			res[string(rec.Key.Digest())] = rec
+		}
+		return res
+	}
+
+	BeforeEach(func() {
+		key1, _ = NewKey("test", "demo", 1)
+		key2, _ = NewKey("test", "demo", 2)
+		polls = make(chan map[string]*Record, 10)
+		last = nil
+
+		policy = NewChangeWatchPolicy()
+		policy.PollInterval = time.Millisecond
+	})
+
+	It("should report created, updated and deleted records", func() {
+		polls <- snapshot(newRecord(nil, key1, nil, 1, 0))
+		polls <- snapshot(newRecord(nil, key1, nil, 1, 0), newRecord(nil, key2, nil, 1, 0))
+		polls <- snapshot(newRecord(nil, key1, nil, 2, 0), newRecord(nil, key2, nil, 1, 0))
+		polls <- snapshot(newRecord(nil, key1, nil, 2, 0))
+
+		cw := newChangeWatcher(policy, source)
+		defer cw.Close()
+
+		event := <-cw.Events()
+		Expect(event.Type).To(Equal(RECORD_CREATED))
+		Expect(event.Key.Equals(key2)).To(BeTrue())
+
+		event = <-cw.Events()
+		Expect(event.Type).To(Equal(RECORD_UPDATED))
+		Expect(event.Key.Equals(key1)).To(BeTrue())
+		Expect(event.Record.Generation).To(Equal(2))
+
+		event = <-cw.Events()
+		Expect(event.Type).To(Equal(RECORD_DELETED))
+		Expect(event.Key.Equals(key2)).To(BeTrue())
+		Expect(event.Record).To(BeNil())
+	})
+
+	It("should report existing records if requested", func() {
+		policy.ReportExisting = true
+		polls <- snapshot(newRecord(nil, key1, nil, 1, 0))
+
+		cw := newChangeWatcher(policy, source)
+		defer cw.Close()
+
+		event := <-cw.Events()
+		Expect(event.Type).To(Equal(RECORD_CREATED))
+		Expect(event.Key.Equals(key1)).To(BeTrue())
+	})
+
+	It("should report failed polls and keep the previous state", func() {
+		polls <- snapshot(newRecord(nil, key1, nil, 1, 0))
+		polls <- nil
+		polls <- snapshot(newRecord(nil, key1, nil, 1, 0))
+		polls <- snapshot()
+
+		cw := newChangeWatcher(policy, source)
+		defer cw.Close()
+
+		event := <-cw.Events()
+		Expect(event.Err).To(HaveOccurred())
+
+		event = <-cw.Events()
+		Expect(event.Err).ToNot(HaveOccurred())
+		Expect(event.Type).To(Equal(RECORD_DELETED))
+	})
+
+	It("should not report existing records after a failed first poll", func() {
+		polls <- nil
+		polls <- snapshot(newRecord(nil, key1, nil, 1, 0))
+		polls <- snapshot(newRecord(nil, key1, nil, 1, 0), newRecord(nil, key2, nil, 1, 0))
+
+		cw := newChangeWatcher(policy, source)
+		defer cw.Close()
+
+		event := <-cw.Events()
+		Expect(event.Err).To(HaveOccurred())
+
+		event = <-cw.Events()
+		Expect(event.Err).ToNot(HaveOccurred())
+		Expect(event.Type).To(Equal(RECORD_CREATED))
+		Expect(event.Key.Equals(key2)).To(BeTrue())
+	})
+
+	It("should close the events channel when closed", func() {
+		polls <- snapshot(newRecord(nil, key1, nil, 1, 0))
+		polls <- snapshot(newRecord(nil, key1, nil, 2, 0))
+
+		policy.EventQueueSize = 0
+		cw := newChangeWatcher(policy, source)
+
+		// the watcher is blocked sending the event
+		time.Sleep(10 * time.Millisecond)
+		close(polls)
+		cw.Close()
+
+		for event := range cw.Events() {
+			Expect(event).ToNot(BeNil())
+		}
+	})
+
+})
@@ -24,12 +24,14 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	. "github.com/THE108/aerospike-client-go/types"
 )
 
 // Client encapsulates an Aerospike cluster.
 // All database operations are available against this object.
+// Client is safe for concurrent use by multiple goroutines.
 type Client struct {
 	cluster *Cluster
 
@@ -43,6 +45,18 @@ type Client struct {
 	DefaultQueryPolicy *QueryPolicy
 	// DefaultAdminPolicy is used for all security commands without a specific policy.
 	DefaultAdminPolicy *AdminPolicy
+	// DefaultInfoPolicy is used for all info commands without a specific policy.
+	DefaultInfoPolicy *InfoPolicy
+
+	rmwStats *rmwStats
+
+	// hints adjusting the default policies per namespace; copied on write
+	namespaceHints      map[string]*NamespaceHint
+	namespaceHintsMutex sync.RWMutex
+
+	// default policies registered per namespace and set; copied on write
+	scopedPolicies      *scopedPolicies
+	scopedPoliciesMutex sync.RWMutex
 }
 
 //-------------------------------------------------------
@@ -79,6 +93,8 @@ func NewClientWithPolicyAndHost(policy *ClientPolicy, hosts ...*Host) (*Client,
 		DefaultScanPolicy:  NewScanPolicy(),
 		DefaultQueryPolicy: NewQueryPolicy(),
 		DefaultAdminPolicy: NewAdminPolicy(),
+		DefaultInfoPolicy:  NewInfoPolicy(),
+		rmwStats:           newRMWStats(),
 	}, nil
 
 }
@@ -102,6 +118,28 @@ func (clnt *Client) GetNodes() []*Node {
 	return clnt.cluster.GetNodes()
 }
 
+// UpdatePolicy changes the settings of the client policy which can be tuned
+// on a live client, without reconnecting:
+//
+//	Timeout, IdleTimeout, IdlePingThreshold and TendInterval
+//	ConnectionQueueSize and LimitConnectionsToQueueSize
+//	ServerConnectionsFraction
+//	MaxKeysPerBatch, AdaptiveBatchSize and BatchLatencyTarget
+//	CommandObserver, SlowCommandThreshold and SlowCommandHandler
+//
+// The other settings of the policy are ignored; changing them requires a new
+// client. Pass a modified copy of the policy the client was created with to
+// only change some of the settings.
+// Shrinking ConnectionQueueSize closes the pooled connections that no longer
+// fit in the pools. A new IdleTimeout applies to new connections, and a new
+// TendInterval from the next tend on.
+func (clnt *Client) UpdatePolicy(policy *ClientPolicy) error {
+	if policy == nil {
+		return NewAerospikeError(PARAMETER_ERROR, "Policy must not be nil")
+	}
+	return clnt.cluster.updatePolicy(policy)
+}
+
 // GetNodeNames returns a list of active server node names in the cluster.
 func (clnt *Client) GetNodeNames() []string {
 	nodes := clnt.cluster.GetNodes()
@@ -124,6 +162,42 @@ func (clnt *Client) GetConnectionCount() (result int) {
 	return
 }
 
+// RequestInfoAny sends the info commands to the nodes of the cluster in order,
+// until one of them answers. Inactive and unhealthy nodes are tried last.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *Client) RequestInfoAny(policy *InfoPolicy, names ...string) (map[string]string, error) {
+	policy = clnt.getUsableInfoPolicy(policy)
+
+	nodes := clnt.cluster.GetNodes()
+	if len(nodes) == 0 {
+		return nil, NewAerospikeError(SERVER_NOT_AVAILABLE, "Info request failed because cluster is empty.")
+	}
+
+	// try healthy nodes first
+	candidates := make([]*Node, 0, len(nodes))
+	for _, node := range nodes {
+		if node.IsActive() && !node.IsUnhealthy() {
+			candidates = append(candidates, node)
+		}
+	}
+	for _, node := range nodes {
+		if !node.IsActive() || node.IsUnhealthy() {
+			candidates = append(candidates, node)
+		}
+	}
+
+	errs := []error{}
+	for _, node := range candidates {
+		res, err := RequestNodeInfoWithPolicy(policy, node, names...)
+		if err == nil {
+			return res, nil
+		}
+		errs = append(errs, err)
+	}
+
+	return nil, mergeErrors(errs)
+}
+
 //-------------------------------------------------------
 // Write Record Operations
 //-------------------------------------------------------
@@ -146,7 +220,7 @@ func (clnt *Client) Put(policy *WritePolicy, key *Key, binMap BinMap) error {
 // This method avoids using the BinMap allocation and iteration and is lighter on GC.
 // If the policy is nil, the default relevant policy will be used.
 func (clnt *Client) PutBins(policy *WritePolicy, key *Key, bins ...*Bin) error {
-	policy = clnt.getUsableWritePolicy(policy)
+	policy = clnt.getUsableWritePolicyFor(policy, key.namespace, key.setName)
 	command := newWriteCommand(clnt.cluster, policy, key, bins, WRITE)
 	return command.Execute()
 }
@@ -156,7 +230,7 @@ func (clnt *Client) PutBins(policy *WritePolicy, key *Key, bins ...*Bin) error {
 // handled when the record already exists.
 // If the policy is nil, the default relevant policy will be used.
 func (clnt *Client) PutObject(policy *WritePolicy, key *Key, obj interface{}) (err error) {
-	policy = clnt.getUsableWritePolicy(policy)
+	policy = clnt.getUsableWritePolicyFor(policy, key.namespace, key.setName)
 
 	bins := marshal(obj)
 	command := newWriteCommand(clnt.cluster, policy, key, bins, WRITE)
@@ -184,7 +258,7 @@ func (clnt *Client) Append(policy *WritePolicy, key *Key, binMap BinMap) error {
 
 // AppendBins works the same as Append, but avoids BinMap allocation and iteration.
 func (clnt *Client) AppendBins(policy *WritePolicy, key *Key, bins ...*Bin) error {
-	policy = clnt.getUsableWritePolicy(policy)
+	policy = clnt.getUsableWritePolicyFor(policy, key.namespace, key.setName)
 	command := newWriteCommand(clnt.cluster, policy, key, bins, APPEND)
 	return command.Execute()
 }
@@ -203,7 +277,7 @@ func (clnt *Client) Prepend(policy *WritePolicy, key *Key, binMap BinMap) error
 
 // PrependBins works the same as Prepend, but avoids BinMap allocation and iteration.
 func (clnt *Client) PrependBins(policy *WritePolicy, key *Key, bins ...*Bin) error {
-	policy = clnt.getUsableWritePolicy(policy)
+	policy = clnt.getUsableWritePolicyFor(policy, key.namespace, key.setName)
 	command := newWriteCommand(clnt.cluster, policy, key, bins, PREPEND)
 	return command.Execute()
 }
@@ -227,7 +301,7 @@ func (clnt *Client) Add(policy *WritePolicy, key *Key, binMap BinMap) error {
 
 // AddBins works the same as Add, but avoids BinMap allocation and iteration.
 func (clnt *Client) AddBins(policy *WritePolicy, key *Key, bins ...*Bin) error {
-	policy = clnt.getUsableWritePolicy(policy)
+	policy = clnt.getUsableWritePolicyFor(policy, key.namespace, key.setName)
 	command := newWriteCommand(clnt.cluster, policy, key, bins, ADD)
 	return command.Execute()
 }
@@ -240,7 +314,7 @@ func (clnt *Client) AddBins(policy *WritePolicy, key *Key, bins ...*Bin) error {
 // The policy specifies the transaction timeout.
 // If the policy is nil, the default relevant policy will be used.
 func (clnt *Client) Delete(policy *WritePolicy, key *Key) (bool, error) {
-	policy = clnt.getUsableWritePolicy(policy)
+	policy = clnt.getUsableWritePolicyFor(policy, key.namespace, key.setName)
 	command := newDeleteCommand(clnt.cluster, policy, key)
 	err := command.Execute()
 	return command.Existed(), err
@@ -255,7 +329,7 @@ func (clnt *Client) Delete(policy *WritePolicy, key *Key) (bool, error) {
 // policy's expiration.
 // If the record doesn't exist, it will return an error.
 func (clnt *Client) Touch(policy *WritePolicy, key *Key) error {
-	policy = clnt.getUsableWritePolicy(policy)
+	policy = clnt.getUsableWritePolicyFor(policy, key.namespace, key.setName)
 	command := newTouchCommand(clnt.cluster, policy, key)
 	return command.Execute()
 }
@@ -268,10 +342,11 @@ func (clnt *Client) Touch(policy *WritePolicy, key *Key) error {
 // The policy can be used to specify timeouts.
 // If the policy is nil, the default relevant policy will be used.
 func (clnt *Client) Exists(policy *BasePolicy, key *Key) (bool, error) {
-	policy = clnt.getUsablePolicy(policy)
-	command := newExistsCommand(clnt.cluster, policy, key)
-	err := command.Execute()
-	return command.Exists(), err
+	policy = clnt.getUsablePolicyFor(policy, key.namespace, key.setName)
+	command, err := clnt.executeHedged(policy, key, func(policy *BasePolicy) hedgeableCommand {
+		return newExistsCommand(clnt.cluster, policy, key)
+	})
+	return command.(*existsCommand).Exists(), err
 }
 
 // BatchExists determines if multiple record keys exist in one batch request.
@@ -279,7 +354,8 @@ func (clnt *Client) Exists(policy *BasePolicy, key *Key) (bool, error) {
 // The policy can be used to specify timeouts.
 // If the policy is nil, the default relevant policy will be used.
 func (clnt *Client) BatchExists(policy *BasePolicy, keys []*Key) ([]bool, error) {
-	policy = clnt.getUsablePolicy(policy)
+	policy = clnt.getUsablePolicyFor(policy, keysNamespace(keys), "")
+	keys, positions := batchKeys(policy, keys)
 
 	// same array can be used without synchronization;
 	// when a key exists, the corresponding index will be marked true
@@ -291,7 +367,7 @@ func (clnt *Client) BatchExists(policy *BasePolicy, keys []*Key) ([]bool, error)
 		return nil, err
 	}
 
-	return existsArray, nil
+	return unsortExists(existsArray, positions), nil
 }
 
 //-------------------------------------------------------
@@ -302,20 +378,61 @@ func (clnt *Client) BatchExists(policy *BasePolicy, keys []*Key) ([]bool, error)
 // The policy can be used to specify timeouts.
 // If the policy is nil, the default relevant policy will be used.
 func (clnt *Client) Get(policy *BasePolicy, key *Key, binNames ...string) (*Record, error) {
-	policy = clnt.getUsablePolicy(policy)
+	policy = clnt.getUsablePolicyFor(policy, key.namespace, key.setName)
 
-	command := newReadCommand(clnt.cluster, policy, key, binNames)
-	if err := command.Execute(); err != nil {
+	// only whole records are cached
+	rc := clnt.cluster.getRecordCache()
+	if rc == nil || len(binNames) > 0 || policy.Txn != nil {
+		rc = nil
+	}
+
+	var invalidations uint64
+	if rc != nil {
+		var rec *Record
+		if rec, invalidations = rc.get(key); rec != nil {
+			return rec, nil
+		}
+	}
+
+	read := func() (*Record, error) {
+		command, err := clnt.executeHedged(policy, key, func(policy *BasePolicy) hedgeableCommand {
+			return newReadCommand(clnt.cluster, policy, key, binNames)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		rec := command.(*readCommand).GetRecord()
+		if len(binNames) == 0 {
+			if err := clnt.cluster.verifyRecordChecksums(rec); err != nil {
+				return nil, err
+			}
+		}
+		return rec, nil
+	}
+
+	var rec *Record
+	var err error
+	if policy.DeduplicateReads && policy.Txn == nil {
+		rec, err = clnt.cluster.reads.do(policy.Context, key, binNames, read)
+	} else {
+		rec, err = read()
+	}
+	if err != nil {
 		return nil, err
 	}
-	return command.GetRecord(), nil
+
+	if rc != nil {
+		rc.put(key, rec, invalidations)
+	}
+	return rec, nil
 }
 
 // GetObject reads a record for specified key and puts the result into the provided object.
 // The policy can be used to specify timeouts.
 // If the policy is nil, the default relevant policy will be used.
 func (clnt *Client) GetObject(policy *BasePolicy, key *Key, obj interface{}) error {
-	policy = clnt.getUsablePolicy(policy)
+	policy = clnt.getUsablePolicyFor(policy, key.namespace, key.setName)
 
 	rval := reflect.ValueOf(obj)
 	cacheObjectTags(rval)
@@ -334,13 +451,15 @@ func (clnt *Client) GetObject(policy *BasePolicy, key *Key, obj interface{}) err
 // The policy can be used to specify timeouts.
 // If the policy is nil, the default relevant policy will be used.
 func (clnt *Client) GetHeader(policy *BasePolicy, key *Key) (*Record, error) {
-	policy = clnt.getUsablePolicy(policy)
+	policy = clnt.getUsablePolicyFor(policy, key.namespace, key.setName)
 
-	command := newReadHeaderCommand(clnt.cluster, policy, key)
-	if err := command.Execute(); err != nil {
+	command, err := clnt.executeHedged(policy, key, func(policy *BasePolicy) hedgeableCommand {
+		return newReadHeaderCommand(clnt.cluster, policy, key)
+	})
+	if err != nil {
 		return nil, err
 	}
-	return command.GetRecord(), nil
+	return command.(*readHeaderCommand).GetRecord(), nil
 }
 
 //-------------------------------------------------------
@@ -353,7 +472,8 @@ func (clnt *Client) GetHeader(policy *BasePolicy, key *Key) (*Record, error) {
 // The policy can be used to specify timeouts.
 // If the policy is nil, the default relevant policy will be used.
 func (clnt *Client) BatchGet(policy *BasePolicy, keys []*Key, binNames ...string) ([]*Record, error) {
-	policy = clnt.getUsablePolicy(policy)
+	policy = clnt.getUsablePolicyFor(policy, keysNamespace(keys), "")
+	keys, positions := batchKeys(policy, keys)
 
 	// same array can be used without synchronization;
 	// when a key exists, the corresponding index will be set to record
@@ -365,13 +485,19 @@ func (clnt *Client) BatchGet(policy *BasePolicy, keys []*Key, binNames ...string
 	}
 
 	err := clnt.batchExecute(keys, func(node *Node, bns *batchNamespace) command {
-		return newBatchCommandGet(node, bns, policy, keys, binSet, records, _INFO1_READ)
+		return newBatchCommandGet(node, bns, policy, keys, binSet, nil, records, _INFO1_READ)
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	return records, nil
+	if len(binNames) == 0 {
+		if err := clnt.cluster.verifyRecordChecksums(records...); err != nil {
+			return nil, err
+		}
+	}
+
+	return unsortRecords(records, positions), nil
 }
 
 // BatchGetHeader reads multiple record header data for specified keys in one batch request.
@@ -380,20 +506,68 @@ func (clnt *Client) BatchGet(policy *BasePolicy, keys []*Key, binNames ...string
 // The policy can be used to specify timeouts.
 // If the policy is nil, the default relevant policy will be used.
 func (clnt *Client) BatchGetHeader(policy *BasePolicy, keys []*Key) ([]*Record, error) {
-	policy = clnt.getUsablePolicy(policy)
+	policy = clnt.getUsablePolicyFor(policy, keysNamespace(keys), "")
+	keys, positions := batchKeys(policy, keys)
+
+	// same array can be used without synchronization;
+	// when a key exists, the corresponding index will be set to record
+	records := make([]*Record, len(keys))
+
+	err := clnt.batchExecute(keys, func(node *Node, bns *batchNamespace) command {
+		return newBatchCommandGet(node, bns, policy, keys, nil, nil, records, _INFO1_READ|_INFO1_NOBINDATA)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return unsortRecords(records, positions), nil
+}
+
+// BatchGetOperate reads multiple records for specified keys in one batch request,
+// applying the same read operations to every record.
+// Only read operations, including CDT reads, are allowed; a PARAMETER_ERROR
+// is returned otherwise.
+// The returned records are in positional order with the original key array order.
+// If a key is not found, the positional record will be nil.
+// The policy can be used to specify timeouts.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *Client) BatchGetOperate(policy *BasePolicy, keys []*Key, operations ...*Operation) ([]*Record, error) {
+	policy = clnt.getUsablePolicyFor(policy, keysNamespace(keys), "")
+
+	readAttr := _INFO1_READ
+	readBin := false
+	for _, operation := range operations {
+		if operation.OpType != READ && operation.OpType != CDT_READ {
+			return nil, NewAerospikeError(PARAMETER_ERROR, "Only read operations are allowed in batch requests.")
+		}
+
+		if !operation.headerOnly {
+			// Read all bins if no bin is specified.
+			if operation.BinName == "" {
+				readAttr |= _INFO1_GET_ALL
+			}
+			readBin = true
+		}
+	}
+
+	if len(operations) > 0 && !readBin {
+		readAttr |= _INFO1_NOBINDATA
+	}
+
+	keys, positions := batchKeys(policy, keys)
 
 	// same array can be used without synchronization;
 	// when a key exists, the corresponding index will be set to record
 	records := make([]*Record, len(keys))
 
 	err := clnt.batchExecute(keys, func(node *Node, bns *batchNamespace) command {
-		return newBatchCommandGet(node, bns, policy, keys, nil, records, _INFO1_READ|_INFO1_NOBINDATA)
+		return newBatchCommandGet(node, bns, policy, keys, nil, operations, records, readAttr)
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	return records, nil
+	return unsortRecords(records, positions), nil
 }
 
 //-------------------------------------------------------
@@ -407,25 +581,150 @@ func (clnt *Client) BatchGetHeader(policy *BasePolicy, keys []*Key) ([]*Record,
 // Write operations are always performed first, regardless of operation order
 // relative to read operations.
 // If the policy is nil, the default relevant policy will be used.
+//
+// The number of operations is limited to MaxOperations. Use OperateChunked
+// to perform more operations on the record.
 func (clnt *Client) Operate(policy *WritePolicy, key *Key, operations ...*Operation) (*Record, error) {
-	policy = clnt.getUsableWritePolicy(policy)
+	policy = clnt.getUsableWritePolicyFor(policy, key.namespace, key.setName)
+
+	if len(operations) > MaxOperations {
+		return nil, NewAerospikeError(PARAMETER_ERROR, fmt.Sprintf("Too many operations in command: %d. Maximum allowed is %d.", len(operations), MaxOperations))
+	}
+
+	command := newOperateCommand(clnt.cluster, policy, key, operations)
+	if err := command.Execute(); err != nil {
+		return nil, err
+	}
+	return command.GetRecord(), nil
+}
+
+// OperateOrdered works the same as Operate, and also returns the results of
+// the operations in the order they were returned in Record.OpResults,
+// including multiple results for the same bin, e.g. a list pop followed by a
+// list size. Record.Bins only holds the last result of each bin.
+// Operations which don't return a value, like writes, have no result.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *Client) OperateOrdered(policy *WritePolicy, key *Key, operations ...*Operation) (*Record, error) {
+	policy = clnt.getUsableWritePolicyFor(policy, key.namespace, key.setName)
+
+	if len(operations) > MaxOperations {
+		return nil, NewAerospikeError(PARAMETER_ERROR, fmt.Sprintf("Too many operations in command: %d. Maximum allowed is %d.", len(operations), MaxOperations))
+	}
+
 	command := newOperateCommand(clnt.cluster, policy, key, operations)
+	command.orderedResults = true
 	if err := command.Execute(); err != nil {
 		return nil, err
 	}
 	return command.GetRecord(), nil
 }
 
+// OperateFiltered works the same as Operate, but only applies the operations
+// if the record matches the FilterExpression of the policy; otherwise none of
+// them are applied. Operations which read and then write the record can be
+// sent with a filter on the bins they read, so the writes are a no-op if the
+// condition no longer holds.
+// Records filtered out are reported with a FILTERED_OUT error, or returned
+// with FilteredOut set and without bins if the policy sets ReturnFilteredOut.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *Client) OperateFiltered(policy *OperatePolicy, key *Key, operations ...*Operation) (*Record, error) {
+	if policy == nil {
+		policy = &OperatePolicy{WritePolicy: *clnt.getUsableWritePolicyFor(nil, key.namespace, key.setName)}
+	} else {
+		policy = policy.Clone()
+	}
+
+	if len(operations) > MaxOperations {
+		return nil, NewAerospikeError(PARAMETER_ERROR, fmt.Sprintf("Too many operations in command: %d. Maximum allowed is %d.", len(operations), MaxOperations))
+	}
+
+	command := newOperateCommand(clnt.cluster, &policy.WritePolicy, key, operations)
+	command.predExp = policy.FilterExpression
+	if err := command.Execute(); err != nil {
+		if ae, ok := err.(AerospikeError); ok && ae.ResultCode() == FILTERED_OUT && policy.ReturnFilteredOut {
+			rec := newRecord(nil, key, nil, 0, 0)
+			rec.FilteredOut = true
+			return rec, nil
+		}
+		return nil, err
+	}
+	return command.GetRecord(), nil
+}
+
+// OperateChunked works the same as Operate, but splits the operations into
+// consecutive chunks of at most MaxOperations operations, and sends each chunk
+// in a separate command.
+//
+// Every chunk after the first one is sent with the EXPECT_GEN_EQUAL generation
+// policy and the generation returned by the previous chunk, so it is only
+// applied if the record has not been modified in between by another client;
+// otherwise a GENERATION_ERROR is returned.
+//
+// Chunks are NOT applied atomically:
+//
+//	Reads in a chunk see the writes of that chunk and of the previous chunks,
+//	but not the writes of the following chunks.
+//	If a chunk fails, the chunks applied before it are not rolled back, and
+//	the record is left with their writes only.
+//
+// Bins read in different chunks are merged in the returned record.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *Client) OperateChunked(policy *WritePolicy, key *Key, operations ...*Operation) (*Record, error) {
+	policy = clnt.getUsableWritePolicyFor(policy, key.namespace, key.setName)
+
+	if len(operations) <= MaxOperations {
+		return clnt.Operate(policy, key, operations...)
+	}
+
+	// copy the policy to avoid changing the user's policy
+	chunkPolicy := *policy
+
+	var res *Record
+	for begin := 0; begin < len(operations); begin += MaxOperations {
+		end := begin + MaxOperations
+		if end > len(operations) {
+			end = len(operations)
+		}
+
+		rec, err := clnt.Operate(&chunkPolicy, key, operations[begin:end]...)
+		if err != nil {
+			return nil, err
+		}
+
+		if res == nil {
+			res = rec
+		} else if rec != nil {
+			for name, value := range rec.Bins {
+				res.Bins[name] = value
+			}
+			res.Generation = rec.Generation
+			res.Expiration = rec.Expiration
+		}
+
+		// the following chunks must not replace or fail on the record created by the previous chunks
+		chunkPolicy.RecordExistsAction = UPDATE
+
+		// make sure the next chunk is applied to the same version of the record
+		if rec != nil {
+			chunkPolicy.GenerationPolicy = EXPECT_GEN_EQUAL
+			chunkPolicy.Generation = int32(rec.Generation)
+		}
+	}
+
+	return res, nil
+}
+
 //-------------------------------------------------------
 // Scan Operations
 //-------------------------------------------------------
 
 // ScanAll reads all records in specified namespace and set from all nodes.
 // If the policy's concurrentNodes is specified, each server node will be read in
-// parallel. Otherwise, server nodes are read sequentially.
+// parallel, limited to MaxConcurrentNodes at a time. Otherwise, server nodes are
+// read sequentially.
 // If the policy is nil, the default relevant policy will be used.
 func (clnt *Client) ScanAll(apolicy *ScanPolicy, namespace string, setName string, binNames ...string) (*Recordset, error) {
-	policy := *clnt.getUsableScanPolicy(apolicy)
+	policy := *clnt.getUsableScanPolicyFor(apolicy, namespace, setName)
 
 	nodes := clnt.cluster.GetNodes()
 	if len(nodes) == 0 {
@@ -440,28 +739,30 @@ func (clnt *Client) ScanAll(apolicy *ScanPolicy, namespace string, setName strin
 	}
 
 	// result recordset
-	res := newRecordset(policy.RecordQueueSize, len(nodes))
+	res := newRecordset(policy.RecordQueueSize, len(nodes)*policy.socketsPerNode())
+	if policy.FailOnClusterChange {
+		if err := failOnClusterChange(res, nodes, policy.Timeout); err != nil {
+			return nil, err
+		}
+	}
+
+	taskId := newTaskId()
+	res.abortOnClose(nodes, scanAbortCommands(taskId, policy.socketsPerNode())...)
 
 	// the whole call should be wrapped in a goroutine
-	if policy.ConcurrentNodes {
+	go func() {
+		// scan at most maxConcurrentNodes nodes at a time
+		slots := make(chan struct{}, policy.maxConcurrentNodes(len(nodes)))
 		for _, node := range nodes {
+			slots <- struct{}{}
 			go func(node *Node) {
-				if err := clnt.scanNode(&policy, node, res, namespace, setName, binNames...); err != nil {
+				defer func() { <-slots }()
+				if err := clnt.scanNode(&policy, node, res, taskId, namespace, setName, binNames...); err != nil {
 					res.sendError(err)
 				}
 			}(node)
 		}
-	} else {
-		// scan nodes one by one
-		go func() {
-			for _, node := range nodes {
-				if err := clnt.scanNode(&policy, node, res, namespace, setName, binNames...); err != nil {
-					res.sendError(err)
-					continue
-				}
-			}
-		}()
-	}
+	}()
 
 	return res, nil
 }
@@ -469,28 +770,59 @@ func (clnt *Client) ScanAll(apolicy *ScanPolicy, namespace string, setName strin
 // ScanNode reads all records in specified namespace and set for one node only.
 // If the policy is nil, the default relevant policy will be used.
 func (clnt *Client) ScanNode(apolicy *ScanPolicy, node *Node, namespace string, setName string, binNames ...string) (*Recordset, error) {
-	policy := *clnt.getUsableScanPolicy(apolicy)
+	policy := *clnt.getUsableScanPolicyFor(apolicy, namespace, setName)
 
 	// results channel must be async for performance
-	res := newRecordset(policy.RecordQueueSize, 1)
+	res := newRecordset(policy.RecordQueueSize, policy.socketsPerNode())
+	if policy.FailOnClusterChange {
+		if err := failOnClusterChange(res, []*Node{node}, policy.Timeout); err != nil {
+			return nil, err
+		}
+	}
 
-	go clnt.scanNode(&policy, node, res, namespace, setName, binNames...)
+	taskId := newTaskId()
+	res.abortOnClose([]*Node{node}, scanAbortCommands(taskId, policy.socketsPerNode())...)
+
+	go clnt.scanNode(&policy, node, res, taskId, namespace, setName, binNames...)
 	return res, nil
 }
 
 // ScanNode reads all records in specified namespace and set for one node only.
 // If the policy is nil, the default relevant policy will be used.
-func (clnt *Client) scanNode(policy *ScanPolicy, node *Node, recordset *Recordset, namespace string, setName string, binNames ...string) error {
+// The scans of the slices of the node are sent with consecutive task ids.
+func (clnt *Client) scanNode(policy *ScanPolicy, node *Node, recordset *Recordset, taskId uint64, namespace string, setName string, binNames ...string) error {
+	sockets := policy.socketsPerNode()
+
 	if policy.WaitUntilMigrationsAreOver {
 		// wait until migrations on node are finished
 		if err := node.WaitUntillMigrationIsFinished(policy.Timeout); err != nil {
-			recordset.signalEnd()
+			for i := 0; i < sockets; i++ {
+				recordset.signalEnd()
+			}
 			return err
 		}
 	}
 
-	command := newScanCommand(node, policy, namespace, setName, binNames, recordset)
-	return command.Execute()
+	if sockets == 1 {
+		command := newScanCommand(node, policy, namespace, setName, binNames, taskId, recordset)
+		return command.Execute()
+	}
+
+	// scan slices of the records of the node in parallel
+	errChan := make(chan error, sockets)
+	for i := 0; i < sockets; i++ {
+		go func(policy *ScanPolicy, taskId uint64) {
+			errChan <- newScanCommand(node, policy, namespace, setName, binNames, taskId, recordset).Execute()
+		}(policy.slice(i, sockets), taskId+uint64(i))
+	}
+
+	var errs []error
+	for i := 0; i < sockets; i++ {
+		if err := <-errChan; err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return mergeErrors(errs)
 }
 
 //-------------------------------------------------------------------
@@ -544,6 +876,15 @@ func (clnt *Client) GetLargeStack(policy *WritePolicy, key *Key, binName string,
 	return NewLargeStack(clnt, policy, key, binName, userModule)
 }
 
+// GetRateCounter initializes a sliding window rate counter, stored in
+// the map bin binName of the record identified by key.
+// See RateCounter for details.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *Client) GetRateCounter(policy *WritePolicy, key *Key, binName string, window time.Duration, buckets int) (*RateCounter, error) {
+	policy = clnt.getUsableWritePolicy(policy)
+	return NewRateCounter(clnt, policy, key, binName, window, buckets)
+}
+
 //---------------------------------------------------------------
 // User defined functions (Supported by Aerospike 3 servers only)
 //---------------------------------------------------------------
@@ -596,7 +937,7 @@ func (clnt *Client) RegisterUDF(policy *WritePolicy, udfBody []byte, serverPath
 		return nil, err
 	}
 
-	conn, err := node.GetConnection(clnt.cluster.clientPolicy.Timeout)
+	conn, err := node.GetConnection(clnt.cluster.policy().Timeout)
 	if err != nil {
 		return nil, err
 	}
@@ -656,7 +997,7 @@ func (clnt *Client) RemoveUDF(policy *WritePolicy, udfName string) (*RemoveTask,
 		return nil, err
 	}
 
-	conn, err := node.GetConnection(clnt.cluster.clientPolicy.Timeout)
+	conn, err := node.GetConnection(clnt.cluster.policy().Timeout)
 	if err != nil {
 		return nil, err
 	}
@@ -698,7 +1039,7 @@ func (clnt *Client) ListUDF(policy *BasePolicy) ([]*UDF, error) {
 		return nil, err
 	}
 
-	conn, err := node.GetConnection(clnt.cluster.clientPolicy.Timeout)
+	conn, err := node.GetConnection(clnt.cluster.policy().Timeout)
 	if err != nil {
 		return nil, err
 	}
@@ -746,16 +1087,70 @@ func (clnt *Client) ListUDF(policy *BasePolicy) ([]*UDF, error) {
 	return res, nil
 }
 
+// GetUDF returns the source code of a registered package containing
+// user defined functions, as stored on the server.
+// This method is only supported by Aerospike 3 servers.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *Client) GetUDF(policy *BasePolicy, udfName string) ([]byte, error) {
+	policy = clnt.getUsablePolicy(policy)
+
+	var strCmd bytes.Buffer
+	// errors are to remove errcheck warnings
+	// they will always be nil as stated in golang docs
+	_, err := strCmd.WriteString("udf-get:filename=")
+	_, err = strCmd.WriteString(udfName)
+	_, err = strCmd.WriteString(";")
+
+	node, err := clnt.cluster.GetRandomNode()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := node.GetConnection(clnt.cluster.policy().Timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	responseMap, err := RequestInfo(conn, strCmd.String())
+	if err != nil {
+		node.InvalidateConnection(conn)
+		return nil, err
+	}
+	node.PutConnection(conn)
+
+	var response string
+	for _, v := range responseMap {
+		if strings.Trim(v, " ") != "" {
+			response = v
+		}
+	}
+
+	res := parseInfoParams(response)
+	if errMsg, exists := res["error"]; exists {
+		return nil, NewAerospikeError(UDF_BAD_RESPONSE, "Getting UDF failed: "+errMsg)
+	}
+
+	content, exists := res["content"]
+	if !exists {
+		return nil, NewAerospikeError(UDF_BAD_RESPONSE, "Getting UDF failed: "+response)
+	}
+
+	return base64.StdEncoding.DecodeString(content)
+}
+
 // Execute executes a user defined function on server and return results.
 // The function operates on a single record.
 // The package name is used to locate the udf file location:
 //
 // udf file = <server udf dir>/<package name>.lua
 //
+// Lua lists and maps are returned as []interface{} and
+// map[interface{}]interface{} values, and numbers as int or float64;
+// use ExecuteObject or DecodeUDFResult to decode them into Go types.
 // This method is only supported by Aerospike 3 servers.
 // If the policy is nil, the default relevant policy will be used.
 func (clnt *Client) Execute(policy *WritePolicy, key *Key, packageName string, functionName string, args ...Value) (interface{}, error) {
-	policy = clnt.getUsableWritePolicy(policy)
+	policy = clnt.getUsableWritePolicyFor(policy, key.namespace, key.setName)
 	command := newExecuteCommand(clnt.cluster, policy, key, packageName, functionName, args)
 	if err := command.Execute(); err != nil {
 		return nil, err
@@ -763,11 +1158,17 @@ func (clnt *Client) Execute(policy *WritePolicy, key *Key, packageName string, f
 
 	record := command.GetRecord()
 
-	if record == nil || len(record.Bins) == 0 {
+	if record == nil {
 		return nil, nil
 	}
+	return udfResult(record.Bins)
+}
 
-	resultMap := record.Bins
+// udfResult returns the value returned by a UDF, from the bins of its response.
+func udfResult(resultMap BinMap) (interface{}, error) {
+	if len(resultMap) == 0 {
+		return nil, nil
+	}
 
 	// User defined functions don't have to return a value.
 	if exists, obj := mapContainsKeyPartial(resultMap, "SUCCESS"); exists {
@@ -808,7 +1209,7 @@ func (clnt *Client) ExecuteUDF(policy *QueryPolicy,
 	functionName string,
 	functionArgs ...Value,
 ) (*ExecuteTask, error) {
-	policy = clnt.getUsableQueryPolicy(policy)
+	policy = clnt.getUsableQueryPolicyFor(policy, statement)
 
 	nodes := clnt.cluster.GetNodes()
 	if len(nodes) == 0 {
@@ -820,17 +1221,19 @@ func (clnt *Client) ExecuteUDF(policy *QueryPolicy,
 		return nil, err
 	}
 
-	statement.SetAggregateFunction(packageName, functionName, functionArgs, false)
+	// copy the statement to avoid race conditions when it is shared between goroutines
+	stmt := *statement
+	stmt.SetAggregateFunction(packageName, functionName, functionArgs, false)
 
 	errs := []error{}
 	for i := range nodes {
-		command := newServerCommand(nodes[i], policy, statement)
+		command := newServerCommand(nodes[i], policy, &stmt)
 		if err := command.Execute(); err != nil {
 			errs = append(errs, err)
 		}
 	}
 
-	return NewExecuteTask(clnt.cluster, statement), mergeErrors(errs)
+	return NewExecuteTask(clnt.cluster, &stmt), mergeErrors(errs)
 }
 
 //--------------------------------------------------------
@@ -845,7 +1248,7 @@ func (clnt *Client) ExecuteUDF(policy *QueryPolicy,
 // This method is only supported by Aerospike 3 servers.
 // If the policy is nil, the default relevant policy will be used.
 func (clnt *Client) Query(policy *QueryPolicy, statement *Statement) (*Recordset, error) {
-	policy = clnt.getUsableQueryPolicy(policy)
+	policy = clnt.getUsableQueryPolicyFor(policy, statement)
 
 	nodes := clnt.cluster.GetNodes()
 	if len(nodes) == 0 {
@@ -861,6 +1264,12 @@ func (clnt *Client) Query(policy *QueryPolicy, statement *Statement) (*Recordset
 
 	// results channel must be async for performance
 	recSet := newRecordset(policy.RecordQueueSize, len(nodes))
+	if policy.FailOnClusterChange {
+		if err := failOnClusterChange(recSet, nodes, policy.Timeout); err != nil {
+			return nil, err
+		}
+	}
+	recSet.abortOnClose(nodes, queryAbortCommands(statement)...)
 
 	// results channel must be async for performance
 	for _, node := range nodes {
@@ -885,7 +1294,7 @@ func (clnt *Client) Query(policy *QueryPolicy, statement *Statement) (*Recordset
 // This method is only supported by Aerospike 3 servers.
 // If the policy is nil, the default relevant policy will be used.
 func (clnt *Client) QueryNode(policy *QueryPolicy, node *Node, statement *Statement) (*Recordset, error) {
-	policy = clnt.getUsableQueryPolicy(policy)
+	policy = clnt.getUsableQueryPolicyFor(policy, statement)
 
 	if policy.WaitUntilMigrationsAreOver {
 		// wait until all migrations are finished
@@ -896,6 +1305,12 @@ func (clnt *Client) QueryNode(policy *QueryPolicy, node *Node, statement *Statem
 
 	// results channel must be async for performance
 	recSet := newRecordset(policy.RecordQueueSize, 1)
+	if policy.FailOnClusterChange {
+		if err := failOnClusterChange(recSet, []*Node{node}, policy.Timeout); err != nil {
+			return nil, err
+		}
+	}
+	recSet.abortOnClose([]*Node{node}, queryAbortCommands(statement)...)
 
 	// copy policies to avoid race conditions
 	newPolicy := *policy
@@ -944,28 +1359,36 @@ func (clnt *Client) CreateIndex(
 	binName string,
 	indexType IndexType,
 ) (*IndexTask, error) {
-	policy = clnt.getUsableWritePolicy(policy)
+	return clnt.CreateComplexIndex(policy, namespace, setName, indexName, binName, indexType, ICT_DEFAULT)
+}
 
-	var strCmd bytes.Buffer
-	_, err := strCmd.WriteString("sindex-create:ns=")
-	_, err = strCmd.WriteString(namespace)
+// CreateComplexIndex creates a secondary index on the elements of list or
+// map bins, as selected by the collection type. With a CDT context, the
+// values of the nested list or map element selected by the context are
+// indexed instead of the top level value of the bin.
+// Query the index with filters of the same collection type and context,
+// eg: NewContainsFilter.
+// CDT context indexes need Aerospike 6.1 servers or newer.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *Client) CreateComplexIndex(
+	policy *WritePolicy,
+	namespace string,
+	setName string,
+	indexName string,
+	binName string,
+	indexType IndexType,
+	indexCollectionType IndexCollectionType,
+	ctx ...*CDTContext,
+) (*IndexTask, error) {
+	policy = clnt.getUsableWritePolicy(policy)
 
-	if len(setName) > 0 {
-		_, err = strCmd.WriteString(";set=")
-		_, err = strCmd.WriteString(setName)
+	strCmd, err := createIndexCommand(namespace, setName, indexName, binName, indexType, indexCollectionType, ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	_, err = strCmd.WriteString(";indexname=")
-	_, err = strCmd.WriteString(indexName)
-	_, err = strCmd.WriteString(";numbins=1")
-	_, err = strCmd.WriteString(";indexdata=")
-	_, err = strCmd.WriteString(binName)
-	_, err = strCmd.WriteString(",")
-	_, err = strCmd.WriteString(string(indexType))
-	_, err = strCmd.WriteString(";priority=normal")
-
 	// Send index command to one node. That node will distribute the command to other nodes.
-	responseMap, err := clnt.sendInfoCommand(policy, strCmd.String())
+	responseMap, err := clnt.sendInfoCommand(policy, strCmd)
 	if err != nil {
 		return nil, err
 	}
@@ -988,6 +1411,126 @@ func (clnt *Client) CreateIndex(
 	return nil, NewAerospikeError(INDEX_GENERIC, "Create index failed: "+response)
 }
 
+func createIndexCommand(
+	namespace string,
+	setName string,
+	indexName string,
+	binName string,
+	indexType IndexType,
+	indexCollectionType IndexCollectionType,
+	ctx []*CDTContext,
+) (string, error) {
+	var strCmd bytes.Buffer
+	strCmd.WriteString("sindex-create:ns=")
+	strCmd.WriteString(namespace)
+
+	if len(setName) > 0 {
+		strCmd.WriteString(";set=")
+		strCmd.WriteString(setName)
+	}
+
+	strCmd.WriteString(";indexname=")
+	strCmd.WriteString(indexName)
+
+	if len(ctx) > 0 {
+		packed, err := base64CDTContext(ctx)
+		if err != nil {
+			return "", err
+		}
+		strCmd.WriteString(";context=")
+		strCmd.WriteString(packed)
+	}
+
+	if indexCollectionType != ICT_DEFAULT {
+		strCmd.WriteString(";indextype=")
+		strCmd.WriteString(indexCollectionType.String())
+	}
+
+	strCmd.WriteString(";numbins=1")
+	strCmd.WriteString(";indexdata=")
+	strCmd.WriteString(binName)
+	strCmd.WriteString(",")
+	strCmd.WriteString(string(indexType))
+	strCmd.WriteString(";priority=normal")
+
+	return strCmd.String(), nil
+}
+
+// CreateIndexIfNotExists creates a secondary index, tolerating the case where
+// an index with the same name already exists on the server.
+// If the existing index does not match the requested definition (set, bin and
+// index type), an INDEX_FOUND error is returned.
+// In both cases the returned IndexTask can be used to wait until the index
+// has been fully built on all nodes.
+// This method is only supported by Aerospike 3 servers.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *Client) CreateIndexIfNotExists(
+	policy *WritePolicy,
+	namespace string,
+	setName string,
+	indexName string,
+	binName string,
+	indexType IndexType,
+) (*IndexTask, error) {
+	task, err := clnt.CreateIndex(policy, namespace, setName, indexName, binName, indexType)
+	if err == nil {
+		return task, nil
+	}
+
+	if ae, ok := err.(AerospikeError); !ok || ae.ResultCode() != INDEX_FOUND {
+		return nil, err
+	}
+
+	policy = clnt.getUsableWritePolicy(policy)
+	responseMap, err := clnt.sendInfoCommand(policy, "sindex")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, response := range responseMap {
+		def := findIndexDefinition(response, namespace, indexName)
+		if def == nil {
+			break
+		}
+
+		if def["set"] != setName && !(setName == "" && def["set"] == "NULL") {
+			return nil, NewAerospikeError(INDEX_FOUND, "Index "+indexName+" already exists on set "+def["set"])
+		}
+
+		if def["bins"] != binName && def["bin"] != binName {
+			return nil, NewAerospikeError(INDEX_FOUND, "Index "+indexName+" already exists on bin "+def["bins"])
+		}
+
+		if !strings.EqualFold(def["type"], string(indexType)) {
+			return nil, NewAerospikeError(INDEX_FOUND, "Index "+indexName+" already exists with type "+def["type"])
+		}
+
+		return NewIndexTask(clnt.cluster, namespace, indexName), nil
+	}
+
+	return nil, NewAerospikeError(INDEX_FOUND)
+}
+
+// findIndexDefinition looks up an index definition in the response of a
+// `sindex` info command. Definitions are separated by ';' and their fields
+// by ':', eg: ns=test:set=demo:indexname=idx:num_bins=1:bins=bin1:type=NUMERIC:...
+func findIndexDefinition(response, namespace, indexName string) map[string]string {
+	for _, idx := range strings.Split(response, ";") {
+		def := map[string]string{}
+		for _, field := range strings.Split(idx, ":") {
+			kv := strings.SplitN(field, "=", 2)
+			if len(kv) == 2 {
+				def[kv[0]] = kv[1]
+			}
+		}
+
+		if def["ns"] == namespace && def["indexname"] == indexName {
+			return def
+		}
+	}
+	return nil
+}
+
 // DropIndex deletes a secondary index.
 // This method is only supported by Aerospike 3 servers.
 // If the policy is nil, the default relevant policy will be used.
@@ -1120,6 +1663,73 @@ func (clnt *Client) QueryUsers(policy *AdminPolicy) ([]*UserRoles, error) {
 	return command.queryUsers(clnt.cluster, policy)
 }
 
+// CreateRole creates a user defined role with its privileges, whitelist and quotas.
+// Whitelist and quotas are optional; they require a server supporting them.
+func (clnt *Client) CreateRole(policy *AdminPolicy, role *RoleInfo) error {
+	policy = clnt.getUsableAdminPolicy(policy)
+
+	command := newAdminCommand()
+	return command.createRole(clnt.cluster, policy, role)
+}
+
+// DropRole removes a user defined role from the cluster.
+func (clnt *Client) DropRole(policy *AdminPolicy, roleName string) error {
+	policy = clnt.getUsableAdminPolicy(policy)
+
+	command := newAdminCommand()
+	return command.dropRole(clnt.cluster, policy, roleName)
+}
+
+// GrantPrivileges adds privileges to a user defined role.
+func (clnt *Client) GrantPrivileges(policy *AdminPolicy, roleName string, privileges []Privilege) error {
+	policy = clnt.getUsableAdminPolicy(policy)
+
+	command := newAdminCommand()
+	return command.grantPrivileges(clnt.cluster, policy, roleName, privileges)
+}
+
+// RevokePrivileges removes privileges from a user defined role.
+func (clnt *Client) RevokePrivileges(policy *AdminPolicy, roleName string, privileges []Privilege) error {
+	policy = clnt.getUsableAdminPolicy(policy)
+
+	command := newAdminCommand()
+	return command.revokePrivileges(clnt.cluster, policy, roleName, privileges)
+}
+
+// SetWhitelist sets the IP addresses the users of the role may connect from.
+// An empty whitelist allows all addresses.
+func (clnt *Client) SetWhitelist(policy *AdminPolicy, roleName string, whitelist []string) error {
+	policy = clnt.getUsableAdminPolicy(policy)
+
+	command := newAdminCommand()
+	return command.setWhitelist(clnt.cluster, policy, roleName, whitelist)
+}
+
+// SetQuotas sets the maximum reads and writes per second allowed for the users
+// of the role. Zero means no limit.
+func (clnt *Client) SetQuotas(policy *AdminPolicy, roleName string, readQuota, writeQuota uint32) error {
+	policy = clnt.getUsableAdminPolicy(policy)
+
+	command := newAdminCommand()
+	return command.setQuotas(clnt.cluster, policy, roleName, readQuota, writeQuota)
+}
+
+// QueryRole retrieves the privileges, whitelist and quotas of a role.
+func (clnt *Client) QueryRole(policy *AdminPolicy, roleName string) (*RoleInfo, error) {
+	policy = clnt.getUsableAdminPolicy(policy)
+
+	command := newAdminCommand()
+	return command.queryRole(clnt.cluster, policy, roleName)
+}
+
+// QueryRoles retrieves all roles and their privileges, whitelists and quotas.
+func (clnt *Client) QueryRoles(policy *AdminPolicy) ([]*RoleInfo, error) {
+	policy = clnt.getUsableAdminPolicy(policy)
+
+	command := newAdminCommand()
+	return command.queryRoles(clnt.cluster, policy)
+}
+
 //-------------------------------------------------------
 // Internal Methods
 //-------------------------------------------------------
@@ -1161,24 +1771,29 @@ func (clnt *Client) batchExecute(keys []*Key, cmdGen func(node *Node, bns *batch
 
 	var wg sync.WaitGroup
 
-	// Use a goroutine per namespace per node
+	// Use a goroutine per sub-batch per namespace per node
 	errs := []error{}
 	errm := new(sync.Mutex)
 
-	wg.Add(len(batchNodes))
 	for _, batchNode := range batchNodes {
-		// copy to avoid race condition
-		bn := *batchNode
-		for _, bns := range bn.BatchNamespaces {
-			go func(bn *Node, bns *batchNamespace) {
-				defer wg.Done()
-				command := cmdGen(bn, bns)
-				if err := command.Execute(); err != nil {
-					errm.Lock()
-					errs = append(errs, err)
-					errm.Unlock()
-				}
-			}(bn.Node, bns)
+		node := batchNode.Node
+		limit := node.batchSizeLimit()
+		for _, bns := range batchNode.BatchNamespaces {
+			for _, sub := range bns.split(limit) {
+				wg.Add(1)
+				go func(node *Node, bns *batchNamespace) {
+					defer wg.Done()
+					command := cmdGen(node, bns)
+					start := time.Now()
+					err := command.Execute()
+					node.adaptBatchSize(bns.offsetSize, time.Since(start), err)
+					if err != nil {
+						errm.Lock()
+						errs = append(errs, err)
+						errm.Unlock()
+					}
+				}(node, sub)
+			}
 		}
 	}
 
@@ -1186,54 +1801,67 @@ func (clnt *Client) batchExecute(keys []*Key, cmdGen func(node *Node, bns *batch
 	return mergeErrors(errs)
 }
 
+// The getUsable*Policy functions return a copy of the policy, or of the
+// default policy if the policy is nil. Commands only use the copy, so
+// callers may modify their policies once the call has returned.
 func (clnt *Client) getUsablePolicy(policy *BasePolicy) *BasePolicy {
 	if policy == nil {
-		if clnt.DefaultPolicy != nil {
-			return clnt.DefaultPolicy
+		if clnt.DefaultPolicy == nil {
+			return NewPolicy()
 		}
-		return NewPolicy()
+		policy = clnt.DefaultPolicy
 	}
-	return policy
+	return policy.Clone()
 }
 
 func (clnt *Client) getUsableWritePolicy(policy *WritePolicy) *WritePolicy {
 	if policy == nil {
-		if clnt.DefaultWritePolicy != nil {
-			return clnt.DefaultWritePolicy
+		if clnt.DefaultWritePolicy == nil {
+			return NewWritePolicy(0, 0)
 		}
-		return NewWritePolicy(0, 0)
+		policy = clnt.DefaultWritePolicy
 	}
-	return policy
+	return policy.Clone()
 }
 
 func (clnt *Client) getUsableScanPolicy(policy *ScanPolicy) *ScanPolicy {
 	if policy == nil {
-		if clnt.DefaultScanPolicy != nil {
-			return clnt.DefaultScanPolicy
+		if clnt.DefaultScanPolicy == nil {
+			return NewScanPolicy()
 		}
-		return NewScanPolicy()
+		policy = clnt.DefaultScanPolicy
 	}
-	return policy
+	return policy.Clone()
 }
 
 func (clnt *Client) getUsableQueryPolicy(policy *QueryPolicy) *QueryPolicy {
 	if policy == nil {
-		if clnt.DefaultQueryPolicy != nil {
-			return clnt.DefaultQueryPolicy
+		if clnt.DefaultQueryPolicy == nil {
+			return NewQueryPolicy()
 		}
-		return NewQueryPolicy()
+		policy = clnt.DefaultQueryPolicy
 	}
-	return policy
+	return policy.Clone()
 }
 
 func (clnt *Client) getUsableAdminPolicy(policy *AdminPolicy) *AdminPolicy {
 	if policy == nil {
-		if clnt.DefaultAdminPolicy != nil {
-			return clnt.DefaultAdminPolicy
+		if clnt.DefaultAdminPolicy == nil {
+			return NewAdminPolicy()
+		}
+		policy = clnt.DefaultAdminPolicy
+	}
+	return policy.Clone()
+}
+
+func (clnt *Client) getUsableInfoPolicy(policy *InfoPolicy) *InfoPolicy {
+	if policy == nil {
+		if clnt.DefaultInfoPolicy == nil {
+			return NewInfoPolicy()
 		}
-		return NewAdminPolicy()
+		policy = clnt.DefaultInfoPolicy
 	}
-	return policy
+	return policy.Clone()
 }
 
 //-------------------------------------------------------
@@ -0,0 +1,169 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"io"
+	"time"
+)
+
+// ClientIface is the public interface of Client.
+// Applications can depend on it instead of *Client to be able to replace
+// the client with a mock in their unit tests.
+type ClientIface interface {
+	Close()
+	IsConnected() bool
+	GetNodes() []*Node
+	UpdatePolicy(policy *ClientPolicy) error
+	GetNodeNames() []string
+	GetConnectionCount() int
+	RequestInfoAny(policy *InfoPolicy, names ...string) (map[string]string, error)
+	About(policy *InfoPolicy) (*AboutInfo, error)
+
+	Put(policy *WritePolicy, key *Key, binMap BinMap) error
+	PutBins(policy *WritePolicy, key *Key, bins ...*Bin) error
+	PutObject(policy *WritePolicy, key *Key, obj interface{}) error
+	Append(policy *WritePolicy, key *Key, binMap BinMap) error
+	AppendBins(policy *WritePolicy, key *Key, bins ...*Bin) error
+	Prepend(policy *WritePolicy, key *Key, binMap BinMap) error
+	PrependBins(policy *WritePolicy, key *Key, bins ...*Bin) error
+	Add(policy *WritePolicy, key *Key, binMap BinMap) error
+	AddBins(policy *WritePolicy, key *Key, bins ...*Bin) error
+	Delete(policy *WritePolicy, key *Key) (bool, error)
+	Move(policy *WritePolicy, srcKey, dstKey *Key) error
+
+	Commit(policy *WritePolicy, txn *Txn) error
+	Abort(policy *WritePolicy, txn *Txn) error
+	Touch(policy *WritePolicy, key *Key) error
+
+	Exists(policy *BasePolicy, key *Key) (bool, error)
+	BatchExists(policy *BasePolicy, keys []*Key) ([]bool, error)
+	Get(policy *BasePolicy, key *Key, binNames ...string) (*Record, error)
+	GetObject(policy *BasePolicy, key *Key, obj interface{}) error
+	GetHeader(policy *BasePolicy, key *Key) (*Record, error)
+	BatchGet(policy *BasePolicy, keys []*Key, binNames ...string) ([]*Record, error)
+	BatchGetHeader(policy *BasePolicy, keys []*Key) ([]*Record, error)
+	BatchGetOperate(policy *BasePolicy, keys []*Key, operations ...*Operation) ([]*Record, error)
+	BatchPut(policy *WritePolicy, writes []*BatchWrite) error
+	VerifyBatchWrites(policy *BasePolicy, writes []*BatchWrite) error
+	BatchUDF(policy *WritePolicy, keys []*Key, packageName string, functionName string, args ...Value) ([]*BatchUDFResult, error)
+
+	Operate(policy *WritePolicy, key *Key, operations ...*Operation) (*Record, error)
+	OperateOrdered(policy *WritePolicy, key *Key, operations ...*Operation) (*Record, error)
+	OperateFiltered(policy *OperatePolicy, key *Key, operations ...*Operation) (*Record, error)
+	OperateChunked(policy *WritePolicy, key *Key, operations ...*Operation) (*Record, error)
+	ExecuteRaw(policy *WritePolicy, raw *RawCommand) (*RawResponse, error)
+	MapIncrement(policy *WritePolicy, key *Key, binName string, mapKey interface{}, incr int) (int, error)
+	ReadModifyWrite(policy *RMWPolicy, key *Key, modify func(rec *Record) (BinMap, error)) error
+	RMWConflictStats() map[string]ConflictStats
+
+	PutEncrypted(policy *WritePolicy, encryptor *BinEncryptor, key *Key, binMap BinMap) error
+	GetDecrypted(policy *BasePolicy, encryptor *BinEncryptor, key *Key, binNames ...string) (*Record, error)
+
+	SetNamespaceHint(namespace string, hint *NamespaceHint)
+	GetNamespaceHint(namespace string) *NamespaceHint
+
+	SetRecordCache(policy *CachePolicy)
+	SetCompression(namespace, setName string, policy *CompressionPolicy) error
+	SetRecordChecksums(namespace, setName string, enabled bool)
+	InvalidateCachedRecords(keys ...*Key)
+
+	SetDefaultPolicy(namespace, setName string, policy *BasePolicy)
+	SetDefaultWritePolicy(namespace, setName string, policy *WritePolicy)
+	SetDefaultScanPolicy(namespace, setName string, policy *ScanPolicy)
+	SetDefaultQueryPolicy(namespace, setName string, policy *QueryPolicy)
+
+	PartitionErrors() []*PartitionErrors
+	PartitionErrorHeatmap(namespace string) []int64
+	ResetPartitionErrors()
+
+	ScanAll(policy *ScanPolicy, namespace string, setName string, binNames ...string) (*Recordset, error)
+	ScanNode(policy *ScanPolicy, node *Node, namespace string, setName string, binNames ...string) (*Recordset, error)
+	Backup(policy *BackupPolicy, w io.Writer, namespace string, setName string, binNames ...string) (*BackupStats, error)
+	Restore(policy *RestorePolicy, r io.Reader) (*RestoreStats, error)
+	AuditTTL(policy *TTLAuditPolicy, namespace string, setNames ...string) (map[string]*TTLReport, error)
+
+	WatchSet(policy *ChangeWatchPolicy, namespace string, setName string, binNames ...string) (*ChangeWatcher, error)
+	WatchKeys(policy *ChangeWatchPolicy, keys []*Key, binNames ...string) (*ChangeWatcher, error)
+	NewChangeTracker(namespace, setName, binName string) (*ChangeTracker, error)
+	NewWriteBuffer(policy *WriteBufferPolicy) (*WriteBuffer, error)
+
+	GetLargeList(policy *WritePolicy, key *Key, binName string, userModule string) *LargeList
+	GetLargeMap(policy *WritePolicy, key *Key, binName string, userModule string) *LargeMap
+	GetLargeSet(policy *WritePolicy, key *Key, binName string, userModule string) *LargeSet
+	GetLargeStack(policy *WritePolicy, key *Key, binName string, userModule string) *LargeStack
+	GetRateCounter(policy *WritePolicy, key *Key, binName string, window time.Duration, buckets int) (*RateCounter, error)
+
+	RegisterUDFFromFile(policy *WritePolicy, clientPath string, serverPath string, language Language) (*RegisterTask, error)
+	RegisterUDF(policy *WritePolicy, udfBody []byte, serverPath string, language Language) (*RegisterTask, error)
+	RemoveUDF(policy *WritePolicy, udfName string) (*RemoveTask, error)
+	ListUDF(policy *BasePolicy) ([]*UDF, error)
+	GetUDF(policy *BasePolicy, udfName string) ([]byte, error)
+	Execute(policy *WritePolicy, key *Key, packageName string, functionName string, args ...Value) (interface{}, error)
+	ExecuteObject(policy *WritePolicy, key *Key, result interface{}, packageName string, functionName string, args ...Value) error
+	ExecuteUDF(policy *QueryPolicy, statement *Statement, packageName string, functionName string, functionArgs ...Value) (*ExecuteTask, error)
+	UpdateTTL(policy *QueryPolicy, statement *Statement, ttl uint32) (*ExecuteTask, error)
+
+	Query(policy *QueryPolicy, statement *Statement) (*Recordset, error)
+	QueryNode(policy *QueryPolicy, node *Node, statement *Statement) (*Recordset, error)
+	QueryOrdered(policy *QueryPolicy, statement *Statement, binName string, descending bool) (*Recordset, error)
+
+	CreateIndex(policy *WritePolicy, namespace string, setName string, indexName string, binName string, indexType IndexType) (*IndexTask, error)
+	CreateComplexIndex(policy *WritePolicy, namespace string, setName string, indexName string, binName string, indexType IndexType, indexCollectionType IndexCollectionType, ctx ...*CDTContext) (*IndexTask, error)
+	CreateIndexIfNotExists(policy *WritePolicy, namespace string, setName string, indexName string, binName string, indexType IndexType) (*IndexTask, error)
+	DropIndex(policy *WritePolicy, namespace string, setName string, indexName string) error
+
+	CreateUser(policy *AdminPolicy, user string, password string, roles []string) error
+	DropUser(policy *AdminPolicy, user string) error
+	ChangePassword(policy *AdminPolicy, user string, password string) error
+	GrantRoles(policy *AdminPolicy, user string, roles []string) error
+	RevokeRoles(policy *AdminPolicy, user string, roles []string) error
+	QueryUser(policy *AdminPolicy, user string) (*UserRoles, error)
+	QueryUsers(policy *AdminPolicy) ([]*UserRoles, error)
+	CreateRole(policy *AdminPolicy, role *RoleInfo) error
+	DropRole(policy *AdminPolicy, roleName string) error
+	GrantPrivileges(policy *AdminPolicy, roleName string, privileges []Privilege) error
+	RevokePrivileges(policy *AdminPolicy, roleName string, privileges []Privilege) error
+	SetWhitelist(policy *AdminPolicy, roleName string, whitelist []string) error
+	SetQuotas(policy *AdminPolicy, roleName string, readQuota, writeQuota uint32) error
+	QueryRole(policy *AdminPolicy, roleName string) (*RoleInfo, error)
+	QueryRoles(policy *AdminPolicy) ([]*RoleInfo, error)
+
+	RequestLatency(policy *InfoPolicy, node *Node) ([]*Latency, error)
+	RequestHistogram(policy *InfoPolicy, node *Node, namespace string, histogramType HistogramType) (*Histogram, error)
+	GetNamespaceConfig(policy *InfoPolicy, node *Node, namespace string) (*NamespaceConfig, error)
+	Truncate(policy *InfoPolicy, namespace, setName string, beforeLastUpdate *time.Time) error
+	ListSets(policy *InfoPolicy, namespace string) ([]*SetCount, error)
+	CountRecords(policy *InfoPolicy, namespace, setName string) (*SetCount, error)
+	SetConfigParam(policy *InfoPolicy, node *Node, context string, name string, value string) error
+	SetNamespaceConfigParam(policy *InfoPolicy, node *Node, namespace string, name string, value string) error
+	GetRoster(policy *InfoPolicy, node *Node, namespace string) (*Roster, error)
+}
+
+var _ ClientIface = &Client{}
+
+// RecordsetIface is the interface to consume the results of Scan and Query commands.
+type RecordsetIface interface {
+	// Results returns a channel with the records and errors of the command.
+	Results() <-chan *Result
+
+	// IsActive returns true if the command hasn't been finished or cancelled.
+	IsActive() bool
+
+	// Close cancels the command and releases its resources.
+	Close()
+}
+
+var _ RecordsetIface = &Recordset{}
@@ -15,12 +15,19 @@
 package aerospike
 
 import (
+	"crypto/tls"
+	"net"
 	"time"
+
+	. "github.com/THE108/aerospike-client-go/types"
 )
 
 const defaultIdleTimeout = 14 * time.Second
 
 // ClientPolicy encapsulates parameters for client policy command.
+// The client keeps its own copy of the policy; changing it after the client
+// is created has no effect. Some settings can be changed on a live client
+// with Client.UpdatePolicy.
 type ClientPolicy struct {
 	// User authentication to cluster. Leave empty for clusters running without restricted access.
 	User string
@@ -29,6 +36,16 @@ type ClientPolicy struct {
 	// in hashed format. Leave empty for clusters running without restricted access.
 	Password string
 
+	// AuthMode determines how the client authenticates with the cluster.
+	// AuthModeExternal and AuthModePKI require TLSConfig to be set.
+	AuthMode AuthMode //= AuthModeInternal
+
+	// TLSConfig, if set, encrypts the connections to the server nodes with TLS.
+	// If its ServerName is empty, the host name of the node address is used
+	// to verify the server certificate.
+	// For AuthModePKI, it must contain the client certificate.
+	TLSConfig *tls.Config
+
 	// Initial host connection timeout in milliseconds.  The timeout when opening a connection
 	// to the server host for the first time.
 	Timeout time.Duration //= 1 second
@@ -38,6 +55,39 @@ type ClientPolicy struct {
 	// the connection will be closed and discarded from the connection pool.
 	IdleTimeout time.Duration //= 14 seconds
 
+	// IdlePingThreshold, if set, validates pooled connections which have not
+	// been used for longer than this duration with a lightweight info request
+	// before they are handed out. Connections failing the ping are discarded.
+	// It avoids failures of the first command after an idle period when load
+	// balancers or firewalls drop idle connections silently. It should be
+	// shorter than IdleTimeout, after which connections are always discarded.
+	IdlePingThreshold time.Duration //= 0 (disabled)
+
+	// TCPKeepAlive is the keep-alive period of connections to the server.
+	// Keep-alive probes detect nodes which died without closing their
+	// connections, which otherwise can take the OS many minutes.
+	// Zero uses the default period of the Go runtime; a negative value
+	// disables keep-alive.
+	TCPKeepAlive time.Duration //= 15 seconds
+
+	// DisableTCPNoDelay enables Nagle's algorithm on connections to the
+	// server. By default it is disabled, so commands are sent without delay.
+	DisableTCPNoDelay bool //= false
+
+	// SendBufferSize and ReceiveBufferSize set the size of the OS socket
+	// buffers of connections to the server. Zero uses the OS default.
+	SendBufferSize    int //= 0
+	ReceiveBufferSize int //= 0
+
+	// DialFunc, if set, is used to open all connections to the server nodes
+	// instead of dialing TCP directly, e.g. to connect through a proxy,
+	// a tunnel or a unix domain socket. The address is in `host:port` format.
+	// Host names of seed hosts are not resolved by the client when DialFunc
+	// is set; they are passed on to DialFunc as is.
+	// TCPKeepAlive is not applied to connections opened by DialFunc; the other
+	// TCP options are applied if DialFunc returns a *net.TCPConn.
+	DialFunc func(network, address string, timeout time.Duration) (net.Conn, error)
+
 	// Size of the Connection Queue cache.
 	ConnectionQueueSize int //= 256
 
@@ -45,27 +95,128 @@ type ClientPolicy struct {
 	// to the node if there are already `ConnectionQueueSize` active connections.
 	LimitConnectionsToQueueSize bool //= false
 
+	// MaxConcurrentConnectionOpens limits the number of connections opened
+	// to a node at the same time, to avoid connection storms when a node
+	// restarts with an empty pool. Commands wait for a pooled connection or
+	// a free slot within their timeout. Zero means no limit.
+	MaxConcurrentConnectionOpens int //= 16
+
+	// ServerConnectionsFraction, if set, stops connection pools from growing
+	// once the connections of all clients to a node, as reported by its
+	// client_connections statistic, reach this fraction of its proto-fd-max
+	// setting. Commands then wait for a pooled connection instead, and fail
+	// with NO_AVAILABLE_CONNECTIONS_TO_NODE if none is returned in time.
+	// It keeps a fleet of clients from exhausting the file descriptors of
+	// the servers. The statistics are refreshed on every tend.
+	// Valid values are between 0 and 1; zero disables the check.
+	ServerConnectionsFraction float64 //= 0 (disabled)
+
+	// ClusterName, if set, is the expected name of the cluster. Nodes reporting
+	// another cluster name are not added to the cluster, so clients configured
+	// with the wrong seed hosts do not connect to another cluster by mistake.
+	ClusterName string
+
+	// NodeSubset, if set, restricts the client to a subset of the nodes of the
+	// cluster, selected by their names or racks.
+	NodeSubset *NodeSubset
+
 	// Throw exception if host connection fails during addHost().
 	FailIfNotConnected bool //= true
 
 	// TendInterval determines interval for checking for cluster state changes.
 	// Minimum possible interval is 10 Miliseconds.
 	TendInterval time.Duration //= 1 second
+
+	// MaxKeysPerBatch limits the number of keys of a namespace sent to a node
+	// in one batch command. Larger batches are split into sub-batches which
+	// are executed in parallel. Zero means no limit.
+	// If AdaptiveBatchSize is set, it is the upper bound of the adaptive size.
+	MaxKeysPerBatch int //= 0
+
+	// AdaptiveBatchSize adapts the sub-batch size of each node to its recent
+	// response latency: the size grows additively while sub-batches complete
+	// within BatchLatencyTarget, and is halved when they are slower or time out.
+	AdaptiveBatchSize bool //= false
+
+	// BatchLatencyTarget is the latency of a sub-batch the adaptive batch
+	// size aims for. Zero uses the default of 100 milliseconds.
+	BatchLatencyTarget time.Duration //= 100 milliseconds
+
+	// NodeAddressChanged is called when a node, identified by its node name,
+	// reappears on a different address; e.g. after a restart with a new IP.
+	// The node object, its connection pool and its statistics are kept.
+	// The handler is called from the cluster tend goroutine and must not block.
+	NodeAddressChanged func(node *Node, oldHost, newHost *Host)
+
+	// NodeFeaturesChanged is called when the features reported by a node change,
+	// e.g. after a server upgrade or downgrade. Commands requiring a feature the
+	// node does not report fail with UNSUPPORTED_FEATURE on the client.
+	// The handler is called from the cluster tend goroutine and must not block.
+	NodeFeaturesChanged func(node *Node, added, removed []string)
+
+	// NodeSelector, if set, chooses the node single record commands are
+	// retried on after an attempt failed. By default, retries are sent to the
+	// master node of the record's partition again.
+	NodeSelector NodeSelector
+
+	// CommandObserver, if set, is called after each command that reached a node
+	// has finished, successfully or not. It can be used to feed metrics, slow
+	// logs and traces, labeled with the Baggage of the command's policy.
+	// The observer is called on the caller's goroutine and should return quickly.
+	CommandObserver func(event *CommandEvent)
+
+	// SlowCommandThreshold, if set, reports commands that reached a node and
+	// took longer than the threshold, including retries, to SlowCommandHandler.
+	SlowCommandThreshold time.Duration //= 0 (disabled)
+
+	// SlowCommandHandler is called with the details of slow commands, e.g.
+	// to log the offending keys. If nil, slow commands are logged as warnings.
+	// The handler is called on the caller's goroutine and should return quickly.
+	SlowCommandHandler func(event *CommandEvent)
 }
 
 // NewClientPolicy generates a new ClientPolicy with default values.
 func NewClientPolicy() *ClientPolicy {
 	return &ClientPolicy{
-		Timeout:                     time.Second,
-		IdleTimeout:                 defaultIdleTimeout,
-		ConnectionQueueSize:         256,
-		FailIfNotConnected:          true,
-		TendInterval:                time.Second,
-		LimitConnectionsToQueueSize: false,
+		Timeout:                      time.Second,
+		IdleTimeout:                  defaultIdleTimeout,
+		TCPKeepAlive:                 15 * time.Second,
+		ConnectionQueueSize:          256,
+		MaxConcurrentConnectionOpens: 16,
+		BatchLatencyTarget:           defaultBatchLatencyTarget,
+		FailIfNotConnected:           true,
+		TendInterval:                 time.Second,
+		LimitConnectionsToQueueSize:  false,
 	}
 }
 
-// RequiresAuthentication returns true if a USer or Password is set for ClientPolicy.
+// Clone returns a copy of the policy. Functions, the NodeSelector and the TLSConfig are shared.
+func (cp *ClientPolicy) Clone() *ClientPolicy {
+	res := *cp
+	return &res
+}
+
+// RequiresAuthentication returns true if a USer or Password is set for ClientPolicy,
+// or the client authenticates with its TLS certificate.
 func (cp *ClientPolicy) RequiresAuthentication() bool {
-	return (cp.User != "") || (cp.Password != "")
+	return (cp.User != "") || (cp.Password != "") || cp.AuthMode == AuthModePKI
+}
+
+// validateAuthMode checks the policy has what its AuthMode needs.
+func (cp *ClientPolicy) validateAuthMode() error {
+	switch cp.AuthMode {
+	case AuthModeInternal:
+		return nil
+	case AuthModeExternal:
+		if cp.TLSConfig == nil {
+			return NewAerospikeError(PARAMETER_ERROR, "External authentication requires TLSConfig; the password is sent in clear text")
+		}
+		return nil
+	case AuthModePKI:
+		if cp.TLSConfig == nil || (len(cp.TLSConfig.Certificates) == 0 && cp.TLSConfig.GetClientCertificate == nil) {
+			return NewAerospikeError(PARAMETER_ERROR, "PKI authentication requires TLSConfig with a client certificate")
+		}
+		return nil
+	}
+	return NewAerospikeError(PARAMETER_ERROR, "Invalid authentication mode")
 }
@@ -706,8 +706,173 @@ var _ = Describe("Aerospike", func() {
 				}
 			})
 
+			It("must apply the read operations to all the records", func() {
+				binRedundant := NewBin("Redundant", "Redundant")
+
+				keys := make([]*Key, 0, keyCount)
+				for i := 0; i < keyCount; i++ {
+					key, err := NewKey(ns, set, randString(50))
+					Expect(err).ToNot(HaveOccurred())
+					keys = append(keys, key)
+
+					err = client.PutBins(wpolicy, key, bin, binRedundant)
+					Expect(err).ToNot(HaveOccurred())
+				}
+
+				records, err := client.BatchGetOperate(rpolicy, keys, GetOpForBin(bin.Name))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(records)).To(Equal(len(keys)))
+				for _, rec := range records {
+					Expect(rec.Bins[binRedundant.Name]).To(BeNil())
+					Expect(rec.Bins[bin.Name]).To(Equal(bin.Value.GetObject()))
+				}
+
+				records, err = client.BatchGetOperate(rpolicy, keys, GetHeaderOp())
+				Expect(err).ToNot(HaveOccurred())
+				for _, rec := range records {
+					Expect(len(rec.Bins)).To(Equal(0))
+					Expect(rec.Generation).To(Equal(1))
+				}
+
+				_, err = client.BatchGetOperate(rpolicy, keys, PutOp(bin))
+				Expect(err).To(HaveOccurred())
+			})
+
+			It("must apply CDT read operations to all the records", func() {
+				keys := make([]*Key, 0, keyCount)
+				for i := 0; i < keyCount; i++ {
+					key, err := NewKey(ns, set, randString(50))
+					Expect(err).ToNot(HaveOccurred())
+					keys = append(keys, key)
+
+					_, err = client.Operate(wpolicy, key, MapIncrementOp(nil, "counters", "a", i), MapIncrementOp(nil, "counters", "b", 1))
+					Expect(err).ToNot(HaveOccurred())
+				}
+
+				records, err := client.BatchGetOperate(rpolicy, keys, MapGetByKeyRangeOp("counters", "a", "b", MAP_RETURN_VALUE))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(records)).To(Equal(len(keys)))
+				for i, rec := range records {
+					Expect(rec.Bins["counters"]).To(Equal([]interface{}{i}))
+				}
+			})
+
+			It("must map results of sorted and deduplicated keys to the original keys", func() {
+				keys := make([]*Key, 0, keyCount)
+				for i := 0; i < keyCount/2; i++ {
+					key, err := NewKey(ns, set, i)
+					Expect(err).ToNot(HaveOccurred())
+
+					if i%2 == 0 {
+						err = client.PutBins(wpolicy, key, NewBin("i", i))
+						Expect(err).ToNot(HaveOccurred())
+					}
+
+					// every key is requested twice
+					dup, _ := NewKey(ns, set, i)
+					keys = append(keys, key, dup)
+				}
+
+				policy := NewPolicy()
+				policy.SortBatchKeys = true
+
+				records, err := client.BatchGet(policy, keys)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(records)).To(Equal(len(keys)))
+				for idx, rec := range records {
+					if i := idx / 2; i%2 == 0 {
+						Expect(rec.Bins["i"]).To(Equal(i))
+					} else {
+						Expect(rec).To(BeNil())
+					}
+				}
+
+				exists, err := client.BatchExists(policy, keys)
+				Expect(err).ToNot(HaveOccurred())
+				for idx := range exists {
+					Expect(exists[idx]).To(Equal((idx/2)%2 == 0))
+				}
+			})
+
+			It("must split batches into sub-batches of adaptive size", func() {
+				cpolicy := *clientPolicy
+				cpolicy.AdaptiveBatchSize = true
+				cpolicy.MaxKeysPerBatch = 64
+
+				sclient, err := NewClientWithPolicy(&cpolicy, *host, *port)
+				Expect(err).ToNot(HaveOccurred())
+				defer sclient.Close()
+
+				keys := make([]*Key, 0, 500)
+				for i := 0; i < 500; i++ {
+					key, err := NewKey(ns, set, randString(50))
+					Expect(err).ToNot(HaveOccurred())
+					err = sclient.PutBins(wpolicy, key, NewBin("i", i))
+					Expect(err).ToNot(HaveOccurred())
+					keys = append(keys, key)
+				}
+
+				records, err := sclient.BatchGet(nil, keys)
+				Expect(err).ToNot(HaveOccurred())
+				for i, rec := range records {
+					Expect(rec.Bins["i"]).To(Equal(i))
+				}
+
+				for _, node := range sclient.GetNodes() {
+					Expect(node.BatchSize()).To(BeNumerically("<=", 64))
+				}
+			})
+
 		}) // Batch Get context
 
+		Context("Batch Put operations", func() {
+
+			It("must write the records and reconcile writes in doubt", func() {
+				writes := make([]*BatchWrite, 0, 100)
+				for i := 0; i < 100; i++ {
+					key, err := NewKey(ns, set, randString(50))
+					Expect(err).ToNot(HaveOccurred())
+					writes = append(writes, NewBatchWrite(key, BinMap{"i": i}))
+				}
+
+				err := client.BatchPut(wpolicy, writes)
+				Expect(err).ToNot(HaveOccurred())
+
+				for i, bw := range writes {
+					Expect(bw.Applied).To(BeTrue())
+
+					rec, err := client.Get(nil, bw.Key, "i")
+					Expect(err).ToNot(HaveOccurred())
+					Expect(rec.Bins["i"]).To(Equal(i))
+				}
+
+				// simulate a write in doubt which was applied, and one which was not
+				writes[0].Applied, writes[0].InDoubt = false, true
+				writes[1].Applied, writes[1].InDoubt = false, true
+				writes[1].Token = []byte("not applied")
+
+				err = client.VerifyBatchWrites(nil, writes)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(writes[0].Applied).To(BeTrue())
+				Expect(writes[0].Generation).To(Equal(1))
+				Expect(writes[1].Applied).To(BeFalse())
+				Expect(writes[1].InDoubt).To(BeFalse())
+
+				// replaying must only write the records not applied
+				err = client.BatchPut(wpolicy, writes)
+				Expect(err).ToNot(HaveOccurred())
+
+				rec, err := client.GetHeader(nil, writes[0].Key)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(rec.Generation).To(Equal(1))
+
+				rec, err = client.GetHeader(nil, writes[1].Key)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(rec.Generation).To(Equal(2))
+			})
+
+		})
+
 		Context("GetHeader operations", func() {
 			bin := NewBin("Aerospike", rand.Intn(math.MaxInt16))
 
@@ -938,7 +1103,107 @@ var _ = Describe("Aerospike", func() {
 				Expect(len(rec.Bins)).To(Equal(2))
 			})
 
-		}) // GetHeader context
+			It("must increment a map counter, creating it if absent", func() {
+				key, err := NewKey(ns, set, randString(50))
+				Expect(err).ToNot(HaveOccurred())
+
+				v, err := client.MapIncrement(nil, key, "counters", "hits", 3)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(v).To(Equal(3))
+
+				v, err = client.MapIncrement(nil, key, "counters", "hits", -1)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(v).To(Equal(2))
+			})
+
+			It("must apply concurrent read-modify-write cycles without losing updates", func() {
+				key, err := NewKey(ns, set, randString(50))
+				Expect(err).ToNot(HaveOccurred())
+
+				const workers = 5
+				errs := make(chan error, workers)
+				for i := 0; i < workers; i++ {
+					go func() {
+						errs <- client.ReadModifyWrite(nil, key, func(rec *Record) (BinMap, error) {
+							if rec == nil {
+								return BinMap{"count": 1}, nil
+							}
+							return BinMap{"count": rec.Bins["count"].(int) + 1}, nil
+						})
+					}()
+				}
+
+				for i := 0; i < workers; i++ {
+					Expect(<-errs).ToNot(HaveOccurred())
+				}
+
+				rec, err = client.Get(nil, key)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(rec.Bins["count"]).To(Equal(workers))
+
+				stats := client.RMWConflictStats()[ns+":"+set]
+				Expect(stats.Attempts).To(BeNumerically(">=", workers))
+			})
+
+			It("must encrypt configured bins on the client", func() {
+				key, err := NewKey(ns, set, randString(50))
+				Expect(err).ToNot(HaveOccurred())
+
+				kms := NewLocalKMS()
+				Expect(kms.AddKey(ns, set, "v1", []byte("0123456789abcdef"))).ToNot(HaveOccurred())
+				encryptor := NewBinEncryptor(kms)
+				encryptor.EncryptBins(ns, set, "ssn")
+
+				err = client.PutEncrypted(wpolicy, encryptor, key, BinMap{"ssn": "123-45-6789", "name": "John"})
+				Expect(err).ToNot(HaveOccurred())
+
+				rec, err = client.Get(nil, key)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(rec.Bins["ssn"]).To(BeAssignableToTypeOf([]byte{}))
+				Expect(rec.Bins[EncryptionKeyVersionBin]).To(Equal("v1"))
+
+				rec, err = client.GetDecrypted(nil, encryptor, key)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(rec.Bins).To(Equal(BinMap{"ssn": "123-45-6789", "name": "John"}))
+			})
+
+			It("must sort and dedup a list bin on the server", func() {
+				key, err := NewKey(ns, set, randString(50))
+				Expect(err).ToNot(HaveOccurred())
+
+				err = client.PutBins(wpolicy, key, NewBin("tags", []interface{}{"c", "a", "b", "a"}))
+				Expect(err).ToNot(HaveOccurred())
+
+				rec, err = client.Operate(nil, key, DedupListOp("tags"), GetOpForBin("tags"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(rec.Bins["tags"]).To(Equal([]interface{}{"a", "b", "c"}))
+			})
+
+		}) // Operate context
+
+		Context("OperateChunked operations", func() {
+
+			It("must reject more than MaxOperations operations, and apply them in chunks with OperateChunked", func() {
+				key, err := NewKey(ns, set, randString(50))
+				Expect(err).ToNot(HaveOccurred())
+
+				ops := []*Operation{}
+				for i := 0; i < MaxOperations+10; i++ {
+					ops = append(ops, AddOp(NewBin("Aerospike1", 1)))
+				}
+				ops = append(ops, GetOp())
+
+				rec, err = client.Operate(nil, key, ops...)
+				Expect(err).To(HaveOccurred())
+
+				rec, err = client.OperateChunked(nil, key, ops...)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(rec.Bins["Aerospike1"]).To(Equal(MaxOperations + 10))
+				Expect(rec.Generation).To(Equal(2))
+			})
+
+		}) // OperateChunked context
 
 	})
 })
@@ -0,0 +1,57 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike_test
+
+import (
+	"fmt"
+
+	. "github.com/THE108/aerospike-client-go"
+	"github.com/THE108/aerospike-client-go/aerotest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Client from connection string", func() {
+
+	var srv *aerotest.Server
+
+	BeforeEach(func() {
+		var err error
+		srv, err = aerotest.NewServer("test")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		srv.Close()
+	})
+
+	It("must connect to clusters with the expected name", func() {
+		client, err := NewClientFromURI(fmt.Sprintf("aerospike://%s/?clusterName=%s&timeout=1s", srv.Address(), aerotest.ClusterName))
+		Expect(err).ToNot(HaveOccurred())
+		defer client.Close()
+
+		Expect(client.IsConnected()).To(BeTrue())
+		Expect(len(client.GetNodes())).To(Equal(1))
+	})
+
+	It("must not connect to clusters with another name", func() {
+		srv.SetClusterName("staging")
+
+		_, err := NewClientFromURI(fmt.Sprintf("aerospike://%s/?clusterName=prod&timeout=1s", srv.Address()))
+		Expect(err).To(HaveOccurred())
+	})
+
+})
@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"math"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	. "github.com/THE108/aerospike-client-go/logger"
@@ -28,6 +29,9 @@ import (
 
 // Cluster encapsulates the aerospike cluster nodes and manages
 // them.
+// Cluster is safe for concurrent use. The node list and the partition maps
+// are copied on write, so the node slices returned by GetNodes are never
+// modified; the nodes of the partitions are kept in AtomicArrays.
 type Cluster struct {
 	// Initial host nodes specified by user.
 	seeds []*Host
@@ -41,38 +45,103 @@ type Cluster struct {
 	// Hints for best node for a partition
 	partitionWriteMap map[string]*AtomicArray
 
+	// Nodes of all replicas of the partitions, the master first, for nodes
+	// supporting the `replicas` or `replicas-all` info commands.
+	partitionReplicaMap map[string][]*AtomicArray
+
+	// Regimes of the masters in partitionWriteMap, for nodes supporting
+	// the `replicas` info command.
+	partitionRegimes map[string][]int
+	regimeMutex      sync.Mutex
+
 	// Random node index.
 	nodeIndex *AtomicInt
 
-	clientPolicy ClientPolicy
+	// *ClientPolicy in use, replaced as a whole on updates; see policy
+	clientPolicy atomic.Value
 
 	mutex       sync.RWMutex
 	wgTend      sync.WaitGroup
 	tendChannel chan struct{}
+	closeOnce   sync.Once
 	closed      AtomicBool
 
+	// Serializes tends. The cluster is tended by waitTillStabilized until it
+	// returns and by the tend goroutine afterwards; the state only modified
+	// while tending, like node aliases and peers, relies on it.
+	tendMutex sync.Mutex
+
 	// User name in UTF-8 encoded bytes.
 	user string
 
 	// Password in hashed format in bytes.
 	password []byte
+
+	// Session token of the last login, and when the client stops using it.
+	sessionToken       []byte
+	sessionExpiration  time.Time
+	sessionMutex       sync.Mutex
+	sessionUnsupported AtomicBool
+
+	// Errors of single record commands by partition.
+	partitionErrors *partitionErrorStats
+
+	// cache of the records read by the client, if enabled
+	recordCache      *recordCache
+	recordCacheMutex sync.RWMutex
+
+	// reads in flight, for BasePolicy.DeduplicateReads
+	reads *readGroup
+
+	// compression policies by "namespace:set", copied on write
+	compression      map[string]*CompressionPolicy
+	compressionMutex sync.RWMutex
+
+	// sets with record checksums
+	checksums recordChecksums
+
+	// hosts of nodes which are not added to the cluster, either since they
+	// belong to another cluster or are not in the NodeSubset, and the time
+	// they were rejected; only accessed while tending
+	rejectedHosts map[Host]time.Time
 }
 
+// rejectedHostExpiration is the time after which rejected hosts are
+// validated again. Their address may have been reused by another node,
+// or the configuration of the node may have changed since.
+const rejectedHostExpiration = time.Minute
+
 // NewCluster generates a Cluster instance.
 func NewCluster(policy *ClientPolicy, hosts []*Host) (*Cluster, error) {
 	newCluster := &Cluster{
-		seeds:             hosts,
-		clientPolicy:      *policy,
-		aliases:           make(map[Host]*Node),
-		nodes:             []*Node{},
-		partitionWriteMap: make(map[string]*AtomicArray),
-		nodeIndex:         NewAtomicInt(0),
-		tendChannel:       make(chan struct{}),
-	}
+		seeds:               hosts,
+		aliases:             make(map[Host]*Node),
+		nodes:               []*Node{},
+		partitionWriteMap:   make(map[string]*AtomicArray),
+		partitionReplicaMap: make(map[string][]*AtomicArray),
+		partitionRegimes:    make(map[string][]int),
+		nodeIndex:           NewAtomicInt(0),
+		tendChannel:         make(chan struct{}),
+		partitionErrors:     newPartitionErrorStats(),
+		reads:               newReadGroup(),
+		rejectedHosts:       map[Host]time.Time{},
+	}
+	cp := *policy
+	newCluster.setPolicy(&cp)
 
 	// setup auth info for cluster
+	if err := policy.validateAuthMode(); err != nil {
+		return nil, err
+	}
+
+	if policy.NodeSubset != nil {
+		if err := policy.NodeSubset.validate(); err != nil {
+			return nil, err
+		}
+	}
+
 	var err error
-	if policy.RequiresAuthentication() {
+	if policy.RequiresAuthentication() && policy.AuthMode != AuthModePKI {
 		newCluster.user = policy.User
 		if newCluster.password, err = hashPassword(policy.Password); err != nil {
 			return nil, err
@@ -89,7 +158,7 @@ func NewCluster(policy *ClientPolicy, hosts []*Host) (*Cluster, error) {
 
 	// start up cluster maintenance go routine
 	newCluster.wgTend.Add(1)
-	go newCluster.clusterBoss(policy)
+	go newCluster.clusterBoss()
 
 	Logger.Debug("New cluster initialized and ready to be used...")
 	return newCluster, nil
@@ -97,16 +166,17 @@ func NewCluster(policy *ClientPolicy, hosts []*Host) (*Cluster, error) {
 
 // Maintains the cluster on intervals.
 // All clean up code for cluster is here as well.
-func (clstr *Cluster) clusterBoss(policy *ClientPolicy) {
+func (clstr *Cluster) clusterBoss() {
 	defer clstr.wgTend.Done()
 
-	tendInterval := policy.TendInterval
-	if tendInterval <= 10*time.Millisecond {
-		tendInterval = 10 * time.Millisecond
-	}
-
 Loop:
 	for {
+		// the interval may be changed by UpdatePolicy
+		tendInterval := clstr.policy().TendInterval
+		if tendInterval <= 10*time.Millisecond {
+			tendInterval = 10 * time.Millisecond
+		}
+
 		select {
 		case <-clstr.tendChannel:
 			// tend channel closed
@@ -145,6 +215,9 @@ func (clstr *Cluster) getSeeds() []*Host {
 
 // Updates cluster state
 func (clstr *Cluster) tend() error {
+	clstr.tendMutex.Lock()
+	defer clstr.tendMutex.Unlock()
+
 	nodes := clstr.GetNodes()
 
 	// All node additions/deletions are performed in tend goroutine.
@@ -204,11 +277,20 @@ func (clstr *Cluster) tend() error {
 func (clstr *Cluster) waitTillStabilized() {
 	count := -1
 
-	doneCh := make(chan bool)
+	// buffered, so the goroutine does not leak once the timeout has passed
+	doneCh := make(chan bool, 1)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
 
-	// will run until the cluster is stablized
+	// will run until the cluster is stablized, or the timeout has passed
 	go func() {
 		for {
+			select {
+			case <-stopCh:
+				return
+			default:
+			}
+
 			if err := clstr.tend(); err != nil {
 				Logger.Warn(err.Error())
 			}
@@ -227,7 +309,7 @@ func (clstr *Cluster) waitTillStabilized() {
 	}()
 
 	// returns either on timeout or on cluster stablization
-	timeout := time.After(clstr.clientPolicy.Timeout)
+	timeout := time.After(clstr.policy().Timeout)
 	select {
 	case <-timeout:
 		return
@@ -256,11 +338,56 @@ func (clstr *Cluster) getPartitions() map[string]*AtomicArray {
 	return res
 }
 
+func (clstr *Cluster) setReplicas(replicaMap map[string][]*AtomicArray) {
+	clstr.mutex.Lock()
+	clstr.partitionReplicaMap = replicaMap
+	clstr.mutex.Unlock()
+}
+
+func (clstr *Cluster) getReplicas() map[string][]*AtomicArray {
+	clstr.mutex.RLock()
+	res := clstr.partitionReplicaMap
+	clstr.mutex.RUnlock()
+	return res
+}
+
+// replicasCommand returns the info command the node supports to request
+// all the replicas of the partitions, or an empty string.
+func replicasCommand(node *Node) string {
+	switch {
+	case !node.useNewInfo:
+		return ""
+	case node.SupportsFeature(replicasRegimeName):
+		return replicasRegimeName
+	case node.SupportsFeature(replicasAllName):
+		return replicasAllName
+	}
+	return ""
+}
+
 func (clstr *Cluster) updatePartitions(conn *Connection, node *Node) error {
 	// TODO: Cluster should not care about version of tokenizer
 	// decouple clstr interface
 	var nmap map[string]*AtomicArray
-	if node.useNewInfo {
+	if command := replicasCommand(node); command != "" {
+		Logger.Info("Updating partitions using %s protocol...", command)
+		tokens, err := newPartitionTokenizerReplicas(conn, command)
+		if err != nil {
+			return err
+		}
+
+		// the regime mutex serializes the updates of the replica map as well
+		var rmap map[string][]*AtomicArray
+		clstr.regimeMutex.Lock()
+		nmap, rmap, err = tokens.UpdatePartition(clstr.getPartitions(), clstr.getReplicas(), clstr.partitionRegimes, node)
+		if err == nil && rmap != nil {
+			clstr.setReplicas(rmap)
+		}
+		clstr.regimeMutex.Unlock()
+		if err != nil {
+			return err
+		}
+	} else if node.useNewInfo {
 		Logger.Info("Updating partitions using new protocol...")
 		tokens, err := newPartitionTokenizerNew(conn)
 		if err != nil {
@@ -302,7 +429,7 @@ func (clstr *Cluster) seedNodes() {
 	list := []*Node{}
 
 	for _, seed := range seedArray {
-		seedNodeValidator, err := newNodeValidator(clstr, seed, clstr.clientPolicy.Timeout)
+		seedNodeValidator, err := newNodeValidator(clstr, seed, clstr.policy().Timeout)
 		if err != nil {
 			Logger.Warn("Seed %s failed: %s", seed.String(), err.Error())
 			continue
@@ -315,7 +442,7 @@ func (clstr *Cluster) seedNodes() {
 			if *alias == *seed {
 				nv = seedNodeValidator
 			} else {
-				nv, err = newNodeValidator(clstr, alias, clstr.clientPolicy.Timeout)
+				nv, err = newNodeValidator(clstr, alias, clstr.policy().Timeout)
 				if err != nil {
 					Logger.Warn("Seed %s failed: %s", seed.String(), err.Error())
 					continue
@@ -361,14 +488,45 @@ func (clstr *Cluster) removeAlias(alias *Host) {
 	}
 }
 
+// isRejected determines if the host has been rejected recently.
+// Expired rejections are removed.
+func (clstr *Cluster) isRejected(host *Host, now time.Time) bool {
+	rejectedAt, rejected := clstr.rejectedHosts[*host]
+	if rejected && now.Sub(rejectedAt) >= rejectedHostExpiration {
+		delete(clstr.rejectedHosts, *host)
+		return false
+	}
+	return rejected
+}
+
 func (clstr *Cluster) findNodesToAdd(hosts []*Host) []*Node {
 	list := make([]*Node, 0, len(hosts))
 
 	for _, host := range hosts {
-		if nv, err := newNodeValidator(clstr, host, clstr.clientPolicy.Timeout); err != nil {
+		if clstr.isRejected(host, time.Now()) {
+			continue
+		}
+
+		if nv, err := newNodeValidator(clstr, host, clstr.policy().Timeout); err != nil {
+			// don't validate rejected nodes on every tend again
+			if ae, ok := err.(AerospikeError); ok && ae.ResultCode() == INVALID_NODE_ERROR {
+				Logger.Info("Node %s is not added: %s", host.String(), err.Error())
+				clstr.rejectedHosts[*host] = time.Now()
+				continue
+			}
 			Logger.Warn("Add node %s failed: %s", host.Name, err.Error())
 		} else {
 			node := clstr.findNodeByName(nv.name)
+
+			// The node is known by its name, but did not respond on its current
+			// address during this tend. It has been restarted on a new address;
+			// move it there instead of treating it as a new node.
+			if node != nil && node.IsActive() && !node.responded.Get() {
+				node.referenceCount.IncrementAndGet()
+				clstr.changeNodeAddress(node, nv, host)
+				continue
+			}
+
 			// make sure node is not already in the list to add
 			if node == nil {
 				for _, n := range list {
@@ -396,6 +554,24 @@ func (clstr *Cluster) findNodesToAdd(hosts []*Host) []*Node {
 	return list
 }
 
+// changeNodeAddress moves an existing node to a new address, and notifies
+// the NodeAddressChanged handler in the client policy if it is set.
+func (clstr *Cluster) changeNodeAddress(node *Node, nv *nodeValidator, host *Host) {
+	oldHost := node.GetHost()
+
+	for _, alias := range node.GetAliases() {
+		clstr.removeAlias(alias)
+	}
+	node.changeAddress(nv, host)
+	clstr.addAlias(host, node)
+
+	Logger.Info("Node `%s` address changed from %s to %s", node.GetName(), oldHost, host)
+
+	if handler := clstr.policy().NodeAddressChanged; handler != nil {
+		handler(node, oldHost, host)
+	}
+}
+
 func (clstr *Cluster) createNode(nv *nodeValidator) *Node {
 	return newNode(clstr, nv)
 }
@@ -475,15 +651,17 @@ func (clstr *Cluster) addNodes(nodesToAdd []*Node) {
 
 func (clstr *Cluster) addAliases(node *Node) {
 	// Add node's aliases to global alias set.
-	// Aliases are only used in tend goroutine, so synchronization is not necessary.
 	for _, alias := range node.GetAliases() {
-		clstr.aliases[*alias] = node
+		clstr.addAlias(alias, node)
 	}
 }
 
 func (clstr *Cluster) addNodesCopy(nodesToAdd []*Node) {
 	clstr.mutex.Lock()
-	clstr.nodes = append(clstr.nodes, nodesToAdd...)
+	// Copy on write; readers keep iterating the array they got.
+	nodes := make([]*Node, 0, len(clstr.nodes)+len(nodesToAdd))
+	nodes = append(nodes, clstr.nodes...)
+	clstr.nodes = append(nodes, nodesToAdd...)
 	clstr.mutex.Unlock()
 }
 
@@ -495,7 +673,6 @@ func (clstr *Cluster) removeNodes(nodesToRemove []*Node) {
 	// Cleanup node resources.
 	for _, node := range nodesToRemove {
 		// Remove node's aliases from cluster alias set.
-		// Aliases are only used in tend goroutine, so synchronization is not necessary.
 		for _, alias := range node.GetAliases() {
 			Logger.Debug("Removing alias ", alias)
 			clstr.removeAlias(alias)
@@ -576,6 +753,31 @@ func (clstr *Cluster) GetNode(partition *Partition) (*Node, error) {
 	return clstr.GetRandomNode()
 }
 
+// GetReplicaNodes returns the active nodes holding the replicas of the
+// partition, the master first. Only the master is known for nodes which do
+// not support the `replicas` or `replicas-all` info commands; the result is
+// empty if no node is known.
+func (clstr *Cluster) GetReplicaNodes(partition *Partition) []*Node {
+	// Must copy hashmap reference for copy on write semantics to work.
+	replicas := clstr.getReplicas()[partition.Namespace]
+
+	nodes := make([]*Node, 0, len(replicas))
+	for _, nodeArray := range replicas {
+		if node, ok := nodeArray.Get(partition.PartitionId).(*Node); ok && node.IsActive() && !clstr.nodeExists(node, nodes) {
+			nodes = append(nodes, node)
+		}
+	}
+
+	if len(nodes) == 0 {
+		if nodeArray, exists := clstr.getPartitions()[partition.Namespace]; exists {
+			if node, ok := nodeArray.Get(partition.PartitionId).(*Node); ok && node.IsActive() {
+				nodes = append(nodes, node)
+			}
+		}
+	}
+	return nodes
+}
+
 // GetRandomNode returns a random node on the cluster
 func (clstr *Cluster) GetRandomNode() (*Node, error) {
 	// Must copy array reference for copy on write semantics to work.
@@ -627,15 +829,16 @@ func (clstr *Cluster) findNodeByName(nodeName string) *Node {
 }
 
 // Close closes all cached connections to the cluster nodes
-// and stops the tend goroutine.
+// and stops the tend goroutine. It can be called more than once,
+// from any goroutine.
 func (clstr *Cluster) Close() {
-	if !clstr.closed.Get() {
+	clstr.closeOnce.Do(func() {
 		// send close signal to maintenance channel
 		close(clstr.tendChannel)
+	})
 
-		// wait until tend is over
-		clstr.wgTend.Wait()
-	}
+	// wait until tend is over
+	clstr.wgTend.Wait()
 }
 
 // MigrationInProgress determines if any node in the cluster
@@ -645,7 +848,7 @@ func (clstr *Cluster) MigrationInProgress(timeout time.Duration) (res bool, err
 		timeout = _DEFAULT_TIMEOUT
 	}
 
-	done := make(chan bool)
+	done := make(chan bool, 1)
 
 	go func() {
 		// this function is guaranteed to return after _DEFAULT_TIMEOUT
@@ -676,15 +879,12 @@ func (clstr *Cluster) MigrationInProgress(timeout time.Duration) (res bool, err
 
 // WaitUntillMigrationIsFinished will block until all
 // migration operations in the cluster all finished.
+// A timeout of zero or less waits without a deadline.
 func (clstr *Cluster) WaitUntillMigrationIsFinished(timeout time.Duration) (err error) {
-	if timeout <= 0 {
-		timeout = _NO_TIMEOUT
-	}
-	done := make(chan error)
+	// buffered, so the goroutine can return once the deadline has passed
+	done := make(chan error, 1)
 
 	go func() {
-		// this function is guaranteed to return after timeout
-		// no go routines will be leaked
 		for {
 			if res, err := clstr.MigrationInProgress(timeout); err != nil || !res {
 				done <- err
@@ -693,9 +893,8 @@ func (clstr *Cluster) WaitUntillMigrationIsFinished(timeout time.Duration) (err
 		}
 	}()
 
-	dealine := time.After(timeout)
 	select {
-	case <-dealine:
+	case <-timeoutChan(timeout):
 		return NewAerospikeError(TIMEOUT)
 	case err = <-done:
 		return err
@@ -715,17 +914,79 @@ func (clstr *Cluster) changePassword(user string, password string, hash []byte)
 	// change password ONLY if the user is the same
 	if clstr.user == user {
 		clstr.mutex.Lock()
-		clstr.clientPolicy.Password = password
+		cp := *clstr.policy()
+		cp.Password = password
+		clstr.setPolicy(&cp)
 		clstr.password = hash
 		clstr.mutex.Unlock()
+
+		// log in with the new password on the next connection
+		clstr.expireSession(nil)
 	}
 }
 
 // ClientPolicy returns the client policy that is currently used with the cluster.
 func (clstr *Cluster) ClientPolicy() (res ClientPolicy) {
-	clstr.mutex.RLock()
-	res = clstr.clientPolicy
-	clstr.mutex.RUnlock()
+	return *clstr.policy()
+}
 
-	return res
+// policy returns the client policy that is currently used with the cluster,
+// without copying it. The policy is shared and must not be changed; updates
+// publish a new one with setPolicy. Clusters not created with NewCluster use
+// the zero policy.
+func (clstr *Cluster) policy() *ClientPolicy {
+	if policy, ok := clstr.clientPolicy.Load().(*ClientPolicy); ok {
+		return policy
+	}
+	return &ClientPolicy{}
+}
+
+// setPolicy replaces the client policy used with the cluster. Writers must
+// be serialized by the caller.
+func (clstr *Cluster) setPolicy(policy *ClientPolicy) {
+	clstr.clientPolicy.Store(policy)
+}
+
+// updatePolicy applies the settings of the policy which can be changed on
+// a live cluster; see Client.UpdatePolicy.
+func (clstr *Cluster) updatePolicy(policy *ClientPolicy) error {
+	if policy.ConnectionQueueSize <= 0 {
+		return NewAerospikeError(PARAMETER_ERROR, "ConnectionQueueSize must be greater than 0")
+	}
+	if policy.ServerConnectionsFraction < 0 || policy.ServerConnectionsFraction > 1 {
+		return NewAerospikeError(PARAMETER_ERROR, "ServerConnectionsFraction must be between 0 and 1")
+	}
+
+	// the tend adds nodes with connection pools of the current size
+	clstr.tendMutex.Lock()
+	defer clstr.tendMutex.Unlock()
+
+	clstr.mutex.Lock()
+	cp := *clstr.policy()
+	resize := cp.ConnectionQueueSize != policy.ConnectionQueueSize
+
+	cp.Timeout = policy.Timeout
+	cp.IdleTimeout = policy.IdleTimeout
+	cp.IdlePingThreshold = policy.IdlePingThreshold
+	cp.TendInterval = policy.TendInterval
+	cp.ConnectionQueueSize = policy.ConnectionQueueSize
+	cp.LimitConnectionsToQueueSize = policy.LimitConnectionsToQueueSize
+	cp.ServerConnectionsFraction = policy.ServerConnectionsFraction
+	cp.MaxKeysPerBatch = policy.MaxKeysPerBatch
+	cp.AdaptiveBatchSize = policy.AdaptiveBatchSize
+	cp.BatchLatencyTarget = policy.BatchLatencyTarget
+	cp.CommandObserver = policy.CommandObserver
+	cp.SlowCommandThreshold = policy.SlowCommandThreshold
+	cp.SlowCommandHandler = policy.SlowCommandHandler
+
+	// commands in flight keep using the policy they read
+	clstr.setPolicy(&cp)
+	clstr.mutex.Unlock()
+
+	if resize {
+		for _, node := range clstr.GetNodes() {
+			node.resizeConnectionPool(policy.ConnectionQueueSize)
+		}
+	}
+	return nil
 }
@@ -0,0 +1,92 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"time"
+
+	. "github.com/THE108/aerospike-client-go/types"
+)
+
+// clusterKeyFunc returns the cluster key reported by a node.
+type clusterKeyFunc func(node *Node) (string, error)
+
+func requestClusterKey(timeout time.Duration) clusterKeyFunc {
+	return func(node *Node) (string, error) {
+		info, err := requestNodeInfo(timeout, node, "cluster-key")
+		if err != nil {
+			return "", err
+		}
+		return info["cluster-key"], nil
+	}
+}
+
+// clusterChangeCheck detects cluster changes during a scan or query job by
+// comparing the cluster keys of the nodes before and after the job.
+// The cluster key changes whenever nodes join or leave the cluster, which
+// starts data migrations; records may then have been returned twice or missed.
+type clusterChangeCheck struct {
+	nodes   []*Node
+	keys    []string
+	request clusterKeyFunc
+}
+
+// newClusterChangeCheck records the cluster keys of the nodes. It fails if
+// the nodes don't agree on the cluster key, since the cluster is changing.
+func newClusterChangeCheck(nodes []*Node, request clusterKeyFunc) (*clusterChangeCheck, error) {
+	check := &clusterChangeCheck{
+		nodes:   nodes,
+		keys:    make([]string, len(nodes)),
+		request: request,
+	}
+
+	for i, node := range nodes {
+		key, err := request(node)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 && key != check.keys[0] {
+			return nil, NewAerospikeError(CLUSTER_KEY_MISMATCH, "Cluster is changing: nodes report different cluster keys")
+		}
+		check.keys[i] = key
+	}
+	return check, nil
+}
+
+// verify returns a CLUSTER_KEY_MISMATCH error if the cluster key of any
+// node has changed, or can not be verified.
+func (check *clusterChangeCheck) verify() error {
+	for i, node := range check.nodes {
+		key, err := check.request(node)
+		if err != nil {
+			return NewAerospikeError(CLUSTER_KEY_MISMATCH, "Cluster key of node "+node.String()+" could not be verified: "+err.Error())
+		}
+		if key != check.keys[i] {
+			return NewAerospikeError(CLUSTER_KEY_MISMATCH, "Cluster changed during the job on node "+node.String())
+		}
+	}
+	return nil
+}
+
+// failOnClusterChange makes the recordset end with a CLUSTER_KEY_MISMATCH
+// error if the cluster changes before all nodes have returned their records.
+func failOnClusterChange(recordset *Recordset, nodes []*Node, timeout time.Duration) error {
+	check, err := newClusterChangeCheck(nodes, requestClusterKey(timeout))
+	if err != nil {
+		return err
+	}
+	recordset.onEnd = check.verify
+	return nil
+}
@@ -0,0 +1,94 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"errors"
+
+	. "github.com/THE108/aerospike-client-go/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cluster Change Check Test", func() {
+
+	var node1, node2 *Node
+	var keys map[*Node]string
+
+	request := func(node *Node) (string, error) {
+		key, exists := keys[node]
+		if !exists {
+			return "", errors.New("node unreachable")
+		}
+		return key, nil
+	}
+
+	resultCode := func(err error) ResultCode {
+		Expect(err).To(HaveOccurred())
+		return err.(AerospikeError).ResultCode()
+	}
+
+	BeforeEach(func() {
+		node1 = &Node{name: "BB9000000000001", host: NewHost("127.0.0.1", 3000)}
+		node2 = &Node{name: "BB9000000000002", host: NewHost("127.0.0.2", 3000)}
+		keys = map[*Node]string{node1: "A1", node2: "A1"}
+	})
+
+	It("should pass if the cluster key has not changed", func() {
+		check, err := newClusterChangeCheck([]*Node{node1, node2}, request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(check.verify()).ToNot(HaveOccurred())
+	})
+
+	It("should fail if the cluster key has changed or can not be verified", func() {
+		check, err := newClusterChangeCheck([]*Node{node1, node2}, request)
+		Expect(err).ToNot(HaveOccurred())
+
+		keys[node2] = "B2"
+		Expect(resultCode(check.verify())).To(Equal(CLUSTER_KEY_MISMATCH))
+
+		delete(keys, node2)
+		Expect(resultCode(check.verify())).To(Equal(CLUSTER_KEY_MISMATCH))
+	})
+
+	It("should fail if the nodes don't agree on the cluster key", func() {
+		keys[node2] = "B2"
+		_, err := newClusterChangeCheck([]*Node{node1, node2}, request)
+		Expect(resultCode(err)).To(Equal(CLUSTER_KEY_MISMATCH))
+	})
+
+	It("should deliver the error of the check with the results", func() {
+		rs := newRecordset(10, 2)
+		rs.onEnd = func() error { return NewAerospikeError(CLUSTER_KEY_MISMATCH) }
+
+		rs.Records <- newRecord(nil, nil, BinMap{"a": 1}, 1, 0)
+		rs.signalEnd()
+		rs.signalEnd()
+
+		var records, errs int
+		for res := range rs.Results() {
+			if res.Err != nil {
+				Expect(resultCode(res.Err)).To(Equal(CLUSTER_KEY_MISMATCH))
+				errs++
+			} else {
+				records++
+			}
+		}
+		Expect(records).To(Equal(1))
+		Expect(errs).To(Equal(1))
+	})
+
+})
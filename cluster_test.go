@@ -0,0 +1,56 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cluster Test", func() {
+
+	It("should validate rejected hosts again once the rejection expires", func() {
+		cluster := &Cluster{rejectedHosts: map[Host]time.Time{}}
+		host := NewHost("10.0.0.1", 3000)
+		now := time.Now()
+
+		Expect(cluster.isRejected(host, now)).To(BeFalse())
+
+		cluster.rejectedHosts[*host] = now
+		Expect(cluster.isRejected(host, now.Add(rejectedHostExpiration/2))).To(BeTrue())
+
+		Expect(cluster.isRejected(host, now.Add(rejectedHostExpiration))).To(BeFalse())
+		Expect(cluster.rejectedHosts).To(BeEmpty())
+	})
+
+	It("should publish a new policy on updates and leave the previous one unchanged", func() {
+		cluster := &Cluster{}
+		cluster.setPolicy(NewClientPolicy())
+		previous := cluster.policy()
+
+		update := NewClientPolicy()
+		update.Timeout = 3 * time.Second
+		update.User = "ignored"
+		Expect(cluster.updatePolicy(update)).ToNot(HaveOccurred())
+
+		Expect(cluster.policy()).ToNot(BeIdenticalTo(previous))
+		Expect(cluster.policy().Timeout).To(Equal(3 * time.Second))
+		Expect(cluster.policy().User).To(BeEmpty())
+		Expect(previous.Timeout).To(Equal(NewClientPolicy().Timeout))
+	})
+
+})
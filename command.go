@@ -34,6 +34,8 @@ const (
 	_INFO1_READ int = (1 << 0)
 	// Get all bins.
 	_INFO1_GET_ALL int = (1 << 1)
+	// Batch-index protocol; the keys are sent in a BATCH_INDEX field.
+	_INFO1_BATCH int = (1 << 3)
 
 	// Do not read the bins
 	_INFO1_NOBINDATA int = (1 << 5)
@@ -53,6 +55,8 @@ const (
 	_INFO2_GENERATION_DUP int = (1 << 4)
 	// Create only. Fail if record already exists.
 	_INFO2_CREATE_ONLY int = (1 << 5)
+	// Leave a tombstone on deletes. Shares the bit of the obsolete _INFO2_GENERATION_DUP.
+	_INFO2_DURABLE_DELETE int = (1 << 4)
 
 	// This is the last of a multi-part message.
 	_INFO3_LAST int = (1 << 0)
@@ -65,6 +69,33 @@ const (
 	_INFO3_CREATE_OR_REPLACE int = (1 << 4)
 	// Completely replace existing record only.
 	_INFO3_REPLACE_ONLY int = (1 << 5)
+	// Linearize reads in strong consistency namespaces.
+	_INFO3_SC_READ_TYPE int = (1 << 6)
+	// Relax read consistency in strong consistency namespaces.
+	_INFO3_SC_READ_RELAX int = (1 << 7)
+
+	// Verify the version of a record read in a multi-record transaction.
+	_INFO4_MRT_VERIFY_READ int = (1 << 0)
+	// Roll forward the writes of a committed multi-record transaction.
+	_INFO4_MRT_ROLL_FORWARD int = (1 << 1)
+	// Roll back the writes of an aborted multi-record transaction.
+	_INFO4_MRT_ROLL_BACK int = (1 << 2)
+
+	// Batch-any key flags, sent after the digest of each key.
+	// Repeat the namespace, attributes and operations of the previous key.
+	_BATCH_MSG_REPEAT int = (1 << 0)
+	// The read, write and info attributes follow.
+	_BATCH_MSG_INFO int = (1 << 1)
+	// The expected generation follows.
+	_BATCH_MSG_GEN int = (1 << 2)
+	// The record TTL follows.
+	_BATCH_MSG_TTL int = (1 << 3)
+
+	// Batch field flags.
+	// Allow the server to process the keys in its service threads.
+	_BATCH_ALLOW_INLINE int = (1 << 0)
+	// Return a result for every key, instead of aborting on the first error.
+	_BATCH_RESPOND_ALL_KEYS int = (1 << 2)
 
 	_MSG_TOTAL_HEADER_SIZE     uint8 = 30
 	_FIELD_HEADER_SIZE         uint8 = 5
@@ -105,6 +136,8 @@ type baseCommand struct {
 func (cmd *baseCommand) setWrite(policy *WritePolicy, operation OperationType, key *Key, bins []*Bin) error {
 	cmd.begin()
 	fieldCount := cmd.estimateKeySize(key, policy.SendKey)
+	txn := cmd.estimateTxnSize(&policy.BasePolicy, key, true)
+	fieldCount += txn.count()
 
 	for i := range bins {
 		cmd.estimateOperationSizeForBin(bins[i])
@@ -114,6 +147,7 @@ func (cmd *baseCommand) setWrite(policy *WritePolicy, operation OperationType, k
 	}
 	cmd.writeHeaderWithPolicy(policy, 0, _INFO2_WRITE, fieldCount, len(bins))
 	cmd.writeKey(key, policy.SendKey)
+	cmd.writeTxn(txn)
 
 	for i := range bins {
 		if err := cmd.writeOperationForBin(bins[i], operation); err != nil {
@@ -129,11 +163,14 @@ func (cmd *baseCommand) setWrite(policy *WritePolicy, operation OperationType, k
 func (cmd *baseCommand) setDelete(policy *WritePolicy, key *Key) error {
 	cmd.begin()
 	fieldCount := cmd.estimateKeySize(key, false)
+	txn := cmd.estimateTxnSize(&policy.BasePolicy, key, true)
+	fieldCount += txn.count()
 	if err := cmd.sizeBuffer(); err != nil {
 		return nil
 	}
 	cmd.writeHeaderWithPolicy(policy, 0, _INFO2_WRITE|_INFO2_DELETE, fieldCount, 0)
 	cmd.writeKey(key, false)
+	cmd.writeTxn(txn)
 	cmd.end()
 	return nil
 
@@ -143,6 +180,8 @@ func (cmd *baseCommand) setDelete(policy *WritePolicy, key *Key) error {
 func (cmd *baseCommand) setTouch(policy *WritePolicy, key *Key) error {
 	cmd.begin()
 	fieldCount := cmd.estimateKeySize(key, policy.SendKey)
+	txn := cmd.estimateTxnSize(&policy.BasePolicy, key, true)
+	fieldCount += txn.count()
 
 	cmd.estimateOperationSize()
 	if err := cmd.sizeBuffer(); err != nil {
@@ -150,6 +189,7 @@ func (cmd *baseCommand) setTouch(policy *WritePolicy, key *Key) error {
 	}
 	cmd.writeHeaderWithPolicy(policy, 0, _INFO2_WRITE, fieldCount, 1)
 	cmd.writeKey(key, policy.SendKey)
+	cmd.writeTxn(txn)
 	cmd.writeOperationForOperationType(TOUCH)
 	cmd.end()
 	return nil
@@ -160,11 +200,14 @@ func (cmd *baseCommand) setTouch(policy *WritePolicy, key *Key) error {
 func (cmd *baseCommand) setExists(policy *BasePolicy, key *Key) error {
 	cmd.begin()
 	fieldCount := cmd.estimateKeySize(key, false)
+	txn := cmd.estimateTxnSize(policy, key, false)
+	fieldCount += txn.count()
 	if err := cmd.sizeBuffer(); err != nil {
 		return nil
 	}
 	cmd.writeHeader(policy.GetBasePolicy(), _INFO1_READ|_INFO1_NOBINDATA, 0, fieldCount, 0)
 	cmd.writeKey(key, false)
+	cmd.writeTxn(txn)
 	cmd.end()
 	return nil
 
@@ -174,11 +217,14 @@ func (cmd *baseCommand) setExists(policy *BasePolicy, key *Key) error {
 func (cmd *baseCommand) setReadForKeyOnly(policy *BasePolicy, key *Key) error {
 	cmd.begin()
 	fieldCount := cmd.estimateKeySize(key, false)
+	txn := cmd.estimateTxnSize(policy, key, false)
+	fieldCount += txn.count()
 	if err := cmd.sizeBuffer(); err != nil {
 		return nil
 	}
 	cmd.writeHeader(policy, _INFO1_READ|_INFO1_GET_ALL, 0, fieldCount, 0)
 	cmd.writeKey(key, false)
+	cmd.writeTxn(txn)
 	cmd.end()
 	return nil
 
@@ -189,6 +235,8 @@ func (cmd *baseCommand) setRead(policy *BasePolicy, key *Key, binNames []string)
 	if binNames != nil && len(binNames) > 0 {
 		cmd.begin()
 		fieldCount := cmd.estimateKeySize(key, false)
+		txn := cmd.estimateTxnSize(policy, key, false)
+		fieldCount += txn.count()
 
 		for i := range binNames {
 			cmd.estimateOperationSizeForBinName(binNames[i])
@@ -198,6 +246,7 @@ func (cmd *baseCommand) setRead(policy *BasePolicy, key *Key, binNames []string)
 		}
 		cmd.writeHeader(policy.GetBasePolicy(), _INFO1_READ, 0, fieldCount, len(binNames))
 		cmd.writeKey(key, false)
+		cmd.writeTxn(txn)
 
 		for i := range binNames {
 			cmd.writeOperationForBinName(binNames[i], READ)
@@ -214,6 +263,8 @@ func (cmd *baseCommand) setRead(policy *BasePolicy, key *Key, binNames []string)
 func (cmd *baseCommand) setReadHeader(policy *BasePolicy, key *Key) error {
 	cmd.begin()
 	fieldCount := cmd.estimateKeySize(key, false)
+	txn := cmd.estimateTxnSize(policy, key, false)
+	fieldCount += txn.count()
 	cmd.estimateOperationSizeForBinName("")
 	if err := cmd.sizeBuffer(); err != nil {
 		return nil
@@ -222,6 +273,7 @@ func (cmd *baseCommand) setReadHeader(policy *BasePolicy, key *Key) error {
 	cmd.writeHeader(policy.GetBasePolicy(), _INFO1_READ|_INFO1_NOBINDATA, 0, fieldCount, 1)
 
 	cmd.writeKey(key, false)
+	cmd.writeTxn(txn)
 	cmd.writeOperationForBinName("", READ)
 	cmd.end()
 	return nil
@@ -229,7 +281,9 @@ func (cmd *baseCommand) setReadHeader(policy *BasePolicy, key *Key) error {
 }
 
 // Implements different command operations
-func (cmd *baseCommand) setOperate(policy *WritePolicy, key *Key, operations []*Operation) error {
+// setOperate writes an Operate command. The operations are only applied if
+// the record matches the predicate expressions, if any.
+func (cmd *baseCommand) setOperate(policy *WritePolicy, key *Key, operations []*Operation, predExp []PredExp) error {
 	cmd.begin()
 	fieldCount := 0
 	readAttr := 0
@@ -252,6 +306,9 @@ func (cmd *baseCommand) setOperate(policy *WritePolicy, key *Key, operations []*
 				readAttr |= _INFO1_READ
 				readHeader = true
 			}
+		case CDT_READ:
+			readAttr |= _INFO1_READ
+			readBin = true
 		default:
 			writeAttr = _INFO2_WRITE
 		}
@@ -259,6 +316,17 @@ func (cmd *baseCommand) setOperate(policy *WritePolicy, key *Key, operations []*
 	}
 
 	fieldCount = cmd.estimateKeySize(key, policy.SendKey && writeAttr != 0)
+	txn := cmd.estimateTxnSize(&policy.BasePolicy, key, writeAttr != 0)
+	fieldCount += txn.count()
+
+	predExpSize := 0
+	if len(predExp) > 0 {
+		for _, predexp := range predExp {
+			predExpSize += predexp.estimateSize()
+		}
+		cmd.dataOffset += int(_FIELD_HEADER_SIZE) + predExpSize
+		fieldCount++
+	}
 
 	if err := cmd.sizeBuffer(); err != nil {
 		return nil
@@ -274,6 +342,14 @@ func (cmd *baseCommand) setOperate(policy *WritePolicy, key *Key, operations []*
 		cmd.writeHeader(policy.GetBasePolicy(), readAttr, writeAttr, fieldCount, len(operations))
 	}
 	cmd.writeKey(key, policy.SendKey && writeAttr != 0)
+	cmd.writeTxn(txn)
+
+	if len(predExp) > 0 {
+		cmd.writeFieldHeader(predExpSize, PREDEXP)
+		for _, predexp := range predExp {
+			cmd.dataOffset = predexp.write(cmd.dataBuffer, cmd.dataOffset)
+		}
+	}
 
 	for _, operation := range operations {
 		if err := cmd.writeOperationForOperation(operation); err != nil {
@@ -289,6 +365,8 @@ func (cmd *baseCommand) setOperate(policy *WritePolicy, key *Key, operations []*
 func (cmd *baseCommand) setUdf(policy Policy, key *Key, packageName string, functionName string, args []Value) error {
 	cmd.begin()
 	fieldCount := cmd.estimateKeySize(key, false)
+	txn := cmd.estimateTxnSize(policy.GetBasePolicy(), key, true)
+	fieldCount += txn.count()
 	argBytes, err := packValueArray(args)
 	if err != nil {
 		return err
@@ -298,8 +376,13 @@ func (cmd *baseCommand) setUdf(policy Policy, key *Key, packageName string, func
 	if err := cmd.sizeBuffer(); err != nil {
 		return nil
 	}
-	cmd.writeHeader(policy.GetBasePolicy(), 0, _INFO2_WRITE, fieldCount, 0)
+	if writePolicy, ok := policy.(*WritePolicy); ok {
+		cmd.writeHeaderWithPolicy(writePolicy, 0, _INFO2_WRITE, fieldCount, 0)
+	} else {
+		cmd.writeHeader(policy.GetBasePolicy(), 0, _INFO2_WRITE, fieldCount, 0)
+	}
 	cmd.writeKey(key, false)
+	cmd.writeTxn(txn)
 	cmd.writeFieldString(packageName, UDF_PACKAGE_NAME)
 	cmd.writeFieldString(functionName, UDF_FUNCTION)
 	cmd.writeFieldBytes(argBytes, UDF_ARGLIST)
@@ -336,7 +419,7 @@ func (cmd *baseCommand) setBatchExists(policy *BasePolicy, keys []*Key, batch *b
 	return nil
 }
 
-func (cmd *baseCommand) setBatchGet(policy Policy, keys []*Key, batch *batchNamespace, binNames map[string]struct{}, readAttr int) error {
+func (cmd *baseCommand) setBatchGet(policy Policy, keys []*Key, batch *batchNamespace, binNames map[string]struct{}, operations []*Operation, readAttr int) error {
 	// Estimate buffer size
 	cmd.begin()
 	byteSize := batch.offsetSize * int(_DIGEST_SIZE)
@@ -348,11 +431,15 @@ func (cmd *baseCommand) setBatchGet(policy Policy, keys []*Key, batch *batchName
 		cmd.estimateOperationSizeForBinName(binName)
 	}
 
+	for i := range operations {
+		cmd.estimateOperationSizeForOperation(operations[i])
+	}
+
 	if err := cmd.sizeBuffer(); err != nil {
 		return nil
 	}
 
-	operationCount := len(binNames)
+	operationCount := len(binNames) + len(operations)
 	cmd.writeHeader(policy.GetBasePolicy(), readAttr, 0, 2, operationCount)
 	cmd.writeFieldString(*batch.namespace, NAMESPACE)
 	cmd.writeFieldHeader(byteSize, DIGEST_RIPE_ARRAY)
@@ -369,12 +456,204 @@ func (cmd *baseCommand) setBatchGet(policy Policy, keys []*Key, batch *batchName
 	for binName := range binNames {
 		cmd.writeOperationForBinName(binName, READ)
 	}
+
+	for _, operation := range operations {
+		if err := cmd.writeOperationForOperation(operation); err != nil {
+			return err
+		}
+	}
+	cmd.end()
+
+	return nil
+}
+
+// setBatchIndex writes a batch command in the batch-index protocol.
+// Each key is sent with its index in keys, which the server returns in the
+// header of its record. The namespace and operations are only sent with the
+// first key; the others are flagged to repeat them.
+func (cmd *baseCommand) setBatchIndex(policy *BasePolicy, keys []*Key, batch *batchNamespace, binNames map[string]struct{}, operations []*Operation, readAttr int) error {
+	// Estimate buffer size
+	cmd.begin()
+	cmd.dataOffset += int(_FIELD_HEADER_SIZE) + 5
+	cmd.dataOffset += batch.offsetSize * (4 + int(_DIGEST_SIZE) + 1)
+	cmd.dataOffset += 5 + len(*batch.namespace) + int(_FIELD_HEADER_SIZE)
+
+	for binName := range binNames {
+		cmd.estimateOperationSizeForBinName(binName)
+	}
+
+	for i := range operations {
+		cmd.estimateOperationSizeForOperation(operations[i])
+	}
+
+	if err := cmd.sizeBuffer(); err != nil {
+		return err
+	}
+
+	// unlike batch-direct, batch-index only reads all bins if asked to
+	operationCount := len(binNames) + len(operations)
+	if operationCount == 0 && readAttr&_INFO1_NOBINDATA == 0 {
+		readAttr |= _INFO1_GET_ALL
+	}
+
+	cmd.writeHeader(policy, readAttr|_INFO1_BATCH, 0, 1, 0)
+
+	// the field size is written once the field is complete
+	fieldSizeOffset := cmd.dataOffset
+	cmd.writeFieldHeader(0, BATCH_INDEX)
+
+	Buffer.Int32ToBytes(int32(batch.offsetSize), cmd.dataBuffer, cmd.dataOffset)
+	cmd.dataOffset += 4
+	// allow the server to process the keys in its service threads
+	cmd.dataBuffer[cmd.dataOffset] = 1
+	cmd.dataOffset++
+
+	for i := 0; i < batch.offsetSize; i++ {
+		offset := batch.offsets[i]
+		Buffer.Int32ToBytes(int32(offset), cmd.dataBuffer, cmd.dataOffset)
+		cmd.dataOffset += 4
+		cmd.dataOffset += copy(cmd.dataBuffer[cmd.dataOffset:], keys[offset].digest)
+
+		if i > 0 {
+			// repeat the namespace and operations of the previous key
+			cmd.dataBuffer[cmd.dataOffset] = 1
+			cmd.dataOffset++
+			continue
+		}
+
+		cmd.dataBuffer[cmd.dataOffset] = 0
+		cmd.dataBuffer[cmd.dataOffset+1] = byte(readAttr)
+		cmd.dataOffset += 2
+		Buffer.Int16ToBytes(1, cmd.dataBuffer, cmd.dataOffset)
+		Buffer.Int16ToBytes(int16(operationCount), cmd.dataBuffer, cmd.dataOffset+2)
+		cmd.dataOffset += 4
+		cmd.writeFieldString(*batch.namespace, NAMESPACE)
+
+		for binName := range binNames {
+			cmd.writeOperationForBinName(binName, READ)
+		}
+
+		for _, operation := range operations {
+			if err := cmd.writeOperationForOperation(operation); err != nil {
+				return err
+			}
+		}
+	}
+
+	Buffer.Int32ToBytes(int32(cmd.dataOffset-fieldSizeOffset-4), cmd.dataBuffer, fieldSizeOffset)
 	cmd.end()
 
 	return nil
 }
 
-func (cmd *baseCommand) setScan(policy *ScanPolicy, namespace *string, setName *string, binNames []string) error {
+// setBatchUDF writes a batch command applying the UDF to the keys, in the
+// batch-any protocol of the batch-index field. Each key is sent with its
+// namespace, set, write attributes and UDF; keys of the same namespace and set
+// as the previous key are flagged to repeat them.
+func (cmd *baseCommand) setBatchUDF(policy *WritePolicy, keys []*Key, batch *batchNamespace, packageName, functionName string, argBytes []byte) error {
+	writeAttr, infoAttr, generation := writePolicyAttrs(policy, _INFO2_WRITE)
+
+	// Estimate buffer size
+	cmd.begin()
+	cmd.dataOffset += int(_FIELD_HEADER_SIZE) + 5
+	var prev *Key
+	for i := 0; i < batch.offsetSize; i++ {
+		key := keys[batch.offsets[i]]
+		cmd.dataOffset += 4 + int(_DIGEST_SIZE) + 1
+		if batchUDFRepeat(policy, prev, key) {
+			continue
+		}
+		prev = key
+
+		cmd.dataOffset += 3 + 2 + 4 + 4
+		cmd.estimateKeySize(key, policy.SendKey)
+		// the digest is sent before the fields
+		cmd.dataOffset -= int(_DIGEST_SIZE + _FIELD_HEADER_SIZE)
+		cmd.estimateUdfSize(packageName, functionName, argBytes)
+	}
+
+	if err := cmd.sizeBuffer(); err != nil {
+		return err
+	}
+
+	cmd.writeHeader(&policy.BasePolicy, _INFO1_BATCH, 0, 1, 0)
+
+	// the field size is written once the field is complete
+	fieldSizeOffset := cmd.dataOffset
+	cmd.writeFieldHeader(0, BATCH_INDEX)
+
+	Buffer.Int32ToBytes(int32(batch.offsetSize), cmd.dataBuffer, cmd.dataOffset)
+	cmd.dataOffset += 4
+	cmd.dataBuffer[cmd.dataOffset] = byte(_BATCH_ALLOW_INLINE | _BATCH_RESPOND_ALL_KEYS)
+	cmd.dataOffset++
+
+	prev = nil
+	for i := 0; i < batch.offsetSize; i++ {
+		offset := batch.offsets[i]
+		key := keys[offset]
+		Buffer.Int32ToBytes(int32(offset), cmd.dataBuffer, cmd.dataOffset)
+		cmd.dataOffset += 4
+		cmd.dataOffset += copy(cmd.dataBuffer[cmd.dataOffset:], key.digest)
+
+		if batchUDFRepeat(policy, prev, key) {
+			cmd.dataBuffer[cmd.dataOffset] = byte(_BATCH_MSG_REPEAT)
+			cmd.dataOffset++
+			continue
+		}
+		prev = key
+
+		cmd.dataBuffer[cmd.dataOffset] = byte(_BATCH_MSG_INFO | _BATCH_MSG_GEN | _BATCH_MSG_TTL)
+		cmd.dataBuffer[cmd.dataOffset+1] = 0
+		cmd.dataBuffer[cmd.dataOffset+2] = byte(writeAttr)
+		cmd.dataBuffer[cmd.dataOffset+3] = byte(infoAttr)
+		cmd.dataOffset += 4
+		Buffer.Int16ToBytes(int16(generation), cmd.dataBuffer, cmd.dataOffset)
+		cmd.dataOffset += 2
+		Buffer.Int32ToBytes(policy.Expiration, cmd.dataBuffer, cmd.dataOffset)
+		cmd.dataOffset += 4
+
+		fieldCount := 4
+		if key.setName != "" {
+			fieldCount++
+		}
+		sendKey := policy.SendKey && key.userKey != nil
+		if sendKey {
+			fieldCount++
+		}
+		Buffer.Int16ToBytes(int16(fieldCount), cmd.dataBuffer, cmd.dataOffset)
+		Buffer.Int16ToBytes(0, cmd.dataBuffer, cmd.dataOffset+2)
+		cmd.dataOffset += 4
+
+		cmd.writeFieldString(key.namespace, NAMESPACE)
+		if key.setName != "" {
+			cmd.writeFieldString(key.setName, TABLE)
+		}
+		if sendKey {
+			cmd.writeFieldValue(key.userKey, KEY)
+		}
+		cmd.writeFieldString(packageName, UDF_PACKAGE_NAME)
+		cmd.writeFieldString(functionName, UDF_FUNCTION)
+		cmd.writeFieldBytes(argBytes, UDF_ARGLIST)
+	}
+
+	Buffer.Int32ToBytes(int32(cmd.dataOffset-fieldSizeOffset-4), cmd.dataBuffer, fieldSizeOffset)
+	cmd.end()
+
+	return nil
+}
+
+// batchUDFRepeat returns true if the key can be sent flagged to repeat the
+// fields of the previous key. User keys differ, so they are never repeated.
+func batchUDFRepeat(policy *WritePolicy, prev, key *Key) bool {
+	return prev != nil && !policy.SendKey && prev.namespace == key.namespace && prev.setName == key.setName
+}
+
+func (cmd *baseCommand) setScan(policy *ScanPolicy, namespace *string, setName *string, binNames []string, taskId uint64) error {
+	priority, err := policy.Priority.scanPriority()
+	if err != nil {
+		return err
+	}
+
 	cmd.begin()
 	fieldCount := 0
 
@@ -392,6 +671,19 @@ func (cmd *baseCommand) setScan(policy *ScanPolicy, namespace *string, setName *
 	cmd.dataOffset += 2 + int(_FIELD_HEADER_SIZE)
 	fieldCount++
 
+	// Allocate space for TaskId field.
+	cmd.dataOffset += 8 + int(_FIELD_HEADER_SIZE)
+	fieldCount++
+
+	predExpSize := 0
+	if len(policy.PredExp) > 0 {
+		for _, predexp := range policy.PredExp {
+			predExpSize += predexp.estimateSize()
+		}
+		cmd.dataOffset += int(_FIELD_HEADER_SIZE) + predExpSize
+		fieldCount++
+	}
+
 	if binNames != nil {
 		for i := range binNames {
 			cmd.estimateOperationSizeForBinName(binNames[i])
@@ -421,9 +713,6 @@ func (cmd *baseCommand) setScan(policy *ScanPolicy, namespace *string, setName *
 	}
 
 	cmd.writeFieldHeader(2, SCAN_OPTIONS)
-	priority := byte(policy.Priority)
-	priority <<= 4
-
 	if policy.FailOnClusterChange {
 		priority |= 0x08
 	}
@@ -432,6 +721,18 @@ func (cmd *baseCommand) setScan(policy *ScanPolicy, namespace *string, setName *
 	cmd.dataBuffer[cmd.dataOffset] = byte(policy.ScanPercent)
 	cmd.dataOffset++
 
+	// the task id identifies the scan to abort it
+	cmd.writeFieldHeader(8, TRAN_ID)
+	Buffer.Int64ToBytes(int64(taskId), cmd.dataBuffer, cmd.dataOffset)
+	cmd.dataOffset += 8
+
+	if len(policy.PredExp) > 0 {
+		cmd.writeFieldHeader(predExpSize, PREDEXP)
+		for _, predexp := range policy.PredExp {
+			cmd.dataOffset = predexp.write(cmd.dataBuffer, cmd.dataOffset)
+		}
+	}
+
 	if binNames != nil {
 		for i := range binNames {
 			cmd.writeOperationForBinName(binNames[i], READ)
@@ -443,7 +744,12 @@ func (cmd *baseCommand) setScan(policy *ScanPolicy, namespace *string, setName *
 }
 
 func (cmd *baseCommand) setQuery(policy *QueryPolicy, statement *Statement, write bool) (err error) {
-	var functionArgBuffer []byte
+	var functionArgBuffer, packedCtx []byte
+
+	priority, err := policy.Priority.scanPriority()
+	if err != nil {
+		return err
+	}
 
 	fieldCount := 0
 	filterSize := 0
@@ -484,6 +790,20 @@ func (cmd *baseCommand) setQuery(policy *QueryPolicy, statement *Statement, writ
 		cmd.dataOffset += filterSize
 		fieldCount++
 
+		// the server only uses the first filter
+		if statement.Filters[0].idxType != ICT_DEFAULT {
+			cmd.dataOffset += int(_FIELD_HEADER_SIZE) + 1
+			fieldCount++
+		}
+
+		if len(statement.Filters[0].ctx) > 0 {
+			if packedCtx, err = packCDTContext(statement.Filters[0].ctx); err != nil {
+				return err
+			}
+			cmd.dataOffset += int(_FIELD_HEADER_SIZE) + len(packedCtx)
+			fieldCount++
+		}
+
 		// Query bin names are specified as a field (Scan bin names are specified later as operations)
 		if len(statement.BinNames) > 0 {
 			cmd.dataOffset += int(_FIELD_HEADER_SIZE)
@@ -502,6 +822,15 @@ func (cmd *baseCommand) setQuery(policy *QueryPolicy, statement *Statement, writ
 		fieldCount++
 	}
 
+	predExpSize := 0
+	if len(statement.PredExp) > 0 {
+		for _, predexp := range statement.PredExp {
+			predExpSize += predexp.estimateSize()
+		}
+		cmd.dataOffset += int(_FIELD_HEADER_SIZE) + predExpSize
+		fieldCount++
+	}
+
 	if statement.functionName != "" {
 		cmd.dataOffset += int(_FIELD_HEADER_SIZE) + 1 // udf type
 		cmd.dataOffset += len(statement.packageName) + int(_FIELD_HEADER_SIZE)
@@ -559,6 +888,12 @@ func (cmd *baseCommand) setQuery(policy *QueryPolicy, statement *Statement, writ
 	cmd.dataOffset += 8
 
 	if len(statement.Filters) > 0 {
+		if idxType := statement.Filters[0].idxType; idxType != ICT_DEFAULT {
+			cmd.writeFieldHeader(1, INDEX_TYPE)
+			cmd.dataBuffer[cmd.dataOffset] = byte(idxType)
+			cmd.dataOffset++
+		}
+
 		cmd.writeFieldHeader(filterSize, INDEX_RANGE)
 		cmd.dataBuffer[cmd.dataOffset] = byte(len(statement.Filters))
 		cmd.dataOffset++
@@ -570,6 +905,10 @@ func (cmd *baseCommand) setQuery(policy *QueryPolicy, statement *Statement, writ
 			}
 		}
 
+		if len(packedCtx) > 0 {
+			cmd.writeFieldBytes(packedCtx, INDEX_CONTEXT)
+		}
+
 		if len(statement.BinNames) > 0 {
 			cmd.writeFieldHeader(binNameSize, QUERY_BINLIST)
 			cmd.dataBuffer[cmd.dataOffset] = byte(len(statement.BinNames))
@@ -584,14 +923,19 @@ func (cmd *baseCommand) setQuery(policy *QueryPolicy, statement *Statement, writ
 	} else {
 		// Calling query with no filters is more efficiently handled by a primary index scan.
 		cmd.writeFieldHeader(2, SCAN_OPTIONS)
-		priority := byte(policy.Priority)
-		priority <<= 4
 		cmd.dataBuffer[cmd.dataOffset] = priority
 		cmd.dataOffset++
 		cmd.dataBuffer[cmd.dataOffset] = byte(100)
 		cmd.dataOffset++
 	}
 
+	if len(statement.PredExp) > 0 {
+		cmd.writeFieldHeader(predExpSize, PREDEXP)
+		for _, predexp := range statement.PredExp {
+			cmd.dataOffset = predexp.write(cmd.dataBuffer, cmd.dataOffset)
+		}
+	}
+
 	if statement.functionName != "" {
 		cmd.writeFieldHeader(1, UDF_OP)
 		if statement.returnData {
@@ -636,7 +980,8 @@ func (cmd *baseCommand) estimateKeySize(key *Key, sendKey bool) int {
 	cmd.dataOffset += int(_DIGEST_SIZE + _FIELD_HEADER_SIZE)
 	fieldCount++
 
-	if sendKey {
+	// keys created from digests have no user key to send
+	if sendKey && key.userKey != nil {
 		// field header size + key size
 		cmd.dataOffset += key.userKey.estimateSize() + int(_FIELD_HEADER_SIZE) + 1
 		fieldCount++
@@ -677,7 +1022,7 @@ func (cmd *baseCommand) estimateOperationSize() {
 // Generic header write.
 func (cmd *baseCommand) writeHeader(policy *BasePolicy, readAttr int, writeAttr int, fieldCount int, operationCount int) {
 
-	if policy.ConsistencyLevel == CONSISTENCY_ALL {
+	if policy.readAllReplicas() {
 		readAttr |= _INFO1_CONSISTENCY_ALL
 	}
 
@@ -689,6 +1034,12 @@ func (cmd *baseCommand) writeHeader(policy *BasePolicy, readAttr int, writeAttr
 	for i := 11; i < 26; i++ {
 		cmd.dataBuffer[i] = 0
 	}
+
+	// the SC read mode only applies to reads
+	if readAttr&_INFO1_READ != 0 && writeAttr&_INFO2_WRITE == 0 {
+		cmd.dataBuffer[11] = byte(readModeSCAttr(policy.ReadModeSC))
+	}
+
 	Buffer.Int16ToBytes(int16(fieldCount), cmd.dataBuffer, 26)
 	Buffer.Int16ToBytes(int16(operationCount), cmd.dataBuffer, 28)
 	cmd.dataOffset = int(_MSG_TOTAL_HEADER_SIZE)
@@ -696,7 +1047,36 @@ func (cmd *baseCommand) writeHeader(policy *BasePolicy, readAttr int, writeAttr
 
 // Header write for write operations.
 func (cmd *baseCommand) writeHeaderWithPolicy(policy *WritePolicy, readAttr int, writeAttr int, fieldCount int, operationCount int) {
-	// Set flags.
+	writeAttr, infoAttr, generation := writePolicyAttrs(policy, writeAttr)
+
+	if policy.readAllReplicas() {
+		readAttr |= _INFO1_CONSISTENCY_ALL
+	}
+
+	// Write all header data except total size which must be written last.
+	cmd.dataBuffer[8] = _MSG_REMAINING_HEADER_SIZE // Message header length.
+	cmd.dataBuffer[9] = byte(readAttr)
+	cmd.dataBuffer[10] = byte(writeAttr)
+	cmd.dataBuffer[11] = byte(infoAttr)
+	cmd.dataBuffer[12] = 0 // unused
+	cmd.dataBuffer[13] = 0 // clear the result code
+	Buffer.Int32ToBytes(generation, cmd.dataBuffer, 14)
+	Buffer.Int32ToBytes(policy.Expiration, cmd.dataBuffer, 18)
+
+	// Initialize timeout. It will be written later.
+	cmd.dataBuffer[22] = 0
+	cmd.dataBuffer[23] = 0
+	cmd.dataBuffer[24] = 0
+	cmd.dataBuffer[25] = 0
+
+	Buffer.Int16ToBytes(int16(fieldCount), cmd.dataBuffer, 26)
+	Buffer.Int16ToBytes(int16(operationCount), cmd.dataBuffer, 28)
+	cmd.dataOffset = int(_MSG_TOTAL_HEADER_SIZE)
+}
+
+// writePolicyAttrs returns the write and info attributes, and the expected
+// generation, of commands sent with the write policy.
+func writePolicyAttrs(policy *WritePolicy, writeAttr int) (int, int, int32) {
 	generation := int32(0)
 	infoAttr := 0
 
@@ -726,29 +1106,7 @@ func (cmd *baseCommand) writeHeaderWithPolicy(policy *WritePolicy, readAttr int,
 		infoAttr |= _INFO3_COMMIT_MASTER
 	}
 
-	if policy.ConsistencyLevel == CONSISTENCY_ALL {
-		readAttr |= _INFO1_CONSISTENCY_ALL
-	}
-
-	// Write all header data except total size which must be written last.
-	cmd.dataBuffer[8] = _MSG_REMAINING_HEADER_SIZE // Message header length.
-	cmd.dataBuffer[9] = byte(readAttr)
-	cmd.dataBuffer[10] = byte(writeAttr)
-	cmd.dataBuffer[11] = byte(infoAttr)
-	cmd.dataBuffer[12] = 0 // unused
-	cmd.dataBuffer[13] = 0 // clear the result code
-	Buffer.Int32ToBytes(generation, cmd.dataBuffer, 14)
-	Buffer.Int32ToBytes(policy.Expiration, cmd.dataBuffer, 18)
-
-	// Initialize timeout. It will be written later.
-	cmd.dataBuffer[22] = 0
-	cmd.dataBuffer[23] = 0
-	cmd.dataBuffer[24] = 0
-	cmd.dataBuffer[25] = 0
-
-	Buffer.Int16ToBytes(int16(fieldCount), cmd.dataBuffer, 26)
-	Buffer.Int16ToBytes(int16(operationCount), cmd.dataBuffer, 28)
-	cmd.dataOffset = int(_MSG_TOTAL_HEADER_SIZE)
+	return writeAttr, infoAttr, generation
 }
 
 func (cmd *baseCommand) writeKey(key *Key, sendKey bool) {
@@ -763,7 +1121,7 @@ func (cmd *baseCommand) writeKey(key *Key, sendKey bool) {
 
 	cmd.writeFieldBytes(key.digest[:], DIGEST_RIPE)
 
-	if sendKey {
+	if sendKey && key.userKey != nil {
 		cmd.writeFieldValue(key.userKey, KEY)
 	}
 }
@@ -918,7 +1276,9 @@ func (cmd *baseCommand) end() {
 var bufPool = NewBufferPool(512, 16*1024, 128*1024)
 
 // SetCommandBufferPool can be used to customize the command Buffer Pool parameters to calibrate
-// the pool for different workloads
+// the pool for different workloads.
+// It must be called before any client is created; it is not safe to call
+// while commands are executed.
 func SetCommandBufferPool(poolSize, initBufSize, maxBufferSize int) {
 	bufPool = NewBufferPool(poolSize, initBufSize, maxBufferSize)
 }
@@ -927,8 +1287,13 @@ func (cmd *baseCommand) execute(ifc command) (err error) {
 	policy := ifc.getPolicy(ifc).GetBasePolicy()
 	iterations := 0
 
+	// in debug builds, make sure shared policies and statements are not modified while in use
+	snapshot := debugSnapshotCommand(ifc)
+	defer snapshot.verify(ifc)
+
 	// set timeout outside the loop
-	limit := time.Now().Add(policy.Timeout)
+	timeout := policy.timeout()
+	limit := time.Now().Add(timeout)
 
 	// set logging level from internal logger
 	scope := log.NewScope(os.Stdout, "aerospike client debug", int(Logger.GetLevel()) + 1)
@@ -936,8 +1301,83 @@ func (cmd *baseCommand) execute(ifc command) (err error) {
 
 	scope.Debug("start execute command")
 
+	start := time.Now()
+	baggage := BaggageFromContext(policy.Context)
+	if len(baggage) > 0 {
+		scope.Debugf("baggage: %s", baggage)
+	}
+
+	if txn := policy.Txn; txn != nil {
+		if err := txn.prepare(ifc); err != nil {
+			return err
+		}
+		defer func() { txn.onResult(ifc, err) }()
+	}
+
+	defer func() {
+		if pc, ok := ifc.(partitionCommand); ok && err != nil {
+			pc.getCluster().partitionErrors.record(pc.getPartition(), cmd.node, isWriteCommand(ifc), err)
+		}
+
+		// failed writes may have been applied as well
+		if pc, ok := ifc.(partitionCommand); ok && cmd.node != nil && isWriteCommand(ifc) {
+			if key := commandKey(ifc); key != nil {
+				pc.getCluster().invalidateCachedRecord(key)
+				pc.getCluster().reads.forget(key)
+			}
+		}
+
+		if cmd.node == nil {
+			return
+		}
+
+		clientPolicy := cmd.node.cluster.policy()
+		duration := time.Since(start)
+		slow := clientPolicy.isSlowCommand(duration)
+		if clientPolicy.CommandObserver == nil && !slow {
+			return
+		}
+
+		event := &CommandEvent{
+			Command:    commandName(ifc),
+			Key:        commandKey(ifc),
+			Node:       cmd.node,
+			Duration:   duration,
+			Iterations: iterations,
+			Err:        err,
+			Baggage:    baggage,
+		}
+		if clientPolicy.CommandObserver != nil {
+			clientPolicy.CommandObserver(event)
+		}
+		if slow {
+			clientPolicy.reportSlowCommand(event)
+		}
+	}()
+
+	// the node of the current attempt, counted in its pending commands
+	var attemptNode *Node
+	var attemptStart time.Time
+	releaseNode := func() {
+		if attemptNode != nil {
+			attemptNode.pendingCommands.DecrementAndGet()
+			attemptNode = nil
+		}
+	}
+	defer releaseNode()
+
+	// only retry once for expired login sessions
+	sessionRenewed := false
+
 	// Execute command until successful, timed out or maximum iterations have been reached.
 	for {
+		releaseNode()
+
+		// the context was cancelled, or its deadline has passed
+		if policy.Context != nil && policy.Context.Err() != nil {
+			return NewAerospikeError(TIMEOUT, "command context is done: "+policy.Context.Err().Error())
+		}
+
 		// too many retries
 		if iterations++; (policy.MaxRetries > 0) && (iterations > policy.MaxRetries+1) {
 			break
@@ -949,7 +1389,7 @@ func (cmd *baseCommand) execute(ifc command) (err error) {
 		}
 
 		// check for command timeout
-		if policy.Timeout > 0 && time.Now().After(limit) {
+		if timeout > 0 && time.Now().After(limit) {
 			break
 		}
 
@@ -964,9 +1404,23 @@ func (cmd *baseCommand) execute(ifc command) (err error) {
 		// set command node, so when you return a record it has the node
 		cmd.node = node
 
-		scope.Debugf("getting connection with timeout %v", policy.Timeout)
+		node.pendingCommands.IncrementAndGet()
+		attemptNode = node
+		attemptStart = time.Now()
+
+		// fail early instead of sending commands the node does not understand anymore
+		if err := node.checkFeatures(ifc); err != nil {
+			return err
+		}
+
+		scope.Debugf("getting connection with timeout %v", timeout)
 
-		cmd.conn, err = node.GetConnection(policy.Timeout)
+		// opening a new connection counts against the command timeout
+		if timeout > 0 {
+			cmd.conn, err = node.getConnectionWithDeadline(limit)
+		} else {
+			cmd.conn, err = node.GetConnection(0)
+		}
 		if err != nil {
 			// Socket connection error has occurred. Decrease health and retry.
 			node.DecreaseHealth()
@@ -991,7 +1445,7 @@ func (cmd *baseCommand) execute(ifc command) (err error) {
 		}
 
 		// Reset timeout in send buffer (destined for server) and socket.
-		Buffer.Int32ToBytes(int32(policy.Timeout/time.Millisecond), cmd.dataBuffer, 22)
+		Buffer.Int32ToBytes(serverTimeout(timeout), cmd.dataBuffer, 22)
 
 		scope.Debug("send command")
 
@@ -1013,6 +1467,18 @@ func (cmd *baseCommand) execute(ifc command) (err error) {
 
 		// Parse results.
 		err = ifc.parseResult(ifc, cmd.conn)
+		if err != nil && isSessionExpired(err) && !sessionRenewed {
+			// the session of the connection expired on the server; log in again
+			// on a new connection and retry once.
+			node.InvalidateConnection(cmd.conn)
+			node.cluster.expireSession(nil)
+			sessionRenewed = true
+
+			// the retry does not count against MaxRetries
+			iterations--
+			continue
+		}
+
 		if err != nil {
 			// close the connection
 			// cancelling/closing the batch/multi commands will return an error, which will
@@ -1034,6 +1500,7 @@ func (cmd *baseCommand) execute(ifc command) (err error) {
 
 		// Reflect healthy status.
 		node.RestoreHealth()
+		node.recordLatency(time.Since(attemptStart))
 
 		// Put connection back in pool.
 		node.PutConnection(cmd.conn)
@@ -0,0 +1,54 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Commit Level Test", func() {
+
+	var key *Key
+
+	BeforeEach(func() {
+		key, _ = NewKey("test", "test", 1)
+	})
+
+	It("should send COMMIT_MASTER on writes, deletes and UDFs", func() {
+		policy := NewWritePolicy(0, 0)
+		policy.CommitLevel = COMMIT_MASTER
+
+		cmd := &baseCommand{}
+		Expect(cmd.setWrite(policy, WRITE, key, []*Bin{NewBin("a", 1)})).ToNot(HaveOccurred())
+		Expect(int(cmd.dataBuffer[11]) & _INFO3_COMMIT_MASTER).To(Equal(_INFO3_COMMIT_MASTER))
+
+		Expect(cmd.setDelete(policy, key)).ToNot(HaveOccurred())
+		Expect(int(cmd.dataBuffer[11]) & _INFO3_COMMIT_MASTER).To(Equal(_INFO3_COMMIT_MASTER))
+
+		Expect(cmd.setUdf(policy, key, "pkg", "fn", nil)).ToNot(HaveOccurred())
+		Expect(int(cmd.dataBuffer[11]) & _INFO3_COMMIT_MASTER).To(Equal(_INFO3_COMMIT_MASTER))
+	})
+
+	It("should wait for all replicas by default", func() {
+		cmd := &baseCommand{}
+		Expect(cmd.setWrite(NewWritePolicy(0, 0), WRITE, key, []*Bin{NewBin("a", 1)})).ToNot(HaveOccurred())
+		Expect(int(cmd.dataBuffer[11]) & _INFO3_COMMIT_MASTER).To(Equal(0))
+
+		Expect(cmd.setUdf(NewWritePolicy(0, 0), key, "pkg", "fn", nil)).ToNot(HaveOccurred())
+		Expect(int(cmd.dataBuffer[11]) & _INFO3_COMMIT_MASTER).To(Equal(0))
+	})
+
+})
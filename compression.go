@@ -0,0 +1,272 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"hash/crc32"
+	"io/ioutil"
+	"strconv"
+	"sync"
+
+	. "github.com/THE108/aerospike-client-go/types"
+	ParticleType "github.com/THE108/aerospike-client-go/types/particle_type"
+)
+
+// CompressionFlate is the id of the built-in DEFLATE compression codec.
+const CompressionFlate = 1
+
+// compressedValueHeaderSize is the size of the header of compressed bins:
+// customValueMagic, a zero type id, the codec id, the particle type of the
+// original value and the CRC-32 of the compressed data. Zero is never
+// registered as a custom value type id. The checksum tells compressed bins
+// apart from user blobs which happen to start with the same bytes.
+const compressedValueHeaderSize = 8
+
+// CompressionCodec compresses bin values. Codecs are registered with
+// RegisterCompressionCodec, e.g. to wrap snappy or zstd implementations.
+// Implementations must be safe for concurrent use.
+type CompressionCodec interface {
+	// Compress returns the compressed form of b.
+	Compress(b []byte) ([]byte, error)
+
+	// Decompress returns the data compressed with Compress.
+	Decompress(b []byte) ([]byte, error)
+}
+
+var compressionCodecs = struct {
+	mutex sync.RWMutex
+	byID  map[uint8]CompressionCodec
+}{
+	byID: map[uint8]CompressionCodec{CompressionFlate: NewFlateCodec(flate.DefaultCompression)},
+}
+
+// RegisterCompressionCodec registers a codec under the id, which is stored
+// with the values it compressed. Register the same codecs with the same ids
+// in all applications sharing the data.
+// codecID must be between 2 and 255; CompressionFlate is built in.
+// Registering an id twice returns an error.
+func RegisterCompressionCodec(codecID uint8, codec CompressionCodec) error {
+	if codecID == 0 || codec == nil {
+		return NewAerospikeError(PARAMETER_ERROR, "Compression codec id must be between 2 and 255, and the codec must not be nil")
+	}
+
+	compressionCodecs.mutex.Lock()
+	defer compressionCodecs.mutex.Unlock()
+
+	if _, exists := compressionCodecs.byID[codecID]; exists {
+		return NewAerospikeError(PARAMETER_ERROR, "Compression codec id "+strconv.Itoa(int(codecID))+" is already registered")
+	}
+	compressionCodecs.byID[codecID] = codec
+	return nil
+}
+
+func compressionCodec(codecID uint8) CompressionCodec {
+	compressionCodecs.mutex.RLock()
+	defer compressionCodecs.mutex.RUnlock()
+	return compressionCodecs.byID[codecID]
+}
+
+type flateCodec struct {
+	level int
+}
+
+// NewFlateCodec returns a DEFLATE codec compressing with the level,
+// see compress/flate.
+func NewFlateCodec(level int) CompressionCodec {
+	return flateCodec{level: level}
+}
+
+func (c flateCodec) Compress(b []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, c.level)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(b); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (c flateCodec) Decompress(b []byte) ([]byte, error) {
+	r := flate.NewReader(bytes.NewReader(b))
+	defer r.Close()
+	return ioutil.ReadAll(r)
+}
+
+// CompressionPolicy encapsulates parameters of client-side bin compression.
+type CompressionPolicy struct {
+	// CodecID is the id of the codec used to compress the bins.
+	CodecID uint8 //= CompressionFlate
+
+	// MinSize is the size of the encoded value from which bins are compressed.
+	MinSize int //= 1024
+}
+
+// NewCompressionPolicy generates a new CompressionPolicy instance with default values.
+func NewCompressionPolicy() *CompressionPolicy {
+	return &CompressionPolicy{
+		CodecID: CompressionFlate,
+		MinSize: 1024,
+	}
+}
+
+// SetCompression enables client-side compression of the bins written to the
+// set by Put, PutBins and PutObject, or disables it if the policy is nil.
+// Bins are only stored compressed if that makes them smaller; compressed bins
+// are stored as blobs with a header, and are decompressed transparently by
+// all reads of the client, regardless of the set configuration.
+// Compressed bins can not be used in server-side operations, expressions,
+// UDFs or secondary indexes; only compress sets with opaque values, like
+// large JSON documents.
+func (clnt *Client) SetCompression(namespace, setName string, policy *CompressionPolicy) error {
+	if policy != nil {
+		if compressionCodec(policy.CodecID) == nil {
+			return NewAerospikeError(PARAMETER_ERROR, "Compression codec id "+strconv.Itoa(int(policy.CodecID))+" is not registered")
+		}
+		p := *policy
+		policy = &p
+	}
+
+	clstr := clnt.cluster
+	clstr.compressionMutex.Lock()
+	defer clstr.compressionMutex.Unlock()
+
+	// copy on write, so commands read the map without locking it for long
+	sets := make(map[string]*CompressionPolicy, len(clstr.compression)+1)
+	for set, p := range clstr.compression {
+		sets[set] = p
+	}
+	if policy != nil {
+		sets[namespace+":"+setName] = policy
+	} else {
+		delete(sets, namespace+":"+setName)
+	}
+	clstr.compression = sets
+	return nil
+}
+
+func (clstr *Cluster) compressionPolicy(key *Key) *CompressionPolicy {
+	clstr.compressionMutex.RLock()
+	defer clstr.compressionMutex.RUnlock()
+	return clstr.compression[key.namespace+":"+key.setName]
+}
+
+// compressBins returns the bins with the values compressed according to the
+// compression policy of the key's set. The bins are returned as is if none
+// of them are compressed.
+func (clstr *Cluster) compressBins(key *Key, bins []*Bin) ([]*Bin, error) {
+	policy := clstr.compressionPolicy(key)
+	if policy == nil {
+		return bins, nil
+	}
+
+	var res []*Bin
+	for i, bin := range bins {
+		value, err := compressValue(policy, bin.Value)
+		if err != nil {
+			return nil, err
+		}
+		if value == nil {
+			if res != nil {
+				res = append(res, bin)
+			}
+			continue
+		}
+
+		if res == nil {
+			res = make([]*Bin, 0, len(bins))
+			res = append(res, bins[:i]...)
+		}
+		res = append(res, &Bin{Name: bin.Name, Value: value})
+	}
+
+	if res == nil {
+		return bins, nil
+	}
+	return res, nil
+}
+
+// compressValue returns the compressed form of the value as a blob, or nil
+// if the value is too small, or compression does not make it smaller.
+func compressValue(policy *CompressionPolicy, value Value) (Value, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	switch value.GetType() {
+	case ParticleType.STRING, ParticleType.BLOB, ParticleType.LIST, ParticleType.MAP:
+	default:
+		return nil, nil
+	}
+
+	size := value.estimateSize()
+	if size < policy.MinSize {
+		return nil, nil
+	}
+
+	plain := make([]byte, size)
+	if _, err := value.write(plain, 0); err != nil {
+		return nil, err
+	}
+
+	codec := compressionCodec(policy.CodecID)
+	if codec == nil {
+		return nil, NewAerospikeError(PARAMETER_ERROR, "Compression codec id "+strconv.Itoa(int(policy.CodecID))+" is not registered")
+	}
+
+	compressed, err := codec.Compress(plain)
+	if err != nil {
+		return nil, NewAerospikeError(SERIALIZE_ERROR, "Error compressing value: "+err.Error())
+	}
+	if compressedValueHeaderSize+len(compressed) >= size {
+		return nil, nil
+	}
+
+	res := make([]byte, compressedValueHeaderSize, compressedValueHeaderSize+len(compressed))
+	res[0], res[1], res[2], res[3] = customValueMagic, 0, policy.CodecID, byte(value.GetType())
+	binary.BigEndian.PutUint32(res[4:], crc32.ChecksumIEEE(compressed))
+	return NewBytesValue(append(res, compressed...)), nil
+}
+
+// decodeCompressedValue decompresses blobs written by compressValue.
+// ok is false for other blobs, and for blobs compressed with an unknown codec.
+func decodeCompressedValue(b []byte) (v interface{}, ok bool, err error) {
+	if len(b) < compressedValueHeaderSize || b[0] != customValueMagic || b[1] != 0 {
+		return nil, false, nil
+	}
+	if binary.BigEndian.Uint32(b[4:]) != crc32.ChecksumIEEE(b[compressedValueHeaderSize:]) {
+		return nil, false, nil
+	}
+
+	codec := compressionCodec(b[2])
+	if codec == nil {
+		return nil, false, nil
+	}
+
+	plain, err := codec.Decompress(b[compressedValueHeaderSize:])
+	if err != nil {
+		return nil, true, NewAerospikeError(PARSE_ERROR, "Error decompressing value: "+err.Error())
+	}
+
+	v, err = bytesToParticle(int(b[3]), plain, 0, len(plain))
+	return v, true, err
+}
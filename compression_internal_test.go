@@ -0,0 +1,152 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"math/rand"
+	"strings"
+
+	ParticleType "github.com/THE108/aerospike-client-go/types/particle_type"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type failingCodec struct{}
+
+func (failingCodec) Compress(b []byte) ([]byte, error)   { return nil, errors.New("compress") }
+func (failingCodec) Decompress(b []byte) ([]byte, error) { return nil, errors.New("decompress") }
+
+// compressedBlob returns a blob with the header of a compressed string.
+func compressedBlob(codecID uint8, data []byte) []byte {
+	blob := []byte{customValueMagic, 0, codecID, byte(ParticleType.STRING), 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(blob[4:], crc32.ChecksumIEEE(data))
+	return append(blob, data...)
+}
+
+var _ = Describe("Compression Test", func() {
+
+	policy := NewCompressionPolicy()
+	doc := strings.Repeat(`{"name":"value","count":42},`, 100)
+
+	// roundTrip compresses the value, and decodes it as read from the server
+	roundTrip := func(value interface{}) (Value, interface{}) {
+		compressed, err := compressValue(policy, NewValue(value))
+		Expect(err).ToNot(HaveOccurred())
+		if compressed == nil {
+			return nil, nil
+		}
+		Expect(compressed.GetType()).To(Equal(ParticleType.BLOB))
+
+		buf := make([]byte, compressed.estimateSize())
+		_, err = compressed.write(buf, 0)
+		Expect(err).ToNot(HaveOccurred())
+
+		obj, err := bytesToParticle(ParticleType.BLOB, buf, 0, len(buf))
+		Expect(err).ToNot(HaveOccurred())
+		return compressed, obj
+	}
+
+	It("should compress large values and decompress them on read", func() {
+		compressed, obj := roundTrip(doc)
+		Expect(obj).To(Equal(doc))
+		Expect(compressed.estimateSize()).To(BeNumerically("<", len(doc)/5))
+
+		list := []interface{}{doc, 1, []byte(doc)}
+		_, obj = roundTrip(list)
+		Expect(obj).To(Equal(list))
+
+		m := map[interface{}]interface{}{"doc": doc}
+		_, obj = roundTrip(m)
+		Expect(obj).To(Equal(m))
+	})
+
+	It("should not compress small, incompressible or numeric values", func() {
+		compressed, _ := roundTrip("small")
+		Expect(compressed).To(BeNil())
+
+		random := make([]byte, 2048)
+		rand.New(rand.NewSource(1)).Read(random)
+		compressed, _ = roundTrip(random)
+		Expect(compressed).To(BeNil())
+
+		compressed, _ = roundTrip(int64(1) << 60)
+		Expect(compressed).To(BeNil())
+	})
+
+	It("should only compress the bins of configured sets", func() {
+		client := &Client{cluster: &Cluster{}}
+		key, _ := NewKey("test", "docs", 1)
+		other, _ := NewKey("test", "other", 1)
+		bins := []*Bin{NewBin("a", 1), NewBin("doc", doc)}
+
+		Expect(client.SetCompression("test", "docs", policy)).ToNot(HaveOccurred())
+
+		res, err := client.cluster.compressBins(key, bins)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(HaveLen(2))
+		Expect(res[0]).To(Equal(bins[0]))
+		Expect(res[1].Name).To(Equal("doc"))
+		Expect(res[1].Value.GetType()).To(Equal(ParticleType.BLOB))
+		Expect(bins[1].Value.GetObject()).To(Equal(doc))
+
+		res, err = client.cluster.compressBins(other, bins)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(bins))
+
+		Expect(client.SetCompression("test", "docs", nil)).ToNot(HaveOccurred())
+		res, err = client.cluster.compressBins(key, bins)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(bins))
+
+		Expect(client.SetCompression("test", "docs", &CompressionPolicy{CodecID: 200})).To(HaveOccurred())
+	})
+
+	It("should register codecs and report their errors", func() {
+		Expect(RegisterCompressionCodec(CompressionFlate, failingCodec{})).To(HaveOccurred())
+		Expect(RegisterCompressionCodec(0, failingCodec{})).To(HaveOccurred())
+		Expect(RegisterCompressionCodec(250, failingCodec{})).ToNot(HaveOccurred())
+		defer func() {
+			compressionCodecs.mutex.Lock()
+			delete(compressionCodecs.byID, 250)
+			compressionCodecs.mutex.Unlock()
+		}()
+
+		_, err := compressValue(&CompressionPolicy{CodecID: 250}, NewValue(doc))
+		Expect(err).To(HaveOccurred())
+
+		// blobs with the header of a compressed value which fail to decompress
+		blob := compressedBlob(250, []byte{1, 2})
+		_, err = bytesToParticle(ParticleType.BLOB, blob, 0, len(blob))
+		Expect(err).To(HaveOccurred())
+
+		// blobs of unknown codecs are read as is
+		blob[2] = 251
+		obj, err := bytesToParticle(ParticleType.BLOB, blob, 0, len(blob))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(obj).To(Equal(blob))
+	})
+
+	It("should read user blobs starting with the header of compressed values as is", func() {
+		blob := []byte{customValueMagic, 0, CompressionFlate, byte(ParticleType.STRING), 1, 2, 3, 4, 5, 6}
+		obj, err := bytesToParticle(ParticleType.BLOB, blob, 0, len(blob))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(obj).To(Equal(blob))
+	})
+
+})
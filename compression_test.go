@@ -0,0 +1,66 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike_test
+
+import (
+	"strings"
+
+	. "github.com/THE108/aerospike-client-go"
+	"github.com/THE108/aerospike-client-go/aerotest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Compression", func() {
+
+	var srv *aerotest.Server
+	var client, other *Client
+
+	BeforeEach(func() {
+		var err error
+		client, srv, err = aerotest.NewClientAndServer("test")
+		Expect(err).ToNot(HaveOccurred())
+		other, err = NewClient(srv.Host(), srv.Port())
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		client.Close()
+		other.Close()
+		Expect(srv.Close()).ToNot(HaveOccurred())
+	})
+
+	It("must decompress the bins written compressed on all reads", func() {
+		Expect(client.SetCompression("test", "aerotest", nil)).ToNot(HaveOccurred())
+		Expect(client.SetCompression("test", "aerotest", NewCompressionPolicy())).ToNot(HaveOccurred())
+
+		doc := strings.Repeat(`{"name":"value","count":42},`, 1000)
+		key, _ := NewKey("test", "aerotest", "doc")
+		Expect(client.Put(nil, key, BinMap{"doc": doc, "n": 1})).ToNot(HaveOccurred())
+
+		// the other client has no compression configured
+		for _, c := range []*Client{client, other} {
+			rec, err := c.Get(nil, key)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rec.Bins).To(Equal(BinMap{"doc": doc, "n": 1}))
+
+			rec, err = c.Get(nil, key, "doc")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rec.Bins).To(Equal(BinMap{"doc": doc}))
+		}
+	})
+
+})
@@ -0,0 +1,180 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike_test
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	. "github.com/THE108/aerospike-client-go"
+	"github.com/THE108/aerospike-client-go/aerotest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// These specs are meant to be run with the race detector (go test -race)
+// to verify objects shared between goroutines are never modified by the client.
+var _ = Describe("Concurrent use", func() {
+
+	var srv *aerotest.Server
+	var client *Client
+
+	BeforeEach(func() {
+		var err error
+		client, srv, err = aerotest.NewClientAndServer("test")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		client.Close()
+		Expect(srv.Close()).ToNot(HaveOccurred())
+	})
+
+	It("must allow sharing policies, keys and bins between goroutines", func() {
+		const goroutines = 8
+		const iterations = 50
+
+		writePolicy := NewWritePolicy(0, 0)
+		readPolicy := NewPolicy()
+		rmwPolicy := NewRMWPolicy()
+		sharedKey, _ := NewKey("test", "aerotest", "shared")
+		bin := NewBin("value", "shared")
+		ops := []*Operation{AddOp(NewBin("count", 1)), GetOp()}
+
+		var wg sync.WaitGroup
+		errs := make(chan error, goroutines*iterations*4)
+		for g := 0; g < goroutines; g++ {
+			wg.Add(1)
+			go func(g int) {
+				defer GinkgoRecover()
+				defer wg.Done()
+
+				key, _ := NewKey("test", "aerotest", strconv.Itoa(g))
+				for i := 0; i < iterations; i++ {
+					if err := client.PutBins(writePolicy, key, bin); err != nil {
+						errs <- err
+					}
+					if _, err := client.Get(readPolicy, key); err != nil {
+						errs <- err
+					}
+					if _, err := client.Operate(writePolicy, sharedKey, ops...); err != nil {
+						errs <- err
+					}
+					err := client.ReadModifyWrite(rmwPolicy, key, func(rec *Record) (BinMap, error) {
+						return BinMap{"n": i}, nil
+					})
+					if err != nil {
+						errs <- err
+					}
+				}
+			}(g)
+		}
+		wg.Wait()
+		close(errs)
+
+		for err := range errs {
+			Expect(err).ToNot(HaveOccurred())
+		}
+
+		rec, err := client.Get(readPolicy, sharedKey, "count")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rec.Bins["count"]).To(Equal(goroutines * iterations))
+		Expect(*writePolicy).To(Equal(*NewWritePolicy(0, 0)))
+	})
+
+	It("must allow hammering the client while nodes join and leave the cluster", func() {
+		const goroutines = 200
+		const iterations = 20
+
+		srv2, err := aerotest.NewServer("test")
+		Expect(err).ToNot(HaveOccurred())
+		defer srv2.Close()
+
+		srv2.SetNodeName("BB9AEROTEST0002")
+		for _, srv := range []*aerotest.Server{srv, srv2} {
+			srv.SetFeatures("peers", "pipelining", "replicas-master")
+		}
+
+		policy := NewClientPolicy()
+		policy.TendInterval = 10 * time.Millisecond
+		tended, err := NewClientWithPolicy(policy, srv.Host(), srv.Port())
+		Expect(err).ToNot(HaveOccurred())
+		defer tended.Close()
+
+		// records may be on either server; only races and panics are checked
+		var wg sync.WaitGroup
+		for g := 0; g < goroutines; g++ {
+			wg.Add(1)
+			go func(g int) {
+				defer GinkgoRecover()
+				defer wg.Done()
+
+				keys := make([]*Key, 0, iterations)
+				for i := 0; i < iterations; i++ {
+					key, _ := NewKey("test", "aerotest", g*iterations+i)
+					keys = append(keys, key)
+
+					tended.Put(nil, key, BinMap{"i": i})
+					tended.Get(nil, key)
+					tended.Exists(nil, key)
+					if i%5 == 4 {
+						tended.BatchGet(nil, keys)
+						tended.Delete(nil, key)
+					}
+					for _, node := range tended.GetNodes() {
+						node.GetName()
+						node.GetAliases()
+						node.Latency()
+					}
+					time.Sleep(time.Millisecond)
+				}
+			}(g)
+		}
+
+		// a node joins, then leaves while the commands run
+		time.Sleep(20 * time.Millisecond)
+		srv.AddPeer(srv2)
+		time.Sleep(50 * time.Millisecond)
+		srv2.Close()
+
+		wg.Wait()
+		Expect(tended.IsConnected()).To(BeTrue())
+	})
+
+	It("must allow closing the client from many goroutines", func() {
+		key, _ := NewKey("test", "aerotest", "close")
+
+		var wg sync.WaitGroup
+		for g := 0; g < 50; g++ {
+			wg.Add(2)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				client.Get(nil, key)
+			}()
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				client.Close()
+			}()
+		}
+		wg.Wait()
+
+		Expect(client.IsConnected()).To(BeFalse())
+	})
+
+})
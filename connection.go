@@ -15,6 +15,7 @@
 package aerospike
 
 import (
+	"crypto/tls"
 	"net"
 	"time"
 
@@ -25,11 +26,13 @@ import (
 // Connection represents a connection with a timeout.
 type Connection struct {
 	// timeout
-	timeout time.Duration
+	timeout  time.Duration
+	deadline time.Time
 
 	// duration after which connection is considered idle
 	idleTimeout  time.Duration
 	idleDeadline time.Time
+	lastUsed     time.Time
 
 	// connection object
 	conn net.Conn
@@ -47,15 +50,49 @@ func errToTimeoutErr(err error) error {
 // If the connection is not established in the specified timeout,
 // an error will be returned
 func NewConnection(address string, timeout time.Duration) (*Connection, error) {
+	return newConnectionWithPolicy(nil, address, "", timeout)
+}
+
+// newConnectionWithPolicy creates a connection, applying the TCP and TLS options
+// of the client policy. If the policy is nil, OS defaults are used.
+// The tlsName is verified on TLS connections if it is not empty.
+func newConnectionWithPolicy(policy *ClientPolicy, address string, tlsName string, timeout time.Duration) (*Connection, error) {
 	newConn := &Connection{}
 
-	conn, err := net.DialTimeout("tcp", address, timeout)
+	var conn net.Conn
+	var err error
+	if policy != nil && policy.DialFunc != nil {
+		conn, err = policy.DialFunc("tcp", address, timeout)
+	} else {
+		dialer := net.Dialer{Timeout: timeout}
+		if policy != nil {
+			dialer.KeepAlive = policy.TCPKeepAlive
+		}
+		conn, err = dialer.Dial("tcp", address)
+	}
 	if err != nil {
 		Logger.Error("Connection to address `" + address + "` failed to establish with error: " + err.Error())
 		return nil, errToTimeoutErr(err)
 	}
 	newConn.conn = conn
 
+	if policy != nil {
+		if err := setTCPOptions(conn, policy); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	if policy != nil && policy.TLSConfig != nil {
+		tlsConn, err := newTLSConnection(conn, policy.TLSConfig, address, tlsName, timeout)
+		if err != nil {
+			Logger.Error("TLS handshake with address `%s` failed with error: %s", address, err)
+			conn.Close()
+			return nil, errToTimeoutErr(err)
+		}
+		newConn.conn = tlsConn
+	}
+
 	// set timeout at the last possible moment
 	if err := newConn.SetTimeout(timeout); err != nil {
 		return nil, err
@@ -63,6 +100,58 @@ func NewConnection(address string, timeout time.Duration) (*Connection, error) {
 	return newConn, nil
 }
 
+// newTLSConnection performs the TLS handshake on the connection within the timeout.
+// The tlsName is verified if it is set, otherwise the ServerName of the config,
+// or the host name of the address.
+func newTLSConnection(conn net.Conn, config *tls.Config, address string, tlsName string, timeout time.Duration) (*tls.Conn, error) {
+	if tlsName != "" {
+		config = config.Clone()
+		config.ServerName = tlsName
+	} else if config.ServerName == "" {
+		if host, _, err := net.SplitHostPort(address); err == nil {
+			config = config.Clone()
+			config.ServerName = host
+		}
+	}
+
+	tlsConn := tls.Client(conn, config)
+	if timeout > 0 {
+		if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+			return nil, err
+		}
+	}
+	if err := tlsConn.Handshake(); err != nil {
+		return nil, err
+	}
+	return tlsConn, nil
+}
+
+// setTCPOptions applies the TCP options of the client policy to the connection.
+func setTCPOptions(conn net.Conn, policy *ClientPolicy) error {
+	tcpConn, ok := conn.(*net.TCPConn)
+	if !ok {
+		return nil
+	}
+
+	// Go disables Nagle's algorithm on TCP connections by default
+	if policy.DisableTCPNoDelay {
+		if err := tcpConn.SetNoDelay(false); err != nil {
+			return err
+		}
+	}
+	if policy.SendBufferSize > 0 {
+		if err := tcpConn.SetWriteBuffer(policy.SendBufferSize); err != nil {
+			return err
+		}
+	}
+	if policy.ReceiveBufferSize > 0 {
+		if err := tcpConn.SetReadBuffer(policy.ReceiveBufferSize); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Write writes the slice to the connection buffer.
 func (ctn *Connection) Write(buf []byte) (total int, err error) {
 	// make sure all bytes are written
@@ -124,12 +213,41 @@ func (ctn *Connection) SetTimeout(timeout time.Duration) error {
 			if err := ctn.conn.SetDeadline(deadline); err != nil {
 				return err
 			}
+			ctn.deadline = deadline
 		}
 	}
 
 	return nil
 }
 
+// setDeadline sets the timeout of the connection to the time left until
+// the deadline. A zero deadline removes the timeout.
+func (ctn *Connection) setDeadline(deadline time.Time) error {
+	if deadline.IsZero() {
+		return ctn.SetTimeout(0)
+	}
+
+	timeout := deadline.Sub(time.Now())
+	if timeout <= 0 {
+		return NewAerospikeError(TIMEOUT)
+	}
+	return ctn.SetTimeout(timeout)
+}
+
+// extendDeadline postpones the deadline of the connection, if any.
+func (ctn *Connection) extendDeadline(d time.Duration) error {
+	if ctn.conn == nil || ctn.deadline.IsZero() || d <= 0 {
+		return nil
+	}
+
+	deadline := ctn.deadline.Add(d)
+	if err := ctn.conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+	ctn.deadline = deadline
+	return nil
+}
+
 // Close closes the connection
 func (ctn *Connection) Close() {
 	if ctn != nil && ctn.conn != nil {
@@ -165,5 +283,6 @@ func (ctn *Connection) isIdle() bool {
 
 // refresh extends the idle deadline of the connection.
 func (ctn *Connection) refresh() {
-	ctn.idleDeadline = time.Now().Add(ctn.idleTimeout)
+	ctn.lastUsed = time.Now()
+	ctn.idleDeadline = ctn.lastUsed.Add(ctn.idleTimeout)
 }
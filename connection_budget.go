@@ -0,0 +1,83 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"strconv"
+
+	. "github.com/THE108/aerospike-client-go/logger"
+)
+
+const (
+	infoStatistics    = "statistics"
+	infoServiceConfig = "get-config:context=service"
+)
+
+// connectionBudgetInfo returns the info commands requested on tend to
+// monitor the connection budget of the node, if enabled.
+func (nd *Node) connectionBudgetInfo() []string {
+	if nd.cluster.policy().ServerConnectionsFraction <= 0 {
+		return nil
+	}
+	return []string{infoStatistics, infoServiceConfig}
+}
+
+// ServerConnections returns the number of client connections to the node
+// and its file descriptor limit, as reported on the last tend.
+// Both are -1 if unknown; they are only requested if
+// ClientPolicy.ServerConnectionsFraction is set.
+func (nd *Node) ServerConnections() (connections, fdMax int) {
+	return nd.serverConnections.Get(), nd.serverFdMax.Get()
+}
+
+// refreshConnectionBudget records the client_connections statistic and the
+// proto-fd-max setting reported by the node.
+func (nd *Node) refreshConnectionBudget(infoMap map[string]string) {
+	if nd.cluster.policy().ServerConnectionsFraction <= 0 {
+		return
+	}
+
+	connections, err1 := strconv.Atoi(parseInfoParams(infoMap[infoStatistics])["client_connections"])
+	fdMax, err2 := strconv.Atoi(parseInfoParams(infoMap[infoServiceConfig])["proto-fd-max"])
+	if err1 != nil || err2 != nil {
+		Logger.Warn("Node `%s` did not report client_connections and proto-fd-max; connection budget is not enforced", nd.name)
+		connections, fdMax = -1, -1
+	}
+
+	nd.serverConnections.Set(connections)
+	nd.serverFdMax.Set(fdMax)
+	nd.tendConnections.Set(nd.connectionCount.Get())
+}
+
+// connectionBudgetExhausted returns true if the connections of all clients
+// to the node have reached the configured fraction of its file descriptor limit.
+// Connections opened by this client since the last tend are added to the
+// reported count. A node without connections from this client is never
+// considered exhausted, so it can still be tended.
+func (nd *Node) connectionBudgetExhausted() bool {
+	fraction := nd.cluster.policy().ServerConnectionsFraction
+	if fraction <= 0 {
+		return false
+	}
+
+	connections, fdMax := nd.ServerConnections()
+	count := nd.connectionCount.Get()
+	if connections < 0 || fdMax <= 0 || count == 0 {
+		return false
+	}
+
+	connections += count - nd.tendConnections.Get()
+	return float64(connections) >= fraction*float64(fdMax)
+}
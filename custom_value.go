@@ -0,0 +1,230 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"sync"
+
+	. "github.com/THE108/aerospike-client-go/types"
+	. "github.com/THE108/aerospike-client-go/types/atomic"
+	ParticleType "github.com/THE108/aerospike-client-go/types/particle_type"
+	Buffer "github.com/THE108/aerospike-client-go/utils/buffer"
+)
+
+// ValueCodec encodes values of a user defined type to bytes, and decodes
+// them back. Codecs are registered with RegisterValueType.
+// Implementations must be safe for concurrent use.
+type ValueCodec interface {
+	// EncodeValue returns the encoding of v, which is always of the
+	// registered type.
+	EncodeValue(v interface{}) ([]byte, error)
+
+	// DecodeValue returns the value of the registered type for the encoding.
+	DecodeValue(b []byte) (interface{}, error)
+}
+
+// customValueMagic marks blobs holding a registered user type.
+// 0xC1 is never used by MessagePack.
+const customValueMagic = 0xC1
+
+// customValueHeaderSize is the size of the magic byte and the type id.
+const customValueHeaderSize = 2
+
+type customValueType struct {
+	id    uint8
+	rtype reflect.Type
+	codec ValueCodec
+}
+
+var customValueTypes = struct {
+	mutex  sync.RWMutex
+	byID   map[uint8]*customValueType
+	byType map[reflect.Type]*customValueType
+
+	// registered avoids locking the registry if no types are registered
+	registered *AtomicBool
+}{
+	byID:       map[uint8]*customValueType{},
+	byType:     map[reflect.Type]*customValueType{},
+	registered: NewAtomicBool(false),
+}
+
+// RegisterValueType registers a codec for the type of sample, so values of
+// that type can be used as bin values, and inside lists and maps, without
+// converting them first.
+// Values are stored as blobs tagged with typeID; reading them returns
+// values of the registered type again. Register the same types with the
+// same ids in all applications sharing the data.
+// typeID must be between 1 and 255. Registering an id or a type twice
+// returns an error.
+func RegisterValueType(typeID uint8, sample interface{}, codec ValueCodec) error {
+	if typeID == 0 {
+		return NewAerospikeError(PARAMETER_ERROR, "Value type id must be between 1 and 255")
+	}
+	if sample == nil || codec == nil {
+		return NewAerospikeError(PARAMETER_ERROR, "Value type sample and codec must not be nil")
+	}
+
+	rtype := reflect.TypeOf(sample)
+
+	customValueTypes.mutex.Lock()
+	defer customValueTypes.mutex.Unlock()
+
+	if _, exists := customValueTypes.byID[typeID]; exists {
+		return NewAerospikeError(PARAMETER_ERROR, "Value type id "+strconv.Itoa(int(typeID))+" is already registered")
+	}
+	if _, exists := customValueTypes.byType[rtype]; exists {
+		return NewAerospikeError(PARAMETER_ERROR, "Value type "+rtype.String()+" is already registered")
+	}
+
+	vt := &customValueType{id: typeID, rtype: rtype, codec: codec}
+	customValueTypes.byID[typeID] = vt
+	customValueTypes.byType[rtype] = vt
+	customValueTypes.registered.Set(true)
+	return nil
+}
+
+// UnregisterValueType removes the codec registered for typeID.
+// Stored values of the type are read as plain blobs afterwards.
+func UnregisterValueType(typeID uint8) {
+	customValueTypes.mutex.Lock()
+	defer customValueTypes.mutex.Unlock()
+
+	if vt, exists := customValueTypes.byID[typeID]; exists {
+		delete(customValueTypes.byID, typeID)
+		delete(customValueTypes.byType, vt.rtype)
+	}
+	customValueTypes.registered.Set(len(customValueTypes.byID) > 0)
+}
+
+// newCustomValue returns a Value for v if its type is registered, or nil.
+func newCustomValue(v interface{}) Value {
+	if !customValueTypes.registered.Get() {
+		return nil
+	}
+
+	customValueTypes.mutex.RLock()
+	vt := customValueTypes.byType[reflect.TypeOf(v)]
+	customValueTypes.mutex.RUnlock()
+
+	if vt == nil {
+		return nil
+	}
+
+	b, err := vt.codec.EncodeValue(v)
+	if err != nil {
+		err = NewAerospikeError(SERIALIZE_ERROR, "Error encoding value of type "+vt.rtype.String()+": "+err.Error())
+	}
+	return &CustomValue{typeID: vt.id, object: v, encoded: b, err: err}
+}
+
+// isCustomValueType returns true if a codec is registered for the type.
+func isCustomValueType(t reflect.Type) bool {
+	if !customValueTypes.registered.Get() {
+		return false
+	}
+
+	customValueTypes.mutex.RLock()
+	_, exists := customValueTypes.byType[t]
+	customValueTypes.mutex.RUnlock()
+	return exists
+}
+
+// decodeCustomValue decodes blobs tagged with a registered type id.
+// ok is false for other blobs.
+func decodeCustomValue(b []byte) (v interface{}, ok bool, err error) {
+	if len(b) < customValueHeaderSize || b[0] != customValueMagic || !customValueTypes.registered.Get() {
+		return nil, false, nil
+	}
+
+	customValueTypes.mutex.RLock()
+	vt := customValueTypes.byID[b[1]]
+	customValueTypes.mutex.RUnlock()
+
+	if vt == nil {
+		return nil, false, nil
+	}
+
+	v, err = vt.codec.DecodeValue(b[customValueHeaderSize:])
+	if err != nil {
+		return nil, true, NewAerospikeError(PARSE_ERROR, "Error decoding value of type "+vt.rtype.String()+": "+err.Error())
+	}
+	return v, true, nil
+}
+
+// CustomValue encapsulates a value of a type registered with RegisterValueType.
+type CustomValue struct {
+	typeID  uint8
+	object  interface{}
+	encoded []byte
+
+	// err is the encoding error, returned on serialization
+	err error
+}
+
+func (vl *CustomValue) bytes() []byte {
+	return append([]byte{customValueMagic, vl.typeID}, vl.encoded...)
+}
+
+func (vl *CustomValue) estimateSize() int {
+	return customValueHeaderSize + len(vl.encoded)
+}
+
+func (vl *CustomValue) write(buffer []byte, offset int) (int, error) {
+	if vl.err != nil {
+		return 0, vl.err
+	}
+
+	buffer[offset] = customValueMagic
+	buffer[offset+1] = vl.typeID
+	copy(buffer[offset+customValueHeaderSize:], vl.encoded)
+	return vl.estimateSize(), nil
+}
+
+func (vl *CustomValue) pack(packer *packer) error {
+	if vl.err != nil {
+		return vl.err
+	}
+
+	packer.PackBytes(vl.bytes())
+	return nil
+}
+
+// GetType returns wire protocol value type.
+func (vl *CustomValue) GetType() int {
+	return ParticleType.BLOB
+}
+
+// GetObject returns original value as an interface{}.
+func (vl *CustomValue) GetObject() interface{} {
+	return vl.object
+}
+
+func (vl *CustomValue) reader() io.Reader {
+	return bytes.NewReader(vl.bytes())
+}
+
+// String implements Stringer interface.
+func (vl *CustomValue) String() string {
+	if s, ok := vl.object.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return Buffer.BytesToHexString(vl.encoded)
+}
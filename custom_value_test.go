@@ -0,0 +1,144 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"errors"
+	"reflect"
+
+	. "github.com/THE108/aerospike-client-go/types"
+	ParticleType "github.com/THE108/aerospike-client-go/types/particle_type"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type testUUID [4]byte
+
+type testUUIDCodec struct{}
+
+func (testUUIDCodec) EncodeValue(v interface{}) ([]byte, error) {
+	u := v.(testUUID)
+	if u == (testUUID{}) {
+		return nil, errors.New("empty uuid")
+	}
+	return u[:], nil
+}
+
+func (testUUIDCodec) DecodeValue(b []byte) (interface{}, error) {
+	var u testUUID
+	if len(b) != len(u) {
+		return nil, errors.New("invalid uuid length")
+	}
+	copy(u[:], b)
+	return u, nil
+}
+
+var _ = Describe("Custom Value Test", func() {
+
+	const typeID = 200
+	uuid := testUUID{1, 2, 3, 4}
+
+	BeforeEach(func() {
+		Expect(RegisterValueType(typeID, testUUID{}, testUUIDCodec{})).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		UnregisterValueType(typeID)
+	})
+
+	It("should encode and decode registered types as bin values", func() {
+		value := NewValue(uuid)
+		Expect(value.GetType()).To(Equal(ParticleType.BLOB))
+		Expect(value.GetObject()).To(Equal(uuid))
+
+		buf := make([]byte, value.estimateSize())
+		n, err := value.write(buf, 0)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(n).To(Equal(len(buf)))
+
+		obj, err := bytesToParticle(ParticleType.BLOB, buf, 0, n)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(obj).To(Equal(uuid))
+	})
+
+	It("should encode and decode registered types in lists and maps", func() {
+		Expect(testPackingFor([]interface{}{uuid, 1})).To(Equal([]interface{}{uuid, 1}))
+		Expect(testPackingFor(map[interface{}]interface{}{"id": uuid})).To(Equal(map[interface{}]interface{}{"id": uuid}))
+	})
+
+	It("should return encoding errors on serialization", func() {
+		value := NewValue(testUUID{})
+
+		buf := make([]byte, value.estimateSize())
+		_, err := value.write(buf, 0)
+		Expect(err).To(HaveOccurred())
+
+		Expect(newPacker().PackObject([]interface{}{testUUID{}})).To(HaveOccurred())
+	})
+
+	It("should read unregistered types as blobs", func() {
+		value := NewValue(uuid)
+		buf := make([]byte, value.estimateSize())
+		_, err := value.write(buf, 0)
+		Expect(err).ToNot(HaveOccurred())
+
+		UnregisterValueType(typeID)
+		obj, err := bytesToParticle(ParticleType.BLOB, buf, 0, len(buf))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(obj).To(Equal(buf))
+	})
+
+	It("should marshal and unmarshal struct fields of registered types", func() {
+		type object struct {
+			ID testUUID
+		}
+
+		bins := structToMap(reflect.ValueOf(object{ID: uuid}))
+		Expect(bins).To(Equal(map[string]interface{}{"ID": uuid}))
+
+		var obj object
+		Expect(setValue(reflect.ValueOf(&obj).Elem().Field(0), uuid)).ToNot(HaveOccurred())
+		Expect(obj.ID).To(Equal(uuid))
+	})
+
+	It("should leave struct fields of registered types unset for nil values", func() {
+		type object struct {
+			ID testUUID
+		}
+
+		obj := object{ID: uuid}
+		Expect(setValue(reflect.ValueOf(&obj).Elem().Field(0), nil)).ToNot(HaveOccurred())
+		Expect(obj.ID).To(Equal(uuid))
+	})
+
+	It("should reject values of other types for struct fields of registered types", func() {
+		type object struct {
+			ID testUUID
+		}
+
+		var obj object
+		err := setValue(reflect.ValueOf(&obj).Elem().Field(0), []byte{1, 2, 3})
+		Expect(err).To(HaveOccurred())
+		Expect(err.(AerospikeError).ResultCode()).To(Equal(BIN_TYPE_ERROR))
+	})
+
+	It("should reject duplicate registrations", func() {
+		Expect(RegisterValueType(typeID, struct{}{}, testUUIDCodec{})).To(HaveOccurred())
+		Expect(RegisterValueType(typeID+1, testUUID{}, testUUIDCodec{})).To(HaveOccurred())
+		Expect(RegisterValueType(0, struct{}{}, testUUIDCodec{})).To(HaveOccurred())
+	})
+
+})
@@ -0,0 +1,98 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package aerospike
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Shared objects and their concurrency contracts:
+//
+//  - Client, Cluster, Node and Recordset are synchronized and are safe for
+//    concurrent use.
+//  - Policies, Statements, Keys, Bins, Values, Filters, Operations and PredExps
+//    are not synchronized, but the client never modifies them. They can be
+//    shared between goroutines once they are set up, as long as they are not
+//    modified while in use by any command.
+//  - Records, BinMaps and Results returned by the client belong to the caller.
+//
+// When built with the `debug` build tag, the client verifies that the policy
+// and the statement of each command are not modified while the command is
+// running, and panics otherwise.
+
+// commandSnapshot holds shallow copies of the policy and the statement of a command.
+type commandSnapshot struct {
+	policy    interface{}
+	statement interface{}
+}
+
+// statementCommand is implemented by commands running a Statement.
+type statementCommand interface {
+	getStatement() *Statement
+}
+
+// debugSnapshotCommand takes a snapshot of the command's policy and statement
+// in debug builds. In other builds it returns nil.
+func debugSnapshotCommand(ifc command) *commandSnapshot {
+	if !debugAssertions {
+		return nil
+	}
+	return snapshotCommand(ifc)
+}
+
+func snapshotCommand(ifc command) *commandSnapshot {
+	res := &commandSnapshot{policy: shallowCopy(ifc.getPolicy(ifc))}
+	if sc, ok := ifc.(statementCommand); ok {
+		res.statement = shallowCopy(sc.getStatement())
+	}
+	return res
+}
+
+// verify panics if the command's policy or statement have changed since the snapshot.
+// It is a no-op on nil snapshots.
+func (s *commandSnapshot) verify(ifc command) {
+	if s == nil {
+		return
+	}
+
+	if err := s.check(ifc); err != nil {
+		panic(err)
+	}
+}
+
+func (s *commandSnapshot) check(ifc command) error {
+	policy := ifc.getPolicy(ifc)
+	if !sameValue(policy, s.policy) {
+		return fmt.Errorf("aerospike: %T was modified while in use by a %s command; policies shared between goroutines must not be modified", policy, commandName(ifc))
+	}
+
+	if sc, ok := ifc.(statementCommand); ok && !sameValue(sc.getStatement(), s.statement) {
+		return fmt.Errorf("aerospike: Statement was modified while in use by a %s command; statements shared between goroutines must not be modified", commandName(ifc))
+	}
+	return nil
+}
+
+// shallowCopy returns a copy of the struct pointed to by ptr, or nil.
+func shallowCopy(ptr interface{}) interface{} {
+	v := reflect.ValueOf(ptr)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return nil
+	}
+	return v.Elem().Interface()
+}
+
+func sameValue(ptr interface{}, snapshot interface{}) bool {
+	return reflect.DeepEqual(shallowCopy(ptr), snapshot)
+}
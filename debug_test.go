@@ -0,0 +1,60 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Debug Assertions Test", func() {
+
+	It("should detect policies modified while in use", func() {
+		key, _ := NewKey("test", "test", 1)
+		policy := NewPolicy()
+		cmd := newReadCommand(nil, policy, key, nil)
+
+		snapshot := snapshotCommand(cmd)
+		Expect(snapshot.check(cmd)).ToNot(HaveOccurred())
+
+		policy.Timeout = time.Second
+		Expect(snapshot.check(cmd)).To(HaveOccurred())
+	})
+
+	It("should detect statements modified while in use", func() {
+		stmt := NewStatement("test", "test")
+		cmd := newQueryRecordCommand(nil, NewQueryPolicy(), stmt, nil)
+
+		snapshot := snapshotCommand(cmd)
+		Expect(snapshot.check(cmd)).ToNot(HaveOccurred())
+
+		stmt.Addfilter(NewRangeFilter("bin", 1, 2))
+		Expect(snapshot.check(cmd)).To(HaveOccurred())
+	})
+
+	It("should only take snapshots in debug builds", func() {
+		key, _ := NewKey("test", "test", 1)
+		cmd := newReadCommand(nil, NewPolicy(), key, nil)
+
+		snapshot := debugSnapshotCommand(cmd)
+		Expect(snapshot != nil).To(Equal(debugAssertions))
+
+		// verifying an unchanged or a nil snapshot is a no-op
+		snapshot.verify(cmd)
+	})
+
+})
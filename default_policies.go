@@ -0,0 +1,192 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+// policyScope is a namespace, or a set of a namespace, default policies
+// are registered for. An empty set name is the whole namespace.
+type policyScope struct {
+	namespace string
+	setName   string
+}
+
+// scopedPolicies holds the default policies registered per namespace and set.
+// It is copied on write, so readers don't need to lock it while resolving.
+type scopedPolicies struct {
+	read  map[policyScope]*BasePolicy
+	write map[policyScope]*WritePolicy
+	scan  map[policyScope]*ScanPolicy
+	query map[policyScope]*QueryPolicy
+}
+
+// updateScopedPolicies replaces the registered default policies with
+// a copy modified by the function.
+func (clnt *Client) updateScopedPolicies(f func(sp *scopedPolicies)) {
+	clnt.scopedPoliciesMutex.Lock()
+	defer clnt.scopedPoliciesMutex.Unlock()
+
+	var sp scopedPolicies
+	if clnt.scopedPolicies != nil {
+		sp = *clnt.scopedPolicies
+	}
+	f(&sp)
+	clnt.scopedPolicies = &sp
+}
+
+func (clnt *Client) getScopedPolicies() *scopedPolicies {
+	clnt.scopedPoliciesMutex.RLock()
+	sp := clnt.scopedPolicies
+	clnt.scopedPoliciesMutex.RUnlock()
+	return sp
+}
+
+// SetDefaultPolicy registers the default read policy for the set of the
+// namespace, or for the whole namespace if setName is empty.
+// Read commands called without a policy use the policy registered for their
+// set, then the one registered for their namespace, and then DefaultPolicy
+// adjusted by the namespace hint. Batch reads use the policy registered for
+// the namespace if all their keys belong to it.
+// A nil policy removes the registration.
+func (clnt *Client) SetDefaultPolicy(namespace, setName string, policy *BasePolicy) {
+	scope := policyScope{namespace: namespace, setName: setName}
+	clnt.updateScopedPolicies(func(sp *scopedPolicies) {
+		policies := make(map[policyScope]*BasePolicy, len(sp.read)+1)
+		for s, p := range sp.read {
+			policies[s] = p
+		}
+
+		if policy == nil {
+			delete(policies, scope)
+		} else {
+			policies[scope] = policy.Clone()
+		}
+		sp.read = policies
+	})
+}
+
+// SetDefaultWritePolicy registers the default write policy for the set of the
+// namespace, or for the whole namespace if setName is empty.
+// Write commands called without a policy use the policy registered for their
+// set, then the one registered for their namespace, and then DefaultWritePolicy
+// adjusted by the namespace hint. A nil policy removes the registration.
+func (clnt *Client) SetDefaultWritePolicy(namespace, setName string, policy *WritePolicy) {
+	scope := policyScope{namespace: namespace, setName: setName}
+	clnt.updateScopedPolicies(func(sp *scopedPolicies) {
+		policies := make(map[policyScope]*WritePolicy, len(sp.write)+1)
+		for s, p := range sp.write {
+			policies[s] = p
+		}
+
+		if policy == nil {
+			delete(policies, scope)
+		} else {
+			policies[scope] = policy.Clone()
+		}
+		sp.write = policies
+	})
+}
+
+// SetDefaultScanPolicy registers the default scan policy for the set of the
+// namespace, or for the whole namespace if setName is empty.
+// Scans called without a policy use the policy registered for their set,
+// then the one registered for their namespace, and then DefaultScanPolicy.
+// A nil policy removes the registration.
+func (clnt *Client) SetDefaultScanPolicy(namespace, setName string, policy *ScanPolicy) {
+	scope := policyScope{namespace: namespace, setName: setName}
+	clnt.updateScopedPolicies(func(sp *scopedPolicies) {
+		policies := make(map[policyScope]*ScanPolicy, len(sp.scan)+1)
+		for s, p := range sp.scan {
+			policies[s] = p
+		}
+
+		if policy == nil {
+			delete(policies, scope)
+		} else {
+			policies[scope] = policy.Clone()
+		}
+		sp.scan = policies
+	})
+}
+
+// SetDefaultQueryPolicy registers the default query policy for the set of the
+// namespace, or for the whole namespace if setName is empty.
+// Queries called without a policy use the policy registered for the set of
+// their statement, then the one registered for its namespace, and then
+// DefaultQueryPolicy. A nil policy removes the registration.
+func (clnt *Client) SetDefaultQueryPolicy(namespace, setName string, policy *QueryPolicy) {
+	scope := policyScope{namespace: namespace, setName: setName}
+	clnt.updateScopedPolicies(func(sp *scopedPolicies) {
+		policies := make(map[policyScope]*QueryPolicy, len(sp.query)+1)
+		for s, p := range sp.query {
+			policies[s] = p
+		}
+
+		if policy == nil {
+			delete(policies, scope)
+		} else {
+			policies[scope] = policy.Clone()
+		}
+		sp.query = policies
+	})
+}
+
+// scopedReadPolicy returns the read policy registered for the set, or for
+// the namespace, or nil.
+func (clnt *Client) scopedReadPolicy(namespace, setName string) *BasePolicy {
+	sp := clnt.getScopedPolicies()
+	if sp == nil {
+		return nil
+	}
+
+	if p := sp.read[policyScope{namespace, setName}]; p != nil {
+		return p
+	}
+	return sp.read[policyScope{namespace: namespace}]
+}
+
+// scopedWritePolicy returns the write policy registered for the set, or for
+// the namespace, or nil.
+func (clnt *Client) scopedWritePolicy(namespace, setName string) *WritePolicy {
+	sp := clnt.getScopedPolicies()
+	if sp == nil {
+		return nil
+	}
+
+	if p := sp.write[policyScope{namespace, setName}]; p != nil {
+		return p
+	}
+	return sp.write[policyScope{namespace: namespace}]
+}
+
+// getUsableScanPolicyFor returns a copy of the policy, or of the default
+// scan policy of the set or namespace if the policy is nil.
+func (clnt *Client) getUsableScanPolicyFor(policy *ScanPolicy, namespace, setName string) *ScanPolicy {
+	if sp := clnt.getScopedPolicies(); policy == nil && sp != nil {
+		if policy = sp.scan[policyScope{namespace, setName}]; policy == nil {
+			policy = sp.scan[policyScope{namespace: namespace}]
+		}
+	}
+	return clnt.getUsableScanPolicy(policy)
+}
+
+// getUsableQueryPolicyFor returns a copy of the policy, or of the default
+// query policy of the statement's set or namespace if the policy is nil.
+func (clnt *Client) getUsableQueryPolicyFor(policy *QueryPolicy, statement *Statement) *QueryPolicy {
+	if sp := clnt.getScopedPolicies(); policy == nil && sp != nil {
+		if policy = sp.query[policyScope{statement.Namespace, statement.SetName}]; policy == nil {
+			policy = sp.query[policyScope{namespace: statement.Namespace}]
+		}
+	}
+	return clnt.getUsableQueryPolicy(policy)
+}
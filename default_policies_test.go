@@ -0,0 +1,85 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Default Policies Test", func() {
+
+	var client *Client
+
+	BeforeEach(func() {
+		client = &Client{
+			DefaultPolicy:      NewPolicy(),
+			DefaultWritePolicy: NewWritePolicy(0, 0),
+			DefaultScanPolicy:  NewScanPolicy(),
+			DefaultQueryPolicy: NewQueryPolicy(),
+		}
+	})
+
+	It("should resolve set, then namespace, then global defaults", func() {
+		nsPolicy := NewWritePolicy(0, 100)
+		setPolicy := NewWritePolicy(0, 200)
+		client.SetDefaultWritePolicy("tenant", "", nsPolicy)
+		client.SetDefaultWritePolicy("tenant", "orders", setPolicy)
+
+		Expect(client.getUsableWritePolicyFor(nil, "tenant", "orders").Expiration).To(Equal(int32(200)))
+		Expect(client.getUsableWritePolicyFor(nil, "tenant", "users").Expiration).To(Equal(int32(100)))
+		Expect(client.getUsableWritePolicyFor(nil, "other", "orders")).To(Equal(client.DefaultWritePolicy))
+
+		// explicitly passed policies are used as is
+		policy := NewWritePolicy(0, 0)
+		Expect(client.getUsableWritePolicyFor(policy, "tenant", "orders")).To(Equal(policy))
+
+		// registered policies are copies
+		setPolicy.Expiration = 1
+		Expect(client.getUsableWritePolicyFor(nil, "tenant", "orders").Expiration).To(Equal(int32(200)))
+	})
+
+	It("should prefer registered read policies over namespace hints", func() {
+		client.SetNamespaceHint("tenant", NewNamespaceHint(STORAGE_IN_MEMORY))
+		policy := NewPolicy()
+		policy.Timeout = time.Second
+		client.SetDefaultPolicy("tenant", "orders", policy)
+
+		Expect(client.getUsablePolicyFor(nil, "tenant", "orders").Timeout).To(Equal(time.Second))
+		Expect(client.getUsablePolicyFor(nil, "tenant", "users").Timeout).To(Equal(50 * time.Millisecond))
+
+		client.SetDefaultPolicy("tenant", "orders", nil)
+		Expect(client.getUsablePolicyFor(nil, "tenant", "orders").Timeout).To(Equal(50 * time.Millisecond))
+	})
+
+	It("should resolve scan and query policies", func() {
+		scanPolicy := NewScanPolicy()
+		scanPolicy.ScanPercent = 10
+		client.SetDefaultScanPolicy("tenant", "", scanPolicy)
+
+		Expect(client.getUsableScanPolicyFor(nil, "tenant", "orders").ScanPercent).To(Equal(10))
+		Expect(client.getUsableScanPolicyFor(nil, "other", "")).To(Equal(client.DefaultScanPolicy))
+
+		queryPolicy := NewQueryPolicy()
+		queryPolicy.RecordQueueSize = 10
+		client.SetDefaultQueryPolicy("tenant", "orders", queryPolicy)
+
+		Expect(client.getUsableQueryPolicyFor(nil, NewStatement("tenant", "orders")).RecordQueueSize).To(Equal(10))
+		Expect(client.getUsableQueryPolicyFor(nil, NewStatement("tenant", "users"))).To(Equal(client.DefaultQueryPolicy))
+	})
+
+})
@@ -0,0 +1,74 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike_test
+
+import (
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	. "github.com/THE108/aerospike-client-go"
+	"github.com/THE108/aerospike-client-go/aerotest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Custom dialer", func() {
+
+	var srv *aerotest.Server
+
+	BeforeEach(func() {
+		var err error
+		srv, err = aerotest.NewServer("test")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(srv.Close()).ToNot(HaveOccurred())
+	})
+
+	It("must open all connections through the dial function", func() {
+		var mutex sync.Mutex
+		dialed := map[string]int{}
+
+		// the host name is only known to the "proxy"
+		policy := NewClientPolicy()
+		policy.DialFunc = func(network, address string, timeout time.Duration) (net.Conn, error) {
+			mutex.Lock()
+			dialed[address]++
+			mutex.Unlock()
+			return net.DialTimeout(network, net.JoinHostPort(srv.Host(), strconv.Itoa(srv.Port())), timeout)
+		}
+
+		client, err := NewClientWithPolicy(policy, "aerospike.proxy.invalid", 3000)
+		Expect(err).ToNot(HaveOccurred())
+		defer client.Close()
+
+		key, _ := NewKey("test", "aerotest", 1)
+		Expect(client.Put(nil, key, BinMap{"a": 1})).ToNot(HaveOccurred())
+
+		rec, err := client.Get(nil, key)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rec.Bins).To(Equal(BinMap{"a": 1}))
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		Expect(len(dialed)).To(Equal(1))
+		Expect(dialed["aerospike.proxy.invalid:3000"]).To(BeNumerically(">=", 2))
+	})
+
+})
@@ -0,0 +1,407 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"io"
+	"sync"
+
+	. "github.com/THE108/aerospike-client-go/types"
+	Buffer "github.com/THE108/aerospike-client-go/utils/buffer"
+)
+
+// EncryptionKeyVersionBin is the companion bin which stores the master key
+// version used to encrypt the bins of a record on its last encrypted write.
+// It can be used to find records which need to be re-encrypted after a key rotation.
+const EncryptionKeyVersionBin = "_enc_ver"
+
+const (
+	_ENCRYPTED_BIN_FORMAT = 1
+	_DATA_KEY_SIZE        = 32
+)
+
+// KMS wraps and unwraps data encryption keys with per-set master keys.
+// Implementations must be safe for concurrent use.
+type KMS interface {
+	// WrapKey encrypts the data key with the current master key of the set,
+	// and returns the master key version and the wrapped data key.
+	WrapKey(namespace, setName string, dataKey []byte) (version string, wrapped []byte, err error)
+
+	// UnwrapKey decrypts a data key wrapped with the given master key version.
+	UnwrapKey(namespace, setName, version string, wrapped []byte) ([]byte, error)
+}
+
+// LocalKMS is a KMS keeping the master keys in memory, e.g. after
+// loading them from a secret store.
+type LocalKMS struct {
+	mutex sync.RWMutex
+	sets  map[string]*localKeyRing
+}
+
+type localKeyRing struct {
+	current string
+	keys    map[string]cipher.AEAD
+}
+
+// NewLocalKMS generates a new LocalKMS instance without any keys.
+func NewLocalKMS() *LocalKMS {
+	return &LocalKMS{sets: map[string]*localKeyRing{}}
+}
+
+// AddKey adds a 16, 24 or 32 byte AES master key for the namespace and set.
+// The last key added to a set is used for encryption; previous keys are only
+// used to decrypt existing records.
+func (kms *LocalKMS) AddKey(namespace, setName, version string, masterKey []byte) error {
+	if version == "" || len(version) > 255 {
+		return NewAerospikeError(PARAMETER_ERROR, "Invalid master key version: `"+version+"`")
+	}
+
+	aead, err := newAEAD(masterKey)
+	if err != nil {
+		return err
+	}
+
+	kms.mutex.Lock()
+	defer kms.mutex.Unlock()
+
+	ring := kms.sets[namespace+":"+setName]
+	if ring == nil {
+		ring = &localKeyRing{keys: map[string]cipher.AEAD{}}
+		kms.sets[namespace+":"+setName] = ring
+	}
+	ring.keys[version] = aead
+	ring.current = version
+	return nil
+}
+
+// WrapKey implements the KMS interface.
+func (kms *LocalKMS) WrapKey(namespace, setName string, dataKey []byte) (string, []byte, error) {
+	var version string
+	var aead cipher.AEAD
+
+	kms.mutex.RLock()
+	if ring := kms.sets[namespace+":"+setName]; ring != nil {
+		version, aead = ring.current, ring.keys[ring.current]
+	}
+	kms.mutex.RUnlock()
+
+	if aead == nil {
+		return "", nil, NewAerospikeError(PARAMETER_ERROR, "No master key for set `"+namespace+":"+setName+"`")
+	}
+
+	wrapped, err := seal(aead, dataKey, []byte(namespace+":"+setName+":"+version))
+	return version, wrapped, err
+}
+
+// UnwrapKey implements the KMS interface.
+func (kms *LocalKMS) UnwrapKey(namespace, setName, version string, wrapped []byte) ([]byte, error) {
+	var aead cipher.AEAD
+
+	kms.mutex.RLock()
+	if ring := kms.sets[namespace+":"+setName]; ring != nil {
+		aead = ring.keys[version]
+	}
+	kms.mutex.RUnlock()
+
+	if aead == nil {
+		return nil, NewAerospikeError(PARAMETER_ERROR, "No master key version `"+version+"` for set `"+namespace+":"+setName+"`")
+	}
+	return open(aead, wrapped, []byte(namespace+":"+setName+":"+version))
+}
+
+// BinEncryptor encrypts the configured bins of each set client-side with
+// envelope encryption: each write uses a new random data key, which is
+// wrapped by the KMS with the set's master key and stored with the encrypted bins.
+// Encrypted bins are stored as blobs, and are bound to the record digest and bin name.
+//
+// BinEncryptor is safe for concurrent use.
+type BinEncryptor struct {
+	kms KMS
+
+	mutex sync.RWMutex
+	sets  map[string]map[string]struct{}
+}
+
+// NewBinEncryptor generates a new BinEncryptor instance using the KMS to
+// wrap the data keys.
+func NewBinEncryptor(kms KMS) *BinEncryptor {
+	return &BinEncryptor{kms: kms, sets: map[string]map[string]struct{}{}}
+}
+
+// EncryptBins adds bins to be encrypted in the namespace and set.
+func (be *BinEncryptor) EncryptBins(namespace, setName string, binNames ...string) {
+	be.mutex.Lock()
+	defer be.mutex.Unlock()
+
+	bins := be.sets[namespace+":"+setName]
+	if bins == nil {
+		bins = map[string]struct{}{}
+		be.sets[namespace+":"+setName] = bins
+	}
+	for _, name := range binNames {
+		bins[name] = struct{}{}
+	}
+}
+
+func (be *BinEncryptor) isEncrypted(namespace, setName, binName string) bool {
+	be.mutex.RLock()
+	defer be.mutex.RUnlock()
+
+	_, exists := be.sets[namespace+":"+setName][binName]
+	return exists
+}
+
+// Encrypt returns a copy of the bins with the configured bins encrypted
+// and the key version bin set. If none of the bins are configured to be
+// encrypted, the bins are returned as is.
+func (be *BinEncryptor) Encrypt(key *Key, bins BinMap) (BinMap, error) {
+	var dataKey cipher.AEAD
+	var header []byte
+
+	res := make(BinMap, len(bins)+1)
+	for name, value := range bins {
+		if value == nil || !be.isEncrypted(key.namespace, key.setName, name) {
+			res[name] = value
+			continue
+		}
+
+		if dataKey == nil {
+			var err error
+			var version string
+			if dataKey, version, header, err = be.newDataKey(key); err != nil {
+				return nil, err
+			}
+			res[EncryptionKeyVersionBin] = version
+		}
+
+		encrypted, err := encryptValue(dataKey, header, key, name, NewValue(value))
+		if err != nil {
+			return nil, err
+		}
+		res[name] = encrypted
+	}
+
+	if dataKey == nil {
+		return bins, nil
+	}
+	return res, nil
+}
+
+// Decrypt decrypts the configured bins of the record in place.
+// The key version bin is removed from the record.
+func (be *BinEncryptor) Decrypt(rec *Record) error {
+	if rec == nil || rec.Key == nil {
+		return nil
+	}
+
+	// the data keys used by the bins, by their wrapped form
+	dataKeys := map[string]cipher.AEAD{}
+
+	for name, value := range rec.Bins {
+		if !be.isEncrypted(rec.Key.namespace, rec.Key.setName, name) {
+			continue
+		}
+
+		blob, ok := value.([]byte)
+		if !ok {
+			return NewAerospikeError(PARSE_ERROR, "Encrypted bin `"+name+"` is not a blob")
+		}
+
+		decrypted, err := be.decryptValue(dataKeys, rec.Key, name, blob)
+		if err != nil {
+			return err
+		}
+		rec.Bins[name] = decrypted
+	}
+
+	delete(rec.Bins, EncryptionKeyVersionBin)
+	return nil
+}
+
+// Results returns the results of the recordset with the records decrypted.
+// Decryption errors are returned as results with errors.
+func (be *BinEncryptor) Results(recordset *Recordset) <-chan *Result {
+	results := recordset.Results()
+	res := make(chan *Result, cap(results))
+
+	go func() {
+		defer close(res)
+		for r := range results {
+			if r.Err == nil {
+				if err := be.Decrypt(r.Record); err != nil {
+					r = &Result{Err: err}
+				}
+			}
+			res <- r
+		}
+	}()
+
+	return res
+}
+
+// newDataKey generates a random data key, and returns it with the header
+// of the encrypted bins: version length (1), version, wrapped key length (2), wrapped key.
+func (be *BinEncryptor) newDataKey(key *Key) (cipher.AEAD, string, []byte, error) {
+	dataKey := make([]byte, _DATA_KEY_SIZE)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, "", nil, err
+	}
+
+	version, wrapped, err := be.kms.WrapKey(key.namespace, key.setName, dataKey)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	if version == "" || len(version) > 255 || len(wrapped) > 0xFFFF {
+		return nil, "", nil, NewAerospikeError(PARAMETER_ERROR, "Invalid wrapped data key returned by the KMS")
+	}
+
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	header := make([]byte, 0, 4+len(version)+len(wrapped))
+	header = append(header, _ENCRYPTED_BIN_FORMAT, byte(len(version)))
+	header = append(header, version...)
+	header = append(header, byte(len(wrapped)>>8), byte(len(wrapped)))
+	header = append(header, wrapped...)
+
+	return aead, version, header, nil
+}
+
+func encryptValue(dataKey cipher.AEAD, header []byte, key *Key, binName string, value Value) ([]byte, error) {
+	// plain text is the particle type followed by the value in wire protocol format
+	plain := make([]byte, 1+value.estimateSize())
+	plain[0] = byte(value.GetType())
+	if _, err := value.write(plain, 1); err != nil {
+		return nil, err
+	}
+
+	sealed, err := seal(dataKey, plain, encryptionAAD(key, binName))
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]byte, 0, len(header)+len(sealed))
+	res = append(res, header...)
+	return append(res, sealed...), nil
+}
+
+func (be *BinEncryptor) decryptValue(dataKeys map[string]cipher.AEAD, key *Key, binName string, blob []byte) (interface{}, error) {
+	invalid := NewAerospikeError(PARSE_ERROR, "Invalid encrypted bin `"+binName+"`")
+
+	if len(blob) < 2 || blob[0] != _ENCRYPTED_BIN_FORMAT {
+		return nil, invalid
+	}
+
+	offset := 2 + int(blob[1])
+	if offset+2 > len(blob) {
+		return nil, invalid
+	}
+	version := string(blob[2:offset])
+
+	end := offset + 2 + int(Buffer.BytesToUint16(blob, offset))
+	if end > len(blob) {
+		return nil, invalid
+	}
+	wrapped := blob[offset+2 : end]
+
+	dataKey := dataKeys[string(wrapped)]
+	if dataKey == nil {
+		plainKey, err := be.kms.UnwrapKey(key.namespace, key.setName, version, wrapped)
+		if err != nil {
+			return nil, err
+		}
+		if dataKey, err = newAEAD(plainKey); err != nil {
+			return nil, err
+		}
+		dataKeys[string(wrapped)] = dataKey
+	}
+
+	plain, err := open(dataKey, blob[end:], encryptionAAD(key, binName))
+	if err != nil {
+		return nil, err
+	}
+	if len(plain) < 1 {
+		return nil, invalid
+	}
+
+	return bytesToParticle(int(plain[0]), plain, 1, len(plain)-1)
+}
+
+// encryptionAAD binds encrypted values to their record and bin.
+func encryptionAAD(key *Key, binName string) []byte {
+	res := make([]byte, 0, len(key.digest)+len(binName))
+	res = append(res, key.digest...)
+	return append(res, binName...)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, NewAerospikeError(PARAMETER_ERROR, "Invalid encryption key: "+err.Error())
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts the plain text and prepends the random nonce.
+func seal(aead cipher.AEAD, plain, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plain)+aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plain, additionalData), nil
+}
+
+func open(aead cipher.AEAD, sealed, additionalData []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, NewAerospikeError(PARSE_ERROR, "Encrypted value is too short")
+	}
+
+	nonce := sealed[:aead.NonceSize()]
+	plain, err := aead.Open(nil, nonce, sealed[aead.NonceSize():], additionalData)
+	if err != nil {
+		return nil, NewAerospikeError(PARSE_ERROR, "Failed to decrypt value: "+err.Error())
+	}
+	return plain, nil
+}
+
+// PutEncrypted writes the bins, encrypting the bins configured in the encryptor.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *Client) PutEncrypted(policy *WritePolicy, encryptor *BinEncryptor, key *Key, binMap BinMap) error {
+	bins, err := encryptor.Encrypt(key, binMap)
+	if err != nil {
+		return err
+	}
+	return clnt.Put(policy, key, bins)
+}
+
+// GetDecrypted reads the record for the key, decrypting the bins configured
+// in the encryptor. If no bin names are passed, all bins are read.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *Client) GetDecrypted(policy *BasePolicy, encryptor *BinEncryptor, key *Key, binNames ...string) (*Record, error) {
+	rec, err := clnt.Get(policy, key, binNames...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := encryptor.Decrypt(rec); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
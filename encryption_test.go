@@ -0,0 +1,116 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Encryption Test", func() {
+
+	var kms *LocalKMS
+	var encryptor *BinEncryptor
+	var key *Key
+
+	BeforeEach(func() {
+		kms = NewLocalKMS()
+		Expect(kms.AddKey("test", "pii", "v1", bytes.Repeat([]byte{1}, 32))).ToNot(HaveOccurred())
+
+		encryptor = NewBinEncryptor(kms)
+		encryptor.EncryptBins("test", "pii", "ssn", "age", "tags")
+
+		key, _ = NewKey("test", "pii", "user1")
+	})
+
+	It("should encrypt configured bins and decrypt them back", func() {
+		bins := BinMap{"ssn": "123-45-6789", "age": 42, "tags": []interface{}{"a", 1}, "name": "John"}
+
+		encrypted, err := encryptor.Encrypt(key, bins)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(encrypted["name"]).To(Equal("John"))
+		Expect(encrypted[EncryptionKeyVersionBin]).To(Equal("v1"))
+		Expect(encrypted["ssn"]).To(BeAssignableToTypeOf([]byte{}))
+		Expect(bytes.Contains(encrypted["ssn"].([]byte), []byte("123-45-6789"))).To(BeFalse())
+
+		rec := newRecord(nil, key, encrypted, 1, 0)
+		Expect(encryptor.Decrypt(rec)).ToNot(HaveOccurred())
+		Expect(rec.Bins).To(Equal(BinMap{"ssn": "123-45-6789", "age": 42, "tags": []interface{}{"a", 1}, "name": "John"}))
+	})
+
+	It("should leave bins of other sets alone", func() {
+		otherKey, _ := NewKey("test", "other", "user1")
+		bins := BinMap{"ssn": "123-45-6789"}
+
+		encrypted, err := encryptor.Encrypt(otherKey, bins)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(encrypted).To(Equal(bins))
+	})
+
+	It("should decrypt records written with rotated keys", func() {
+		encrypted, err := encryptor.Encrypt(key, BinMap{"ssn": "old"})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(kms.AddKey("test", "pii", "v2", bytes.Repeat([]byte{2}, 16))).ToNot(HaveOccurred())
+		rotated, err := encryptor.Encrypt(key, BinMap{"age": 43})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rotated[EncryptionKeyVersionBin]).To(Equal("v2"))
+
+		rec := newRecord(nil, key, BinMap{"ssn": encrypted["ssn"], "age": rotated["age"]}, 2, 0)
+		Expect(encryptor.Decrypt(rec)).ToNot(HaveOccurred())
+		Expect(rec.Bins).To(Equal(BinMap{"ssn": "old", "age": 43}))
+	})
+
+	It("should reject values moved to other bins or records", func() {
+		encrypted, err := encryptor.Encrypt(key, BinMap{"ssn": "123-45-6789"})
+		Expect(err).ToNot(HaveOccurred())
+
+		rec := newRecord(nil, key, BinMap{"age": encrypted["ssn"]}, 1, 0)
+		Expect(encryptor.Decrypt(rec)).To(HaveOccurred())
+
+		otherKey, _ := NewKey("test", "pii", "user2")
+		rec = newRecord(nil, otherKey, BinMap{"ssn": encrypted["ssn"]}, 1, 0)
+		Expect(encryptor.Decrypt(rec)).To(HaveOccurred())
+
+		rec = newRecord(nil, key, BinMap{"ssn": "plain text"}, 1, 0)
+		Expect(encryptor.Decrypt(rec)).To(HaveOccurred())
+	})
+
+	It("should fail without a master key", func() {
+		encryptor.EncryptBins("test", "nokey", "ssn")
+		noKey, _ := NewKey("test", "nokey", "user1")
+
+		_, err := encryptor.Encrypt(noKey, BinMap{"ssn": "x"})
+		Expect(err).To(HaveOccurred())
+		Expect(kms.AddKey("test", "pii", "v3", []byte("short"))).To(HaveOccurred())
+	})
+
+	It("should decrypt recordset results", func() {
+		encrypted, err := encryptor.Encrypt(key, BinMap{"ssn": "123-45-6789"})
+		Expect(err).ToNot(HaveOccurred())
+
+		rs := NewRecordsetFromResults(&Result{Record: newRecord(nil, key, encrypted, 1, 0)})
+		var recs []*Record
+		for res := range encryptor.Results(rs) {
+			Expect(res.Err).ToNot(HaveOccurred())
+			recs = append(recs, res.Record)
+		}
+		Expect(len(recs)).To(Equal(1))
+		Expect(recs[0].Bins).To(Equal(BinMap{"ssn": "123-45-6789"}))
+	})
+
+})
@@ -0,0 +1,103 @@
+/*
+ * Copyright 2012-2014 Aerospike, Inc.
+ *
+ * Portions may be licensed to Aerospike, Inc. under one or more contributor
+ * license agreements.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not
+ * use this file except in compliance with the License. You may obtain a copy of
+ * the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations under
+ * the License.
+ */
+
+// Package documentstore is an example of a document store on top of Aerospike.
+//
+// Documents are Go structs persisted with PutObject and GetObject; each
+// exported field is stored in a bin. Concurrent updates are made safe with
+// ReadModifyWrite, which retries an update if the document was changed
+// after it was read.
+package documentstore
+
+import (
+	"errors"
+
+	as "github.com/THE108/aerospike-client-go"
+	. "github.com/THE108/aerospike-client-go/types"
+)
+
+// ErrNotFound is returned for documents which do not exist.
+var ErrNotFound = errors.New("document not found")
+
+// Store keeps documents in a set.
+type Store struct {
+	client    *as.Client
+	namespace string
+	set       string
+}
+
+// New returns a document store for the set.
+func New(client *as.Client, namespace, set string) *Store {
+	return &Store{client: client, namespace: namespace, set: set}
+}
+
+// Save stores the document, replacing the previous version.
+// doc must be a pointer to a struct.
+func (s *Store) Save(id string, doc interface{}) error {
+	key, err := as.NewKey(s.namespace, s.set, id)
+	if err != nil {
+		return err
+	}
+
+	policy := as.NewWritePolicy(0, 0)
+	policy.RecordExistsAction = as.REPLACE
+	return s.client.PutObject(policy, key, doc)
+}
+
+// Load reads the document into doc, which must be a pointer to a struct.
+func (s *Store) Load(id string, doc interface{}) error {
+	key, err := as.NewKey(s.namespace, s.set, id)
+	if err != nil {
+		return err
+	}
+
+	err = s.client.GetObject(nil, key, doc)
+	if ae, ok := err.(AerospikeError); ok && ae.ResultCode() == KEY_NOT_FOUND_ERROR {
+		return ErrNotFound
+	}
+	return err
+}
+
+// Update passes the fields of the document to modify and writes back the
+// fields it returns. If the document is changed concurrently, modify is
+// called again with the new version.
+func (s *Store) Update(id string, modify func(fields as.BinMap) (as.BinMap, error)) error {
+	key, err := as.NewKey(s.namespace, s.set, id)
+	if err != nil {
+		return err
+	}
+
+	policy := as.NewRMWPolicy()
+	policy.MaxAttempts = 100
+	return s.client.ReadModifyWrite(policy, key, func(rec *as.Record) (as.BinMap, error) {
+		if rec == nil {
+			return nil, ErrNotFound
+		}
+		return modify(rec.Bins)
+	})
+}
+
+// Delete removes the document. Deleting a document which does not exist is not an error.
+func (s *Store) Delete(id string) error {
+	key, err := as.NewKey(s.namespace, s.set, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.Delete(nil, key)
+	return err
+}
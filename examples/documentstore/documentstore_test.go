@@ -0,0 +1,100 @@
+/*
+ * Copyright 2012-2014 Aerospike, Inc.
+ *
+ * Portions may be licensed to Aerospike, Inc. under one or more contributor
+ * license agreements.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not
+ * use this file except in compliance with the License. You may obtain a copy of
+ * the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations under
+ * the License.
+ */
+
+package documentstore_test
+
+import (
+	"sync"
+
+	as "github.com/THE108/aerospike-client-go"
+	"github.com/THE108/aerospike-client-go/aerotest"
+	"github.com/THE108/aerospike-client-go/examples/documentstore"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type article struct {
+	Title  string
+	Author string `as:"author"`
+	Views  int    `as:"views"`
+}
+
+var _ = Describe("Document Store", func() {
+
+	var srv *aerotest.Server
+	var client *as.Client
+	var store *documentstore.Store
+
+	BeforeEach(func() {
+		var err error
+		srv, err = aerotest.NewServer("test")
+		Expect(err).ToNot(HaveOccurred())
+
+		client, err = as.NewClient(srv.Host(), srv.Port())
+		Expect(err).ToNot(HaveOccurred())
+
+		store = documentstore.New(client, "test", "articles")
+	})
+
+	AfterEach(func() {
+		client.Close()
+		Expect(srv.Close()).ToNot(HaveOccurred())
+	})
+
+	It("must save and load documents", func() {
+		doc := &article{Title: "Hello", Author: "jdoe"}
+		Expect(store.Save("a1", doc)).ToNot(HaveOccurred())
+
+		var loaded article
+		Expect(store.Load("a1", &loaded)).ToNot(HaveOccurred())
+		Expect(loaded).To(Equal(*doc))
+
+		Expect(store.Load("a2", &loaded)).To(Equal(documentstore.ErrNotFound))
+	})
+
+	It("must not lose concurrent updates", func() {
+		Expect(store.Save("a1", &article{Title: "Hello"})).ToNot(HaveOccurred())
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+
+				err := store.Update("a1", func(fields as.BinMap) (as.BinMap, error) {
+					return as.BinMap{"views": fields["views"].(int) + 1}, nil
+				})
+				Expect(err).ToNot(HaveOccurred())
+			}()
+		}
+		wg.Wait()
+
+		var loaded article
+		Expect(store.Load("a1", &loaded)).ToNot(HaveOccurred())
+		Expect(loaded).To(Equal(article{Title: "Hello", Views: 10}))
+	})
+
+	It("must not update missing documents", func() {
+		err := store.Update("a1", func(fields as.BinMap) (as.BinMap, error) {
+			return fields, nil
+		})
+		Expect(err).To(Equal(documentstore.ErrNotFound))
+	})
+
+})
@@ -0,0 +1,126 @@
+/*
+ * Copyright 2012-2014 Aerospike, Inc.
+ *
+ * Portions may be licensed to Aerospike, Inc. under one or more contributor
+ * license agreements.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not
+ * use this file except in compliance with the License. You may obtain a copy of
+ * the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations under
+ * the License.
+ */
+
+// Package leaderboard is an example of game leaderboards on top of Aerospike.
+//
+// Each leaderboard is a record holding the scores of its players in a map
+// bin. Scores are incremented on the server with MapIncrement, so any
+// number of game servers can submit scores concurrently.
+// Ranking is done on the client, which suits boards of up to a few
+// thousand players.
+package leaderboard
+
+import (
+	"sort"
+
+	as "github.com/THE108/aerospike-client-go"
+)
+
+const scoresBin = "scores"
+
+// Entry is the score of a player.
+type Entry struct {
+	Player string
+	Score  int
+}
+
+// Board is a leaderboard.
+type Board struct {
+	client *as.Client
+	key    *as.Key
+}
+
+// New returns the leaderboard with the given name.
+func New(client *as.Client, namespace, set, name string) (*Board, error) {
+	key, err := as.NewKey(namespace, set, name)
+	if err != nil {
+		return nil, err
+	}
+	return &Board{client: client, key: key}, nil
+}
+
+// AddScore adds points to the score of the player and returns the new score.
+// Players not on the board yet start with a score of 0.
+func (b *Board) AddScore(player string, points int) (int, error) {
+	return b.client.MapIncrement(nil, b.key, scoresBin, player, points)
+}
+
+// Top returns the n players with the highest scores, highest first.
+// Players with the same score are ordered by name.
+func (b *Board) Top(n int) ([]Entry, error) {
+	entries, err := b.entries()
+	if err != nil {
+		return nil, err
+	}
+
+	if n < len(entries) {
+		entries = entries[:n]
+	}
+	return entries, nil
+}
+
+// Rank returns the 1-based rank of the player and their score.
+// The rank is 0 if the player is not on the board.
+func (b *Board) Rank(player string) (int, int, error) {
+	entries, err := b.entries()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for i, e := range entries {
+		if e.Player == player {
+			return i + 1, e.Score, nil
+		}
+	}
+	return 0, 0, nil
+}
+
+// Reset removes all scores from the board.
+func (b *Board) Reset() error {
+	_, err := b.client.Delete(nil, b.key)
+	return err
+}
+
+// entries returns the scores of the board in ranking order.
+func (b *Board) entries() ([]Entry, error) {
+	rec, err := b.client.Get(nil, b.key, scoresBin)
+	if err != nil || rec == nil {
+		return nil, err
+	}
+
+	scores, _ := rec.Bins[scoresBin].(map[interface{}]interface{})
+	entries := make([]Entry, 0, len(scores))
+	for player, score := range scores {
+		p, _ := player.(string)
+		s, _ := score.(int)
+		entries = append(entries, Entry{Player: p, Score: s})
+	}
+
+	sort.Sort(byRank(entries))
+	return entries, nil
+}
+
+type byRank []Entry
+
+func (r byRank) Len() int      { return len(r) }
+func (r byRank) Swap(i, j int) { r[i], r[j] = r[j], r[i] }
+func (r byRank) Less(i, j int) bool {
+	if r[i].Score != r[j].Score {
+		return r[i].Score > r[j].Score
+	}
+	return r[i].Player < r[j].Player
+}
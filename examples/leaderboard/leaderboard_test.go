@@ -0,0 +1,101 @@
+/*
+ * Copyright 2012-2014 Aerospike, Inc.
+ *
+ * Portions may be licensed to Aerospike, Inc. under one or more contributor
+ * license agreements.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not
+ * use this file except in compliance with the License. You may obtain a copy of
+ * the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations under
+ * the License.
+ */
+
+package leaderboard_test
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"sync"
+
+	as "github.com/THE108/aerospike-client-go"
+	"github.com/THE108/aerospike-client-go/examples/leaderboard"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var host = flag.String("h", "127.0.0.1", "Aerospike server seed hostnames or IP addresses")
+var port = flag.Int("p", 3000, "Aerospike server seed hostname or IP address port number.")
+var namespace = flag.String("n", "test", "Aerospike namespace.")
+
+var _ = Describe("Leaderboard", func() {
+
+	var client *as.Client
+	var board *leaderboard.Board
+
+	BeforeEach(func() {
+		var err error
+		client, err = as.NewClient(*host, *port)
+		Expect(err).ToNot(HaveOccurred())
+
+		board, err = leaderboard.New(client, *namespace, "leaderboard", fmt.Sprintf("board-%d", rand.Int63()))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(board.Reset()).ToNot(HaveOccurred())
+		client.Close()
+	})
+
+	It("must rank players by score", func() {
+		for player, score := range map[string]int{"alice": 30, "bob": 10, "carol": 20, "dave": 20} {
+			_, err := board.AddScore(player, score)
+			Expect(err).ToNot(HaveOccurred())
+		}
+
+		top, err := board.Top(3)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(top).To(Equal([]leaderboard.Entry{{Player: "alice", Score: 30}, {Player: "carol", Score: 20}, {Player: "dave", Score: 20}}))
+
+		rank, score, err := board.Rank("bob")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rank).To(Equal(4))
+		Expect(score).To(Equal(10))
+
+		rank, _, err = board.Rank("eve")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rank).To(Equal(0))
+	})
+
+	It("must add concurrent scores", func() {
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+
+				_, err := board.AddScore("alice", 5)
+				Expect(err).ToNot(HaveOccurred())
+			}()
+		}
+		wg.Wait()
+
+		_, score, err := board.Rank("alice")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(score).To(Equal(100))
+	})
+
+	It("must return an empty board", func() {
+		top, err := board.Top(10)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(top).To(BeEmpty())
+	})
+
+})
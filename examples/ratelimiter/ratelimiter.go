@@ -0,0 +1,85 @@
+/*
+ * Copyright 2012-2014 Aerospike, Inc.
+ *
+ * Portions may be licensed to Aerospike, Inc. under one or more contributor
+ * license agreements.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not
+ * use this file except in compliance with the License. You may obtain a copy of
+ * the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations under
+ * the License.
+ */
+
+// Package ratelimiter is an example of a distributed rate limiter on top of
+// Aerospike, shared by all instances of a service.
+//
+// The events of each caller are counted by a RateCounter in the caller's
+// record. Counting and trimming the sliding window happens on the server
+// in a single command, so instances never need to coordinate.
+package ratelimiter
+
+import (
+	"time"
+
+	as "github.com/THE108/aerospike-client-go"
+)
+
+// Limiter allows up to a number of events per caller in a sliding window.
+type Limiter struct {
+	client    *as.Client
+	namespace string
+	set       string
+	limit     int
+	window    time.Duration
+	policy    *as.WritePolicy
+}
+
+// New returns a limiter which allows limit events per caller in window.
+// Records of callers which stay idle for a window expire on the server.
+func New(client *as.Client, namespace, set string, limit int, window time.Duration) *Limiter {
+	// keep the record at least for the window
+	ttl := int32((window + time.Second - 1) / time.Second)
+
+	return &Limiter{
+		client:    client,
+		namespace: namespace,
+		set:       set,
+		limit:     limit,
+		window:    window,
+		policy:    as.NewWritePolicy(0, ttl),
+	}
+}
+
+// Allow records an event for the caller and reports whether it is within
+// the limit, along with the number of events of the caller in the window.
+func (l *Limiter) Allow(caller string) (bool, int, error) {
+	key, err := as.NewKey(l.namespace, l.set, caller)
+	if err != nil {
+		return false, 0, err
+	}
+
+	rc, err := l.client.GetRateCounter(l.policy, key, "events", l.window, 10)
+	if err != nil {
+		return false, 0, err
+	}
+	return rc.Allow(l.limit)
+}
+
+// Count returns the number of events of the caller in the window.
+func (l *Limiter) Count(caller string) (int, error) {
+	key, err := as.NewKey(l.namespace, l.set, caller)
+	if err != nil {
+		return 0, err
+	}
+
+	rc, err := l.client.GetRateCounter(l.policy, key, "events", l.window, 10)
+	if err != nil {
+		return 0, err
+	}
+	return rc.Count()
+}
@@ -0,0 +1,98 @@
+/*
+ * Copyright 2012-2014 Aerospike, Inc.
+ *
+ * Portions may be licensed to Aerospike, Inc. under one or more contributor
+ * license agreements.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not
+ * use this file except in compliance with the License. You may obtain a copy of
+ * the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations under
+ * the License.
+ */
+
+package ratelimiter_test
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"time"
+
+	as "github.com/THE108/aerospike-client-go"
+	"github.com/THE108/aerospike-client-go/examples/ratelimiter"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var host = flag.String("h", "127.0.0.1", "Aerospike server seed hostnames or IP addresses")
+var port = flag.Int("p", 3000, "Aerospike server seed hostname or IP address port number.")
+var namespace = flag.String("n", "test", "Aerospike namespace.")
+
+var _ = Describe("Rate Limiter", func() {
+
+	var client *as.Client
+	var caller string
+
+	BeforeEach(func() {
+		var err error
+		client, err = as.NewClient(*host, *port)
+		Expect(err).ToNot(HaveOccurred())
+
+		caller = fmt.Sprintf("caller-%d", rand.Int63())
+	})
+
+	AfterEach(func() {
+		client.Close()
+	})
+
+	It("must reject events over the limit", func() {
+		limiter := ratelimiter.New(client, *namespace, "ratelimiter", 3, time.Minute)
+
+		for i := 1; i <= 5; i++ {
+			allowed, count, err := limiter.Allow(caller)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(count).To(Equal(i))
+			Expect(allowed).To(Equal(i <= 3))
+		}
+
+		count, err := limiter.Count(caller)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(count).To(Equal(5))
+	})
+
+	It("must count callers separately", func() {
+		limiter := ratelimiter.New(client, *namespace, "ratelimiter", 1, time.Minute)
+
+		allowed, _, err := limiter.Allow(caller)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(allowed).To(BeTrue())
+
+		allowed, _, err = limiter.Allow(caller + "-other")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(allowed).To(BeTrue())
+	})
+
+	It("must allow events again after the window", func() {
+		limiter := ratelimiter.New(client, *namespace, "ratelimiter", 1, time.Second)
+
+		allowed, _, err := limiter.Allow(caller)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(allowed).To(BeTrue())
+
+		allowed, _, err = limiter.Allow(caller)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(allowed).To(BeFalse())
+
+		time.Sleep(1100 * time.Millisecond)
+		allowed, _, err = limiter.Allow(caller)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(allowed).To(BeTrue())
+	})
+
+})
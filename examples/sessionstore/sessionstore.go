@@ -0,0 +1,127 @@
+/*
+ * Copyright 2012-2014 Aerospike, Inc.
+ *
+ * Portions may be licensed to Aerospike, Inc. under one or more contributor
+ * license agreements.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not
+ * use this file except in compliance with the License. You may obtain a copy of
+ * the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations under
+ * the License.
+ */
+
+// Package sessionstore is an example of a web session store with sliding
+// expiration on top of Aerospike.
+//
+// Each session is a record whose TTL is the idle timeout of the session.
+// Reading a session touches the record in the same command, so active
+// sessions stay alive and idle ones are expired by the server.
+package sessionstore
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	as "github.com/THE108/aerospike-client-go"
+	. "github.com/THE108/aerospike-client-go/types"
+)
+
+// ErrNotFound is returned for sessions which do not exist or have expired.
+var ErrNotFound = errors.New("session not found")
+
+// Store keeps sessions in a set.
+type Store struct {
+	client    *as.Client
+	namespace string
+	set       string
+	policy    *as.WritePolicy
+}
+
+// New returns a session store which expires sessions after they have not
+// been read for idleTimeout. The timeout is rounded down to seconds.
+func New(client *as.Client, namespace, set string, idleTimeout time.Duration) *Store {
+	return &Store{
+		client:    client,
+		namespace: namespace,
+		set:       set,
+		policy:    as.NewWritePolicy(0, int32(idleTimeout/time.Second)),
+	}
+}
+
+// Create stores a new session with the given data and returns its id.
+func (s *Store) Create(data as.BinMap) (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	id := hex.EncodeToString(buf)
+
+	key, err := as.NewKey(s.namespace, s.set, id)
+	if err != nil {
+		return "", err
+	}
+
+	// never overwrite another session on an id collision
+	policy := *s.policy
+	policy.RecordExistsAction = as.CREATE_ONLY
+	if err := s.client.Put(&policy, key, data); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// Get returns the data of the session and resets its idle timeout.
+func (s *Store) Get(id string) (as.BinMap, error) {
+	key, err := as.NewKey(s.namespace, s.set, id)
+	if err != nil {
+		return nil, err
+	}
+
+	rec, err := s.client.Operate(s.policy, key, as.TouchOp(), as.GetOp())
+	if isNotFound(err) || (err == nil && rec == nil) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return rec.Bins, nil
+}
+
+// Update sets the given bins of the session and resets its idle timeout.
+// Bins not in data are kept.
+func (s *Store) Update(id string, data as.BinMap) error {
+	key, err := as.NewKey(s.namespace, s.set, id)
+	if err != nil {
+		return err
+	}
+
+	policy := *s.policy
+	policy.RecordExistsAction = as.UPDATE_ONLY
+	err = s.client.Put(&policy, key, data)
+	if isNotFound(err) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// Delete ends the session. Deleting a session which does not exist is not an error.
+func (s *Store) Delete(id string) error {
+	key, err := as.NewKey(s.namespace, s.set, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.Delete(nil, key)
+	return err
+}
+
+func isNotFound(err error) bool {
+	ae, ok := err.(AerospikeError)
+	return ok && ae.ResultCode() == KEY_NOT_FOUND_ERROR
+}
@@ -0,0 +1,96 @@
+/*
+ * Copyright 2012-2014 Aerospike, Inc.
+ *
+ * Portions may be licensed to Aerospike, Inc. under one or more contributor
+ * license agreements.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not
+ * use this file except in compliance with the License. You may obtain a copy of
+ * the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations under
+ * the License.
+ */
+
+package sessionstore_test
+
+import (
+	"time"
+
+	as "github.com/THE108/aerospike-client-go"
+	"github.com/THE108/aerospike-client-go/aerotest"
+	"github.com/THE108/aerospike-client-go/examples/sessionstore"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Session Store", func() {
+
+	var srv *aerotest.Server
+	var client *as.Client
+	var store *sessionstore.Store
+
+	BeforeEach(func() {
+		var err error
+		srv, err = aerotest.NewServer("test")
+		Expect(err).ToNot(HaveOccurred())
+
+		client, err = as.NewClient(srv.Host(), srv.Port())
+		Expect(err).ToNot(HaveOccurred())
+
+		store = sessionstore.New(client, "test", "sessions", time.Hour)
+	})
+
+	AfterEach(func() {
+		client.Close()
+		Expect(srv.Close()).ToNot(HaveOccurred())
+	})
+
+	It("must create, read and update sessions", func() {
+		id, err := store.Create(as.BinMap{"user": "jdoe", "visits": 1})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(len(id)).To(Equal(32))
+
+		data, err := store.Get(id)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(Equal(as.BinMap{"user": "jdoe", "visits": 1}))
+
+		Expect(store.Update(id, as.BinMap{"visits": 2})).ToNot(HaveOccurred())
+		data, err = store.Get(id)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(Equal(as.BinMap{"user": "jdoe", "visits": 2}))
+	})
+
+	It("must expire sessions after the idle timeout", func() {
+		id, err := store.Create(as.BinMap{"user": "jdoe"})
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = store.Get(id)
+		Expect(err).ToNot(HaveOccurred())
+
+		key, _ := as.NewKey("test", "sessions", id)
+		rec, err := client.GetHeader(nil, key)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rec.Expiration).To(BeNumerically(">", 3590))
+		Expect(rec.Expiration).To(BeNumerically("<=", 3600))
+	})
+
+	It("must report unknown and deleted sessions as not found", func() {
+		_, err := store.Get("unknown")
+		Expect(err).To(Equal(sessionstore.ErrNotFound))
+		Expect(store.Update("unknown", as.BinMap{"visits": 1})).To(Equal(sessionstore.ErrNotFound))
+
+		id, err := store.Create(as.BinMap{"user": "jdoe"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(store.Delete(id)).ToNot(HaveOccurred())
+
+		_, err = store.Get(id)
+		Expect(err).To(Equal(sessionstore.ErrNotFound))
+		Expect(store.Delete(id)).ToNot(HaveOccurred())
+	})
+
+})
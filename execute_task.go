@@ -15,6 +15,7 @@
 package aerospike
 
 import (
+	"context"
 	"strconv"
 	"strings"
 
@@ -98,6 +99,53 @@ func (etsk *ExecuteTask) IsDone() (bool, error) {
 	return done, nil
 }
 
+// RecordsRead queries all nodes and returns the total number of records
+// processed by the job so far.
+func (etsk *ExecuteTask) RecordsRead() (int64, error) {
+	var command string
+	if etsk.scan {
+		command = "scan-list"
+	} else {
+		command = "query-list"
+	}
+
+	var total int64
+	for _, node := range etsk.cluster.GetNodes() {
+		responseMap, err := RequestNodeInfo(node, command)
+		if err != nil {
+			return 0, err
+		}
+
+		total += parseJobRecordsRead(responseMap[command], etsk.taskId)
+	}
+
+	return total, nil
+}
+
+// parseJobRecordsRead returns the recs_read value of a job in the
+// response of a `scan-list` or `query-list` info command.
+func parseJobRecordsRead(response string, taskId uint64) int64 {
+	find := "job_id=" + strconv.FormatUint(taskId, 10) + ":"
+	index := strings.Index(response, find)
+	if index < 0 {
+		return 0
+	}
+
+	// jobs are separated by ';', and their fields by ':'
+	job := response[index+len(find):]
+	if end := strings.Index(job, ";"); end >= 0 {
+		job = job[:end]
+	}
+
+	for _, field := range strings.Split(job, ":") {
+		if strings.HasPrefix(field, "recs_read=") {
+			n, _ := strconv.ParseInt(strings.TrimPrefix(field, "recs_read="), 10, 64)
+			return n
+		}
+	}
+	return 0
+}
+
 // OnComplete returns a channel which will be closed when the task is
 // completed.
 // If an error is encountered while performing the task, an error
@@ -105,3 +153,9 @@ func (etsk *ExecuteTask) IsDone() (bool, error) {
 func (etsk *ExecuteTask) OnComplete() chan error {
 	return etsk.onComplete(etsk)
 }
+
+// WaitUntilDone blocks until the task is finished, an error is encountered,
+// or the context is cancelled.
+func (etsk *ExecuteTask) WaitUntilDone(ctx context.Context) error {
+	return etsk.waitUntilDone(ctx, etsk)
+}
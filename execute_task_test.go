@@ -0,0 +1,33 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ExecuteTask Test", func() {
+
+	It("should parse records read by a job", func() {
+		response := "job_id=11:job_status=DONE:recs_read=10;" +
+			"job_id=12:job_status=IN PROGRESS:udf_active=1:recs_read=250:net_io_bytes=34"
+
+		Expect(parseJobRecordsRead(response, 12)).To(Equal(int64(250)))
+		Expect(parseJobRecordsRead(response, 11)).To(Equal(int64(10)))
+		Expect(parseJobRecordsRead(response, 1)).To(Equal(int64(0)))
+	})
+
+})
@@ -0,0 +1,281 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	. "github.com/THE108/aerospike-client-go/logger"
+	. "github.com/THE108/aerospike-client-go/types"
+	. "github.com/THE108/aerospike-client-go/types/atomic"
+)
+
+// FailoverPolicy determines how a FailoverClient moves commands between
+// its primary and secondary cluster.
+type FailoverPolicy struct {
+	// FailoverWrites sends writes to the secondary cluster as well while the
+	// primary is unreachable. Writes which failed on the primary with a
+	// timeout are retried on the secondary, although they may have been
+	// applied on the primary.
+	FailoverWrites bool //= false
+
+	// HealthCheckInterval is the interval the primary cluster is checked on.
+	// Commands fall back to the primary once it is reachable again.
+	HealthCheckInterval time.Duration //= 1 second
+}
+
+// NewFailoverPolicy generates a new FailoverPolicy with default values.
+func NewFailoverPolicy() *FailoverPolicy {
+	return &FailoverPolicy{
+		HealthCheckInterval: time.Second,
+	}
+}
+
+// FailoverClient sends commands to a primary cluster, and fails them over
+// to a secondary cluster, e.g. in a passive data center, while the primary
+// is unreachable. Only errors showing the primary could not be reached, like
+// network errors and timeouts, cause a failover; errors returned by the
+// primary's nodes, like KEY_NOT_FOUND_ERROR, are returned as is.
+// The primary cluster is health checked in the background, and commands fall
+// back to it as soon as it is reachable again.
+// Records are not replicated between the clusters by the client.
+// FailoverClient is safe for concurrent use.
+type FailoverClient struct {
+	primary   *Client
+	secondary *Client
+	policy    FailoverPolicy
+
+	failedOver AtomicBool
+
+	done      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// NewFailoverClient creates clients for the primary and the secondary cluster
+// from their seed hosts, with the same client policy.
+// The client is created while at least one of the clusters is reachable,
+// unless ClientPolicy.FailIfNotConnected is false.
+// If the policy is nil, the default relevant policy will be used.
+func NewFailoverClient(policy *FailoverPolicy, clientPolicy *ClientPolicy, primary, secondary []*Host) (*FailoverClient, error) {
+	if policy == nil {
+		policy = NewFailoverPolicy()
+	}
+	if clientPolicy == nil {
+		clientPolicy = NewClientPolicy()
+	}
+
+	// either cluster may be down when the client is created
+	cp := clientPolicy.Clone()
+	cp.FailIfNotConnected = false
+
+	primaryClient, err := NewClientWithPolicyAndHost(cp, primary...)
+	if err != nil {
+		return nil, err
+	}
+
+	secondaryClient, err := NewClientWithPolicyAndHost(cp, secondary...)
+	if err != nil {
+		primaryClient.Close()
+		return nil, err
+	}
+
+	if clientPolicy.FailIfNotConnected && !primaryClient.IsConnected() && !secondaryClient.IsConnected() {
+		primaryClient.Close()
+		secondaryClient.Close()
+		return nil, fmt.Errorf("Failed to connect to host(s): %v, %v", primary, secondary)
+	}
+
+	fc := &FailoverClient{
+		primary:   primaryClient,
+		secondary: secondaryClient,
+		policy:    *policy,
+		done:      make(chan struct{}),
+	}
+	fc.failedOver.Set(!primaryClient.IsConnected())
+
+	if fc.policy.HealthCheckInterval <= 10*time.Millisecond {
+		fc.policy.HealthCheckInterval = 10 * time.Millisecond
+	}
+
+	fc.wg.Add(1)
+	go fc.checkHealth()
+
+	return fc, nil
+}
+
+// Primary returns the client of the primary cluster.
+func (fc *FailoverClient) Primary() *Client {
+	return fc.primary
+}
+
+// Secondary returns the client of the secondary cluster.
+func (fc *FailoverClient) Secondary() *Client {
+	return fc.secondary
+}
+
+// FailedOver returns true while commands are sent to the secondary cluster.
+func (fc *FailoverClient) FailedOver() bool {
+	return fc.failedOver.Get()
+}
+
+// Close stops the health checks and closes the clients of both clusters.
+func (fc *FailoverClient) Close() {
+	fc.closeOnce.Do(func() {
+		close(fc.done)
+		fc.wg.Wait()
+
+		fc.primary.Close()
+		fc.secondary.Close()
+	})
+}
+
+// Read runs the read fn on the client of the primary cluster, or on the
+// client of the secondary cluster while the primary is unreachable.
+// If fn fails on the primary because it is unreachable, it is run on the
+// secondary as well. fn may be run twice, so it must not keep its results
+// of a failed run.
+func (fc *FailoverClient) Read(fn func(client *Client) error) error {
+	return fc.run(true, fn)
+}
+
+// Write runs the write fn like Read if FailoverPolicy.FailoverWrites is set.
+// Otherwise fn is always run on the client of the primary cluster.
+func (fc *FailoverClient) Write(fn func(client *Client) error) error {
+	return fc.run(fc.policy.FailoverWrites, fn)
+}
+
+func (fc *FailoverClient) run(failover bool, fn func(client *Client) error) error {
+	if !failover || !fc.failedOver.Get() {
+		err := fn(fc.primary)
+		if !failover || !isUnreachableError(err) {
+			return err
+		}
+
+		if fc.failedOver.CompareAndToggle(false) {
+			Logger.Warn("Primary cluster is unreachable, failing over to the secondary cluster: %s", err)
+		}
+	}
+	return fn(fc.secondary)
+}
+
+// checkHealth moves commands between the clusters depending on whether the
+// primary cluster is reachable, until the client is closed.
+func (fc *FailoverClient) checkHealth() {
+	defer fc.wg.Done()
+
+	for {
+		select {
+		case <-fc.done:
+			return
+		case <-time.After(fc.policy.HealthCheckInterval):
+		}
+
+		healthy := fc.primaryHealthy()
+		if healthy && fc.failedOver.Get() {
+			Logger.Info("Primary cluster is reachable again, falling back from the secondary cluster")
+		} else if !healthy && !fc.failedOver.Get() {
+			Logger.Warn("Primary cluster is unreachable, failing over to the secondary cluster")
+		}
+		fc.failedOver.Set(!healthy)
+	}
+}
+
+// primaryHealthy returns true if a node of the primary cluster answers an
+// info request.
+func (fc *FailoverClient) primaryHealthy() bool {
+	node, err := fc.primary.cluster.GetRandomNode()
+	if err != nil {
+		return false
+	}
+
+	_, err = RequestNodeInfo(node, "node")
+	return err == nil
+}
+
+// isUnreachableError returns true if the error shows that a command could
+// not reach the cluster, rather than being rejected by it.
+func isUnreachableError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	ae, ok := err.(AerospikeError)
+	if !ok {
+		// network errors
+		return true
+	}
+
+	switch ae.ResultCode() {
+	case TIMEOUT, INVALID_NODE_ERROR, NO_AVAILABLE_CONNECTIONS_TO_NODE, SERVER_NOT_AVAILABLE:
+		return true
+	}
+	return false
+}
+
+// Get reads the record of the key with failover; see Client.Get.
+func (fc *FailoverClient) Get(policy *BasePolicy, key *Key, binNames ...string) (rec *Record, err error) {
+	err = fc.Read(func(client *Client) (err error) {
+		rec, err = client.Get(policy, key, binNames...)
+		return err
+	})
+	return rec, err
+}
+
+// Exists checks if the record of the key exists with failover; see Client.Exists.
+func (fc *FailoverClient) Exists(policy *BasePolicy, key *Key) (exists bool, err error) {
+	err = fc.Read(func(client *Client) (err error) {
+		exists, err = client.Exists(policy, key)
+		return err
+	})
+	return exists, err
+}
+
+// BatchGet reads the records of the keys with failover; see Client.BatchGet.
+func (fc *FailoverClient) BatchGet(policy *BasePolicy, keys []*Key, binNames ...string) (recs []*Record, err error) {
+	err = fc.Read(func(client *Client) (err error) {
+		recs, err = client.BatchGet(policy, keys, binNames...)
+		return err
+	})
+	return recs, err
+}
+
+// Put writes the bins of the record; see Client.Put and FailoverClient.Write.
+func (fc *FailoverClient) Put(policy *WritePolicy, key *Key, binMap BinMap) error {
+	return fc.Write(func(client *Client) error {
+		return client.Put(policy, key, binMap)
+	})
+}
+
+// Delete deletes the record; see Client.Delete and FailoverClient.Write.
+func (fc *FailoverClient) Delete(policy *WritePolicy, key *Key) (existed bool, err error) {
+	err = fc.Write(func(client *Client) (err error) {
+		existed, err = client.Delete(policy, key)
+		return err
+	})
+	return existed, err
+}
+
+// Operate performs the operations on the record; see Client.Operate and
+// FailoverClient.Write. It is handled as a write, even if all operations are reads.
+func (fc *FailoverClient) Operate(policy *WritePolicy, key *Key, operations ...*Operation) (rec *Record, err error) {
+	err = fc.Write(func(client *Client) (err error) {
+		rec, err = client.Operate(policy, key, operations...)
+		return err
+	})
+	return rec, err
+}
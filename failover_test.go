@@ -0,0 +1,122 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike_test
+
+import (
+	"time"
+
+	. "github.com/THE108/aerospike-client-go"
+	"github.com/THE108/aerospike-client-go/aerotest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Failover client", func() {
+
+	var primary, secondary *aerotest.Server
+	var client *FailoverClient
+	var key *Key
+
+	var newClient = func(failoverWrites bool) {
+		policy := NewFailoverPolicy()
+		policy.FailoverWrites = failoverWrites
+		policy.HealthCheckInterval = 20 * time.Millisecond
+
+		clientPolicy := NewClientPolicy()
+		clientPolicy.TendInterval = 20 * time.Millisecond
+		clientPolicy.Timeout = 200 * time.Millisecond
+
+		var err error
+		client, err = NewFailoverClient(policy, clientPolicy,
+			[]*Host{NewHost(primary.Host(), primary.Port())},
+			[]*Host{NewHost(secondary.Host(), secondary.Port())},
+		)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(client.Primary().Put(nil, key, BinMap{"dc": "primary"})).ToNot(HaveOccurred())
+		Expect(client.Secondary().Put(nil, key, BinMap{"dc": "secondary"})).ToNot(HaveOccurred())
+	}
+
+	var readDC = func() string {
+		rec, err := client.Get(nil, key)
+		Expect(err).ToNot(HaveOccurred())
+		return rec.Bins["dc"].(string)
+	}
+
+	var waitFailedOver = func(expected bool) {
+		for deadline := time.Now().Add(3 * time.Second); client.FailedOver() != expected; {
+			if time.Now().After(deadline) {
+				Fail("the client did not switch clusters")
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	BeforeEach(func() {
+		var err error
+		primary, err = aerotest.NewServer("test")
+		Expect(err).ToNot(HaveOccurred())
+		secondary, err = aerotest.NewServer("test")
+		Expect(err).ToNot(HaveOccurred())
+		secondary.SetNodeName("BB9AEROTEST0002")
+
+		key, err = NewKey("test", "set", 1)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		client.Close()
+		primary.Close()
+		secondary.Close()
+	})
+
+	It("must fail reads over to the secondary and fall back to the primary", func() {
+		newClient(false)
+		Expect(readDC()).To(Equal("primary"))
+		Expect(client.FailedOver()).To(BeFalse())
+
+		address := primary.Address()
+		primary.Close()
+		Expect(readDC()).To(Equal("secondary"))
+		Expect(client.FailedOver()).To(BeTrue())
+
+		// writes are not failed over by default
+		Expect(client.Put(nil, key, BinMap{"dc": "none"})).To(HaveOccurred())
+
+		var err error
+		primary, err = aerotest.NewServerOnAddress(address, "test")
+		Expect(err).ToNot(HaveOccurred())
+		waitFailedOver(false)
+
+		// the restarted primary has lost the record
+		rec, err := client.Get(nil, key)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rec).To(BeNil())
+	})
+
+	It("must fail writes over if enabled", func() {
+		newClient(true)
+
+		primary.Close()
+		Expect(client.Put(nil, key, BinMap{"dc": "written"})).ToNot(HaveOccurred())
+		Expect(client.FailedOver()).To(BeTrue())
+
+		rec, err := client.Secondary().Get(nil, key)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rec.Bins["dc"]).To(Equal("written"))
+	})
+
+})
@@ -0,0 +1,124 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike_test
+
+import (
+	"time"
+
+	. "github.com/THE108/aerospike-client-go"
+	"github.com/THE108/aerospike-client-go/aerotest"
+	. "github.com/THE108/aerospike-client-go/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Server feature changes", func() {
+
+	type change struct {
+		added, removed []string
+	}
+
+	var srv *aerotest.Server
+	var client *Client
+	var changes chan change
+
+	var nextChange = func() change {
+		select {
+		case c := <-changes:
+			return c
+		case <-time.After(time.Second):
+			Fail("no feature change detected")
+		}
+		return change{}
+	}
+
+	BeforeEach(func() {
+		var err error
+		changes = make(chan change, 10)
+		policy := NewClientPolicy()
+		policy.TendInterval = 20 * time.Millisecond
+		policy.NodeFeaturesChanged = func(node *Node, added, removed []string) {
+			changes <- change{added, removed}
+		}
+
+		client, srv, err = aerotest.NewClientAndServerWithPolicy(policy, "test")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		client.Close()
+		Expect(srv.Close()).ToNot(HaveOccurred())
+	})
+
+	It("must detect removed features and fail commands requiring them", func() {
+		key, _ := NewKey("test", "aerotest", "key1")
+		Expect(client.PutBins(nil, key, NewBin("l", []interface{}{2, 1}))).ToNot(HaveOccurred())
+
+		srv.SetFeatures("pipelining", "replicas-master")
+
+		c := nextChange()
+		Expect(c.removed).To(Equal([]string{"cdt-list", "udf"}))
+		Expect(client.GetNodes()[0].SupportsFeature("cdt-list")).To(BeFalse())
+
+		_, err := client.Operate(nil, key, ListSortOp("l", 0))
+		Expect(err).To(HaveOccurred())
+		Expect(err.(AerospikeError).ResultCode()).To(Equal(UNSUPPORTED_FEATURE))
+
+		// commands not requiring the features are not affected
+		_, err = client.Get(nil, key)
+		Expect(err).ToNot(HaveOccurred())
+
+		srv.SetFeatures("cdt-list", "pipelining", "replicas-master", "udf")
+		c = nextChange()
+		Expect(c.added).To(Equal([]string{"cdt-list", "udf"}))
+		Expect(c.removed).To(BeNil())
+	})
+
+	It("must know the build and features of nodes when they are added", func() {
+		node := client.GetNodes()[0]
+		Expect(node.Build()).To(Equal(aerotest.Build))
+		Expect(node.Features()).To(Equal([]string{"cdt-list", "pipelining", "replicas-master", "udf"}))
+	})
+
+	It("must gate namespace truncation on the truncate-namespace feature", func() {
+		key, _ := NewKey("test", "aerotest", "key1")
+		other, _ := NewKey("test", "other", "key1")
+		Expect(client.Put(nil, key, BinMap{"n": 1})).ToNot(HaveOccurred())
+		Expect(client.Put(nil, other, BinMap{"n": 1})).ToNot(HaveOccurred())
+
+		err := client.Truncate(nil, "test", "", nil)
+		Expect(err).To(HaveOccurred())
+		Expect(err.(AerospikeError).ResultCode()).To(Equal(UNSUPPORTED_FEATURE))
+		Expect(srv.Len("test")).To(Equal(2))
+
+		// records updated after the time are kept
+		before := time.Now().Add(-time.Hour)
+		Expect(client.Truncate(nil, "test", "aerotest", &before)).ToNot(HaveOccurred())
+		Expect(srv.Len("test")).To(Equal(2))
+
+		Expect(client.Truncate(nil, "test", "aerotest", nil)).ToNot(HaveOccurred())
+		exists, err := client.Exists(nil, key)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(exists).To(BeFalse())
+		Expect(srv.Len("test")).To(Equal(1))
+
+		srv.SetFeatures("cdt-list", "pipelining", "replicas-master", "truncate-namespace", "udf")
+		Expect(nextChange().added).To(Equal([]string{"truncate-namespace"}))
+		Expect(client.Truncate(nil, "test", "", nil)).ToNot(HaveOccurred())
+		Expect(srv.Len("test")).To(Equal(0))
+	})
+
+})
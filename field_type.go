@@ -23,23 +23,28 @@ const (
 	TABLE     FieldType = 1
 	KEY       FieldType = 2
 
-	//BIN FieldType = 3;
+	RECORD_VERSION FieldType = 3 // version of the record in a multi-record transaction
 
 	DIGEST_RIPE FieldType = 4
 
-	//GU_TID FieldType = 5;
+	MRT_ID FieldType = 5 // multi-record transaction id
 
 	DIGEST_RIPE_ARRAY FieldType = 6
+	MRT_DEADLINE      FieldType = 6 // multi-record transaction deadline; only sent with MRT_ID
 	TRAN_ID           FieldType = 7 // user supplied transaction id, which is simply passed back
 	SCAN_OPTIONS      FieldType = 8
 	INDEX_NAME        FieldType = 21
 	INDEX_RANGE       FieldType = 22
 	INDEX_FILTER      FieldType = 23
+	INDEX_CONTEXT     FieldType = 23 // CDT context of the filtered index; replaces INDEX_FILTER
 	INDEX_LIMIT       FieldType = 24
 	INDEX_ORDER_BY    FieldType = 25
+	INDEX_TYPE        FieldType = 26 // collection type of the filtered index
 	UDF_PACKAGE_NAME  FieldType = 30
 	UDF_FUNCTION      FieldType = 31
 	UDF_ARGLIST       FieldType = 32
 	UDF_OP            FieldType = 33
 	QUERY_BINLIST     FieldType = 40
+	BATCH_INDEX       FieldType = 41
+	PREDEXP           FieldType = 43
 )
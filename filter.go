@@ -15,41 +15,81 @@
 package aerospike
 
 import (
+	. "github.com/THE108/aerospike-client-go/types"
+	ParticleType "github.com/THE108/aerospike-client-go/types/particle_type"
 	Buffer "github.com/THE108/aerospike-client-go/utils/buffer"
 )
 
 // Filter specifies a query filter definition.
 type Filter struct {
-	name  string
-	begin Value
-	end   Value
+	name    string
+	idxType IndexCollectionType
+	begin   Value
+	end     Value
+	ctx     []*CDTContext
 }
 
 // NewEqualFilter creates a new equality filter instance for query.
-func NewEqualFilter(binName string, value interface{}) *Filter {
+// The value may be an integer, a string, or a []byte for blob indexes.
+// With a CDT context, the filter applies to the index on the nested
+// element selected by the context.
+func NewEqualFilter(binName string, value interface{}, ctx ...*CDTContext) *Filter {
 	val := NewValue(value)
-	return newFilter(binName, val, val)
+	return newFilter(binName, ICT_DEFAULT, val, val, ctx)
 }
 
 // NewRangeFilter creates a range filter for query.
 // Range arguments must be int64 values.
 // String ranges are not supported.
-func NewRangeFilter(binName string, begin int64, end int64) *Filter {
-	return newFilter(binName, NewValue(begin), NewValue(end))
+// With a CDT context, the filter applies to the index on the nested
+// element selected by the context.
+func NewRangeFilter(binName string, begin int64, end int64, ctx ...*CDTContext) *Filter {
+	return newFilter(binName, ICT_DEFAULT, NewValue(begin), NewValue(end), ctx)
+}
+
+// NewContainsFilter creates a filter for query selecting the records whose
+// list or map bin contains the value, as an element, map key or map value
+// depending on the collection type. The bin must have an index of the same
+// collection type and CDT context, see Client.CreateComplexIndex.
+func NewContainsFilter(binName string, indexCollectionType IndexCollectionType, value interface{}, ctx ...*CDTContext) *Filter {
+	val := NewValue(value)
+	return newFilter(binName, indexCollectionType, val, val, ctx)
+}
+
+// NewContainsRangeFilter creates a filter for query selecting the records
+// whose list or map bin contains an integer between begin and end inclusive,
+// as an element, map key or map value depending on the collection type.
+// The bin must have an index of the same collection type and CDT context,
+// see Client.CreateComplexIndex.
+func NewContainsRangeFilter(binName string, indexCollectionType IndexCollectionType, begin, end int64, ctx ...*CDTContext) *Filter {
+	return newFilter(binName, indexCollectionType, NewValue(begin), NewValue(end), ctx)
 }
 
 // Create a filter for query.
 // Range arguments must be longs or integers which can be cast to longs.
 // String ranges are not supported.
-func newFilter(name string, begin Value, end Value) *Filter {
+func newFilter(name string, indexCollectionType IndexCollectionType, begin Value, end Value, ctx []*CDTContext) *Filter {
 	return &Filter{
-		name:  name,
-		begin: begin,
-		end:   end,
+		name:    name,
+		idxType: indexCollectionType,
+		begin:   begin,
+		end:     end,
+		ctx:     ctx,
 	}
 }
 
+// IndexCollectionType returns the collection type of the index the filter applies to.
+func (fltr *Filter) IndexCollectionType() IndexCollectionType {
+	return fltr.idxType
+}
+
 func (fltr *Filter) estimateSize() (int, error) {
+	switch fltr.begin.GetType() {
+	case ParticleType.INTEGER, ParticleType.STRING, ParticleType.BLOB:
+	default:
+		return 0, NewAerospikeError(PARAMETER_ERROR, "Filter values must be integers, strings or blobs")
+	}
+
 	// bin name size(1) + particle type size(1) + begin particle size(4) + end particle size(4) = 10
 	return len(fltr.name) + fltr.begin.estimateSize() + fltr.end.estimateSize() + 10, nil
 }
@@ -0,0 +1,143 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"time"
+)
+
+// hedgeableCommand is a single record read which can be hedged.
+type hedgeableCommand interface {
+	command
+
+	// setFirstNode sends the first attempt of the command to the node
+	// instead of the master of the partition.
+	setFirstNode(node *Node)
+}
+
+// hedgeResult is the outcome of one of the requests of a hedged read.
+type hedgeResult struct {
+	cmd command
+	err error
+}
+
+// executeHedged executes the read command created by newCommand. If the
+// policy has a HedgeDelay and the command has not finished within the delay,
+// a second command is sent to another node. The command with the first
+// successful response is returned; if both fail, the error of the first one.
+func (clnt *Client) executeHedged(policy *BasePolicy, key *Key, newCommand func(policy *BasePolicy) hedgeableCommand) (command, error) {
+	primary := newCommand(policy)
+	if policy.HedgeDelay <= 0 || policy.Txn != nil {
+		return primary, primary.Execute()
+	}
+
+	// buffered, so the slower command never blocks
+	results := make(chan hedgeResult, 2)
+	go func() {
+		results <- hedgeResult{cmd: primary, err: primary.Execute()}
+	}()
+
+	timer := time.NewTimer(policy.HedgeDelay)
+	defer timer.Stop()
+
+	select {
+	case res := <-results:
+		return res.cmd, res.err
+	case <-timer.C:
+	}
+
+	node := clnt.cluster.hedgeNode(NewPartitionByKey(key))
+	if node == nil {
+		res := <-results
+		return res.cmd, res.err
+	}
+
+	// the hedge is not retried, and does not outlive the original timeout
+	hedgePolicy := policy.Clone()
+	hedgePolicy.MaxRetries = 0
+	if hedgePolicy.Timeout > 0 {
+		hedgePolicy.Timeout -= policy.HedgeDelay
+		if hedgePolicy.Timeout < time.Millisecond {
+			hedgePolicy.Timeout = time.Millisecond
+		}
+	}
+
+	hedge := newCommand(hedgePolicy)
+	hedge.setFirstNode(node)
+	go func() {
+		results <- hedgeResult{cmd: hedge, err: hedge.Execute()}
+	}()
+
+	first := <-results
+	if first.err == nil {
+		return first.cmd, nil
+	}
+
+	second := <-results
+	if second.err == nil {
+		return second.cmd, nil
+	}
+
+	// prefer the error of the original command
+	if first.cmd == primary {
+		return first.cmd, first.err
+	}
+	return second.cmd, second.err
+}
+
+func (cmd *singleCommand) setFirstNode(node *Node) {
+	cmd.firstNode = node
+}
+
+// hedgeNode returns an active node other than the master of the partition
+// to send hedged reads to, or nil if there is none. The replicas of the
+// partition are preferred if they are known; any other node is used
+// otherwise. The NodeSelector of the client policy is used if set; otherwise
+// nodes are chosen round robin.
+func (clstr *Cluster) hedgeNode(partition *Partition) *Node {
+	master, err := clstr.GetNode(partition)
+	if err != nil {
+		return nil
+	}
+
+	var nodes []*Node
+	for _, node := range clstr.GetReplicaNodes(partition) {
+		if node != master {
+			nodes = append(nodes, node)
+		}
+	}
+	if len(nodes) == 0 {
+		for _, node := range clstr.GetNodes() {
+			if node.IsActive() && node != master {
+				nodes = append(nodes, node)
+			}
+		}
+	}
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	if selector := clstr.policy().NodeSelector; selector != nil {
+		if node := selector.SelectNode(nodes, master, master); node != nil && node != master {
+			return node
+		}
+	}
+
+	index := clstr.nodeIndex.GetAndIncrement() % len(nodes)
+	if index < 0 {
+		index = -index
+	}
+	return nodes[index]
+}
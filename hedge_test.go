@@ -0,0 +1,145 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"time"
+
+	. "github.com/THE108/aerospike-client-go/types"
+	. "github.com/THE108/aerospike-client-go/types/atomic"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// testHedgedCommand answers after the delay of the node it is sent to.
+type testHedgedCommand struct {
+	*singleCommand
+
+	policy *BasePolicy
+	delays map[*Node]time.Duration
+	errors map[*Node]error
+}
+
+func (cmd *testHedgedCommand) getPolicy(ifc command) Policy                    { return cmd.policy }
+func (cmd *testHedgedCommand) writeBuffer(ifc command) error                   { return nil }
+func (cmd *testHedgedCommand) parseResult(ifc command, conn *Connection) error { return nil }
+
+func (cmd *testHedgedCommand) Execute() error {
+	node, _ := cmd.getNode(cmd)
+	cmd.node = node
+	time.Sleep(cmd.delays[node])
+	return cmd.errors[node]
+}
+
+var _ = Describe("Hedged Read Test", func() {
+
+	var master, replica *Node
+	var client *Client
+	var key *Key
+	var delays map[*Node]time.Duration
+	var errors map[*Node]error
+
+	var newTestNode = func(name string) *Node {
+		return &Node{
+			name:   name,
+			host:   NewHost("127.0.0.1", 3000),
+			active: NewAtomicBool(true),
+		}
+	}
+
+	var execute = func(policy *BasePolicy) (*Node, error) {
+		cmd, err := client.executeHedged(policy, key, func(policy *BasePolicy) hedgeableCommand {
+			return &testHedgedCommand{
+				singleCommand: newSingleCommand(client.cluster, key),
+				policy:        policy,
+				delays:        delays,
+				errors:        errors,
+			}
+		})
+		return cmd.(*testHedgedCommand).node, err
+	}
+
+	BeforeEach(func() {
+		master = newTestNode("BB9000000000001")
+		replica = newTestNode("BB9000000000002")
+
+		key, _ = NewKey("test", "test", 1)
+		partitions := NewAtomicArray(_PARTITIONS)
+		partitions.Set(NewPartitionByKey(key).PartitionId, master)
+
+		client = &Client{cluster: &Cluster{
+			nodes:             []*Node{master, replica},
+			partitionWriteMap: map[string]*AtomicArray{"test": partitions},
+			nodeIndex:         NewAtomicInt(0),
+		}}
+
+		delays = map[*Node]time.Duration{}
+		errors = map[*Node]error{}
+	})
+
+	It("should send a second request if the first one is slow", func() {
+		delays[master] = 500 * time.Millisecond
+
+		policy := NewPolicy()
+		policy.HedgeDelay = 10 * time.Millisecond
+
+		start := time.Now()
+		node, err := execute(policy)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(node).To(Equal(replica))
+		Expect(time.Since(start)).To(BeNumerically("<", 400*time.Millisecond))
+	})
+
+	It("should not hedge fast requests or without a delay", func() {
+		policy := NewPolicy()
+		policy.HedgeDelay = 100 * time.Millisecond
+
+		node, err := execute(policy)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(node).To(Equal(master))
+
+		delays[master] = 20 * time.Millisecond
+		node, err = execute(NewPolicy())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(node).To(Equal(master))
+	})
+
+	It("should wait for the other request if one fails", func() {
+		delays[master] = 50 * time.Millisecond
+		errors[replica] = NewAerospikeError(TIMEOUT)
+
+		policy := NewPolicy()
+		policy.HedgeDelay = 10 * time.Millisecond
+
+		node, err := execute(policy)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(node).To(Equal(master))
+
+		errors[master] = NewAerospikeError(KEY_NOT_FOUND_ERROR)
+		node, err = execute(policy)
+		Expect(err).To(HaveOccurred())
+		Expect(err.(AerospikeError).ResultCode()).To(Equal(KEY_NOT_FOUND_ERROR))
+		Expect(node).To(Equal(master))
+	})
+
+	It("should only hedge to nodes other than the master", func() {
+		Expect(client.cluster.hedgeNode(NewPartitionByKey(key))).To(Equal(replica))
+
+		client.cluster.nodes = []*Node{master}
+		Expect(client.cluster.hedgeNode(NewPartitionByKey(key))).To(BeNil())
+	})
+
+})
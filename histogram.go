@@ -0,0 +1,229 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"strconv"
+	"strings"
+
+	. "github.com/THE108/aerospike-client-go/types"
+)
+
+// HistogramType determines the type of a namespace histogram.
+type HistogramType string
+
+const (
+	// TTL_HISTOGRAM specifies the histogram of record time-to-live values.
+	TTL_HISTOGRAM HistogramType = "ttl"
+
+	// OBJECT_SIZE_HISTOGRAM specifies the histogram of record sizes.
+	OBJECT_SIZE_HISTOGRAM HistogramType = "object-size"
+
+	// OBJECT_SIZE_LINEAR_HISTOGRAM specifies the linear histogram of record sizes.
+	OBJECT_SIZE_LINEAR_HISTOGRAM HistogramType = "object-size-linear"
+)
+
+// Histogram contains a bucketed distribution of records in a namespace.
+type Histogram struct {
+	// Namespace of the histogram.
+	Namespace string
+
+	// Type of the histogram.
+	Type HistogramType
+
+	// Units of the bucket widths, e.g. `seconds` or `bytes`.
+	// Empty if not reported by the server.
+	Units string
+
+	// BucketWidth is the width of each bucket in Units.
+	BucketWidth int64
+
+	// Buckets contain the number of records in each bucket.
+	// Bucket i contains records with values in [i*BucketWidth, (i+1)*BucketWidth).
+	Buckets []int64
+}
+
+// Total returns the total number of records in the histogram.
+func (h *Histogram) Total() (res int64) {
+	for _, count := range h.Buckets {
+		res += count
+	}
+	return res
+}
+
+// HistogramBucket is a bucket of a histogram with bounds converted to
+// the base unit of the histogram type: seconds for ttl histograms, and
+// bytes for object-size histograms.
+type HistogramBucket struct {
+	// From is the inclusive lower bound of the bucket.
+	From int64
+
+	// To is the exclusive upper bound of the bucket.
+	To int64
+
+	// Count is the number of records in the bucket.
+	Count int64
+}
+
+// histogramUnits maps the units reported by the server to
+// the number of base units they contain.
+var histogramUnits = map[string]int64{
+	"":        1,
+	"seconds": 1,
+	"minutes": 60,
+	"hours":   60 * 60,
+	"days":    24 * 60 * 60,
+	"bytes":   1,
+	"rblocks": 128,
+	"kib":     1024,
+	"mib":     1024 * 1024,
+}
+
+// BaseUnits returns the unit HistogramBucket bounds are expressed in,
+// `seconds` or `bytes`.
+func (h *Histogram) BaseUnits() string {
+	if h.Type == TTL_HISTOGRAM {
+		return "seconds"
+	}
+	return "bytes"
+}
+
+// UnitSize returns the number of base units in one unit of the histogram.
+// Units which are unknown or not reported are assumed to be base units.
+func (h *Histogram) UnitSize() int64 {
+	if size, exists := histogramUnits[strings.ToLower(h.Units)]; exists {
+		return size
+	}
+	return 1
+}
+
+// BucketRanges returns the buckets of the histogram with their bounds
+// converted to base units.
+func (h *Histogram) BucketRanges() []HistogramBucket {
+	width := h.BucketWidth * h.UnitSize()
+
+	res := make([]HistogramBucket, len(h.Buckets))
+	for i, count := range h.Buckets {
+		res[i] = HistogramBucket{
+			From:  int64(i) * width,
+			To:    int64(i+1) * width,
+			Count: count,
+		}
+	}
+	return res
+}
+
+// Percentile returns the upper bound in base units of the bucket below which
+// the percentage p (0-100) of records fall. It returns 0 for empty histograms.
+func (h *Histogram) Percentile(p float64) int64 {
+	total := h.Total()
+	if total == 0 {
+		return 0
+	}
+
+	threshold := int64(float64(total) * p / 100)
+	var sum int64
+	ranges := h.BucketRanges()
+	for _, b := range ranges {
+		if sum += b.Count; sum > 0 && sum >= threshold {
+			return b.To
+		}
+	}
+	return ranges[len(ranges)-1].To
+}
+
+// RequestHistogram retrieves and parses a namespace histogram from the specified node.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *Client) RequestHistogram(policy *InfoPolicy, node *Node, namespace string, histogramType HistogramType) (*Histogram, error) {
+	policy = clnt.getUsableInfoPolicy(policy)
+
+	command := "histogram:namespace=" + namespace + ";type=" + string(histogramType)
+	infoMap, err := RequestNodeInfoWithPolicy(policy, node, command)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseHistogram(namespace, histogramType, infoMap[command])
+}
+
+// parseHistogram parses the output of the `histogram:` info command.
+// Two formats are supported:
+//
+//	units=seconds:hist-width=2592000:bucket-width=25920:buckets=0,12,5,...
+//	test-ttl=100,25920,0,12,5,...
+//
+// In the second format, the first two values are the bucket count and the bucket width.
+func parseHistogram(namespace string, histogramType HistogramType, info string) (*Histogram, error) {
+	info = strings.TrimSpace(info)
+	if info == "" || strings.HasPrefix(strings.ToLower(info), "error") {
+		return nil, NewAerospikeError(PARSE_ERROR, "Histogram not available: "+info)
+	}
+
+	res := &Histogram{Namespace: namespace, Type: histogramType}
+
+	var buckets []string
+	if strings.Contains(info, "buckets=") {
+		for _, param := range strings.Split(info, ":") {
+			kv := strings.SplitN(param, "=", 2)
+			if len(kv) != 2 {
+				continue
+			}
+
+			switch kv[0] {
+			case "units":
+				res.Units = kv[1]
+			case "bucket-width":
+				width, err := strconv.ParseInt(kv[1], 10, 64)
+				if err != nil {
+					return nil, NewAerospikeError(PARSE_ERROR, "Invalid histogram bucket width: "+kv[1])
+				}
+				res.BucketWidth = width
+			case "buckets":
+				buckets = strings.Split(kv[1], ",")
+			}
+		}
+	} else {
+		if index := strings.Index(info, "="); index >= 0 {
+			info = info[index+1:]
+		}
+
+		values := strings.Split(info, ",")
+		if len(values) < 2 {
+			return nil, NewAerospikeError(PARSE_ERROR, "Invalid histogram: "+info)
+		}
+
+		width, err := strconv.ParseInt(values[1], 10, 64)
+		if err != nil {
+			return nil, NewAerospikeError(PARSE_ERROR, "Invalid histogram bucket width: "+values[1])
+		}
+		res.BucketWidth = width
+		buckets = values[2:]
+	}
+
+	res.Buckets = make([]int64, 0, len(buckets))
+	for _, v := range buckets {
+		if v = strings.TrimSpace(v); v == "" {
+			continue
+		}
+
+		count, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, NewAerospikeError(PARSE_ERROR, "Invalid histogram bucket value: "+v)
+		}
+		res.Buckets = append(res.Buckets, count)
+	}
+
+	return res, nil
+}
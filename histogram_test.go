@@ -0,0 +1,76 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Histogram Test", func() {
+
+	It("should parse the key/value histogram format", func() {
+		h, err := parseHistogram("test", TTL_HISTOGRAM, "units=seconds:hist-width=100:bucket-width=25:buckets=1,0,7,2")
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(h.Namespace).To(Equal("test"))
+		Expect(h.Type).To(Equal(TTL_HISTOGRAM))
+		Expect(h.Units).To(Equal("seconds"))
+		Expect(h.BucketWidth).To(Equal(int64(25)))
+		Expect(h.Buckets).To(Equal([]int64{1, 0, 7, 2}))
+		Expect(h.Total()).To(Equal(int64(10)))
+	})
+
+	It("should parse the legacy histogram format", func() {
+		h, err := parseHistogram("test", OBJECT_SIZE_HISTOGRAM, "test-object-size=3,128,4,5,6")
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(h.Units).To(Equal(""))
+		Expect(h.BucketWidth).To(Equal(int64(128)))
+		Expect(h.Buckets).To(Equal([]int64{4, 5, 6}))
+	})
+
+	It("should convert buckets to base units", func() {
+		h, err := parseHistogram("test", TTL_HISTOGRAM, "units=hours:hist-width=4:bucket-width=1:buckets=1,0,7,2")
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(h.BaseUnits()).To(Equal("seconds"))
+		Expect(h.BucketRanges()).To(Equal([]HistogramBucket{
+			{From: 0, To: 3600, Count: 1},
+			{From: 3600, To: 7200, Count: 0},
+			{From: 7200, To: 10800, Count: 7},
+			{From: 10800, To: 14400, Count: 2},
+		}))
+		Expect(h.Percentile(50)).To(Equal(int64(10800)))
+		Expect(h.Percentile(100)).To(Equal(int64(14400)))
+
+		h, err = parseHistogram("test", OBJECT_SIZE_LINEAR_HISTOGRAM, "units=rblocks:hist-width=100:bucket-width=2:buckets=3,4")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(h.BaseUnits()).To(Equal("bytes"))
+		Expect(h.BucketRanges()[1]).To(Equal(HistogramBucket{From: 256, To: 512, Count: 4}))
+
+		h.Buckets = []int64{0, 0}
+		Expect(h.Percentile(99)).To(Equal(int64(0)))
+	})
+
+	It("should return an error when the histogram is not available", func() {
+		_, err := parseHistogram("test", TTL_HISTOGRAM, "error-unknown-namespace")
+		Expect(err).To(HaveOccurred())
+
+		_, err = parseHistogram("test", TTL_HISTOGRAM, "units=seconds:bucket-width=25:buckets=1,x")
+		Expect(err).To(HaveOccurred())
+	})
+
+})
@@ -27,6 +27,12 @@ type Host struct {
 	// Port of database server.
 	Port int
 
+	// TLSName is the name the server certificate is verified against on TLS
+	// connections. If empty, the ServerName of ClientPolicy.TLSConfig or the
+	// host name is used. Nodes discovered through the peers protocol get the
+	// TLS name reported by the cluster.
+	TLSName string
+
 	addPort string
 }
 
@@ -71,11 +71,32 @@ var _ = Describe("Index operations test", func() {
 				// wait until index is created
 				<-idxTask.OnComplete()
 
+				pct, err := idxTask.Progress()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(pct).To(Equal(100))
+
 				// no duplicate index is allowed
 				_, err = client.CreateIndex(wpolicy, ns, set, set+bin1.Name, bin1.Name, STRING)
 				Expect(err).To(HaveOccurred())
 			})
 
+			It("must tolerate an existing Index with the same definition", func() {
+				idxTask, err := client.CreateIndex(wpolicy, ns, set, set+bin1.Name, bin1.Name, NUMERIC)
+				Expect(err).ToNot(HaveOccurred())
+				defer client.DropIndex(wpolicy, ns, set, set+bin1.Name)
+
+				// wait until index is created
+				<-idxTask.OnComplete()
+
+				idxTask, err = client.CreateIndexIfNotExists(wpolicy, ns, set, set+bin1.Name, bin1.Name, NUMERIC)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(<-idxTask.OnComplete()).ToNot(HaveOccurred())
+
+				// a different definition is not allowed
+				_, err = client.CreateIndexIfNotExists(wpolicy, ns, set, set+bin1.Name, bin2.Name, STRING)
+				Expect(err).To(HaveOccurred())
+			})
+
 			It("must drop an Index", func() {
 				idxTask, err := client.CreateIndex(wpolicy, ns, set, set+bin1.Name, bin1.Name, STRING)
 				Expect(err).ToNot(HaveOccurred())
@@ -23,4 +23,40 @@ const (
 
 	// STRING specifies an index on string values.
 	STRING IndexType = "STRING"
+
+	// BLOB specifies an index on blob ([]byte) values.
+	// Blob indexes need Aerospike 7 servers or newer. Values of bins
+	// compressed by the client are not indexed, see Client.SetCompression.
+	BLOB IndexType = "BLOB"
 )
+
+// IndexCollectionType is the type of the values of a secondary index on
+// bins holding lists or maps.
+type IndexCollectionType int
+
+const (
+	// ICT_DEFAULT indexes the scalar values of the bins.
+	ICT_DEFAULT IndexCollectionType = iota
+
+	// ICT_LIST indexes the elements of list values.
+	ICT_LIST
+
+	// ICT_MAPKEYS indexes the keys of map values.
+	ICT_MAPKEYS
+
+	// ICT_MAPVALUES indexes the values of map values.
+	ICT_MAPVALUES
+)
+
+// String returns the name of the collection type in index definitions.
+func (ict IndexCollectionType) String() string {
+	switch ict {
+	case ICT_LIST:
+		return "LIST"
+	case ICT_MAPKEYS:
+		return "MAPKEYS"
+	case ICT_MAPVALUES:
+		return "MAPVALUES"
+	}
+	return "DEFAULT"
+}
@@ -17,6 +17,7 @@ package aerospike
 import (
 	"bytes"
 	"encoding/binary"
+	"io"
 	"strings"
 	"time"
 
@@ -26,17 +27,52 @@ import (
 
 const (
 	_DEFAULT_TIMEOUT = 2 * time.Second
-	_NO_TIMEOUT      = 365 * 24 * time.Hour
 )
 
+// timeoutChan returns a channel which receives once the timeout has passed.
+// A timeout of zero or less has no deadline; the returned channel is nil,
+// and never receives.
+func timeoutChan(timeout time.Duration) <-chan time.Time {
+	if timeout <= 0 {
+		return nil
+	}
+	return time.After(timeout)
+}
+
 // Access server's info monitoring protocol.
 type info struct {
 	msg *Message
 }
 
 // RequestNodeInfo gets info values by name from the specified database server node.
+// Default InfoPolicy will be used.
 func RequestNodeInfo(node *Node, name ...string) (map[string]string, error) {
-	conn, err := node.GetConnection(_DEFAULT_TIMEOUT)
+	return RequestNodeInfoWithPolicy(nil, node, name...)
+}
+
+// RequestNodeInfoWithPolicy gets info values by name from the specified database server node.
+// The command will be retried on network errors according to the policy.
+// If the policy is nil, the default InfoPolicy will be used.
+func RequestNodeInfoWithPolicy(policy *InfoPolicy, node *Node, name ...string) (response map[string]string, err error) {
+	if policy == nil {
+		policy = NewInfoPolicy()
+	}
+
+	for iterations := 0; iterations <= policy.MaxRetries; iterations++ {
+		// Sleep before trying again, after the first iteration
+		if iterations > 0 && policy.SleepBetweenRetries > 0 {
+			time.Sleep(policy.SleepBetweenRetries)
+		}
+
+		if response, err = requestNodeInfo(policy.Timeout, node, name...); err == nil {
+			return response, nil
+		}
+	}
+	return nil, err
+}
+
+func requestNodeInfo(timeout time.Duration, node *Node, name ...string) (map[string]string, error) {
+	conn, err := node.GetConnection(timeout)
 	if err != nil {
 		return nil, err
 	}
@@ -50,29 +86,41 @@ func RequestNodeInfo(node *Node, name ...string) (map[string]string, error) {
 	return response, nil
 }
 
-// RequestNodeStats returns statistics for the specified node as a map
+// RequestNodeStats returns statistics for the specified node as a map.
+// Default InfoPolicy will be used.
 func RequestNodeStats(node *Node) (map[string]string, error) {
-	infoMap, err := RequestNodeInfo(node, "statistics")
+	return RequestNodeStatsWithPolicy(nil, node)
+}
+
+// RequestNodeStatsWithPolicy returns statistics for the specified node as a map.
+// If the policy is nil, the default InfoPolicy will be used.
+func RequestNodeStatsWithPolicy(policy *InfoPolicy, node *Node) (map[string]string, error) {
+	infoMap, err := RequestNodeInfoWithPolicy(policy, node, "statistics")
 	if err != nil {
 		return nil, err
 	}
 
-	res := map[string]string{}
-
 	v, exists := infoMap["statistics"]
 	if !exists {
-		return res, nil
+		return map[string]string{}, nil
 	}
 
+	return parseInfoParams(v), nil
+}
+
+// parseInfoParams parses `name1=value1;name2=value2;...` info values into a map.
+func parseInfoParams(v string) map[string]string {
+	res := map[string]string{}
+
 	values := strings.Split(v, ";")
 	for i := range values {
-		kv := strings.Split(values[i], "=")
+		kv := strings.SplitN(values[i], "=", 2)
 		if len(kv) > 1 {
 			res[kv[0]] = kv[1]
 		}
 	}
 
-	return res, nil
+	return res
 }
 
 // Send multiple commands to server and store results.
@@ -100,9 +148,65 @@ func RequestInfo(conn *Connection, names ...string) (map[string]string, error) {
 	return info.parseMultiResponse()
 }
 
+// RequestInfoRaw sends the info command to the connection and returns a reader
+// streaming the raw response body, which consists of the command, a tab, the
+// value and a newline. Unlike RequestInfo, the response is not loaded into
+// memory, so it can be used for very large outputs, like dump commands.
+// The response must be read to the end before the connection is used again;
+// otherwise the connection must be closed.
+func RequestInfoRaw(conn *Connection, command string) (io.Reader, error) {
+	nfo := &info{
+		msg: NewMessage(MSG_INFO, []byte(strings.Trim(command, " ")+"\n")),
+	}
+
+	if err := nfo.sendRequest(conn); err != nil {
+		return nil, err
+	}
+	return &infoReader{conn: conn, remaining: nfo.msg.Length()}, nil
+}
+
+// infoReader reads an info response body from the connection.
+type infoReader struct {
+	conn      *Connection
+	remaining int64
+}
+
+func (r *infoReader) Read(p []byte) (int, error) {
+	if r.remaining <= 0 {
+		return 0, io.EOF
+	}
+
+	if int64(len(p)) > r.remaining {
+		p = p[:r.remaining]
+	}
+
+	n, err := r.conn.conn.Read(p)
+	r.remaining -= int64(n)
+	if err == io.EOF {
+		return n, io.ErrUnexpectedEOF
+	} else if err != nil {
+		return n, errToTimeoutErr(err)
+	}
+	return n, nil
+}
+
 // Issue request and set results buffer. This method is used internally.
 // The static request methods should be used instead.
 func (nfo *info) sendCommand(conn *Connection) error {
+	if err := nfo.sendRequest(conn); err != nil {
+		return err
+	}
+
+	// Logger.Debug("Header Response: %v %v %v %v", t.Type, t.Version, t.Length(), t.DataLen)
+	if err := nfo.msg.Resize(nfo.msg.Length()); err != nil {
+		return err
+	}
+	_, err := conn.Read(nfo.msg.Data, len(nfo.msg.Data))
+	return err
+}
+
+// sendRequest writes the request and reads the response header.
+func (nfo *info) sendRequest(conn *Connection) error {
 	// Write.
 	if _, err := conn.Write(nfo.msg.Serialize()); err != nil {
 		Logger.Debug("Failed to send command.")
@@ -118,13 +222,7 @@ func (nfo *info) sendCommand(conn *Connection) error {
 		Logger.Debug("Failed to read command response.")
 		return err
 	}
-
-	// Logger.Debug("Header Response: %v %v %v %v", t.Type, t.Version, t.Length(), t.DataLen)
-	if err := nfo.msg.Resize(nfo.msg.Length()); err != nil {
-		return err
-	}
-	_, err := conn.Read(nfo.msg.Data, len(nfo.msg.Data))
-	return err
+	return nil
 }
 
 func (nfo *info) parseSingleResponse(name string) (string, error) {
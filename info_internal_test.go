@@ -0,0 +1,100 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net"
+	"time"
+
+	. "github.com/THE108/aerospike-client-go/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Info Test", func() {
+
+	var client, server net.Conn
+	var conn *Connection
+
+	// serve reads the request and answers with the response body
+	var serve = func(body []byte) chan []byte {
+		requests := make(chan []byte, 1)
+		go func() {
+			header := make([]byte, MSG_HEADER_SIZE)
+			if _, err := io.ReadFull(server, header); err != nil {
+				return
+			}
+			request := make([]byte, int(header[6])<<8|int(header[7]))
+			io.ReadFull(server, request)
+			requests <- request
+
+			server.Write(NewMessage(MSG_INFO, body).Serialize())
+		}()
+		return requests
+	}
+
+	BeforeEach(func() {
+		client, server = net.Pipe()
+		conn = &Connection{conn: client}
+		Expect(conn.SetTimeout(time.Second)).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		client.Close()
+		server.Close()
+	})
+
+	It("must stream raw responses larger than the message buffer limit", func() {
+		body := append([]byte("sindex-dump\t"), bytes.Repeat([]byte("a"), 2*1024*1024)...)
+		body = append(body, '\n')
+		requests := serve(body)
+
+		r, err := RequestInfoRaw(conn, "sindex-dump")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(<-requests).To(Equal([]byte("sindex-dump\n")))
+
+		res, err := ioutil.ReadAll(r)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(bytes.Equal(res, body)).To(BeTrue())
+
+		// the connection is reusable after the response was read
+		serve([]byte("build\t3.9.1\n"))
+		info, err := RequestInfo(conn, "build")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info).To(Equal(map[string]string{"build": "3.9.1"}))
+	})
+
+	It("must fail on truncated responses", func() {
+		go func() {
+			header := make([]byte, MSG_HEADER_SIZE+len("build\n"))
+			io.ReadFull(server, header)
+
+			msg := NewMessage(MSG_INFO, []byte("build\t3.9.1\n")).Serialize()
+			server.Write(msg[:len(msg)-4])
+			server.Close()
+		}()
+
+		r, err := RequestInfoRaw(conn, "build")
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = ioutil.ReadAll(r)
+		Expect(err).To(Equal(io.ErrUnexpectedEOF))
+	})
+
+})
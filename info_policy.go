@@ -0,0 +1,49 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import "time"
+
+// InfoPolicy contains attributes used for info commands.
+type InfoPolicy struct {
+
+	// Info command socket timeout.
+	// Default is two seconds timeout.
+	Timeout time.Duration
+
+	// MaxRetries determines maximum number of retries before aborting the info command.
+	// A retry is attempted when there is a network error.
+	// Default is no retries (0).
+	MaxRetries int
+
+	// SleepBetweenRetries determines duration to sleep between retries.
+	// Enter zero to skip sleep.
+	SleepBetweenRetries time.Duration
+}
+
+// NewInfoPolicy generates a new InfoPolicy with default values.
+func NewInfoPolicy() *InfoPolicy {
+	return &InfoPolicy{
+		Timeout:             _DEFAULT_TIMEOUT,
+		MaxRetries:          0,
+		SleepBetweenRetries: 100 * time.Millisecond,
+	}
+}
+
+// Clone returns a copy of the policy.
+func (p *InfoPolicy) Clone() *InfoPolicy {
+	res := *p
+	return &res
+}
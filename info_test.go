@@ -0,0 +1,126 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike_test
+
+import (
+	"time"
+
+	. "github.com/THE108/aerospike-client-go"
+	"github.com/THE108/aerospike-client-go/aerotest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Node Info Request Test", func() {
+
+	var srv1, srv2 *aerotest.Server
+	var servers map[string]*aerotest.Server
+	var client *Client
+
+	BeforeEach(func() {
+		var err error
+		srv1, err = aerotest.NewServer("test")
+		Expect(err).ToNot(HaveOccurred())
+		srv2, err = aerotest.NewServer("test")
+		Expect(err).ToNot(HaveOccurred())
+
+		srv2.SetNodeName("BB9AEROTEST0002")
+		for _, srv := range []*aerotest.Server{srv1, srv2} {
+			srv.SetFeatures("peers", "pipelining", "replicas-master")
+		}
+		srv1.AddPeer(srv2)
+		srv2.AddPeer(srv1)
+		servers = map[string]*aerotest.Server{aerotest.NodeName: srv1, "BB9AEROTEST0002": srv2}
+
+		// info requests dropped by the servers must not be taken by the tend
+		policy := NewClientPolicy()
+		policy.TendInterval = time.Hour
+		client, err = NewClientWithPolicy(policy, srv1.Host(), srv1.Port())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(len(client.GetNodes())).To(Equal(2))
+	})
+
+	AfterEach(func() {
+		client.Close()
+		srv1.Close()
+		srv2.Close()
+	})
+
+	It("must retry info requests on network errors up to MaxRetries", func() {
+		node, err := client.Cluster().GetNodeByName(aerotest.NodeName)
+		Expect(err).ToNot(HaveOccurred())
+
+		policy := NewInfoPolicy()
+		policy.SleepBetweenRetries = time.Millisecond
+		policy.MaxRetries = 1
+
+		srv1.DropInfoRequests(2)
+		_, err = RequestNodeInfoWithPolicy(policy, node, "node")
+		Expect(err).To(HaveOccurred())
+
+		policy.MaxRetries = 2
+		srv1.DropInfoRequests(2)
+		info, err := RequestNodeInfoWithPolicy(policy, node, "node")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info).To(Equal(map[string]string{"node": aerotest.NodeName}))
+	})
+
+	It("must time out info requests after the policy timeout", func() {
+		node, err := client.Cluster().GetNodeByName(aerotest.NodeName)
+		Expect(err).ToNot(HaveOccurred())
+
+		policy := NewInfoPolicy()
+		policy.Timeout = 50 * time.Millisecond
+
+		srv1.SetInfoDelay(300 * time.Millisecond)
+		start := time.Now()
+		_, err = RequestNodeInfoWithPolicy(policy, node, "node")
+		Expect(err).To(HaveOccurred())
+		Expect(time.Since(start)).To(BeNumerically("<", 300*time.Millisecond))
+	})
+
+	It("must send info requests to the next node when a node fails", func() {
+		nodes := client.GetNodes()
+
+		servers[nodes[0].GetName()].DropInfoRequests(1)
+		info, err := client.RequestInfoAny(nil, "node")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info).To(Equal(map[string]string{"node": nodes[1].GetName()}))
+
+		srv1.DropInfoRequests(1)
+		srv2.DropInfoRequests(1)
+		_, err = client.RequestInfoAny(nil, "node")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("must send info requests to unhealthy nodes last", func() {
+		nodes := client.GetNodes()
+		for !nodes[0].IsUnhealthy() {
+			nodes[0].DecreaseHealth()
+		}
+
+		info, err := client.RequestInfoAny(nil, "node")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info).To(Equal(map[string]string{"node": nodes[1].GetName()}))
+
+		// the unhealthy node is still tried if the healthy ones fail
+		servers[nodes[1].GetName()].DropInfoRequests(1)
+		info, err = client.RequestInfoAny(nil, "node")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info).To(Equal(map[string]string{"node": nodes[0].GetName()}))
+	})
+
+})
@@ -29,6 +29,8 @@ import (
 // an optional set name, and a user defined key which must be unique within a set.
 // Records can also be identified by namespace/digest which is the combination used
 // on the server.
+// Keys are immutable once created, except through SetDigest and SetValue,
+// and can be shared between goroutines.
 type Key struct {
 	// namespace. Equivalent to database name.
 	namespace string
@@ -39,6 +41,10 @@ type Key struct {
 	// Unique server hash value generated from set name and user key.
 	digest []byte
 
+	// backing array of the digests computed by the client, so keys are
+	// allocated at once, and SetValue does not allocate a new digest
+	digestBuf [20]byte
+
 	// Original user key. This key is immediately converted to a hash digest.
 	// This key is not used or returned by the server by default. If the user key needs
 	// to persist on the server, use one of the following methods:
@@ -92,7 +98,7 @@ func NewKey(namespace string, setName string, key interface{}) (newKey *Key, err
 		userKey:   NewValue(key),
 	}
 
-	newKey.digest, err = computeDigest(newKey)
+	newKey.digest, err = computeDigest(newKey.digestBuf[:0], setName, newKey.userKey)
 
 	return newKey, err
 }
@@ -112,6 +118,62 @@ func NewKeyWithDigest(namespace string, setName string, key interface{}, digest
 	return newKey, err
 }
 
+// NewKeysWithDigests initializes keys from namespace, optional set name and
+// digests computed outside the client, e.g. by ComputeDigest. The keys
+// have no user key. The digests are not copied.
+func NewKeysWithDigests(namespace string, setName string, digests [][]byte) ([]*Key, error) {
+	keys := make([]*Key, len(digests))
+	for i, digest := range digests {
+		keys[i] = &Key{
+			namespace: namespace,
+			setName:   setName,
+		}
+
+		if err := keys[i].SetDigest(digest); err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
+
+// ComputeDigest returns the digest the server identifies a record by, for
+// the set name and user key. It is the digest of keys created by NewKey.
+func ComputeDigest(setName string, key interface{}) ([]byte, error) {
+	return computeDigest(nil, setName, NewValue(key))
+}
+
+// SetValue changes the user key, and recomputes the digest of the key.
+// It allows reusing a key for many records, e.g. in loops writing millions
+// of records, without allocating a new key and digest for each of them.
+// The key must not be changed while it is used by a command; keys passed to
+// asynchronous APIs, like the batch or the change watcher, must not be reused.
+// WriteBuffer, transactions and the record cache keep their own copy of
+// the key, so keys can be reused once they return.
+// The digest is changed in place: slices returned by Digest before the call,
+// and the Key of records read with the key, change with it.
+// The key is left unchanged on errors.
+func (ky *Key) SetValue(key interface{}) error {
+	userKey := NewValue(key)
+
+	// a digest set by SetDigest is the caller's, and is not overwritten
+	digest, err := computeDigest(ky.digestBuf[:0], ky.setName, userKey)
+	if err != nil {
+		return err
+	}
+
+	ky.userKey = userKey
+	ky.digest = digest
+	return nil
+}
+
+// clone returns a copy of the key with its own digest, which is not
+// changed by SetValue on the key.
+func (ky *Key) clone() *Key {
+	res := *ky
+	res.digest = append([]byte(nil), ky.digest...)
+	return &res
+}
+
 // SetDigest sets a custom hash
 func (ky *Key) SetDigest(digest []byte) error {
 	if len(digest) != 20 {
@@ -123,8 +185,9 @@ func (ky *Key) SetDigest(digest []byte) error {
 
 // Generate unique server hash value from set name, key type and user defined key.
 // The hash function is RIPEMD-160 (a 160 bit hash).
-func computeDigest(key *Key) ([]byte, error) {
-	keyType := key.userKey.GetType()
+// The digest is appended to dst, which avoids an allocation if it has the capacity.
+func computeDigest(dst []byte, setName string, userKey Value) ([]byte, error) {
+	keyType := userKey.GetType()
 
 	if keyType == ParticleType.NULL {
 		return nil, NewAerospikeError(PARAMETER_ERROR, "Invalid key: nil")
@@ -140,12 +203,25 @@ func computeDigest(key *Key) ([]byte, error) {
 
 	buf := keyBufPool.Get().(*bytes.Buffer)
 	buf.Reset()
-	buf.WriteString(key.setName)
+	buf.WriteString(setName)
 	buf.WriteByte(byte(keyType))
-	buf.ReadFrom(key.userKey.reader())
+
+	// the common key types are written without allocating a reader
+	switch v := userKey.(type) {
+	case StringValue:
+		buf.WriteString(string(v))
+	case IntegerValue:
+		writeDigestInt(buf, int64(v))
+	case LongValue:
+		writeDigestInt(buf, int64(v))
+	case BytesValue:
+		buf.Write(v)
+	default:
+		buf.ReadFrom(userKey.reader())
+	}
 
 	h.Write(buf.Bytes())
-	res := h.Sum(nil)
+	res := h.Sum(dst)
 
 	// put hash object back to the pool
 	hashPool.Put(h)
@@ -154,6 +230,13 @@ func computeDigest(key *Key) ([]byte, error) {
 	return res, nil
 }
 
+// writeDigestInt writes the integer in big endian order, like IntegerValue.reader.
+func writeDigestInt(buf *bytes.Buffer, n int64) {
+	var b [8]byte
+	Buffer.Int64ToBytes(n, b[:], 0)
+	buf.Write(b[:])
+}
+
 // hash pool
 var hashPool *Pool
 var keyBufPool *Pool
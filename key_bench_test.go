@@ -134,6 +134,27 @@ func Benchmark_NewKey_________Int(b *testing.B) {
 	makeKeys(rand.Int63(), b)
 }
 
+func Benchmark_Key_SetValue_____Int(b *testing.B) {
+	key, _ := NewKey("ns", "set", 0)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		key.SetValue(i)
+		res = key.Digest()
+	}
+}
+
+func Benchmark_Key_SetValue__String(b *testing.B) {
+	key, _ := NewKey("ns", "set", "")
+	value := strings.Repeat("s", 10)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		key.SetValue(value)
+		res = key.Digest()
+	}
+}
+
 func Benchmark_NewKey_List_No_Reflect(b *testing.B) {
 	list := []interface{}{
 		strings.Repeat("s", 1),
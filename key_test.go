@@ -143,6 +143,48 @@ var _ = Describe("Key Test", func() {
 			Expect(key.Digest()).To(Equal([]byte("01234567890123456789")))
 		})
 
+		It("for digests computed outside keys", func() {
+			digest, err := ComputeDigest("set", math.MaxInt64)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(hex.EncodeToString(digest)).To(Equal("1698328974afa62c8e069860c1516f780d63dbb8"))
+
+			_, err = ComputeDigest("set", nil)
+			Expect(err).To(HaveOccurred())
+
+			keys, err := NewKeysWithDigests("namespace", "set", [][]byte{digest, []byte("01234567890123456789")})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(keys)).To(Equal(2))
+
+			key, _ := NewKey("namespace", "set", math.MaxInt64)
+			Expect(keys[0].Equals(key)).To(BeTrue())
+			Expect(keys[0].Namespace()).To(Equal("namespace"))
+			Expect(keys[0].Value()).To(BeNil())
+			Expect(keys[1].Digest()).To(Equal([]byte("01234567890123456789")))
+
+			_, err = NewKeysWithDigests("namespace", "set", [][]byte{digest, []byte("short")})
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("for keys reused with SetValue", func() {
+			key, _ := NewKey("namespace", "set", "a")
+			for _, value := range []interface{}{math.MaxInt64, -1, "string", []byte{1, 2}, []interface{}{1, "a"}} {
+				expected, _ := NewKey("namespace", "set", value)
+				Expect(key.SetValue(value)).ToNot(HaveOccurred())
+				Expect(key.Digest()).To(Equal(expected.Digest()))
+				Expect(key.Value()).To(Equal(expected.Value()))
+			}
+
+			// the key is unchanged on errors
+			Expect(key.SetValue(nil)).To(HaveOccurred())
+			Expect(key.Value().GetObject()).To(Equal([]interface{}{1, "a"}))
+
+			// digests set by the caller are not overwritten
+			digest := []byte("01234567890123456789")
+			Expect(key.SetDigest(digest)).ToNot(HaveOccurred())
+			Expect(key.SetValue(1)).ToNot(HaveOccurred())
+			Expect(digest).To(Equal([]byte("01234567890123456789")))
+		})
+
 	})
 
 })
@@ -14,6 +14,10 @@
 
 package aerospike
 
+import (
+	. "github.com/THE108/aerospike-client-go/types"
+)
+
 // LargeList encapsulates a list within a single bin.
 type LargeList struct {
 	*baseLargeObject
@@ -45,6 +49,30 @@ func (ll *LargeList) Remove(values ...interface{}) (err error) {
 	return err
 }
 
+// RemoveRange deletes values from list which are between begin and end, inclusive.
+func (ll *LargeList) RemoveRange(begin, end interface{}) (err error) {
+	_, err = ll.client.Execute(ll.policy, ll.key, ll.packageName, "remove_range", ll.binName, NewValue(begin), NewValue(end))
+	return err
+}
+
+// Exists checks existence of value in the list.
+func (ll *LargeList) Exists(value interface{}) (bool, error) {
+	res, err := ll.client.Execute(ll.policy, ll.key, ll.packageName, "exists", ll.binName, NewValue(value))
+	if err != nil {
+		return false, err
+	}
+
+	switch v := res.(type) {
+	case nil:
+		return false, nil
+	case int:
+		return v != 0, nil
+	case int64:
+		return v != 0, nil
+	}
+	return false, NewAerospikeError(PARSE_ERROR, "Unexpected large list exists result type")
+}
+
 // Find selects values from list.
 func (ll *LargeList) Find(value interface{}) ([]interface{}, error) {
 	res, err := ll.client.Execute(ll.policy, ll.key, ll.packageName, "find", ll.binName, NewValue(value))
@@ -85,7 +113,13 @@ func (ll *LargeList) FindFirst(count int) ([]interface{}, error) {
 }
 
 // FFilterThenindFirst selects values from the beginning of list up to a maximum count after applying lua filter.
+// Deprecated: use FilterThenFindFirst instead.
 func (ll *LargeList) FFilterThenindFirst(count int, filterModule, filterName string, filterArgs ...interface{}) ([]interface{}, error) {
+	return ll.FilterThenFindFirst(count, filterModule, filterName, filterArgs...)
+}
+
+// FilterThenFindFirst selects values from the beginning of list up to a maximum count after applying lua filter.
+func (ll *LargeList) FilterThenFindFirst(count int, filterModule, filterName string, filterArgs ...interface{}) ([]interface{}, error) {
 	res, err := ll.client.Execute(ll.policy, ll.key, ll.packageName, "find_first", ll.binName, NewValue(count), NewValue(filterModule), NewValue(filterName), ToValueArray(filterArgs))
 	if err != nil {
 		return nil, err
@@ -19,7 +19,8 @@ import (
 	. "github.com/onsi/gomega"
 
 	. "github.com/THE108/aerospike-client-go"
-	// . "github.com/THE108/aerospike-client-go/types"
+	"github.com/THE108/aerospike-client-go/aerotest"
+	. "github.com/THE108/aerospike-client-go/types"
 )
 
 var _ = Describe("LargeList Test", func() {
@@ -102,6 +103,30 @@ var _ = Describe("LargeList Test", func() {
 		Expect(len(scanResult)).To(Equal(0))
 	})
 
+	It("should support Exists() and RemoveRange()", func() {
+		llist := client.GetLargeList(wpolicy, key, randString(10), "")
+
+		for i := 1; i <= 10; i++ {
+			err = llist.Add(NewValue(i))
+			Expect(err).ToNot(HaveOccurred())
+		}
+
+		exists, err := llist.Exists(5)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(exists).To(BeTrue())
+
+		err = llist.RemoveRange(3, 7)
+		Expect(err).ToNot(HaveOccurred())
+
+		exists, err = llist.Exists(5)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(exists).To(BeFalse())
+
+		sz, err := llist.Size()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sz).To(Equal(5))
+	})
+
 	It("should correctly GetConfig()", func() {
 		llist := client.GetLargeList(wpolicy, key, randString(10), "")
 		err = llist.Add(NewValue(0))
@@ -113,3 +138,56 @@ var _ = Describe("LargeList Test", func() {
 	})
 
 }) // describe
+
+var _ = Describe("LargeList Exists Test", func() {
+
+	var srv *aerotest.Server
+	var client *Client
+	var llist *LargeList
+	var result interface{}
+
+	BeforeEach(func() {
+		var err error
+		client, srv, err = aerotest.NewClientAndServer("test")
+		Expect(err).ToNot(HaveOccurred())
+
+		// stands in for the exists function of the llist module
+		srv.RegisterUDF("llist", "exists", func(bins map[string]interface{}, args []interface{}) (interface{}, error) {
+			return result, nil
+		})
+
+		key, _ := NewKey("test", "aerotest", "list")
+		llist = client.GetLargeList(nil, key, "list", "")
+	})
+
+	AfterEach(func() {
+		client.Close()
+		Expect(srv.Close()).ToNot(HaveOccurred())
+	})
+
+	It("must check the existence of values", func() {
+		result = int64(1)
+		exists, err := llist.Exists(5)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(exists).To(BeTrue())
+
+		result = int64(0)
+		exists, err = llist.Exists(5)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(exists).To(BeFalse())
+
+		result = nil
+		exists, err = llist.Exists(5)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(exists).To(BeFalse())
+	})
+
+	It("must report unexpected results of exists instead of panicking", func() {
+		result = "yes"
+		exists, err := llist.Exists(5)
+		Expect(err).To(HaveOccurred())
+		Expect(err.(AerospikeError).ResultCode()).To(Equal(PARSE_ERROR))
+		Expect(exists).To(BeFalse())
+	})
+
+})
@@ -0,0 +1,174 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	. "github.com/THE108/aerospike-client-go/types"
+)
+
+// LatencyBucket contains the percentage of operations
+// which took longer than the bucket threshold.
+type LatencyBucket struct {
+	Threshold  time.Duration
+	Percentage float64
+}
+
+// Latency contains a latency histogram of an operation on a server node.
+type Latency struct {
+	// Name is the name of the histogram as reported by the server,
+	// e.g. `reads` or `{test}-read`.
+	Name string
+
+	// Namespace of the histogram. Empty for servers reporting histograms
+	// for all namespaces together.
+	Namespace string
+
+	// Operation of the histogram, e.g. `reads`, `writes_master` or `read`.
+	Operation string
+
+	// Time is the end of the measurement period, as reported by the server.
+	// Empty for servers not reporting it.
+	Time string
+
+	// OpsPerSec is the throughput of the operation during the measurement period.
+	OpsPerSec float64
+
+	// Buckets contain the percentages of operations above each threshold.
+	Buckets []LatencyBucket
+}
+
+// RequestLatency retrieves and parses the latency histograms of the node.
+// Both `latency:` (older servers) and `latencies:` output formats are supported.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *Client) RequestLatency(policy *InfoPolicy, node *Node) ([]*Latency, error) {
+	policy = clnt.getUsableInfoPolicy(policy)
+
+	infoMap, err := RequestNodeInfoWithPolicy(policy, node, "latencies:", "latency:")
+	if err != nil {
+		return nil, err
+	}
+
+	if v := infoMap["latencies:"]; v != "" && !strings.HasPrefix(v, "error") {
+		return parseLatency(v)
+	}
+	return parseLatency(infoMap["latency:"])
+}
+
+// parseLatency parses the output of `latency:` and `latencies:` info commands.
+//
+// `latency:` output consists of header and data pairs:
+//
+//	reads:15:26:57-GMT,ops/sec,>1ms,>8ms,>64ms;15:27:07,0.0,0.00,0.00,0.00;...
+//
+// `latencies:` output consists of one entry per histogram, with power of two thresholds:
+//
+//	{test}-read:msec,1.0,0.00,0.00,0.00,...;{test}-write:msec,...
+func parseLatency(info string) ([]*Latency, error) {
+	res := []*Latency{}
+
+	tokens := strings.Split(strings.TrimSpace(info), ";")
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+
+		index := strings.Index(token, ":")
+		if index <= 0 {
+			continue
+		}
+
+		name, values := token[:index], strings.Split(token[index+1:], ",")
+		if len(values) < 2 || strings.HasPrefix(values[0], "error") {
+			// no data for this histogram yet
+			continue
+		}
+
+		l := &Latency{Name: name, Operation: name}
+		if strings.HasPrefix(name, "{") {
+			if end := strings.Index(name, "}"); end > 0 {
+				l.Namespace = name[1:end]
+				l.Operation = strings.TrimPrefix(name[end+1:], "-")
+			}
+		}
+
+		var thresholds []time.Duration
+		var err error
+
+		switch values[0] {
+		case "msec", "usec":
+			unit := time.Millisecond
+			if values[0] == "usec" {
+				unit = time.Microsecond
+			}
+
+			values = values[1:]
+			thresholds = make([]time.Duration, len(values)-1)
+			for j := range thresholds {
+				thresholds[j] = unit << uint(j)
+			}
+		default:
+			if values[1] != "ops/sec" {
+				return nil, NewAerospikeError(PARSE_ERROR, "Invalid latency histogram header: "+token)
+			}
+
+			if thresholds, err = parseLatencyThresholds(values[2:]); err != nil {
+				return nil, err
+			}
+
+			// values are in the next token
+			if i++; i >= len(tokens) {
+				return nil, NewAerospikeError(PARSE_ERROR, "Latency histogram values missing for: "+name)
+			}
+			values = strings.Split(tokens[i], ",")
+			l.Time, values = values[0], values[1:]
+		}
+
+		if len(values) != len(thresholds)+1 {
+			return nil, NewAerospikeError(PARSE_ERROR, "Latency histogram bucket count mismatch for: "+name)
+		}
+
+		if l.OpsPerSec, err = strconv.ParseFloat(values[0], 64); err != nil {
+			return nil, NewAerospikeError(PARSE_ERROR, "Invalid latency histogram ops/sec: "+values[0])
+		}
+
+		l.Buckets = make([]LatencyBucket, len(thresholds))
+		for j := range thresholds {
+			pct, err := strconv.ParseFloat(values[j+1], 64)
+			if err != nil {
+				return nil, NewAerospikeError(PARSE_ERROR, "Invalid latency histogram percentage: "+values[j+1])
+			}
+			l.Buckets[j] = LatencyBucket{Threshold: thresholds[j], Percentage: pct}
+		}
+
+		res = append(res, l)
+	}
+
+	return res, nil
+}
+
+// parseLatencyThresholds parses thresholds in `>1ms` format.
+func parseLatencyThresholds(values []string) ([]time.Duration, error) {
+	res := make([]time.Duration, len(values))
+	for i, v := range values {
+		d, err := time.ParseDuration(strings.TrimPrefix(v, ">"))
+		if err != nil {
+			return nil, NewAerospikeError(PARSE_ERROR, "Invalid latency histogram threshold: "+v)
+		}
+		res[i] = d
+	}
+	return res, nil
+}
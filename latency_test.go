@@ -0,0 +1,79 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Latency Histogram Test", func() {
+
+	It("should parse the `latency:` output format", func() {
+		info := "reads:15:26:57-GMT,ops/sec,>1ms,>8ms,>64ms;15:27:07,1520.3,5.25,0.51,0.01;" +
+			"writes_master:error-no-data-yet-or-back-too-small;" +
+			"udf:15:26:57-GMT,ops/sec,>1ms,>8ms,>64ms;15:27:07,0.0,0.00,0.00,0.00"
+
+		res, err := parseLatency(info)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(len(res)).To(Equal(2))
+
+		Expect(res[0].Name).To(Equal("reads"))
+		Expect(res[0].Operation).To(Equal("reads"))
+		Expect(res[0].Namespace).To(Equal(""))
+		Expect(res[0].Time).To(Equal("15:27:07"))
+		Expect(res[0].OpsPerSec).To(Equal(1520.3))
+		Expect(res[0].Buckets).To(Equal([]LatencyBucket{
+			{Threshold: time.Millisecond, Percentage: 5.25},
+			{Threshold: 8 * time.Millisecond, Percentage: 0.51},
+			{Threshold: 64 * time.Millisecond, Percentage: 0.01},
+		}))
+
+		Expect(res[1].Name).To(Equal("udf"))
+		Expect(len(res[1].Buckets)).To(Equal(3))
+	})
+
+	It("should parse the `latencies:` output format", func() {
+		info := "batch-index:;{test}-read:msec,10.5,1.50,0.25,0.00;{bar}-write:usec,2.0,100.00,50.00"
+
+		res, err := parseLatency(info)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(len(res)).To(Equal(2))
+
+		Expect(res[0].Name).To(Equal("{test}-read"))
+		Expect(res[0].Namespace).To(Equal("test"))
+		Expect(res[0].Operation).To(Equal("read"))
+		Expect(res[0].OpsPerSec).To(Equal(10.5))
+		Expect(res[0].Buckets).To(Equal([]LatencyBucket{
+			{Threshold: time.Millisecond, Percentage: 1.5},
+			{Threshold: 2 * time.Millisecond, Percentage: 0.25},
+			{Threshold: 4 * time.Millisecond, Percentage: 0},
+		}))
+
+		Expect(res[1].Namespace).To(Equal("bar"))
+		Expect(res[1].Buckets[1].Threshold).To(Equal(2 * time.Microsecond))
+	})
+
+	It("should return an error on malformed output", func() {
+		_, err := parseLatency("reads:15:26:57-GMT,ops/sec,>1ms,>8ms;15:27:07,0.0,0.00")
+		Expect(err).To(HaveOccurred())
+
+		_, err = parseLatency("reads:15:26:57-GMT,ops/sec,>1ms")
+		Expect(err).To(HaveOccurred())
+	})
+
+})
@@ -0,0 +1,75 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+// List bin operations. These are executed on the server on list bins,
+// and can be combined with other operations in a single Operate command.
+// Requires server versions that support CDT list operations.
+
+const (
+	_CDT_LIST_APPEND = 1
+	_CDT_LIST_SORT   = 13
+
+	// list order and write flags of list policies
+	_CDT_LIST_ORDERED    = 1
+	_CDT_LIST_ADD_UNIQUE = 1
+	_CDT_LIST_NO_FAIL    = 4
+)
+
+// ListSortFlags determines sort flags for ListSortOp.
+type ListSortFlags int
+
+const (
+	// ListSortFlagsDefault sorts the list, keeping duplicate values.
+	ListSortFlagsDefault ListSortFlags = 0
+
+	// ListSortFlagsDropDuplicates sorts the list and removes duplicate values.
+	ListSortFlagsDropDuplicates ListSortFlags = 2
+)
+
+// packCDTParams packs a CDT command and its arguments in the wire format
+// expected by the server: a 2 byte command code followed by the message
+// packed argument list, if any.
+func packCDTParams(command int, args ...interface{}) ([]byte, error) {
+	packer := newPacker()
+	packer.buffer.WriteByte(byte(command >> 8))
+	packer.buffer.WriteByte(byte(command))
+
+	if len(args) > 0 {
+		if err := packer.PackList(args); err != nil {
+			return nil, err
+		}
+	}
+	return packer.buffer.Bytes(), nil
+}
+
+func newCDTOperation(opType OperationType, binName string, command int, args ...interface{}) *Operation {
+	// args are always of supported native types; packing can't fail
+	packed, _ := packCDTParams(command, args...)
+	return &Operation{OpType: opType, BinName: binName, BinValue: NewBytesValue(packed)}
+}
+
+// ListSortOp creates a list sort operation.
+// The server sorts the list bin in place, optionally dropping duplicate values.
+// The operation does not return a value.
+func ListSortOp(binName string, sortFlags ListSortFlags) *Operation {
+	return newCDTOperation(CDT_MODIFY, binName, _CDT_LIST_SORT, int(sortFlags))
+}
+
+// DedupListOp creates an operation that sorts a list bin and removes its
+// duplicate values on the server, without a read-modify-write cycle.
+func DedupListOp(binName string) *Operation {
+	return ListSortOp(binName, ListSortFlagsDropDuplicates)
+}
@@ -0,0 +1,34 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("List Operations Test", func() {
+
+	It("should pack the list sort operation", func() {
+		op := ListSortOp("tags", ListSortFlagsDefault)
+		Expect(op.OpType).To(Equal(CDT_MODIFY))
+		Expect(op.BinName).To(Equal("tags"))
+		Expect(op.BinValue.GetObject()).To(Equal([]byte{0x00, 0x0d, 0x91, 0x00}))
+
+		op = DedupListOp("tags")
+		Expect(op.BinValue.GetObject()).To(Equal([]byte{0x00, 0x0d, 0x91, 0x02}))
+	})
+
+})
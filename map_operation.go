@@ -0,0 +1,153 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	. "github.com/THE108/aerospike-client-go/types"
+)
+
+// Map bin operations. These are executed on the server on map bins,
+// and can be combined with other operations in a single Operate command.
+// Requires server versions that support CDT map operations.
+
+const (
+	_CDT_MAP_INCREMENT              = 73
+	_CDT_MAP_DECREMENT              = 74
+	_CDT_MAP_REMOVE_BY_KEY_INTERVAL = 84
+	_CDT_MAP_GET_BY_KEY_INTERVAL    = 103
+)
+
+// MapReturnType determines what a map operation that selects items returns.
+type MapReturnType int
+
+const (
+	// MAP_RETURN_NONE returns nothing.
+	MAP_RETURN_NONE MapReturnType = 0
+
+	// MAP_RETURN_COUNT returns the number of selected items.
+	MAP_RETURN_COUNT MapReturnType = 5
+
+	// MAP_RETURN_KEY returns the keys of the selected items.
+	MAP_RETURN_KEY MapReturnType = 6
+
+	// MAP_RETURN_VALUE returns the values of the selected items.
+	MAP_RETURN_VALUE MapReturnType = 7
+
+	// MAP_RETURN_KEY_VALUE returns the keys and values of the selected items.
+	MAP_RETURN_KEY_VALUE MapReturnType = 8
+)
+
+// MapOrder determines the sort order of a map bin.
+type MapOrder int
+
+const (
+	// MAP_UNORDERED keeps map items in no particular order.
+	MAP_UNORDERED MapOrder = 0
+
+	// MAP_KEY_ORDERED sorts map items by key.
+	MAP_KEY_ORDERED MapOrder = 1
+
+	// MAP_KEY_VALUE_ORDERED sorts map items by key and value.
+	MAP_KEY_VALUE_ORDERED MapOrder = 3
+)
+
+// MapPolicy determines the order of a map bin, used when the
+// operation creates the map.
+type MapPolicy struct {
+	// Order determines the order of the map when it is created.
+	Order MapOrder //= MAP_UNORDERED
+}
+
+// NewMapPolicy generates a new MapPolicy instance.
+func NewMapPolicy(order MapOrder) *MapPolicy {
+	return &MapPolicy{Order: order}
+}
+
+// Clone returns a copy of the policy.
+func (p *MapPolicy) Clone() *MapPolicy {
+	res := *p
+	return &res
+}
+
+// DefaultMapPolicy returns a MapPolicy for unordered maps.
+func DefaultMapPolicy() *MapPolicy {
+	return NewMapPolicy(MAP_UNORDERED)
+}
+
+// MapIncrementOp creates a map increment operation.
+// The server increments the value of mapKey by incr. If the bin does not
+// exist, it is created as a map ordered according to policy; if mapKey does
+// not exist, it is created with the value incr.
+// The operation returns the new value.
+func MapIncrementOp(policy *MapPolicy, binName string, mapKey interface{}, incr interface{}) *Operation {
+	if policy == nil {
+		policy = DefaultMapPolicy()
+	}
+	return newCDTOperation(CDT_MODIFY, binName, _CDT_MAP_INCREMENT, mapKey, incr, int(policy.Order))
+}
+
+// MapDecrementOp creates a map decrement operation.
+// It has the same create-if-absent semantics as MapIncrementOp.
+// The operation returns the new value.
+func MapDecrementOp(policy *MapPolicy, binName string, mapKey interface{}, decr interface{}) *Operation {
+	if policy == nil {
+		policy = DefaultMapPolicy()
+	}
+	return newCDTOperation(CDT_MODIFY, binName, _CDT_MAP_DECREMENT, mapKey, decr, int(policy.Order))
+}
+
+// MapRemoveByKeyRangeOp creates an operation that removes the map items with
+// keys in the range [keyBegin, keyEnd). A nil keyBegin selects from the lowest
+// key, and a nil keyEnd selects up to the highest key.
+func MapRemoveByKeyRangeOp(binName string, keyBegin, keyEnd interface{}, returnType MapReturnType) *Operation {
+	return newCDTOperation(CDT_MODIFY, binName, _CDT_MAP_REMOVE_BY_KEY_INTERVAL, keyRangeParams(returnType, keyBegin, keyEnd)...)
+}
+
+// MapGetByKeyRangeOp creates an operation that returns the map items with
+// keys in the range [keyBegin, keyEnd). A nil keyBegin selects from the lowest
+// key, and a nil keyEnd selects up to the highest key.
+func MapGetByKeyRangeOp(binName string, keyBegin, keyEnd interface{}, returnType MapReturnType) *Operation {
+	return newCDTOperation(CDT_READ, binName, _CDT_MAP_GET_BY_KEY_INTERVAL, keyRangeParams(returnType, keyBegin, keyEnd)...)
+}
+
+// keyRangeParams returns the arguments of a key range operation.
+// The server treats a nil end as a value lower than any key, so an open
+// ended range is sent without the end argument.
+func keyRangeParams(returnType MapReturnType, keyBegin, keyEnd interface{}) []interface{} {
+	if keyEnd == nil {
+		return []interface{}{int(returnType), keyBegin}
+	}
+	return []interface{}{int(returnType), keyBegin, keyEnd}
+}
+
+// MapIncrement increments the integer counter stored under mapKey in a map
+// bin by incr, creating the record, the map bin and the counter if they do
+// not exist, and returns the counter's new value.
+// Pass a negative incr to decrement the counter.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *Client) MapIncrement(policy *WritePolicy, key *Key, binName string, mapKey interface{}, incr int) (int, error) {
+	rec, err := clnt.Operate(policy, key, MapIncrementOp(nil, binName, mapKey, incr))
+	if err != nil {
+		return 0, err
+	}
+
+	switch v := rec.Bins[binName].(type) {
+	case int:
+		return v, nil
+	case int64:
+		return int(v), nil
+	}
+	return 0, NewAerospikeError(PARSE_ERROR, "Unexpected map increment result type")
+}
@@ -0,0 +1,44 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Map Operations Test", func() {
+
+	It("should pack the map increment and decrement operations", func() {
+		op := MapIncrementOp(nil, "counters", "a", 5)
+		Expect(op.OpType).To(Equal(CDT_MODIFY))
+		Expect(op.BinName).To(Equal("counters"))
+		Expect(op.BinValue.GetObject()).To(Equal([]byte{0x00, 0x49, 0x93, 0xa2, 0x03, 'a', 0x05, 0x00}))
+
+		op = MapDecrementOp(NewMapPolicy(MAP_KEY_ORDERED), "counters", 1, 2)
+		Expect(op.BinValue.GetObject()).To(Equal([]byte{0x00, 0x4a, 0x93, 0x01, 0x02, 0x01}))
+	})
+
+	It("should pack the map key range operations", func() {
+		op := MapRemoveByKeyRangeOp("m", nil, 10, MAP_RETURN_NONE)
+		Expect(op.OpType).To(Equal(CDT_MODIFY))
+		Expect(op.BinValue.GetObject()).To(Equal([]byte{0x00, 0x54, 0x93, 0x00, 0xc0, 0x0a}))
+
+		op = MapGetByKeyRangeOp("m", 10, nil, MAP_RETURN_VALUE)
+		Expect(op.OpType).To(Equal(CDT_READ))
+		Expect(op.BinValue.GetObject()).To(Equal([]byte{0x00, 0x67, 0x92, 0x07, 0x0a}))
+	})
+
+})
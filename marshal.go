@@ -36,6 +36,11 @@ func valueToInterface(f reflect.Value) interface{} {
 		f = reflect.Indirect(f)
 	}
 
+	// user registered types are encoded by their codec
+	if f.IsValid() && isCustomValueType(f.Type()) {
+		return f.Interface()
+	}
+
 	switch f.Kind() {
 	case reflect.Uint64:
 		return int64(f.Uint())
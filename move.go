@@ -0,0 +1,136 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"bytes"
+
+	. "github.com/THE108/aerospike-client-go/types"
+)
+
+// MoveState is the state of the records after a failed Move.
+type MoveState int
+
+const (
+	// MOVE_NOT_COPIED indicates no record was changed; the source record
+	// is intact and the destination record was not written.
+	MOVE_NOT_COPIED MoveState = iota
+
+	// MOVE_IN_DOUBT indicates the write of the destination record failed
+	// in a way that it may or may not have been applied, e.g. on timeouts
+	// and network errors. The source record is intact.
+	MOVE_IN_DOUBT
+
+	// MOVE_COPIED indicates the destination record was written, but the
+	// source record could not be deleted, or the source was changed during
+	// the move and the destination record could not be removed again.
+	// Both records may exist.
+	MOVE_COPIED
+)
+
+// MoveError is returned by Move when the record could not be moved.
+// It wraps the error of the failing step.
+type MoveError struct {
+	error
+
+	state MoveState
+}
+
+func newMoveError(state MoveState, err error) *MoveError {
+	return &MoveError{
+		error: err,
+		state: state,
+	}
+}
+
+// State returns the state the records were left in.
+func (me *MoveError) State() MoveState { return me.state }
+
+// Err returns the error of the failing step.
+func (me *MoveError) Err() error { return me.error }
+
+// Move moves the record of srcKey to dstKey, which must not exist yet.
+// The record is read, written to dstKey with CREATE_ONLY, and the source
+// is deleted only if its generation did not change in the meantime.
+// If the source was changed during the move, the destination record is
+// removed again and the move can be retried.
+//
+// The remaining TTL of the record is kept, unless the policy sets an
+// expiration. RecordExistsAction and GenerationPolicy of the policy are
+// ignored. On failure, a *MoveError reporting the state of the records
+// is returned.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *Client) Move(policy *WritePolicy, srcKey, dstKey *Key) error {
+	policy = clnt.getUsableWritePolicyFor(policy, srcKey.namespace, srcKey.setName)
+
+	if srcKey.namespace == dstKey.namespace && bytes.Equal(srcKey.digest, dstKey.digest) {
+		return newMoveError(MOVE_NOT_COPIED, NewAerospikeError(PARAMETER_ERROR, "Source and destination keys are the same"))
+	}
+
+	rec, err := clnt.Get(&policy.BasePolicy, srcKey)
+	if err != nil {
+		return newMoveError(MOVE_NOT_COPIED, err)
+	}
+	if rec == nil {
+		return newMoveError(MOVE_NOT_COPIED, NewAerospikeError(KEY_NOT_FOUND_ERROR))
+	}
+
+	writePolicy := *policy
+	writePolicy.RecordExistsAction = CREATE_ONLY
+	writePolicy.GenerationPolicy = NONE
+	if writePolicy.Expiration == 0 {
+		// records which never expire report a TTL of 0
+		writePolicy.Expiration = -1
+		if rec.Expiration > 0 {
+			writePolicy.Expiration = int32(rec.Expiration)
+		}
+	}
+
+	ops := make([]*Operation, 0, len(rec.Bins))
+	for name, value := range rec.Bins {
+		ops = append(ops, PutOp(NewBin(name, value)))
+	}
+
+	dst, err := clnt.Operate(&writePolicy, dstKey, ops...)
+	if err != nil {
+		if isInDoubt(err) {
+			return newMoveError(MOVE_IN_DOUBT, err)
+		}
+		return newMoveError(MOVE_NOT_COPIED, err)
+	}
+
+	deletePolicy := *policy
+	deletePolicy.GenerationPolicy = EXPECT_GEN_EQUAL
+	deletePolicy.Generation = int32(rec.Generation)
+
+	existed, err := clnt.Delete(&deletePolicy, srcKey)
+	if err == nil && existed {
+		return nil
+	}
+
+	if err == nil || (!isInDoubt(err) && err.(AerospikeError).ResultCode() == GENERATION_ERROR) {
+		// the source was changed or deleted during the move; undo the copy
+		err = NewAerospikeError(GENERATION_ERROR, "Source record changed during move")
+
+		rollbackPolicy := *policy
+		rollbackPolicy.GenerationPolicy = EXPECT_GEN_EQUAL
+		rollbackPolicy.Generation = int32(dst.Generation)
+		if _, rbErr := clnt.Delete(&rollbackPolicy, dstKey); rbErr == nil {
+			return newMoveError(MOVE_NOT_COPIED, err)
+		}
+	}
+
+	return newMoveError(MOVE_COPIED, err)
+}
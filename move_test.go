@@ -0,0 +1,90 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike_test
+
+import (
+	. "github.com/THE108/aerospike-client-go"
+	"github.com/THE108/aerospike-client-go/aerotest"
+	. "github.com/THE108/aerospike-client-go/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Move", func() {
+
+	var srv *aerotest.Server
+	var client *Client
+	var srcKey, dstKey *Key
+
+	BeforeEach(func() {
+		var err error
+		client, srv, err = aerotest.NewClientAndServer("test")
+		Expect(err).ToNot(HaveOccurred())
+
+		srcKey, _ = NewKey("test", "aerotest", "src")
+		dstKey, _ = NewKey("test", "aerotest", "dst")
+	})
+
+	AfterEach(func() {
+		client.Close()
+		Expect(srv.Close()).ToNot(HaveOccurred())
+	})
+
+	It("must move the record and keep its TTL", func() {
+		policy := NewWritePolicy(0, 100)
+		Expect(client.Put(policy, srcKey, BinMap{"a": 1, "b": "str"})).ToNot(HaveOccurred())
+
+		Expect(client.Move(nil, srcKey, dstKey)).ToNot(HaveOccurred())
+
+		exists, err := client.Exists(nil, srcKey)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(exists).To(BeFalse())
+
+		rec, err := client.Get(nil, dstKey)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rec.Bins).To(Equal(BinMap{"a": 1, "b": "str"}))
+		Expect(rec.Expiration).To(BeNumerically("~", 100, 2))
+	})
+
+	It("must leave the records intact if the destination exists", func() {
+		Expect(client.Put(nil, srcKey, BinMap{"a": 1})).ToNot(HaveOccurred())
+		Expect(client.Put(nil, dstKey, BinMap{"a": 2})).ToNot(HaveOccurred())
+
+		err := client.Move(nil, srcKey, dstKey)
+		Expect(err).To(HaveOccurred())
+		Expect(err.(*MoveError).State()).To(Equal(MOVE_NOT_COPIED))
+		Expect(err.(*MoveError).Err().(AerospikeError).ResultCode()).To(Equal(KEY_EXISTS_ERROR))
+
+		rec, err := client.Get(nil, srcKey)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rec.Bins).To(Equal(BinMap{"a": 1}))
+
+		rec, err = client.Get(nil, dstKey)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rec.Bins).To(Equal(BinMap{"a": 2}))
+	})
+
+	It("must fail if the source does not exist", func() {
+		err := client.Move(nil, srcKey, dstKey)
+		Expect(err).To(HaveOccurred())
+		Expect(err.(*MoveError).State()).To(Equal(MOVE_NOT_COPIED))
+		Expect(err.(*MoveError).Err().(AerospikeError).ResultCode()).To(Equal(KEY_NOT_FOUND_ERROR))
+
+		err = client.Move(nil, srcKey, srcKey)
+		Expect(err.(*MoveError).Err().(AerospikeError).ResultCode()).To(Equal(PARAMETER_ERROR))
+	})
+
+})
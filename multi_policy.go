@@ -29,7 +29,9 @@ type MultiPolicy struct {
 	// Number of records to place in queue before blocking.
 	// Records received from multiple server nodes will be placed in a queue.
 	// A separate goroutine consumes these records in parallel.
-	// If the queue is full, the producer goroutines will block until records are consumed.
+	// If the queue is full, the producer goroutines will block until records are consumed,
+	// and stop reading from their sockets; the servers are then throttled by TCP flow control.
+	// The time spent waiting for the consumer does not count towards the policy Timeout.
 	RecordQueueSize int //= 5000
 
 	// Blocks until on-going migrations are over
@@ -45,3 +47,12 @@ func NewMultiPolicy() *MultiPolicy {
 		WaitUntilMigrationsAreOver: false,
 	}
 }
+
+// Clone returns a deep copy of the policy, including its BasePolicy.
+func (p *MultiPolicy) Clone() *MultiPolicy {
+	res := *p
+	if p.BasePolicy != nil {
+		res.BasePolicy = p.BasePolicy.Clone()
+	}
+	return &res
+}
@@ -0,0 +1,155 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"strconv"
+	"strings"
+
+	. "github.com/THE108/aerospike-client-go/types"
+)
+
+// NamespaceConfig contains the configuration of a namespace on a server node.
+type NamespaceConfig struct {
+	// Namespace is the name of the namespace.
+	Namespace string
+
+	// ReplicationFactor is the number of copies of each record in the cluster.
+	ReplicationFactor int
+
+	// MemorySize is the maximum amount of memory for the namespace in bytes.
+	MemorySize int64
+
+	// DefaultTTL is the default time-to-live of the records in seconds.
+	DefaultTTL int64
+
+	// MaxTTL is the maximum time-to-live allowed for the records in seconds.
+	MaxTTL int64
+
+	// HighWaterDiskPct is the disk usage percentage at which evictions begin.
+	HighWaterDiskPct int
+
+	// HighWaterMemoryPct is the memory usage percentage at which evictions begin.
+	HighWaterMemoryPct int
+
+	// StopWritesPct is the memory usage percentage at which writes are disallowed.
+	StopWritesPct int
+
+	// EvictTenthsPct is the maximum tenths of a percent of data evicted in each cycle.
+	EvictTenthsPct int
+
+	// SingleBin determines if the namespace only allows one bin per record.
+	SingleBin bool
+
+	// DataInMemory determines if a copy of the data is kept in memory for persisted namespaces.
+	DataInMemory bool
+
+	// DefragLwmPct is the block usage percentage below which storage blocks are defragmented.
+	DefragLwmPct int
+
+	// Params contains all the configuration parameters as reported by the server,
+	// including the ones not mapped to the fields above.
+	Params map[string]string
+}
+
+// GetNamespaceConfig retrieves the configuration of a namespace from the specified node.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *Client) GetNamespaceConfig(policy *InfoPolicy, node *Node, namespace string) (*NamespaceConfig, error) {
+	policy = clnt.getUsableInfoPolicy(policy)
+
+	command := "get-config:context=namespace;id=" + namespace
+	infoMap, err := RequestNodeInfoWithPolicy(policy, node, command)
+	if err != nil {
+		return nil, err
+	}
+
+	response := infoMap[command]
+	if response == "" || strings.HasPrefix(strings.ToLower(response), "error") {
+		return nil, NewAerospikeError(INVALID_NAMESPACE, "Failed to get config for namespace `"+namespace+"`: "+response)
+	}
+
+	return parseNamespaceConfig(namespace, response)
+}
+
+// SetConfigParam changes a configuration parameter on the specified node at runtime.
+// The context is the configuration context, e.g. `service`, `network` or `namespace;id=test`.
+// The change is only applied to the specified node, and is not persisted
+// in the server configuration file.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *Client) SetConfigParam(policy *InfoPolicy, node *Node, context string, name string, value string) error {
+	policy = clnt.getUsableInfoPolicy(policy)
+
+	command := "set-config:context=" + context + ";" + name + "=" + value
+	infoMap, err := RequestNodeInfoWithPolicy(policy, node, command)
+	if err != nil {
+		return err
+	}
+
+	if response := infoMap[command]; strings.ToLower(strings.TrimSpace(response)) != "ok" {
+		return NewAerospikeError(INVALID_FIELD, "Failed to set config parameter `"+name+"` to `"+value+"`: "+response)
+	}
+	return nil
+}
+
+// SetNamespaceConfigParam changes a namespace configuration parameter on the specified node at runtime.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *Client) SetNamespaceConfigParam(policy *InfoPolicy, node *Node, namespace string, name string, value string) error {
+	return clnt.SetConfigParam(policy, node, "namespace;id="+namespace, name, value)
+}
+
+func parseNamespaceConfig(namespace string, response string) (*NamespaceConfig, error) {
+	params := parseInfoParams(response)
+	res := &NamespaceConfig{Namespace: namespace, Params: params}
+
+	var err error
+	intParam := func(name string, dst *int) {
+		if v, exists := params[name]; exists && err == nil {
+			if *dst, err = strconv.Atoi(v); err != nil {
+				err = NewAerospikeError(PARSE_ERROR, "Invalid value for namespace config parameter `"+name+"`: "+v)
+			}
+		}
+	}
+	int64Param := func(name string, dst *int64) {
+		if v, exists := params[name]; exists && err == nil {
+			if *dst, err = strconv.ParseInt(v, 10, 64); err != nil {
+				err = NewAerospikeError(PARSE_ERROR, "Invalid value for namespace config parameter `"+name+"`: "+v)
+			}
+		}
+	}
+	boolParam := func(name string, dst *bool) {
+		if v, exists := params[name]; exists && err == nil {
+			if *dst, err = strconv.ParseBool(v); err != nil {
+				err = NewAerospikeError(PARSE_ERROR, "Invalid value for namespace config parameter `"+name+"`: "+v)
+			}
+		}
+	}
+
+	intParam("replication-factor", &res.ReplicationFactor)
+	int64Param("memory-size", &res.MemorySize)
+	int64Param("default-ttl", &res.DefaultTTL)
+	int64Param("max-ttl", &res.MaxTTL)
+	intParam("high-water-disk-pct", &res.HighWaterDiskPct)
+	intParam("high-water-memory-pct", &res.HighWaterMemoryPct)
+	intParam("stop-writes-pct", &res.StopWritesPct)
+	intParam("evict-tenths-pct", &res.EvictTenthsPct)
+	boolParam("single-bin", &res.SingleBin)
+	boolParam("storage-engine.data-in-memory", &res.DataInMemory)
+	intParam("storage-engine.defrag-lwm-pct", &res.DefragLwmPct)
+
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -0,0 +1,52 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Namespace Config Test", func() {
+
+	It("should parse the namespace config into typed fields", func() {
+		info := "memory-size=4294967296;replication-factor=2;default-ttl=2592000;max-ttl=0;" +
+			"high-water-disk-pct=50;high-water-memory-pct=60;stop-writes-pct=90;evict-tenths-pct=5;" +
+			"single-bin=false;storage-engine=device;storage-engine.data-in-memory=true;storage-engine.defrag-lwm-pct=50"
+
+		config, err := parseNamespaceConfig("test", info)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(config.Namespace).To(Equal("test"))
+		Expect(config.MemorySize).To(Equal(int64(4294967296)))
+		Expect(config.ReplicationFactor).To(Equal(2))
+		Expect(config.DefaultTTL).To(Equal(int64(2592000)))
+		Expect(config.MaxTTL).To(Equal(int64(0)))
+		Expect(config.HighWaterDiskPct).To(Equal(50))
+		Expect(config.HighWaterMemoryPct).To(Equal(60))
+		Expect(config.StopWritesPct).To(Equal(90))
+		Expect(config.EvictTenthsPct).To(Equal(5))
+		Expect(config.SingleBin).To(BeFalse())
+		Expect(config.DataInMemory).To(BeTrue())
+		Expect(config.DefragLwmPct).To(Equal(50))
+		Expect(config.Params["storage-engine"]).To(Equal("device"))
+	})
+
+	It("should return an error for invalid numeric values", func() {
+		_, err := parseNamespaceConfig("test", "replication-factor=two")
+		Expect(err).To(HaveOccurred())
+	})
+
+})
@@ -0,0 +1,189 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"time"
+)
+
+// NamespaceStorage determines how a namespace stores its records.
+type NamespaceStorage int
+
+const (
+	// STORAGE_IN_MEMORY is a namespace holding its data in memory.
+	// Commands are expected to complete within a few milliseconds.
+	STORAGE_IN_MEMORY NamespaceStorage = iota
+
+	// STORAGE_SSD is a namespace reading its data from SSD or other
+	// slower devices, e.g. an archival namespace.
+	STORAGE_SSD
+)
+
+// NamespaceHint adjusts the default policies for commands on a namespace.
+// It only applies to commands called without a policy; explicitly passed
+// policies are always used as is.
+type NamespaceHint struct {
+	// Storage of the namespace.
+	Storage NamespaceStorage
+
+	// Timeout overrides the timeout of the default policies, if not zero.
+	Timeout time.Duration
+
+	// MaxRetries overrides the maximum retries of the default policies,
+	// if not negative.
+	MaxRetries int
+
+	// SleepBetweenRetries overrides the sleep between retries of the default
+	// policies, if not zero.
+	SleepBetweenRetries time.Duration
+
+	// HedgeDelay overrides the hedge delay of the default policies, if not
+	// zero. A negative delay disables hedging on the namespace.
+	HedgeDelay time.Duration
+}
+
+// NewNamespaceHint generates a NamespaceHint with values suited for the storage.
+func NewNamespaceHint(storage NamespaceStorage) *NamespaceHint {
+	switch storage {
+	case STORAGE_IN_MEMORY:
+		// fail fast and retry quickly
+		return &NamespaceHint{
+			Storage:             storage,
+			Timeout:             50 * time.Millisecond,
+			MaxRetries:          2,
+			SleepBetweenRetries: time.Millisecond,
+		}
+	default:
+		// give devices time to respond
+		return &NamespaceHint{
+			Storage:             storage,
+			Timeout:             2 * time.Second,
+			MaxRetries:          3,
+			SleepBetweenRetries: 100 * time.Millisecond,
+		}
+	}
+}
+
+// apply sets the values of the hint on the policy.
+func (h *NamespaceHint) apply(policy *BasePolicy) {
+	if h.Timeout != 0 {
+		policy.Timeout = h.Timeout
+	}
+	if h.MaxRetries >= 0 {
+		policy.MaxRetries = h.MaxRetries
+	}
+	if h.SleepBetweenRetries != 0 {
+		policy.SleepBetweenRetries = h.SleepBetweenRetries
+	}
+	if h.HedgeDelay != 0 {
+		policy.HedgeDelay = h.HedgeDelay
+	}
+}
+
+// SetNamespaceHint registers the hint for the namespace. Commands on the
+// namespace called without a policy use the default policies of the client
+// adjusted by the hint. A nil hint removes the hint of the namespace.
+func (clnt *Client) SetNamespaceHint(namespace string, hint *NamespaceHint) {
+	clnt.namespaceHintsMutex.Lock()
+	defer clnt.namespaceHintsMutex.Unlock()
+
+	// copy on write, so readers don't need to copy
+	hints := make(map[string]*NamespaceHint, len(clnt.namespaceHints)+1)
+	for ns, h := range clnt.namespaceHints {
+		hints[ns] = h
+	}
+
+	if hint == nil {
+		delete(hints, namespace)
+	} else {
+		h := *hint
+		hints[namespace] = &h
+	}
+	clnt.namespaceHints = hints
+}
+
+// GetNamespaceHint returns the hint registered for the namespace, or nil.
+func (clnt *Client) GetNamespaceHint(namespace string) *NamespaceHint {
+	clnt.namespaceHintsMutex.RLock()
+	hint := clnt.namespaceHints[namespace]
+	clnt.namespaceHintsMutex.RUnlock()
+
+	if hint == nil {
+		return nil
+	}
+	res := *hint
+	return &res
+}
+
+func (clnt *Client) namespaceHint(namespace string) *NamespaceHint {
+	clnt.namespaceHintsMutex.RLock()
+	hint := clnt.namespaceHints[namespace]
+	clnt.namespaceHintsMutex.RUnlock()
+	return hint
+}
+
+// getUsablePolicyFor returns a copy of the policy, or of the default policy
+// registered for the set or namespace, or of the default policy adjusted by
+// the hint of the namespace if the policy is nil.
+func (clnt *Client) getUsablePolicyFor(policy *BasePolicy, namespace, setName string) *BasePolicy {
+	if policy != nil {
+		return policy.Clone()
+	}
+
+	if policy = clnt.scopedReadPolicy(namespace, setName); policy != nil {
+		return policy.Clone()
+	}
+
+	policy = clnt.getUsablePolicy(nil)
+	if hint := clnt.namespaceHint(namespace); hint != nil {
+		hint.apply(policy)
+	}
+	return policy
+}
+
+// getUsableWritePolicyFor returns a copy of the policy, or of the default
+// write policy registered for the set or namespace, or of the default write
+// policy adjusted by the hint of the namespace if the policy is nil.
+func (clnt *Client) getUsableWritePolicyFor(policy *WritePolicy, namespace, setName string) *WritePolicy {
+	if policy != nil {
+		return policy.Clone()
+	}
+
+	if policy = clnt.scopedWritePolicy(namespace, setName); policy != nil {
+		return policy.Clone()
+	}
+
+	policy = clnt.getUsableWritePolicy(nil)
+	if hint := clnt.namespaceHint(namespace); hint != nil {
+		hint.apply(&policy.BasePolicy)
+	}
+	return policy
+}
+
+// keysNamespace returns the namespace of the keys, or an empty string if
+// they belong to several namespaces.
+func keysNamespace(keys []*Key) string {
+	if len(keys) == 0 {
+		return ""
+	}
+
+	namespace := keys[0].namespace
+	for _, key := range keys[1:] {
+		if key.namespace != namespace {
+			return ""
+		}
+	}
+	return namespace
+}
@@ -0,0 +1,91 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Namespace Hint Test", func() {
+
+	var client *Client
+
+	BeforeEach(func() {
+		client = &Client{
+			DefaultPolicy:      NewPolicy(),
+			DefaultWritePolicy: NewWritePolicy(0, 0),
+		}
+	})
+
+	It("should adjust the default policies of the namespace", func() {
+		client.SetNamespaceHint("hot", NewNamespaceHint(STORAGE_IN_MEMORY))
+		client.SetNamespaceHint("cold", &NamespaceHint{Storage: STORAGE_SSD, Timeout: 5 * time.Second, MaxRetries: -1})
+
+		policy := client.getUsablePolicyFor(nil, "hot", "")
+		Expect(policy.Timeout).To(Equal(50 * time.Millisecond))
+		Expect(policy.SleepBetweenRetries).To(Equal(time.Millisecond))
+
+		wpolicy := client.getUsableWritePolicyFor(nil, "cold", "")
+		Expect(wpolicy.Timeout).To(Equal(5 * time.Second))
+		Expect(wpolicy.MaxRetries).To(Equal(client.DefaultWritePolicy.MaxRetries))
+		Expect(wpolicy.SleepBetweenRetries).To(Equal(client.DefaultWritePolicy.SleepBetweenRetries))
+
+		// the default policies are not modified
+		Expect(client.DefaultPolicy.Timeout).To(Equal(time.Duration(0)))
+		Expect(client.getUsablePolicyFor(nil, "other", "")).To(Equal(client.DefaultPolicy))
+	})
+
+	It("should adjust the hedge delay of the default policies of the namespace", func() {
+		client.DefaultPolicy.HedgeDelay = 10 * time.Millisecond
+		client.SetNamespaceHint("hot", &NamespaceHint{MaxRetries: -1, HedgeDelay: 2 * time.Millisecond})
+		client.SetNamespaceHint("cold", &NamespaceHint{MaxRetries: -1, HedgeDelay: -1})
+		client.SetNamespaceHint("other", &NamespaceHint{MaxRetries: -1})
+
+		Expect(client.getUsablePolicyFor(nil, "hot", "").HedgeDelay).To(Equal(2 * time.Millisecond))
+		Expect(client.getUsablePolicyFor(nil, "cold", "").HedgeDelay).To(BeNumerically("<", 0))
+		Expect(client.getUsablePolicyFor(nil, "other", "").HedgeDelay).To(Equal(10 * time.Millisecond))
+	})
+
+	It("should not adjust explicitly passed policies", func() {
+		client.SetNamespaceHint("hot", NewNamespaceHint(STORAGE_IN_MEMORY))
+
+		policy := NewPolicy()
+		Expect(client.getUsablePolicyFor(policy, "hot", "")).To(Equal(policy))
+	})
+
+	It("should use the hint for batches on a single namespace only", func() {
+		key1, _ := NewKey("hot", "demo", 1)
+		key2, _ := NewKey("hot", "demo", 2)
+		key3, _ := NewKey("cold", "demo", 3)
+
+		Expect(keysNamespace([]*Key{key1, key2})).To(Equal("hot"))
+		Expect(keysNamespace([]*Key{key1, key3})).To(Equal(""))
+		Expect(keysNamespace(nil)).To(Equal(""))
+	})
+
+	It("should remove hints and keep registered hints immutable", func() {
+		hint := NewNamespaceHint(STORAGE_SSD)
+		client.SetNamespaceHint("cold", hint)
+		hint.Timeout = time.Hour
+		Expect(client.GetNamespaceHint("cold").Timeout).To(Equal(2 * time.Second))
+
+		client.SetNamespaceHint("cold", nil)
+		Expect(client.GetNamespaceHint("cold")).To(BeNil())
+	})
+
+})
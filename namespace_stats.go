@@ -0,0 +1,135 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	. "github.com/THE108/aerospike-client-go/types"
+)
+
+// NamespaceStats holds the statistics of a namespace on a node, parsed from
+// the `namespace/<ns>` info command. Statistics the server does not report
+// are zero.
+type NamespaceStats struct {
+	// Namespace is the name of the namespace.
+	Namespace string
+
+	// Time is when the statistics were parsed.
+	Time time.Time
+
+	// Objects is the number of records of the namespace on the node.
+	Objects int64 `info:"objects"`
+
+	// Tombstones is the number of tombstones of the namespace on the node.
+	Tombstones int64 `info:"tombstones"`
+
+	// MasterObjects is the number of records the node is master of.
+	MasterObjects int64 `info:"master_objects"`
+
+	// ProleObjects is the number of replica records on the node.
+	ProleObjects int64 `info:"prole_objects"`
+
+	// MemoryUsedBytes is the memory used by the namespace in bytes.
+	MemoryUsedBytes int64 `info:"memory_used_bytes"`
+
+	// MemoryFreePct is the percentage of the namespace memory which is free.
+	MemoryFreePct float64 `info:"memory_free_pct"`
+
+	// DeviceUsedBytes is the storage used by the namespace in bytes.
+	DeviceUsedBytes int64 `info:"device_used_bytes"`
+
+	// DeviceFreePct is the percentage of the namespace storage which is free.
+	DeviceFreePct float64 `info:"device_free_pct"`
+
+	// ClientReadSuccess is the number of successful reads.
+	ClientReadSuccess int64 `info:"client_read_success"`
+
+	// ClientReadError is the number of failed reads.
+	ClientReadError int64 `info:"client_read_error"`
+
+	// ClientReadNotFound is the number of reads of records which do not exist.
+	ClientReadNotFound int64 `info:"client_read_not_found"`
+
+	// ClientWriteSuccess is the number of successful writes.
+	ClientWriteSuccess int64 `info:"client_write_success"`
+
+	// ClientWriteError is the number of failed writes.
+	ClientWriteError int64 `info:"client_write_error"`
+
+	// ClientDeleteSuccess is the number of successful deletes.
+	ClientDeleteSuccess int64 `info:"client_delete_success"`
+
+	// ClientTsvcTimeout is the number of commands which timed out in the transaction queue.
+	ClientTsvcTimeout int64 `info:"client_tsvc_timeout"`
+
+	// EvictedObjects is the number of records evicted.
+	EvictedObjects int64 `info:"evicted_objects"`
+
+	// ExpiredObjects is the number of records expired.
+	ExpiredObjects int64 `info:"expired_objects"`
+
+	// MigrateTxPartitionsRemaining is the number of partitions remaining to send.
+	MigrateTxPartitionsRemaining int64 `info:"migrate_tx_partitions_remaining"`
+
+	// MigrateRxPartitionsRemaining is the number of partitions remaining to receive.
+	MigrateRxPartitionsRemaining int64 `info:"migrate_rx_partitions_remaining"`
+
+	// StopWrites is true if the namespace does not accept writes.
+	StopWrites bool `info:"stop_writes"`
+
+	// HwmBreached is true if a high water mark is breached, and records are evicted.
+	HwmBreached bool `info:"hwm_breached"`
+
+	// Other holds the statistics without a field, and the values of fields
+	// which could not be parsed, like in NodeStats.
+	Other map[string]interface{}
+}
+
+// namespaceStatsFields maps statistic names to the index of their NamespaceStats field.
+var namespaceStatsFields = statsFields(reflect.TypeOf(NamespaceStats{}))
+
+// ParseNamespaceStats parses the response of the `namespace/<ns>` info command.
+func ParseNamespaceStats(namespace string, response string) *NamespaceStats {
+	stats := &NamespaceStats{Namespace: namespace, Time: time.Now()}
+	stats.Other = parseStats(parseInfoParams(response), reflect.ValueOf(stats).Elem(), namespaceStatsFields)
+	return stats
+}
+
+// RequestNamespaceStats returns the statistics of a namespace on the specified node.
+// Default InfoPolicy will be used.
+func RequestNamespaceStats(node *Node, namespace string) (*NamespaceStats, error) {
+	return RequestNamespaceStatsWithPolicy(nil, node, namespace)
+}
+
+// RequestNamespaceStatsWithPolicy returns the statistics of a namespace on the specified node.
+// If the policy is nil, the default InfoPolicy will be used.
+func RequestNamespaceStatsWithPolicy(policy *InfoPolicy, node *Node, namespace string) (*NamespaceStats, error) {
+	command := "namespace/" + namespace
+	infoMap, err := RequestNodeInfoWithPolicy(policy, node, command)
+	if err != nil {
+		return nil, err
+	}
+
+	// unknown namespaces are reported as `type=unknown` by the server
+	response := infoMap[command]
+	if lower := strings.ToLower(response); lower == "" || strings.HasPrefix(lower, "error") || strings.HasPrefix(lower, "type=unknown") {
+		return nil, NewAerospikeError(INVALID_NAMESPACE, "Failed to get statistics for namespace `"+namespace+"`: "+response)
+	}
+
+	return ParseNamespaceStats(namespace, response), nil
+}
@@ -0,0 +1,81 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Namespace Stats Test", func() {
+
+	It("should parse namespace statistics into typed fields", func() {
+		stats := ParseNamespaceStats("test", "objects=10;memory_free_pct=99.5;client_read_error=3;stop_writes=false;hwm_breached=true;nsup_cycle_duration=2")
+
+		Expect(stats.Namespace).To(Equal("test"))
+		Expect(stats.Objects).To(Equal(int64(10)))
+		Expect(stats.MemoryFreePct).To(Equal(99.5))
+		Expect(stats.ClientReadError).To(Equal(int64(3)))
+		Expect(stats.StopWrites).To(BeFalse())
+		Expect(stats.HwmBreached).To(BeTrue())
+		Expect(stats.Other).To(Equal(map[string]interface{}{"nsup_cycle_duration": int64(2)}))
+	})
+
+	It("should compute the rates of counters between snapshots", func() {
+		prev := ParseNamespaceStats("test", "client_read_success=100;client_write_success=50;custom_counter=10;evicted_objects=7")
+		cur := ParseNamespaceStats("test", "client_read_success=300;client_write_success=50;custom_counter=40;evicted_objects=2")
+		cur.Time = prev.Time.Add(2 * time.Second)
+
+		delta, err := StatsDelta(prev, cur)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(delta.Interval).To(Equal(2 * time.Second))
+		Expect(delta.Rate("client_read_success")).To(Equal(100.0))
+		Expect(delta.Rate("client_write_success")).To(Equal(0.0))
+		Expect(delta.Rate("custom_counter")).To(Equal(15.0))
+
+		// counters reset by a restart are left out
+		_, exists := delta.Rates["evicted_objects"]
+		Expect(exists).To(BeFalse())
+	})
+
+	It("should compute node statistics rates", func() {
+		prev := ParseNodeStats(map[string]string{"client_connections": "10", "batch_index_initiate": "5"})
+		cur := ParseNodeStats(map[string]string{"client_connections": "20", "batch_index_initiate": "25"})
+		cur.Time = prev.Time.Add(10 * time.Second)
+
+		delta, err := StatsDelta(prev, cur)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(delta.Rate("client_connections")).To(Equal(1.0))
+		Expect(delta.Rate("batch_index_initiate")).To(Equal(2.0))
+	})
+
+	It("should reject snapshots out of order or of different types", func() {
+		prev := ParseNodeStats(map[string]string{})
+		cur := ParseNodeStats(map[string]string{})
+		cur.Time = prev.Time.Add(-time.Second)
+
+		_, err := StatsDelta(prev, cur)
+		Expect(err).To(HaveOccurred())
+
+		_, err = StatsDelta(prev, ParseNamespaceStats("test", ""))
+		Expect(err).To(HaveOccurred())
+
+		_, err = StatsDelta(nil, cur)
+		Expect(err).To(HaveOccurred())
+	})
+
+})
@@ -30,7 +30,9 @@ const (
 	_FULL_HEALTH = 100
 )
 
-// Node represents an Aerospike Database Server Node
+// Node represents an Aerospike Database Server Node.
+// Node is safe for concurrent use; its state is updated by the cluster tend
+// goroutine.
 type Node struct {
 	cluster *Cluster
 	name    string
@@ -43,35 +45,91 @@ type Node struct {
 	health          *AtomicInt //AtomicInteger
 
 	partitionGeneration *AtomicInt
+	peersGeneration     *AtomicInt
 	refreshCount        *AtomicInt
 	referenceCount      *AtomicInt
 	responded           *AtomicBool
 	useNewInfo          bool
 	active              *AtomicBool
 	mutex               sync.RWMutex
+
+	// build of the server, reported at the creation of the node
+	build string
+
+	// features reported by the node, and features it stopped reporting
+	features           map[string]struct{}
+	removedFeatures    map[string]struct{}
+	hasRemovedFeatures *AtomicBool
+	featureMutex       sync.RWMutex
+
+	// limits the number of connections being opened concurrently; nil if unlimited
+	connectionOpens chan struct{}
+
+	// load statistics for NodeSelector
+	pendingCommands *AtomicInt
+	latencyEMA      *AtomicInt
+
+	// adaptive sub-batch size
+	batchSize *AtomicInt
+
+	// connection budget of the server, refreshed on tend
+	serverConnections *AtomicInt
+	serverFdMax       *AtomicInt
+	tendConnections   *AtomicInt
+
+	// peers last reported by the node; only accessed by the tend goroutine
+	peers []*Host
+
+	// connection reserved for tending, outside of the pool
+	tendConn      *Connection
+	tendConnMutex sync.Mutex
 }
 
 // NewNode initializes a server node with connection parameters.
 func newNode(cluster *Cluster, nv *nodeValidator) *Node {
-	return &Node{
+	policy := cluster.policy()
+	var connectionOpens chan struct{}
+	if policy.MaxConcurrentConnectionOpens > 0 {
+		connectionOpens = make(chan struct{}, policy.MaxConcurrentConnectionOpens)
+	}
+
+	nd := &Node{
 		cluster:    cluster,
 		name:       nv.name,
 		aliases:    nv.aliases,
 		address:    nv.address,
 		useNewInfo: nv.useNewInfo,
+		build:      nv.build,
 
 		// Assign host to first IP alias because the server identifies nodes
 		// by IP address (not hostname).
 		host:                nv.aliases[0],
-		connections:         NewAtomicQueue(cluster.clientPolicy.ConnectionQueueSize),
+		connections:         NewAtomicQueue(policy.ConnectionQueueSize),
 		connectionCount:     NewAtomicInt(0),
 		health:              NewAtomicInt(_FULL_HEALTH),
 		partitionGeneration: NewAtomicInt(-1),
+		peersGeneration:     NewAtomicInt(-1),
 		referenceCount:      NewAtomicInt(0),
 		refreshCount:        NewAtomicInt(0),
 		responded:           NewAtomicBool(false),
 		active:              NewAtomicBool(true),
+		removedFeatures:     map[string]struct{}{},
+		hasRemovedFeatures:  NewAtomicBool(false),
+		connectionOpens:     connectionOpens,
+		pendingCommands:     NewAtomicInt(0),
+		latencyEMA:          NewAtomicInt(0),
+		batchSize:           NewAtomicInt(initialBatchSize(policy)),
+		serverConnections:   NewAtomicInt(-1),
+		serverFdMax:         NewAtomicInt(-1),
+		tendConnections:     NewAtomicInt(0),
 	}
+
+	// features are known before the first refresh, so commands sent
+	// before it are checked as well
+	if nv.hasFeatures {
+		nd.updateFeatures(nv.features)
+	}
+	return nd
 }
 
 // Refresh requests current status from server node, and updates node with the result.
@@ -80,46 +138,43 @@ func (nd *Node) Refresh() ([]*Host, error) {
 
 	nd.refreshCount.IncrementAndGet()
 
-	conn, err := nd.GetConnection(1 * time.Second)
+	conn, err := nd.getTendConnection(1 * time.Second)
 	if err != nil {
 		return nil, err
 	}
 
-	infoMap, err := RequestInfo(conn, "node", "partition-generation", "services")
+	commands := append([]string{"node", "partition-generation", "features"}, nd.friendsInfo()...)
+	commands = append(commands, nd.connectionBudgetInfo()...)
+	infoMap, err := RequestInfo(conn, commands...)
 	if err != nil {
-		nd.InvalidateConnection(conn)
+		nd.closeTendConnection()
 		nd.DecreaseHealth()
 		return nil, err
 	}
 
 	if err := nd.verifyNodeName(infoMap); err != nil {
-		nd.PutConnection(conn)
 		return nil, err
 	}
 	nd.RestoreHealth()
 	nd.responded.Set(true)
+	nd.refreshFeatures(infoMap["features"])
+	nd.refreshConnectionBudget(infoMap)
 
-	if friends, err = nd.addFriends(infoMap); err != nil {
-		nd.PutConnection(conn)
+	if nd.SupportsFeature(FeaturePeers) {
+		friends, err = nd.addPeers(conn, infoMap)
+	} else {
+		friends, err = nd.addFriends(infoMap)
+	}
+	if err != nil {
+		nd.closeTendConnection()
 		return nil, err
 	}
 
 	if err := nd.updatePartitions(conn, infoMap); err != nil {
-		nd.InvalidateConnection(conn)
+		nd.closeTendConnection()
 		return nil, err
 	}
 
-	// Suppose there is a request peak, and we create lots of connections to
-	// handle those requests. We want to keep those connections around while the
-	// peak is taking place. When the peak is over, we hopefully will want to
-	// close and those connections, and remove pressure from our side (the
-	// client) and servers. Because the connection pool is a FIFO queue, if we
-	// refresh the connection here, we may end up preventing lots of unused
-	// connections from going idle, and therefore preventing them from being
-	// closed. We should keep the number of connections to servers as low as
-	// possible when there is no need for them. For that reason, we just put the
-	// connection back into the pool without refreshing it.
-	nd.PutConnection(conn)
 	return friends, nil
 }
 
@@ -132,8 +187,10 @@ func (nd *Node) verifyNodeName(infoMap map[string]string) error {
 	}
 
 	if !(nd.name == infoName) {
-		// Set node to inactive immediately.
+		// Set node to inactive immediately. Another node answers at the
+		// address now, so the tend connection is not reused.
 		nd.active.Set(false)
+		nd.closeTendConnection()
 		return NewAerospikeError(INVALID_NODE_ERROR, "Node name has changed. Old="+nd.name+" New="+infoName)
 	}
 	return nil
@@ -148,6 +205,7 @@ func (nd *Node) addFriends(infoMap map[string]string) ([]*Host, error) {
 	}
 
 	friendNames := strings.Split(friendString, ";")
+	hosts := make([]*Host, 0, len(friendNames))
 
 	for _, friend := range friendNames {
 
@@ -161,7 +219,18 @@ func (nd *Node) addFriends(infoMap map[string]string) ([]*Host, error) {
 		host := friendInfo[0]
 		port, _ := strconv.Atoi(friendInfo[1])
 
-		alias := NewHost(host, port)
+		hosts = append(hosts, NewHost(host, port))
+	}
+
+	return nd.referenceFriends(hosts), nil
+}
+
+// referenceFriends counts the references to the known nodes among the hosts,
+// and returns the hosts of the nodes which are not known yet.
+func (nd *Node) referenceFriends(hosts []*Host) []*Host {
+	var friends []*Host
+
+	for _, alias := range hosts {
 		node := nd.cluster.findAlias(alias)
 
 		if node != nil {
@@ -177,7 +246,7 @@ func (nd *Node) addFriends(infoMap map[string]string) ([]*Host, error) {
 		}
 	}
 
-	return friends, nil
+	return friends
 }
 
 func (nd *Node) findAlias(friends []*Host, alias *Host) bool {
@@ -212,26 +281,40 @@ func (nd *Node) updatePartitions(conn *Connection, infoMap map[string]string) er
 // GetConnection gets a connection to the node.
 // If no pooled connection is available, a new connection will be created.
 func (nd *Node) GetConnection(timeout time.Duration) (conn *Connection, err error) {
-	tBegin := time.Now()
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+	return nd.getConnectionWithDeadline(deadline)
+}
+
+// getConnectionWithDeadline gets a connection to the node, whose socket
+// timeout is set to the deadline. If a new connection is needed, the time
+// to connect and authenticate counts against the deadline.
+// A zero deadline means no timeout.
+func (nd *Node) getConnectionWithDeadline(deadline time.Time) (conn *Connection, err error) {
 	pollTries := 0
+	policy := nd.cluster.policy()
 
 L:
-	for timeout == 0 || time.Now().Sub(tBegin) <= timeout {
+	for deadline.IsZero() || !time.Now().After(deadline) {
 
 		if t := nd.connections.Poll(); t != nil {
 			conn = t.(*Connection)
-			if conn.IsConnected() && !conn.isIdle() {
-				if err := conn.SetTimeout(timeout); err == nil {
+			if conn.IsConnected() && !conn.isIdle() && nd.pingConnection(conn, deadline) == nil {
+				if err := conn.setDeadline(deadline); err == nil {
 					return conn, nil
 				}
 			}
 			nd.InvalidateConnection(conn)
 		}
 
-		// if connection count is limited and enough connections are already created, don't create a new one
-		if nd.cluster.clientPolicy.LimitConnectionsToQueueSize && nd.connectionCount.Get() >= nd.cluster.clientPolicy.ConnectionQueueSize {
+		// if connection count is limited and enough connections are already created, don't create a new one;
+		// the same applies if the file descriptor budget of the node is exhausted
+		if (policy.LimitConnectionsToQueueSize && nd.connectionCount.Get() >= policy.ConnectionQueueSize) ||
+			nd.connectionBudgetExhausted() {
 			// will avoid an infinite loop
-			if timeout != 0 || pollTries < 10 {
+			if !deadline.IsZero() || pollTries < 10 {
 				// 10 reteies, each waits for 100us for a total of 1 milliseconds
 				time.Sleep(time.Microsecond * 100)
 				pollTries++
@@ -240,27 +323,27 @@ L:
 			break L
 		}
 
-		if conn, err = NewConnection(nd.address, nd.cluster.clientPolicy.Timeout); err != nil {
-			return nil, err
+		// too many connections are being opened to the node; wait for one
+		// of them, or for a connection to be put back into the pool
+		if nd.connectionOpens != nil {
+			select {
+			case nd.connectionOpens <- struct{}{}:
+			default:
+				time.Sleep(time.Microsecond * 100)
+				continue
+			}
 		}
 
-		// need to authenticate
-		if err = conn.Authenticate(nd.cluster.user, nd.cluster.Password()); err != nil {
-			// Socket not authenticated. Do not put back into pool.
-			conn.Close()
+		conn, err = nd.newConnection(deadline)
 
-			return nil, err
+		if nd.connectionOpens != nil {
+			<-nd.connectionOpens
 		}
 
-		if err = conn.SetTimeout(timeout); err != nil {
-			// Socket not authenticated. Do not put back into pool.
-			conn.Close()
+		if err != nil {
 			return nil, err
 		}
 
-		conn.setIdleTimeout(nd.cluster.clientPolicy.IdleTimeout)
-		conn.refresh()
-
 		nd.connectionCount.IncrementAndGet()
 		return conn, nil
 	}
@@ -268,6 +351,87 @@ L:
 	return nil, NewAerospikeError(NO_AVAILABLE_CONNECTIONS_TO_NODE)
 }
 
+// pingConnection validates a pooled connection which has not been used for
+// longer than ClientPolicy.IdlePingThreshold with an info request, since load
+// balancers and firewalls may drop idle connections without notice.
+func (nd *Node) pingConnection(conn *Connection, deadline time.Time) error {
+	policy := nd.cluster.policy()
+	threshold := policy.IdlePingThreshold
+	if threshold <= 0 || time.Now().Sub(conn.lastUsed) < threshold {
+		return nil
+	}
+
+	timeout := policy.Timeout
+	if !deadline.IsZero() {
+		if remaining := deadline.Sub(time.Now()); timeout <= 0 || remaining < timeout {
+			timeout = remaining
+		}
+	}
+	if timeout <= 0 {
+		return NewAerospikeError(TIMEOUT)
+	}
+
+	if err := conn.SetTimeout(timeout); err != nil {
+		return err
+	}
+
+	info, err := RequestInfo(conn, "node")
+	if err != nil {
+		Logger.Debug("Idle connection to node %s failed the ping: %s", nd, err)
+		return err
+	}
+
+	if info["node"] != nd.name {
+		return NewAerospikeError(INVALID_NODE_ERROR, "Idle connection to node "+nd.String()+" reached node `"+info["node"]+"`")
+	}
+
+	conn.refresh()
+	return nil
+}
+
+// newConnection opens and authenticates a new connection to the node
+// before the deadline.
+func (nd *Node) newConnection(deadline time.Time) (*Connection, error) {
+	policy := nd.cluster.policy()
+	connectTimeout := policy.Timeout
+	if !deadline.IsZero() {
+		remaining := deadline.Sub(time.Now())
+		if remaining <= 0 {
+			return nil, NewAerospikeError(TIMEOUT, "command timed out before a connection to node "+nd.String()+" could be opened")
+		}
+		if connectTimeout <= 0 || remaining < connectTimeout {
+			connectTimeout = remaining
+		}
+	}
+
+	conn, err := newConnectionWithPolicy(policy, nd.GetAddress(), nd.GetHost().TLSName, connectTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	// authentication must complete before the deadline as well
+	if err = conn.setDeadline(deadline); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	// need to authenticate
+	if err = nd.cluster.authenticate(conn); err != nil {
+		// Socket not authenticated. Do not put back into pool.
+		conn.Close()
+		return nil, err
+	}
+
+	if err = conn.setDeadline(deadline); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	conn.setIdleTimeout(policy.IdleTimeout)
+	conn.refresh()
+	return conn, nil
+}
+
 // PutConnection puts back a connection to the pool.
 // If connection pool is full, the connection will be
 // closed and discarded.
@@ -303,7 +467,40 @@ func (nd *Node) IsUnhealthy() bool {
 
 // GetHost retrieves host for the node.
 func (nd *Node) GetHost() *Host {
-	return nd.host
+	nd.mutex.RLock()
+	host := nd.host
+	nd.mutex.RUnlock()
+
+	return host
+}
+
+// GetAddress returns the address the node connections are established to.
+func (nd *Node) GetAddress() string {
+	nd.mutex.RLock()
+	address := nd.address
+	nd.mutex.RUnlock()
+
+	return address
+}
+
+// changeAddress moves the node to a new address, keeping its identity.
+// This happens when a node restarts and comes back on a different IP.
+// Pooled connections and the tend connection to the old address are
+// closed, and the node's aliases are replaced by the new host.
+func (nd *Node) changeAddress(nv *nodeValidator, host *Host) {
+	nd.mutex.Lock()
+	nd.host = host
+	nd.address = nv.address
+	nd.aliases = []*Host{host}
+	nd.useNewInfo = nv.useNewInfo
+	nd.mutex.Unlock()
+
+	// the restarted node reports its peers again
+	nd.peersGeneration.Set(-1)
+
+	nd.closeConnections()
+	nd.closeTendConnection()
+	nd.RestoreHealth()
 }
 
 // GetConnectionCount retrieves connection count
@@ -339,31 +536,37 @@ func (nd *Node) setAliases(aliases []*Host) {
 
 // AddAlias adds an alias for the node
 func (nd *Node) AddAlias(aliasToAdd *Host) {
-	// Aliases are only referenced in the cluster tend goroutine,
-	// so synchronization is not necessary.
-	aliases := nd.GetAliases()
-	if aliases == nil {
-		aliases = []*Host{}
-	}
-
-	aliases = append(aliases, aliasToAdd)
-	nd.setAliases(aliases)
+	nd.mutex.Lock()
+	// Copy on write; readers keep iterating the aliases they got.
+	aliases := make([]*Host, 0, len(nd.aliases)+1)
+	aliases = append(aliases, nd.aliases...)
+	nd.aliases = append(aliases, aliasToAdd)
+	nd.mutex.Unlock()
 }
 
 // Close marks node as inactice and closes all of its pooled connections.
 func (nd *Node) Close() {
 	nd.active.Set(false)
 	nd.closeConnections()
+	nd.closeTendConnection()
 }
 
 // String implements stringer interface
 func (nd *Node) String() string {
-	return nd.name + " " + nd.host.String()
+	return nd.name + " " + nd.GetHost().String()
+}
+
+// resizeConnectionPool changes the size of the connection pool. Pooled
+// connections which do not fit in the new size are closed.
+func (nd *Node) resizeConnectionPool(size int) {
+	for _, conn := range nd.connections.Resize(size) {
+		nd.InvalidateConnection(conn.(*Connection))
+	}
 }
 
 func (nd *Node) closeConnections() {
 	for conn := nd.connections.Poll(); conn != nil; conn = nd.connections.Poll() {
-		conn.(*Connection).Close()
+		nd.InvalidateConnection(conn.(*Connection))
 	}
 }
 
@@ -374,13 +577,13 @@ func (nd *Node) Equals(other *Node) bool {
 
 // MigrationInProgress determines if the node is participating in a data migration
 func (nd *Node) MigrationInProgress() (bool, error) {
-	values, err := RequestNodeStats(nd)
+	stats, err := RequestNodeStatsTyped(nil, nd)
 	if err != nil {
 		return false, err
 	}
 
 	// if the migration_progress_send exists and is not `0`, then migration is in progress
-	if migration, exists := values["migrate_progress_send"]; exists && migration != "0" {
+	if stats.MigrateProgressSend != 0 {
 		return true, nil
 	}
 
@@ -389,15 +592,12 @@ func (nd *Node) MigrationInProgress() (bool, error) {
 }
 
 // WaitUntillMigrationIsFinished will block until migration operations are finished.
+// A timeout of zero or less waits without a deadline.
 func (nd *Node) WaitUntillMigrationIsFinished(timeout time.Duration) (err error) {
-	if timeout <= 0 {
-		timeout = _NO_TIMEOUT
-	}
-	done := make(chan error)
+	// buffered, so the goroutine can return once the deadline has passed
+	done := make(chan error, 1)
 
 	go func() {
-		// this function is guaranteed to return after timeout
-		// no go routines will be leaked
 		for {
 			if res, err := nd.MigrationInProgress(); err != nil || !res {
 				done <- err
@@ -406,9 +606,8 @@ func (nd *Node) WaitUntillMigrationIsFinished(timeout time.Duration) (err error)
 		}
 	}()
 
-	dealine := time.After(timeout)
 	select {
-	case <-dealine:
+	case <-timeoutChan(timeout):
 		return NewAerospikeError(TIMEOUT)
 	case err = <-done:
 		return err
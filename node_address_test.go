@@ -0,0 +1,156 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike_test
+
+import (
+	"sync"
+	"time"
+
+	. "github.com/THE108/aerospike-client-go"
+	"github.com/THE108/aerospike-client-go/aerotest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Node address changes", func() {
+
+	const movedName = "BB9AEROTEST0002"
+
+	var other, moving, restarted *aerotest.Server
+	var client *Client
+	var node *Node
+	var key *Key
+
+	var mutex sync.Mutex
+	var changes [][2]Host
+
+	var newServer = func(address string, namespace string) *aerotest.Server {
+		srv, err := aerotest.NewServerOnAddress(address, namespace)
+		Expect(err).ToNot(HaveOccurred())
+		srv.SetFeatures("peers", "pipelining", "replicas-master")
+		return srv
+	}
+
+	// the other node serves another namespace, so the partitions of "test"
+	// are only owned by the node which changes its address
+	var testPartitionNode = func() *Node {
+		owner, err := client.Cluster().GetNode(NewPartition("test", 0))
+		Expect(err).ToNot(HaveOccurred())
+		return owner
+	}
+
+	var waitForTends = func(count int) {
+		time.Sleep(time.Duration(count) * 20 * time.Millisecond)
+	}
+
+	BeforeEach(func() {
+		changes = nil
+		restarted = nil
+		key, _ = NewKey("test", "demo", 1)
+
+		other = newServer("127.0.0.1:0", "other")
+		moving = newServer("127.0.0.1:0", "test")
+		moving.SetNodeName(movedName)
+		other.AddPeer(moving)
+		moving.AddPeer(other)
+
+		policy := NewClientPolicy()
+		policy.TendInterval = 20 * time.Millisecond
+		policy.Timeout = 200 * time.Millisecond
+		policy.NodeAddressChanged = func(node *Node, oldHost, newHost *Host) {
+			mutex.Lock()
+			changes = append(changes, [2]Host{*oldHost, *newHost})
+			mutex.Unlock()
+		}
+
+		// the moving node is the seed, so it is refreshed before the other
+		// node references it as its peer in every tend
+		var err error
+		client, err = NewClientWithPolicy(policy, moving.Host(), moving.Port())
+		Expect(err).ToNot(HaveOccurred())
+
+		node, err = client.Cluster().GetNodeByName(movedName)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(testPartitionNode()).To(BeIdenticalTo(node))
+	})
+
+	AfterEach(func() {
+		client.Close()
+		other.Close()
+		moving.Close()
+		if restarted != nil {
+			restarted.Close()
+		}
+	})
+
+	It("must move a node restarted on a new address", func() {
+		oldHost := *node.GetHost()
+
+		Expect(moving.Close()).ToNot(HaveOccurred())
+		restarted = newServer("127.0.0.1:0", "test")
+		restarted.SetNodeName(movedName)
+		restarted.AddPeer(other)
+		other.SetPeers(restarted)
+
+		newHost := *NewHost(restarted.Host(), restarted.Port())
+		for deadline := time.Now().Add(3 * time.Second); *node.GetHost() != newHost; {
+			Expect(time.Now().Before(deadline)).To(BeTrue(), "the node was not moved")
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		mutex.Lock()
+		Expect(changes).To(Equal([][2]Host{{oldHost, newHost}}))
+		mutex.Unlock()
+
+		// the node keeps its identity and its partitions
+		moved, err := client.Cluster().GetNodeByName(movedName)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(moved).To(BeIdenticalTo(node))
+		Expect(len(client.GetNodes())).To(Equal(2))
+		Expect(node.GetAliases()).To(Equal([]*Host{&newHost}))
+		Expect(testPartitionNode()).To(BeIdenticalTo(node))
+
+		// commands open their connections at the new address
+		Expect(client.Put(nil, key, BinMap{"i": 1})).ToNot(HaveOccurred())
+		Expect(restarted.Len("test")).To(Equal(1))
+	})
+
+	It("must not move a node which does not respond for a while", func() {
+		oldHost := *node.GetHost()
+		aliases := node.GetAliases()
+
+		Expect(moving.Close()).ToNot(HaveOccurred())
+		waitForTends(5)
+		Expect(node.IsActive()).To(BeTrue())
+
+		restarted = newServer(oldHost.String(), "test")
+		restarted.SetNodeName(movedName)
+		restarted.AddPeer(other)
+		waitForTends(5)
+
+		mutex.Lock()
+		Expect(changes).To(BeEmpty())
+		mutex.Unlock()
+
+		Expect(*node.GetHost()).To(Equal(oldHost))
+		Expect(node.GetAliases()).To(Equal(aliases))
+		Expect(testPartitionNode()).To(BeIdenticalTo(node))
+
+		Expect(client.Put(nil, key, BinMap{"i": 1})).ToNot(HaveOccurred())
+		Expect(restarted.Len("test")).To(Equal(1))
+	})
+
+})
@@ -0,0 +1,264 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"io"
+	"net"
+	"time"
+
+	. "github.com/THE108/aerospike-client-go/types"
+	. "github.com/THE108/aerospike-client-go/types/atomic"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Node GetConnection Test", func() {
+
+	var listener net.Listener
+	var node *Node
+	var policy *ClientPolicy
+
+	BeforeEach(func() {
+		var err error
+		listener, err = net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).ToNot(HaveOccurred())
+
+		node = &Node{
+			cluster:         &Cluster{},
+			name:            "BB9000000000001",
+			host:            NewHost("127.0.0.1", 3000),
+			address:         listener.Addr().String(),
+			connections:     NewAtomicQueue(4),
+			connectionCount: NewAtomicInt(0),
+			active:          NewAtomicBool(true),
+			connectionOpens: make(chan struct{}, 1),
+
+			serverConnections: NewAtomicInt(-1),
+			serverFdMax:       NewAtomicInt(-1),
+			tendConnections:   NewAtomicInt(0),
+		}
+		policy = NewClientPolicy()
+		node.cluster.setPolicy(policy)
+	})
+
+	AfterEach(func() {
+		listener.Close()
+	})
+
+	It("must set the socket deadline of new connections to the command deadline", func() {
+		deadline := time.Now().Add(time.Second)
+		conn, err := node.getConnectionWithDeadline(deadline)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.Close()
+
+		Expect(conn.deadline.Sub(deadline)).To(BeNumerically("<", time.Millisecond))
+		Expect(node.GetConnectionCount()).To(Equal(1))
+		Expect(len(node.connectionOpens)).To(Equal(0))
+	})
+
+	It("must apply the TCP options of the client policy", func() {
+		policy.TCPKeepAlive = -1
+		policy.DisableTCPNoDelay = true
+		policy.SendBufferSize = 64 * 1024
+		policy.ReceiveBufferSize = 64 * 1024
+
+		conn, err := node.GetConnection(time.Second)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.Close()
+
+		_, ok := conn.conn.(*net.TCPConn)
+		Expect(ok).To(BeTrue())
+		Expect(setTCPOptions(conn.conn, NewClientPolicy())).ToNot(HaveOccurred())
+	})
+
+	It("must not open connections after the deadline", func() {
+		_, err := node.newConnection(time.Now().Add(-time.Millisecond))
+		Expect(err).To(HaveOccurred())
+		Expect(err.(AerospikeError).ResultCode()).To(Equal(TIMEOUT))
+	})
+
+	It("must wait for a free slot to open connections within the timeout", func() {
+		// another command is opening a connection
+		node.connectionOpens <- struct{}{}
+
+		start := time.Now()
+		_, err := node.GetConnection(50 * time.Millisecond)
+		Expect(err).To(HaveOccurred())
+		Expect(time.Since(start)).To(BeNumerically(">=", 50*time.Millisecond))
+		Expect(node.GetConnectionCount()).To(Equal(0))
+
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			<-node.connectionOpens
+		}()
+
+		conn, err := node.GetConnection(time.Second)
+		Expect(err).ToNot(HaveOccurred())
+		conn.Close()
+	})
+
+	It("must use a pooled connection instead of waiting for a free slot", func() {
+		conn, err := node.GetConnection(time.Second)
+		Expect(err).ToNot(HaveOccurred())
+		node.PutConnection(conn)
+
+		node.connectionOpens <- struct{}{}
+		pooled, err := node.GetConnection(time.Second)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pooled).To(Equal(conn))
+		pooled.Close()
+	})
+
+	It("must close the tend connection when the node moves", func() {
+		conn, err := node.getTendConnection(time.Second)
+		Expect(err).ToNot(HaveOccurred())
+
+		node.health = NewAtomicInt(_FULL_HEALTH)
+		node.peersGeneration = NewAtomicInt(-1)
+		node.changeAddress(&nodeValidator{address: "127.0.0.1:3001"}, NewHost("127.0.0.1", 3001))
+		Expect(conn.IsConnected()).To(BeFalse())
+		Expect(node.tendConn).To(BeNil())
+		Expect(node.GetHost().Port).To(Equal(3001))
+	})
+
+	It("must close the tend connection when another node answers at the address", func() {
+		conn, err := node.getTendConnection(time.Second)
+		Expect(err).ToNot(HaveOccurred())
+
+		err = node.verifyNodeName(map[string]string{"node": "BB9000000000999"})
+		Expect(err).To(HaveOccurred())
+		Expect(err.(AerospikeError).ResultCode()).To(Equal(INVALID_NODE_ERROR))
+		Expect(node.IsActive()).To(BeFalse())
+		Expect(conn.IsConnected()).To(BeFalse())
+		Expect(node.tendConn).To(BeNil())
+	})
+
+	Context("with IdlePingThreshold", func() {
+
+		// answer reads info requests on the accepted connection and answers
+		// with the node name; it counts the requests
+		var answer = func(name string, pings chan struct{}) {
+			go func() {
+				server, err := listener.Accept()
+				if err != nil {
+					return
+				}
+				defer server.Close()
+
+				for {
+					header := make([]byte, MSG_HEADER_SIZE)
+					if _, err := io.ReadFull(server, header); err != nil {
+						return
+					}
+					io.ReadFull(server, make([]byte, int(header[6])<<8|int(header[7])))
+					pings <- struct{}{}
+					server.Write(NewMessage(MSG_INFO, []byte("node\t"+name+"\n")).Serialize())
+				}
+			}()
+		}
+
+		BeforeEach(func() {
+			policy.IdlePingThreshold = 10 * time.Millisecond
+		})
+
+		It("must ping connections idle beyond the threshold before reusing them", func() {
+			pings := make(chan struct{}, 10)
+			answer(node.name, pings)
+
+			conn, err := node.GetConnection(time.Second)
+			Expect(err).ToNot(HaveOccurred())
+			node.PutConnection(conn)
+
+			// recently used connections are not pinged
+			pooled, err := node.GetConnection(time.Second)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(pings)).To(Equal(0))
+			node.PutConnection(pooled)
+
+			time.Sleep(20 * time.Millisecond)
+			pooled, err = node.GetConnection(time.Second)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pooled).To(Equal(conn))
+			Expect(len(pings)).To(Equal(1))
+			pooled.Close()
+		})
+
+		It("must discard connections failing the ping", func() {
+			pings := make(chan struct{}, 10)
+			answer("BB9000000000999", pings)
+
+			conn, err := node.GetConnection(time.Second)
+			Expect(err).ToNot(HaveOccurred())
+			node.PutConnection(conn)
+
+			time.Sleep(20 * time.Millisecond)
+			fresh, err := node.GetConnection(time.Second)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fresh == conn).To(BeFalse())
+			Expect(conn.IsConnected()).To(BeFalse())
+			Expect(node.GetConnectionCount()).To(Equal(1))
+			fresh.Close()
+		})
+
+	})
+
+	Context("Connection budget", func() {
+
+		BeforeEach(func() {
+			policy.ServerConnectionsFraction = 0.5
+		})
+
+		It("must not open connections once the budget of the server is exhausted", func() {
+			conn, err := node.GetConnection(time.Second)
+			Expect(err).ToNot(HaveOccurred())
+			defer conn.Close()
+
+			// 40 of 100 descriptors are used, including the connection above
+			node.refreshConnectionBudget(map[string]string{
+				infoStatistics:    "objects=10;client_connections=40;uptime=100",
+				infoServiceConfig: "proto-fd-max=100;proto-fd-idle-ms=60000",
+			})
+			Expect(node.connectionBudgetExhausted()).To(BeFalse())
+
+			// 10 connections opened since the tend reach half of the descriptors
+			node.connectionCount.Set(11)
+			Expect(node.connectionBudgetExhausted()).To(BeTrue())
+
+			_, err = node.GetConnection(10 * time.Millisecond)
+			Expect(err).To(HaveOccurred())
+			Expect(err.(AerospikeError).ResultCode()).To(Equal(NO_AVAILABLE_CONNECTIONS_TO_NODE))
+
+			// pooled connections are still handed out
+			node.PutConnection(conn)
+			pooled, err := node.GetConnection(10 * time.Millisecond)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pooled == conn).To(BeTrue())
+		})
+
+		It("must not enforce the budget if the server does not report it", func() {
+			node.connectionCount.Set(10)
+			node.refreshConnectionBudget(map[string]string{})
+
+			connections, fdMax := node.ServerConnections()
+			Expect(connections).To(Equal(-1))
+			Expect(fdMax).To(Equal(-1))
+			Expect(node.connectionBudgetExhausted()).To(BeFalse())
+		})
+
+	})
+
+})
@@ -0,0 +1,280 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	. "github.com/THE108/aerospike-client-go/logger"
+	. "github.com/THE108/aerospike-client-go/types"
+)
+
+// Server features, as reported by the `features` info command.
+const (
+	FeatureBatchAny          = "batch-any"
+	FeatureBatchIndex        = "batch-index"
+	FeatureCDTList           = "cdt-list"
+	FeatureCDTMap            = "cdt-map"
+	FeatureFloat             = "float"
+	FeaturePeers             = "peers"
+	FeaturePipelining        = "pipelining"
+	FeatureReplicas          = "replicas"
+	FeatureTruncateNamespace = "truncate-namespace"
+	FeatureUDF               = "udf"
+)
+
+// Build returns the build version of the server, as reported when the node
+// was added to the cluster.
+func (nd *Node) Build() string {
+	return nd.build
+}
+
+// Features returns the sorted list of features reported by the node
+// on the last tend, or when it was added to the cluster.
+func (nd *Node) Features() []string {
+	nd.featureMutex.RLock()
+	defer nd.featureMutex.RUnlock()
+
+	res := make([]string, 0, len(nd.features))
+	for f := range nd.features {
+		res = append(res, f)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// SupportsFeature returns true if the node reported the feature on the last tend,
+// or when it was added to the cluster.
+func (nd *Node) SupportsFeature(feature string) bool {
+	nd.featureMutex.RLock()
+	_, exists := nd.features[feature]
+	nd.featureMutex.RUnlock()
+	return exists
+}
+
+// supportsBatchIndex returns true if batch commands are sent to the node in the
+// batch-index protocol. Older nodes, and nodes whose features are not known,
+// are sent batch-direct commands, so batches work across clusters in the middle
+// of an upgrade.
+func (nd *Node) supportsBatchIndex() bool {
+	return nd.SupportsFeature(FeatureBatchIndex)
+}
+
+// updateFeatures caches the features reported by the node, and returns
+// the features added and removed since the last tend.
+// Removed features are remembered until the node reports them again, e.g.
+// after a server downgrade; commands requiring them fail on the client.
+func (nd *Node) updateFeatures(featureList string) (added, removed []string) {
+	features := map[string]struct{}{}
+	for _, f := range parseFeatures(featureList) {
+		features[f] = struct{}{}
+	}
+
+	nd.featureMutex.Lock()
+	defer nd.featureMutex.Unlock()
+
+	// the first tend of the node only fills the cache
+	if nd.features != nil {
+		for f := range features {
+			if _, exists := nd.features[f]; !exists {
+				added = append(added, f)
+			}
+			delete(nd.removedFeatures, f)
+		}
+		for f := range nd.features {
+			if _, exists := features[f]; !exists {
+				removed = append(removed, f)
+				nd.removedFeatures[f] = struct{}{}
+			}
+		}
+		sort.Strings(added)
+		sort.Strings(removed)
+	}
+
+	nd.features = features
+	nd.hasRemovedFeatures.Set(len(nd.removedFeatures) > 0)
+	return added, removed
+}
+
+// refreshFeatures updates the cached features of the node and notifies
+// the NodeFeaturesChanged handler in the client policy if they changed.
+func (nd *Node) refreshFeatures(featureList string) {
+	added, removed := nd.updateFeatures(featureList)
+	if len(added) == 0 && len(removed) == 0 {
+		return
+	}
+
+	if len(removed) > 0 {
+		Logger.Warn("Node `%s` does not support features `%s` anymore. Commands requiring them will fail.", nd.name, strings.Join(removed, ";"))
+	} else {
+		Logger.Info("Node `%s` supports new features `%s`", nd.name, strings.Join(added, ";"))
+	}
+
+	if handler := nd.cluster.policy().NodeFeaturesChanged; handler != nil {
+		handler(nd, added, removed)
+	}
+}
+
+// checkFeatures returns an UNSUPPORTED_FEATURE error if the node does not
+// support a feature the command requires, instead of sending the command.
+// Nodes whose features are not known yet are sent all commands.
+func (nd *Node) checkFeatures(ifc command) error {
+	for _, f := range requiredFeatures(ifc) {
+		if err := nd.requireFeature(f); err != nil {
+			return err
+		}
+	}
+
+	// values are only searched for floats if the node does not support them
+	if err := nd.requireFeature(FeatureFloat); err != nil && sendsFloats(ifc) {
+		return err
+	}
+	return nil
+}
+
+// requireFeature returns an UNSUPPORTED_FEATURE error if the node does not
+// support the feature.
+func (nd *Node) requireFeature(feature string) error {
+	nd.featureMutex.RLock()
+	_, supported := nd.features[feature]
+	known := nd.features != nil
+	removed := false
+	if nd.hasRemovedFeatures.Get() {
+		_, removed = nd.removedFeatures[feature]
+	}
+	nd.featureMutex.RUnlock()
+
+	switch {
+	case removed:
+		return NewAerospikeError(UNSUPPORTED_FEATURE, "Node "+nd.String()+" does not support feature `"+feature+"` anymore")
+	case known && !supported:
+		return NewAerospikeError(UNSUPPORTED_FEATURE, "Node "+nd.String()+" (build "+nd.build+") does not support feature `"+feature+"`")
+	}
+	return nil
+}
+
+// requireFeature returns an UNSUPPORTED_FEATURE error if a node of the
+// cluster does not support the feature.
+func (clstr *Cluster) requireFeature(feature string) error {
+	nodes := clstr.GetNodes()
+	if len(nodes) == 0 {
+		return NewAerospikeError(SERVER_NOT_AVAILABLE, "Cluster is empty.")
+	}
+
+	for _, node := range nodes {
+		if err := node.requireFeature(feature); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// requiredFeatures returns the server features the command depends on.
+func requiredFeatures(ifc command) []string {
+	var res []string
+
+	switch cmd := ifc.(type) {
+	case *executeCommand:
+		res = append(res, FeatureUDF)
+	case *operateCommand:
+		for _, op := range cmd.operations {
+			if op.OpType != CDT_READ && op.OpType != CDT_MODIFY {
+				continue
+			}
+			if isMapOperation(op) {
+				res = append(res, FeatureCDTMap)
+			} else {
+				res = append(res, FeatureCDTList)
+			}
+		}
+	case statementCommand:
+		if stmt := cmd.getStatement(); stmt != nil && stmt.functionName != "" {
+			res = append(res, FeatureUDF)
+		}
+	}
+	return res
+}
+
+// sendsFloats returns true if the command sends float values. Floats are
+// only sent in list and map values, of bins, operations and UDF arguments.
+func sendsFloats(ifc command) bool {
+	switch cmd := ifc.(type) {
+	case *writeCommand:
+		for _, bin := range cmd.bins {
+			if hasFloat(bin.Value) {
+				return true
+			}
+		}
+	case *operateCommand:
+		for _, op := range cmd.operations {
+			if hasFloat(op.BinValue) {
+				return true
+			}
+		}
+	case *executeCommand:
+		return hasFloat(cmd.args)
+	case statementCommand:
+		if stmt := cmd.getStatement(); stmt != nil {
+			return hasFloat(stmt.functionArgs)
+		}
+	}
+	return false
+}
+
+// hasFloat returns true if the value is or contains a float.
+func hasFloat(v interface{}) bool {
+	switch v := v.(type) {
+	case nil, []byte, BytesValue, StringValue, LongValue, IntegerValue:
+		return false
+	case float32, float64:
+		return true
+	case *ListValue:
+		return hasFloat(v.list)
+	case *MapValue:
+		return hasFloat(v.vmap)
+	case *ValueArray:
+		return hasFloat(v.array)
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Array, reflect.Slice:
+		for i := 0; i < rv.Len(); i++ {
+			if hasFloat(rv.Index(i).Interface()) {
+				return true
+			}
+		}
+	case reflect.Map:
+		for _, k := range rv.MapKeys() {
+			if hasFloat(k.Interface()) || hasFloat(rv.MapIndex(k).Interface()) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isMapOperation determines if the CDT operation is a map operation by its
+// op code; map op codes start at 64.
+func isMapOperation(op *Operation) bool {
+	if b, ok := op.BinValue.(BytesValue); ok && len(b) >= 2 {
+		return int(b[0])<<8|int(b[1]) >= 64
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	. "github.com/THE108/aerospike-client-go/types"
+	. "github.com/THE108/aerospike-client-go/types/atomic"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Node Features Test", func() {
+
+	var node *Node
+
+	BeforeEach(func() {
+		node = &Node{
+			name:               "BB9000000000001",
+			host:               NewHost("127.0.0.1", 3000),
+			removedFeatures:    map[string]struct{}{},
+			hasRemovedFeatures: NewAtomicBool(false),
+		}
+	})
+
+	It("should detect added and removed features", func() {
+		added, removed := node.updateFeatures("cdt-list;udf")
+		Expect(added).To(BeNil())
+		Expect(removed).To(BeNil())
+		Expect(node.Features()).To(Equal([]string{"cdt-list", "udf"}))
+
+		added, removed = node.updateFeatures("udf;cdt-map")
+		Expect(added).To(Equal([]string{"cdt-map"}))
+		Expect(removed).To(Equal([]string{"cdt-list"}))
+		Expect(node.SupportsFeature("cdt-list")).To(BeFalse())
+		Expect(node.hasRemovedFeatures.Get()).To(BeTrue())
+
+		added, removed = node.updateFeatures("udf;cdt-map;cdt-list")
+		Expect(added).To(Equal([]string{"cdt-list"}))
+		Expect(removed).To(BeNil())
+		Expect(node.hasRemovedFeatures.Get()).To(BeFalse())
+	})
+
+	It("should determine the features required by commands", func() {
+		key, _ := NewKey("test", "demo", 1)
+
+		cmd := newOperateCommand(nil, NewWritePolicy(0, 0), key, []*Operation{GetOp(), ListSortOp("l", 0), MapIncrementOp(DefaultMapPolicy(), "m", "k", 1)})
+		Expect(requiredFeatures(cmd)).To(Equal([]string{"cdt-list", "cdt-map"}))
+
+		cmd = newOperateCommand(nil, NewWritePolicy(0, 0), key, []*Operation{GetOp()})
+		Expect(requiredFeatures(cmd)).To(BeNil())
+
+		Expect(requiredFeatures(&executeCommand{})).To(Equal([]string{"udf"}))
+	})
+
+	It("should fail commands requiring removed features", func() {
+		key, _ := NewKey("test", "demo", 1)
+		cmd := newOperateCommand(nil, NewWritePolicy(0, 0), key, []*Operation{ListSortOp("l", 0)})
+
+		node.updateFeatures("cdt-list;udf")
+		Expect(node.checkFeatures(cmd)).ToNot(HaveOccurred())
+
+		node.updateFeatures("udf")
+		err := node.checkFeatures(cmd)
+		Expect(err).To(HaveOccurred())
+		Expect(err.(AerospikeError).ResultCode()).To(Equal(UNSUPPORTED_FEATURE))
+
+		cmd = newOperateCommand(nil, NewWritePolicy(0, 0), key, []*Operation{GetOp()})
+		Expect(node.checkFeatures(cmd)).ToNot(HaveOccurred())
+	})
+
+	It("should fail commands requiring features the node never reported", func() {
+		key, _ := NewKey("test", "demo", 1)
+		cmd := newOperateCommand(nil, NewWritePolicy(0, 0), key, []*Operation{MapIncrementOp(DefaultMapPolicy(), "m", "k", 1)})
+
+		// the features of the node are not known yet
+		Expect(node.checkFeatures(cmd)).ToNot(HaveOccurred())
+
+		node.build = "3.7.0"
+		node.updateFeatures("cdt-list;udf")
+		err := node.checkFeatures(cmd)
+		Expect(err).To(HaveOccurred())
+		Expect(err.(AerospikeError).ResultCode()).To(Equal(UNSUPPORTED_FEATURE))
+		Expect(err.Error()).To(Equal("Node BB9000000000001 127.0.0.1:3000 (build 3.7.0) does not support feature `cdt-map`"))
+		Expect(node.Build()).To(Equal("3.7.0"))
+	})
+
+	It("should fail commands sending floats to nodes without float support", func() {
+		key, _ := NewKey("test", "demo", 1)
+		floats := newWriteCommand(nil, NewWritePolicy(0, 0), key, []*Bin{NewBin("l", []interface{}{1, map[interface{}]interface{}{"f": 1.5}})}, WRITE)
+		ints := newWriteCommand(nil, NewWritePolicy(0, 0), key, []*Bin{NewBin("l", []interface{}{1, 2})}, WRITE)
+
+		Expect(sendsFloats(floats)).To(BeTrue())
+		Expect(sendsFloats(ints)).To(BeFalse())
+		Expect(sendsFloats(newOperateCommand(nil, NewWritePolicy(0, 0), key, []*Operation{PutOp(NewBin("l", []float64{0.5}))}))).To(BeTrue())
+
+		node.updateFeatures("cdt-list;udf")
+		err := node.checkFeatures(floats)
+		Expect(err).To(HaveOccurred())
+		Expect(err.(AerospikeError).ResultCode()).To(Equal(UNSUPPORTED_FEATURE))
+		Expect(node.checkFeatures(ints)).ToNot(HaveOccurred())
+
+		node.updateFeatures("cdt-list;float;udf")
+		Expect(node.checkFeatures(floats)).ToNot(HaveOccurred())
+	})
+
+})
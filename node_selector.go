@@ -0,0 +1,230 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	. "github.com/THE108/aerospike-client-go/types"
+	. "github.com/THE108/aerospike-client-go/types/atomic"
+)
+
+// NodeSelector chooses the node a single record command is retried on after
+// an attempt failed. The first attempt is always sent to the master node of
+// the record's partition; nodes which are not the master proxy the command
+// to it.
+// Implementations must be safe for concurrent use.
+type NodeSelector interface {
+	// SelectNode returns the node for the next attempt of a command.
+	// nodes contains the active nodes of the cluster and is never empty.
+	// preferred is the master node of the partition, or a random node if the
+	// master is unknown.
+	// failed is the node of the failed attempt, or nil if it was not sent.
+	// Returning nil selects the preferred node.
+	SelectNode(nodes []*Node, preferred, failed *Node) *Node
+}
+
+// PendingCommands returns the number of commands currently sent to the node,
+// or waiting for its response.
+func (nd *Node) PendingCommands() int {
+	return nd.pendingCommands.Get()
+}
+
+// Latency returns the exponential moving average of the response time of
+// successful commands sent to the node, or zero if none has succeeded yet.
+func (nd *Node) Latency() time.Duration {
+	return time.Duration(nd.latencyEMA.Get())
+}
+
+// recordLatency updates the moving average latency of the node.
+func (nd *Node) recordLatency(d time.Duration) {
+	for {
+		old := nd.latencyEMA.Get()
+		ema := int(d)
+		if old > 0 {
+			// alpha = 1/8
+			ema = old + (int(d)-old)/8
+		}
+		if nd.latencyEMA.CompareAndSet(old, ema) {
+			return
+		}
+	}
+}
+
+// excludeNode returns the nodes without the node, unless it is the only one.
+func excludeNode(nodes []*Node, node *Node) []*Node {
+	if node == nil || len(nodes) < 2 {
+		return nodes
+	}
+
+	res := make([]*Node, 0, len(nodes))
+	for _, nd := range nodes {
+		if nd != node {
+			res = append(res, nd)
+		}
+	}
+	if len(res) == 0 {
+		return nodes
+	}
+	return res
+}
+
+type roundRobinNodeSelector struct {
+	index *AtomicInt
+}
+
+// NewRoundRobinNodeSelector returns a NodeSelector which cycles through
+// the nodes of the cluster, skipping the node of the failed attempt.
+func NewRoundRobinNodeSelector() NodeSelector {
+	return &roundRobinNodeSelector{index: NewAtomicInt(0)}
+}
+
+func (s *roundRobinNodeSelector) SelectNode(nodes []*Node, preferred, failed *Node) *Node {
+	nodes = excludeNode(nodes, failed)
+	index := s.index.GetAndIncrement() % len(nodes)
+	if index < 0 {
+		index = -index
+	}
+	return nodes[index]
+}
+
+type leastPendingNodeSelector struct {
+	mutex sync.Mutex
+	rnd   *rand.Rand
+}
+
+// NewLeastPendingNodeSelector returns a NodeSelector which picks two random
+// nodes, other than the node of the failed attempt, and selects the one with
+// fewer pending commands (power of two choices).
+func NewLeastPendingNodeSelector() NodeSelector {
+	return &leastPendingNodeSelector{rnd: rand.New(rand.NewSource(time.Now().UnixNano()))}
+}
+
+func (s *leastPendingNodeSelector) SelectNode(nodes []*Node, preferred, failed *Node) *Node {
+	nodes = excludeNode(nodes, failed)
+	if len(nodes) == 1 {
+		return nodes[0]
+	}
+
+	s.mutex.Lock()
+	i := s.rnd.Intn(len(nodes))
+	j := s.rnd.Intn(len(nodes) - 1)
+	s.mutex.Unlock()
+
+	// make sure the choices are distinct
+	if j >= i {
+		j++
+	}
+
+	if nodes[j].PendingCommands() < nodes[i].PendingCommands() {
+		return nodes[j]
+	}
+	return nodes[i]
+}
+
+type lowestLatencyNodeSelector struct{}
+
+// NewLowestLatencyNodeSelector returns a NodeSelector which selects the node
+// with the lowest average latency, other than the node of the failed attempt.
+// Nodes without successful commands yet are preferred, so they are measured.
+func NewLowestLatencyNodeSelector() NodeSelector {
+	return lowestLatencyNodeSelector{}
+}
+
+func (lowestLatencyNodeSelector) SelectNode(nodes []*Node, preferred, failed *Node) *Node {
+	nodes = excludeNode(nodes, failed)
+
+	best := nodes[0]
+	for _, node := range nodes[1:] {
+		if node.Latency() < best.Latency() {
+			best = node
+		}
+	}
+	return best
+}
+
+// latencyWeightedNode picks one of the nodes at random, with a probability
+// inversely proportional to its latency. r must be in [0, 1).
+// Nodes without a measured latency are weighted like the fastest measured
+// node, so they get measured; if no latency is known, all nodes are equally
+// likely.
+func latencyWeightedNode(nodes []*Node, r float64) *Node {
+	var fastest time.Duration
+	for _, node := range nodes {
+		if l := node.Latency(); l > 0 && (fastest == 0 || l < fastest) {
+			fastest = l
+		}
+	}
+	if fastest == 0 {
+		return nodes[int(r*float64(len(nodes)))%len(nodes)]
+	}
+
+	weights := make([]float64, len(nodes))
+	var total float64
+	for i, node := range nodes {
+		l := node.Latency()
+		if l <= 0 {
+			l = fastest
+		}
+		weights[i] = 1 / float64(l)
+		total += weights[i]
+	}
+
+	target := r * total
+	for i, w := range weights {
+		if target < w {
+			return nodes[i]
+		}
+		target -= w
+	}
+	return nodes[len(nodes)-1]
+}
+
+// latencyAwareNode returns the replica of the partition to send a read to
+// when BasePolicy.LatencyAwareReads is set, or nil if no replica is known.
+func (clstr *Cluster) latencyAwareNode(partition *Partition) *Node {
+	nodes := clstr.GetReplicaNodes(partition)
+	switch len(nodes) {
+	case 0:
+		return nil
+	case 1:
+		return nodes[0]
+	}
+	return latencyWeightedNode(nodes, rand.Float64())
+}
+
+// selectNode asks the selector for the next node among the active nodes of
+// the cluster. It falls back to the preferred node if the selector returns nil.
+func (clstr *Cluster) selectNode(selector NodeSelector, preferred, failed *Node) (*Node, error) {
+	var nodes []*Node
+	for _, node := range clstr.GetNodes() {
+		if node.IsActive() {
+			nodes = append(nodes, node)
+		}
+	}
+
+	if len(nodes) > 0 {
+		if node := selector.SelectNode(nodes, preferred, failed); node != nil {
+			return node, nil
+		}
+	}
+
+	if preferred == nil {
+		return nil, NewAerospikeError(INVALID_NODE_ERROR)
+	}
+	return preferred, nil
+}
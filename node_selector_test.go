@@ -0,0 +1,135 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"time"
+
+	. "github.com/THE108/aerospike-client-go/types/atomic"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Node Selector Test", func() {
+
+	var node1, node2, node3 *Node
+	var nodes []*Node
+
+	var newTestNode = func(name string) *Node {
+		return &Node{
+			name:            name,
+			host:            NewHost("127.0.0.1", 3000),
+			active:          NewAtomicBool(true),
+			pendingCommands: NewAtomicInt(0),
+			latencyEMA:      NewAtomicInt(0),
+		}
+	}
+
+	BeforeEach(func() {
+		node1 = newTestNode("BB9000000000001")
+		node2 = newTestNode("BB9000000000002")
+		node3 = newTestNode("BB9000000000003")
+		nodes = []*Node{node1, node2, node3}
+	})
+
+	It("should cycle through the nodes other than the failed one", func() {
+		selector := NewRoundRobinNodeSelector()
+
+		Expect(selector.SelectNode(nodes, node1, node1)).To(Equal(node2))
+		Expect(selector.SelectNode(nodes, node1, node1)).To(Equal(node3))
+		Expect(selector.SelectNode(nodes, node1, node1)).To(Equal(node2))
+		Expect(selector.SelectNode(nodes, node1, nil)).To(Equal(node1))
+	})
+
+	It("should select the node with fewer pending commands", func() {
+		selector := NewLeastPendingNodeSelector()
+
+		node1.pendingCommands.Set(3)
+		node2.pendingCommands.Set(5)
+		for i := 0; i < 20; i++ {
+			Expect(selector.SelectNode(nodes[:2], node1, nil)).To(Equal(node1))
+		}
+
+		// the failed node is never selected, unless it is the only one
+		for i := 0; i < 20; i++ {
+			Expect(selector.SelectNode(nodes, node1, node1)).ToNot(Equal(node1))
+		}
+		Expect(selector.SelectNode(nodes[:1], node1, node1)).To(Equal(node1))
+	})
+
+	It("should select the node with the lowest latency", func() {
+		selector := NewLowestLatencyNodeSelector()
+
+		node1.recordLatency(time.Millisecond)
+		node2.recordLatency(5 * time.Millisecond)
+		node3.recordLatency(3 * time.Millisecond)
+		Expect(selector.SelectNode(nodes, node2, nil)).To(Equal(node1))
+		Expect(selector.SelectNode(nodes, node2, node1)).To(Equal(node3))
+
+		// the average moves towards new samples
+		node3.recordLatency(11 * time.Millisecond)
+		Expect(node3.Latency()).To(Equal(4 * time.Millisecond))
+		Expect(selector.SelectNode(nodes, node2, node1)).To(Equal(node3))
+	})
+
+	It("should weight nodes by the inverse of their latency", func() {
+		// no latency known yet
+		Expect(latencyWeightedNode(nodes, 0)).To(Equal(node1))
+		Expect(latencyWeightedNode(nodes, 0.5)).To(Equal(node2))
+		Expect(latencyWeightedNode(nodes, 0.99)).To(Equal(node3))
+
+		// node1 gets three times the reads of node2
+		node1.recordLatency(time.Millisecond)
+		node2.recordLatency(3 * time.Millisecond)
+		Expect(node2.Latency()).To(Equal(3 * time.Millisecond))
+		Expect(latencyWeightedNode(nodes[:2], 0.7)).To(Equal(node1))
+		Expect(latencyWeightedNode(nodes[:2], 0.8)).To(Equal(node2))
+
+		// the unmeasured node3 is weighted like the fastest node
+		Expect(latencyWeightedNode(nodes, 0.4)).To(Equal(node1))
+		Expect(latencyWeightedNode(nodes, 0.5)).To(Equal(node2))
+		Expect(latencyWeightedNode(nodes, 0.9)).To(Equal(node3))
+	})
+
+	It("should fall back to the preferred node", func() {
+		cluster := &Cluster{nodes: nodes}
+		nilSelector := nodeSelectorFunc(func(nodes []*Node, preferred, failed *Node) *Node { return nil })
+
+		node, err := cluster.selectNode(nilSelector, node2, node1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(node).To(Equal(node2))
+
+		// inactive nodes are not offered to the selector
+		node3.active.Set(false)
+		offered := nodeSelectorFunc(func(nodes []*Node, preferred, failed *Node) *Node {
+			Expect(nodes).To(Equal([]*Node{node1, node2}))
+			return nodes[0]
+		})
+		node, err = cluster.selectNode(offered, node2, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(node).To(Equal(node1))
+
+		_, err = cluster.selectNode(nilSelector, nil, node1)
+		Expect(err).To(HaveOccurred())
+	})
+
+})
+
+type nodeSelectorFunc func(nodes []*Node, preferred, failed *Node) *Node
+
+func (f nodeSelectorFunc) SelectNode(nodes []*Node, preferred, failed *Node) *Node {
+	return f(nodes, preferred, failed)
+}
@@ -0,0 +1,156 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"reflect"
+	"strconv"
+	"time"
+)
+
+const infoTag = "info"
+
+// NodeStats holds the statistics of a node, parsed from the `statistics`
+// info command. Statistics the server does not report are zero.
+type NodeStats struct {
+	// Time is when the statistics were parsed.
+	Time time.Time
+
+	// ClusterSize is the number of nodes in the cluster seen by the node.
+	ClusterSize int64 `info:"cluster_size"`
+
+	// Uptime is the number of seconds since the node was started.
+	Uptime int64 `info:"uptime"`
+
+	// ClientConnections is the number of open client connections.
+	ClientConnections int64 `info:"client_connections"`
+
+	// HeartbeatConnections is the number of open heartbeat connections.
+	HeartbeatConnections int64 `info:"heartbeat_connections"`
+
+	// FabricConnections is the number of open fabric connections.
+	FabricConnections int64 `info:"fabric_connections"`
+
+	// Objects is the number of records stored on the node.
+	Objects int64 `info:"objects"`
+
+	// MigrateProgressSend is the number of partitions pending to be sent.
+	MigrateProgressSend int64 `info:"migrate_progress_send"`
+
+	// MigrateProgressRecv is the number of partitions pending to be received.
+	MigrateProgressRecv int64 `info:"migrate_progress_recv"`
+
+	// MigratePartitionsRemaining is the number of partitions remaining to migrate.
+	MigratePartitionsRemaining int64 `info:"migrate_partitions_remaining"`
+
+	// SystemFreeMemPct is the percentage of free system memory.
+	SystemFreeMemPct float64 `info:"system_free_mem_pct"`
+
+	// SystemTotalCPUPct is the percentage of CPU used by the system.
+	SystemTotalCPUPct float64 `info:"system_total_cpu_pct"`
+
+	// HeapEfficiencyPct is the ratio of allocated to mapped heap memory.
+	HeapEfficiencyPct float64 `info:"heap_efficiency_pct"`
+
+	// Other holds the statistics without a field, and the values of fields
+	// which could not be parsed. Integers are parsed into int64, decimals into
+	// float64 and `true`/`false` into bool; other values are kept as strings.
+	Other map[string]interface{}
+}
+
+// nodeStatsFields maps statistic names to the index of their NodeStats field.
+var nodeStatsFields = statsFields(reflect.TypeOf(NodeStats{}))
+
+// statsFields maps the statistic names in the info tags of the struct fields
+// to the field indexes.
+func statsFields(t reflect.Type) map[string]int {
+	res := map[string]int{}
+	for i := 0; i < t.NumField(); i++ {
+		if name := t.Field(i).Tag.Get(infoTag); name != "" {
+			res[name] = i
+		}
+	}
+	return res
+}
+
+// ParseNodeStats parses the statistics returned by RequestNodeStats.
+func ParseNodeStats(values map[string]string) *NodeStats {
+	stats := &NodeStats{Time: time.Now()}
+	stats.Other = parseStats(values, reflect.ValueOf(stats).Elem(), nodeStatsFields)
+	return stats
+}
+
+// parseStats sets the fields of the struct v from the values, and returns
+// the values without a field.
+func parseStats(values map[string]string, v reflect.Value, fields map[string]int) map[string]interface{} {
+	other := map[string]interface{}{}
+	for name, value := range values {
+		if i, exists := fields[name]; exists && setStatsField(v.Field(i), value) {
+			continue
+		}
+		other[name] = parseStatsValue(value)
+	}
+	return other
+}
+
+// setStatsField parses the value into the field, and returns false if it is not of the field's type.
+func setStatsField(f reflect.Value, value string) bool {
+	switch f.Kind() {
+	case reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return false
+		}
+		f.SetInt(n)
+	case reflect.Float64:
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return false
+		}
+		f.SetFloat(n)
+	case reflect.Bool:
+		if value != "true" && value != "false" {
+			return false
+		}
+		f.SetBool(value == "true")
+	default:
+		return false
+	}
+	return true
+}
+
+// parseStatsValue converts a statistic value to the Go type it represents.
+func parseStatsValue(value string) interface{} {
+	if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return n
+	}
+	if f, err := strconv.ParseFloat(value, 64); err == nil {
+		return f
+	}
+	if value == "true" || value == "false" {
+		return value == "true"
+	}
+	return value
+}
+
+// RequestNodeStatsTyped returns the statistics of the node parsed into NodeStats.
+// If the policy is nil, the default InfoPolicy will be used.
+func RequestNodeStatsTyped(policy *InfoPolicy, node *Node) (*NodeStats, error) {
+	values, err := RequestNodeStatsWithPolicy(policy, node)
+	if err != nil {
+		return nil, err
+	}
+	return ParseNodeStats(values), nil
+}
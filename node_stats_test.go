@@ -0,0 +1,50 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Node Stats Test", func() {
+
+	It("should parse known statistics into typed fields", func() {
+		stats := ParseNodeStats(parseInfoParams("cluster_size=3;client_connections=42;objects=1000;system_free_mem_pct=87;heap_efficiency_pct=61.5;migrate_progress_send=2"))
+
+		Expect(stats.ClusterSize).To(Equal(int64(3)))
+		Expect(stats.ClientConnections).To(Equal(int64(42)))
+		Expect(stats.Objects).To(Equal(int64(1000)))
+		Expect(stats.SystemFreeMemPct).To(Equal(87.0))
+		Expect(stats.HeapEfficiencyPct).To(Equal(61.5))
+		Expect(stats.MigrateProgressSend).To(Equal(int64(2)))
+		Expect(stats.Uptime).To(Equal(int64(0)))
+		Expect(stats.Other).To(BeEmpty())
+	})
+
+	It("should keep other statistics with their parsed values", func() {
+		stats := ParseNodeStats(parseInfoParams("batch_index_initiate=7;query_avg_rec_count=1.5;cluster_integrity=true;paxos_principal=BB9;objects=n/a"))
+
+		Expect(stats.Other).To(Equal(map[string]interface{}{
+			"batch_index_initiate": int64(7),
+			"query_avg_rec_count":  1.5,
+			"cluster_integrity":    true,
+			"paxos_principal":      "BB9",
+			"objects":              "n/a",
+		}))
+		Expect(stats.Objects).To(Equal(int64(0)))
+	})
+
+})
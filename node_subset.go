@@ -0,0 +1,112 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"path"
+	"strconv"
+
+	. "github.com/THE108/aerospike-client-go/types"
+)
+
+// NodeSubset restricts a client to a subset of the nodes of a cluster, e.g.
+// to the rack of nodes reserved for analytic workloads, so heavy scans and
+// queries never reach the latency critical nodes.
+// Nodes outside the subset are not added to the cluster, and the client does
+// not connect to them. As a consequence:
+//
+//   - Scans and queries only return the records mastered by the nodes of the subset.
+//   - Single record and batch commands for partitions mastered by other nodes
+//     are sent to a node of the subset, which proxies them to the master.
+//   - Seed hosts must be nodes of the subset; the other nodes are discovered through them.
+//
+// Nodes are checked once, when they are discovered; a node moved to another
+// rack is only left or joined by new clients.
+type NodeSubset struct {
+	// NodeNames are the patterns in path.Match syntax, e.g. `BB9*`, of the
+	// names of the nodes in the subset.
+	NodeNames []string
+
+	// RackIds are the racks of the nodes in the subset, as configured with
+	// the rack-id of Namespace on the nodes. Nodes without a rack-id are in rack 0.
+	RackIds []int
+
+	// Namespace whose rack-id is checked; required with RackIds.
+	Namespace string
+}
+
+// validate checks the patterns of the subset are valid.
+func (ns *NodeSubset) validate() error {
+	for _, pattern := range ns.NodeNames {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return NewAerospikeError(PARAMETER_ERROR, "Invalid node name pattern `"+pattern+"`")
+		}
+	}
+
+	if len(ns.RackIds) > 0 && ns.Namespace == "" {
+		return NewAerospikeError(PARAMETER_ERROR, "Namespace of the node subset racks must not be empty")
+	}
+	return nil
+}
+
+// infoNames returns the info values the subset is checked against,
+// besides the node name.
+func (ns *NodeSubset) infoNames() []string {
+	if len(ns.RackIds) == 0 {
+		return nil
+	}
+	return []string{ns.rackInfoName()}
+}
+
+func (ns *NodeSubset) rackInfoName() string {
+	return "get-config:context=namespace;id=" + ns.Namespace
+}
+
+// contains returns true if the node with the name, and the info values
+// requested with infoNames, is in the subset.
+func (ns *NodeSubset) contains(name string, infoMap map[string]string) bool {
+	if len(ns.NodeNames) > 0 {
+		matched := false
+		for _, pattern := range ns.NodeNames {
+			if ok, _ := path.Match(pattern, name); ok {
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			return false
+		}
+	}
+
+	if len(ns.RackIds) > 0 {
+		rackId := 0
+		if v, exists := parseInfoParams(infoMap[ns.rackInfoName()])["rack-id"]; exists {
+			var err error
+			if rackId, err = strconv.Atoi(v); err != nil {
+				return false
+			}
+		}
+
+		for _, id := range ns.RackIds {
+			if id == rackId {
+				return true
+			}
+		}
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,108 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike_test
+
+import (
+	"time"
+
+	. "github.com/THE108/aerospike-client-go"
+	"github.com/THE108/aerospike-client-go/aerotest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Node subsets", func() {
+
+	var srv1, srv2 *aerotest.Server
+
+	var nodeNames = func(client *Client) []string {
+		names := []string{}
+		for _, node := range client.GetNodes() {
+			names = append(names, node.GetName())
+		}
+		return names
+	}
+
+	var newClient = func(subset *NodeSubset, seed *aerotest.Server) (*Client, error) {
+		policy := NewClientPolicy()
+		policy.TendInterval = 20 * time.Millisecond
+		policy.NodeSubset = subset
+		return NewClientWithPolicy(policy, seed.Host(), seed.Port())
+	}
+
+	BeforeEach(func() {
+		var err error
+		srv1, err = aerotest.NewServer("test")
+		Expect(err).ToNot(HaveOccurred())
+		srv2, err = aerotest.NewServer("test")
+		Expect(err).ToNot(HaveOccurred())
+
+		srv2.SetNodeName("BB9AEROTEST0002")
+		srv1.SetRackId(1)
+		srv2.SetRackId(2)
+		for _, srv := range []*aerotest.Server{srv1, srv2} {
+			srv.SetFeatures("peers", "pipelining", "replicas-master")
+		}
+		srv1.AddPeer(srv2)
+		srv2.AddPeer(srv1)
+	})
+
+	AfterEach(func() {
+		srv1.Close()
+		srv2.Close()
+	})
+
+	It("must only add the nodes of the racks", func() {
+		client, err := newClient(&NodeSubset{Namespace: "test", RackIds: []int{1}}, srv1)
+		Expect(err).ToNot(HaveOccurred())
+		defer client.Close()
+
+		time.Sleep(100 * time.Millisecond)
+		Expect(nodeNames(client)).To(Equal([]string{aerotest.NodeName}))
+
+		key, err := NewKey("test", "set", 1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(client.Put(nil, key, BinMap{"a": 1})).ToNot(HaveOccurred())
+		Expect(srv1.Len("test")).To(Equal(1))
+		Expect(srv2.Len("test")).To(Equal(0))
+	})
+
+	It("must only add the nodes matching the name patterns", func() {
+		client, err := newClient(&NodeSubset{NodeNames: []string{"*0002"}}, srv2)
+		Expect(err).ToNot(HaveOccurred())
+		defer client.Close()
+
+		time.Sleep(100 * time.Millisecond)
+		Expect(nodeNames(client)).To(Equal([]string{"BB9AEROTEST0002"}))
+	})
+
+	It("must add all nodes matching all criteria", func() {
+		client, err := newClient(&NodeSubset{NodeNames: []string{"BB9*"}, Namespace: "test", RackIds: []int{1, 2}}, srv1)
+		Expect(err).ToNot(HaveOccurred())
+		defer client.Close()
+
+		Expect(nodeNames(client)).To(ConsistOf(aerotest.NodeName, "BB9AEROTEST0002"))
+	})
+
+	It("must reject invalid subsets", func() {
+		_, err := newClient(&NodeSubset{NodeNames: []string{"["}}, srv1)
+		Expect(err).To(HaveOccurred())
+
+		_, err = newClient(&NodeSubset{RackIds: []int{1}}, srv1)
+		Expect(err).To(HaveOccurred())
+	})
+
+})
@@ -31,6 +31,11 @@ type nodeValidator struct {
 	address    string
 	useNewInfo bool //= true
 	cluster    *Cluster
+
+	// build and features reported by the node, cached at its creation
+	build       string
+	features    string
+	hasFeatures bool
 }
 
 // Generates a node validator
@@ -52,11 +57,12 @@ func newNodeValidator(cluster *Cluster, host *Host, timeout time.Duration) (*nod
 }
 
 func (ndv *nodeValidator) setAliases(host *Host) error {
-	// IP addresses do not need a lookup
+	// IP addresses do not need a lookup; custom dialers resolve host names themselves
 	ip := net.ParseIP(host.Name)
-	if ip != nil {
+	if ip != nil || ndv.cluster.policy().DialFunc != nil {
 		aliases := make([]*Host, 1)
 		aliases[0] = NewHost(host.Name, host.Port)
+		aliases[0].TLSName = host.TLSName
 		ndv.aliases = aliases
 	} else {
 		addresses, err := net.LookupHost(host.Name)
@@ -64,9 +70,17 @@ func (ndv *nodeValidator) setAliases(host *Host) error {
 			Logger.Error("HostLookup failed with error: ", err)
 			return err
 		}
+
+		// the certificate is issued for the host name, not its addresses
+		tlsName := host.TLSName
+		if tlsName == "" && ndv.cluster.policy().TLSConfig != nil {
+			tlsName = host.Name
+		}
+
 		aliases := make([]*Host, len(addresses))
 		for idx, addr := range addresses {
 			aliases[idx] = NewHost(addr, host.Port)
+			aliases[idx].TLSName = tlsName
 		}
 		ndv.aliases = aliases
 	}
@@ -77,7 +91,7 @@ func (ndv *nodeValidator) setAliases(host *Host) error {
 func (ndv *nodeValidator) setAddress(timeout time.Duration) error {
 	for _, alias := range ndv.aliases {
 		address := net.JoinHostPort(alias.Name, strconv.Itoa(alias.Port))
-		conn, err := NewConnection(address, time.Second)
+		conn, err := newConnectionWithPolicy(ndv.cluster.policy(), address, alias.TLSName, time.Second)
 		if err != nil {
 			return err
 		}
@@ -85,7 +99,7 @@ func (ndv *nodeValidator) setAddress(timeout time.Duration) error {
 		defer conn.Close()
 
 		// need to authenticate
-		if err := conn.Authenticate(ndv.cluster.user, ndv.cluster.Password()); err != nil {
+		if err := ndv.cluster.authenticate(conn); err != nil {
 			// Socket not authenticated. Do not put back into pool.
 			conn.Close()
 
@@ -96,13 +110,35 @@ func (ndv *nodeValidator) setAddress(timeout time.Duration) error {
 			return err
 		}
 
-		infoMap, err := RequestInfo(conn, "node", "build")
+		names := []string{"node", "build", "features"}
+		clusterName := ndv.cluster.policy().ClusterName
+		if clusterName != "" {
+			names = append(names, "cluster-name")
+		}
+
+		subset := ndv.cluster.policy().NodeSubset
+		if subset != nil {
+			names = append(names, subset.infoNames()...)
+		}
+
+		infoMap, err := RequestInfo(conn, names...)
 		if err != nil {
 			return err
 		}
+
+		if clusterName != "" && infoMap["cluster-name"] != clusterName {
+			return NewAerospikeError(INVALID_NODE_ERROR, "Node "+address+" is in cluster `"+infoMap["cluster-name"]+"`, not `"+clusterName+"`")
+		}
+
+		if subset != nil && !subset.contains(infoMap["node"], infoMap) {
+			return NewAerospikeError(INVALID_NODE_ERROR, "Node "+infoMap["node"]+" on "+address+" is not in the node subset of the client")
+		}
+
 		if nodeName, exists := infoMap["node"]; exists {
 			ndv.name = nodeName
 			ndv.address = address
+			ndv.build = infoMap["build"]
+			ndv.features, ndv.hasFeatures = infoMap["features"]
 
 			// Check new info protocol support for >= 2.6.6 build
 			if buildVersion, exists := infoMap["build"]; exists {
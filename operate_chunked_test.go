@@ -0,0 +1,82 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike_test
+
+import (
+	. "github.com/THE108/aerospike-client-go"
+	"github.com/THE108/aerospike-client-go/aerotest"
+	. "github.com/THE108/aerospike-client-go/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Chunked operations", func() {
+
+	var srv *aerotest.Server
+	var client *Client
+	var key *Key
+
+	BeforeEach(func() {
+		var err error
+		client, srv, err = aerotest.NewClientAndServer("test")
+		Expect(err).ToNot(HaveOccurred())
+
+		key = mustKey("chunked")
+		Expect(client.Put(nil, key, BinMap{"s": "str"})).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		client.Close()
+		Expect(srv.Close()).ToNot(HaveOccurred())
+	})
+
+	It("must leave the chunks applied before a failed chunk", func() {
+		ops := []*Operation{}
+		for i := 0; i < MaxOperations; i++ {
+			ops = append(ops, AddOp(NewBin("n", 1)))
+		}
+		// fails the second chunk: strings can't be incremented
+		ops = append(ops, AddOp(NewBin("n", 1)), AddOp(NewBin("s", 1)))
+
+		_, err := client.OperateChunked(nil, key, ops...)
+		Expect(err).To(HaveOccurred())
+		Expect(err.(AerospikeError).ResultCode()).To(Equal(BIN_TYPE_ERROR))
+
+		rec, err := client.Get(nil, key)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rec.Bins).To(Equal(BinMap{"s": "str", "n": MaxOperations}))
+		Expect(rec.Generation).To(Equal(2))
+	})
+
+	It("must not show the writes of the following chunks to the reads of a chunk", func() {
+		ops := []*Operation{}
+		for i := 0; i < MaxOperations-1; i++ {
+			ops = append(ops, AddOp(NewBin("n", 1)))
+		}
+		// the last operation of the first chunk
+		ops = append(ops, GetOpForBin("n"), AddOp(NewBin("n", 1)))
+
+		rec, err := client.OperateChunked(nil, key, ops...)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rec.Bins["n"]).To(Equal(MaxOperations - 1))
+
+		rec, err = client.Get(nil, key)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rec.Bins["n"]).To(Equal(MaxOperations))
+		Expect(rec.Generation).To(Equal(3))
+	})
+
+})
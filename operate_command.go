@@ -14,11 +14,18 @@
 
 package aerospike
 
+import (
+	. "github.com/THE108/aerospike-client-go/types"
+)
+
 type operateCommand struct {
 	*readCommand
 
 	policy     *WritePolicy
 	operations []*Operation
+
+	// predExp is the filter expression of OperateFiltered commands
+	predExp []PredExp
 }
 
 func newOperateCommand(cluster *Cluster, policy *WritePolicy, key *Key, operations []*Operation) *operateCommand {
@@ -30,7 +37,10 @@ func newOperateCommand(cluster *Cluster, policy *WritePolicy, key *Key, operatio
 }
 
 func (cmd *operateCommand) writeBuffer(ifc command) error {
-	return cmd.setOperate(cmd.policy, cmd.key, cmd.operations)
+	if isWriteCommand(cmd) && cmd.cluster.hasRecordChecksum(cmd.key) {
+		return NewAerospikeError(PARAMETER_ERROR, "Records with checksums can only be written whole")
+	}
+	return cmd.setOperate(cmd.policy, cmd.key, cmd.operations, cmd.predExp)
 }
 
 func (cmd *operateCommand) Execute() error {
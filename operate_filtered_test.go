@@ -0,0 +1,98 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike_test
+
+import (
+	. "github.com/THE108/aerospike-client-go"
+	"github.com/THE108/aerospike-client-go/aerotest"
+	. "github.com/THE108/aerospike-client-go/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Filtered Operate", func() {
+
+	var srv *aerotest.Server
+	var client *Client
+	var key *Key
+
+	BeforeEach(func() {
+		var err error
+		client, srv, err = aerotest.NewClientAndServer("test")
+		Expect(err).ToNot(HaveOccurred())
+
+		key, _ = NewKey("test", "aerotest", "account")
+		Expect(client.Put(nil, key, BinMap{"balance": 100, "status": "active"})).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		client.Close()
+		Expect(srv.Close()).ToNot(HaveOccurred())
+	})
+
+	// withdraws 60 if the account is active and the balance covers it
+	withdraw := func(policy *OperatePolicy) (*Record, error) {
+		policy.FilterExpression = []PredExp{
+			NewPredExpStringBin("status"),
+			NewPredExpStringValue("active"),
+			NewPredExpStringEqual(),
+			NewPredExpIntegerBin("balance"),
+			NewPredExpIntegerValue(60),
+			NewPredExpIntegerGreaterEq(),
+			NewPredExpAnd(2),
+		}
+		return client.OperateFiltered(policy, key, AddOp(NewBin("balance", -60)), GetOpForBin("balance"))
+	}
+
+	It("must apply the operations to matching records only", func() {
+		rec, err := withdraw(NewOperatePolicy())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rec.FilteredOut).To(BeFalse())
+		Expect(rec.Bins).To(Equal(BinMap{"balance": 40}))
+
+		_, err = withdraw(NewOperatePolicy())
+		Expect(err).To(HaveOccurred())
+		Expect(err.(AerospikeError).ResultCode()).To(Equal(FILTERED_OUT))
+
+		rec, err = client.Get(nil, key)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rec.Bins["balance"]).To(Equal(40))
+		Expect(rec.Generation).To(Equal(2))
+	})
+
+	It("must return filtered out records if the policy asks for them", func() {
+		Expect(client.PutBins(nil, key, NewBin("status", "closed"))).ToNot(HaveOccurred())
+
+		policy := NewOperatePolicy()
+		policy.ReturnFilteredOut = true
+		rec, err := withdraw(policy)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rec.FilteredOut).To(BeTrue())
+		Expect(rec.Key).To(Equal(key))
+		Expect(len(rec.Bins)).To(Equal(0))
+
+		rec, err = client.Get(nil, key)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rec.Bins["balance"]).To(Equal(100))
+	})
+
+	It("must work as Operate without a filter expression", func() {
+		rec, err := client.OperateFiltered(nil, key, AddOp(NewBin("balance", 1)), GetOpForBin("balance"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rec.Bins).To(Equal(BinMap{"balance": 101}))
+	})
+
+})
@@ -0,0 +1,49 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+// OperatePolicy encapsulates parameters for OperateFiltered commands, which
+// are only applied to records matching a filter expression.
+type OperatePolicy struct {
+	WritePolicy
+
+	// FilterExpression determines the predicates evaluated on the server
+	// against the record before the operations are applied. If the record
+	// does not match them, none of the operations are applied, and the command
+	// fails with FILTERED_OUT. See PredExp for details.
+	// Requires servers supporting predicate expressions on record commands.
+	FilterExpression []PredExp
+
+	// ReturnFilteredOut determines if records which do not match the
+	// FilterExpression are returned as a Record with FilteredOut set,
+	// instead of as a FILTERED_OUT error.
+	ReturnFilteredOut bool //= false
+}
+
+// NewOperatePolicy initializes a new OperatePolicy instance with default parameters.
+func NewOperatePolicy() *OperatePolicy {
+	return &OperatePolicy{
+		WritePolicy: *NewWritePolicy(0, 0),
+	}
+}
+
+// Clone returns a copy of the policy. The Context and Txn are shared.
+func (p *OperatePolicy) Clone() *OperatePolicy {
+	res := *p
+	if p.FilterExpression != nil {
+		res.FilterExpression = append([]PredExp(nil), p.FilterExpression...)
+	}
+	return &res
+}
@@ -23,13 +23,20 @@ const (
 	READ OperationType = 1
 	// READ_HEADER OperationType = 1
 
-	WRITE   OperationType = 2
-	ADD     OperationType = 5
-	APPEND  OperationType = 9
-	PREPEND OperationType = 10
-	TOUCH   OperationType = 11
+	WRITE      OperationType = 2
+	CDT_READ   OperationType = 3
+	CDT_MODIFY OperationType = 4
+	ADD        OperationType = 5
+	APPEND     OperationType = 9
+	PREPEND    OperationType = 10
+	TOUCH      OperationType = 11
 )
 
+// MaxOperations is the maximum number of operations
+// allowed in a single Operate command.
+// Use Client.OperateChunked to perform more operations on a record.
+const MaxOperations = 255
+
 // Operation contasins operation definition.
 // This struct is used in client's operate() method.
 type Operation struct {
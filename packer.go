@@ -201,6 +201,11 @@ func (pckr *packer) PackObject(obj interface{}) error {
 		return pckr.PackMap(obj.(map[interface{}]interface{}))
 	}
 
+	// check for user registered types
+	if cv := newCustomValue(obj); cv != nil {
+		return cv.pack(pckr)
+	}
+
 	// check for array and map
 	rv := reflect.ValueOf(obj)
 	switch reflect.TypeOf(obj).Kind() {
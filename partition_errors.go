@@ -0,0 +1,231 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	. "github.com/THE108/aerospike-client-go/types"
+)
+
+// PartitionErrors contains the errors of single record commands
+// on a partition, as seen by the client. Only network errors, timeouts,
+// overloaded devices and unavailable partitions are counted; per-record
+// outcomes like KEY_NOT_FOUND_ERROR or GENERATION_ERROR are not.
+type PartitionErrors struct {
+	Namespace   string
+	PartitionId int
+
+	// ReadErrors is the number of failed read commands.
+	ReadErrors int64
+
+	// WriteErrors is the number of failed write, delete, touch, UDF
+	// and operate commands with write operations.
+	WriteErrors int64
+
+	// ResultCodes counts the timeouts, overloaded devices and
+	// unavailable partitions by their result code.
+	ResultCodes map[ResultCode]int64
+
+	// NetworkErrors counts the errors without a result code,
+	// e.g. connection resets.
+	NetworkErrors int64
+
+	// Nodes counts the errors by the name of the node
+	// the command was last sent to.
+	Nodes map[string]int64
+
+	// LastError is the time of the last error.
+	LastError time.Time
+}
+
+// Total returns the number of read and write errors of the partition.
+func (pe *PartitionErrors) Total() int64 {
+	return pe.ReadErrors + pe.WriteErrors
+}
+
+func (pe *PartitionErrors) clone() *PartitionErrors {
+	res := *pe
+	res.ResultCodes = make(map[ResultCode]int64, len(pe.ResultCodes))
+	for k, v := range pe.ResultCodes {
+		res.ResultCodes[k] = v
+	}
+	res.Nodes = make(map[string]int64, len(pe.Nodes))
+	for k, v := range pe.Nodes {
+		res.Nodes[k] = v
+	}
+	return &res
+}
+
+// partitionCommand is implemented by commands targeting a single partition.
+type partitionCommand interface {
+	getCluster() *Cluster
+	getPartition() *Partition
+}
+
+// partitionErrorStats aggregates command errors by partition.
+type partitionErrorStats struct {
+	mutex      sync.Mutex
+	partitions map[Partition]*PartitionErrors
+}
+
+func newPartitionErrorStats() *partitionErrorStats {
+	return &partitionErrorStats{partitions: map[Partition]*PartitionErrors{}}
+}
+
+func (pes *partitionErrorStats) record(partition *Partition, node *Node, write bool, err error) {
+	if pes == nil || partition == nil || !isPartitionError(err) {
+		return
+	}
+
+	pes.mutex.Lock()
+	defer pes.mutex.Unlock()
+
+	pe := pes.partitions[*partition]
+	if pe == nil {
+		pe = &PartitionErrors{
+			Namespace:   partition.Namespace,
+			PartitionId: partition.PartitionId,
+			ResultCodes: map[ResultCode]int64{},
+			Nodes:       map[string]int64{},
+		}
+		pes.partitions[*partition] = pe
+	}
+
+	if write {
+		pe.WriteErrors++
+	} else {
+		pe.ReadErrors++
+	}
+
+	if ae, ok := err.(AerospikeError); ok {
+		pe.ResultCodes[ae.ResultCode()]++
+	} else {
+		pe.NetworkErrors++
+	}
+
+	if node != nil {
+		pe.Nodes[node.GetName()]++
+	}
+	pe.LastError = time.Now()
+}
+
+// snapshot returns a copy of the partitions with errors, most errors first.
+func (pes *partitionErrorStats) snapshot() []*PartitionErrors {
+	pes.mutex.Lock()
+	res := make([]*PartitionErrors, 0, len(pes.partitions))
+	for _, pe := range pes.partitions {
+		res = append(res, pe.clone())
+	}
+	pes.mutex.Unlock()
+
+	sort.Sort(partitionErrorsByTotal(res))
+	return res
+}
+
+// heatmap returns the error totals of the namespace, indexed by partition id.
+func (pes *partitionErrorStats) heatmap(namespace string) []int64 {
+	res := make([]int64, _PARTITIONS)
+
+	pes.mutex.Lock()
+	defer pes.mutex.Unlock()
+
+	for ptn, pe := range pes.partitions {
+		if ptn.Namespace == namespace {
+			res[ptn.PartitionId] = pe.Total()
+		}
+	}
+	return res
+}
+
+func (pes *partitionErrorStats) reset() {
+	pes.mutex.Lock()
+	pes.partitions = map[Partition]*PartitionErrors{}
+	pes.mutex.Unlock()
+}
+
+type partitionErrorsByTotal []*PartitionErrors
+
+func (s partitionErrorsByTotal) Len() int      { return len(s) }
+func (s partitionErrorsByTotal) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s partitionErrorsByTotal) Less(i, j int) bool {
+	if s[i].Total() != s[j].Total() {
+		return s[i].Total() > s[j].Total()
+	}
+	if s[i].Namespace != s[j].Namespace {
+		return s[i].Namespace < s[j].Namespace
+	}
+	return s[i].PartitionId < s[j].PartitionId
+}
+
+// isPartitionError returns true if the error points at the health of the
+// partition: network errors, timeouts, overloaded devices and unavailable
+// partitions.
+func isPartitionError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	ae, ok := err.(AerospikeError)
+	if !ok {
+		return true
+	}
+
+	switch ae.ResultCode() {
+	case TIMEOUT, DEVICE_OVERLOAD, SERVER_NOT_AVAILABLE, INVALID_NODE_ERROR:
+		return true
+	}
+	return false
+}
+
+// isWriteCommand determines if the command changes the record.
+func isWriteCommand(ifc command) bool {
+	switch cmd := ifc.(type) {
+	case *writeCommand, *deleteCommand, *touchCommand, *executeCommand:
+		return true
+	case *operateCommand:
+		for _, op := range cmd.operations {
+			if op.OpType != READ && op.OpType != CDT_READ {
+				return true
+			}
+		}
+	case *rawCommand:
+		return cmd.isWrite()
+	case *txnRecordCommand:
+		// only verifying a transaction read does not change the record
+		return cmd.kind != txnVerify
+	}
+	return false
+}
+
+// PartitionErrors returns a snapshot of the errors of single record
+// commands aggregated by partition, with the partitions with the most
+// errors first. Only partitions with errors are returned.
+func (clnt *Client) PartitionErrors() []*PartitionErrors {
+	return clnt.cluster.partitionErrors.snapshot()
+}
+
+// PartitionErrorHeatmap returns the number of errors of each partition
+// of the namespace, indexed by partition id.
+func (clnt *Client) PartitionErrorHeatmap(namespace string) []int64 {
+	return clnt.cluster.partitionErrors.heatmap(namespace)
+}
+
+// ResetPartitionErrors clears the partition error statistics.
+func (clnt *Client) ResetPartitionErrors() {
+	clnt.cluster.partitionErrors.reset()
+}
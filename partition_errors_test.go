@@ -0,0 +1,89 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"errors"
+
+	. "github.com/THE108/aerospike-client-go/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Partition Errors Test", func() {
+
+	It("should aggregate errors by partition", func() {
+		stats := newPartitionErrorStats()
+		stats.record(NewPartition("test", 7), nil, false, NewAerospikeError(TIMEOUT))
+		stats.record(NewPartition("test", 7), nil, true, NewAerospikeError(DEVICE_OVERLOAD))
+		stats.record(NewPartition("test", 7), nil, true, errors.New("connection reset"))
+		stats.record(NewPartition("test", 9), nil, false, NewAerospikeError(TIMEOUT))
+		stats.record(NewPartition("bar", 7), nil, false, NewAerospikeError(TIMEOUT))
+		stats.record(NewPartition("test", 9), nil, false, nil)
+
+		snapshot := stats.snapshot()
+		Expect(len(snapshot)).To(Equal(3))
+
+		hottest := snapshot[0]
+		Expect(hottest.Namespace).To(Equal("test"))
+		Expect(hottest.PartitionId).To(Equal(7))
+		Expect(hottest.ReadErrors).To(Equal(int64(1)))
+		Expect(hottest.WriteErrors).To(Equal(int64(2)))
+		Expect(hottest.NetworkErrors).To(Equal(int64(1)))
+		Expect(hottest.ResultCodes).To(Equal(map[ResultCode]int64{TIMEOUT: 1, DEVICE_OVERLOAD: 1}))
+		Expect(snapshot[1].Namespace).To(Equal("bar"))
+
+		heatmap := stats.heatmap("test")
+		Expect(len(heatmap)).To(Equal(_PARTITIONS))
+		Expect(heatmap[7]).To(Equal(int64(3)))
+		Expect(heatmap[9]).To(Equal(int64(1)))
+		Expect(heatmap[8]).To(Equal(int64(0)))
+
+		// snapshots are copies
+		hottest.ResultCodes[TIMEOUT] = 100
+		Expect(stats.snapshot()[0].ResultCodes[TIMEOUT]).To(Equal(int64(1)))
+
+		stats.reset()
+		Expect(len(stats.snapshot())).To(Equal(0))
+	})
+
+	It("should not count per-record outcomes", func() {
+		stats := newPartitionErrorStats()
+		for _, code := range []ResultCode{KEY_NOT_FOUND_ERROR, KEY_EXISTS_ERROR, GENERATION_ERROR, FILTERED_OUT} {
+			stats.record(NewPartition("test", 7), nil, true, NewAerospikeError(code))
+		}
+		Expect(stats.snapshot()).To(BeEmpty())
+
+		stats.record(NewPartition("test", 7), nil, false, NewAerospikeError(INVALID_NODE_ERROR))
+		stats.record(NewPartition("test", 7), nil, false, NewAerospikeError(SERVER_NOT_AVAILABLE))
+		Expect(stats.heatmap("test")[7]).To(Equal(int64(2)))
+	})
+
+	It("should classify write commands", func() {
+		key, _ := NewKey("test", "test", 1)
+		Expect(isWriteCommand(newOperateCommand(nil, NewWritePolicy(0, 0), key, []*Operation{GetOp()}))).To(BeFalse())
+		Expect(isWriteCommand(newOperateCommand(nil, NewWritePolicy(0, 0), key, []*Operation{AddOp(NewBin("a", 1)), GetOp()}))).To(BeTrue())
+		Expect(isWriteCommand(newDeleteCommand(nil, NewWritePolicy(0, 0), key))).To(BeTrue())
+		Expect(isWriteCommand(newReadCommand(nil, NewPolicy(), key, nil))).To(BeFalse())
+
+		txn := NewTxn()
+		Expect(isWriteCommand(newTxnRecordCommand(nil, NewWritePolicy(0, 0), txn, key, txnVerify))).To(BeFalse())
+		Expect(isWriteCommand(newTxnRecordCommand(nil, NewWritePolicy(0, 0), txn, key, txnRollForward))).To(BeTrue())
+		Expect(isWriteCommand(newTxnRecordCommand(nil, NewWritePolicy(0, 0), txn, key, txnRollBack))).To(BeTrue())
+		Expect(isWriteCommand(newTxnRecordCommand(nil, NewWritePolicy(0, 0), txn, key, txnClose))).To(BeTrue())
+	})
+
+})
@@ -0,0 +1,118 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"encoding/base64"
+
+	. "github.com/THE108/aerospike-client-go/types/atomic"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Partition Replicas Test", func() {
+
+	var n1, n2, n3 *Node
+	var cluster *Cluster
+	var regimes map[string][]int
+
+	// bitmap returns the base64 encoded bitmap of the partitions
+	bitmap := func(partitions ...int) string {
+		buf := make([]byte, _PARTITIONS/8)
+		for _, p := range partitions {
+			buf[p>>3] |= 0x80 >> uint(p&7)
+		}
+		return base64.StdEncoding.EncodeToString(buf)
+	}
+
+	update := func(node *Node, info string) {
+		pt := &partitionTokenizerReplicas{info: info}
+		nmap, rmap, err := pt.UpdatePartition(cluster.getPartitions(), cluster.getReplicas(), regimes, node)
+		Expect(err).ToNot(HaveOccurred())
+		if nmap != nil {
+			cluster.setPartitions(nmap)
+		}
+		if rmap != nil {
+			cluster.setReplicas(rmap)
+		}
+	}
+
+	partition := func(id int) *Partition {
+		return NewPartition("test", id)
+	}
+
+	BeforeEach(func() {
+		newNode := func(name string) *Node {
+			return &Node{name: name, active: NewAtomicBool(true)}
+		}
+		n1, n2, n3 = newNode("n1"), newNode("n2"), newNode("n3")
+
+		cluster = &Cluster{
+			partitionWriteMap:   map[string]*AtomicArray{},
+			partitionReplicaMap: map[string][]*AtomicArray{},
+		}
+		regimes = map[string][]int{}
+	})
+
+	It("should build the replica table from replicas-all bitmaps", func() {
+		update(n1, "test:2,"+bitmap(0, 1)+","+bitmap(2))
+		update(n2, "test:2,"+bitmap(2)+","+bitmap(0))
+		update(n3, "test:2,"+bitmap()+","+bitmap(1))
+
+		Expect(cluster.GetReplicaNodes(partition(0))).To(Equal([]*Node{n1, n2}))
+		Expect(cluster.GetReplicaNodes(partition(1))).To(Equal([]*Node{n1, n3}))
+		Expect(cluster.GetReplicaNodes(partition(2))).To(Equal([]*Node{n2, n1}))
+		Expect(cluster.GetReplicaNodes(partition(3))).To(BeEmpty())
+
+		node, err := cluster.GetNode(partition(2))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(node).To(Equal(n2))
+	})
+
+	It("should drop replicas the node does not hold anymore", func() {
+		update(n1, "test:3,"+bitmap(0)+","+bitmap(1)+","+bitmap(2))
+		Expect(cluster.GetReplicaNodes(partition(2))).To(Equal([]*Node{n1}))
+
+		update(n1, "test:2,"+bitmap(0)+","+bitmap(2))
+		Expect(cluster.GetReplicaNodes(partition(1))).To(BeEmpty())
+		Expect(cluster.GetReplicaNodes(partition(2))).To(Equal([]*Node{n1}))
+
+		update(n1, "test:1,"+bitmap(0))
+		Expect(cluster.GetReplicaNodes(partition(2))).To(BeEmpty())
+	})
+
+	It("should skip inactive nodes and fall back to the master", func() {
+		update(n1, "test:5,2,"+bitmap(0)+","+bitmap(1))
+		update(n2, "test:5,2,"+bitmap(1)+","+bitmap(0))
+		n2.active.Set(false)
+		Expect(cluster.GetReplicaNodes(partition(0))).To(Equal([]*Node{n1}))
+
+		cluster.partitionReplicaMap = map[string][]*AtomicArray{}
+		Expect(cluster.GetReplicaNodes(partition(0))).To(Equal([]*Node{n1}))
+	})
+
+	It("should hedge to the replicas of the partition", func() {
+		update(n1, "test:2,"+bitmap(0)+","+bitmap(1))
+		update(n3, "test:2,"+bitmap(1)+","+bitmap(0))
+		cluster.nodes = []*Node{n1, n2, n3}
+		cluster.nodeIndex = NewAtomicInt(0)
+
+		for i := 0; i < 4; i++ {
+			Expect(cluster.hedgeNode(partition(0))).To(Equal(n3))
+		}
+	})
+
+})
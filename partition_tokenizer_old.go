@@ -65,6 +65,10 @@ func (pt *partitionTokenizerOld) UpdatePartition(nmap map[string]*AtomicArray, n
 			break
 		}
 		nodeArray, exists := nmap[partition.Namespace]
+		if copied && !exists {
+			// the namespace may have been added for a previous partition
+			nodeArray, exists = amap[partition.Namespace]
+		}
 
 		if !exists {
 			if !copied {
@@ -76,7 +80,7 @@ func (pt *partitionTokenizerOld) UpdatePartition(nmap map[string]*AtomicArray, n
 				copied = true
 			}
 
-			nodeArray := NewAtomicArray(_PARTITIONS)
+			nodeArray = NewAtomicArray(_PARTITIONS)
 			amap[partition.Namespace] = nodeArray
 		}
 		Logger.Debug(partition.String() + "," + node.name)
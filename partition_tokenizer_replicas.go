@@ -0,0 +1,213 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+
+	. "github.com/THE108/aerospike-client-go/types"
+	. "github.com/THE108/aerospike-client-go/types/atomic"
+)
+
+const (
+	replicasRegimeName = "replicas"
+	replicasAllName    = "replicas-all"
+)
+
+// partitionTokenizerReplicas parses the partition map with all the replicas
+// of each partition, for nodes supporting the `replicas` or `replicas-all`
+// info commands. The `replicas` command also sends the regime of each
+// namespace. Regimes are increased by the server on every cluster change in
+// strong consistency namespaces; a node claiming a partition with a regime
+// older than the one already known is ignored, so reads never go to a stale
+// master.
+type partitionTokenizerReplicas struct {
+	info string
+}
+
+func newPartitionTokenizerReplicas(conn *Connection, command string) (*partitionTokenizerReplicas, error) {
+	// Send format:    replicas\n
+	// Receive format: replicas\t<ns1>:[<regime>,]<count>,<base 64 encoded bitmap>,...;<ns2>:...\n
+	infoMap, err := RequestInfo(conn, command)
+	if err != nil {
+		return nil, err
+	}
+
+	info := strings.TrimSpace(infoMap[command])
+	if len(info) == 0 {
+		return nil, NewAerospikeError(PARSE_ERROR, command+" is empty")
+	}
+
+	return &partitionTokenizerReplicas{info: info}, nil
+}
+
+// UpdatePartition sets the node as the master of the partitions of the first
+// bitmap of each namespace, and as the replica of the partitions of the
+// following bitmaps, unless a newer regime is already known for the
+// partition. The partition and replica maps are copied if namespaces or
+// replicas are added; the new maps are returned in that case, nil otherwise.
+// regimes is updated in place.
+func (pt *partitionTokenizerReplicas) UpdatePartition(nmap map[string]*AtomicArray, rmap map[string][]*AtomicArray, regimes map[string][]int, node *Node) (map[string]*AtomicArray, map[string][]*AtomicArray, error) {
+	var amap map[string]*AtomicArray
+	var armap map[string][]*AtomicArray
+
+	for _, entry := range strings.Split(pt.info, ";") {
+		if len(entry) == 0 {
+			continue
+		}
+
+		sep := strings.IndexByte(entry, ':')
+		if sep < 0 {
+			return nil, nil, NewAerospikeError(PARSE_ERROR, "Invalid partition entry. Response="+pt.getTruncatedResponse())
+		}
+
+		namespace := strings.TrimSpace(entry[:sep])
+		if len(namespace) <= 0 || len(namespace) >= 32 {
+			return nil, nil, NewAerospikeError(PARSE_ERROR, "Invalid partition namespace "+
+				namespace+". Response="+pt.getTruncatedResponse())
+		}
+
+		regime, bitmaps, err := parseReplicasEntry(entry[sep+1:])
+		if err != nil {
+			return nil, nil, NewAerospikeError(PARSE_ERROR, "Invalid partitions for namespace "+
+				namespace+": "+err.Error()+". Response="+pt.getTruncatedResponse())
+		}
+
+		if len(bitmaps) == 0 {
+			continue
+		}
+
+		buffers := make([][]byte, len(bitmaps))
+		for j := range bitmaps {
+			if buffers[j], err = base64.StdEncoding.DecodeString(bitmaps[j]); err != nil {
+				return nil, nil, err
+			}
+			if len(buffers[j]) < (_PARTITIONS+7)/8 {
+				return nil, nil, NewAerospikeError(PARSE_ERROR, "Partition bitmap for namespace "+namespace+" is too short")
+			}
+		}
+
+		nodeArray, exists := nmap[namespace]
+		if !exists && amap != nil {
+			nodeArray, exists = amap[namespace]
+		}
+		if !exists {
+			if amap == nil {
+				// Make shallow copy of map.
+				amap = make(map[string]*AtomicArray, len(nmap)+1)
+				for k, v := range nmap {
+					amap[k] = v
+				}
+			}
+
+			nodeArray = NewAtomicArray(_PARTITIONS)
+			amap[namespace] = nodeArray
+		}
+
+		replicas := rmap[namespace]
+		if armap != nil {
+			if r, exists := armap[namespace]; exists {
+				replicas = r
+			}
+		}
+		if len(replicas) < len(bitmaps) {
+			if armap == nil {
+				// Make shallow copy of map.
+				armap = make(map[string][]*AtomicArray, len(rmap)+1)
+				for k, v := range rmap {
+					armap[k] = v
+				}
+			}
+
+			// the arrays of the existing replicas are shared with the old map
+			grown := make([]*AtomicArray, len(bitmaps))
+			copy(grown, replicas)
+			for j := len(replicas); j < len(grown); j++ {
+				grown[j] = NewAtomicArray(_PARTITIONS)
+			}
+			replicas = grown
+			armap[namespace] = replicas
+		}
+
+		nsRegimes := regimes[namespace]
+		if nsRegimes == nil {
+			nsRegimes = make([]int, _PARTITIONS)
+			regimes[namespace] = nsRegimes
+		}
+
+		for j, buffer := range buffers {
+			for i := 0; i < _PARTITIONS; i++ {
+				if (buffer[i>>3] & (0x80 >> uint((i & 7)))) != 0 {
+					if regime < nsRegimes[i] {
+						continue
+					}
+					if j == 0 {
+						nsRegimes[i] = regime
+						nodeArray.Set(i, node)
+					}
+					replicas[j].Set(i, node)
+				} else if j > 0 && replicas[j].Get(i) == node {
+					// the node does not hold this replica anymore
+					replicas[j].Set(i, nil)
+				}
+			}
+		}
+
+		// the node does not hold the replicas beyond the ones it reported
+		for j := len(buffers); j < len(replicas); j++ {
+			for i := 0; i < _PARTITIONS; i++ {
+				if replicas[j].Get(i) == node {
+					replicas[j].Set(i, nil)
+				}
+			}
+		}
+	}
+
+	return amap, armap, nil
+}
+
+// parseReplicasEntry parses `[<regime>,]<count>,<bitmap>,...`. The regime is
+// only sent by servers with strong consistency support; it is 0 otherwise.
+func parseReplicasEntry(entry string) (regime int, bitmaps []string, err error) {
+	fields := strings.Split(entry, ",")
+
+	values := make([]int, 0, 2)
+	for len(values) < 2 && len(values) < len(fields) {
+		v, err := strconv.Atoi(fields[len(values)])
+		if err != nil {
+			break
+		}
+		values = append(values, v)
+	}
+
+	switch {
+	case len(values) == 2 && len(fields) == values[1]+2:
+		regime, bitmaps = values[0], fields[2:]
+	case len(values) >= 1 && len(fields) == values[0]+1:
+		bitmaps = fields[1:]
+	default:
+		return 0, nil, NewAerospikeError(PARSE_ERROR, "replica count does not match the bitmaps")
+	}
+	return regime, bitmaps, nil
+}
+
+func (pt *partitionTokenizerReplicas) getTruncatedResponse() string {
+	if len(pt.info) > 200 {
+		return pt.info[:200]
+	}
+	return pt.info
+}
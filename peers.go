@@ -0,0 +1,212 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"net"
+	"strconv"
+	"strings"
+
+	. "github.com/THE108/aerospike-client-go/logger"
+	. "github.com/THE108/aerospike-client-go/types"
+)
+
+// friendsInfo returns the info commands to discover the other nodes of the
+// cluster. Nodes supporting the peers protocol are asked for the generation
+// of their peers list only; the list is requested when it changes.
+// If the features of a node are not known, both protocols are asked for.
+func (nd *Node) friendsInfo() []string {
+	nd.featureMutex.RLock()
+	_, supportsPeers := nd.features[FeaturePeers]
+	featuresKnown := nd.features != nil
+	nd.featureMutex.RUnlock()
+
+	switch {
+	case !featuresKnown:
+		return []string{"services", "peers-generation"}
+	case supportsPeers:
+		return []string{"peers-generation"}
+	}
+	return []string{"services"}
+}
+
+// peersInfo returns the info command for the peers list, with the TLS
+// addresses if the client connects over TLS.
+func (nd *Node) peersInfo() string {
+	if nd.cluster.policy().TLSConfig != nil {
+		return "peers-tls-std"
+	}
+	return "peers-clear-std"
+}
+
+// addPeers requests the peers list of the node if its generation changed
+// since the last refresh, and returns the peers which are not known yet.
+// The peers last received are referenced on every refresh, so that nodes
+// are not removed while the list does not change.
+// Peers are only accessed by the tend goroutine.
+func (nd *Node) addPeers(conn *Connection, infoMap map[string]string) ([]*Host, error) {
+	genString, exists := infoMap["peers-generation"]
+	if !exists || len(genString) == 0 {
+		return nil, NewAerospikeError(PARSE_ERROR, "peers-generation is empty")
+	}
+
+	generation, err := strconv.Atoi(genString)
+	if err != nil {
+		return nil, NewAerospikeError(PARSE_ERROR, "Invalid peers-generation: "+genString)
+	}
+
+	if nd.peersGeneration.Get() != generation {
+		command := nd.peersInfo()
+		peersMap, err := RequestInfo(conn, command)
+		if err != nil {
+			return nil, err
+		}
+
+		// the list may be newer than the generation requested before
+		generation, peers, err := parsePeers(peersMap[command])
+		if err != nil {
+			return nil, err
+		}
+
+		Logger.Info("Node %s peers generation %d changed", nd.GetName(), generation)
+		nd.peers = peers
+		nd.peersGeneration.Set(generation)
+	}
+
+	return nd.referenceFriends(nd.peers), nil
+}
+
+// parsePeers parses a peers list in the format
+// `<generation>,<default port>,[[<node name>,<tls name>,[<address>[:<port>],...]],...]`.
+// IPv6 addresses are enclosed in brackets. The first address of each peer
+// is used; the others are aliases of the same node and are learned when
+// the node is added.
+func parsePeers(response string) (generation int, peers []*Host, err error) {
+	p := &peersParser{s: strings.TrimSpace(response)}
+
+	generation, err = strconv.Atoi(p.token())
+	if err != nil || !p.expect(',') {
+		return 0, nil, p.error()
+	}
+
+	defaultPort, err := strconv.Atoi(p.token())
+	if err != nil || !p.expect(',') || !p.expect('[') {
+		return 0, nil, p.error()
+	}
+
+	for !p.expect(']') {
+		if len(peers) > 0 && !p.expect(',') {
+			return 0, nil, p.error()
+		}
+		if !p.expect('[') {
+			return 0, nil, p.error()
+		}
+
+		// node name
+		p.token()
+		if !p.expect(',') {
+			return 0, nil, p.error()
+		}
+		tlsName := p.token()
+		if !p.expect(',') || !p.expect('[') {
+			return 0, nil, p.error()
+		}
+
+		var host *Host
+		for i := 0; !p.expect(']'); i++ {
+			if i > 0 && !p.expect(',') {
+				return 0, nil, p.error()
+			}
+			address := p.address()
+			if address == "" {
+				return 0, nil, p.error()
+			}
+			if host == nil {
+				if host, err = parsePeerAddress(address, defaultPort); err != nil {
+					return 0, nil, err
+				}
+				host.TLSName = tlsName
+			}
+		}
+
+		if !p.expect(']') {
+			return 0, nil, p.error()
+		}
+		if host != nil {
+			peers = append(peers, host)
+		}
+	}
+
+	return generation, peers, nil
+}
+
+// parsePeerAddress parses `host`, `host:port`, `[ipv6]` or `[ipv6]:port`.
+func parsePeerAddress(address string, defaultPort int) (*Host, error) {
+	host, port := address, defaultPort
+	if strings.HasPrefix(address, "[") || strings.Count(address, ":") == 1 {
+		if h, p, err := net.SplitHostPort(address); err == nil {
+			host = h
+			if port, err = strconv.Atoi(p); err != nil {
+				return nil, NewAerospikeError(PARSE_ERROR, "Invalid peer address: "+address)
+			}
+		} else {
+			host = strings.TrimSuffix(strings.TrimPrefix(address, "["), "]")
+		}
+	}
+	return NewHost(host, port), nil
+}
+
+// peersParser reads the tokens of a peers list.
+type peersParser struct {
+	s   string
+	pos int
+}
+
+// expect consumes the character c if it is next.
+func (p *peersParser) expect(c byte) bool {
+	if p.pos < len(p.s) && p.s[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// token reads up to the next delimiter.
+func (p *peersParser) token() string {
+	start := p.pos
+	for p.pos < len(p.s) && strings.IndexByte(",[]", p.s[p.pos]) < 0 {
+		p.pos++
+	}
+	return p.s[start:p.pos]
+}
+
+// address reads an address, which may contain a bracketed IPv6 address.
+func (p *peersParser) address() string {
+	start := p.pos
+	if p.expect('[') {
+		for p.pos < len(p.s) && p.s[p.pos] != ']' {
+			p.pos++
+		}
+		if !p.expect(']') {
+			return ""
+		}
+	}
+	p.token()
+	return p.s[start:p.pos]
+}
+
+func (p *peersParser) error() error {
+	return NewAerospikeError(PARSE_ERROR, "Invalid peers list at position "+strconv.Itoa(p.pos)+": "+p.s)
+}
@@ -0,0 +1,69 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Peers Parser Test", func() {
+
+	host := func(name string, port int, tlsName string) *Host {
+		h := NewHost(name, port)
+		h.TLSName = tlsName
+		return h
+	}
+
+	It("should parse peers with default and explicit ports", func() {
+		gen, peers, err := parsePeers("6,3000,[[BB9050011AC4202,,[172.17.0.4]],[BB9070011AC4202,,[172.17.0.6:3100,10.0.0.6]]]")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(gen).To(Equal(6))
+		Expect(peers).To(Equal([]*Host{
+			host("172.17.0.4", 3000, ""),
+			host("172.17.0.6", 3100, ""),
+		}))
+	})
+
+	It("should parse TLS names and IPv6 addresses", func() {
+		gen, peers, err := parsePeers("2,4333,[[BB9050011AC4202,node1.example.com,[[2001:db8::1]:4000]],[BB9070011AC4202,node2.example.com,[[2001:db8::2]]]]")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(gen).To(Equal(2))
+		Expect(peers).To(Equal([]*Host{
+			host("2001:db8::1", 4000, "node1.example.com"),
+			host("2001:db8::2", 4333, "node2.example.com"),
+		}))
+	})
+
+	It("should parse empty peers lists", func() {
+		gen, peers, err := parsePeers("0,3000,[]")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(gen).To(Equal(0))
+		Expect(peers).To(BeEmpty())
+
+		// peers without addresses are skipped
+		_, peers, err = parsePeers("1,3000,[[BB9050011AC4202,,[]]]")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(peers).To(BeEmpty())
+	})
+
+	It("should reject malformed peers lists", func() {
+		for _, response := range []string{"", "x,3000,[]", "1,3000", "1,3000,[[BB9,,[1.2.3.4]]", "1,3000,[[BB9,[1.2.3.4]]]", "1,3000,[[BB9,,[1.2.3.4:x]]]"} {
+			_, _, err := parsePeers(response)
+			Expect(err).To(HaveOccurred(), response)
+		}
+	})
+
+})
@@ -0,0 +1,72 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike_test
+
+import (
+	. "github.com/THE108/aerospike-client-go"
+	"github.com/THE108/aerospike-client-go/aerotest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Peers discovery", func() {
+
+	var srv1, srv2 *aerotest.Server
+	var client *Client
+
+	BeforeEach(func() {
+		var err error
+		srv1, err = aerotest.NewServer("test")
+		Expect(err).ToNot(HaveOccurred())
+		srv2, err = aerotest.NewServer("test")
+		Expect(err).ToNot(HaveOccurred())
+
+		srv2.SetNodeName("BB9AEROTEST0002")
+		for _, srv := range []*aerotest.Server{srv1, srv2} {
+			srv.SetFeatures("peers", "pipelining", "replicas-master")
+		}
+		srv1.AddPeer(srv2)
+		srv2.AddPeer(srv1)
+
+		client, err = NewClient(srv1.Host(), srv1.Port())
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		client.Close()
+		srv1.Close()
+		srv2.Close()
+	})
+
+	It("must discover the nodes of the cluster from the peers of the seed", func() {
+		names := []string{}
+		for _, node := range client.GetNodes() {
+			names = append(names, node.GetName())
+		}
+		Expect(names).To(ConsistOf(aerotest.NodeName, "BB9AEROTEST0002"))
+	})
+
+	It("must keep the nodes while the peers do not change", func() {
+		for i := 0; i < 3; i++ {
+			for _, node := range client.GetNodes() {
+				_, err := node.Refresh()
+				Expect(err).ToNot(HaveOccurred())
+			}
+		}
+		Expect(len(client.GetNodes())).To(Equal(2))
+	})
+
+})
@@ -15,6 +15,8 @@
 package aerospike
 
 import (
+	"context"
+	"math"
 	"time"
 )
 
@@ -26,18 +28,35 @@ type Policy interface {
 
 // BasePolicy excapsulates parameters for transaction policy attributes
 // used in all database operation calls.
+//
+// Policies are never modified by the client, which copies them when a command
+// is called. A policy can be shared between goroutines, and modified once the
+// calls using it have returned; use Clone to derive policies concurrently.
 type BasePolicy struct {
 	Policy
 
 	// Priority of request relative to other transactions.
-	// Currently, only used for scans.
+	// It is sent to the server with scans, and with queries without filters,
+	// which the server runs as scans. Use LOW for background jobs, so they
+	// yield to online traffic. The wire protocol has no priority for single
+	// record, batch and secondary index query commands; they are run at the
+	// priority of the server's transaction threads.
 	Priority Priority //= Priority.DEFAULT;
 
 	// How replicas should be consulted in a read operation to provide the desired
 	// consistency guarantee. Default to allowing one replica to be used in the
 	// read operation.
+	// Equivalent to ReadModeAP; all replicas are consulted if either is set to ALL.
 	ConsistencyLevel ConsistencyLevel //= CONSISTENCY_ONE
 
+	// ReadModeAP determines how many replicas are consulted in reads of
+	// namespaces which are not in strong consistency mode.
+	ReadModeAP ReadModeAP //= AP_ONE
+
+	// ReadModeSC determines the read consistency guarantee for namespaces
+	// in strong consistency mode. It is ignored for other namespaces.
+	ReadModeSC ReadModeSC //= SC_SESSION
+
 	// Timeout specifies transaction timeout.
 	// This timeout is used to set the socket timeout and is also sent to the
 	// server along with the transaction in the wire protocol.
@@ -53,6 +72,49 @@ type BasePolicy struct {
 	// SleepBetweenReplies determines duration to sleep between retries if a transaction fails and the
 	// timeout was not exceeded.  Enter zero to skip sleep.
 	SleepBetweenRetries time.Duration //= 500ms;
+
+	// SortBatchKeys sorts the keys of batch reads by namespace and partition
+	// before they are sent, for better cache locality on the server, and sends
+	// keys with identical digests only once. Results are still returned in the
+	// order of the original keys; duplicate keys share the same record.
+	// Currently, only used for batch reads.
+	SortBatchKeys bool //= false
+
+	// HedgeDelay, if set, sends a second request for Get, GetHeader and Exists
+	// to another node if the first one has not answered within the delay.
+	// The first successful response is returned. It trades extra load for
+	// lower tail latency; use a delay around the p99 latency of reads.
+	// Commands in a transaction are never hedged.
+	HedgeDelay time.Duration //= 0 (disabled)
+
+	// DeduplicateReads makes concurrent Gets of the same record and bins
+	// share a single command: while a Get is in flight, identical Gets wait
+	// for its result instead of sending their own request, using the policy
+	// of the first Get. Waiting Gets return when their Context is done.
+	// Gets started after a write of the record through the client completed
+	// never share the result of a read started before it.
+	// Commands in a transaction are never deduplicated.
+	DeduplicateReads bool //= false
+
+	// LatencyAwareReads sends the first attempt of single record reads to
+	// the master or a replica of the record's partition, chosen at random
+	// with a probability inversely proportional to the node's Latency, so
+	// consistently slow nodes receive fewer reads. Retries follow the usual
+	// rules. Reads in strong consistency mode which require the master are
+	// proxied to it by the replica; use SC_ALLOW_REPLICA to avoid the extra hop.
+	// Commands in a transaction and writes are always sent to the master.
+	LatencyAwareReads bool //= false
+
+	// Context optionally carries per-call metadata attached with WithBaggage.
+	// The metadata is passed on to ClientPolicy.CommandObserver and debug logs.
+	// If the context has a deadline, the time remaining until the deadline is
+	// used as the timeout of commands, capped by Timeout, and commands are not
+	// retried once the context is done.
+	Context context.Context
+
+	// Txn is the multi-record transaction the command is part of, if any.
+	// Only single record commands can be part of a transaction.
+	Txn *Txn
 }
 
 // NewPolicy generates a new BasePolicy instance with default values.
@@ -60,13 +122,71 @@ func NewPolicy() *BasePolicy {
 	return &BasePolicy{
 		Priority:            DEFAULT,
 		ConsistencyLevel:    CONSISTENCY_ONE,
+		ReadModeAP:          AP_ONE,
+		ReadModeSC:          SC_SESSION,
 		Timeout:             0 * time.Millisecond,
 		MaxRetries:          2,
 		SleepBetweenRetries: 500 * time.Millisecond,
 	}
 }
 
+// NewInfinitePolicy returns a policy for commands which wait for the server as
+// long as it takes: neither the client nor the server time the command out.
+// Use it instead of a very long Timeout as a sentinel. Commands are still
+// retried MaxRetries times on network errors; the Context can cancel them.
+func NewInfinitePolicy() *BasePolicy {
+	policy := NewPolicy()
+	policy.Timeout = 0
+	return policy
+}
+
+// Clone returns a copy of the policy. The Context and Txn are shared.
+func (p *BasePolicy) Clone() *BasePolicy {
+	res := *p
+	return &res
+}
+
 var _ Policy = &BasePolicy{}
 
 // GetBasePolicy returns embedded BasePolicy in all types that embed this struct.
 func (p *BasePolicy) GetBasePolicy() *BasePolicy { return p }
+
+// timeout returns the timeout of a command. If the policy's Context has a
+// deadline, the time remaining until the deadline is used, capped by Timeout.
+func (p *BasePolicy) timeout() time.Duration {
+	if p.Context == nil {
+		return p.Timeout
+	}
+
+	deadline, ok := p.Context.Deadline()
+	if !ok {
+		return p.Timeout
+	}
+
+	// zero means no timeout, and the server only accepts milliseconds
+	remaining := deadline.Sub(time.Now())
+	if remaining < time.Millisecond {
+		remaining = time.Millisecond
+	}
+
+	if p.Timeout > 0 && p.Timeout < remaining {
+		return p.Timeout
+	}
+	return remaining
+}
+
+// serverTimeout returns the timeout sent to the server in milliseconds.
+// Zero means no timeout; timeouts too long for the wire protocol are capped.
+func serverTimeout(timeout time.Duration) int32 {
+	ms := timeout / time.Millisecond
+	switch {
+	case timeout <= 0:
+		return 0
+	case ms == 0:
+		// not to be taken for no timeout
+		return 1
+	case ms > math.MaxInt32:
+		return math.MaxInt32
+	}
+	return int32(ms)
+}
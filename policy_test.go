@@ -0,0 +1,144 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"context"
+	"math"
+	"time"
+
+	. "github.com/THE108/aerospike-client-go/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Policy Test", func() {
+
+	It("should derive the timeout from the context deadline", func() {
+		policy := NewPolicy()
+		Expect(policy.timeout()).To(Equal(time.Duration(0)))
+
+		policy.Context = WithBaggage(nil, Baggage{"tenant": "a"})
+		Expect(policy.timeout()).To(Equal(time.Duration(0)))
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		policy.Context = ctx
+		Expect(policy.timeout()).To(BeNumerically("~", time.Minute, time.Second))
+
+		// capped by the policy timeout
+		policy.Timeout = time.Second
+		Expect(policy.timeout()).To(Equal(time.Second))
+
+		expired, cancelExpired := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+		defer cancelExpired()
+		policy.Context = expired
+		Expect(policy.timeout()).To(Equal(time.Millisecond))
+	})
+
+	It("should send timeouts the server can represent", func() {
+		Expect(serverTimeout(0)).To(Equal(int32(0)))
+		Expect(serverTimeout(-time.Second)).To(Equal(int32(0)))
+		Expect(serverTimeout(time.Microsecond)).To(Equal(int32(1)))
+		Expect(serverTimeout(1500 * time.Millisecond)).To(Equal(int32(1500)))
+		Expect(serverTimeout(365 * 24 * time.Hour)).To(Equal(int32(math.MaxInt32)))
+
+		policy := NewInfinitePolicy()
+		Expect(policy.timeout()).To(Equal(time.Duration(0)))
+		Expect(timeoutChan(policy.Timeout)).To(BeNil())
+		Expect(timeoutChan(time.Millisecond)).ToNot(BeNil())
+	})
+
+	It("should not run commands once the context is done", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		policy := NewPolicy()
+		policy.Context = ctx
+
+		key, _ := NewKey("test", "test", 1)
+		cmd := newExistsCommand(&Cluster{partitionErrors: newPartitionErrorStats()}, policy, key)
+		err := cmd.Execute()
+		Expect(err).To(HaveOccurred())
+		Expect(err.(AerospikeError).ResultCode()).To(Equal(TIMEOUT))
+	})
+
+	It("should limit the parallelism of scans", func() {
+		policy := NewScanPolicy()
+		Expect(policy.maxConcurrentNodes(5)).To(Equal(5))
+		Expect(policy.socketsPerNode()).To(Equal(1))
+
+		policy.MaxConcurrentNodes = 2
+		Expect(policy.maxConcurrentNodes(5)).To(Equal(2))
+		Expect(policy.maxConcurrentNodes(1)).To(Equal(1))
+
+		policy.ConcurrentNodes = false
+		Expect(policy.maxConcurrentNodes(5)).To(Equal(1))
+
+		policy.SocketsPerNode = 3
+		slice := policy.slice(1, 3)
+		Expect(slice.PredExp).To(Equal([]PredExp{
+			NewPredExpRecDigestModulo(3),
+			NewPredExpIntegerValue(1),
+			NewPredExpIntegerEqual(),
+		}))
+		Expect(policy.PredExp).To(BeNil())
+
+		// slices are combined with the predicates of the policy
+		policy.PredExp = NewPredExpLastUpdateRange(time.Now(), time.Time{})
+		slice = policy.slice(2, 3)
+		Expect(len(slice.PredExp)).To(Equal(7))
+		Expect(slice.PredExp[6]).To(Equal(NewPredExpAnd(2)))
+		Expect(len(policy.PredExp)).To(Equal(3))
+	})
+
+	It("should deep copy policies with Clone", func() {
+		policy := NewScanPolicy()
+		clone := policy.Clone()
+		Expect(clone).To(Equal(policy))
+
+		clone.Timeout = time.Second
+		clone.RecordQueueSize = 1
+		clone.ScanPercent = 1
+		Expect(policy.Timeout).To(Equal(time.Duration(0)))
+		Expect(policy.RecordQueueSize).To(Equal(5000))
+		Expect(policy.ScanPercent).To(Equal(100))
+
+		backup := NewBackupPolicy()
+		backupClone := backup.Clone()
+		backupClone.MaxRetries = 5
+		Expect(backup.MaxRetries).To(Equal(0))
+
+		write := NewWritePolicy(0, 0)
+		writeClone := write.Clone()
+		writeClone.Timeout = time.Second
+		Expect(write.Timeout).To(Equal(time.Duration(0)))
+	})
+
+	It("should copy policies at call time", func() {
+		client := &Client{DefaultQueryPolicy: NewQueryPolicy()}
+
+		policy := NewQueryPolicy()
+		used := client.getUsableQueryPolicy(policy)
+		policy.Timeout = time.Second
+		Expect(used.Timeout).To(Equal(time.Duration(0)))
+
+		used = client.getUsableQueryPolicy(nil)
+		client.DefaultQueryPolicy.Timeout = time.Second
+		Expect(used.Timeout).To(Equal(time.Duration(0)))
+	})
+
+})
@@ -0,0 +1,311 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	Buffer "github.com/THE108/aerospike-client-go/utils/buffer"
+)
+
+const (
+	_AS_PREDEXP_AND uint16 = 1
+	_AS_PREDEXP_OR  uint16 = 2
+	_AS_PREDEXP_NOT uint16 = 3
+
+	_AS_PREDEXP_INTEGER_VALUE uint16 = 10
+	_AS_PREDEXP_STRING_VALUE  uint16 = 11
+
+	_AS_PREDEXP_INTEGER_BIN uint16 = 100
+	_AS_PREDEXP_STRING_BIN  uint16 = 101
+
+	_AS_PREDEXP_REC_DEVICE_SIZE   uint16 = 150
+	_AS_PREDEXP_REC_LAST_UPDATE   uint16 = 151
+	_AS_PREDEXP_REC_VOID_TIME     uint16 = 152
+	_AS_PREDEXP_REC_DIGEST_MODULO uint16 = 153
+
+	_AS_PREDEXP_INTEGER_EQUAL     uint16 = 200
+	_AS_PREDEXP_INTEGER_UNEQUAL   uint16 = 201
+	_AS_PREDEXP_INTEGER_GREATER   uint16 = 202
+	_AS_PREDEXP_INTEGER_GREATEREQ uint16 = 203
+	_AS_PREDEXP_INTEGER_LESS      uint16 = 204
+	_AS_PREDEXP_INTEGER_LESSEQ    uint16 = 205
+
+	_AS_PREDEXP_STRING_EQUAL   uint16 = 210
+	_AS_PREDEXP_STRING_UNEQUAL uint16 = 211
+	_AS_PREDEXP_STRING_REGEX   uint16 = 212
+)
+
+// tag(2) + length(4)
+const _PREDEXP_HEADER_SIZE = 6
+
+// PredExp represents a predicate expression, evaluated on the server for each
+// record selected by the statement's index Filter. Only matching records are
+// returned.
+//
+// Predicate expressions are written in postfix notation: values first, then
+// the operator which consumes them. For example, to select records with
+// status = 'active' AND score > 100:
+//
+//	stmt.SetPredExp(
+//	  NewPredExpStringBin("status"),
+//	  NewPredExpStringValue("active"),
+//	  NewPredExpStringEqual(),
+//	  NewPredExpIntegerBin("score"),
+//	  NewPredExpIntegerValue(100),
+//	  NewPredExpIntegerGreater(),
+//	  NewPredExpAnd(2),
+//	)
+type PredExp interface {
+	String() string
+	estimateSize() int
+	write(buf []byte, offset int) int
+}
+
+func writePredExpHeader(buf []byte, offset int, tag uint16, length int) int {
+	Buffer.Int16ToBytes(int16(tag), buf, offset)
+	Buffer.Int32ToBytes(int32(length), buf, offset+2)
+	return offset + _PREDEXP_HEADER_SIZE
+}
+
+// predExpOp is a comparison operator without arguments.
+type predExpOp struct {
+	tag  uint16
+	name string
+}
+
+func (e *predExpOp) String() string    { return e.name }
+func (e *predExpOp) estimateSize() int { return _PREDEXP_HEADER_SIZE }
+func (e *predExpOp) write(buf []byte, offset int) int {
+	return writePredExpHeader(buf, offset, e.tag, 0)
+}
+
+// predExpLogical is a logical operator that consumes a number of expressions.
+type predExpLogical struct {
+	tag   uint16
+	name  string
+	nexpr uint16
+}
+
+func (e *predExpLogical) String() string    { return e.name + "(" + strconv.Itoa(int(e.nexpr)) + ")" }
+func (e *predExpLogical) estimateSize() int { return _PREDEXP_HEADER_SIZE + 2 }
+func (e *predExpLogical) write(buf []byte, offset int) int {
+	offset = writePredExpHeader(buf, offset, e.tag, 2)
+	Buffer.Int16ToBytes(int16(e.nexpr), buf, offset)
+	return offset + 2
+}
+
+// NewPredExpAnd creates an AND predicate of the previous nexpr expressions.
+func NewPredExpAnd(nexpr uint16) PredExp {
+	return &predExpLogical{tag: _AS_PREDEXP_AND, name: "AND", nexpr: nexpr}
+}
+
+// NewPredExpOr creates an OR predicate of the previous nexpr expressions.
+func NewPredExpOr(nexpr uint16) PredExp {
+	return &predExpLogical{tag: _AS_PREDEXP_OR, name: "OR", nexpr: nexpr}
+}
+
+// NewPredExpNot creates a NOT predicate of the previous expression.
+func NewPredExpNot() PredExp {
+	return &predExpOp{tag: _AS_PREDEXP_NOT, name: "NOT"}
+}
+
+// predExpBin references the value of a bin.
+type predExpBin struct {
+	tag  uint16
+	name string
+}
+
+func (e *predExpBin) String() string    { return e.name }
+func (e *predExpBin) estimateSize() int { return _PREDEXP_HEADER_SIZE + len(e.name) }
+func (e *predExpBin) write(buf []byte, offset int) int {
+	offset = writePredExpHeader(buf, offset, e.tag, len(e.name))
+	return offset + copy(buf[offset:], e.name)
+}
+
+// NewPredExpIntegerBin creates a predicate referencing an integer bin.
+func NewPredExpIntegerBin(name string) PredExp {
+	return &predExpBin{tag: _AS_PREDEXP_INTEGER_BIN, name: name}
+}
+
+// NewPredExpStringBin creates a predicate referencing a string bin.
+func NewPredExpStringBin(name string) PredExp {
+	return &predExpBin{tag: _AS_PREDEXP_STRING_BIN, name: name}
+}
+
+// predExpIntegerValue is an integer constant.
+type predExpIntegerValue int64
+
+func (e predExpIntegerValue) String() string    { return strconv.FormatInt(int64(e), 10) }
+func (e predExpIntegerValue) estimateSize() int { return _PREDEXP_HEADER_SIZE + 8 }
+func (e predExpIntegerValue) write(buf []byte, offset int) int {
+	offset = writePredExpHeader(buf, offset, _AS_PREDEXP_INTEGER_VALUE, 8)
+	Buffer.Int64ToBytes(int64(e), buf, offset)
+	return offset + 8
+}
+
+// NewPredExpIntegerValue creates an integer constant predicate.
+func NewPredExpIntegerValue(val int64) PredExp {
+	return predExpIntegerValue(val)
+}
+
+// predExpStringValue is a string constant.
+type predExpStringValue string
+
+func (e predExpStringValue) String() string    { return strconv.Quote(string(e)) }
+func (e predExpStringValue) estimateSize() int { return _PREDEXP_HEADER_SIZE + len(e) }
+func (e predExpStringValue) write(buf []byte, offset int) int {
+	offset = writePredExpHeader(buf, offset, _AS_PREDEXP_STRING_VALUE, len(e))
+	return offset + copy(buf[offset:], string(e))
+}
+
+// NewPredExpStringValue creates a string constant predicate.
+func NewPredExpStringValue(val string) PredExp {
+	return predExpStringValue(val)
+}
+
+// NewPredExpIntegerEqual creates an equality predicate for integer values.
+func NewPredExpIntegerEqual() PredExp {
+	return &predExpOp{tag: _AS_PREDEXP_INTEGER_EQUAL, name: "="}
+}
+
+// NewPredExpIntegerUnequal creates an inequality predicate for integer values.
+func NewPredExpIntegerUnequal() PredExp {
+	return &predExpOp{tag: _AS_PREDEXP_INTEGER_UNEQUAL, name: "!="}
+}
+
+// NewPredExpIntegerGreater creates a greater-than predicate for integer values.
+func NewPredExpIntegerGreater() PredExp {
+	return &predExpOp{tag: _AS_PREDEXP_INTEGER_GREATER, name: ">"}
+}
+
+// NewPredExpIntegerGreaterEq creates a greater-than-or-equal predicate for integer values.
+func NewPredExpIntegerGreaterEq() PredExp {
+	return &predExpOp{tag: _AS_PREDEXP_INTEGER_GREATEREQ, name: ">="}
+}
+
+// NewPredExpIntegerLess creates a less-than predicate for integer values.
+func NewPredExpIntegerLess() PredExp {
+	return &predExpOp{tag: _AS_PREDEXP_INTEGER_LESS, name: "<"}
+}
+
+// NewPredExpIntegerLessEq creates a less-than-or-equal predicate for integer values.
+func NewPredExpIntegerLessEq() PredExp {
+	return &predExpOp{tag: _AS_PREDEXP_INTEGER_LESSEQ, name: "<="}
+}
+
+// NewPredExpStringEqual creates an equality predicate for string values.
+func NewPredExpStringEqual() PredExp {
+	return &predExpOp{tag: _AS_PREDEXP_STRING_EQUAL, name: "="}
+}
+
+// NewPredExpStringUnequal creates an inequality predicate for string values.
+func NewPredExpStringUnequal() PredExp {
+	return &predExpOp{tag: _AS_PREDEXP_STRING_UNEQUAL, name: "!="}
+}
+
+// predExpStringRegex matches a string against a regular expression.
+type predExpStringRegex struct {
+	flags uint32
+}
+
+func (e *predExpStringRegex) String() string    { return fmt.Sprintf("regex(%d)", e.flags) }
+func (e *predExpStringRegex) estimateSize() int { return _PREDEXP_HEADER_SIZE + 4 }
+func (e *predExpStringRegex) write(buf []byte, offset int) int {
+	offset = writePredExpHeader(buf, offset, _AS_PREDEXP_STRING_REGEX, 4)
+	Buffer.Int32ToBytes(int32(e.flags), buf, offset)
+	return offset + 4
+}
+
+// NewPredExpStringRegex creates a regular expression predicate for string values.
+// The flags are POSIX regcomp flags, eg: REG_ICASE.
+func NewPredExpStringRegex(flags uint32) PredExp {
+	return &predExpStringRegex{flags: flags}
+}
+
+// NewPredExpRecLastUpdate creates a predicate referencing the last update
+// time of the record, an integer in nanoseconds since the Unix epoch.
+func NewPredExpRecLastUpdate() PredExp {
+	return &predExpOp{tag: _AS_PREDEXP_REC_LAST_UPDATE, name: "rec.LastUpdate"}
+}
+
+// NewPredExpRecVoidTime creates a predicate referencing the expiration time
+// of the record, an integer in nanoseconds since the Unix epoch.
+// The void time of records which never expire is 0.
+func NewPredExpRecVoidTime() PredExp {
+	return &predExpOp{tag: _AS_PREDEXP_REC_VOID_TIME, name: "rec.VoidTime"}
+}
+
+// NewPredExpRecDeviceSize creates a predicate referencing the storage size
+// of the record in bytes. It is 0 for namespaces stored in memory only.
+func NewPredExpRecDeviceSize() PredExp {
+	return &predExpOp{tag: _AS_PREDEXP_REC_DEVICE_SIZE, name: "rec.DeviceSize"}
+}
+
+// predExpDigestModulo references the digest of the record modulo a number.
+type predExpDigestModulo struct {
+	mod int32
+}
+
+func (e *predExpDigestModulo) String() string    { return fmt.Sprintf("rec.DigestModulo(%d)", e.mod) }
+func (e *predExpDigestModulo) estimateSize() int { return _PREDEXP_HEADER_SIZE + 4 }
+func (e *predExpDigestModulo) write(buf []byte, offset int) int {
+	offset = writePredExpHeader(buf, offset, _AS_PREDEXP_REC_DIGEST_MODULO, 4)
+	Buffer.Int32ToBytes(e.mod, buf, offset)
+	return offset + 4
+}
+
+// NewPredExpRecDigestModulo creates a predicate referencing the digest of the
+// record modulo mod, an integer in the range [0, mod). It splits the records
+// into mod slices of about the same size, eg: to scan them in parallel.
+func NewPredExpRecDigestModulo(mod int32) PredExp {
+	return &predExpDigestModulo{mod: mod}
+}
+
+// NewPredExpLastUpdateRange returns the predicate selecting the records last
+// updated at or after from, and before to. A zero time leaves that end of the
+// range open; nil is returned if both are zero.
+// Use it for incremental scans and queries of the records modified since the
+// previous one:
+//
+//	policy.PredExp = NewPredExpLastUpdateRange(lastScan, time.Time{})
+func NewPredExpLastUpdateRange(from, to time.Time) []PredExp {
+	return predExpTimeRange(NewPredExpRecLastUpdate, from, to)
+}
+
+// NewPredExpVoidTimeRange returns the predicate selecting the records
+// expiring at or after from, and before to. A zero time leaves that end of the
+// range open; nil is returned if both are zero.
+// Records which never expire have a void time of 0, and are only selected if
+// from is zero.
+func NewPredExpVoidTimeRange(from, to time.Time) []PredExp {
+	return predExpTimeRange(NewPredExpRecVoidTime, from, to)
+}
+
+func predExpTimeRange(metadata func() PredExp, from, to time.Time) []PredExp {
+	var res []PredExp
+	if !from.IsZero() {
+		res = append(res, metadata(), NewPredExpIntegerValue(from.UnixNano()), NewPredExpIntegerGreaterEq())
+	}
+	if !to.IsZero() {
+		res = append(res, metadata(), NewPredExpIntegerValue(to.UnixNano()), NewPredExpIntegerLess())
+	}
+	if !from.IsZero() && !to.IsZero() {
+		res = append(res, NewPredExpAnd(2))
+	}
+	return res
+}
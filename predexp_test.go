@@ -0,0 +1,100 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PredExp Test", func() {
+
+	marshal := func(predexps ...PredExp) []byte {
+		size := 0
+		for _, e := range predexps {
+			size += e.estimateSize()
+		}
+
+		buf := make([]byte, size)
+		offset := 0
+		for _, e := range predexps {
+			offset = e.write(buf, offset)
+		}
+		Expect(offset).To(Equal(size))
+		return buf
+	}
+
+	It("should marshal values, bins and operators", func() {
+		Expect(marshal(NewPredExpIntegerBin("a"))).To(Equal([]byte{0, 100, 0, 0, 0, 1, 'a'}))
+		Expect(marshal(NewPredExpIntegerValue(258))).To(Equal([]byte{0, 10, 0, 0, 0, 8, 0, 0, 0, 0, 0, 0, 1, 2}))
+		Expect(marshal(NewPredExpStringValue("ok"))).To(Equal([]byte{0, 11, 0, 0, 0, 2, 'o', 'k'}))
+		Expect(marshal(NewPredExpIntegerGreater())).To(Equal([]byte{0, 202, 0, 0, 0, 0}))
+		Expect(marshal(NewPredExpAnd(2))).To(Equal([]byte{0, 1, 0, 0, 0, 2, 0, 2}))
+		Expect(marshal(NewPredExpStringRegex(2))).To(Equal([]byte{0, 212, 0, 0, 0, 4, 0, 0, 0, 2}))
+	})
+
+	It("should marshal a compound expression", func() {
+		buf := marshal(
+			NewPredExpStringBin("status"),
+			NewPredExpStringValue("active"),
+			NewPredExpStringEqual(),
+			NewPredExpIntegerBin("score"),
+			NewPredExpIntegerValue(100),
+			NewPredExpIntegerGreater(),
+			NewPredExpAnd(2),
+		)
+		Expect(len(buf)).To(Equal(12 + 12 + 6 + 11 + 14 + 6 + 8))
+	})
+
+	It("should marshal record metadata ranges", func() {
+		Expect(marshal(NewPredExpRecLastUpdate())).To(Equal([]byte{0, 151, 0, 0, 0, 0}))
+		Expect(marshal(NewPredExpRecVoidTime())).To(Equal([]byte{0, 152, 0, 0, 0, 0}))
+
+		from := time.Unix(0, 258)
+		Expect(NewPredExpLastUpdateRange(time.Time{}, time.Time{})).To(BeNil())
+		Expect(marshal(NewPredExpLastUpdateRange(from, time.Time{})...)).To(Equal([]byte{
+			0, 151, 0, 0, 0, 0,
+			0, 10, 0, 0, 0, 8, 0, 0, 0, 0, 0, 0, 1, 2,
+			0, 203, 0, 0, 0, 0,
+		}))
+
+		predexps := NewPredExpVoidTimeRange(from, from.Add(time.Hour))
+		Expect(len(predexps)).To(Equal(7))
+		Expect(predexps[5]).To(Equal(NewPredExpIntegerLess()))
+		Expect(predexps[6]).To(Equal(NewPredExpAnd(2)))
+	})
+
+	It("should marshal digest modulo predicates", func() {
+		Expect(marshal(NewPredExpRecDigestModulo(3))).To(Equal([]byte{0, 153, 0, 0, 0, 4, 0, 0, 0, 3}))
+		Expect(NewPredExpRecDigestModulo(3).String()).To(Equal("rec.DigestModulo(3)"))
+	})
+
+	It("should send scan predicates", func() {
+		ns, set := "test", "test"
+		policy := NewScanPolicy()
+
+		cmd := &baseCommand{}
+		Expect(cmd.setScan(policy, &ns, &set, nil, 1)).ToNot(HaveOccurred())
+		size := cmd.dataOffset
+
+		policy.PredExp = NewPredExpLastUpdateRange(time.Now(), time.Time{})
+		Expect(cmd.setScan(policy, &ns, &set, nil, 1)).ToNot(HaveOccurred())
+		Expect(cmd.dataOffset - size).To(Equal(int(_FIELD_HEADER_SIZE) + 6 + 14 + 6))
+		Expect(int(cmd.dataBuffer[27])).To(Equal(5)) // field count
+	})
+
+})
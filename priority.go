@@ -14,6 +14,12 @@
 
 package aerospike
 
+import (
+	"strconv"
+
+	. "github.com/THE108/aerospike-client-go/types"
+)
+
 // Priority of operations on database server.
 type Priority int
 
@@ -31,3 +37,25 @@ const (
 	// HIGH determines that the server should run the operation at the highest priority.
 	HIGH
 )
+
+func (p Priority) String() string {
+	switch p {
+	case DEFAULT:
+		return "DEFAULT"
+	case LOW:
+		return "LOW"
+	case MEDIUM:
+		return "MEDIUM"
+	case HIGH:
+		return "HIGH"
+	}
+	return "Priority(" + strconv.Itoa(int(p)) + ")"
+}
+
+// scanPriority returns the priority bits of the scan options field.
+func (p Priority) scanPriority() (byte, error) {
+	if p < DEFAULT || p > HIGH {
+		return 0, NewAerospikeError(PARAMETER_ERROR, "Invalid priority: "+p.String())
+	}
+	return byte(p) << 4, nil
+}
@@ -0,0 +1,67 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	. "github.com/THE108/aerospike-client-go/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Priority Test", func() {
+
+	// the scan options follow the namespace and set fields
+	scanOptions := func(cmd *baseCommand) byte {
+		return cmd.dataBuffer[int(_MSG_TOTAL_HEADER_SIZE)+2*int(_FIELD_HEADER_SIZE)+8+int(_FIELD_HEADER_SIZE)]
+	}
+
+	It("should send the priority of scans", func() {
+		ns, set := "test", "test"
+		policy := NewScanPolicy()
+		policy.FailOnClusterChange = false
+
+		cmd := &baseCommand{}
+		Expect(cmd.setScan(policy, &ns, &set, nil, 1)).ToNot(HaveOccurred())
+		Expect(scanOptions(cmd)).To(Equal(byte(0)))
+
+		policy.Priority = LOW
+		Expect(cmd.setScan(policy, &ns, &set, nil, 1)).ToNot(HaveOccurred())
+		Expect(scanOptions(cmd)).To(Equal(byte(0x10)))
+
+		policy.Priority = HIGH
+		policy.FailOnClusterChange = true
+		Expect(cmd.setScan(policy, &ns, &set, nil, 1)).ToNot(HaveOccurred())
+		Expect(scanOptions(cmd)).To(Equal(byte(0x38)))
+	})
+
+	It("should reject invalid priorities", func() {
+		ns, set := "test", "test"
+		policy := NewScanPolicy()
+		policy.Priority = HIGH + 1
+
+		cmd := &baseCommand{}
+		err := cmd.setScan(policy, &ns, &set, nil, 1)
+		Expect(err).To(HaveOccurred())
+		Expect(err.(AerospikeError).ResultCode()).To(Equal(PARAMETER_ERROR))
+		Expect(policy.Priority.String()).To(Equal("Priority(4)"))
+
+		queryPolicy := NewQueryPolicy()
+		queryPolicy.Priority = -1
+		Expect(cmd.setQuery(queryPolicy, NewStatement("test", "test"), false)).To(HaveOccurred())
+		Expect(MEDIUM.String()).To(Equal("MEDIUM"))
+	})
+
+})
@@ -33,6 +33,10 @@ func (cmd *queryCommand) getPolicy(ifc command) Policy {
 	return cmd.policy
 }
 
+func (cmd *queryCommand) getStatement() *Statement {
+	return cmd.statement
+}
+
 func (cmd *queryCommand) writeBuffer(ifc command) (err error) {
 	return cmd.setQuery(cmd.policy, cmd.statement, false)
 }
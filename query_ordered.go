@@ -0,0 +1,270 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"bytes"
+
+	. "github.com/THE108/aerospike-client-go/types"
+)
+
+// QueryOrdered executes a query and returns a Recordset whose records are
+// ordered by the value of binName.
+// Each node streams its results through the secondary index, which returns them
+// in index order; the client merges the per-node streams as records arrive,
+// so results do not have to be fully materialized to be sorted.
+// The statement must filter on binName, so that nodes return records in its
+// index order. Since nodes stream records in ascending order, descending
+// queries buffer the records of every node before returning the first one.
+//
+// This method is only supported by Aerospike 3 servers.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *Client) QueryOrdered(policy *QueryPolicy, statement *Statement, binName string, descending bool) (*Recordset, error) {
+	if err := validateOrderedStatement(statement, binName); err != nil {
+		return nil, err
+	}
+
+	policy = clnt.getUsableQueryPolicyFor(policy, statement)
+
+	nodes := clnt.cluster.GetNodes()
+	if len(nodes) == 0 {
+		return nil, NewAerospikeError(SERVER_NOT_AVAILABLE, "Query failed because cluster is empty.")
+	}
+
+	if policy.WaitUntilMigrationsAreOver {
+		// wait until all migrations are finished
+		if err := clnt.cluster.WaitUntillMigrationIsFinished(policy.Timeout); err != nil {
+			return nil, err
+		}
+	}
+
+	// each node gets its own stream so they can be merged in order
+	sources := make([]*Recordset, len(nodes))
+	for i, node := range nodes {
+		sources[i] = newRecordset(policy.RecordQueueSize, 1)
+		sources[i].abortOnClose([]*Node{node}, queryAbortCommands(statement)...)
+
+		// copy policies to avoid race conditions
+		newPolicy := *policy
+		command := newQueryRecordCommand(node, &newPolicy, statement, sources[i])
+		go func(recSet *Recordset) {
+			err := command.Execute()
+			if err != nil {
+				recSet.sendError(err)
+			}
+		}(sources[i])
+	}
+
+	recSet := newRecordset(policy.RecordQueueSize, 1)
+	go mergeOrdered(recSet, sources, binName, descending)
+
+	return recSet, nil
+}
+
+// validateOrderedStatement checks that the records of statement are
+// returned in the index order of binName.
+func validateOrderedStatement(statement *Statement, binName string) error {
+	if len(statement.Filters) != 1 || statement.Filters[0].name != binName {
+		return NewAerospikeError(PARAMETER_ERROR, "Ordered queries require a single filter on bin "+binName)
+	}
+	return nil
+}
+
+// mergeOrdered merges the ascending record streams in sources into res.
+// Errors from all sources are forwarded to res.
+func mergeOrdered(res *Recordset, sources []*Recordset, binName string, descending bool) {
+	defer res.signalEnd()
+	defer func() {
+		for _, src := range sources {
+			src.Close()
+		}
+	}()
+
+	errs := make([]chan error, len(sources))
+	for i := range sources {
+		errs[i] = sources[i].Errors
+	}
+
+	// next returns the next record of source i, or nil if it is exhausted.
+	// It returns false if res has been cancelled.
+	next := func(i int) (*Record, bool) {
+		for {
+			select {
+			case rec, ok := <-sources[i].Records:
+				if !ok {
+					// errors may still be buffered on the closed channel
+					if errs[i] != nil {
+						for err := range errs[i] {
+							res.sendError(err)
+						}
+					}
+					return nil, true
+				}
+				return rec, true
+			case err, ok := <-errs[i]:
+				if !ok {
+					errs[i] = nil
+					continue
+				}
+				res.sendError(err)
+			case <-res.cancelled:
+				return nil, false
+			}
+		}
+	}
+
+	if descending {
+		// reverse the ascending streams once they are complete
+		buffered := make([][]*Record, len(sources))
+		for i := range sources {
+			for {
+				rec, ok := next(i)
+				if !ok {
+					return
+				}
+				if rec == nil {
+					break
+				}
+				buffered[i] = append(buffered[i], rec)
+			}
+		}
+
+		next = func(i int) (*Record, bool) {
+			n := len(buffered[i])
+			if n == 0 {
+				return nil, true
+			}
+			rec := buffered[i][n-1]
+			buffered[i] = buffered[i][:n-1]
+			return rec, true
+		}
+	}
+
+	var ok bool
+	heads := make([]*Record, len(sources))
+	for i := range sources {
+		if heads[i], ok = next(i); !ok {
+			return
+		}
+	}
+
+	for {
+		best := -1
+		for i, rec := range heads {
+			if rec == nil {
+				continue
+			}
+
+			if best < 0 {
+				best = i
+				continue
+			}
+
+			c := compareBinValues(rec.Bins[binName], heads[best].Bins[binName])
+			if descending {
+				c = -c
+			}
+
+			if c < 0 {
+				best = i
+			}
+		}
+
+		// all sources are exhausted
+		if best < 0 {
+			return
+		}
+
+		select {
+		case res.Records <- heads[best]:
+		case <-res.cancelled:
+			return
+		}
+
+		if heads[best], ok = next(best); !ok {
+			return
+		}
+	}
+}
+
+// compareBinValues compares two bin values returned by the server.
+// Values of different types are ordered by type: nil, integers, floats, strings, blobs, others.
+func compareBinValues(a, b interface{}) int {
+	ra, rb := binValueRank(a), binValueRank(b)
+	if ra != rb {
+		if ra < rb {
+			return -1
+		}
+		return 1
+	}
+
+	switch va := a.(type) {
+	case int:
+		return compareInt64(int64(va), toInt64(b))
+	case int64:
+		return compareInt64(va, toInt64(b))
+	case float64:
+		vb := b.(float64)
+		if va < vb {
+			return -1
+		} else if va > vb {
+			return 1
+		}
+		return 0
+	case string:
+		vb := b.(string)
+		if va < vb {
+			return -1
+		} else if va > vb {
+			return 1
+		}
+		return 0
+	case []byte:
+		return bytes.Compare(va, b.([]byte))
+	}
+	return 0
+}
+
+func binValueRank(v interface{}) int {
+	switch v.(type) {
+	case nil:
+		return 0
+	case int, int64:
+		return 1
+	case float64:
+		return 2
+	case string:
+		return 3
+	case []byte:
+		return 4
+	}
+	return 5
+}
+
+func toInt64(v interface{}) int64 {
+	if i, ok := v.(int); ok {
+		return int64(i)
+	}
+	return v.(int64)
+}
+
+func compareInt64(a, b int64) int {
+	if a < b {
+		return -1
+	} else if a > b {
+		return 1
+	}
+	return 0
+}
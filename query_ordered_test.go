@@ -0,0 +1,96 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Ordered Query Test", func() {
+
+	source := func(err error, values ...interface{}) *Recordset {
+		rs := newRecordset(len(values)+1, 1)
+		for _, v := range values {
+			rs.Records <- newRecord(nil, nil, BinMap{"bin": v}, 1, 0)
+		}
+		if err != nil {
+			rs.sendError(err)
+		}
+		rs.signalEnd()
+		return rs
+	}
+
+	collect := func(rs *Recordset) ([]interface{}, []error) {
+		values := []interface{}{}
+		errs := []error{}
+		for res := range rs.Results() {
+			if res.Err != nil {
+				errs = append(errs, res.Err)
+			} else {
+				values = append(values, res.Record.Bins["bin"])
+			}
+		}
+		return values, errs
+	}
+
+	It("should merge ordered streams", func() {
+		res := newRecordset(10, 1)
+		go mergeOrdered(res, []*Recordset{
+			source(nil, 1, 4, 7),
+			source(nil),
+			source(nil, 2, 3, 9),
+		}, "bin", false)
+
+		values, errs := collect(res)
+		Expect(errs).To(BeEmpty())
+		Expect(values).To(Equal([]interface{}{1, 2, 3, 4, 7, 9}))
+	})
+
+	It("should merge streams in descending order and forward errors", func() {
+		res := newRecordset(10, 1)
+		go mergeOrdered(res, []*Recordset{
+			source(nil, "a", "c"),
+			source(errors.New("node failed"), "b", "d"),
+		}, "bin", true)
+
+		values, errs := collect(res)
+		Expect(values).To(Equal([]interface{}{"d", "c", "b", "a"}))
+		Expect(len(errs)).To(Equal(1))
+	})
+
+	It("should require a filter on the ordering bin", func() {
+		stmt := NewStatement("test", "test")
+		Expect(validateOrderedStatement(stmt, "bin")).To(HaveOccurred())
+
+		stmt.Addfilter(NewRangeFilter("other", 0, 10))
+		Expect(validateOrderedStatement(stmt, "bin")).To(HaveOccurred())
+
+		stmt = NewStatement("test", "test")
+		stmt.Addfilter(NewRangeFilter("bin", 0, 10))
+		Expect(validateOrderedStatement(stmt, "bin")).ToNot(HaveOccurred())
+	})
+
+	It("should compare bin values", func() {
+		Expect(compareBinValues(nil, 1)).To(Equal(-1))
+		Expect(compareBinValues(2, int64(1))).To(Equal(1))
+		Expect(compareBinValues("a", "a")).To(Equal(0))
+		Expect(compareBinValues([]byte{1}, []byte{2})).To(Equal(-1))
+		Expect(compareBinValues("a", 1)).To(Equal(1))
+	})
+
+})
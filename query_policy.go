@@ -17,6 +17,12 @@ package aerospike
 // QueryPolicy encapsulates parameters for policy attributes used in query operations.
 type QueryPolicy struct {
 	*MultiPolicy
+
+	// FailOnClusterChange verifies the cluster key of the nodes before and
+	// after the query. If the cluster changed, the recordset ends with a
+	// CLUSTER_KEY_MISMATCH error, since records may have been returned twice
+	// or missed.
+	FailOnClusterChange bool //= false
 }
 
 // NewQueryPolicy generates a new QueryPolicy instance with default values.
@@ -30,3 +36,12 @@ func NewQueryPolicy() *QueryPolicy {
 
 	return res
 }
+
+// Clone returns a deep copy of the policy, including its MultiPolicy.
+func (p *QueryPolicy) Clone() *QueryPolicy {
+	res := *p
+	if p.MultiPolicy != nil {
+		res.MultiPolicy = p.MultiPolicy.Clone()
+	}
+	return &res
+}
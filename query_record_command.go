@@ -111,13 +111,9 @@ func (cmd *queryRecordCommand) parseRecordResults(ifc command, receiveSize int)
 			bins[name] = value
 		}
 
-		// If the channel is full and it blocks, we don't want this command to
-		// block forever, or panic in case the channel is closed in the meantime.
-		select {
 		// send back the result on the async channel
-		case cmd.recordset.Records <- newRecord(cmd.node, key, bins, generation, expiration):
-		case <-cmd.recordset.cancelled:
-			return false, NewAerospikeError(SCAN_TERMINATED)
+		if err := cmd.sendRecord(newRecord(cmd.node, key, bins, generation, expiration)); err != nil {
+			return false, err
 		}
 	}
 
@@ -149,6 +149,51 @@ var _ = Describe("Query operations", func() {
 		Expect(cnt).To(BeNumerically(">", 0))
 	})
 
+	It("must Query a specific range with additional predicates and get only relevant records back", func() {
+		stm := NewStatement(ns, set)
+		stm.Addfilter(NewRangeFilter(bin3.Name, 0, math.MaxInt16/2))
+		stm.SetPredExp(
+			NewPredExpStringBin(bin4.Name),
+			NewPredExpStringValue("constValue"),
+			NewPredExpStringEqual(),
+			NewPredExpIntegerBin(bin3.Name),
+			NewPredExpIntegerValue(math.MaxInt16/4),
+			NewPredExpIntegerGreater(),
+			NewPredExpAnd(2),
+		)
+
+		recordset, err := client.Query(nil, stm)
+		Expect(err).ToNot(HaveOccurred())
+
+		cnt := 0
+		for res := range recordset.Results() {
+			Expect(res.Err).ToNot(HaveOccurred())
+			rec := res.Record
+			cnt++
+			Expect(rec.Bins[bin3.Name]).To(BeNumerically(">", math.MaxInt16/4))
+			Expect(rec.Bins[bin3.Name]).To(BeNumerically("<=", math.MaxInt16/2))
+		}
+
+		Expect(cnt).To(BeNumerically(">", 0))
+	})
+
+	It("must reset the TTL of records in a range with a background job", func() {
+		stm := NewStatement(ns, set)
+		stm.Addfilter(NewRangeFilter(bin3.Name, 0, math.MaxInt16/2))
+
+		task, err := client.UpdateTTL(nil, stm, 1000)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(<-task.OnComplete()).ToNot(HaveOccurred())
+
+		for _, key := range keys {
+			rec, err := client.Get(nil, key)
+			Expect(err).ToNot(HaveOccurred())
+			if rec.Bins[bin3.Name].(int) <= math.MaxInt16/2 {
+				Expect(rec.Expiration).To(BeNumerically("<=", 1000))
+			}
+		}
+	})
+
 	It("must Query a specific range by applying a udf filter and get only relevant records back", func() {
 		regTask, err := client.RegisterUDF(nil, []byte(udfFilter), "udfFilter.lua", LUA)
 		Expect(err).ToNot(HaveOccurred())
@@ -204,4 +249,30 @@ var _ = Describe("Query operations", func() {
 		Expect(recs).To(ContainElement(bin3.Value.GetObject()))
 	})
 
+	It("must return the records changed since a change sequence number", func() {
+		tracker, err := client.NewChangeTracker(ns, set, "changeseq")
+		Expect(err).ToNot(HaveOccurred())
+
+		key1, _ := NewKey(ns, set, randString(50))
+		key2, _ := NewKey(ns, set, randString(50))
+
+		seq1, err := tracker.Put(nil, key1, BinMap{"value": 1})
+		Expect(err).ToNot(HaveOccurred())
+		seq2, err := tracker.Put(nil, key2, BinMap{"value": 2})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(seq2).To(BeNumerically(">", seq1))
+
+		recordset, err := tracker.GetChangesSince(nil, seq1, "value")
+		Expect(err).ToNot(HaveOccurred())
+
+		var recs []*Record
+		for res := range recordset.Results() {
+			Expect(res.Err).ToNot(HaveOccurred())
+			recs = append(recs, res.Record)
+		}
+
+		Expect(len(recs)).To(Equal(1))
+		Expect(recs[0].Bins).To(Equal(BinMap{"value": 2, "changeseq": int(seq2)}))
+	})
+
 })
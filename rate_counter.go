@@ -0,0 +1,153 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"time"
+
+	. "github.com/THE108/aerospike-client-go/types"
+)
+
+// RateCounter is a sliding window event counter stored in a map bin.
+// The window is split into a number of buckets; each bucket is a map item
+// whose key is the bucket's start time and whose value is the number of
+// events recorded in it.
+//
+// Adding events, trimming expired buckets and counting the events in the
+// window is done on the server in a single Operate command, so concurrent
+// clients never need a read-modify-write cycle.
+// It is typically used to rate limit requests per user:
+//
+//	rc, _ := client.GetRateCounter(nil, userKey, "api_calls", time.Minute, 6)
+//	if allowed, _, err := rc.Allow(100); err == nil && !allowed {
+//	  // reject the request
+//	}
+type RateCounter struct {
+	client  *Client
+	policy  *WritePolicy
+	key     *Key
+	binName string
+
+	window      time.Duration
+	bucketWidth time.Duration
+	buckets     int64
+
+	// now is replaceable for tests
+	now func() time.Time
+}
+
+// NewRateCounter initializes a rate counter over a window of the given
+// duration, split into the given number of buckets.
+// More buckets make the window slide more smoothly at the cost of a larger map.
+// Each bucket must span at least one nanosecond.
+func NewRateCounter(client *Client, policy *WritePolicy, key *Key, binName string, window time.Duration, buckets int) (*RateCounter, error) {
+	if buckets < 1 {
+		buckets = 1
+	}
+
+	if window < time.Duration(buckets) {
+		return nil, NewAerospikeError(PARAMETER_ERROR, "Rate counter window must be at least one nanosecond per bucket")
+	}
+
+	return &RateCounter{
+		client:      client,
+		policy:      policy,
+		key:         key,
+		binName:     binName,
+		window:      window,
+		bucketWidth: window / time.Duration(buckets),
+		buckets:     int64(buckets),
+		now:         time.Now,
+	}, nil
+}
+
+// bucketRange returns the key of the current bucket and the key of the
+// oldest bucket still in the window.
+func (rc *RateCounter) bucketRange() (current, first int64) {
+	current = rc.now().UnixNano() / int64(rc.bucketWidth)
+	return current, current - rc.buckets + 1
+}
+
+// Add records n events in the current bucket, removes expired buckets,
+// and returns the number of events in the window, including the new ones.
+func (rc *RateCounter) Add(n int) (int, error) {
+	current, first := rc.bucketRange()
+
+	rec, err := rc.client.Operate(rc.policy, rc.key,
+		MapIncrementOp(NewMapPolicy(MAP_KEY_ORDERED), rc.binName, current, n),
+		MapRemoveByKeyRangeOp(rc.binName, nil, first, MAP_RETURN_NONE),
+		MapGetByKeyRangeOp(rc.binName, first, nil, MAP_RETURN_VALUE),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return sumCounts(rec.Bins[rc.binName])
+}
+
+// Count returns the number of events in the window without recording any.
+func (rc *RateCounter) Count() (int, error) {
+	_, first := rc.bucketRange()
+
+	rec, err := rc.client.Operate(rc.policy, rc.key,
+		MapGetByKeyRangeOp(rc.binName, first, nil, MAP_RETURN_VALUE),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	// record does not exist
+	if rec == nil {
+		return 0, nil
+	}
+
+	return sumCounts(rec.Bins[rc.binName])
+}
+
+// Allow records one event and reports whether the number of events in the
+// window is within limit. Rejected events are counted as well, so clients
+// that keep retrying stay throttled.
+func (rc *RateCounter) Allow(limit int) (bool, int, error) {
+	count, err := rc.Add(1)
+	if err != nil {
+		return false, 0, err
+	}
+	return count <= limit, count, nil
+}
+
+// sumCounts adds up the bucket values returned by the server.
+func sumCounts(v interface{}) (int, error) {
+	if v == nil {
+		return 0, nil
+	}
+
+	list, ok := v.([]interface{})
+	if !ok {
+		return 0, NewAerospikeError(PARSE_ERROR, "Unexpected rate counter result type")
+	}
+
+	sum := 0
+	for _, c := range list {
+		switch c := c.(type) {
+		case int:
+			sum += c
+		case int64:
+			sum += int(c)
+		default:
+			return 0, NewAerospikeError(PARSE_ERROR, "Unexpected rate counter bucket type")
+		}
+	}
+	return sum, nil
+}
@@ -0,0 +1,49 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RateCounter Test", func() {
+
+	It("should compute the window bucket range", func() {
+		rc, err := NewRateCounter(nil, nil, nil, "calls", time.Minute, 6)
+		Expect(err).ToNot(HaveOccurred())
+		rc.now = func() time.Time { return time.Unix(125, 0) }
+
+		current, first := rc.bucketRange()
+		Expect(current).To(Equal(int64(12)))
+		Expect(first).To(Equal(int64(7)))
+	})
+
+	It("should reject windows shorter than the number of buckets", func() {
+		_, err := NewRateCounter(nil, nil, nil, "calls", 5*time.Nanosecond, 6)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should sum bucket counts", func() {
+		Expect(sumCounts(nil)).To(Equal(0))
+		Expect(sumCounts([]interface{}{1, int64(2), 3})).To(Equal(6))
+
+		_, err := sumCounts([]interface{}{"x"})
+		Expect(err).To(HaveOccurred())
+	})
+
+})
@@ -0,0 +1,87 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike_test
+
+import (
+	"time"
+
+	. "github.com/THE108/aerospike-client-go"
+	"github.com/THE108/aerospike-client-go/aerotest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Rate counter", func() {
+
+	var srv *aerotest.Server
+	var client *Client
+	var key *Key
+
+	BeforeEach(func() {
+		var err error
+		srv, err = aerotest.NewServer("test")
+		Expect(err).ToNot(HaveOccurred())
+		srv.SetFeatures("cdt-list", "cdt-map", "pipelining", "replicas-master", "udf")
+
+		client, err = NewClient(srv.Host(), srv.Port())
+		Expect(err).ToNot(HaveOccurred())
+
+		key, _ = NewKey("test", "aerotest", "user")
+	})
+
+	AfterEach(func() {
+		client.Close()
+		Expect(srv.Close()).ToNot(HaveOccurred())
+	})
+
+	It("should count the events in the window", func() {
+		rc, err := client.GetRateCounter(nil, key, "calls", time.Hour, 6)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(rc.Count()).To(Equal(0))
+		Expect(rc.Add(2)).To(Equal(2))
+		Expect(rc.Add(3)).To(Equal(5))
+		Expect(rc.Count()).To(Equal(5))
+	})
+
+	It("should limit the events in the window", func() {
+		rc, err := client.GetRateCounter(nil, key, "calls", time.Hour, 6)
+		Expect(err).ToNot(HaveOccurred())
+
+		for i := 1; i <= 3; i++ {
+			allowed, count, err := rc.Allow(3)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(allowed).To(BeTrue())
+			Expect(count).To(Equal(i))
+		}
+
+		allowed, count, err := rc.Allow(3)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(allowed).To(BeFalse())
+		Expect(count).To(Equal(4))
+	})
+
+	It("should drop the events which left the window", func() {
+		rc, err := client.GetRateCounter(nil, key, "calls", 100*time.Millisecond, 2)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(rc.Add(5)).To(Equal(5))
+		time.Sleep(250 * time.Millisecond)
+		Expect(rc.Count()).To(Equal(0))
+		Expect(rc.Add(1)).To(Equal(1))
+	})
+
+})
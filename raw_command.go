@@ -0,0 +1,266 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"fmt"
+
+	. "github.com/THE108/aerospike-client-go/logger"
+	. "github.com/THE108/aerospike-client-go/types"
+	Buffer "github.com/THE108/aerospike-client-go/utils/buffer"
+)
+
+// RawCommand is a single record command built by the caller, for server
+// features the client does not support yet. The namespace, set and digest
+// fields of the key are written by the client, followed by Fields and
+// Operations in order; the header is written from the attributes and the
+// write policy passed to ExecuteRaw.
+// Nothing is validated by the client; malformed commands may be rejected by
+// the server with a PARAMETER_ERROR, or worse.
+type RawCommand struct {
+	// Key routes the command to the master node of its partition.
+	Key *Key
+
+	// ReadAttr, WriteAttr and InfoAttr are the attributes of the message
+	// header, which are ORed with the attributes derived from the policy.
+	// Commands with a WriteAttr are sent like writes; otherwise like reads.
+	ReadAttr  byte
+	WriteAttr byte
+	InfoAttr  byte
+
+	// Fields are sent after the fields of the key.
+	Fields []*RawField
+
+	// Operations are sent as is; any OperationType can be used. A nil BinValue
+	// is sent as a null value.
+	Operations []*Operation
+}
+
+// RawField is a field of a raw command or response.
+type RawField struct {
+	Type FieldType
+	Data []byte
+}
+
+// RawBin is a bin of a raw response.
+type RawBin struct {
+	Name         string
+	ParticleType int
+
+	// Data is the particle as returned by the server.
+	Data []byte
+
+	// Value is the decoded particle, or nil if the particle type is unknown to the client.
+	Value interface{}
+}
+
+// RawResponse is the response to a RawCommand.
+type RawResponse struct {
+	// ResultCode is the result code returned by the server. Result codes other
+	// than OK are returned here rather than as an error.
+	ResultCode ResultCode
+
+	Generation uint32
+	Expiration uint32
+
+	// Fields are the fields of the response, in order.
+	Fields []*RawField
+
+	// Bins are the bins of the response in order, including multiple
+	// results for the same bin.
+	Bins []*RawBin
+}
+
+// ExecuteRaw sends the raw command to the node holding the master of the
+// key's partition, retrying it according to the policy, and returns the
+// parsed response. It is an escape hatch to use new server features before
+// the client supports them; prefer the typed API otherwise.
+// Raw commands can not be part of a multi-record transaction.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *Client) ExecuteRaw(policy *WritePolicy, raw *RawCommand) (*RawResponse, error) {
+	if raw == nil || raw.Key == nil {
+		return nil, NewAerospikeError(PARAMETER_ERROR, "Raw command must have a key")
+	}
+
+	policy = clnt.getUsableWritePolicyFor(policy, raw.Key.namespace, raw.Key.setName)
+	if policy.Txn != nil {
+		return nil, NewAerospikeError(PARAMETER_ERROR, "Raw commands can not be part of a transaction")
+	}
+
+	if len(raw.Operations) > MaxOperations {
+		return nil, NewAerospikeError(PARAMETER_ERROR, fmt.Sprintf("Too many operations in command: %d. Maximum allowed is %d.", len(raw.Operations), MaxOperations))
+	}
+
+	command := newRawCommand(clnt.cluster, policy, raw)
+	if err := command.Execute(); err != nil {
+		return nil, err
+	}
+	return command.response, nil
+}
+
+type rawCommand struct {
+	*singleCommand
+
+	policy   *WritePolicy
+	raw      *RawCommand
+	response *RawResponse
+}
+
+func newRawCommand(cluster *Cluster, policy *WritePolicy, raw *RawCommand) *rawCommand {
+	return &rawCommand{
+		singleCommand: newSingleCommand(cluster, raw.Key),
+		policy:        policy,
+		raw:           raw,
+	}
+}
+
+func (cmd *rawCommand) getPolicy(ifc command) Policy {
+	return cmd.policy
+}
+
+func (cmd *rawCommand) isWrite() bool {
+	return cmd.raw.WriteAttr != 0
+}
+
+func (cmd *rawCommand) writeBuffer(ifc command) error {
+	raw := cmd.raw
+	write := cmd.isWrite()
+
+	operations := make([]*Operation, len(raw.Operations))
+	for i, op := range raw.Operations {
+		operations[i] = op
+		if op.BinValue == nil {
+			operations[i] = &Operation{OpType: op.OpType, BinName: op.BinName, BinValue: NewNullValue()}
+		}
+	}
+
+	cmd.begin()
+	fieldCount := cmd.estimateKeySize(raw.Key, cmd.policy.SendKey && write)
+	for _, field := range raw.Fields {
+		cmd.dataOffset += len(field.Data) + int(_FIELD_HEADER_SIZE)
+		fieldCount++
+	}
+	for _, op := range operations {
+		cmd.estimateOperationSizeForOperation(op)
+	}
+
+	if err := cmd.sizeBuffer(); err != nil {
+		return err
+	}
+
+	if write {
+		cmd.writeHeaderWithPolicy(cmd.policy, int(raw.ReadAttr), int(raw.WriteAttr), fieldCount, len(operations))
+	} else {
+		cmd.writeHeader(&cmd.policy.BasePolicy, int(raw.ReadAttr), 0, fieldCount, len(operations))
+	}
+	cmd.dataBuffer[11] |= raw.InfoAttr
+
+	cmd.writeKey(raw.Key, cmd.policy.SendKey && write)
+	for _, field := range raw.Fields {
+		cmd.writeFieldBytes(field.Data, field.Type)
+	}
+
+	for _, op := range operations {
+		if err := cmd.writeOperationForOperation(op); err != nil {
+			return err
+		}
+	}
+
+	cmd.end()
+	return nil
+}
+
+func (cmd *rawCommand) parseResult(ifc command, conn *Connection) error {
+	// Read header.
+	if _, err := conn.Read(cmd.dataBuffer, int(_MSG_TOTAL_HEADER_SIZE)); err != nil {
+		Logger.Warn("parse result error: %s", err)
+		return err
+	}
+
+	sz := Buffer.BytesToInt64(cmd.dataBuffer, 0)
+	headerLength := int(cmd.dataBuffer[8])
+	res := &RawResponse{
+		ResultCode: ResultCode(cmd.dataBuffer[13] & 0xFF),
+		Generation: Buffer.BytesToUint32(cmd.dataBuffer, 14),
+		Expiration: Buffer.BytesToUint32(cmd.dataBuffer, 18),
+	}
+	fieldCount := int(Buffer.BytesToUint16(cmd.dataBuffer, 26))
+	opCount := int(Buffer.BytesToUint16(cmd.dataBuffer, 28))
+	receiveSize := int((sz & 0xFFFFFFFFFFFF) - int64(headerLength))
+
+	// Read remaining message bytes.
+	if receiveSize > 0 {
+		if err := cmd.sizeBufferSz(receiveSize); err != nil {
+			return err
+		}
+		if _, err := conn.Read(cmd.dataBuffer, receiveSize); err != nil {
+			Logger.Warn("parse result error: %s", err)
+			return err
+		}
+	}
+
+	// the data buffer is reused, so the response gets copies of its data
+	offset := 0
+	for i := 0; i < fieldCount; i++ {
+		if offset+int(_FIELD_HEADER_SIZE) > receiveSize {
+			return NewAerospikeError(PARSE_ERROR, "Raw response is truncated")
+		}
+		size := int(Buffer.BytesToUint32(cmd.dataBuffer, offset)) - 1
+		if size < 0 || offset+int(_FIELD_HEADER_SIZE)+size > receiveSize {
+			return NewAerospikeError(PARSE_ERROR, "Invalid field size in raw response")
+		}
+
+		res.Fields = append(res.Fields, &RawField{
+			Type: FieldType(cmd.dataBuffer[offset+4]),
+			Data: append([]byte(nil), cmd.dataBuffer[offset+int(_FIELD_HEADER_SIZE):offset+int(_FIELD_HEADER_SIZE)+size]...),
+		})
+		offset += int(_FIELD_HEADER_SIZE) + size
+	}
+
+	for i := 0; i < opCount; i++ {
+		if offset+int(_OPERATION_HEADER_SIZE) > receiveSize {
+			return NewAerospikeError(PARSE_ERROR, "Raw response is truncated")
+		}
+		opSize := int(Buffer.BytesToUint32(cmd.dataBuffer, offset))
+		particleType := int(cmd.dataBuffer[offset+5])
+		nameSize := int(cmd.dataBuffer[offset+7])
+		particleSize := opSize - (4 + nameSize)
+		start := offset + int(_OPERATION_HEADER_SIZE) + nameSize
+		if particleSize < 0 || start+particleSize > receiveSize {
+			return NewAerospikeError(PARSE_ERROR, "Invalid operation size in raw response")
+		}
+
+		data := append([]byte(nil), cmd.dataBuffer[start:start+particleSize]...)
+		value, err := bytesToParticle(particleType, data, 0, len(data))
+		if err != nil {
+			return err
+		}
+
+		res.Bins = append(res.Bins, &RawBin{
+			Name:         string(cmd.dataBuffer[offset+int(_OPERATION_HEADER_SIZE) : start]),
+			ParticleType: particleType,
+			Data:         data,
+			Value:        value,
+		})
+		offset = start + particleSize
+	}
+
+	cmd.response = res
+	return nil
+}
+
+func (cmd *rawCommand) Execute() error {
+	return cmd.execute(cmd)
+}
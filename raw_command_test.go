@@ -0,0 +1,92 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike_test
+
+import (
+	. "github.com/THE108/aerospike-client-go"
+	"github.com/THE108/aerospike-client-go/aerotest"
+	. "github.com/THE108/aerospike-client-go/types"
+	ParticleType "github.com/THE108/aerospike-client-go/types/particle_type"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Raw commands", func() {
+
+	var srv *aerotest.Server
+	var client *Client
+	var key *Key
+
+	BeforeEach(func() {
+		var err error
+		client, srv, err = aerotest.NewClientAndServer("test")
+		Expect(err).ToNot(HaveOccurred())
+
+		key, err = NewKey("test", "set", 1)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		client.Close()
+		srv.Close()
+	})
+
+	It("must send raw writes and reads", func() {
+		res, err := client.ExecuteRaw(nil, &RawCommand{
+			Key:       key,
+			WriteAttr: 1, // INFO2_WRITE
+			Operations: []*Operation{
+				{OpType: WRITE, BinName: "a", BinValue: NewIntegerValue(7)},
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res.ResultCode).To(Equal(OK))
+		Expect(res.Generation).To(Equal(uint32(1)))
+
+		res, err = client.ExecuteRaw(nil, &RawCommand{
+			Key:        key,
+			ReadAttr:   1, // INFO1_READ
+			Operations: []*Operation{{OpType: READ, BinName: "a"}},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(len(res.Bins)).To(Equal(1))
+		Expect(res.Bins[0].Name).To(Equal("a"))
+		Expect(res.Bins[0].ParticleType).To(Equal(ParticleType.INTEGER))
+		Expect(res.Bins[0].Value).To(Equal(7))
+
+		rec, err := client.Get(nil, key)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rec.Bins).To(Equal(BinMap{"a": 7}))
+	})
+
+	It("must return server result codes in the response", func() {
+		res, err := client.ExecuteRaw(nil, &RawCommand{
+			Key:        key,
+			ReadAttr:   1, // INFO1_READ
+			Operations: []*Operation{{OpType: READ, BinName: "a"}},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res.ResultCode).To(Equal(KEY_NOT_FOUND_ERROR))
+		Expect(res.Bins).To(BeNil())
+	})
+
+	It("must reject commands without keys", func() {
+		_, err := client.ExecuteRaw(nil, &RawCommand{})
+		Expect(err).To(HaveOccurred())
+		Expect(err.(AerospikeError).ResultCode()).To(Equal(PARAMETER_ERROR))
+	})
+
+})
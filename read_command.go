@@ -35,6 +35,9 @@ type readCommand struct {
 
 	// pointer to the object that's going to be unmarshalled
 	object interface{}
+
+	// keep the results of all operations in order, see Record.OpResults
+	orderedResults bool
 }
 
 func newReadCommand(cluster *Cluster, policy Policy, key *Key, binNames []string) *readCommand {
@@ -82,7 +85,7 @@ func (cmd *readCommand) parseResult(ifc command, conn *Connection) error {
 			Logger.Warn("parse result error: " + err.Error())
 			return err
 		}
-
+		cmd.parseTxnFields(fieldCount)
 	}
 
 	if resultCode != 0 {
@@ -132,6 +135,7 @@ func (cmd *readCommand) parseRecord(
 	expiration int,
 ) (*Record, error) {
 	var bins BinMap
+	var opResults []*OpResult
 	receiveOffset := 0
 
 	// There can be fields in the response (setname etc).
@@ -161,9 +165,15 @@ func (cmd *readCommand) parseRecord(
 			bins = make(BinMap, opCount)
 		}
 		bins[name] = value
+
+		if cmd.orderedResults {
+			opResults = append(opResults, &OpResult{BinName: name, Value: value})
+		}
 	}
 
-	return newRecord(cmd.node, cmd.key, bins, generation, expiration), nil
+	rec := newRecord(cmd.node, cmd.key, bins, generation, expiration)
+	rec.OpResults = opResults
+	return rec, nil
 }
 
 func (cmd *readCommand) parseObject(
@@ -203,7 +213,7 @@ func (cmd *readCommand) parseObject(
 
 		particleBytesSize := int(opSize - (4 + nameSize))
 		value, _ := bytesToParticle(particleType, cmd.dataBuffer, receiveOffset, particleBytesSize)
-		if err := cmd.setObjectField(rv, name, value); err != nil {
+		if err := setObjectField(rv, name, value); err != nil {
 			return err
 		}
 
@@ -221,7 +231,7 @@ func (cmd *readCommand) Execute() error {
 	return cmd.execute(cmd)
 }
 
-func (cmd *readCommand) setObjectField(obj reflect.Value, fieldName string, value interface{}) error {
+func setObjectField(obj reflect.Value, fieldName string, value interface{}) error {
 	if value == nil {
 		return nil
 	}
@@ -232,14 +242,25 @@ func (cmd *readCommand) setObjectField(obj reflect.Value, fieldName string, valu
 		fieldName = name
 	}
 	f := iobj.FieldByName(fieldName)
-	setValue(f, value)
-
-	return nil
+	return setValue(f, value)
 }
 
 func setValue(f reflect.Value, value interface{}) error {
 	// find the name based on tag mapping
 	if f.CanSet() {
+		// user registered types are decoded by their codec
+		if isCustomValueType(f.Type()) {
+			if value == nil {
+				return nil
+			}
+			rv := reflect.ValueOf(value)
+			if rv.Type() != f.Type() {
+				return NewAerospikeError(BIN_TYPE_ERROR, "Value of type "+rv.Type().String()+" can not be set to a field of type "+f.Type().String())
+			}
+			f.Set(rv)
+			return nil
+		}
+
 		switch f.Kind() {
 		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 			f.SetInt(int64(value.(int)))
@@ -397,7 +418,9 @@ func setValue(f reflect.Value, value interface{}) error {
 							}
 
 							if valMap[alias] != nil {
-								setValue(reflect.Indirect(newObjPtr).FieldByName(alias), valMap[alias])
+								if err := setValue(reflect.Indirect(newObjPtr).FieldByName(alias), valMap[alias]); err != nil {
+									return err
+								}
 							}
 						}
 
@@ -420,7 +443,9 @@ func setValue(f reflect.Value, value interface{}) error {
 			}
 
 			for i := 0; i < theArray.Len(); i++ {
-				setValue(f.Index(i), theArray.Index(i).Interface())
+				if err := setValue(f.Index(i), theArray.Index(i).Interface()); err != nil {
+					return err
+				}
 			}
 		case reflect.Map:
 			theMap := value.(map[interface{}]interface{})
@@ -469,7 +494,9 @@ func setValue(f reflect.Value, value interface{}) error {
 				}
 
 				if valMap[alias] != nil {
-					setValue(f.FieldByName(typeOfT.Field(i).Name), valMap[alias])
+					if err := setValue(f.FieldByName(typeOfT.Field(i).Name), valMap[alias]); err != nil {
+						return err
+					}
 				}
 			}
 
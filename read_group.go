@@ -0,0 +1,119 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	. "github.com/THE108/aerospike-client-go/types"
+)
+
+// readCall is a read in flight, shared by the Gets waiting for it.
+type readCall struct {
+	done chan struct{}
+	rec  *Record
+	err  error
+}
+
+// readGroup deduplicates concurrent identical reads: only the first Get of
+// a record executes the command, the others wait for its result.
+// Calls are kept by record, then by bin names.
+type readGroup struct {
+	mutex sync.Mutex
+	calls map[string]map[string]*readCall
+}
+
+func newReadGroup() *readGroup {
+	return &readGroup{calls: map[string]map[string]*readCall{}}
+}
+
+// do executes read, unless an identical read is already in flight; in that
+// case it waits for the result of that read, or until ctx is done.
+// Waiting callers get a copy of the record, so they can modify it.
+func (g *readGroup) do(ctx context.Context, key *Key, binNames []string, read func() (*Record, error)) (*Record, error) {
+	rk, bk := recordCacheKey(key), strings.Join(binNames, "\x00")
+
+	g.mutex.Lock()
+	calls := g.calls[rk]
+	if call, exists := calls[bk]; exists {
+		g.mutex.Unlock()
+		return call.wait(ctx)
+	}
+	if calls == nil {
+		calls = map[string]*readCall{}
+		g.calls[rk] = calls
+	}
+	call := &readCall{done: make(chan struct{})}
+	calls[bk] = call
+	g.mutex.Unlock()
+
+	defer func() {
+		g.mutex.Lock()
+		// the calls of the record may have been forgotten meanwhile
+		if calls := g.calls[rk]; calls[bk] == call {
+			delete(calls, bk)
+			if len(calls) == 0 {
+				delete(g.calls, rk)
+			}
+		}
+		g.mutex.Unlock()
+		close(call.done)
+	}()
+
+	// a panicking read must not leave the waiters without an error
+	defer func() {
+		if r := recover(); r != nil {
+			call.rec, call.err = nil, NewAerospikeError(PARSE_ERROR, fmt.Sprintf("Shared read failed: %v", r))
+			panic(r)
+		}
+	}()
+
+	rec, err := read()
+	if err == nil && rec != nil {
+		// waiters copy a record of their own, which the caller can not modify
+		call.rec = copyCachedRecord(rec)
+	}
+	call.err = err
+	return rec, err
+}
+
+func (call *readCall) wait(ctx context.Context) (*Record, error) {
+	var ctxDone <-chan struct{}
+	if ctx != nil {
+		ctxDone = ctx.Done()
+	}
+
+	select {
+	case <-call.done:
+	case <-ctxDone:
+		return nil, NewAerospikeError(TIMEOUT, "command context is done: "+ctx.Err().Error())
+	}
+
+	if call.err != nil || call.rec == nil {
+		return call.rec, call.err
+	}
+	return copyCachedRecord(call.rec), nil
+}
+
+// forget makes reads of the key started from now on execute a new command
+// instead of waiting for one in flight, which may miss a completed write.
+func (g *readGroup) forget(key *Key) {
+	g.mutex.Lock()
+	delete(g.calls, recordCacheKey(key))
+	g.mutex.Unlock()
+}
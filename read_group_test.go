@@ -0,0 +1,178 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	. "github.com/THE108/aerospike-client-go/types"
+	. "github.com/THE108/aerospike-client-go/types/atomic"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Read Deduplication Test", func() {
+
+	var group *readGroup
+	var key *Key
+	var calls *AtomicInt
+	var release chan struct{}
+
+	BeforeEach(func() {
+		group = newReadGroup()
+		key, _ = NewKey("test", "test", 1)
+		calls = NewAtomicInt(0)
+		release = make(chan struct{})
+	})
+
+	read := func() (*Record, error) {
+		calls.IncrementAndGet()
+		<-release
+		return newRecord(nil, key, BinMap{"a": 1}, 1, 0), nil
+	}
+
+	// started starts a read in the background
+	started := func(ctx context.Context, binNames []string, res chan *Record) {
+		go func() {
+			defer GinkgoRecover()
+			rec, err := group.do(ctx, key, binNames, read)
+			Expect(err).ToNot(HaveOccurred())
+			res <- rec
+		}()
+	}
+
+	waitForCalls := func(n int) {
+		for i := 0; i < 100 && calls.Get() < n; i++ {
+			time.Sleep(10 * time.Millisecond)
+		}
+		Expect(calls.Get()).To(Equal(n))
+	}
+
+	inFlight := func() int {
+		group.mutex.Lock()
+		defer group.mutex.Unlock()
+		return len(group.calls[recordCacheKey(key)])
+	}
+
+	It("should share the result of concurrent identical reads", func() {
+		res := make(chan *Record, 10)
+		started(nil, nil, res)
+		waitForCalls(1)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 9; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				rec, err := group.do(nil, key, nil, read)
+				Expect(err).ToNot(HaveOccurred())
+				res <- rec
+			}()
+		}
+
+		// let the other reads join the one in flight
+		time.Sleep(100 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		var recs []*Record
+		for i := 0; i < 10; i++ {
+			rec := <-res
+			Expect(rec.Bins).To(Equal(BinMap{"a": 1}))
+			recs = append(recs, rec)
+		}
+		Expect(calls.Get()).To(Equal(1))
+		Expect(inFlight()).To(Equal(0))
+
+		// every caller can modify its record
+		recs[0].Bins["a"] = 2
+		Expect(recs[1].Bins["a"]).To(Equal(1))
+	})
+
+	It("should not share reads of different bins", func() {
+		res := make(chan *Record, 2)
+		started(nil, nil, res)
+		started(nil, []string{"a"}, res)
+		waitForCalls(2)
+
+		close(release)
+		<-res
+		<-res
+	})
+
+	It("should start a new read after the record was written", func() {
+		res := make(chan *Record, 2)
+		started(nil, nil, res)
+		waitForCalls(1)
+
+		group.forget(key)
+		Expect(inFlight()).To(Equal(0))
+		started(nil, nil, res)
+		waitForCalls(2)
+
+		close(release)
+		<-res
+		<-res
+		Expect(inFlight()).To(Equal(0))
+	})
+
+	It("should fail the waiting reads when the read panics", func() {
+		panicked := make(chan interface{}, 1)
+		go func() {
+			defer func() { panicked <- recover() }()
+			group.do(nil, key, nil, func() (*Record, error) {
+				calls.IncrementAndGet()
+				<-release
+				panic("broken read")
+			})
+		}()
+		waitForCalls(1)
+
+		errs := make(chan error, 1)
+		go func() {
+			_, err := group.do(nil, key, nil, read)
+			errs <- err
+		}()
+
+		// let the other read join the one in flight
+		time.Sleep(100 * time.Millisecond)
+		close(release)
+
+		Expect(<-panicked).To(Equal("broken read"))
+		err := <-errs
+		Expect(err).To(HaveOccurred())
+		Expect(err.(AerospikeError).ResultCode()).To(Equal(PARSE_ERROR))
+		Expect(inFlight()).To(Equal(0))
+	})
+
+	It("should stop waiting when the context is done", func() {
+		res := make(chan *Record, 1)
+		started(nil, nil, res)
+		waitForCalls(1)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := group.do(ctx, key, nil, read)
+		Expect(err).To(HaveOccurred())
+		Expect(err.(AerospikeError).ResultCode()).To(Equal(TIMEOUT))
+
+		close(release)
+		<-res
+	})
+
+})
@@ -0,0 +1,39 @@
+/*
+ * Copyright 2012-2014 Aerospike, Inc.
+ *
+ * Portions may be licensed to Aerospike, Inc. under one or more contributor
+ * license agreements.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not
+ * use this file except in compliance with the License. You may obtain a copy of
+ * the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations under
+ * the License.
+ */
+
+package aerospike
+
+// ReadModeAP determines how many replicas are consulted in reads of
+// namespaces which are not in strong consistency mode (AP namespaces).
+// It is ignored for namespaces in strong consistency mode.
+type ReadModeAP int
+
+const (
+	// AP_ONE reads the record from a single replica. Reads may return a stale
+	// version of the record while partitions migrate, e.g. after a node restart.
+	AP_ONE ReadModeAP = iota
+
+	// AP_ALL makes the server resolve duplicates of the record across all
+	// replicas during migrations, and return the latest version.
+	AP_ALL
+)
+
+// readAllReplicas returns true if reads should consult all replicas.
+// ReadModeAP and the older ConsistencyLevel are equivalent.
+func (p *BasePolicy) readAllReplicas() bool {
+	return p.ReadModeAP == AP_ALL || p.ConsistencyLevel == CONSISTENCY_ALL
+}
@@ -0,0 +1,46 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Read Mode AP Test", func() {
+
+	It("should request all replicas with AP_ALL or CONSISTENCY_ALL", func() {
+		cmd := &baseCommand{dataBuffer: make([]byte, 64)}
+
+		policy := NewPolicy()
+		cmd.writeHeader(policy, _INFO1_READ, 0, 0, 0)
+		Expect(int(cmd.dataBuffer[9])).To(Equal(_INFO1_READ))
+
+		policy.ReadModeAP = AP_ALL
+		cmd.writeHeader(policy, _INFO1_READ, 0, 0, 0)
+		Expect(int(cmd.dataBuffer[9])).To(Equal(_INFO1_READ | _INFO1_CONSISTENCY_ALL))
+
+		policy = NewPolicy()
+		policy.ConsistencyLevel = CONSISTENCY_ALL
+		cmd.writeHeader(policy, _INFO1_READ, 0, 0, 0)
+		Expect(int(cmd.dataBuffer[9])).To(Equal(_INFO1_READ | _INFO1_CONSISTENCY_ALL))
+
+		writePolicy := NewWritePolicy(0, 0)
+		writePolicy.ReadModeAP = AP_ALL
+		cmd.writeHeaderWithPolicy(writePolicy, _INFO1_READ, _INFO2_WRITE, 0, 0)
+		Expect(int(cmd.dataBuffer[9])).To(Equal(_INFO1_READ | _INFO1_CONSISTENCY_ALL))
+	})
+
+})
@@ -0,0 +1,56 @@
+/*
+ * Copyright 2012-2014 Aerospike, Inc.
+ *
+ * Portions may be licensed to Aerospike, Inc. under one or more contributor
+ * license agreements.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not
+ * use this file except in compliance with the License. You may obtain a copy of
+ * the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations under
+ * the License.
+ */
+
+package aerospike
+
+// ReadModeSC determines the read consistency guarantee for namespaces
+// configured in strong consistency (SC) mode. It is ignored for namespaces
+// which are not in SC mode.
+type ReadModeSC int
+
+const (
+	// SC_SESSION ensures this client will only see an increasing sequence of
+	// record versions. Reads go to the master node only.
+	SC_SESSION ReadModeSC = iota
+
+	// SC_LINEARIZE ensures all clients will only see an increasing sequence
+	// of record versions. Reads go to the master node only, and are slower
+	// since the master must verify it still owns the partition.
+	SC_LINEARIZE
+
+	// SC_ALLOW_REPLICA allows the server to read from the master or any
+	// full (non-migrating) replica. Record versions are not guaranteed
+	// to always increase.
+	SC_ALLOW_REPLICA
+
+	// SC_ALLOW_UNAVAILABLE allows the server to also read from unavailable
+	// partitions. Record versions are not guaranteed to always increase.
+	SC_ALLOW_UNAVAILABLE
+)
+
+// readModeSCAttr returns the info3 header bits for the read mode.
+func readModeSCAttr(mode ReadModeSC) int {
+	switch mode {
+	case SC_LINEARIZE:
+		return _INFO3_SC_READ_TYPE
+	case SC_ALLOW_REPLICA:
+		return _INFO3_SC_READ_RELAX
+	case SC_ALLOW_UNAVAILABLE:
+		return _INFO3_SC_READ_TYPE | _INFO3_SC_READ_RELAX
+	}
+	return 0
+}
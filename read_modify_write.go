@@ -0,0 +1,184 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	. "github.com/THE108/aerospike-client-go/types"
+)
+
+// RMWPolicy encapsulates parameters for read-modify-write (check-and-set) loops.
+type RMWPolicy struct {
+	WritePolicy
+
+	// MaxAttempts determines the maximum number of read-modify-write cycles
+	// before giving up on a conflicting record.
+	MaxAttempts int //= 10
+
+	// BaseJitter determines the initial upper bound of the random delay applied
+	// before retrying after a conflict. The bound doubles on each attempt and
+	// grows with the recent conflict rate of the set.
+	BaseJitter time.Duration //= 5ms
+
+	// MaxJitter caps the random delay between attempts.
+	MaxJitter time.Duration //= 500ms
+}
+
+// NewRMWPolicy generates a new RMWPolicy instance with default values.
+func NewRMWPolicy() *RMWPolicy {
+	return &RMWPolicy{
+		WritePolicy: *NewWritePolicy(0, 0),
+		MaxAttempts: 10,
+		BaseJitter:  5 * time.Millisecond,
+		MaxJitter:   500 * time.Millisecond,
+	}
+}
+
+// Clone returns a copy of the policy.
+func (p *RMWPolicy) Clone() *RMWPolicy {
+	res := *p
+	return &res
+}
+
+// ConflictStats holds read-modify-write statistics of a set.
+type ConflictStats struct {
+	// Attempts is the number of write attempts.
+	Attempts int64
+
+	// Conflicts is the number of attempts that failed because the
+	// record was modified concurrently.
+	Conflicts int64
+
+	// ConflictRate is a moving average of the recent conflict rate, between 0 and 1.
+	ConflictRate float64
+}
+
+// rmwStats keeps ConflictStats per namespace and set.
+type rmwStats struct {
+	mutex sync.Mutex
+	sets  map[string]*ConflictStats
+}
+
+func newRMWStats() *rmwStats {
+	return &rmwStats{sets: map[string]*ConflictStats{}}
+}
+
+// record registers an attempt, and returns the updated conflict rate of the set.
+func (s *rmwStats) record(set string, conflict bool) float64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	st := s.sets[set]
+	if st == nil {
+		st = &ConflictStats{}
+		s.sets[set] = st
+	}
+
+	st.Attempts++
+	sample := 0.0
+	if conflict {
+		st.Conflicts++
+		sample = 1
+	}
+	st.ConflictRate = 0.9*st.ConflictRate + 0.1*sample
+
+	return st.ConflictRate
+}
+
+func (s *rmwStats) snapshot() map[string]ConflictStats {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	res := make(map[string]ConflictStats, len(s.sets))
+	for k, v := range s.sets {
+		res[k] = *v
+	}
+	return res
+}
+
+// rmwJitter returns a random delay before the next attempt.
+func rmwJitter(policy *RMWPolicy, attempt int, conflictRate float64) time.Duration {
+	bound := policy.BaseJitter << uint(attempt-1)
+	bound = time.Duration(float64(bound) * (1 + 3*conflictRate))
+	if bound > policy.MaxJitter || bound <= 0 {
+		bound = policy.MaxJitter
+	}
+
+	if bound <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(bound)))
+}
+
+// ReadModifyWrite reads a record, passes it to modify, and writes back the
+// returned bins only if the record has not been changed in the meantime.
+// rec is nil if the record does not exist yet.
+// On conflict, the cycle is retried after a random delay, up to
+// policy.MaxAttempts times. The delay adapts to the conflict rate of the set.
+//
+// Conflicts and attempts are recorded per set; see RMWConflictStats.
+// If modify returns an error, the cycle is aborted and the error returned.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *Client) ReadModifyWrite(policy *RMWPolicy, key *Key, modify func(rec *Record) (BinMap, error)) error {
+	if policy == nil {
+		policy = NewRMWPolicy()
+	}
+
+	set := key.Namespace() + ":" + key.SetName()
+
+	for attempt := 1; ; attempt++ {
+		// rec will be nil if the record does not exist
+		rec, err := clnt.Get(&policy.BasePolicy, key)
+		if err != nil {
+			return err
+		}
+
+		bins, err := modify(rec)
+		if err != nil {
+			return err
+		}
+
+		// copy the policy to set the generation check
+		wp := policy.WritePolicy
+		if rec == nil {
+			wp.RecordExistsAction = CREATE_ONLY
+		} else {
+			wp.GenerationPolicy = EXPECT_GEN_EQUAL
+			wp.Generation = int32(rec.Generation)
+		}
+
+		err = clnt.Put(&wp, key, bins)
+		conflict := false
+		if ae, ok := err.(AerospikeError); ok {
+			conflict = ae.ResultCode() == GENERATION_ERROR || ae.ResultCode() == KEY_EXISTS_ERROR
+		}
+
+		rate := clnt.rmwStats.record(set, conflict)
+		if !conflict || attempt >= policy.MaxAttempts {
+			return err
+		}
+
+		time.Sleep(rmwJitter(policy, attempt, rate))
+	}
+}
+
+// RMWConflictStats returns the read-modify-write statistics of
+// ReadModifyWrite calls, keyed by "namespace:set".
+func (clnt *Client) RMWConflictStats() map[string]ConflictStats {
+	return clnt.rmwStats.snapshot()
+}
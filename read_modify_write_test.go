@@ -0,0 +1,51 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ReadModifyWrite Test", func() {
+
+	It("should record conflict statistics per set", func() {
+		stats := newRMWStats()
+		stats.record("test:a", false)
+		stats.record("test:a", true)
+		stats.record("test:b", false)
+
+		snapshot := stats.snapshot()
+		Expect(snapshot["test:a"].Attempts).To(Equal(int64(2)))
+		Expect(snapshot["test:a"].Conflicts).To(Equal(int64(1)))
+		Expect(snapshot["test:a"].ConflictRate).To(BeNumerically("~", 0.1, 0.0001))
+		Expect(snapshot["test:b"].Conflicts).To(Equal(int64(0)))
+	})
+
+	It("should bound the jitter", func() {
+		policy := NewRMWPolicy()
+		for attempt := 1; attempt < 20; attempt++ {
+			j := rmwJitter(policy, attempt, 1)
+			Expect(j).To(BeNumerically(">=", 0))
+			Expect(j).To(BeNumerically("<", policy.MaxJitter))
+		}
+
+		policy.MaxJitter = 0
+		Expect(rmwJitter(policy, 1, 0)).To(Equal(time.Duration(0)))
+	})
+
+})
@@ -16,10 +16,14 @@ package aerospike
 
 import (
 	"fmt"
+	"reflect"
+
+	. "github.com/THE108/aerospike-client-go/types"
 )
 
 // Record is the container struct for database records.
 // Records are equivalent to rows.
+// Records returned by the client belong to the caller and are not synchronized.
 type Record struct {
 	// Key is the record's key.
 	// Might be empty, or may only consist of digest value.
@@ -35,8 +39,27 @@ type Record struct {
 	Generation int
 
 	// Expiration is TTL (Time-To-Live).
-	// Number of seconds until record expires.
+	// Number of seconds until record expires, or 0 if it never expires.
 	Expiration int
+
+	// OpResults are the results of the operations in the order they were
+	// returned by the server, including multiple results for the same bin.
+	// Only set by OperateOrdered.
+	OpResults []*OpResult
+
+	// FilteredOut is set if the record did not match the filter expression
+	// of an OperateFiltered command, and none of the operations were applied.
+	// Only set if the OperatePolicy asked for it with ReturnFilteredOut.
+	FilteredOut bool
+}
+
+// OpResult is the result of an operation of an Operate command.
+type OpResult struct {
+	// BinName is the name of the bin the operation was applied to.
+	BinName string
+
+	// Value is the value returned by the operation.
+	Value interface{}
 }
 
 func newRecord(node *Node, key *Key, bins BinMap, generation int, expiration int) *Record {
@@ -61,3 +84,29 @@ func newRecord(node *Node, key *Key, bins BinMap, generation int, expiration int
 func (rc *Record) String() string {
 	return fmt.Sprintf("%v %v", *rc.Key, rc.Bins)
 }
+
+// ToObject sets the fields of obj, which must be a pointer to a struct,
+// from the bins of the record, the same way GetObject does.
+// Bins without a matching field are ignored.
+func (rc *Record) ToObject(obj interface{}) (err error) {
+	rv := reflect.ValueOf(obj)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return NewAerospikeError(PARAMETER_ERROR, "Object must be a non-nil pointer to a struct")
+	}
+
+	// values which do not fit the fields panic while being set
+	defer func() {
+		if r := recover(); r != nil {
+			err = NewAerospikeError(BIN_TYPE_ERROR, fmt.Sprintf("Record bins do not match the object: %v", r))
+		}
+	}()
+
+	rv = rv.Elem()
+	cacheObjectTags(rv)
+	for name, value := range rc.Bins {
+		if err := setObjectField(rv, name, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
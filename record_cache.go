@@ -0,0 +1,271 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// RecordCache stores the records cached by the client, see Client.SetRecordCache.
+// Records are stored by their namespace and digest. Implementations must be
+// safe for concurrent use.
+type RecordCache interface {
+	// Get returns the record stored for the key, if it has not expired.
+	Get(key string) (*Record, bool)
+
+	// Put stores the record for the key for the duration of the TTL.
+	Put(key string, record *Record, ttl time.Duration)
+
+	// Remove removes the record stored for the key, if any.
+	Remove(key string)
+}
+
+// CachePolicy encapsulates parameters of the client record cache.
+type CachePolicy struct {
+	// Store keeps the cached records. If nil, an LRU cache of 10000
+	// records is used.
+	Store RecordCache
+
+	// TTL is the time records of sets without an entry in SetTTLs are cached.
+	// Zero only caches the records of sets in SetTTLs.
+	TTL time.Duration //= 1 minute
+
+	// SetTTLs overrides the TTL per set, keyed by "namespace:set".
+	// A zero TTL disables the cache for the set.
+	SetTTLs map[string]time.Duration
+}
+
+// NewCachePolicy generates a new CachePolicy instance with default values.
+func NewCachePolicy() *CachePolicy {
+	return &CachePolicy{
+		TTL: time.Minute,
+	}
+}
+
+// recordCache caches the records read by Get, and drops the records written
+// by the client.
+type recordCache struct {
+	policy CachePolicy
+
+	// Records are only stored if no record was invalidated while they were
+	// read, so a read racing with a write never caches the old record.
+	mutex         sync.Mutex
+	invalidations uint64
+}
+
+func newRecordCache(policy *CachePolicy) *recordCache {
+	rc := &recordCache{policy: *policy}
+	if rc.policy.Store == nil {
+		rc.policy.Store = NewLRURecordCache(10000)
+	}
+
+	setTTLs := make(map[string]time.Duration, len(policy.SetTTLs))
+	for set, ttl := range policy.SetTTLs {
+		setTTLs[set] = ttl
+	}
+	rc.policy.SetTTLs = setTTLs
+	return rc
+}
+
+func recordCacheKey(key *Key) string {
+	return key.namespace + ":" + string(key.digest)
+}
+
+// ttl returns the time the records of the key's set are cached.
+func (rc *recordCache) ttl(key *Key) time.Duration {
+	if ttl, exists := rc.policy.SetTTLs[key.namespace+":"+key.setName]; exists {
+		return ttl
+	}
+	return rc.policy.TTL
+}
+
+// get returns a copy of the cached record of the key, and the number of
+// invalidations to pass on to put if the record is not cached.
+func (rc *recordCache) get(key *Key) (*Record, uint64) {
+	rc.mutex.Lock()
+	invalidations := rc.invalidations
+	rc.mutex.Unlock()
+
+	if rc.ttl(key) <= 0 {
+		return nil, invalidations
+	}
+
+	if rec, exists := rc.policy.Store.Get(recordCacheKey(key)); exists {
+		return copyCachedRecord(rec), invalidations
+	}
+	return nil, invalidations
+}
+
+// put caches a copy of the record, unless records were invalidated
+// since the read started.
+func (rc *recordCache) put(key *Key, rec *Record, invalidations uint64) {
+	ttl := rc.ttl(key)
+	if ttl <= 0 || rec == nil {
+		return
+	}
+
+	rc.mutex.Lock()
+	defer rc.mutex.Unlock()
+
+	if rc.invalidations == invalidations {
+		rc.policy.Store.Put(recordCacheKey(key), copyCachedRecord(rec), ttl)
+	}
+}
+
+func (rc *recordCache) invalidate(key *Key) {
+	rc.mutex.Lock()
+	defer rc.mutex.Unlock()
+
+	rc.invalidations++
+	rc.policy.Store.Remove(recordCacheKey(key))
+}
+
+// copyCachedRecord copies the record, its key and its bin map, so callers
+// can modify the records they get, and reuse their keys with Key.SetValue.
+// Bin values are not copied.
+func copyCachedRecord(rec *Record) *Record {
+	res := *rec
+	if rec.Key != nil {
+		res.Key = rec.Key.clone()
+	}
+	res.Bins = make(BinMap, len(rec.Bins))
+	for name, value := range rec.Bins {
+		res.Bins[name] = value
+	}
+	return &res
+}
+
+// SetRecordCache enables a read-through cache of the records read by Get
+// without bin names, or disables it if the policy is nil.
+// Records written, deleted or touched through the client are dropped from
+// the cache, even if the command failed. Writes by other clients, batch and
+// query UDFs are only seen once the cached records expire; drop them with
+// InvalidateCachedRecords if needed. Records read in a transaction are
+// neither cached nor served from the cache.
+// The Expiration of cached records is the one at the time they were read.
+func (clnt *Client) SetRecordCache(policy *CachePolicy) {
+	var rc *recordCache
+	if policy != nil {
+		rc = newRecordCache(policy)
+	}
+	clnt.cluster.setRecordCache(rc)
+}
+
+// InvalidateCachedRecords drops the records of the keys from the record cache.
+func (clnt *Client) InvalidateCachedRecords(keys ...*Key) {
+	if rc := clnt.cluster.getRecordCache(); rc != nil {
+		for _, key := range keys {
+			rc.invalidate(key)
+		}
+	}
+}
+
+func (clstr *Cluster) setRecordCache(rc *recordCache) {
+	clstr.recordCacheMutex.Lock()
+	clstr.recordCache = rc
+	clstr.recordCacheMutex.Unlock()
+}
+
+func (clstr *Cluster) getRecordCache() *recordCache {
+	clstr.recordCacheMutex.RLock()
+	rc := clstr.recordCache
+	clstr.recordCacheMutex.RUnlock()
+	return rc
+}
+
+// invalidateCachedRecord drops the record of the key from the record cache, if any.
+func (clstr *Cluster) invalidateCachedRecord(key *Key) {
+	if rc := clstr.getRecordCache(); rc != nil {
+		rc.invalidate(key)
+	}
+}
+
+type lruEntry struct {
+	key     string
+	record  *Record
+	expires time.Time
+}
+
+// lruRecordCache is a RecordCache evicting the least recently used records.
+type lruRecordCache struct {
+	mutex    sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List
+}
+
+// NewLRURecordCache returns an in-memory RecordCache holding up to capacity
+// records, evicting the least recently used ones.
+func NewLRURecordCache(capacity int) RecordCache {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &lruRecordCache{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element, capacity),
+		order:    list.New(),
+	}
+}
+
+func (c *lruRecordCache) Get(key string) (*Record, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	elem := c.entries[key]
+	if elem == nil {
+		return nil, false
+	}
+
+	entry := elem.Value.(*lruEntry)
+	if time.Now().After(entry.expires) {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+		return nil, false
+	}
+
+	c.order.MoveToFront(elem)
+	return entry.record, true
+}
+
+func (c *lruRecordCache) Put(key string, record *Record, ttl time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entry := &lruEntry{key: key, record: record, expires: time.Now().Add(ttl)}
+	if elem := c.entries[key]; elem != nil {
+		elem.Value = entry
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(entry)
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry).key)
+	}
+}
+
+func (c *lruRecordCache) Remove(key string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if elem := c.entries[key]; elem != nil {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Record Cache Test", func() {
+
+	It("should evict the least recently used records", func() {
+		cache := NewLRURecordCache(2)
+		cache.Put("a", &Record{Generation: 1}, time.Minute)
+		cache.Put("b", &Record{Generation: 2}, time.Minute)
+
+		_, exists := cache.Get("a")
+		Expect(exists).To(BeTrue())
+
+		cache.Put("c", &Record{Generation: 3}, time.Minute)
+		_, exists = cache.Get("b")
+		Expect(exists).To(BeFalse())
+
+		rec, exists := cache.Get("a")
+		Expect(exists).To(BeTrue())
+		Expect(rec.Generation).To(Equal(1))
+
+		cache.Remove("a")
+		_, exists = cache.Get("a")
+		Expect(exists).To(BeFalse())
+	})
+
+	It("should expire records after their TTL", func() {
+		cache := NewLRURecordCache(10)
+		cache.Put("a", &Record{}, time.Millisecond)
+		time.Sleep(5 * time.Millisecond)
+
+		_, exists := cache.Get("a")
+		Expect(exists).To(BeFalse())
+	})
+
+	It("should not cache records read while records were invalidated", func() {
+		rc := newRecordCache(NewCachePolicy())
+		key, _ := NewKey("test", "test", 1)
+
+		rec, invalidations := rc.get(key)
+		Expect(rec).To(BeNil())
+
+		rc.invalidate(key)
+		rc.put(key, &Record{}, invalidations)
+		rec, _ = rc.get(key)
+		Expect(rec).To(BeNil())
+	})
+
+})
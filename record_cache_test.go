@@ -0,0 +1,125 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike_test
+
+import (
+	"time"
+
+	. "github.com/THE108/aerospike-client-go"
+	"github.com/THE108/aerospike-client-go/aerotest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Record cache", func() {
+
+	var srv *aerotest.Server
+	var client, other *Client
+	var key *Key
+
+	var getBin = func(c *Client) interface{} {
+		rec, err := c.Get(nil, key)
+		Expect(err).ToNot(HaveOccurred())
+		return rec.Bins["a"]
+	}
+
+	BeforeEach(func() {
+		var err error
+		client, srv, err = aerotest.NewClientAndServer("test")
+		Expect(err).ToNot(HaveOccurred())
+		other, err = NewClient(srv.Host(), srv.Port())
+		Expect(err).ToNot(HaveOccurred())
+
+		policy := NewCachePolicy()
+		policy.SetTTLs = map[string]time.Duration{"test:nocache": 0}
+		client.SetRecordCache(policy)
+
+		key, _ = NewKey("test", "aerotest", "cached")
+		Expect(client.Put(nil, key, BinMap{"a": 1})).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		client.Close()
+		other.Close()
+		Expect(srv.Close()).ToNot(HaveOccurred())
+	})
+
+	It("must serve cached records until they are invalidated", func() {
+		Expect(getBin(client)).To(Equal(1))
+
+		// writes by other clients are not seen
+		Expect(other.Put(nil, key, BinMap{"a": 2})).ToNot(HaveOccurred())
+		Expect(getBin(client)).To(Equal(1))
+
+		client.InvalidateCachedRecords(key)
+		Expect(getBin(client)).To(Equal(2))
+	})
+
+	It("must drop records written through the client", func() {
+		Expect(getBin(client)).To(Equal(1))
+
+		Expect(client.Put(nil, key, BinMap{"a": 3})).ToNot(HaveOccurred())
+		Expect(getBin(client)).To(Equal(3))
+
+		_, err := client.Operate(nil, key, AddOp(NewBin("a", 1)))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(getBin(client)).To(Equal(4))
+
+		_, err = client.Delete(nil, key)
+		Expect(err).ToNot(HaveOccurred())
+		rec, err := client.Get(nil, key)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rec).To(BeNil())
+	})
+
+	It("must not cache sets with a zero TTL or reads of some bins", func() {
+		nocache, _ := NewKey("test", "nocache", "k")
+		Expect(client.Put(nil, nocache, BinMap{"a": 1})).ToNot(HaveOccurred())
+		_, err := client.Get(nil, nocache)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(other.Put(nil, nocache, BinMap{"a": 2})).ToNot(HaveOccurred())
+
+		rec, err := client.Get(nil, nocache)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rec.Bins["a"]).To(Equal(2))
+
+		_, err = client.Get(nil, key, "a")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(other.Put(nil, key, BinMap{"a": 5})).ToNot(HaveOccurred())
+		Expect(getBin(client)).To(Equal(5))
+	})
+
+	It("must return copies of cached records", func() {
+		rec, err := client.Get(nil, key)
+		Expect(err).ToNot(HaveOccurred())
+		rec.Bins["a"] = 10
+
+		Expect(getBin(client)).To(Equal(1))
+	})
+
+	It("must keep the keys of cached records when the key is reused", func() {
+		reused, _ := NewKey("test", "aerotest", "cached")
+		_, err := client.Get(nil, reused)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reused.SetValue("other")).ToNot(HaveOccurred())
+
+		rec, err := client.Get(nil, key)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rec.Key.Value().GetObject()).To(Equal("cached"))
+		Expect(rec.Key.Digest()).To(Equal(key.Digest()))
+	})
+
+})
@@ -0,0 +1,260 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"bytes"
+	"hash/crc32"
+	"sort"
+	"sync"
+
+	. "github.com/THE108/aerospike-client-go/types"
+	ParticleType "github.com/THE108/aerospike-client-go/types/particle_type"
+	Buffer "github.com/THE108/aerospike-client-go/utils/buffer"
+)
+
+// RecordChecksumBin is the name of the bin in which the client stores the
+// checksum of the records of sets with checksums enabled.
+const RecordChecksumBin = "_cksum"
+
+// recordChecksums holds the sets with checksums enabled, by "namespace:set".
+type recordChecksums struct {
+	mutex sync.RWMutex
+	sets  map[string]struct{}
+}
+
+// SetRecordChecksums enables or disables record checksums for the set.
+//
+// When enabled, Put, PutBins and PutObject store a CRC32 checksum of the key
+// digest and the bins in RecordChecksumBin, and Get and BatchGet of whole
+// records verify it. A record which does not match its checksum, or has no
+// checksum, fails with a RECORD_CHECKSUM_MISMATCH error. Reads of some bins
+// only, scans and queries are not verified; use VerifyRecordChecksum on the
+// records they return. The checksum bin is removed from verified records.
+//
+// Since the checksum covers the whole record, writes to the set must replace
+// the record: Puts need a REPLACE, REPLACE_ONLY or CREATE_ONLY
+// RecordExistsAction, and Add, Append, Prepend and Operate with write
+// operations return a PARAMETER_ERROR. UDFs modifying the records of the set
+// invalidate their checksums.
+func (clnt *Client) SetRecordChecksums(namespace, setName string, enabled bool) {
+	rc := &clnt.cluster.checksums
+	rc.mutex.Lock()
+	defer rc.mutex.Unlock()
+
+	if rc.sets == nil {
+		rc.sets = map[string]struct{}{}
+	}
+	if enabled {
+		rc.sets[namespace+":"+setName] = struct{}{}
+	} else {
+		delete(rc.sets, namespace+":"+setName)
+	}
+}
+
+func (clstr *Cluster) hasRecordChecksum(key *Key) bool {
+	rc := &clstr.checksums
+	rc.mutex.RLock()
+	defer rc.mutex.RUnlock()
+
+	_, exists := rc.sets[key.namespace+":"+key.setName]
+	return exists
+}
+
+// checksumWrite returns an error if the write would invalidate the checksum
+// of the record.
+func (clstr *Cluster) checksumWrite(policy *WritePolicy, key *Key, operation OperationType) error {
+	if !clstr.hasRecordChecksum(key) {
+		return nil
+	}
+
+	if operation != WRITE {
+		return NewAerospikeError(PARAMETER_ERROR, "Records with checksums can only be written whole")
+	}
+	switch policy.RecordExistsAction {
+	case REPLACE, REPLACE_ONLY, CREATE_ONLY:
+		return nil
+	}
+	return NewAerospikeError(PARAMETER_ERROR, "Records with checksums must be written with a REPLACE, REPLACE_ONLY or CREATE_ONLY RecordExistsAction")
+}
+
+// addRecordChecksum returns the bins with the checksum bin of the record.
+func addRecordChecksum(key *Key, bins []*Bin) ([]*Bin, error) {
+	values := make(map[string]interface{}, len(bins))
+	res := make([]*Bin, 0, len(bins)+1)
+	for _, bin := range bins {
+		if bin.Name == RecordChecksumBin {
+			continue
+		}
+		res = append(res, bin)
+
+		if bin.Value == nil || bin.Value.GetType() == ParticleType.NULL {
+			continue
+		}
+
+		// checksum the value as it is read back from the server
+		b, err := valueBytes(bin.Value)
+		if err != nil {
+			return nil, err
+		}
+		if values[bin.Name], err = bytesToParticle(bin.Value.GetType(), b, 0, len(b)); err != nil {
+			return nil, err
+		}
+	}
+
+	checksum, err := recordChecksum(key, values)
+	if err != nil {
+		return nil, err
+	}
+	return append(res, NewBin(RecordChecksumBin, checksum)), nil
+}
+
+// VerifyRecordChecksum verifies the record against the checksum stored in
+// RecordChecksumBin, which is removed from the record. The record must have
+// been read with all its bins. A RECORD_CHECKSUM_MISMATCH error is returned
+// if the checksum is missing or does not match.
+func VerifyRecordChecksum(rec *Record) error {
+	if rec == nil || rec.Key == nil {
+		return nil
+	}
+
+	stored, ok := rec.Bins[RecordChecksumBin].([]byte)
+	if !ok {
+		return NewAerospikeError(RECORD_CHECKSUM_MISMATCH, "Record has no checksum")
+	}
+
+	values := make(map[string]interface{}, len(rec.Bins))
+	for name, value := range rec.Bins {
+		if name != RecordChecksumBin {
+			values[name] = value
+		}
+	}
+
+	checksum, err := recordChecksum(rec.Key, values)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(checksum, stored) {
+		return NewAerospikeError(RECORD_CHECKSUM_MISMATCH)
+	}
+
+	delete(rec.Bins, RecordChecksumBin)
+	return nil
+}
+
+// verifyRecordChecksums verifies the records of sets with checksums enabled.
+func (clstr *Cluster) verifyRecordChecksums(records ...*Record) error {
+	for _, rec := range records {
+		if rec != nil && rec.Key != nil && clstr.hasRecordChecksum(rec.Key) {
+			if err := VerifyRecordChecksum(rec); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// recordChecksum returns the CRC32 checksum of the key digest and the sorted
+// bins, in a canonical encoding of their values.
+func recordChecksum(key *Key, values map[string]interface{}) ([]byte, error) {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	crc := crc32.NewIEEE()
+	crc.Write(key.digest)
+	for _, name := range names {
+		b, err := canonicalValueBytes(values[name])
+		if err != nil {
+			return nil, err
+		}
+		crc.Write([]byte(name))
+		crc.Write(b)
+	}
+
+	res := make([]byte, 4)
+	Buffer.Int32ToBytes(int32(crc.Sum32()), res, 0)
+	return res, nil
+}
+
+// canonicalValueBytes encodes the value as read from the server: scalars
+// by their particle type and wire format, lists and maps recursively, with
+// the entries of maps sorted by the encoding of their keys.
+func canonicalValueBytes(v interface{}) ([]byte, error) {
+	switch val := v.(type) {
+	case []interface{}:
+		res := []byte{byte(ParticleType.LIST)}
+		for _, item := range val {
+			b, err := canonicalValueBytes(item)
+			if err != nil {
+				return nil, err
+			}
+			res = appendLengthPrefixed(res, b)
+		}
+		return res, nil
+
+	case map[interface{}]interface{}:
+		entries := make([][]byte, 0, len(val))
+		for k, item := range val {
+			kb, err := canonicalValueBytes(k)
+			if err != nil {
+				return nil, err
+			}
+			vb, err := canonicalValueBytes(item)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, appendLengthPrefixed(appendLengthPrefixed(nil, kb), vb))
+		}
+		sort.Sort(byteSlices(entries))
+
+		res := []byte{byte(ParticleType.MAP)}
+		for _, entry := range entries {
+			res = append(res, entry...)
+		}
+		return res, nil
+
+	case float32:
+		return canonicalValueBytes(float64(val))
+
+	case float64:
+		// floats are only read inside lists and maps, and have no Value
+		res := make([]byte, 9)
+		res[0] = byte(ParticleType.FLOAT)
+		Buffer.Float64ToBytes(val, res, 1)
+		return res, nil
+	}
+
+	value := NewValue(v)
+	b, err := valueBytes(value)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{byte(value.GetType())}, b...), nil
+}
+
+func appendLengthPrefixed(buf, b []byte) []byte {
+	size := make([]byte, 4)
+	Buffer.Int32ToBytes(int32(len(b)), size, 0)
+	return append(append(buf, size...), b...)
+}
+
+type byteSlices [][]byte
+
+func (s byteSlices) Len() int           { return len(s) }
+func (s byteSlices) Less(i, j int) bool { return bytes.Compare(s[i], s[j]) < 0 }
+func (s byteSlices) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
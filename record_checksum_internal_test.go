@@ -0,0 +1,129 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	. "github.com/THE108/aerospike-client-go/types"
+	ParticleType "github.com/THE108/aerospike-client-go/types/particle_type"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Record Checksum Test", func() {
+
+	var key *Key
+
+	BeforeEach(func() {
+		key, _ = NewKey("test", "checksums", 1)
+	})
+
+	// readBack returns the record as read from the server after writing the bins
+	readBack := func(bins BinMap) *Record {
+		list := make([]*Bin, 0, len(bins))
+		for name, value := range bins {
+			list = append(list, NewBin(name, value))
+		}
+
+		withChecksum, err := addRecordChecksum(key, list)
+		Expect(err).ToNot(HaveOccurred())
+
+		read := BinMap{}
+		for _, bin := range withChecksum {
+			if bin.Value.GetType() != ParticleType.NULL {
+				read[bin.Name] = testPackingFor(bin.Value.GetObject())
+			}
+		}
+		return newRecord(nil, key, read, 1, 0)
+	}
+
+	resultCode := func(err error) ResultCode {
+		Expect(err).To(HaveOccurred())
+		return err.(AerospikeError).ResultCode()
+	}
+
+	It("should verify records read back and remove the checksum bin", func() {
+		bins := BinMap{
+			"int":    42,
+			"string": "str",
+			"bytes":  []byte{1, 2},
+			"list":   []interface{}{1, "a", []interface{}{2.5}},
+			"map":    map[interface{}]interface{}{"a": 1, "b": "c", 3: []interface{}{4}},
+			"nil":    nil,
+		}
+
+		rec := readBack(bins)
+		Expect(rec.Bins).To(HaveKey(RecordChecksumBin))
+		Expect(rec.Bins).ToNot(HaveKey("nil"))
+		Expect(VerifyRecordChecksum(rec)).ToNot(HaveOccurred())
+		Expect(rec.Bins).ToNot(HaveKey(RecordChecksumBin))
+		Expect(len(rec.Bins)).To(Equal(5))
+	})
+
+	It("should not depend on the order of map entries", func() {
+		m := map[interface{}]interface{}{}
+		for i := 0; i < 100; i++ {
+			m[i] = i * 2
+		}
+
+		c1, err := recordChecksum(key, map[string]interface{}{"m": m})
+		Expect(err).ToNot(HaveOccurred())
+		for i := 0; i < 10; i++ {
+			c2, err := recordChecksum(key, map[string]interface{}{"m": m})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(c2).To(Equal(c1))
+		}
+	})
+
+	It("should report modified records and missing checksums", func() {
+		rec := readBack(BinMap{"a": 1, "b": "str"})
+		rec.Bins["a"] = 2
+		Expect(resultCode(VerifyRecordChecksum(rec))).To(Equal(RECORD_CHECKSUM_MISMATCH))
+		Expect(rec.Bins).To(HaveKey(RecordChecksumBin))
+
+		rec = readBack(BinMap{"a": 1})
+		rec.Bins["b"] = "added"
+		Expect(resultCode(VerifyRecordChecksum(rec))).To(Equal(RECORD_CHECKSUM_MISMATCH))
+
+		// the digest is part of the checksum
+		rec = readBack(BinMap{"a": 1})
+		rec.Key, _ = NewKey("test", "checksums", 2)
+		Expect(resultCode(VerifyRecordChecksum(rec))).To(Equal(RECORD_CHECKSUM_MISMATCH))
+
+		rec = newRecord(nil, key, BinMap{"a": 1}, 1, 0)
+		Expect(resultCode(VerifyRecordChecksum(rec))).To(Equal(RECORD_CHECKSUM_MISMATCH))
+	})
+
+	It("should only allow writes replacing whole records", func() {
+		cluster := &Cluster{}
+		client := &Client{cluster: cluster}
+
+		policy := NewWritePolicy(0, 0)
+		Expect(cluster.checksumWrite(policy, key, WRITE)).ToNot(HaveOccurred())
+
+		client.SetRecordChecksums("test", "checksums", true)
+		Expect(resultCode(cluster.checksumWrite(policy, key, WRITE))).To(Equal(PARAMETER_ERROR))
+		policy.RecordExistsAction = REPLACE
+		Expect(cluster.checksumWrite(policy, key, WRITE)).ToNot(HaveOccurred())
+		Expect(resultCode(cluster.checksumWrite(policy, key, ADD))).To(Equal(PARAMETER_ERROR))
+
+		other, _ := NewKey("test", "other", 1)
+		Expect(cluster.checksumWrite(NewWritePolicy(0, 0), other, APPEND)).ToNot(HaveOccurred())
+
+		client.SetRecordChecksums("test", "checksums", false)
+		Expect(cluster.checksumWrite(NewWritePolicy(0, 0), key, ADD)).ToNot(HaveOccurred())
+	})
+
+})
@@ -0,0 +1,106 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike_test
+
+import (
+	. "github.com/THE108/aerospike-client-go"
+	"github.com/THE108/aerospike-client-go/aerotest"
+	. "github.com/THE108/aerospike-client-go/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Record checksums", func() {
+
+	var srv *aerotest.Server
+	var client, other *Client
+	var policy *WritePolicy
+
+	BeforeEach(func() {
+		var err error
+		client, srv, err = aerotest.NewClientAndServer("test")
+		Expect(err).ToNot(HaveOccurred())
+		other, err = NewClient(srv.Host(), srv.Port())
+		Expect(err).ToNot(HaveOccurred())
+
+		client.SetRecordChecksums("test", "aerotest", true)
+		policy = NewWritePolicy(0, 0)
+		policy.RecordExistsAction = REPLACE
+	})
+
+	AfterEach(func() {
+		client.Close()
+		other.Close()
+		Expect(srv.Close()).ToNot(HaveOccurred())
+	})
+
+	resultCode := func(err error) ResultCode {
+		Expect(err).To(HaveOccurred())
+		return err.(AerospikeError).ResultCode()
+	}
+
+	It("must verify whole records read back", func() {
+		key, _ := NewKey("test", "aerotest", "rec")
+		bins := BinMap{"s": "str", "n": 1, "l": []interface{}{1, "a"}}
+		Expect(client.Put(policy, key, bins)).ToNot(HaveOccurred())
+
+		rec, err := client.Get(nil, key)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rec.Bins).To(Equal(bins))
+
+		// partial reads are not verified
+		rec, err = client.Get(nil, key, "s")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rec.Bins).To(Equal(BinMap{"s": "str"}))
+
+		// the other client has no checksums configured
+		rec, err = other.Get(nil, key)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rec.Bins).To(HaveKey(RecordChecksumBin))
+		Expect(VerifyRecordChecksum(rec)).ToNot(HaveOccurred())
+		Expect(rec.Bins).To(Equal(bins))
+	})
+
+	It("must report records modified without a checksum", func() {
+		key, _ := NewKey("test", "aerotest", "rec")
+		Expect(client.Put(policy, key, BinMap{"n": 1})).ToNot(HaveOccurred())
+		Expect(other.Add(nil, key, BinMap{"n": 1})).ToNot(HaveOccurred())
+
+		_, err := client.Get(nil, key)
+		Expect(resultCode(err)).To(Equal(RECORD_CHECKSUM_MISMATCH))
+
+		key, _ = NewKey("test", "aerotest", "unchecked")
+		Expect(other.Put(nil, key, BinMap{"n": 1})).ToNot(HaveOccurred())
+		_, err = client.Get(nil, key)
+		Expect(resultCode(err)).To(Equal(RECORD_CHECKSUM_MISMATCH))
+	})
+
+	It("must reject writes which would invalidate the checksum", func() {
+		key, _ := NewKey("test", "aerotest", "rec")
+		Expect(resultCode(client.Put(nil, key, BinMap{"n": 1}))).To(Equal(PARAMETER_ERROR))
+		Expect(resultCode(client.Add(policy, key, BinMap{"n": 1}))).To(Equal(PARAMETER_ERROR))
+
+		_, err := client.Operate(policy, key, AddOp(NewBin("n", 1)))
+		Expect(resultCode(err)).To(Equal(PARAMETER_ERROR))
+
+		// reads are allowed
+		Expect(client.Put(policy, key, BinMap{"n": 1})).ToNot(HaveOccurred())
+		rec, err := client.Operate(policy, key, GetOp())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rec.Bins).To(HaveKey("n"))
+	})
+
+})
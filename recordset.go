@@ -15,8 +15,10 @@
 package aerospike
 
 import (
+	"strconv"
 	"sync"
 
+	. "github.com/THE108/aerospike-client-go/logger"
 	. "github.com/THE108/aerospike-client-go/types/atomic"
 )
 
@@ -26,6 +28,7 @@ type Result struct {
 }
 
 // Recordset encapsulates the result of Scan and Query commands.
+// Recordset is safe for concurrent use; it can be closed from any goroutine.
 type Recordset struct {
 	// Records is a channel on which the resulting records will be sent back.
 	// NOTE: Do not use Records directly. Range on channel returned by Results() instead.
@@ -42,6 +45,14 @@ type Recordset struct {
 	active    *AtomicBool
 	cancelled chan struct{}
 
+	// onEnd is called when all goroutines have finished, before the recordset
+	// is closed; an error it returns is delivered as the last result.
+	onEnd func() error
+
+	// abort terminates the scan or query on the server if the recordset is
+	// closed before all results were received.
+	abort func()
+
 	chanLock sync.Mutex
 }
 
@@ -59,6 +70,25 @@ func newRecordset(recSize, goroutines int) *Recordset {
 	return rs
 }
 
+// NewRecordsetFromResults returns a finished Recordset which yields the given
+// records and errors. It is useful to stub the results of Scan and Query
+// commands in unit tests.
+func NewRecordsetFromResults(results ...*Result) *Recordset {
+	rs := newRecordset(len(results), 1)
+	rs.Errors = make(chan error, len(results))
+
+	for _, r := range results {
+		if r.Err != nil {
+			rs.sendError(r.Err)
+		} else {
+			rs.Records <- r.Record
+		}
+	}
+	rs.signalEnd()
+
+	return rs
+}
+
 // IsActive returns true if the operation hasn't been finished or cancelled.
 func (rcs *Recordset) IsActive() bool {
 	return rcs.active.Get()
@@ -93,6 +123,12 @@ func (rcs *Recordset) Results() <-chan *Result {
 				if r != nil {
 					res <- &Result{Record: r, Err: nil}
 				} else {
+					// the recordset is closed; deliver errors still buffered
+					for e := range rcs.Errors {
+						if e != nil {
+							res <- &Result{Record: nil, Err: e}
+						}
+					}
 					close(res)
 					break L
 				}
@@ -108,12 +144,18 @@ func (rcs *Recordset) Results() <-chan *Result {
 }
 
 // Close all streams from different nodes.
+// If the scan or query is still running, it is aborted on the server as well;
+// otherwise the server would run it to the end although nobody reads the results.
 func (rcs *Recordset) Close() {
 	// do it only once
 	if rcs.active.CompareAndToggle(true) {
 		// this will broadcast to all commands listening to the channel
 		close(rcs.cancelled)
 
+		if rcs.abort != nil && rcs.goroutines.Get() > 0 {
+			rcs.abort()
+		}
+
 		// wait till all goroutines are done
 		rcs.wgGoroutines.Wait()
 
@@ -124,9 +166,59 @@ func (rcs *Recordset) Close() {
 	}
 }
 
+// abortOnClose makes Close send the info commands to the nodes if results are
+// still expected. Nodes which have already finished the job ignore them.
+func (rcs *Recordset) abortOnClose(nodes []*Node, commands ...string) {
+	if len(commands) == 0 {
+		return
+	}
+
+	rcs.abort = func() {
+		var wg sync.WaitGroup
+		for _, node := range nodes {
+			wg.Add(1)
+			go func(node *Node) {
+				defer wg.Done()
+				if _, err := RequestNodeInfo(node, commands...); err != nil {
+					Logger.Debug("Failed to abort the job on node %s: %s", node, err)
+				}
+			}(node)
+		}
+		wg.Wait()
+	}
+}
+
+// scanAbortCommands returns the info commands aborting the scans with the
+// task ids from taskId to taskId+scans-1.
+func scanAbortCommands(taskId uint64, scans int) []string {
+	commands := make([]string, scans)
+	for i := range commands {
+		commands[i] = "scan-abort:id=" + strconv.FormatUint(taskId+uint64(i), 10)
+	}
+	return commands
+}
+
+// queryAbortCommands returns the info commands aborting the query of the
+// statement. Statements without filters are executed as scans. Queries sent
+// without a task id can not be aborted.
+func queryAbortCommands(statement *Statement) []string {
+	if statement.TaskId == 0 {
+		return nil
+	}
+	if statement.IsScan() {
+		return scanAbortCommands(statement.TaskId, 1)
+	}
+	return []string{"query-kill:trid=" + strconv.FormatUint(statement.TaskId, 10)}
+}
+
 func (rcs *Recordset) signalEnd() {
 	rcs.wgGoroutines.Done()
 	if rcs.goroutines.DecrementAndGet() == 0 {
+		if rcs.onEnd != nil && rcs.IsActive() {
+			if err := rcs.onEnd(); err != nil {
+				rcs.sendError(err)
+			}
+		}
 		rcs.Close()
 	}
 }
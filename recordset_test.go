@@ -16,6 +16,8 @@ package aerospike
 
 import (
 	"errors"
+	"net"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -36,4 +38,95 @@ var _ = Describe("Recordset test", func() {
 		Expect(<-rs.Errors).To(BeNil())
 	})
 
+	It("must yield stubbed results", func() {
+		rs := NewRecordsetFromResults(
+			&Result{Record: newRecord(nil, nil, BinMap{"a": 1}, 1, 0)},
+			&Result{Err: errors.New("Error")},
+			&Result{Record: newRecord(nil, nil, BinMap{"a": 2}, 1, 0)},
+		)
+
+		var ifc RecordsetIface = rs
+
+		records, errs := 0, 0
+		for res := range ifc.Results() {
+			if res.Err != nil {
+				errs++
+			} else {
+				records++
+			}
+		}
+		Expect(records).To(Equal(2))
+		Expect(errs).To(Equal(1))
+		Expect(ifc.IsActive()).To(BeFalse())
+	})
+
+	It("must abort jobs on the server only if closed before the end", func() {
+		aborted := 0
+
+		rs := newRecordset(10, 1)
+		rs.abort = func() { aborted++ }
+		go func() {
+			<-rs.cancelled
+			rs.signalEnd()
+		}()
+		rs.Close()
+		rs.Close()
+		Expect(aborted).To(Equal(1))
+
+		rs = newRecordset(10, 1)
+		rs.abort = func() { aborted++ }
+		rs.signalEnd()
+		Expect(rs.IsActive()).To(BeFalse())
+		Expect(aborted).To(Equal(1))
+
+		Expect(scanAbortCommands(7, 2)).To(Equal([]string{"scan-abort:id=7", "scan-abort:id=8"}))
+
+		stmt := NewStatement("test", "set")
+		stmt.TaskId = 9
+		Expect(queryAbortCommands(stmt)).To(Equal([]string{"scan-abort:id=9"}))
+		stmt.Addfilter(NewEqualFilter("bin", 1))
+		Expect(queryAbortCommands(stmt)).To(Equal([]string{"query-kill:trid=9"}))
+		stmt.TaskId = 0
+		Expect(queryAbortCommands(stmt)).To(BeNil())
+
+		// recordsets without jobs to abort
+		rs = newRecordset(10, 1)
+		rs.abortOnClose(nil)
+		Expect(rs.abort).To(BeNil())
+	})
+
+	It("must pause multi commands while the consumer lags, without losing their timeout", func() {
+		client, server := net.Pipe()
+		defer client.Close()
+		defer server.Close()
+
+		conn := &Connection{conn: client}
+		Expect(conn.SetTimeout(time.Second)).ToNot(HaveOccurred())
+		deadline := conn.deadline
+
+		rs := newRecordset(1, 1)
+		cmd := newMultiCommand(nil, rs)
+		cmd.conn = conn
+
+		Expect(cmd.sendRecord(newRecord(nil, nil, BinMap{"a": 1}, 1, 0))).ToNot(HaveOccurred())
+
+		// the queue is full; the next record must wait for the consumer
+		sent := make(chan error, 1)
+		go func() {
+			sent <- cmd.sendRecord(newRecord(nil, nil, BinMap{"a": 2}, 1, 0))
+		}()
+
+		time.Sleep(50 * time.Millisecond)
+		Expect(len(sent)).To(Equal(0))
+
+		Expect((<-rs.Records).Bins["a"]).To(Equal(1))
+		Expect(<-sent).ToNot(HaveOccurred())
+		Expect(conn.deadline.Sub(deadline)).To(BeNumerically(">=", 50*time.Millisecond))
+
+		// a cancelled recordset must not block the command
+		go rs.Close()
+		Expect(cmd.sendRecord(newRecord(nil, nil, BinMap{"a": 3}, 1, 0))).To(HaveOccurred())
+		rs.signalEnd()
+	})
+
 })
@@ -0,0 +1,758 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	. "github.com/THE108/aerospike-client-go/types"
+	ParticleType "github.com/THE108/aerospike-client-go/types/particle_type"
+)
+
+// RestorePolicy encapsulates parameters used in restore operations.
+type RestorePolicy struct {
+	// WritePolicy is used to write the records. Generation, GenerationPolicy
+	// and Expiration are overridden for each record unless IgnoreGeneration
+	// and IgnoreExpiration are set, respectively.
+	// User keys stored in the backup are always sent to the server.
+	WritePolicy
+
+	// Format determines the format of the backup. Default is BACKUP_ASB.
+	Format BackupFormat
+
+	// Namespace overrides the namespace of the records in the backup.
+	// Default (empty) restores the records to their original namespace.
+	Namespace string
+
+	// IgnoreGeneration writes the records regardless of their generation.
+	// By default, records are only written if they do not exist or their
+	// generation on the server is lower than in the backup (EXPECT_GEN_GT),
+	// so newer records are kept.
+	IgnoreGeneration bool
+
+	// IgnoreExpiration writes the records with the Expiration of the WritePolicy.
+	// By default, the TTL of each record is computed from the void time in the
+	// backup, and records which have expired in the meantime are not restored.
+	IgnoreExpiration bool
+
+	// Parallel is the number of goroutines writing records. Default is 16.
+	Parallel int
+
+	// RecordsPerSecond throttles the restore to a maximum number of records
+	// per second. Default (0) is not to throttle.
+	RecordsPerSecond int
+}
+
+// NewRestorePolicy generates a new RestorePolicy instance with default values.
+func NewRestorePolicy() *RestorePolicy {
+	return &RestorePolicy{
+		WritePolicy: *NewWritePolicy(0, 0),
+		Format:      BACKUP_ASB,
+		Parallel:    16,
+	}
+}
+
+// Clone returns a copy of the policy.
+func (p *RestorePolicy) Clone() *RestorePolicy {
+	res := *p
+	return &res
+}
+
+// RestoreStats contains the statistics of a finished restore.
+type RestoreStats struct {
+	// Records is the number of records written.
+	Records int64
+	// Skipped is the number of records not written because a newer
+	// record exists on the server, or the record exists and the
+	// RecordExistsAction of the policy is CREATE_ONLY.
+	Skipped int64
+	// Expired is the number of records not written because they expired.
+	Expired int64
+
+	Duration time.Duration
+}
+
+// Restore reads records from a backup in the format determined by the policy,
+// and writes them to the cluster. Global sections of asb files, e.g. UDFs and
+// indexes, are not restored.
+// The restore stops on the first error.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *Client) Restore(policy *RestorePolicy, r io.Reader) (*RestoreStats, error) {
+	if policy == nil {
+		policy = NewRestorePolicy()
+	}
+
+	decoder, err := newBackupDecoder(policy.Format, r)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	stats := &RestoreStats{}
+	limiter := newThrottle(policy.RecordsPerSecond)
+
+	var once sync.Once
+	var firstErr error
+	abort := make(chan struct{})
+	fail := func(err error) {
+		once.Do(func() {
+			firstErr = err
+			close(abort)
+		})
+	}
+
+	parallel := policy.Parallel
+	if parallel <= 0 {
+		parallel = 1
+	}
+	records := make(chan *backupRecord, parallel)
+
+	var wg sync.WaitGroup
+	wg.Add(parallel)
+	for i := 0; i < parallel; i++ {
+		go func() {
+			defer wg.Done()
+
+			for rec := range records {
+				select {
+				case <-abort:
+					continue
+				default:
+				}
+
+				if err := clnt.restoreRecord(policy, rec, stats); err != nil {
+					fail(err)
+				}
+			}
+		}()
+	}
+
+L:
+	for {
+		rec, err := decoder.next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			fail(err)
+			break
+		}
+
+		limiter.wait()
+
+		select {
+		case records <- rec:
+		case <-abort:
+			break L
+		}
+	}
+	close(records)
+	wg.Wait()
+
+	stats.Duration = time.Since(start)
+	return stats, firstErr
+}
+
+func (clnt *Client) restoreRecord(policy *RestorePolicy, rec *backupRecord, stats *RestoreStats) error {
+	// copy the policy; it is shared between goroutines
+	wp := policy.WritePolicy
+	wp.SendKey = rec.hasUserKey
+
+	if !policy.IgnoreGeneration {
+		wp.GenerationPolicy = EXPECT_GEN_GT
+		wp.Generation = int32(rec.generation)
+	}
+
+	if !policy.IgnoreExpiration {
+		if rec.voidTime <= 0 {
+			wp.Expiration = -1
+		} else {
+			ttl := rec.voidTime - (time.Now().Unix() - CITRUSLEAF_EPOCH)
+			if ttl <= 0 {
+				atomic.AddInt64(&stats.Expired, 1)
+				return nil
+			}
+			wp.Expiration = int32(ttl)
+		}
+	}
+
+	if policy.Namespace != "" {
+		rec.key.namespace = policy.Namespace
+	}
+
+	bins := make([]*Bin, 0, len(rec.bins))
+	for name, value := range rec.bins {
+		bins = append(bins, NewBin(name, value))
+	}
+
+	if err := clnt.PutBins(&wp, rec.key, bins...); err != nil {
+		if ae, ok := err.(AerospikeError); ok {
+			switch ae.ResultCode() {
+			case GENERATION_ERROR, KEY_EXISTS_ERROR:
+				atomic.AddInt64(&stats.Skipped, 1)
+				return nil
+			}
+		}
+		return err
+	}
+
+	atomic.AddInt64(&stats.Records, 1)
+	return nil
+}
+
+// backupRecord is a record read from a backup.
+type backupRecord struct {
+	key        *Key
+	hasUserKey bool
+	bins       BinMap
+	generation int
+	voidTime   int64
+}
+
+func newBackupRecord(namespace, setName string, userKey interface{}, digest []byte) (*backupRecord, error) {
+	var key *Key
+	var err error
+	if digest != nil {
+		key, err = NewKeyWithDigest(namespace, setName, userKey, digest)
+	} else {
+		key, err = NewKey(namespace, setName, userKey)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &backupRecord{key: key, hasUserKey: userKey != nil}, nil
+}
+
+type backupDecoder interface {
+	// next returns the next record, or io.EOF at the end of the backup.
+	next() (*backupRecord, error)
+}
+
+func newBackupDecoder(format BackupFormat, r io.Reader) (backupDecoder, error) {
+	switch format {
+	case BACKUP_ASB:
+		return &asbDecoder{r: bufio.NewReader(r), line: 1}, nil
+	case BACKUP_NDJSON:
+		d := json.NewDecoder(r)
+		d.UseNumber()
+		return &ndjsonDecoder{d: d}, nil
+	}
+	return nil, NewAerospikeError(PARAMETER_ERROR, fmt.Sprintf("Invalid backup format: %d", format))
+}
+
+// asbDecoder reads records in asbackup's text format.
+type asbDecoder struct {
+	r    *bufio.Reader
+	line int
+	eol  bool
+}
+
+func (d *asbDecoder) error(msg string, args ...interface{}) error {
+	return NewAerospikeError(PARSE_ERROR, fmt.Sprintf("Invalid backup file at line %d: %s", d.line, fmt.Sprintf(msg, args...)))
+}
+
+// advance updates the line number after the data has been read.
+// The line number of errors is the line of the last byte read.
+func (d *asbDecoder) advance(data ...byte) {
+	for _, c := range data {
+		if d.eol {
+			d.line++
+		}
+		d.eol = c == '\n'
+	}
+}
+
+func (d *asbDecoder) readByte() (byte, error) {
+	c, err := d.r.ReadByte()
+	if err == io.EOF {
+		return 0, d.error("unexpected end of file")
+	} else if err != nil {
+		return 0, err
+	}
+
+	d.advance(c)
+	return c, nil
+}
+
+func (d *asbDecoder) skipLine() error {
+	s, err := d.r.ReadString('\n')
+	if err != nil {
+		return d.error("unexpected end of file")
+	}
+	d.advance([]byte(s)...)
+	return nil
+}
+
+func (d *asbDecoder) expect(expected byte) error {
+	c, err := d.readByte()
+	if err != nil {
+		return err
+	}
+	if c != expected {
+		return d.error("expected %q, found %q", expected, c)
+	}
+	return nil
+}
+
+// readToken reads an escaped token terminated by a space or new line.
+func (d *asbDecoder) readToken() (string, byte, error) {
+	var buf bytes.Buffer
+	for {
+		c, err := d.readByte()
+		if err != nil {
+			return "", 0, err
+		}
+
+		switch c {
+		case '\\':
+			if c, err = d.readByte(); err != nil {
+				return "", 0, err
+			}
+		case ' ', '\n':
+			return buf.String(), c, nil
+		}
+		buf.WriteByte(c)
+	}
+}
+
+// readLine reads an escaped token terminated by a new line.
+func (d *asbDecoder) readLine() (string, error) {
+	s, term, err := d.readToken()
+	if err != nil {
+		return "", err
+	}
+	if term != '\n' {
+		return "", d.error("unexpected space after %q", s)
+	}
+	return s, nil
+}
+
+func (d *asbDecoder) readInt(last bool) (int64, error) {
+	s, term, err := d.readToken()
+	if err != nil {
+		return 0, err
+	}
+	if last != (term == '\n') {
+		return 0, d.error("unexpected %q after %q", term, s)
+	}
+
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, d.error("invalid integer %q", s)
+	}
+	return v, nil
+}
+
+// readData reads a length-prefixed value terminated by a new line.
+// If encoded is set, the value is decoded from base64.
+func (d *asbDecoder) readData(encoded bool) ([]byte, error) {
+	length, err := d.readInt(false)
+	if err != nil {
+		return nil, err
+	}
+	if length < 0 || length > int64(MaxBufferSize) {
+		return nil, d.error("invalid length %d", length)
+	}
+
+	data := make([]byte, length)
+	if _, err := io.ReadFull(d.r, data); err != nil {
+		return nil, d.error("unexpected end of file")
+	}
+	d.advance(data...)
+
+	if err := d.expect('\n'); err != nil {
+		return nil, err
+	}
+
+	if encoded {
+		if data, err = base64.StdEncoding.DecodeString(string(data)); err != nil {
+			return nil, d.error("invalid base64 data")
+		}
+	}
+	return data, nil
+}
+
+func (d *asbDecoder) next() (*backupRecord, error) {
+	var namespace, setName string
+	var userKey interface{}
+	var digest []byte
+	var generation int
+	var voidTime int64
+
+	for {
+		c, err := d.r.ReadByte()
+		if err == io.EOF && namespace == "" && digest == nil && userKey == nil {
+			return nil, io.EOF
+		} else if err == io.EOF {
+			return nil, d.error("unexpected end of file")
+		} else if err != nil {
+			return nil, err
+		}
+		d.advance(c)
+
+		switch c {
+		case 'V':
+			s, term, err := d.readToken()
+			if err != nil {
+				return nil, err
+			}
+			if s != "ersion" || term != ' ' {
+				return nil, d.error("invalid header")
+			}
+
+			version, err := d.readLine()
+			if err != nil {
+				return nil, err
+			}
+			if version != "3.0" && version != "3.1" {
+				return nil, d.error("unsupported version %q", version)
+			}
+
+		case '*':
+			// global sections, which are not restored
+			if err := d.skipGlobal(); err != nil {
+				return nil, err
+			}
+
+		case '#':
+			// meta data
+			if err := d.skipLine(); err != nil {
+				return nil, err
+			}
+
+		case '+':
+			if err := d.expect(' '); err != nil {
+				return nil, err
+			}
+			field, err := d.readByte()
+			if err != nil {
+				return nil, err
+			}
+			if err := d.expect(' '); err != nil {
+				return nil, err
+			}
+
+			switch field {
+			case 'k':
+				if userKey, err = d.readKey(); err != nil {
+					return nil, err
+				}
+			case 'n':
+				if namespace, err = d.readLine(); err != nil {
+					return nil, err
+				}
+			case 'd':
+				s, err := d.readLine()
+				if err != nil {
+					return nil, err
+				}
+				if digest, err = base64.StdEncoding.DecodeString(s); err != nil {
+					return nil, d.error("invalid digest %q", s)
+				}
+			case 's':
+				if setName, err = d.readLine(); err != nil {
+					return nil, err
+				}
+			case 'g':
+				gen, err := d.readInt(true)
+				if err != nil {
+					return nil, err
+				}
+				generation = int(gen)
+			case 't':
+				if voidTime, err = d.readInt(true); err != nil {
+					return nil, err
+				}
+			case 'b':
+				count, err := d.readInt(true)
+				if err != nil {
+					return nil, err
+				}
+
+				rec, err := newBackupRecord(namespace, setName, userKey, digest)
+				if err != nil {
+					return nil, d.error("%s", err)
+				}
+				rec.generation = generation
+				rec.voidTime = voidTime
+
+				if rec.bins, err = d.readBins(int(count)); err != nil {
+					return nil, err
+				}
+				return rec, nil
+			default:
+				return nil, d.error("unknown record field %q", field)
+			}
+
+		default:
+			return nil, d.error("unexpected %q", c)
+		}
+	}
+}
+
+// skipGlobal skips a secondary index or UDF line.
+// UDF lines contain the length of the file, which can span multiple lines.
+func (d *asbDecoder) skipGlobal() error {
+	if err := d.expect(' '); err != nil {
+		return err
+	}
+	section, err := d.readByte()
+	if err != nil {
+		return err
+	}
+
+	if section == 'u' {
+		// * u <type> <name> <length> <content>
+		if err := d.expect(' '); err != nil {
+			return err
+		}
+		if _, _, err := d.readToken(); err != nil {
+			return err
+		}
+		if _, term, err := d.readToken(); err != nil {
+			return err
+		} else if term != ' ' {
+			return d.error("invalid UDF")
+		}
+		_, err := d.readData(false)
+		return err
+	}
+
+	if section != '\n' {
+		return d.skipLine()
+	}
+	return nil
+}
+
+func (d *asbDecoder) readKey() (interface{}, error) {
+	keyType, err := d.readByte()
+	if err != nil {
+		return nil, err
+	}
+
+	raw := false
+	c, err := d.readByte()
+	if err != nil {
+		return nil, err
+	}
+	if c == '!' {
+		raw = true
+		c, err = d.readByte()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if c != ' ' {
+		return nil, d.error("expected ' ', found %q", c)
+	}
+
+	switch keyType {
+	case 'I':
+		return d.readInt(true)
+	case 'S':
+		data, err := d.readData(false)
+		return string(data), err
+	case 'B':
+		return d.readData(!raw)
+	}
+	return nil, d.error("unsupported key type %q", keyType)
+}
+
+func (d *asbDecoder) readBins(count int) (BinMap, error) {
+	bins := make(BinMap, count)
+	for i := 0; i < count; i++ {
+		if err := d.expect('-'); err != nil {
+			return nil, err
+		}
+		if err := d.expect(' '); err != nil {
+			return nil, err
+		}
+		binType, err := d.readByte()
+		if err != nil {
+			return nil, err
+		}
+
+		raw := false
+		c, err := d.readByte()
+		if err != nil {
+			return nil, err
+		}
+		if c == '!' {
+			raw = true
+			if c, err = d.readByte(); err != nil {
+				return nil, err
+			}
+		}
+		if c != ' ' {
+			return nil, d.error("expected ' ', found %q", c)
+		}
+
+		name, term, err := d.readToken()
+		if err != nil {
+			return nil, err
+		}
+		if (binType == 'N') != (term == '\n') {
+			return nil, d.error("unexpected %q after bin name %q", term, name)
+		}
+
+		switch binType {
+		case 'N':
+			bins[name] = nil
+		case 'I':
+			if bins[name], err = d.readInt(true); err != nil {
+				return nil, err
+			}
+		case 'S':
+			data, err := d.readData(false)
+			if err != nil {
+				return nil, err
+			}
+			bins[name] = string(data)
+		case 'B':
+			if bins[name], err = d.readData(!raw); err != nil {
+				return nil, err
+			}
+		case 'L', 'M':
+			data, err := d.readData(!raw)
+			if err != nil {
+				return nil, err
+			}
+
+			ptype := ParticleType.LIST
+			if binType == 'M' {
+				ptype = ParticleType.MAP
+			}
+			if bins[name], err = bytesToParticle(ptype, data, 0, len(data)); err != nil {
+				return nil, d.error("invalid value of bin %q: %s", name, err)
+			}
+		default:
+			return nil, d.error("unsupported type %q of bin %q", binType, name)
+		}
+	}
+	return bins, nil
+}
+
+// ndjsonDecoder reads records written by the NDJSON backup format.
+type ndjsonDecoder struct {
+	d *json.Decoder
+}
+
+func (d *ndjsonDecoder) next() (*backupRecord, error) {
+	var r ndjsonRecord
+	if err := d.d.Decode(&r); err == io.EOF {
+		return nil, io.EOF
+	} else if err != nil {
+		return nil, NewAerospikeError(PARSE_ERROR, "Invalid backup file: "+err.Error())
+	}
+
+	userKey, err := fromJSONValue(r.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	var digest []byte
+	if len(r.Digest) > 0 {
+		digest = r.Digest
+	}
+
+	rec, err := newBackupRecord(r.Namespace, r.Set, userKey, digest)
+	if err != nil {
+		return nil, err
+	}
+	rec.generation = r.Generation
+	rec.voidTime = r.VoidTime
+
+	rec.bins = make(BinMap, len(r.Bins))
+	for name, value := range r.Bins {
+		if rec.bins[name], err = fromJSONValue(value); err != nil {
+			return nil, err
+		}
+	}
+	return rec, nil
+}
+
+// fromJSONValue reverses toJSONValue.
+func fromJSONValue(v interface{}) (interface{}, error) {
+	switch val := v.(type) {
+	case json.Number:
+		i, err := val.Int64()
+		if err != nil {
+			return nil, NewAerospikeError(TYPE_NOT_SUPPORTED, "Invalid backup file: unsupported number "+val.String())
+		}
+		return i, nil
+	case []interface{}:
+		res := make([]interface{}, len(val))
+		for i := range val {
+			var err error
+			if res[i], err = fromJSONValue(val[i]); err != nil {
+				return nil, err
+			}
+		}
+		return res, nil
+	case map[string]interface{}:
+		if len(val) == 1 {
+			if s, ok := val["$blob"].(string); ok {
+				b, err := base64.StdEncoding.DecodeString(s)
+				if err != nil {
+					return nil, NewAerospikeError(PARSE_ERROR, "Invalid backup file: invalid blob")
+				}
+				return b, nil
+			}
+			if pairs, ok := val["$map"].([]interface{}); ok {
+				return jsonPairsToMap(pairs)
+			}
+		}
+
+		res := make(map[interface{}]interface{}, len(val))
+		for k, v := range val {
+			var err error
+			if res[k], err = fromJSONValue(v); err != nil {
+				return nil, err
+			}
+		}
+		return res, nil
+	}
+	return v, nil
+}
+
+func jsonPairsToMap(pairs []interface{}) (interface{}, error) {
+	res := make(map[interface{}]interface{}, len(pairs))
+	for _, p := range pairs {
+		pair, ok := p.([]interface{})
+		if !ok || len(pair) != 2 {
+			return nil, NewAerospikeError(PARSE_ERROR, "Invalid backup file: invalid map entry")
+		}
+
+		k, err := fromJSONValue(pair[0])
+		if err != nil {
+			return nil, err
+		}
+		v, err := fromJSONValue(pair[1])
+		if err != nil {
+			return nil, err
+		}
+		res[k] = v
+	}
+	return res, nil
+}
@@ -0,0 +1,156 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"bytes"
+	"io"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Restore Test", func() {
+
+	var records []*Record
+
+	BeforeEach(func() {
+		key1, _ := NewKey("test", "demo", "key1")
+		key2, _ := NewKey("test", "my set", 42)
+		key3, _ := NewKeyWithDigest("test", "", nil, bytes.Repeat([]byte{7}, 20))
+
+		records = []*Record{
+			newRecord(nil, key1, BinMap{
+				"int":    10,
+				"str":    "multi\nline string",
+				"blob":   []byte{1, 2, 3},
+				"list":   []interface{}{1, "a", []byte{4}},
+				"map":    map[interface{}]interface{}{1: "a", "b": []interface{}{2}},
+				"my bin": "x",
+			}, 3, 0),
+			newRecord(nil, key2, BinMap{"a": 1}, 1, 0),
+			newRecord(nil, key3, BinMap{"a": "b"}, 2, 0),
+		}
+	})
+
+	var roundTrip = func(format BackupFormat) []*backupRecord {
+		encoder, err := newBackupEncoder(format)
+		Expect(err).ToNot(HaveOccurred())
+
+		var buf bytes.Buffer
+		buf.Write(encoder.header("test"))
+		for _, rec := range records {
+			Expect(encoder.encode(&buf, rec)).ToNot(HaveOccurred())
+		}
+
+		decoder, err := newBackupDecoder(format, &buf)
+		Expect(err).ToNot(HaveOccurred())
+
+		var res []*backupRecord
+		for {
+			rec, err := decoder.next()
+			if err == io.EOF {
+				break
+			}
+			Expect(err).ToNot(HaveOccurred())
+			res = append(res, rec)
+		}
+		return res
+	}
+
+	var checkRecords = func(res []*backupRecord, bins BinMap) {
+		Expect(len(res)).To(Equal(len(records)))
+
+		Expect(res[0].key.Equals(records[0].Key)).To(BeTrue())
+		Expect(res[0].key.SetName()).To(Equal("demo"))
+		Expect(res[0].hasUserKey).To(BeTrue())
+		Expect(res[0].key.Value().GetObject()).To(Equal("key1"))
+		Expect(res[0].generation).To(Equal(3))
+		Expect(res[0].bins).To(Equal(bins))
+
+		Expect(res[1].key.SetName()).To(Equal("my set"))
+		Expect(res[1].key.Value().GetObject()).To(Equal(int64(42)))
+		Expect(res[1].key.Equals(records[1].Key)).To(BeTrue())
+
+		Expect(res[2].hasUserKey).To(BeFalse())
+		Expect(res[2].key.Digest()).To(Equal(bytes.Repeat([]byte{7}, 20)))
+		Expect(res[2].bins).To(Equal(BinMap{"a": "b"}))
+	}
+
+	It("should read back records written in asb format", func() {
+		res := roundTrip(BACKUP_ASB)
+		checkRecords(res, BinMap{
+			"int":    int64(10),
+			"str":    "multi\nline string",
+			"blob":   []byte{1, 2, 3},
+			"list":   []interface{}{1, "a", []byte{4}},
+			"map":    map[interface{}]interface{}{1: "a", "b": []interface{}{2}},
+			"my bin": "x",
+		})
+	})
+
+	It("should read back records written as NDJSON", func() {
+		// JSON numbers are restored as int64
+		res := roundTrip(BACKUP_NDJSON)
+		checkRecords(res, BinMap{
+			"int":    int64(10),
+			"str":    "multi\nline string",
+			"blob":   []byte{1, 2, 3},
+			"list":   []interface{}{int64(1), "a", []byte{4}},
+			"map":    map[interface{}]interface{}{int64(1): "a", "b": []interface{}{int64(2)}},
+			"my bin": "x",
+		})
+	})
+
+	It("should skip global sections of asb files", func() {
+		backup := strings.Join([]string{
+			"Version 3.1",
+			"# namespace test",
+			"# first-file",
+			"* i test demo idx 1 a N",
+			"* u L test.lua 8 x=1\ny=2\n",
+			"+ n test",
+			"+ d AAAAAAAAAAAAAAAAAAAAAAAAAAA=",
+			"+ g 1",
+			"+ t 0",
+			"+ b 1",
+			"- I a 1",
+			"",
+		}, "\n")
+
+		decoder, _ := newBackupDecoder(BACKUP_ASB, strings.NewReader(backup))
+		rec, err := decoder.next()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rec.bins).To(Equal(BinMap{"a": int64(1)}))
+
+		_, err = decoder.next()
+		Expect(err).To(Equal(io.EOF))
+	})
+
+	It("should report invalid asb files with the line number", func() {
+		backup := "Version 3.1\n+ n test\n+ g x\n"
+
+		decoder, _ := newBackupDecoder(BACKUP_ASB, strings.NewReader(backup))
+		_, err := decoder.next()
+		Expect(err).To(HaveOccurred())
+		Expect(strings.Contains(err.Error(), "line 3")).To(BeTrue())
+
+		decoder, _ = newBackupDecoder(BACKUP_ASB, strings.NewReader("Version 3.1\n+ n test\n+ b 1\n"))
+		_, err = decoder.next()
+		Expect(err).To(HaveOccurred())
+	})
+
+})
@@ -0,0 +1,125 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike_test
+
+import (
+	"strings"
+
+	. "github.com/THE108/aerospike-client-go"
+	"github.com/THE108/aerospike-client-go/aerotest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Restore", func() {
+
+	var srv *aerotest.Server
+	var client *Client
+
+	// key1 and key2 never expire; key3 expired in 2010
+	backup := strings.Join([]string{
+		"Version 3.1",
+		"# namespace test",
+		"# first-file",
+		"+ k S 4 key1",
+		"+ n test",
+		"+ s aerotest",
+		"+ g 1",
+		"+ t 0",
+		"+ b 2",
+		"- I count 10",
+		"- S name 8 John Doe",
+		"+ k I 2",
+		"+ n test",
+		"+ s aerotest",
+		"+ g 5",
+		"+ t 0",
+		"+ b 1",
+		"- L list 8 kgGiA2E=",
+		"+ k S 4 key3",
+		"+ n test",
+		"+ s aerotest",
+		"+ g 1",
+		"+ t 100",
+		"+ b 1",
+		"- I count 1",
+		"",
+	}, "\n")
+
+	BeforeEach(func() {
+		var err error
+		client, srv, err = aerotest.NewClientAndServer("test", "copy")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		client.Close()
+		Expect(srv.Close()).ToNot(HaveOccurred())
+	})
+
+	It("must restore records from asb files", func() {
+		stats, err := client.Restore(nil, strings.NewReader(backup))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stats.Records).To(Equal(int64(2)))
+		Expect(stats.Expired).To(Equal(int64(1)))
+		Expect(srv.Len("test")).To(Equal(2))
+
+		key, _ := NewKey("test", "aerotest", "key1")
+		rec, err := client.Get(nil, key)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rec.Bins).To(Equal(BinMap{"count": 10, "name": "John Doe"}))
+
+		key, _ = NewKey("test", "aerotest", 2)
+		rec, err = client.Get(nil, key)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rec.Bins).To(Equal(BinMap{"list": []interface{}{1, "a"}}))
+	})
+
+	It("must not overwrite records with the same or a newer generation", func() {
+		_, err := client.Restore(nil, strings.NewReader(backup))
+		Expect(err).ToNot(HaveOccurred())
+
+		// both records have generation 1 on the server now; key1 has generation 1
+		// in the backup and is skipped, key2 has generation 5 and is overwritten
+		stats, err := client.Restore(nil, strings.NewReader(backup))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stats.Records).To(Equal(int64(1)))
+		Expect(stats.Skipped).To(Equal(int64(1)))
+
+		policy := NewRestorePolicy()
+		policy.IgnoreGeneration = true
+		stats, err = client.Restore(policy, strings.NewReader(backup))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stats.Records).To(Equal(int64(2)))
+	})
+
+	It("must restore records to another namespace", func() {
+		policy := NewRestorePolicy()
+		policy.Namespace = "copy"
+
+		stats, err := client.Restore(policy, strings.NewReader(backup))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stats.Records).To(Equal(int64(2)))
+		Expect(srv.Len("test")).To(Equal(0))
+		Expect(srv.Len("copy")).To(Equal(2))
+	})
+
+	It("must stop on invalid files", func() {
+		_, err := client.Restore(nil, strings.NewReader("Version 3.1\n+ n test\n+ b x\n"))
+		Expect(err).To(HaveOccurred())
+	})
+
+})
@@ -31,3 +31,102 @@ const (
 	// Read allow read transactions with the database.
 	Read Role = "Read"
 )
+
+// PrivilegeCode is the permission granted by a privilege.
+type PrivilegeCode string
+
+const (
+	// PrivilegeUserAdmin allows to manage users and their roles.
+	PrivilegeUserAdmin PrivilegeCode = "user-admin"
+
+	// PrivilegeSysAdmin allows to manage indexes, user defined functions and server configuration.
+	PrivilegeSysAdmin PrivilegeCode = "sys-admin"
+
+	// PrivilegeDataAdmin allows to manage indexes and user defined functions.
+	PrivilegeDataAdmin PrivilegeCode = "data-admin"
+
+	// PrivilegeUDFAdmin allows to manage user defined functions.
+	PrivilegeUDFAdmin PrivilegeCode = "udf-admin"
+
+	// PrivilegeSIndexAdmin allows to manage secondary indexes.
+	PrivilegeSIndexAdmin PrivilegeCode = "sindex-admin"
+
+	// PrivilegeRead allows to read data.
+	PrivilegeRead PrivilegeCode = "read"
+
+	// PrivilegeReadWrite allows to read and write data.
+	PrivilegeReadWrite PrivilegeCode = "read-write"
+
+	// PrivilegeReadWriteUDF allows to read and write data through user defined functions.
+	PrivilegeReadWriteUDF PrivilegeCode = "read-write-udf"
+
+	// PrivilegeWrite allows to write data.
+	PrivilegeWrite PrivilegeCode = "write"
+
+	// PrivilegeTruncate allows to truncate data.
+	PrivilegeTruncate PrivilegeCode = "truncate"
+)
+
+// privilegeIDs maps privilege codes to their ids in the admin protocol.
+var privilegeIDs = map[PrivilegeCode]byte{
+	PrivilegeUserAdmin:    0,
+	PrivilegeSysAdmin:     1,
+	PrivilegeDataAdmin:    2,
+	PrivilegeUDFAdmin:     3,
+	PrivilegeSIndexAdmin:  4,
+	PrivilegeRead:         10,
+	PrivilegeReadWrite:    11,
+	PrivilegeReadWriteUDF: 12,
+	PrivilegeWrite:        13,
+	PrivilegeTruncate:     14,
+}
+
+// privilegeCodes maps privilege ids in the admin protocol to their codes.
+var privilegeCodes = func() map[byte]PrivilegeCode {
+	res := make(map[byte]PrivilegeCode, len(privilegeIDs))
+	for code, id := range privilegeIDs {
+		res[id] = code
+	}
+	return res
+}()
+
+// canScope returns true if the privilege can be limited to a namespace and set.
+// Only data privileges can be scoped.
+func (pc PrivilegeCode) canScope() bool {
+	return privilegeIDs[pc] >= privilegeIDs[PrivilegeRead]
+}
+
+// Privilege is a permission granted to a role, optionally limited to a
+// namespace, or a set of a namespace.
+type Privilege struct {
+	// Code is the permission granted.
+	Code PrivilegeCode
+
+	// Namespace limits the privilege to a namespace. Empty means all namespaces.
+	// Only data privileges can be limited to a namespace.
+	Namespace string
+
+	// SetName limits the privilege to a set of the namespace. Empty means all sets.
+	SetName string
+}
+
+// RoleInfo contains information about a role.
+type RoleInfo struct {
+	// Name is the role name.
+	Name string
+
+	// Privileges granted to the role.
+	Privileges []Privilege
+
+	// Whitelist is the list of IP addresses the role's users may connect from.
+	// Empty means all addresses.
+	Whitelist []string
+
+	// ReadQuota is the maximum number of reads per second allowed for the
+	// role's users. Zero means no limit.
+	ReadQuota uint32
+
+	// WriteQuota is the maximum number of writes per second allowed for the
+	// role's users. Zero means no limit.
+	WriteQuota uint32
+}
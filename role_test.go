@@ -0,0 +1,74 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	. "github.com/THE108/aerospike-client-go/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Role Admin Protocol Test", func() {
+
+	role := &RoleInfo{
+		Name: "analyst",
+		Privileges: []Privilege{
+			{Code: PrivilegeSIndexAdmin},
+			{Code: PrivilegeRead, Namespace: "test"},
+			{Code: PrivilegeReadWrite, Namespace: "test", SetName: "events"},
+		},
+		Whitelist:  []string{"10.0.0.1", "10.0.0.0/24"},
+		ReadQuota:  100,
+		WriteQuota: 20,
+	}
+
+	It("should parse the role fields it writes", func() {
+		acmd := &AdminCommand{dataBuffer: make([]byte, 1024)}
+		acmd.writeHeader(_QUERY_ROLES, 5)
+		acmd.writeFieldStr(_ROLE, role.Name)
+		Expect(acmd.writePrivileges(role.Privileges)).ToNot(HaveOccurred())
+		acmd.writeWhitelist(role.Whitelist)
+		acmd.writeFieldUint32(_READ_QUOTA, role.ReadQuota)
+		acmd.writeFieldUint32(_WRITE_QUOTA, role.WriteQuota)
+
+		status, roles, err := acmd.parseRoleBlocks(acmd.dataOffset)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(status).To(Equal(0))
+		Expect(roles).To(Equal([]*RoleInfo{role}))
+	})
+
+	It("should stop at the end of the query", func() {
+		acmd := &AdminCommand{dataBuffer: make([]byte, 1024)}
+		acmd.writeHeader(_QUERY_ROLES, 0)
+		acmd.dataBuffer[1] = byte(_QUERY_END)
+
+		status, roles, err := acmd.parseRoleBlocks(acmd.dataOffset)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(status).To(Equal(-1))
+		Expect(roles).To(BeNil())
+	})
+
+	It("should reject unknown and wrongly scoped privileges", func() {
+		acmd := &AdminCommand{dataBuffer: make([]byte, 1024)}
+		err := acmd.writePrivileges([]Privilege{{Code: "admin"}})
+		Expect(err).To(HaveOccurred())
+		Expect(err.(AerospikeError).ResultCode()).To(Equal(INVALID_PRIVILEGE))
+
+		err = acmd.writePrivileges([]Privilege{{Code: PrivilegeSysAdmin, Namespace: "test"}})
+		Expect(err).To(HaveOccurred())
+	})
+
+})
@@ -0,0 +1,98 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"strings"
+
+	. "github.com/THE108/aerospike-client-go/types"
+)
+
+// Roster contains the roster of a namespace in strong consistency mode.
+// Node entries are reported as-is by the server, e.g. `BB9020011AC4202`
+// or `BB9020011AC4202@1` for a node in rack 1.
+type Roster struct {
+	// Namespace is the name of the namespace.
+	Namespace string
+
+	// Nodes is the roster in effect.
+	Nodes []string
+
+	// PendingNodes is the roster set on the node, which is applied on the next recluster.
+	PendingNodes []string
+
+	// ObservedNodes are the nodes in the cluster currently holding the namespace.
+	ObservedNodes []string
+}
+
+// GetRoster retrieves the roster of a strong consistency namespace from the specified node.
+// If the node is nil, a random node of the cluster will be used.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *Client) GetRoster(policy *InfoPolicy, node *Node, namespace string) (*Roster, error) {
+	policy = clnt.getUsableInfoPolicy(policy)
+
+	if node == nil {
+		var err error
+		if node, err = clnt.cluster.GetRandomNode(); err != nil {
+			return nil, err
+		}
+	}
+
+	command := "roster:namespace=" + namespace
+	infoMap, err := RequestNodeInfoWithPolicy(policy, node, command)
+	if err != nil {
+		return nil, err
+	}
+
+	response := infoMap[command]
+	if response == "" || strings.HasPrefix(strings.ToLower(response), "error") {
+		return nil, NewAerospikeError(INVALID_NAMESPACE, "Failed to get roster for namespace `"+namespace+"`: "+response)
+	}
+
+	return parseRoster(namespace, response)
+}
+
+// parseRoster parses `roster=A,B:pending_roster=A,B:observed_nodes=A,B`.
+// Empty lists are reported as `null`.
+func parseRoster(namespace string, response string) (*Roster, error) {
+	res := &Roster{Namespace: namespace}
+	found := false
+
+	for _, param := range strings.Split(strings.TrimSpace(response), ":") {
+		kv := strings.SplitN(param, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+
+		var nodes []string
+		if v := strings.TrimSpace(kv[1]); v != "" && v != "null" {
+			nodes = strings.Split(v, ",")
+		}
+
+		switch kv[0] {
+		case "roster":
+			res.Nodes, found = nodes, true
+		case "pending_roster":
+			res.PendingNodes = nodes
+		case "observed_nodes":
+			res.ObservedNodes = nodes
+		}
+	}
+
+	if !found {
+		return nil, NewAerospikeError(PARSE_ERROR, "Invalid roster for namespace `"+namespace+"`: "+response)
+	}
+	return res, nil
+}
@@ -26,6 +26,7 @@ type scanCommand struct {
 	namespace string
 	setName   string
 	binNames  []string
+	taskId    uint64
 }
 
 func newScanCommand(
@@ -34,6 +35,7 @@ func newScanCommand(
 	namespace string,
 	setName string,
 	binNames []string,
+	taskId uint64,
 	recordset *Recordset,
 ) *scanCommand {
 	return &scanCommand{
@@ -42,6 +44,7 @@ func newScanCommand(
 		namespace:        namespace,
 		setName:          setName,
 		binNames:         binNames,
+		taskId:           taskId,
 	}
 }
 
@@ -50,7 +53,7 @@ func (cmd *scanCommand) getPolicy(ifc command) Policy {
 }
 
 func (cmd *scanCommand) writeBuffer(ifc command) error {
-	return cmd.setScan(cmd.policy, &cmd.namespace, &cmd.setName, cmd.binNames)
+	return cmd.setScan(cmd.policy, &cmd.namespace, &cmd.setName, cmd.binNames, cmd.taskId)
 }
 
 func (cmd *scanCommand) parseRecordResults(ifc command, receiveSize int) (bool, error) {
@@ -134,13 +137,9 @@ func (cmd *scanCommand) parseRecordResults(ifc command, receiveSize int) (bool,
 			bins[name] = value
 		}
 
-		// If the channel is full and it blocks, we don't want this command to
-		// block forever, or panic in case the channel is closed in the meantime.
-		select {
 		// send back the result on the async channel
-		case cmd.recordset.Records <- newRecord(cmd.node, key, bins, generation, expiration):
-		case <-cmd.recordset.cancelled:
-			return false, NewAerospikeError(SCAN_TERMINATED)
+		if err := cmd.sendRecord(newRecord(cmd.node, key, bins, generation, expiration)); err != nil {
+			return false, err
 		}
 	}
 
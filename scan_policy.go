@@ -26,11 +26,33 @@ type ScanPolicy struct {
 	// ConcurrentNodes determines how to issue scan requests (in parallel or sequentially).
 	ConcurrentNodes bool //= true;
 
+	// MaxConcurrentNodes limits the number of nodes scanned at the same time
+	// if ConcurrentNodes is set; once the scan of a node is over, the scan of
+	// the next one starts. 1 scans the nodes sequentially, 0 scans all nodes
+	// in parallel.
+	MaxConcurrentNodes int //= 0;
+
+	// SocketsPerNode determines the number of scan commands run in parallel on
+	// each node, each on its own connection. Every command scans a slice of the
+	// records, selected by a digest modulo predicate added to PredExp, so the
+	// server must support predicate expressions. Values below 2 scan each node
+	// with a single command.
+	SocketsPerNode int //= 1;
+
 	// Indicates if bin data is retrieved. If false, only record digests are retrieved.
 	IncludeBinData bool //= true;
 
 	// FailOnClusterChange determines scan termination if cluster is in fluctuating state.
+	// The cluster key of the nodes is also verified by the client before and
+	// after the scan; if it changed, the recordset ends with a
+	// CLUSTER_KEY_MISMATCH error, since records may have been returned twice
+	// or missed.
 	FailOnClusterChange bool
+
+	// PredExp determines additional predicates evaluated on the server for
+	// each scanned record, eg: NewPredExpLastUpdateRange for incremental
+	// scans. See PredExp for details. (Optional)
+	PredExp []PredExp
 }
 
 // NewScanPolicy creates a new ScanPolicy instance with default values.
@@ -39,6 +61,7 @@ func NewScanPolicy() *ScanPolicy {
 		MultiPolicy:         NewMultiPolicy(),
 		ScanPercent:         100,
 		ConcurrentNodes:     true,
+		SocketsPerNode:      1,
 		IncludeBinData:      true,
 		FailOnClusterChange: true,
 	}
@@ -47,3 +70,49 @@ func NewScanPolicy() *ScanPolicy {
 
 	return res
 }
+
+// Clone returns a deep copy of the policy, including its MultiPolicy.
+func (p *ScanPolicy) Clone() *ScanPolicy {
+	res := *p
+	if p.MultiPolicy != nil {
+		res.MultiPolicy = p.MultiPolicy.Clone()
+	}
+	if p.PredExp != nil {
+		res.PredExp = append([]PredExp(nil), p.PredExp...)
+	}
+	return &res
+}
+
+// maxConcurrentNodes returns the number of nodes scanned at the same time.
+func (p *ScanPolicy) maxConcurrentNodes(nodes int) int {
+	switch {
+	case !p.ConcurrentNodes:
+		return 1
+	case p.MaxConcurrentNodes > 0 && p.MaxConcurrentNodes < nodes:
+		return p.MaxConcurrentNodes
+	}
+	return nodes
+}
+
+// socketsPerNode returns the number of scan commands run on each node.
+func (p *ScanPolicy) socketsPerNode() int {
+	if p.SocketsPerNode < 2 {
+		return 1
+	}
+	return p.SocketsPerNode
+}
+
+// slice returns a copy of the policy which only scans the records whose
+// digest modulo slices is slice.
+func (p *ScanPolicy) slice(slice, slices int) *ScanPolicy {
+	res := p.Clone()
+	res.PredExp = append(res.PredExp,
+		NewPredExpRecDigestModulo(int32(slices)),
+		NewPredExpIntegerValue(int64(slice)),
+		NewPredExpIntegerEqual(),
+	)
+	if len(p.PredExp) > 0 {
+		res.PredExp = append(res.PredExp, NewPredExpAnd(2))
+	}
+	return res
+}
@@ -15,8 +15,11 @@
 package aerospike_test
 
 import (
+	"bytes"
 	"math"
 	"math/rand"
+	"strings"
+	"time"
 
 	. "github.com/THE108/aerospike-client-go"
 
@@ -148,6 +151,21 @@ var _ = Describe("Scan operations", func() {
 		Expect(len(keys)).To(Equal(0))
 	})
 
+	It("must Scan and get all records back with limited node and per node parallelism", func() {
+		Expect(len(keys)).To(Equal(keyCount))
+
+		scanPolicy := NewScanPolicy()
+		scanPolicy.MaxConcurrentNodes = 1
+		scanPolicy.SocketsPerNode = 4
+
+		recordset, err := client.ScanAll(scanPolicy, ns, set)
+		Expect(err).ToNot(HaveOccurred())
+
+		checkResults(recordset, 0)
+
+		Expect(len(keys)).To(Equal(0))
+	})
+
 	It("must Cancel Scan", func() {
 		Expect(len(keys)).To(Equal(keyCount))
 
@@ -159,4 +177,62 @@ var _ = Describe("Scan operations", func() {
 		Expect(len(keys)).To(BeNumerically("<=", keyCount/2))
 	})
 
+	It("must Backup all records of a set", func() {
+		Expect(len(keys)).To(Equal(keyCount))
+
+		var buf bytes.Buffer
+		stats, err := client.Backup(nil, &buf, ns, set)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stats.Records).To(Equal(int64(keyCount)))
+		Expect(stats.Bytes).To(Equal(int64(buf.Len())))
+
+		Expect(strings.HasPrefix(buf.String(), "Version 3.1\n# namespace test\n")).To(BeTrue())
+		Expect(strings.Count(buf.String(), "\n+ n test\n")).To(Equal(keyCount))
+	})
+
+	It("must Watch a set and report changed records", func() {
+		policy := NewChangeWatchPolicy()
+		policy.PollInterval = 100 * time.Millisecond
+
+		watcher, err := client.WatchSet(policy, ns, set)
+		Expect(err).ToNot(HaveOccurred())
+		defer watcher.Close()
+
+		// wait for the first poll to finish
+		time.Sleep(time.Second)
+
+		var key *Key
+		for _, k := range keys {
+			key = k
+			break
+		}
+		err = client.PutBins(wpolicy, key, bin1)
+		Expect(err).ToNot(HaveOccurred())
+
+		event := <-watcher.Events()
+		Expect(event.Err).ToNot(HaveOccurred())
+		Expect(event.Type).To(Equal(RECORD_UPDATED))
+		Expect(event.Key.Digest()).To(Equal(key.Digest()))
+	})
+
+	It("must Audit the TTL of all records of a set", func() {
+		// the records of the set never expire; make one of them expire in an hour
+		var key *Key
+		for _, k := range keys {
+			key = k
+			break
+		}
+		err := client.PutBins(NewWritePolicy(0, 3600), key, bin1)
+		Expect(err).ToNot(HaveOccurred())
+
+		reports, err := client.AuditTTL(nil, ns, set)
+		Expect(err).ToNot(HaveOccurred())
+
+		report := reports[set]
+		Expect(report.Records).To(Equal(int64(keyCount)))
+		Expect(report.NeverExpire).To(Equal(int64(keyCount - 1)))
+		Expect(report.Expiring).To(Equal(int64(1)))
+		Expect(report.Buckets[0]).To(Equal(int64(1)))
+	})
+
 })
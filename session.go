@@ -0,0 +1,120 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"time"
+
+	. "github.com/THE108/aerospike-client-go/types"
+)
+
+// sessionRenewMargin is how long before the server expires a session token
+// the client stops using it for new connections and logs in again.
+const sessionRenewMargin = time.Minute
+
+// isSessionExpired returns true if the error is the server rejecting an
+// expired session token.
+func isSessionExpired(err error) bool {
+	ae, ok := err.(AerospikeError)
+	return ok && ae.ResultCode() == EXPIRED_SESSION
+}
+
+// session returns the current session token, if it has not expired.
+func (clstr *Cluster) session() []byte {
+	clstr.sessionMutex.Lock()
+	defer clstr.sessionMutex.Unlock()
+
+	if clstr.sessionToken == nil {
+		return nil
+	}
+	if !clstr.sessionExpiration.IsZero() && !time.Now().Before(clstr.sessionExpiration) {
+		clstr.sessionToken = nil
+		return nil
+	}
+	return clstr.sessionToken
+}
+
+// setSession stores the session token received from the server.
+// The token is renewed before the server expires it.
+func (clstr *Cluster) setSession(token []byte, ttl time.Duration) {
+	var expiration time.Time
+	if ttl > 0 {
+		margin := sessionRenewMargin
+		if ttl < 2*margin {
+			margin = ttl / 2
+		}
+		expiration = time.Now().Add(ttl - margin)
+	}
+
+	clstr.sessionMutex.Lock()
+	clstr.sessionToken = token
+	clstr.sessionExpiration = expiration
+	clstr.sessionMutex.Unlock()
+}
+
+// expireSession drops the session token, if it is still the given one,
+// so the next connection logs in again.
+func (clstr *Cluster) expireSession(token []byte) {
+	clstr.sessionMutex.Lock()
+	if token == nil || string(token) == string(clstr.sessionToken) {
+		clstr.sessionToken = nil
+	}
+	clstr.sessionMutex.Unlock()
+}
+
+// authenticate authenticates a new connection if security is enabled.
+// The session token of a previous login is used if there is one; expired
+// sessions are renewed by logging in again. Servers which do not support
+// sessions are authenticated with the user's credentials in internal mode.
+func (clstr *Cluster) authenticate(conn *Connection) error {
+	mode := clstr.policy().AuthMode
+	if clstr.user == "" && mode != AuthModePKI {
+		return nil
+	}
+
+	if token := clstr.session(); token != nil {
+		err := newAdminCommand().authenticateSession(conn, clstr.user, token)
+		if !isSessionExpired(err) {
+			return err
+		}
+
+		// the server expired the session before the client did
+		clstr.expireSession(token)
+	}
+
+	if clstr.sessionUnsupported.Get() {
+		return conn.Authenticate(clstr.user, clstr.Password())
+	}
+
+	token, ttl, err := newAdminCommand().login(conn, mode, clstr.user, clstr.Password(), clstr.clearPassword())
+	if err != nil {
+		if ae, ok := err.(AerospikeError); ok && ae.ResultCode() == INVALID_COMMAND && mode == AuthModeInternal {
+			// old servers do not support login sessions
+			clstr.sessionUnsupported.Set(true)
+			return conn.Authenticate(clstr.user, clstr.Password())
+		}
+		return err
+	}
+
+	if token != nil {
+		clstr.setSession(token, ttl)
+	}
+	return nil
+}
+
+// clearPassword returns the clear text password, sent for external authentication.
+func (clstr *Cluster) clearPassword() string {
+	return clstr.policy().Password
+}
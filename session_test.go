@@ -0,0 +1,237 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"crypto/tls"
+	"io"
+	"net"
+	"time"
+
+	. "github.com/THE108/aerospike-client-go/types"
+	Buffer "github.com/THE108/aerospike-client-go/utils/buffer"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// testAdminHandler answers an admin command with a result code and fields.
+type testAdminHandler func(command byte, fields map[byte][]byte) (ResultCode, map[byte][]byte)
+
+// serveTestAdmin answers the admin commands sent on conn until it is closed.
+func serveTestAdmin(conn net.Conn, handler testAdminHandler) {
+	defer conn.Close()
+
+	header := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(conn, header); err != nil {
+			return
+		}
+		msg := make([]byte, Buffer.BytesToInt64(header, 0)&0xFFFFFFFFFFFF)
+		if _, err := io.ReadFull(conn, msg); err != nil {
+			return
+		}
+
+		fields := map[byte][]byte{}
+		for i, offset := 0, 16; i < int(msg[3]); i++ {
+			length := int(Buffer.BytesToUint32(msg, offset)) - 1
+			fields[msg[offset+4]] = msg[offset+5 : offset+5+length]
+			offset += 5 + length
+		}
+
+		result, respFields := handler(msg[2], fields)
+
+		resp := make([]byte, 24)
+		resp[9] = byte(result)
+		resp[11] = byte(len(respFields))
+		for id, value := range respFields {
+			field := make([]byte, 5)
+			Buffer.Int32ToBytes(int32(len(value)+1), field, 0)
+			field[4] = id
+			resp = append(append(resp, field...), value...)
+		}
+		Buffer.Int64ToBytes(int64(len(resp)-8)|(_MSG_VERSION<<56)|(_MSG_TYPE<<48), resp, 0)
+
+		if _, err := conn.Write(resp); err != nil {
+			return
+		}
+	}
+}
+
+var _ = Describe("Login Session Test", func() {
+
+	var cluster *Cluster
+	var logins, tokenAuths, passwordAuths int
+	var handler testAdminHandler
+
+	ttl := func(seconds uint32) []byte {
+		b := make([]byte, 4)
+		Buffer.Int32ToBytes(int32(seconds), b, 0)
+		return b
+	}
+
+	authenticate := func() error {
+		client, server := net.Pipe()
+		go serveTestAdmin(server, handler)
+		defer client.Close()
+
+		return cluster.authenticate(&Connection{conn: client})
+	}
+
+	BeforeEach(func() {
+		cluster = &Cluster{user: "user", password: []byte("hash")}
+		logins, tokenAuths, passwordAuths = 0, 0, 0
+
+		handler = func(command byte, fields map[byte][]byte) (ResultCode, map[byte][]byte) {
+			Expect(string(fields[_USER])).To(Equal("user"))
+
+			switch {
+			case command == _LOGIN:
+				logins++
+				Expect(string(fields[_CREDENTIAL])).To(Equal("hash"))
+				return OK, map[byte][]byte{_SESSION_TOKEN: []byte("token"), _SESSION_TTL: ttl(3600)}
+			case fields[_SESSION_TOKEN] != nil:
+				tokenAuths++
+				Expect(string(fields[_SESSION_TOKEN])).To(Equal("token"))
+				return OK, nil
+			default:
+				passwordAuths++
+				return OK, nil
+			}
+		}
+	})
+
+	It("should log in once and authenticate new connections with the session token", func() {
+		Expect(authenticate()).ToNot(HaveOccurred())
+		Expect(authenticate()).ToNot(HaveOccurred())
+		Expect(authenticate()).ToNot(HaveOccurred())
+
+		Expect(logins).To(Equal(1))
+		Expect(tokenAuths).To(Equal(2))
+		Expect(cluster.session()).To(Equal([]byte("token")))
+		Expect(cluster.sessionExpiration.After(time.Now().Add(58 * time.Minute))).To(BeTrue())
+	})
+
+	It("should log in again when the session has expired", func() {
+		Expect(authenticate()).ToNot(HaveOccurred())
+
+		// expired on the client
+		cluster.sessionExpiration = time.Now().Add(-time.Second)
+		Expect(authenticate()).ToNot(HaveOccurred())
+		Expect(logins).To(Equal(2))
+		Expect(tokenAuths).To(Equal(0))
+
+		// expired on the server
+		login := handler
+		handler = func(command byte, fields map[byte][]byte) (ResultCode, map[byte][]byte) {
+			if fields[_SESSION_TOKEN] != nil {
+				return EXPIRED_SESSION, nil
+			}
+			return login(command, fields)
+		}
+		Expect(authenticate()).ToNot(HaveOccurred())
+		Expect(logins).To(Equal(3))
+	})
+
+	It("should fall back to password authentication on servers without sessions", func() {
+		auth := handler
+		handler = func(command byte, fields map[byte][]byte) (ResultCode, map[byte][]byte) {
+			if command == _LOGIN {
+				return INVALID_COMMAND, nil
+			}
+			return auth(command, fields)
+		}
+
+		Expect(authenticate()).ToNot(HaveOccurred())
+		Expect(authenticate()).ToNot(HaveOccurred())
+		Expect(passwordAuths).To(Equal(2))
+		Expect(cluster.session()).To(BeNil())
+	})
+
+	It("should return login errors", func() {
+		handler = func(command byte, fields map[byte][]byte) (ResultCode, map[byte][]byte) {
+			return INVALID_CREDENTIAL, nil
+		}
+
+		err := authenticate()
+		Expect(err).To(HaveOccurred())
+		Expect(err.(AerospikeError).ResultCode()).To(Equal(INVALID_CREDENTIAL))
+	})
+
+	It("should send the clear text password for external authentication", func() {
+		cluster.setPolicy(&ClientPolicy{AuthMode: AuthModeExternal, Password: "secret"})
+
+		login := handler
+		handler = func(command byte, fields map[byte][]byte) (ResultCode, map[byte][]byte) {
+			if command == _LOGIN {
+				Expect(string(fields[_CLEAR_PASSWORD])).To(Equal("secret"))
+			}
+			return login(command, fields)
+		}
+
+		Expect(authenticate()).ToNot(HaveOccurred())
+		Expect(logins).To(Equal(1))
+	})
+
+	It("should log in without credentials for PKI authentication", func() {
+		cluster = &Cluster{}
+		cluster.setPolicy(&ClientPolicy{AuthMode: AuthModePKI})
+
+		var fields map[byte][]byte
+		handler = func(command byte, f map[byte][]byte) (ResultCode, map[byte][]byte) {
+			fields = f
+			if command == _LOGIN {
+				return OK, map[byte][]byte{_SESSION_TOKEN: []byte("token")}
+			}
+			return OK, nil
+		}
+
+		Expect(authenticate()).ToNot(HaveOccurred())
+		Expect(fields).To(BeEmpty())
+
+		Expect(authenticate()).ToNot(HaveOccurred())
+		Expect(fields).To(Equal(map[byte][]byte{_SESSION_TOKEN: []byte("token")}))
+	})
+
+	It("should require TLS for external and PKI authentication", func() {
+		policy := NewClientPolicy()
+		policy.AuthMode = AuthModeExternal
+		Expect(policy.validateAuthMode()).To(HaveOccurred())
+
+		policy.TLSConfig = &tls.Config{}
+		Expect(policy.validateAuthMode()).ToNot(HaveOccurred())
+
+		policy.AuthMode = AuthModePKI
+		Expect(policy.validateAuthMode()).To(HaveOccurred())
+		Expect(policy.RequiresAuthentication()).To(BeTrue())
+
+		policy.TLSConfig.Certificates = []tls.Certificate{{}}
+		Expect(policy.validateAuthMode()).ToNot(HaveOccurred())
+
+		_, err := NewCluster(&ClientPolicy{AuthMode: AuthModePKI}, nil)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should renew sessions before the server expires them", func() {
+		cluster.setSession([]byte("token"), 30*time.Second)
+		Expect(cluster.sessionExpiration.Before(time.Now().Add(16 * time.Second))).To(BeTrue())
+
+		cluster.expireSession([]byte("other"))
+		Expect(cluster.session()).To(Equal([]byte("token")))
+		cluster.expireSession([]byte("token"))
+		Expect(cluster.session()).To(BeNil())
+	})
+
+})
@@ -0,0 +1,217 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	. "github.com/THE108/aerospike-client-go/types"
+)
+
+// SetStats holds the statistics of a set on a node, parsed from the
+// `sets/<ns>` info command. Statistics the server does not report are zero.
+type SetStats struct {
+	// Namespace is the name of the namespace of the set.
+	Namespace string
+
+	// SetName is the name of the set.
+	SetName string
+
+	// Objects is the number of records of the set on the node, including replicas.
+	Objects int64 `info:"objects"`
+
+	// Tombstones is the number of tombstones of the set on the node.
+	Tombstones int64 `info:"tombstones"`
+
+	// MemoryDataBytes is the memory used by the records of the set in bytes.
+	MemoryDataBytes int64 `info:"memory_data_bytes"`
+
+	// DeviceDataBytes is the storage used by the records of the set in bytes.
+	DeviceDataBytes int64 `info:"device_data_bytes"`
+
+	// StopWritesCount is the number of records above which writes to the set are refused.
+	StopWritesCount int64 `info:"stop-writes-count"`
+
+	// DisableEviction is true if the records of the set are never evicted.
+	DisableEviction bool `info:"disable-eviction"`
+
+	// Other holds the statistics without a field, like in NodeStats.
+	Other map[string]interface{}
+}
+
+// SetCount holds the number of records of a set in the cluster.
+type SetCount struct {
+	// Namespace is the name of the namespace of the set.
+	Namespace string
+
+	// SetName is the name of the set.
+	SetName string
+
+	// Objects is the number of records of the set in the cluster, not
+	// counting replicas. It is derived from the per node counts and the
+	// replication factor, and is approximate while partitions migrate.
+	Objects int64
+
+	// Nodes holds the statistics of the set by node name.
+	Nodes map[string]*SetStats
+}
+
+// setStatsFields maps statistic names to the index of their SetStats field.
+var setStatsFields = statsFields(reflect.TypeOf(SetStats{}))
+
+// ParseSetStats parses the response of the `sets/<ns>` info command.
+// Servers before 3.9 report the names and counts with the `ns_name`,
+// `set_name` and `n_objects` keys, which are accepted as well.
+func ParseSetStats(response string) []*SetStats {
+	res := []*SetStats{}
+	for _, set := range strings.Split(response, ";") {
+		values := map[string]string{}
+		for _, param := range strings.Split(set, ":") {
+			if kv := strings.SplitN(param, "=", 2); len(kv) == 2 {
+				values[kv[0]] = kv[1]
+			}
+		}
+
+		stats := &SetStats{
+			Namespace: popInfoValue(values, "ns", "ns_name"),
+			SetName:   popInfoValue(values, "set", "set_name"),
+		}
+		if stats.SetName == "" {
+			continue
+		}
+		if v, exists := values["n_objects"]; exists {
+			values["objects"] = v
+			delete(values, "n_objects")
+		}
+
+		stats.Other = parseStats(values, reflect.ValueOf(stats).Elem(), setStatsFields)
+		res = append(res, stats)
+	}
+	return res
+}
+
+// popInfoValue removes the value of the first of the keys which exists, and returns it.
+func popInfoValue(values map[string]string, keys ...string) string {
+	for _, key := range keys {
+		if v, exists := values[key]; exists {
+			delete(values, key)
+			return v
+		}
+	}
+	return ""
+}
+
+// ListSets returns the record counts of the sets of the namespace, sorted
+// by set name, with their statistics on each node of the cluster.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *Client) ListSets(policy *InfoPolicy, namespace string) ([]*SetCount, error) {
+	policy = clnt.getUsableInfoPolicy(policy)
+
+	nodes := clnt.cluster.GetNodes()
+	if len(nodes) == 0 {
+		return nil, NewAerospikeError(SERVER_NOT_AVAILABLE, "ListSets failed because cluster is empty.")
+	}
+
+	sets := map[string]*SetCount{}
+	totals := map[string]int64{}
+	replicationFactor := 0
+	for _, node := range nodes {
+		setsCommand, nsCommand := "sets/"+namespace, "namespace/"+namespace
+		infoMap, err := RequestNodeInfoWithPolicy(policy, node, setsCommand, nsCommand)
+		if err != nil {
+			return nil, err
+		}
+
+		nsResponse := infoMap[nsCommand]
+		if lower := strings.ToLower(nsResponse); strings.HasPrefix(lower, "error") || strings.HasPrefix(lower, "type=unknown") {
+			return nil, NewAerospikeError(INVALID_NAMESPACE, "Failed to list sets of namespace `"+namespace+"`: "+nsResponse)
+		}
+		if rf := parseReplicationFactor(nsResponse); rf > replicationFactor {
+			replicationFactor = rf
+		}
+
+		for _, stats := range ParseSetStats(infoMap[setsCommand]) {
+			set := sets[stats.SetName]
+			if set == nil {
+				set = &SetCount{Namespace: namespace, SetName: stats.SetName, Nodes: map[string]*SetStats{}}
+				sets[stats.SetName] = set
+			}
+			set.Nodes[node.GetName()] = stats
+			totals[stats.SetName] += stats.Objects
+		}
+	}
+
+	// each record is counted once per replica
+	replicationFactor = effectiveReplicationFactor(replicationFactor, len(nodes))
+
+	res := make([]*SetCount, 0, len(sets))
+	for name, set := range sets {
+		set.Objects = totals[name] / int64(replicationFactor)
+		res = append(res, set)
+	}
+	sort.Sort(setCountsByName(res))
+	return res, nil
+}
+
+// CountRecords returns the record count of the set, with its statistics on
+// each node of the cluster. Sets without records on any node have a zero count.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *Client) CountRecords(policy *InfoPolicy, namespace, setName string) (*SetCount, error) {
+	sets, err := clnt.ListSets(policy, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, set := range sets {
+		if set.SetName == setName {
+			return set, nil
+		}
+	}
+	return &SetCount{Namespace: namespace, SetName: setName, Nodes: map[string]*SetStats{}}, nil
+}
+
+// parseReplicationFactor returns the replication factor reported in the
+// response of the `namespace/<ns>` info command, or 0 if it is not reported.
+func parseReplicationFactor(response string) int {
+	params := parseInfoParams(response)
+	for _, name := range []string{"effective_replication_factor", "replication-factor", "repl-factor"} {
+		if rf, err := strconv.Atoi(params[name]); err == nil {
+			return rf
+		}
+	}
+	return 0
+}
+
+// effectiveReplicationFactor returns the number of copies of each record in
+// a cluster of clusterSize nodes. Like the server, it clamps the configured
+// replication factor to the cluster size; 0 means a single copy.
+func effectiveReplicationFactor(rf, clusterSize int) int {
+	if rf > clusterSize {
+		rf = clusterSize
+	}
+	if rf < 1 {
+		return 1
+	}
+	return rf
+}
+
+type setCountsByName []*SetCount
+
+func (s setCountsByName) Len() int           { return len(s) }
+func (s setCountsByName) Less(i, j int) bool { return s[i].SetName < s[j].SetName }
+func (s setCountsByName) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
@@ -0,0 +1,64 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Set Stats Test", func() {
+
+	It("should parse set statistics into typed fields", func() {
+		sets := ParseSetStats("ns=test:set=users:objects=10:tombstones=1:memory_data_bytes=640:stop-writes-count=0:disable-eviction=true:truncate_lut=7;" +
+			"ns=test:set=orders:objects=3:tombstones=0;")
+
+		Expect(len(sets)).To(Equal(2))
+		Expect(sets[0].Namespace).To(Equal("test"))
+		Expect(sets[0].SetName).To(Equal("users"))
+		Expect(sets[0].Objects).To(Equal(int64(10)))
+		Expect(sets[0].Tombstones).To(Equal(int64(1)))
+		Expect(sets[0].MemoryDataBytes).To(Equal(int64(640)))
+		Expect(sets[0].DisableEviction).To(BeTrue())
+		Expect(sets[0].Other).To(Equal(map[string]interface{}{"truncate_lut": int64(7)}))
+		Expect(sets[1].SetName).To(Equal("orders"))
+		Expect(sets[1].Objects).To(Equal(int64(3)))
+
+		Expect(ParseSetStats("")).To(BeEmpty())
+	})
+
+	It("should parse the statistics of old servers", func() {
+		sets := ParseSetStats("ns_name=test:set_name=users:n_objects=5:set-enable-xdr=use-default")
+		Expect(len(sets)).To(Equal(1))
+		Expect(sets[0].Namespace).To(Equal("test"))
+		Expect(sets[0].SetName).To(Equal("users"))
+		Expect(sets[0].Objects).To(Equal(int64(5)))
+		Expect(sets[0].Other).To(Equal(map[string]interface{}{"set-enable-xdr": "use-default"}))
+	})
+
+	It("should parse the replication factor", func() {
+		Expect(parseReplicationFactor("objects=1;effective_replication_factor=2;replication-factor=3")).To(Equal(2))
+		Expect(parseReplicationFactor("objects=1;replication-factor=3")).To(Equal(3))
+		Expect(parseReplicationFactor("objects=1;repl-factor=2")).To(Equal(2))
+		Expect(parseReplicationFactor("objects=1")).To(Equal(0))
+	})
+
+	It("should clamp the replication factor to the cluster size", func() {
+		Expect(effectiveReplicationFactor(2, 1)).To(Equal(1))
+		Expect(effectiveReplicationFactor(2, 3)).To(Equal(2))
+		Expect(effectiveReplicationFactor(0, 3)).To(Equal(1))
+	})
+
+})
@@ -0,0 +1,81 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike_test
+
+import (
+	. "github.com/THE108/aerospike-client-go"
+	"github.com/THE108/aerospike-client-go/aerotest"
+	. "github.com/THE108/aerospike-client-go/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Set enumeration", func() {
+
+	var srv *aerotest.Server
+	var client *Client
+
+	BeforeEach(func() {
+		var err error
+		client, srv, err = aerotest.NewClientAndServer("test")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		client.Close()
+		Expect(srv.Close()).ToNot(HaveOccurred())
+	})
+
+	It("must list the sets with their record counts", func() {
+		put := func(setName string, value int) {
+			key, err := NewKey("test", setName, value)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(client.Put(nil, key, BinMap{"n": value})).ToNot(HaveOccurred())
+		}
+		for i := 0; i < 3; i++ {
+			put("users", i)
+		}
+		put("orders", 1)
+
+		// the server reports a replication factor of 2, more than its single node
+		sets, err := client.ListSets(nil, "test")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(len(sets)).To(Equal(2))
+		Expect(sets[0].SetName).To(Equal("orders"))
+		Expect(sets[0].Objects).To(Equal(int64(1)))
+		Expect(sets[1].SetName).To(Equal("users"))
+		Expect(sets[1].Objects).To(Equal(int64(3)))
+
+		node := client.GetNodes()[0]
+		Expect(sets[1].Nodes[node.GetName()].Objects).To(Equal(int64(3)))
+
+		count, err := client.CountRecords(nil, "test", "users")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(count.Objects).To(Equal(int64(3)))
+
+		count, err = client.CountRecords(nil, "test", "missing")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(count.Objects).To(Equal(int64(0)))
+		Expect(count.Nodes).To(BeEmpty())
+	})
+
+	It("must report unknown namespaces", func() {
+		_, err := client.ListSets(nil, "unknown")
+		Expect(err).To(HaveOccurred())
+		Expect(err.(AerospikeError).ResultCode()).To(Equal(INVALID_NAMESPACE))
+	})
+
+})
@@ -24,6 +24,16 @@ type singleCommand struct {
 	cluster   *Cluster
 	key       *Key
 	partition *Partition
+
+	// number of nodes chosen for the command so far
+	attempts int
+
+	// node of the first attempt instead of the master, for hedged reads
+	firstNode *Node
+
+	// transaction fields of the response, see parseTxnFields
+	recordVersion *uint64
+	txnDeadline   int32
 }
 
 func newSingleCommand(cluster *Cluster, key *Key) *singleCommand {
@@ -36,7 +46,38 @@ func newSingleCommand(cluster *Cluster, key *Key) *singleCommand {
 }
 
 func (cmd *singleCommand) getNode(ifc command) (*Node, error) {
-	return cmd.cluster.GetNode(cmd.partition)
+	if cmd.attempts == 0 && cmd.firstNode != nil && cmd.firstNode.IsActive() {
+		cmd.attempts++
+		return cmd.firstNode, nil
+	}
+
+	// reads may start on a replica chosen by latency
+	if cmd.attempts == 0 && cmd.firstNode == nil {
+		if policy := ifc.getPolicy(ifc).GetBasePolicy(); policy.LatencyAwareReads && policy.Txn == nil && !isWriteCommand(ifc) {
+			if node := cmd.cluster.latencyAwareNode(cmd.partition); node != nil {
+				cmd.attempts++
+				return node, nil
+			}
+		}
+	}
+
+	node, err := cmd.cluster.GetNode(cmd.partition)
+
+	// retries are sent to the node chosen by the selector, if there is one
+	if cmd.attempts++; cmd.attempts > 1 {
+		if selector := cmd.cluster.policy().NodeSelector; selector != nil {
+			return cmd.cluster.selectNode(selector, node, cmd.node)
+		}
+	}
+	return node, err
+}
+
+func (cmd *singleCommand) getCluster() *Cluster {
+	return cmd.cluster
+}
+
+func (cmd *singleCommand) getPartition() *Partition {
+	return cmd.partition
 }
 
 func (cmd *singleCommand) emptySocket(conn *Connection) error {
@@ -44,6 +85,7 @@ func (cmd *singleCommand) emptySocket(conn *Connection) error {
 	// Empty the socket to be safe.
 	sz := Buffer.BytesToInt64(cmd.dataBuffer, 0)
 	headerLength := cmd.dataBuffer[8]
+	fieldCount := int(Buffer.BytesToUint16(cmd.dataBuffer, 26))
 	receiveSize := int(sz&0xFFFFFFFFFFFF) - int(headerLength)
 
 	// Read remaining message bytes.
@@ -54,6 +96,7 @@ func (cmd *singleCommand) emptySocket(conn *Connection) error {
 		if _, err := conn.Read(cmd.dataBuffer, receiveSize); err != nil {
 			return err
 		}
+		cmd.parseTxnFields(fieldCount)
 	}
 	return nil
 }
@@ -0,0 +1,56 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"time"
+
+	. "github.com/THE108/aerospike-client-go/logger"
+)
+
+// keyCommand is implemented by commands on a single record.
+type keyCommand interface {
+	getKey() *Key
+}
+
+// commandKey returns the key of single record commands, or nil.
+func commandKey(ifc command) *Key {
+	if kc, ok := ifc.(keyCommand); ok {
+		return kc.getKey()
+	}
+	return nil
+}
+
+// isSlowCommand returns true if the command took longer than
+// ClientPolicy.SlowCommandThreshold.
+func (cp *ClientPolicy) isSlowCommand(duration time.Duration) bool {
+	return cp.SlowCommandThreshold > 0 && duration > cp.SlowCommandThreshold
+}
+
+// reportSlowCommand passes the event to ClientPolicy.SlowCommandHandler,
+// or logs it as a warning if no handler is set.
+func (cp *ClientPolicy) reportSlowCommand(event *CommandEvent) {
+	if cp.SlowCommandHandler != nil {
+		cp.SlowCommandHandler(event)
+		return
+	}
+
+	key := "-"
+	if event.Key != nil {
+		key = event.Key.String()
+	}
+	Logger.Warn("Slow command `%s` on node `%s` took %s in %d attempt(s), key: %s, error: %v",
+		event.Command, event.Node, event.Duration, event.Iterations, key, event.Err)
+}
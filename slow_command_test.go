@@ -0,0 +1,84 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike_test
+
+import (
+	"sync"
+	"time"
+
+	. "github.com/THE108/aerospike-client-go"
+	"github.com/THE108/aerospike-client-go/aerotest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Slow commands", func() {
+
+	var srv *aerotest.Server
+	var policy *ClientPolicy
+	var mutex sync.Mutex
+	var events []*CommandEvent
+
+	BeforeEach(func() {
+		var err error
+		srv, err = aerotest.NewServer("test")
+		Expect(err).ToNot(HaveOccurred())
+
+		events = nil
+		policy = NewClientPolicy()
+		policy.SlowCommandHandler = func(event *CommandEvent) {
+			mutex.Lock()
+			events = append(events, event)
+			mutex.Unlock()
+		}
+	})
+
+	AfterEach(func() {
+		Expect(srv.Close()).ToNot(HaveOccurred())
+	})
+
+	It("must report commands exceeding the threshold with their key", func() {
+		policy.SlowCommandThreshold = time.Nanosecond
+		client, err := NewClientWithPolicy(policy, srv.Host(), srv.Port())
+		Expect(err).ToNot(HaveOccurred())
+		defer client.Close()
+
+		key, _ := NewKey("test", "aerotest", "slow")
+		Expect(client.Put(nil, key, BinMap{"a": 1})).ToNot(HaveOccurred())
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		Expect(len(events)).To(Equal(1))
+		Expect(events[0].Command).To(Equal("write"))
+		Expect(events[0].Key.Digest()).To(Equal(key.Digest()))
+		Expect(events[0].Node).ToNot(BeNil())
+		Expect(events[0].Iterations).To(Equal(1))
+	})
+
+	It("must not report commands without a threshold", func() {
+		client, err := NewClientWithPolicy(policy, srv.Host(), srv.Port())
+		Expect(err).ToNot(HaveOccurred())
+		defer client.Close()
+
+		key, _ := NewKey("test", "aerotest", "slow")
+		Expect(client.Put(nil, key, BinMap{"a": 1})).ToNot(HaveOccurred())
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		Expect(len(events)).To(Equal(0))
+	})
+
+})
@@ -17,6 +17,10 @@ package aerospike
 import xornd "github.com/THE108/aerospike-client-go/types/rand"
 
 // Statement encapsulates query statement parameters.
+//
+// Statements are never modified by the client. A statement can be shared between
+// goroutines, but must not be modified while in use by a query. Note that concurrent
+// queries using the same statement are sent with the same TaskId.
 type Statement struct {
 	// Namespace determines query Namespace
 	Namespace string
@@ -38,6 +42,11 @@ type Statement struct {
 	// aggregation function.
 	Filters []*Filter
 
+	// PredExp determines additional predicates evaluated on the server (Optional)
+	// for each record selected by the index filter, eg: NewPredExpLastUpdateRange
+	// for incremental queries. See PredExp for details.
+	PredExp []PredExp
+
 	packageName  string
 	functionName string
 	functionArgs []Value
@@ -67,6 +76,14 @@ func (stmt *Statement) Addfilter(filter *Filter) error {
 	return nil
 }
 
+// SetPredExp sets the predicate expressions evaluated on the server
+// for each record selected by the statement's filter.
+func (stmt *Statement) SetPredExp(predexp ...PredExp) error {
+	stmt.PredExp = predexp
+
+	return nil
+}
+
 // SetAggregateFunction sets aggregation function parameters.
 // This function will be called on both the server
 // and client for each selected item.
@@ -82,9 +99,11 @@ func (stmt *Statement) IsScan() bool {
 	return stmt.Filters == nil
 }
 
-// Always set the taskId client-side to a non-zero random value
-func (stmt *Statement) setTaskId() {
-	for stmt.TaskId == 0 {
-		stmt.TaskId = uint64(xornd.Int64())
+// newTaskId returns a random non-zero task id for scans and queries.
+func newTaskId() uint64 {
+	for {
+		if taskId := uint64(xornd.Int64()); taskId != 0 {
+			return taskId
+		}
 	}
 }
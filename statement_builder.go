@@ -0,0 +1,265 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"fmt"
+
+	. "github.com/THE108/aerospike-client-go/types"
+	ParticleType "github.com/THE108/aerospike-client-go/types/particle_type"
+)
+
+// Server limits on the names of a statement.
+const (
+	maxNamespaceLen = 31
+	maxSetNameLen   = 63
+	maxBinNameLen   = 15
+	maxIndexNameLen = 255
+	maxQueryBins    = 255
+)
+
+// Param is a placeholder for a filter value of a StatementBuilder, bound to
+// its value when the statement is built.
+type Param string
+
+// Params holds the values of the parameters of a StatementBuilder by name.
+type Params map[string]interface{}
+
+// StatementBuilder builds query statements, validating them against the
+// limits of the server before they are sent. Filter values can be given
+// directly, or as a Param bound when the statement is built, so that a
+// builder can be reused for queries differing only by their values:
+//
+//	byAge := NewStatementBuilder("test", "users").
+//		Bins("name", "age").
+//		Index("users_age", NUMERIC).
+//		Range("age", Param("min"), Param("max"))
+//
+//	stmt, err := byAge.Build(Params{"min": 18, "max": 30})
+//
+// Invalid arguments are reported by Build. A builder must not be modified
+// while it is used by other goroutines; Build can be called concurrently.
+type StatementBuilder struct {
+	namespace string
+	setName   string
+	binNames  []string
+
+	indexName string
+	indexType IndexType
+
+	filter      *filterTemplate
+	filterCount int
+
+	predExp []PredExp
+
+	err error
+}
+
+// filterTemplate is a filter with values possibly given as parameters.
+type filterTemplate struct {
+	binName string
+	idxType IndexCollectionType
+	begin   interface{}
+	end     interface{}
+	isRange bool
+	ctx     []*CDTContext
+}
+
+// NewStatementBuilder returns a builder of statements for the set of the
+// namespace. An empty set name queries the whole namespace.
+func NewStatementBuilder(namespace, setName string) *StatementBuilder {
+	return &StatementBuilder{namespace: namespace, setName: setName}
+}
+
+// Bins sets the names of the bins returned by the query. All bins are
+// returned if none are set.
+func (b *StatementBuilder) Bins(binNames ...string) *StatementBuilder {
+	b.binNames = append([]string(nil), binNames...)
+	return b
+}
+
+// Index sets the name and type of the secondary index used by the filter.
+// The values of the filter are checked against the index type. If the index
+// name is empty, the server uses the index of the filter's bin.
+func (b *StatementBuilder) Index(indexName string, indexType IndexType) *StatementBuilder {
+	b.indexName = indexName
+	b.indexType = indexType
+	return b
+}
+
+// Equal filters the records whose bin equals the value, an integer, string,
+// []byte or Param.
+func (b *StatementBuilder) Equal(binName string, value interface{}, ctx ...*CDTContext) *StatementBuilder {
+	return b.where(&filterTemplate{binName: binName, begin: value, end: value, ctx: ctx})
+}
+
+// Range filters the records whose integer bin is between begin and end
+// inclusive. The bounds are integers or Params.
+func (b *StatementBuilder) Range(binName string, begin, end interface{}, ctx ...*CDTContext) *StatementBuilder {
+	return b.where(&filterTemplate{binName: binName, begin: begin, end: end, isRange: true, ctx: ctx})
+}
+
+// Contains filters the records whose list or map bin contains the value,
+// as with NewContainsFilter.
+func (b *StatementBuilder) Contains(binName string, indexCollectionType IndexCollectionType, value interface{}, ctx ...*CDTContext) *StatementBuilder {
+	return b.where(&filterTemplate{binName: binName, idxType: indexCollectionType, begin: value, end: value, ctx: ctx})
+}
+
+// ContainsRange filters the records whose list or map bin contains an
+// integer between begin and end inclusive, as with NewContainsRangeFilter.
+func (b *StatementBuilder) ContainsRange(binName string, indexCollectionType IndexCollectionType, begin, end interface{}, ctx ...*CDTContext) *StatementBuilder {
+	return b.where(&filterTemplate{binName: binName, idxType: indexCollectionType, begin: begin, end: end, isRange: true, ctx: ctx})
+}
+
+func (b *StatementBuilder) where(filter *filterTemplate) *StatementBuilder {
+	b.filter = filter
+	b.filterCount++
+	return b
+}
+
+// PredExp sets the predicate expressions evaluated on the server for each
+// record selected by the filter.
+func (b *StatementBuilder) PredExp(predexp ...PredExp) *StatementBuilder {
+	b.predExp = append([]PredExp(nil), predexp...)
+	return b
+}
+
+// Build validates the statement, binds the parameters of its filter to
+// their values, and returns a new Statement.
+func (b *StatementBuilder) Build(params Params) (*Statement, error) {
+	if err := b.validate(); err != nil {
+		return nil, err
+	}
+
+	stmt := NewStatement(b.namespace, b.setName, b.binNames...)
+	stmt.IndexName = b.indexName
+	stmt.PredExp = b.predExp
+
+	if b.filter != nil {
+		filter, err := b.bindFilter(params)
+		if err != nil {
+			return nil, err
+		}
+		stmt.Filters = []*Filter{filter}
+	}
+	return stmt, nil
+}
+
+func (b *StatementBuilder) validate() error {
+	switch {
+	case b.namespace == "":
+		return NewAerospikeError(INVALID_NAMESPACE, "Namespace must not be empty")
+	case len(b.namespace) > maxNamespaceLen:
+		return NewAerospikeError(INVALID_NAMESPACE, fmt.Sprintf("Namespace `%s` is longer than %d characters", b.namespace, maxNamespaceLen))
+	case len(b.setName) > maxSetNameLen:
+		return NewAerospikeError(PARAMETER_ERROR, fmt.Sprintf("Set name `%s` is longer than %d characters", b.setName, maxSetNameLen))
+	case len(b.indexName) > maxIndexNameLen:
+		return NewAerospikeError(INDEX_NAME_MAXLEN, fmt.Sprintf("Index name is longer than %d characters", maxIndexNameLen))
+	case len(b.binNames) > maxQueryBins:
+		return NewAerospikeError(PARAMETER_ERROR, fmt.Sprintf("Queries can return at most %d bins", maxQueryBins))
+	case b.filterCount > 1:
+		return NewAerospikeError(PARAMETER_ERROR, "Only one filter is allowed by the server")
+	case b.indexName != "" && b.filter == nil:
+		return NewAerospikeError(PARAMETER_ERROR, "An index is set without a filter")
+	}
+
+	for _, binName := range b.binNames {
+		if err := validateBinName(binName); err != nil {
+			return err
+		}
+	}
+	if b.filter != nil {
+		return validateBinName(b.filter.binName)
+	}
+	return nil
+}
+
+func validateBinName(binName string) error {
+	if binName == "" {
+		return NewAerospikeError(PARAMETER_ERROR, "Bin names must not be empty")
+	}
+	if len(binName) > maxBinNameLen {
+		return NewAerospikeError(BIN_NAME_TOO_LONG, fmt.Sprintf("Bin name `%s` is longer than %d characters", binName, maxBinNameLen))
+	}
+	return nil
+}
+
+// bindFilter returns the filter with its parameters bound to their values,
+// and checks the values against the type of the filter and of the index.
+func (b *StatementBuilder) bindFilter(params Params) (*Filter, error) {
+	ft := b.filter
+
+	begin, err := bindParam(ft.begin, params)
+	if err != nil {
+		return nil, err
+	}
+	end, err := bindParam(ft.end, params)
+	if err != nil {
+		return nil, err
+	}
+
+	valueType := begin.GetType()
+	switch {
+	case ft.isRange && (valueType != ParticleType.INTEGER || end.GetType() != ParticleType.INTEGER):
+		return nil, NewAerospikeError(PARAMETER_ERROR, "Range filter on bin `"+ft.binName+"` needs integer bounds")
+	case valueType != ParticleType.INTEGER && valueType != ParticleType.STRING && valueType != ParticleType.BLOB:
+		return nil, NewAerospikeError(PARAMETER_ERROR, fmt.Sprintf("Filter on bin `%s` has a value of unsupported type %T", ft.binName, begin.GetObject()))
+	}
+
+	if b.indexType != "" && indexParticleType[b.indexType] != valueType {
+		return nil, NewAerospikeError(PARAMETER_ERROR, fmt.Sprintf("Filter on bin `%s` has a value of type %T, which can not be looked up in a %s index", ft.binName, begin.GetObject(), b.indexType))
+	}
+
+	return newFilter(ft.binName, ft.idxType, begin, end, ft.ctx), nil
+}
+
+// indexParticleType maps index types to the particle type of their values.
+var indexParticleType = map[IndexType]int{
+	NUMERIC: ParticleType.INTEGER,
+	STRING:  ParticleType.STRING,
+	BLOB:    ParticleType.BLOB,
+}
+
+// bindParam returns the value, or the value bound to it if it is a Param.
+func bindParam(v interface{}, params Params) (Value, error) {
+	if p, ok := v.(Param); ok {
+		bound, exists := params[string(p)]
+		if !exists {
+			return nil, NewAerospikeError(PARAMETER_ERROR, "Parameter `"+string(p)+"` is not bound")
+		}
+		v = bound
+	}
+
+	// integers are sent as int64, like by NewRangeFilter
+	switch n := v.(type) {
+	case int:
+		return NewValue(int64(n)), nil
+	case int8:
+		return NewValue(int64(n)), nil
+	case int16:
+		return NewValue(int64(n)), nil
+	case int32:
+		return NewValue(int64(n)), nil
+	case uint8:
+		return NewValue(int64(n)), nil
+	case uint16:
+		return NewValue(int64(n)), nil
+	case uint32:
+		return NewValue(int64(n)), nil
+	case int64, string, []byte, Value:
+		return NewValue(v), nil
+	}
+	return nil, NewAerospikeError(PARAMETER_ERROR, fmt.Sprintf("Filter value of type %T is not supported", v))
+}
@@ -0,0 +1,112 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"strings"
+	"time"
+
+	. "github.com/THE108/aerospike-client-go/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Statement Builder Test", func() {
+
+	resultCode := func(err error) ResultCode {
+		Expect(err).To(HaveOccurred())
+		return err.(AerospikeError).ResultCode()
+	}
+
+	It("should build statements with bound parameters", func() {
+		byAge := NewStatementBuilder("test", "users").
+			Bins("name", "age").
+			Index("users_age", NUMERIC).
+			Range("age", Param("min"), Param("max")).
+			PredExp(NewPredExpLastUpdateRange(time.Unix(1, 0), time.Time{})...)
+
+		stmt, err := byAge.Build(Params{"min": 18, "max": 30})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stmt.Namespace).To(Equal("test"))
+		Expect(stmt.SetName).To(Equal("users"))
+		Expect(stmt.BinNames).To(Equal([]string{"name", "age"}))
+		Expect(stmt.IndexName).To(Equal("users_age"))
+		Expect(stmt.Filters).To(Equal([]*Filter{NewRangeFilter("age", 18, 30)}))
+		Expect(len(stmt.PredExp)).To(Equal(3))
+
+		// the builder can be reused with other values
+		other, err := byAge.Build(Params{"min": 40, "max": 50})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(other.Filters).To(Equal([]*Filter{NewRangeFilter("age", 40, 50)}))
+		Expect(stmt.Filters).To(Equal([]*Filter{NewRangeFilter("age", 18, 30)}))
+
+		stmt, err = NewStatementBuilder("test", "").Equal("name", "joe").Build(nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stmt.Filters).To(Equal([]*Filter{NewEqualFilter("name", "joe")}))
+
+		stmt, err = NewStatementBuilder("test", "users").Contains("tags", ICT_LIST, Param("tag")).Build(Params{"tag": "admin"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stmt.Filters).To(Equal([]*Filter{NewContainsFilter("tags", ICT_LIST, "admin")}))
+
+		stmt, err = NewStatementBuilder("test", "users").Build(nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stmt.IsScan()).To(BeTrue())
+	})
+
+	It("should validate names against the server limits", func() {
+		_, err := NewStatementBuilder("", "users").Build(nil)
+		Expect(resultCode(err)).To(Equal(INVALID_NAMESPACE))
+
+		_, err = NewStatementBuilder(strings.Repeat("n", 32), "users").Build(nil)
+		Expect(resultCode(err)).To(Equal(INVALID_NAMESPACE))
+
+		_, err = NewStatementBuilder("test", strings.Repeat("s", 64)).Build(nil)
+		Expect(resultCode(err)).To(Equal(PARAMETER_ERROR))
+
+		_, err = NewStatementBuilder("test", "users").Bins("name", strings.Repeat("b", 16)).Build(nil)
+		Expect(resultCode(err)).To(Equal(BIN_NAME_TOO_LONG))
+
+		_, err = NewStatementBuilder("test", "users").Equal("", 1).Build(nil)
+		Expect(resultCode(err)).To(Equal(PARAMETER_ERROR))
+
+		_, err = NewStatementBuilder("test", "users").Index(strings.Repeat("i", 256), NUMERIC).Equal("age", 1).Build(nil)
+		Expect(resultCode(err)).To(Equal(INDEX_NAME_MAXLEN))
+
+		_, err = NewStatementBuilder("test", "users").Index("users_age", NUMERIC).Build(nil)
+		Expect(resultCode(err)).To(Equal(PARAMETER_ERROR))
+
+		_, err = NewStatementBuilder("test", "users").Equal("age", 1).Equal("name", "joe").Build(nil)
+		Expect(resultCode(err)).To(Equal(PARAMETER_ERROR))
+	})
+
+	It("should check the types of filter values", func() {
+		_, err := NewStatementBuilder("test", "users").Range("age", Param("min"), 30).Build(nil)
+		Expect(resultCode(err)).To(Equal(PARAMETER_ERROR))
+
+		_, err = NewStatementBuilder("test", "users").Range("age", "a", "z").Build(nil)
+		Expect(resultCode(err)).To(Equal(PARAMETER_ERROR))
+
+		_, err = NewStatementBuilder("test", "users").Equal("age", 1.5).Build(nil)
+		Expect(resultCode(err)).To(Equal(PARAMETER_ERROR))
+
+		_, err = NewStatementBuilder("test", "users").Index("users_age", NUMERIC).Equal("age", Param("age")).Build(Params{"age": "18"})
+		Expect(resultCode(err)).To(Equal(PARAMETER_ERROR))
+
+		_, err = NewStatementBuilder("test", "users").Index("users_id", BLOB).Equal("id", []byte{1}).Build(nil)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+})
@@ -0,0 +1,302 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package stats exposes the metrics of an Aerospike client through expvar,
+// or as an http.Handler in the Prometheus text exposition format:
+//
+//	collector := stats.NewCollector(client)
+//	http.Handle("/metrics", collector)
+//
+// Command counters and durations are only collected if the Observe method of
+// the collector is set as ClientPolicy.CommandObserver. Since the policy is
+// needed to create the client, create the collector with a nil client first,
+// and set the client with SetClient once it is connected.
+package stats
+
+import (
+	"expvar"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	as "github.com/THE108/aerospike-client-go"
+)
+
+// commandStats holds the counters of a command.
+type commandStats struct {
+	count    int64
+	errors   int64
+	duration time.Duration
+}
+
+// Collector gathers the metrics of a client.
+// Collector is safe for concurrent use.
+type Collector struct {
+	mutex    sync.Mutex
+	client   *as.Client
+	commands map[string]*commandStats
+}
+
+// NewCollector generates a Collector for the client. The client may be nil,
+// and set later with SetClient.
+func NewCollector(client *as.Client) *Collector {
+	return &Collector{
+		client:   client,
+		commands: map[string]*commandStats{},
+	}
+}
+
+// SetClient sets the client whose metrics are collected.
+func (c *Collector) SetClient(client *as.Client) {
+	c.mutex.Lock()
+	c.client = client
+	c.mutex.Unlock()
+}
+
+// Observe records a finished command. Set it as ClientPolicy.CommandObserver
+// to collect command counters and durations.
+func (c *Collector) Observe(event *as.CommandEvent) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	cs := c.commands[event.Command]
+	if cs == nil {
+		cs = &commandStats{}
+		c.commands[event.Command] = cs
+	}
+
+	cs.count++
+	cs.duration += event.Duration
+	if event.Err != nil {
+		cs.errors++
+	}
+}
+
+// Publish publishes the metrics as an expvar variable with the name.
+// Like expvar.Publish, it panics if the name is already registered.
+func (c *Collector) Publish(name string) {
+	expvar.Publish(name, expvar.Func(func() interface{} { return c.Snapshot() }))
+}
+
+// NodeStats holds the metrics of a node.
+type NodeStats struct {
+	Name            string  `json:"name"`
+	Address         string  `json:"address"`
+	Active          bool    `json:"active"`
+	Connections     int     `json:"connections"`
+	PendingCommands int     `json:"pending_commands"`
+	LatencySeconds  float64 `json:"latency_seconds"`
+	BatchSize       int     `json:"batch_size"`
+}
+
+type nodeStatsByName []NodeStats
+
+func (s nodeStatsByName) Len() int           { return len(s) }
+func (s nodeStatsByName) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s nodeStatsByName) Less(i, j int) bool { return s[i].Name < s[j].Name }
+
+// ErrorStats holds the read, write and network errors of single record
+// commands on a namespace.
+type ErrorStats struct {
+	Read    int64 `json:"read"`
+	Write   int64 `json:"write"`
+	Network int64 `json:"network"`
+}
+
+// CommandStats holds the counters of a command.
+type CommandStats struct {
+	Count           int64   `json:"count"`
+	Errors          int64   `json:"errors"`
+	DurationSeconds float64 `json:"duration_seconds"`
+}
+
+// Snapshot is a point in time copy of the metrics of a client.
+type Snapshot struct {
+	Nodes        []NodeStats                 `json:"nodes"`
+	Errors       map[string]ErrorStats       `json:"errors"`
+	RMWConflicts map[string]as.ConflictStats `json:"rmw_conflicts"`
+	Commands     map[string]CommandStats     `json:"commands"`
+}
+
+// Snapshot returns the current metrics. Nodes are sorted by name, and
+// errors are keyed by namespace.
+func (c *Collector) Snapshot() *Snapshot {
+	c.mutex.Lock()
+	client := c.client
+	res := &Snapshot{
+		Errors:   map[string]ErrorStats{},
+		Commands: make(map[string]CommandStats, len(c.commands)),
+	}
+	for name, cs := range c.commands {
+		res.Commands[name] = CommandStats{
+			Count:           cs.count,
+			Errors:          cs.errors,
+			DurationSeconds: cs.duration.Seconds(),
+		}
+	}
+	c.mutex.Unlock()
+
+	if client == nil {
+		res.RMWConflicts = map[string]as.ConflictStats{}
+		return res
+	}
+
+	for _, node := range client.GetNodes() {
+		res.Nodes = append(res.Nodes, NodeStats{
+			Name:            node.GetName(),
+			Address:         node.GetHost().String(),
+			Active:          node.IsActive(),
+			Connections:     node.GetConnectionCount(),
+			PendingCommands: node.PendingCommands(),
+			LatencySeconds:  node.Latency().Seconds(),
+			BatchSize:       node.BatchSize(),
+		})
+	}
+	sort.Sort(nodeStatsByName(res.Nodes))
+
+	for _, pe := range client.PartitionErrors() {
+		es := res.Errors[pe.Namespace]
+		es.Read += pe.ReadErrors
+		es.Write += pe.WriteErrors
+		es.Network += pe.NetworkErrors
+		res.Errors[pe.Namespace] = es
+	}
+
+	res.RMWConflicts = client.RMWConflictStats()
+	return res
+}
+
+// ServeHTTP writes the metrics in the Prometheus text exposition format.
+func (c *Collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	c.WritePrometheus(w)
+}
+
+// WritePrometheus writes the metrics in the Prometheus text exposition format.
+func (c *Collector) WritePrometheus(w io.Writer) error {
+	s := c.Snapshot()
+	pw := &promWriter{w: w}
+
+	pw.header("aerospike_node_active", "gauge", "Whether the node is active.")
+	for _, n := range s.Nodes {
+		active := 0
+		if n.Active {
+			active = 1
+		}
+		pw.sample("aerospike_node_active", nodeLabels(n), float64(active))
+	}
+	pw.header("aerospike_node_connections", "gauge", "Connections in the pool of the node.")
+	for _, n := range s.Nodes {
+		pw.sample("aerospike_node_connections", nodeLabels(n), float64(n.Connections))
+	}
+	pw.header("aerospike_node_pending_commands", "gauge", "Commands sent to the node waiting for a response.")
+	for _, n := range s.Nodes {
+		pw.sample("aerospike_node_pending_commands", nodeLabels(n), float64(n.PendingCommands))
+	}
+	pw.header("aerospike_node_latency_seconds", "gauge", "Moving average latency of commands sent to the node.")
+	for _, n := range s.Nodes {
+		pw.sample("aerospike_node_latency_seconds", nodeLabels(n), n.LatencySeconds)
+	}
+	pw.header("aerospike_node_batch_size", "gauge", "Maximum number of keys in batch requests to the node.")
+	for _, n := range s.Nodes {
+		pw.sample("aerospike_node_batch_size", nodeLabels(n), float64(n.BatchSize))
+	}
+
+	namespaces := make([]string, 0, len(s.Errors))
+	for ns := range s.Errors {
+		namespaces = append(namespaces, ns)
+	}
+	sort.Strings(namespaces)
+
+	pw.header("aerospike_errors_total", "counter", "Errors of single record commands by namespace and kind.")
+	for _, ns := range namespaces {
+		es := s.Errors[ns]
+		pw.sample("aerospike_errors_total", labels("namespace", ns, "kind", "read"), float64(es.Read))
+		pw.sample("aerospike_errors_total", labels("namespace", ns, "kind", "write"), float64(es.Write))
+		pw.sample("aerospike_errors_total", labels("namespace", ns, "kind", "network"), float64(es.Network))
+	}
+
+	sets := make([]string, 0, len(s.RMWConflicts))
+	for set := range s.RMWConflicts {
+		sets = append(sets, set)
+	}
+	sort.Strings(sets)
+
+	pw.header("aerospike_rmw_attempts_total", "counter", "Read-modify-write attempts by set.")
+	for _, set := range sets {
+		pw.sample("aerospike_rmw_attempts_total", labels("set", set), float64(s.RMWConflicts[set].Attempts))
+	}
+	pw.header("aerospike_rmw_conflicts_total", "counter", "Read-modify-write conflicts by set.")
+	for _, set := range sets {
+		pw.sample("aerospike_rmw_conflicts_total", labels("set", set), float64(s.RMWConflicts[set].Conflicts))
+	}
+
+	commands := make([]string, 0, len(s.Commands))
+	for name := range s.Commands {
+		commands = append(commands, name)
+	}
+	sort.Strings(commands)
+
+	pw.header("aerospike_commands_total", "counter", "Commands by name.")
+	for _, name := range commands {
+		pw.sample("aerospike_commands_total", labels("command", name), float64(s.Commands[name].Count))
+	}
+	pw.header("aerospike_command_errors_total", "counter", "Failed commands by name.")
+	for _, name := range commands {
+		pw.sample("aerospike_command_errors_total", labels("command", name), float64(s.Commands[name].Errors))
+	}
+	pw.header("aerospike_command_duration_seconds_total", "counter", "Time spent in commands by name, including retries.")
+	for _, name := range commands {
+		pw.sample("aerospike_command_duration_seconds_total", labels("command", name), s.Commands[name].DurationSeconds)
+	}
+
+	return pw.err
+}
+
+// promWriter writes metrics, keeping the first error.
+type promWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (pw *promWriter) header(name, typ, help string) {
+	if pw.err == nil {
+		_, pw.err = fmt.Fprintf(pw.w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+	}
+}
+
+func (pw *promWriter) sample(name, labels string, value float64) {
+	if pw.err == nil {
+		_, pw.err = fmt.Fprintf(pw.w, "%s{%s} %g\n", name, labels, value)
+	}
+}
+
+func nodeLabels(n NodeStats) string {
+	return labels("node", n.Name, "address", n.Address)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// labels formats the name and value pairs as Prometheus labels.
+func labels(pairs ...string) string {
+	parts := make([]string, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		parts = append(parts, pairs[i]+`="`+labelEscaper.Replace(pairs[i+1])+`"`)
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,88 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stats_test
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+
+	as "github.com/THE108/aerospike-client-go"
+	"github.com/THE108/aerospike-client-go/aerotest"
+	"github.com/THE108/aerospike-client-go/stats"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Stats", func() {
+
+	var srv *aerotest.Server
+	var client *as.Client
+	var collector *stats.Collector
+
+	BeforeEach(func() {
+		var err error
+		srv, err = aerotest.NewServer("test")
+		Expect(err).ToNot(HaveOccurred())
+
+		collector = stats.NewCollector(nil)
+		policy := as.NewClientPolicy()
+		policy.CommandObserver = collector.Observe
+
+		client, err = as.NewClientWithPolicy(policy, srv.Host(), srv.Port())
+		Expect(err).ToNot(HaveOccurred())
+		collector.SetClient(client)
+	})
+
+	AfterEach(func() {
+		client.Close()
+		Expect(srv.Close()).ToNot(HaveOccurred())
+	})
+
+	It("must collect node and command metrics", func() {
+		key, _ := as.NewKey("test", "aerotest", "stats")
+		Expect(client.Put(nil, key, as.BinMap{"a": 1})).ToNot(HaveOccurred())
+		_, err := client.Get(nil, key)
+		Expect(err).ToNot(HaveOccurred())
+
+		s := collector.Snapshot()
+		Expect(len(s.Nodes)).To(Equal(1))
+		Expect(s.Nodes[0].Active).To(BeTrue())
+		Expect(s.Commands["write"].Count).To(Equal(int64(1)))
+		Expect(s.Commands["read"].Count).To(Equal(int64(1)))
+		Expect(s.Commands["read"].Errors).To(Equal(int64(0)))
+	})
+
+	It("must serve metrics in the Prometheus text format", func() {
+		key, _ := as.NewKey("test", "aerotest", "stats")
+		Expect(client.Put(nil, key, as.BinMap{"a": 1})).ToNot(HaveOccurred())
+
+		rec := httptest.NewRecorder()
+		collector.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+		body := rec.Body.String()
+
+		Expect(strings.Contains(body, "# TYPE aerospike_commands_total counter\n")).To(BeTrue())
+		Expect(strings.Contains(body, `aerospike_commands_total{command="write"} 1`+"\n")).To(BeTrue())
+		Expect(strings.Contains(body, `aerospike_node_active{node="`)).To(BeTrue())
+	})
+
+	It("must report empty metrics without a client", func() {
+		var buf bytes.Buffer
+		Expect(stats.NewCollector(nil).WritePrometheus(&buf)).ToNot(HaveOccurred())
+		Expect(strings.Contains(buf.String(), "# TYPE aerospike_node_active gauge\n")).To(BeTrue())
+	})
+
+})
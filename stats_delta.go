@@ -0,0 +1,107 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"reflect"
+	"time"
+
+	. "github.com/THE108/aerospike-client-go/types"
+)
+
+// StatsSnapshot is a snapshot of statistics with the time it was taken.
+// It is implemented by NodeStats and NamespaceStats.
+type StatsSnapshot interface {
+	// statsValues returns the numeric statistics by name.
+	statsValues() (time.Time, map[string]float64)
+}
+
+// StatsRates holds the per second rates of the statistics between two snapshots.
+type StatsRates struct {
+	// Interval is the time between the snapshots.
+	Interval time.Duration
+
+	// Rates is the change per second of the numeric statistics found in
+	// both snapshots. Counters which decreased, e.g. because the node was
+	// restarted, are left out.
+	Rates map[string]float64
+}
+
+// Rate returns the change per second of the statistic, or zero if it is unknown.
+func (sr *StatsRates) Rate(name string) float64 {
+	return sr.Rates[name]
+}
+
+// StatsDelta computes the rates of the statistics between two snapshots of the same type.
+// Rates are only meaningful for counters, like `client_read_success`.
+func StatsDelta(prev, cur StatsSnapshot) (*StatsRates, error) {
+	if prev == nil || cur == nil || reflect.TypeOf(prev) != reflect.TypeOf(cur) {
+		return nil, NewAerospikeError(PARAMETER_ERROR, "Statistics snapshots must be of the same type")
+	}
+
+	prevTime, prevValues := prev.statsValues()
+	curTime, curValues := cur.statsValues()
+
+	interval := curTime.Sub(prevTime)
+	if interval <= 0 {
+		return nil, NewAerospikeError(PARAMETER_ERROR, "Statistics snapshots must be taken in order")
+	}
+
+	rates := make(map[string]float64, len(curValues))
+	for name, value := range curValues {
+		if prevValue, exists := prevValues[name]; exists && value >= prevValue {
+			rates[name] = (value - prevValue) / interval.Seconds()
+		}
+	}
+
+	return &StatsRates{Interval: interval, Rates: rates}, nil
+}
+
+func (ns *NodeStats) statsValues() (time.Time, map[string]float64) {
+	return ns.Time, statsNumbers(reflect.ValueOf(ns).Elem(), nodeStatsFields, ns.Other)
+}
+
+func (ns *NamespaceStats) statsValues() (time.Time, map[string]float64) {
+	return ns.Time, statsNumbers(reflect.ValueOf(ns).Elem(), namespaceStatsFields, ns.Other)
+}
+
+// statsNumbers returns the numeric fields of the stats struct v, and the
+// numeric values of other.
+func statsNumbers(v reflect.Value, fields map[string]int, other map[string]interface{}) map[string]float64 {
+	res := make(map[string]float64, len(fields)+len(other))
+	for name, value := range other {
+		switch n := value.(type) {
+		case int64:
+			res[name] = float64(n)
+		case float64:
+			res[name] = n
+		}
+	}
+
+	for name, i := range fields {
+		// fields which could not be parsed are in other
+		if _, exists := other[name]; exists {
+			continue
+		}
+
+		switch f := v.Field(i); f.Kind() {
+		case reflect.Int64:
+			res[name] = float64(f.Int())
+		case reflect.Float64:
+			res[name] = f.Float()
+		}
+	}
+	return res
+}
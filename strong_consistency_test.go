@@ -0,0 +1,108 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"encoding/base64"
+
+	. "github.com/THE108/aerospike-client-go/types/atomic"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Strong Consistency Test", func() {
+
+	// bitmap returns the base64 encoded bitmap of the partitions
+	bitmap := func(partitions ...int) string {
+		buf := make([]byte, _PARTITIONS/8)
+		for _, p := range partitions {
+			buf[p>>3] |= 0x80 >> uint(p&7)
+		}
+		return base64.StdEncoding.EncodeToString(buf)
+	}
+
+	It("should parse rosters", func() {
+		roster, err := parseRoster("test", "roster=BB9@1,BB8@1:pending_roster=null:observed_nodes=BB9,BB8,BB7\n")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(roster.Namespace).To(Equal("test"))
+		Expect(roster.Nodes).To(Equal([]string{"BB9@1", "BB8@1"}))
+		Expect(roster.PendingNodes).To(BeNil())
+		Expect(roster.ObservedNodes).To(Equal([]string{"BB9", "BB8", "BB7"}))
+
+		_, err = parseRoster("test", "unexpected")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should parse replicas with and without regimes", func() {
+		regime, bitmaps, err := parseReplicasEntry("7,2,AAA=,BBB=")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(regime).To(Equal(7))
+		Expect(bitmaps).To(Equal([]string{"AAA=", "BBB="}))
+
+		regime, bitmaps, err = parseReplicasEntry("2,AAA=,BBB=")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(regime).To(Equal(0))
+		Expect(bitmaps).To(Equal([]string{"AAA=", "BBB="}))
+
+		_, _, err = parseReplicasEntry("3,AAA=")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should ignore partition claims of older regimes", func() {
+		n1, n2 := &Node{name: "n1"}, &Node{name: "n2"}
+		regimes := map[string][]int{}
+
+		pt := &partitionTokenizerReplicas{info: "test:5,1," + bitmap(0, 1) + "\n"}
+		nmap, _, err := pt.UpdatePartition(map[string]*AtomicArray{}, map[string][]*AtomicArray{}, regimes, n1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(nmap["test"].Get(0)).To(Equal(n1))
+		Expect(nmap["test"].Get(1)).To(Equal(n1))
+
+		// stale master
+		pt = &partitionTokenizerReplicas{info: "test:4,1," + bitmap(0)}
+		_, _, err = pt.UpdatePartition(nmap, map[string][]*AtomicArray{}, regimes, n2)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(nmap["test"].Get(0)).To(Equal(n1))
+
+		// new master
+		pt = &partitionTokenizerReplicas{info: "test:6,2," + bitmap(1) + "," + bitmap(0)}
+		_, _, err = pt.UpdatePartition(nmap, map[string][]*AtomicArray{}, regimes, n2)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(nmap["test"].Get(0)).To(Equal(n1))
+		Expect(nmap["test"].Get(1)).To(Equal(n2))
+		Expect(regimes["test"][1]).To(Equal(6))
+	})
+
+	It("should send the read mode in read headers only", func() {
+		cmd := &baseCommand{dataBuffer: make([]byte, 64)}
+
+		policy := NewPolicy()
+		policy.ReadModeSC = SC_LINEARIZE
+		cmd.writeHeader(policy, _INFO1_READ, 0, 0, 0)
+		Expect(int(cmd.dataBuffer[11])).To(Equal(_INFO3_SC_READ_TYPE))
+
+		policy.ReadModeSC = SC_ALLOW_UNAVAILABLE
+		cmd.writeHeader(policy, _INFO1_READ, 0, 0, 0)
+		Expect(int(cmd.dataBuffer[11])).To(Equal(_INFO3_SC_READ_TYPE | _INFO3_SC_READ_RELAX))
+
+		cmd.writeHeader(policy, _INFO1_READ, _INFO2_WRITE, 0, 0)
+		Expect(cmd.dataBuffer[11]).To(Equal(byte(0)))
+
+		cmd.writeHeader(NewPolicy(), _INFO1_READ, 0, 0, 0)
+		Expect(cmd.dataBuffer[11]).To(Equal(byte(0)))
+	})
+
+})
@@ -15,29 +15,52 @@
 package aerospike
 
 import (
+	"context"
 	"time"
 )
 
+// DefaultTaskPollInterval is the default interval between task status checks.
+const DefaultTaskPollInterval = 1 * time.Second
+
 // Task interface defines methods for asynchronous tasks.
 type Task interface {
 	IsDone() (bool, error)
 
 	onComplete(ifc Task) chan error
 	OnComplete() chan error
+
+	waitUntilDone(ctx context.Context, ifc Task) error
+	// WaitUntilDone blocks until the task is done, an error occurs,
+	// or the context is cancelled.
+	WaitUntilDone(ctx context.Context) error
+
+	// SetPollInterval sets the interval between task status checks.
+	SetPollInterval(interval time.Duration)
 }
 
 // BaseTask is used to poll for server task completion.
+// Tasks are not synchronized; call SetPollInterval before waiting on the task.
 type BaseTask struct {
 	cluster        *Cluster
 	done           bool
 	onCompleteChan chan error
+	pollInterval   time.Duration
 }
 
 // NewTask initializes task with fields needed to query server nodes.
 func NewTask(cluster *Cluster, done bool) *BaseTask {
 	return &BaseTask{
-		cluster: cluster,
-		done:    done,
+		cluster:      cluster,
+		done:         done,
+		pollInterval: DefaultTaskPollInterval,
+	}
+}
+
+// SetPollInterval sets the interval between task status checks.
+// It must be called before the task is waited on.
+func (btsk *BaseTask) SetPollInterval(interval time.Duration) {
+	if interval > 0 {
+		btsk.pollInterval = interval
 	}
 }
 
@@ -52,7 +75,7 @@ func (btsk *BaseTask) onComplete(ifc Task) chan error {
 	btsk.onCompleteChan = make(chan error)
 
 	// goroutine will loop every <interval> until IsDone() returns true or error
-	const interval = 1 * time.Second
+	interval := btsk.pollInterval
 	go func() {
 		// always close the channel on return
 		defer close(btsk.onCompleteChan)
@@ -74,3 +97,32 @@ func (btsk *BaseTask) onComplete(ifc Task) chan error {
 
 	return btsk.onCompleteChan
 }
+
+// Wait for asynchronous task to complete, checking its status every poll interval.
+func (btsk *BaseTask) waitUntilDone(ctx context.Context, ifc Task) error {
+	if btsk.done {
+		return nil
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	ticker := time.NewTicker(btsk.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		done, err := ifc.IsDone()
+		if err != nil {
+			return err
+		} else if done {
+			return nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -15,6 +15,7 @@
 package aerospike
 
 import (
+	"context"
 	"regexp"
 	"strconv"
 	"strings"
@@ -37,40 +38,65 @@ func NewIndexTask(cluster *Cluster, namespace string, indexName string) *IndexTa
 	}
 }
 
+var loadPctRegexp = regexp.MustCompile(`\.*load_pct=(\d+)\.*`)
+
 // IsDone queries all nodes for task completion status.
+// The task is done when the index exists and is fully loaded on every node.
 func (tski *IndexTask) IsDone() (bool, error) {
+	pct, err := tski.Progress()
+	if err != nil {
+		return false, err
+	}
+	return pct == 100, nil
+}
+
+// Progress queries all nodes and returns the index build progress in percent,
+// as reported by the least advanced node. Nodes which have not received the
+// index yet report 0. If there are no nodes in the cluster, -1 is returned.
+func (tski *IndexTask) Progress() (int, error) {
 	command := "sindex/" + tski.namespace + "/" + tski.indexName
 	nodes := tski.cluster.GetNodes()
-	complete := false
-
-	r := regexp.MustCompile(`\.*load_pct=(\d+)\.*`)
+	progress := -1
 
 	for _, node := range nodes {
 		responseMap, err := RequestNodeInfo(node, command)
 		if err != nil {
-			return false, err
+			return -1, err
 		}
 
 		for _, response := range responseMap {
-			find := "load_pct="
-			index := strings.Index(response, find)
-
-			if index < 0 {
-				complete = true
-				continue
+			pct := parseIndexLoadPct(response)
+			if progress < 0 || pct < progress {
+				progress = pct
 			}
+		}
+	}
+	return progress, nil
+}
 
-			matchRes := r.FindStringSubmatch(response)
-			// we know it exists and is a valid number
-			pct, _ := strconv.Atoi(matchRes[1])
+// parseIndexLoadPct returns the load percentage of an index
+// from the response of a `sindex/<ns>/<index>` info command.
+func parseIndexLoadPct(response string) int {
+	// index has not yet been propagated to this node
+	if strings.HasPrefix(response, "FAIL:201") {
+		return 0
+	}
 
-			if pct >= 0 && pct < 100 {
-				return false, nil
-			}
-			complete = true
-		}
+	if !strings.Contains(response, "load_pct=") {
+		return 100
+	}
+
+	matchRes := loadPctRegexp.FindStringSubmatch(response)
+	if matchRes == nil {
+		return 100
 	}
-	return complete, nil
+
+	// we know it exists and is a valid number
+	pct, _ := strconv.Atoi(matchRes[1])
+	if pct < 0 || pct > 100 {
+		return 100
+	}
+	return pct
 }
 
 // OnComplete returns a channel that will be closed as soon as the task is finished.
@@ -78,3 +104,9 @@ func (tski *IndexTask) IsDone() (bool, error) {
 func (tski *IndexTask) OnComplete() chan error {
 	return tski.onComplete(tski)
 }
+
+// WaitUntilDone blocks until the task is finished, an error is encountered,
+// or the context is cancelled.
+func (tski *IndexTask) WaitUntilDone(ctx context.Context) error {
+	return tski.waitUntilDone(ctx, tski)
+}
@@ -0,0 +1,31 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("IndexTask Test", func() {
+
+	It("should parse index load percentage", func() {
+		Expect(parseIndexLoadPct("keys=10;entries=10;load_pct=42;loadtime=0")).To(Equal(42))
+		Expect(parseIndexLoadPct("keys=10;entries=10;load_pct=100")).To(Equal(100))
+		Expect(parseIndexLoadPct("FAIL:201:NO INDEX")).To(Equal(0))
+		Expect(parseIndexLoadPct("keys=10;entries=10")).To(Equal(100))
+	})
+
+})
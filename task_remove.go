@@ -15,6 +15,7 @@
 package aerospike
 
 import (
+	"context"
 	"strings"
 )
 
@@ -63,3 +64,9 @@ func (tskr *RemoveTask) IsDone() (bool, error) {
 func (tskr *RemoveTask) OnComplete() chan error {
 	return tskr.onComplete(tskr)
 }
+
+// WaitUntilDone blocks until the task is finished, an error is encountered,
+// or the context is cancelled.
+func (tskr *RemoveTask) WaitUntilDone(ctx context.Context) error {
+	return tskr.waitUntilDone(ctx, tskr)
+}
@@ -0,0 +1,78 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// countdownTask is done after a number of status checks.
+type countdownTask struct {
+	*BaseTask
+	checks int
+}
+
+func (t *countdownTask) IsDone() (bool, error) {
+	t.checks--
+	return t.checks <= 0, nil
+}
+
+func (t *countdownTask) OnComplete() chan error {
+	return t.onComplete(t)
+}
+
+func (t *countdownTask) WaitUntilDone(ctx context.Context) error {
+	return t.waitUntilDone(ctx, t)
+}
+
+var _ Task = &countdownTask{}
+
+var _ = Describe("Task Test", func() {
+
+	It("should wait until the task is done", func() {
+		task := &countdownTask{BaseTask: NewTask(nil, false), checks: 3}
+		task.SetPollInterval(time.Millisecond)
+
+		Expect(task.WaitUntilDone(context.Background())).ToNot(HaveOccurred())
+		Expect(task.checks).To(Equal(0))
+	})
+
+	It("should stop waiting when the context is cancelled", func() {
+		task := &countdownTask{BaseTask: NewTask(nil, false), checks: 1000000}
+		task.SetPollInterval(time.Millisecond)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		Expect(task.WaitUntilDone(ctx)).To(Equal(context.DeadlineExceeded))
+	})
+
+	It("should use the poll interval in OnComplete", func() {
+		task := &countdownTask{BaseTask: NewTask(nil, false), checks: 2}
+		task.SetPollInterval(time.Millisecond)
+
+		select {
+		case err := <-task.OnComplete():
+			Expect(err).ToNot(HaveOccurred())
+		case <-time.After(time.Second):
+			Fail("task did not complete in time")
+		}
+	})
+
+})
@@ -0,0 +1,56 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"time"
+)
+
+// getTendConnection returns the connection reserved for tending the node,
+// opening it if needed. It is not part of the connection pool, so tending
+// never waits for, or is refused, a pooled connection when the pool is
+// exhausted. The connection is only used by the tend goroutine.
+func (nd *Node) getTendConnection(timeout time.Duration) (*Connection, error) {
+	nd.tendConnMutex.Lock()
+	defer nd.tendConnMutex.Unlock()
+
+	if nd.tendConn != nil && nd.tendConn.IsConnected() {
+		if err := nd.tendConn.SetTimeout(timeout); err == nil {
+			return nd.tendConn, nil
+		}
+		nd.tendConn.Close()
+		nd.tendConn = nil
+	}
+
+	conn, err := nd.newConnection(time.Now().Add(timeout))
+	if err != nil {
+		return nil, err
+	}
+
+	nd.tendConn = conn
+	return conn, nil
+}
+
+// closeTendConnection closes the connection reserved for tending the node;
+// a new one is opened on the next tend.
+func (nd *Node) closeTendConnection() {
+	nd.tendConnMutex.Lock()
+	defer nd.tendConnMutex.Unlock()
+
+	if nd.tendConn != nil {
+		nd.tendConn.Close()
+		nd.tendConn = nil
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike_test
+
+import (
+	. "github.com/THE108/aerospike-client-go"
+	"github.com/THE108/aerospike-client-go/aerotest"
+	. "github.com/THE108/aerospike-client-go/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Tend connection", func() {
+
+	var srv *aerotest.Server
+	var client *Client
+
+	BeforeEach(func() {
+		var err error
+		policy := NewClientPolicy()
+		policy.ConnectionQueueSize = 1
+		policy.LimitConnectionsToQueueSize = true
+		client, srv, err = aerotest.NewClientAndServerWithPolicy(policy, "test")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		client.Close()
+		Expect(srv.Close()).ToNot(HaveOccurred())
+	})
+
+	It("must tend nodes while the connection pool is exhausted", func() {
+		node := client.GetNodes()[0]
+
+		// take the only connection allowed in the pool
+		conn, err := node.GetConnection(0)
+		Expect(err).ToNot(HaveOccurred())
+		defer node.PutConnection(conn)
+
+		_, err = node.GetConnection(0)
+		Expect(err).To(HaveOccurred())
+		Expect(err.(AerospikeError).ResultCode()).To(Equal(NO_AVAILABLE_CONNECTIONS_TO_NODE))
+
+		for i := 0; i < 3; i++ {
+			_, err = node.Refresh()
+			Expect(err).ToNot(HaveOccurred())
+		}
+		Expect(node.IsActive()).To(BeTrue())
+		Expect(node.GetConnectionCount()).To(Equal(1))
+	})
+
+})
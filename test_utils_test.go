@@ -20,6 +20,7 @@ import (
 
 	. "github.com/onsi/gomega"
 
+	. "github.com/THE108/aerospike-client-go"
 	// . "github.com/THE108/aerospike-client-go/logger"
 )
 
@@ -104,3 +105,9 @@ func mapsEqual(ia, ib interface{}) {
 	Expect(len(a)).To(Equal(len(b)))
 	Expect(a).To(BeEquivalentTo(b))
 }
+
+func mustKey(value interface{}) *Key {
+	key, err := NewKey("test", "aerotest", value)
+	Expect(err).ToNot(HaveOccurred())
+	return key
+}
@@ -11,27 +11,58 @@
 // WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
 // See the License for the specific language governing permissions and
 // limitations under the License.
-
 package main
 
 import (
+	"bytes"
+	"encoding/json"
 	"flag"
+	"fmt"
 	"log"
 	"os"
+	"sort"
 	"strings"
+	"text/tabwriter"
 	"time"
 
 	as "github.com/THE108/aerospike-client-go"
 )
 
+// stringList is a flag which can be passed multiple times.
+type stringList []string
+
+func (sl *stringList) String() string {
+	return strings.Join(*sl, ",")
+}
+
+func (sl *stringList) Set(value string) error {
+	*sl = append(*sl, strings.Trim(value, " "))
+	return nil
+}
+
 var host = flag.String("h", "127.0.0.1", "host (default 127.0.0.1)")
 var port = flag.Int("p", 3000, "port (default 3000)")
-var value = flag.String("v", "", "(fetch single value - default all)")
+var values stringList
 var sepLines = flag.Bool("l", false, "(print in seperate lines - default false)")
+var allNodes = flag.Bool("a", false, "Run the info commands on all nodes of the cluster instead of the seed node.")
+var output = flag.String("o", "plain", "Output format: plain, table or json.")
+var timeout = flag.Int("t", 2000, "Info command timeout in milliseconds.")
 var user = flag.String("U", "", "User.")
 var password = flag.String("P", "", "Password.")
 var clientPolicy *as.ClientPolicy
 
+func init() {
+	flag.Var(&values, "v", "Info command to run. Can be passed multiple times. (fetch single value - default all)")
+}
+
+// nodeInfo holds the info values returned by a node.
+type nodeInfo struct {
+	Node    string            `json:"node"`
+	Address string            `json:"address"`
+	Values  map[string]string `json:"values,omitempty"`
+	Error   string            `json:"error,omitempty"`
+}
+
 func main() {
 	flag.Parse()
 	log.SetOutput(os.Stdout)
@@ -44,24 +75,118 @@ func main() {
 	}
 
 	// connect to the host
-	if client, err := as.NewClientWithPolicy(clientPolicy, *host, *port); err != nil {
+	client, err := as.NewClientWithPolicy(clientPolicy, *host, *port)
+	if err != nil {
 		log.Fatalln(err.Error())
-	} else {
-		node := client.GetNodes()[0]
-		if conn, err := node.GetConnection(time.Second); err != nil {
-			log.Fatalln(err.Error())
+	}
+	defer client.Close()
+
+	nodes := client.GetNodes()
+	if len(nodes) == 0 {
+		log.Fatalln("No nodes found in the cluster.")
+	}
+	if !*allNodes {
+		nodes = []*as.Node{seedNode(nodes)}
+	}
+
+	policy := as.NewInfoPolicy()
+	policy.Timeout = time.Duration(*timeout) * time.Millisecond
+
+	results := make([]*nodeInfo, len(nodes))
+	for i, node := range nodes {
+		results[i] = &nodeInfo{Node: node.GetName(), Address: node.GetHost().String()}
+		if infoMap, err := as.RequestNodeInfoWithPolicy(policy, node, values...); err != nil {
+			results[i].Error = err.Error()
 		} else {
-			if infoMap, err := as.RequestInfo(conn, strings.Trim(*value, " ")); err != nil {
-				node.InvalidateConnection(conn)
-				log.Fatalln(err.Error())
-			} else {
-				node.PutConnection(conn)
-				cnt := 1
-				for k, v := range infoMap {
-					log.Printf("%d :  %s\n     %s\n\n", cnt, k, v)
-					cnt++
-				}
+			results[i].Values = infoMap
+		}
+	}
+
+	switch *output {
+	case "json":
+		printJSON(results)
+	case "table":
+		printTable(results)
+	case "plain":
+		printPlain(results)
+	default:
+		log.Fatalln("Invalid output format: " + *output)
+	}
+}
+
+// seedNode returns the node with the seed address, or the first node if not found.
+func seedNode(nodes []*as.Node) *as.Node {
+	for _, node := range nodes {
+		if h := node.GetHost(); h.Name == *host && h.Port == *port {
+			return node
+		}
+	}
+	return nodes[0]
+}
+
+// sortedNames returns the info names of the node in a stable order.
+func sortedNames(infoMap map[string]string) []string {
+	names := make([]string, 0, len(infoMap))
+	for name := range infoMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// formatValue splits the value into separate lines if requested.
+func formatValue(value string, indent string) string {
+	if !*sepLines {
+		return value
+	}
+	return strings.Replace(strings.Trim(value, ";"), ";", "\n"+indent, -1)
+}
+
+func printPlain(results []*nodeInfo) {
+	for _, res := range results {
+		if len(results) > 1 {
+			log.Printf("%s (%s)\n", res.Node, res.Address)
+		}
+
+		if res.Error != "" {
+			log.Printf("error: %s\n\n", res.Error)
+			continue
+		}
+
+		for i, name := range sortedNames(res.Values) {
+			log.Printf("%d :  %s\n     %s\n\n", i+1, name, formatValue(res.Values[name], "     "))
+		}
+	}
+}
+
+func printTable(results []*nodeInfo) {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "NODE\tADDRESS\tNAME\tVALUE")
+
+	for _, res := range results {
+		if res.Error != "" {
+			fmt.Fprintf(w, "%s\t%s\t\terror: %s\n", res.Node, res.Address, res.Error)
+			continue
+		}
+
+		for _, name := range sortedNames(res.Values) {
+			lines := strings.Split(formatValue(res.Values[name], ""), "\n")
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", res.Node, res.Address, name, lines[0])
+			for _, line := range lines[1:] {
+				fmt.Fprintf(w, "\t\t\t%s\n", line)
 			}
 		}
 	}
+
+	w.Flush()
+	log.Print(buf.String())
+}
+
+func printJSON(results []*nodeInfo) {
+	b, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		log.Fatalln(err.Error())
+	}
+	log.Println(string(b))
 }
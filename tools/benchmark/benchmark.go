@@ -45,6 +45,7 @@ type TStats struct {
 	RMin, RMax int64
 	WLat, RLat int64
 	Wn, Rn     []int64
+	WH, RH     *latencyHistogram // write and read latency histograms
 }
 
 var countReportChan = make(chan *TStats, 100) // async chan
@@ -66,6 +67,10 @@ var throughput = flag.Int64("g", 0, "Throttle transactions per second to a maxim
 var timeout = flag.Int("T", 0, "Read/Write timeout in milliseconds.")
 var maxRetries = flag.Int("maxRetries", 2, "Maximum number of retries before aborting the current transaction.")
 var connQueueSize = flag.Int("queueSize", 4096, "Maximum number of connections to pool.")
+var limitConnections = flag.Bool("limitConnections", false, "Do not open more connections than the pool size (queueSize) per node.")
+var runTime = flag.Int("t", 0, "Benchmark run time in seconds.\n\tIf zero, run until all keys are initialized for 'insert' workloads, or forever for 'read/update' workloads.")
+var percentileDef = flag.String("pct", "50,90,99,99.9", "Comma separated latency percentiles to report.\n\tIf empty, do not report percentiles.")
+var seed = flag.Int64("seed", 0, "Seed for the random bin data and workload mix generator.\n\tIf zero, a time based seed is used.")
 
 var randBinData = flag.Bool("R", false, "Use dynamically generated random bin values instead of default static fixed bin values.")
 var useMarshalling = flag.Bool("M", false, "Use marshaling a struct instead of simple key/value operations")
@@ -79,6 +84,10 @@ var binDataSize int
 var workloadType string
 var workloadPercent int
 var latBase, latCols int
+var percentiles []float64
+
+// benchmark end time, zero for no limit
+var deadline time.Time
 
 // group mutex to wait for all load generating go routines to finish
 var wg sync.WaitGroup
@@ -113,6 +122,7 @@ func main() {
 	clientPolicy := NewClientPolicy()
 	// cache lots  connections
 	clientPolicy.ConnectionQueueSize = *connQueueSize
+	clientPolicy.LimitConnectionsToQueueSize = *limitConnections
 	clientPolicy.User = *user
 	clientPolicy.Password = *password
 	clientPolicy.Timeout = 10 * time.Second
@@ -123,6 +133,10 @@ func main() {
 
 	log.Println("Nodes Found:", client.GetNodeNames())
 
+	if *runTime > 0 {
+		deadline = time.Now().Add(time.Duration(*runTime) * time.Second)
+	}
+
 	go reporter()
 	wg.Add(*concurrency)
 	for i := 1; i < *concurrency; i++ {
@@ -167,8 +181,12 @@ func printBenchmarkParams() {
 	log.Printf("max throughput\t%s", throughputToString())
 	log.Printf("timeout\t\t%v ms", *timeout)
 	log.Printf("max retries\t\t%d", *maxRetries)
+	log.Printf("queue size\t\t%d, limited: %v", *connQueueSize, *limitConnections)
+	log.Printf("run time\t\t%d s", *runTime)
+	log.Printf("seed\t\t%d", *seed)
 	log.Printf("debug:\t\t%v", *debugMode)
 	log.Printf("latency:\t\t%d:%d", latBase, latCols)
+	log.Printf("percentiles:\t%v", percentiles)
 }
 
 // parses an string of (key:value) type
@@ -222,6 +240,15 @@ func readFlags() {
 		latCols, latBase = parseLatency(*latency)
 	}
 
+	var err error
+	if percentiles, err = parsePercentiles(*percentileDef); err != nil {
+		log.Fatal(err)
+	}
+
+	if *seed != 0 {
+		xr = NewXorRandWithSeed(uint64(*seed))
+	}
+
 	var binDataSz, workloadPct *int
 
 	binDataType, binDataSz = parseValuedParam(*binDef)
@@ -363,10 +390,11 @@ func runBench(client *Client, ident int, times int) {
 
 	wLatList := make([]int64, latCols+1)
 	rLatList := make([]int64, latCols+1)
+	wHist, rHist := newLatencyHistogram(), newLatencyHistogram()
 
 	bin := defaultBin
 	obj := defaultObj
-	for i := 1; workloadType == "RU" || i <= times; i++ {
+	for i := 1; (workloadType == "RU" || i <= times) && (deadline.IsZero() || time.Now().Before(deadline)); i++ {
 		rLat, wLat = 0, 0
 		key, _ := NewKey(*namespace, *set, ident*times+(i%times))
 		if workloadType == "I" || int(xr.Uint64()%100) >= workloadPercent {
@@ -390,7 +418,9 @@ func runBench(client *Client, ident int, times int) {
 					incOnError(&writeErr, &writeTOErr, err)
 				}
 			}
-			wLat = int64(time.Now().Sub(tm) / time.Millisecond)
+			elapsed := time.Now().Sub(tm)
+			wHist.add(elapsed)
+			wLat = int64(elapsed / time.Millisecond)
 			wLatTotal += wLat
 
 			// under 1 ms
@@ -418,7 +448,9 @@ func runBench(client *Client, ident int, times int) {
 					incOnError(&readErr, &readTOErr, err)
 				}
 			}
-			rLat = int64(time.Now().Sub(tm) / time.Millisecond)
+			elapsed := time.Now().Sub(tm)
+			rHist.add(elapsed)
+			rLat = int64(elapsed / time.Millisecond)
 			rLatTotal += rLat
 
 			// under 1 ms
@@ -446,7 +478,7 @@ func runBench(client *Client, ident int, times int) {
 		}
 
 		if forceReport || (time.Now().Sub(t) > (100 * time.Millisecond)) {
-			countReportChan <- &TStats{false, WCount, RCount, writeErr, readErr, writeTOErr, readTOErr, wMinLat, wMaxLat, rMinLat, rMaxLat, wLatTotal, rLatTotal, wLatList, rLatList}
+			countReportChan <- &TStats{false, WCount, RCount, writeErr, readErr, writeTOErr, readTOErr, wMinLat, wMaxLat, rMinLat, rMaxLat, wLatTotal, rLatTotal, wLatList, rLatList, wHist, rHist}
 			WCount, RCount = 0, 0
 			writeErr, readErr = 0, 0
 			writeTOErr, readTOErr = 0, 0
@@ -458,6 +490,7 @@ func runBench(client *Client, ident int, times int) {
 
 			wLatList = make([]int64, latCols+1)
 			rLatList = make([]int64, latCols+1)
+			wHist, rHist = newLatencyHistogram(), newLatencyHistogram()
 
 			t = time.Now()
 		}
@@ -468,7 +501,7 @@ func runBench(client *Client, ident int, times int) {
 			time.Sleep(time.Second - time.Duration(time.Now().UnixNano()-atomic.LoadInt64(&lastReport)))
 		}
 	}
-	countReportChan <- &TStats{false, WCount, RCount, writeErr, readErr, writeTOErr, readTOErr, wMinLat, wMaxLat, rMinLat, rMaxLat, wLatTotal, rLatTotal, wLatList, rLatList}
+	countReportChan <- &TStats{false, WCount, RCount, writeErr, readErr, writeTOErr, readTOErr, wMinLat, wMaxLat, rMinLat, rMaxLat, wLatTotal, rLatTotal, wLatList, rLatList, wHist, rHist}
 }
 
 // calculates transactions per second
@@ -508,6 +541,11 @@ func reporter() {
 	wLatList := make([]int64, latCols+1)
 	rLatList := make([]int64, latCols+1)
 
+	// latency histograms of the report interval, and the whole run
+	wHist, rHist := newLatencyHistogram(), newLatencyHistogram()
+	wRunHist, rRunHist := newLatencyHistogram(), newLatencyHistogram()
+	runStart := time.Now()
+
 	var strBuff bytes.Buffer
 
 	memProfileStr := func() string {
@@ -549,6 +587,9 @@ Loop:
 			wTotalLat += stats.WLat
 			rTotalLat += stats.RLat
 
+			wHist.merge(stats.WH)
+			rHist.merge(stats.RH)
+
 			for i := 0; i <= latCols; i++ {
 				if stats.Wn != nil {
 					wLatList[i] += stats.Wn[i]
@@ -614,7 +655,21 @@ Loop:
 					strBuff.Reset()
 				}
 
+				if len(percentiles) > 0 {
+					if wHist.count > 0 {
+						log.Printf("\tWRITE latency(ms)%s", wHist.format(percentiles))
+					}
+					if rHist.count > 0 {
+						log.Printf("\tREAD  latency(ms)%s", rHist.format(percentiles))
+					}
+				}
+
 				// reset stats
+				wRunHist.merge(wHist)
+				rRunHist.merge(rHist)
+				wHist.reset()
+				rHist.reset()
+
 				wTotalLat, rTotalLat = 0, 0
 				wMinLat, wMaxLat = 0, 0
 				rMinLat, rMaxLat = 0, 0
@@ -629,6 +684,7 @@ Loop:
 				lastReportTime = time.Now()
 
 				if stats.Exit {
+					printSummary(wRunHist, rRunHist, time.Since(runStart))
 					break Loop
 				}
 			}
@@ -637,6 +693,24 @@ Loop:
 	countReportChan <- &TStats{}
 }
 
+// prints the totals and the latency percentiles of the whole run
+func printSummary(wHist, rHist *latencyHistogram, duration time.Duration) {
+	log.Printf("summary: duration=%v write(count=%d tps=%d) read(count=%d tps=%d)",
+		duration-duration%time.Millisecond,
+		wHist.count, calcTPS(int(wHist.count), duration),
+		rHist.count, calcTPS(int(rHist.count), duration),
+	)
+
+	if len(percentiles) > 0 {
+		if wHist.count > 0 {
+			log.Printf("\tWRITE latency(ms)%s", wHist.format(percentiles))
+		}
+		if rHist.count > 0 {
+			log.Printf("\tREAD  latency(ms)%s", rHist.format(percentiles))
+		}
+	}
+}
+
 type XorRand struct {
 	src [2]uint64
 }
@@ -645,6 +719,10 @@ func NewXorRand() *XorRand {
 	return &XorRand{[2]uint64{uint64(time.Now().UnixNano()), uint64(time.Now().UnixNano())}}
 }
 
+func NewXorRandWithSeed(seed uint64) *XorRand {
+	return &XorRand{[2]uint64{seed, seed ^ 0x9E3779B97F4A7C15}}
+}
+
 func (r *XorRand) Int64() int64 {
 	return int64(r.Uint64())
 }
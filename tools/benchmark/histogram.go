@@ -0,0 +1,144 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	histSubBuckets = 16 // sub buckets per power of two; relative error < 1/16
+	histBuckets    = 64 * histSubBuckets
+)
+
+// latencyHistogram is a log-linear histogram of latencies in microseconds.
+// It is not safe for concurrent use.
+type latencyHistogram struct {
+	counts [histBuckets]int64
+	count  int64
+	max    int64
+}
+
+func newLatencyHistogram() *latencyHistogram {
+	return &latencyHistogram{}
+}
+
+func histIndex(v int64) int {
+	if v < histSubBuckets {
+		if v < 0 {
+			return 0
+		}
+		return int(v)
+	}
+
+	// shift the value until it fits in [histSubBuckets, 2*histSubBuckets)
+	exp := uint(0)
+	for (v >> exp) >= 2*histSubBuckets {
+		exp++
+	}
+	return int(exp+1)*histSubBuckets + int(v>>exp) - histSubBuckets
+}
+
+// histUpperBound returns the highest value stored in the bucket.
+func histUpperBound(index int) int64 {
+	if index < histSubBuckets {
+		return int64(index)
+	}
+	exp := uint(index/histSubBuckets - 1)
+	sub := int64(index%histSubBuckets + histSubBuckets)
+	return ((sub + 1) << exp) - 1
+}
+
+func (h *latencyHistogram) add(d time.Duration) {
+	v := int64(d / time.Microsecond)
+	h.counts[histIndex(v)]++
+	h.count++
+	if v > h.max {
+		h.max = v
+	}
+}
+
+func (h *latencyHistogram) merge(other *latencyHistogram) {
+	if other == nil {
+		return
+	}
+	for i := range other.counts {
+		h.counts[i] += other.counts[i]
+	}
+	h.count += other.count
+	if other.max > h.max {
+		h.max = other.max
+	}
+}
+
+func (h *latencyHistogram) reset() {
+	*h = latencyHistogram{}
+}
+
+// percentile returns the latency in microseconds which p percent of
+// the recorded latencies do not exceed.
+func (h *latencyHistogram) percentile(p float64) int64 {
+	if h.count == 0 {
+		return 0
+	}
+
+	target := int64(math.Ceil(p / 100 * float64(h.count)))
+	if target < 1 {
+		target = 1
+	}
+
+	var sum int64
+	for i := range h.counts {
+		if sum += h.counts[i]; sum >= target {
+			if v := histUpperBound(i); v < h.max {
+				return v
+			}
+			return h.max
+		}
+	}
+	return h.max
+}
+
+// format formats the requested percentiles in milliseconds.
+func (h *latencyHistogram) format(percentiles []float64) string {
+	var buf bytes.Buffer
+	for _, p := range percentiles {
+		buf.WriteString(fmt.Sprintf(" p%s=%.3f", strconv.FormatFloat(p, 'f', -1, 64), float64(h.percentile(p))/1000))
+	}
+	buf.WriteString(fmt.Sprintf(" max=%.3f", float64(h.max)/1000))
+	return buf.String()
+}
+
+// parsePercentiles parses a comma separated list of percentiles, e.g. `50,90,99,99.9`.
+func parsePercentiles(param string) ([]float64, error) {
+	var res []float64
+	for _, v := range strings.Split(param, ",") {
+		if v = strings.TrimSpace(v); v == "" {
+			continue
+		}
+
+		p, err := strconv.ParseFloat(v, 64)
+		if err != nil || p <= 0 || p > 100 {
+			return nil, fmt.Errorf("Invalid percentile: %s", v)
+		}
+		res = append(res, p)
+	}
+	return res, nil
+}
@@ -0,0 +1,229 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package policylint provides an analyzer flagging dangerous uses of
+// client policies in code using the Aerospike client:
+//
+//   - non-idempotent writes with a nil WritePolicy, which are retried
+//     by the default policy and may be applied twice,
+//   - scans in HTTP request handlers, which read whole sets per request,
+//   - modifications of shared policies: package-level policy variables
+//     and the default policies of the client, which may be in use by
+//     other goroutines.
+package policylint
+
+import (
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// clientPackage is the import path of the Aerospike client.
+const clientPackage = "github.com/THE108/aerospike-client-go"
+
+// Analyzer reports dangerous uses of client policies.
+var Analyzer = &analysis.Analyzer{
+	Name: "policylint",
+	Doc:  "check for dangerous uses of Aerospike client policies",
+	Run:  run,
+}
+
+// nonIdempotentWrites are client methods which change records relative
+// to their current state.
+var nonIdempotentWrites = map[string]bool{
+	"Add":            true,
+	"AddBins":        true,
+	"Append":         true,
+	"AppendBins":     true,
+	"Prepend":        true,
+	"PrependBins":    true,
+	"Operate":        true,
+	"OperateChunked": true,
+	"Execute":        true,
+}
+
+// scans are client methods reading whole sets.
+var scans = map[string]bool{
+	"ScanAll":  true,
+	"ScanNode": true,
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	for _, file := range pass.Files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.CallExpr:
+				checkNilWritePolicy(pass, n)
+			case *ast.FuncDecl:
+				if n.Body != nil && isRequestHandler(pass, n.Type) {
+					checkScans(pass, n.Body)
+				}
+			case *ast.FuncLit:
+				if isRequestHandler(pass, n.Type) {
+					checkScans(pass, n.Body)
+				}
+			case *ast.AssignStmt:
+				for _, lhs := range n.Lhs {
+					checkSharedPolicy(pass, lhs)
+				}
+			case *ast.IncDecStmt:
+				checkSharedPolicy(pass, n.X)
+			}
+			return true
+		})
+	}
+	return nil, nil
+}
+
+// checkNilWritePolicy reports non-idempotent writes called with a nil policy.
+func checkNilWritePolicy(pass *analysis.Pass, call *ast.CallExpr) {
+	method, ok := clientMethod(pass, call)
+	if !ok || !nonIdempotentWrites[method] || len(call.Args) == 0 {
+		return
+	}
+
+	if tv, ok := pass.TypesInfo.Types[call.Args[0]]; ok && tv.IsNil() {
+		pass.Reportf(call.Pos(), "%s with a nil WritePolicy is retried by the default policy and may be applied twice; pass a WritePolicy with MaxRetries = 0", method)
+	}
+}
+
+// checkScans reports scans in the body of a request handler.
+func checkScans(pass *analysis.Pass, body *ast.BlockStmt) {
+	ast.Inspect(body, func(n ast.Node) bool {
+		// nested handlers are checked on their own
+		if fn, ok := n.(*ast.FuncLit); ok && isRequestHandler(pass, fn.Type) {
+			return false
+		}
+
+		if call, ok := n.(*ast.CallExpr); ok {
+			if method, ok := clientMethod(pass, call); ok && scans[method] {
+				pass.Reportf(call.Pos(), "%s in an HTTP request handler reads the whole set on every request; the number of scanned records cannot be limited", method)
+			}
+		}
+		return true
+	})
+}
+
+// checkSharedPolicy reports assignments to fields of shared policies.
+func checkSharedPolicy(pass *analysis.Pass, lhs ast.Expr) {
+	sel, ok := lhs.(*ast.SelectorExpr)
+	if !ok || !isPolicy(pass.TypesInfo.TypeOf(sel.X)) {
+		return
+	}
+
+	if name, shared := sharedPolicy(pass, sel.X); shared {
+		pass.Reportf(lhs.Pos(), "modifying the shared policy %s, which may be in use by other goroutines; change a copy of the policy instead", name)
+	}
+}
+
+// sharedPolicy determines if the policy expression refers to a package-level
+// variable or to a default policy of a client, and returns its name.
+func sharedPolicy(pass *analysis.Pass, expr ast.Expr) (string, bool) {
+	for {
+		switch e := expr.(type) {
+		case *ast.ParenExpr:
+			expr = e.X
+			continue
+		case *ast.StarExpr:
+			expr = e.X
+			continue
+		case *ast.Ident:
+			v, ok := pass.TypesInfo.Uses[e].(*types.Var)
+			return e.Name, ok && isPackageLevel(v)
+		case *ast.SelectorExpr:
+			// qualified package-level variable
+			if x, ok := e.X.(*ast.Ident); ok {
+				if _, ok := pass.TypesInfo.Uses[x].(*types.PkgName); ok {
+					v, ok := pass.TypesInfo.Uses[e.Sel].(*types.Var)
+					return x.Name + "." + e.Sel.Name, ok && isPackageLevel(v)
+				}
+			}
+
+			// default policies of the client
+			if isClient(pass.TypesInfo.TypeOf(e.X)) {
+				return e.Sel.Name, strings.HasPrefix(e.Sel.Name, "Default")
+			}
+
+			// embedded or nested policies
+			if isPolicy(pass.TypesInfo.TypeOf(e.X)) {
+				expr = e.X
+				continue
+			}
+		}
+		return "", false
+	}
+}
+
+func isPackageLevel(v *types.Var) bool {
+	return v.Pkg() != nil && v.Parent() == v.Pkg().Scope()
+}
+
+// clientMethod returns the name of the client method called, if any.
+func clientMethod(pass *analysis.Pass, call *ast.CallExpr) (string, bool) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return "", false
+	}
+
+	selection, ok := pass.TypesInfo.Selections[sel]
+	if !ok || selection.Kind() != types.MethodVal || !isClient(selection.Recv()) {
+		return "", false
+	}
+	return sel.Sel.Name, true
+}
+
+// isRequestHandler determines if the function has the signature of an
+// http.HandlerFunc.
+func isRequestHandler(pass *analysis.Pass, fn *ast.FuncType) bool {
+	if fn.Params == nil {
+		return false
+	}
+
+	var hasWriter, hasRequest bool
+	for _, field := range fn.Params.List {
+		switch typeName(pass.TypesInfo.TypeOf(field.Type)) {
+		case "net/http.ResponseWriter":
+			hasWriter = true
+		case "net/http.Request":
+			hasRequest = true
+		}
+	}
+	return hasWriter && hasRequest
+}
+
+func isClient(t types.Type) bool {
+	name := typeName(t)
+	return name == clientPackage+".Client" || name == clientPackage+".ClientIface"
+}
+
+func isPolicy(t types.Type) bool {
+	name := typeName(t)
+	return strings.HasPrefix(name, clientPackage+".") && strings.HasSuffix(name, "Policy")
+}
+
+// typeName returns the qualified name of the named type, or of the type
+// it points to.
+func typeName(t types.Type) string {
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+
+	named, ok := t.(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return ""
+	}
+	return named.Obj().Pkg().Path() + "." + named.Obj().Name()
+}
@@ -0,0 +1,35 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policylint_test
+
+import (
+	"testing"
+
+	"github.com/THE108/aerospike-client-go/tools/policylint"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestNilWritePolicy(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), policylint.Analyzer, "writes")
+}
+
+func TestScansInRequestHandlers(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), policylint.Analyzer, "scans")
+}
+
+func TestSharedPolicies(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), policylint.Analyzer, "shared")
+}
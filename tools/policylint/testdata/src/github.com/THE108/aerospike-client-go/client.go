@@ -0,0 +1,54 @@
+// Package aerospike is a stub of the client API used by the analyzer tests.
+package aerospike
+
+type BinMap map[string]interface{}
+
+type Key struct{}
+
+type Operation struct{}
+
+type Record struct{}
+
+type Recordset struct{}
+
+type BasePolicy struct {
+	MaxRetries int
+}
+
+type WritePolicy struct {
+	BasePolicy
+	Expiration uint32
+}
+
+type ScanPolicy struct {
+	BasePolicy
+	ConcurrentNodes bool
+}
+
+func NewWritePolicy(generation, expiration uint32) *WritePolicy {
+	return &WritePolicy{Expiration: expiration}
+}
+
+type Client struct {
+	DefaultPolicy      *BasePolicy
+	DefaultWritePolicy *WritePolicy
+	DefaultScanPolicy  *ScanPolicy
+}
+
+func (clnt *Client) Put(policy *WritePolicy, key *Key, binMap BinMap) error { return nil }
+
+func (clnt *Client) Add(policy *WritePolicy, key *Key, binMap BinMap) error { return nil }
+
+func (clnt *Client) Append(policy *WritePolicy, key *Key, binMap BinMap) error { return nil }
+
+func (clnt *Client) Operate(policy *WritePolicy, key *Key, operations ...*Operation) (*Record, error) {
+	return nil, nil
+}
+
+func (clnt *Client) Get(policy *BasePolicy, key *Key, binNames ...string) (*Record, error) {
+	return nil, nil
+}
+
+func (clnt *Client) ScanAll(policy *ScanPolicy, namespace string, setName string, binNames ...string) (*Recordset, error) {
+	return nil, nil
+}
@@ -0,0 +1,8 @@
+package policies
+
+import (
+	as "github.com/THE108/aerospike-client-go"
+)
+
+// Write is a policy shared by the packages of the application.
+var Write = as.NewWritePolicy(0, 0)
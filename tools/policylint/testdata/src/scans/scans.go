@@ -0,0 +1,35 @@
+package scans
+
+import (
+	"net/http"
+
+	as "github.com/THE108/aerospike-client-go"
+)
+
+var client *as.Client
+
+func handler(w http.ResponseWriter, r *http.Request) {
+	client.ScanAll(nil, "test", "users") // want "ScanAll in an HTTP request handler reads the whole set"
+}
+
+func routes(mux *http.ServeMux) {
+	mux.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+		client.ScanAll(nil, "test", "users") // want "ScanAll in an HTTP request handler reads the whole set"
+	})
+
+	// scans outside of handlers are fine
+	client.ScanAll(nil, "test", "users")
+}
+
+func nested(w http.ResponseWriter, r *http.Request) {
+	// the nested handler is reported once
+	http.HandleFunc("/all", func(w http.ResponseWriter, r *http.Request) {
+		client.ScanAll(nil, "test", "all") // want "ScanAll in an HTTP request handler reads the whole set"
+	})
+
+	client.Get(nil, nil)
+}
+
+func export() {
+	client.ScanAll(nil, "test", "users")
+}
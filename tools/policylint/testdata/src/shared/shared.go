@@ -0,0 +1,29 @@
+package shared
+
+import (
+	"policies"
+
+	as "github.com/THE108/aerospike-client-go"
+)
+
+var writePolicy = as.NewWritePolicy(0, 0)
+
+var retries int
+
+func configure(client *as.Client) {
+	writePolicy.Expiration = 10                         // want "modifying the shared policy writePolicy"
+	writePolicy.MaxRetries++                            // want "modifying the shared policy writePolicy"
+	policies.Write.Expiration = 10                      // want "modifying the shared policy policies.Write"
+	client.DefaultWritePolicy.MaxRetries = 0            // want "modifying the shared policy DefaultWritePolicy"
+	client.DefaultWritePolicy.BasePolicy.MaxRetries = 0 // want "modifying the shared policy DefaultWritePolicy"
+	client.DefaultScanPolicy.ConcurrentNodes = false    // want "modifying the shared policy DefaultScanPolicy"
+
+	// copies of shared policies may be changed
+	policy := *client.DefaultWritePolicy
+	policy.MaxRetries = 0
+	local := as.NewWritePolicy(0, 0)
+	local.Expiration = 10
+
+	// other package-level variables are not policies
+	retries = 3
+}
@@ -0,0 +1,19 @@
+package writes
+
+import (
+	as "github.com/THE108/aerospike-client-go"
+)
+
+func writes(client *as.Client, key *as.Key, policy *as.WritePolicy) {
+	client.Add(nil, key, as.BinMap{"a": 1})      // want "Add with a nil WritePolicy is retried"
+	client.Append(nil, key, as.BinMap{"a": "b"}) // want "Append with a nil WritePolicy is retried"
+	client.Operate(nil, key)                     // want "Operate with a nil WritePolicy is retried"
+
+	// idempotent writes and reads may be retried
+	client.Put(nil, key, as.BinMap{"a": 1})
+	client.Get(nil, key)
+
+	// non-idempotent writes with a policy
+	client.Add(policy, key, as.BinMap{"a": 1})
+	client.Operate(as.NewWritePolicy(0, 0), key)
+}
@@ -0,0 +1,60 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	. "github.com/THE108/aerospike-client-go/types"
+)
+
+// Truncate removes the records of the set last updated before
+// beforeLastUpdate, or all its records if it is nil. If the set name is
+// empty, the records of the whole namespace are removed, which needs all
+// nodes to support FeatureTruncateNamespace; an UNSUPPORTED_FEATURE error
+// is returned otherwise.
+// The command is sent to one node, which distributes it to the others.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *Client) Truncate(policy *InfoPolicy, namespace, setName string, beforeLastUpdate *time.Time) error {
+	policy = clnt.getUsableInfoPolicy(policy)
+
+	command := "truncate:namespace=" + namespace + ";set=" + setName
+	if setName == "" {
+		if err := clnt.cluster.requireFeature(FeatureTruncateNamespace); err != nil {
+			return err
+		}
+		command = "truncate-namespace:namespace=" + namespace
+	}
+	if beforeLastUpdate != nil {
+		command += ";lut=" + strconv.FormatInt(beforeLastUpdate.UnixNano(), 10)
+	}
+
+	node, err := clnt.cluster.GetRandomNode()
+	if err != nil {
+		return err
+	}
+
+	infoMap, err := RequestNodeInfoWithPolicy(policy, node, command)
+	if err != nil {
+		return err
+	}
+
+	if response := infoMap[command]; strings.ToLower(strings.TrimSpace(response)) != "ok" {
+		return NewAerospikeError(SERVER_ERROR, "Truncate failed: "+response)
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"time"
+
+	. "github.com/THE108/aerospike-client-go/types"
+)
+
+// TTLAuditPolicy encapsulates parameters for auditing the TTL of records.
+type TTLAuditPolicy struct {
+	// ScanPolicy is used to scan the records. Bin data is never requested.
+	ScanPolicy
+
+	// BucketWidth is the TTL range of each bucket of the report. Default is 1 day.
+	BucketWidth time.Duration
+
+	// Buckets is the number of buckets of the report. Records with a longer
+	// TTL are counted in the last bucket. Default is 30.
+	Buckets int
+
+	// ExpiringWithin is the TTL below which records are counted as expiring
+	// soon. Default is 7 days.
+	ExpiringWithin time.Duration
+}
+
+// NewTTLAuditPolicy generates a new TTLAuditPolicy instance with default values.
+func NewTTLAuditPolicy() *TTLAuditPolicy {
+	return &TTLAuditPolicy{
+		ScanPolicy:     *NewScanPolicy(),
+		BucketWidth:    24 * time.Hour,
+		Buckets:        30,
+		ExpiringWithin: 7 * 24 * time.Hour,
+	}
+}
+
+// Clone returns a deep copy of the policy, including its ScanPolicy.
+func (p *TTLAuditPolicy) Clone() *TTLAuditPolicy {
+	res := *p
+	res.ScanPolicy = *p.ScanPolicy.Clone()
+	return &res
+}
+
+// TTLReport is the distribution of record TTLs in a set.
+type TTLReport struct {
+	Namespace string
+	Set       string
+
+	// Records is the number of records in the set.
+	Records int64
+
+	// NeverExpire is the number of records without expiration.
+	NeverExpire int64
+
+	// Expired is the number of records past their expiration which
+	// were not removed by the server yet. They are not in any bucket.
+	Expired int64
+
+	// Expiring is the number of records expiring within the
+	// ExpiringWithin duration of the policy.
+	Expiring int64
+
+	// BucketWidth is the TTL range of each bucket.
+	BucketWidth time.Duration
+
+	// Buckets contain the number of expiring records in each TTL range.
+	// Bucket i contains records with a TTL in [i*BucketWidth, (i+1)*BucketWidth);
+	// the last bucket also contains all records with a longer TTL.
+	Buckets []int64
+}
+
+// ttlAuditor collects TTL reports by set.
+type ttlAuditor struct {
+	policy    *TTLAuditPolicy
+	namespace string
+	reports   map[string]*TTLReport
+}
+
+func newTTLAuditor(policy *TTLAuditPolicy, namespace string) *ttlAuditor {
+	return &ttlAuditor{
+		policy:    policy,
+		namespace: namespace,
+		reports:   map[string]*TTLReport{},
+	}
+}
+
+// report returns the report of the set, creating it if needed.
+func (ta *ttlAuditor) report(setName string) *TTLReport {
+	report := ta.reports[setName]
+	if report == nil {
+		report = &TTLReport{
+			Namespace:   ta.namespace,
+			Set:         setName,
+			BucketWidth: ta.policy.BucketWidth,
+			Buckets:     make([]int64, ta.policy.Buckets),
+		}
+		ta.reports[setName] = report
+	}
+	return report
+}
+
+func (ta *ttlAuditor) add(setName string, expiration int) {
+	report := ta.report(setName)
+	report.Records++
+
+	switch {
+	case expiration == 0:
+		report.NeverExpire++
+		return
+	case expiration < 0:
+		report.Expired++
+		return
+	}
+
+	ttl := time.Duration(expiration) * time.Second
+	if ttl < ta.policy.ExpiringWithin {
+		report.Expiring++
+	}
+
+	bucket := int(ttl / ta.policy.BucketWidth)
+	if bucket >= len(report.Buckets) {
+		bucket = len(report.Buckets) - 1
+	}
+	report.Buckets[bucket]++
+}
+
+// AuditTTL scans the record headers of the sets and reports the distribution
+// of their TTLs, keyed by set name. If no sets are specified, the whole
+// namespace is scanned and reported for each set found.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *Client) AuditTTL(policy *TTLAuditPolicy, namespace string, setNames ...string) (map[string]*TTLReport, error) {
+	if policy == nil {
+		policy = NewTTLAuditPolicy()
+	}
+	if policy.BucketWidth <= 0 || policy.Buckets <= 0 {
+		return nil, NewAerospikeError(PARAMETER_ERROR, "TTL audit requires a positive bucket width and count.")
+	}
+
+	scanPolicy := policy.ScanPolicy
+	scanPolicy.IncludeBinData = false
+
+	if len(setNames) == 0 {
+		setNames = []string{""}
+	}
+
+	auditor := newTTLAuditor(policy, namespace)
+	for _, setName := range setNames {
+		recordset, err := clnt.ScanAll(&scanPolicy, namespace, setName)
+		if err != nil {
+			return nil, err
+		}
+
+		for res := range recordset.Results() {
+			if res.Err != nil {
+				recordset.Close()
+				return nil, res.Err
+			}
+			auditor.add(res.Record.Key.SetName(), res.Record.Expiration)
+		}
+
+		// make sure requested sets are reported, even if they are empty
+		if setName != "" {
+			auditor.report(setName)
+		}
+	}
+
+	return auditor.reports, nil
+}
@@ -0,0 +1,56 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TTL Audit Test", func() {
+
+	const day = 24 * 60 * 60
+
+	It("should report the TTL distribution per set", func() {
+		policy := NewTTLAuditPolicy()
+		policy.Buckets = 4
+		policy.ExpiringWithin = 2 * 24 * time.Hour
+
+		auditor := newTTLAuditor(policy, "test")
+		auditor.add("a", 0)
+		auditor.add("a", -100)
+		auditor.add("a", day/2)
+		auditor.add("a", day+1)
+		auditor.add("a", 3*day)
+		auditor.add("a", 100*day)
+		auditor.add("b", day)
+		auditor.report("c")
+
+		a := auditor.reports["a"]
+		Expect(a.Namespace).To(Equal("test"))
+		Expect(a.Records).To(Equal(int64(6)))
+		Expect(a.NeverExpire).To(Equal(int64(1)))
+		Expect(a.Expired).To(Equal(int64(1)))
+		Expect(a.Expiring).To(Equal(int64(2)))
+		Expect(a.BucketWidth).To(Equal(24 * time.Hour))
+		Expect(a.Buckets).To(Equal([]int64{1, 1, 0, 2}))
+
+		Expect(auditor.reports["b"].Buckets).To(Equal([]int64{0, 1, 0, 0}))
+		Expect(auditor.reports["c"].Records).To(Equal(int64(0)))
+	})
+
+})
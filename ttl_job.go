@@ -0,0 +1,70 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+const (
+	ttlUDFPackage  = "as_client_ttl"
+	ttlUDFFunction = "set_ttl"
+)
+
+// ttlUDF resets the TTL of existing records only; records that expired or
+// were deleted since the job started are not recreated.
+const ttlUDF = `
+function set_ttl(rec, ttl)
+  if aerospike:exists(rec) then
+    record.set_ttl(rec, ttl)
+    aerospike:update(rec)
+  end
+end
+`
+
+// UpdateTTL starts a background job on the server which resets the TTL of
+// all records selected by the statement to ttl seconds.
+// Use the statement's Filters and PredExp to select the records, eg: to
+// extend the TTL of active users only. Records are not sent to the client.
+//
+// The job is implemented by a small UDF package which is registered on the
+// server on first use. The returned ExecuteTask can be used to wait for the
+// job to finish and to track its progress.
+//
+// This method is only supported by Aerospike 3 servers.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *Client) UpdateTTL(policy *QueryPolicy, statement *Statement, ttl uint32) (*ExecuteTask, error) {
+	if err := clnt.registerTTLUDF(); err != nil {
+		return nil, err
+	}
+
+	return clnt.ExecuteUDF(policy, statement, ttlUDFPackage, ttlUDFFunction, NewValue(int64(ttl)))
+}
+
+// registerTTLUDF makes sure the TTL UDF package is registered on the cluster.
+func (clnt *Client) registerTTLUDF() error {
+	udfs, err := clnt.ListUDF(nil)
+	if err != nil {
+		return err
+	}
+
+	for _, udf := range udfs {
+		if udf.Filename == ttlUDFPackage+".lua" {
+			return nil
+		}
+	}
+
+	task, err := clnt.RegisterUDF(nil, []byte(ttlUDF), ttlUDFPackage+".lua", LUA)
+	if err != nil {
+		return err
+	}
+	return <-task.OnComplete()
+}
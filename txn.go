@@ -0,0 +1,362 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"sync"
+
+	. "github.com/THE108/aerospike-client-go/types"
+)
+
+// TxnState is the state of a multi-record transaction.
+type TxnState int
+
+const (
+	// TXN_OPEN indicates commands can be added to the transaction.
+	TXN_OPEN TxnState = iota
+
+	// TXN_VERIFIED indicates the reads of the transaction were verified
+	// and its writes are being committed.
+	TXN_VERIFIED
+
+	// TXN_COMMITTED indicates the transaction was committed.
+	TXN_COMMITTED
+
+	// TXN_ABORTED indicates the transaction was aborted.
+	TXN_ABORTED
+)
+
+// TxnMonitorSetName is the set of the monitor records, in which the server
+// tracks the records written by a transaction until it is committed or aborted.
+const TxnMonitorSetName = "<ERO~MRT"
+
+const (
+	txnMonitorIDBin      = "id"
+	txnMonitorDigestsBin = "keyds"
+	txnMonitorForwardBin = "fwd"
+)
+
+// txnRead is a record read in a transaction, along with its version.
+type txnRead struct {
+	key     *Key
+	version uint64
+}
+
+// Txn is a multi-record transaction (MRT) coordinated by the server.
+// Attach it to the Txn field of the policies of single record commands;
+// the records they read and write participate in the transaction until
+// it is finished with Client.Commit or Client.Abort.
+//
+// Written records are locked by the server until the transaction is
+// finished; other commands on them fail with MRT_BLOCKED. The versions of
+// the records read are verified on commit, which fails if any of them has
+// changed in the meantime. If the client dies before the transaction is
+// finished, the server rolls it back once its deadline has passed.
+//
+// All records of a transaction must belong to the same namespace.
+// Batch, scan and query commands can not be part of a transaction.
+// Requires servers with multi-record transaction support, and namespaces
+// in strong consistency mode. Txn is safe for concurrent use.
+type Txn struct {
+	id int64
+
+	mutex     sync.Mutex
+	namespace string
+	state     TxnState
+	deadline  int32
+	reads     map[string]txnRead
+	writes    map[string]*Key
+}
+
+// NewTxn returns a new transaction with a random id.
+func NewTxn() *Txn {
+	var b [8]byte
+	for {
+		// the server reserves 0 for no transaction
+		rand.Read(b[:])
+		if id := int64(binary.LittleEndian.Uint64(b[:])); id != 0 {
+			return &Txn{
+				id:     id,
+				reads:  map[string]txnRead{},
+				writes: map[string]*Key{},
+			}
+		}
+	}
+}
+
+// ID returns the id of the transaction.
+func (txn *Txn) ID() int64 { return txn.id }
+
+// State returns the state of the transaction.
+func (txn *Txn) State() TxnState {
+	txn.mutex.Lock()
+	defer txn.mutex.Unlock()
+	return txn.state
+}
+
+// Namespace returns the namespace of the transaction, or an empty string
+// before the first command.
+func (txn *Txn) Namespace() string {
+	txn.mutex.Lock()
+	defer txn.mutex.Unlock()
+	return txn.namespace
+}
+
+// monitorKey returns the key of the monitor record of the transaction.
+func (txn *Txn) monitorKey() (*Key, error) {
+	return NewKey(txn.namespace, TxnMonitorSetName, txn.id)
+}
+
+// readVersion returns the version of the record read in the transaction, if any.
+func (txn *Txn) readVersion(key *Key) (uint64, bool) {
+	txn.mutex.Lock()
+	defer txn.mutex.Unlock()
+	r, exists := txn.reads[string(key.digest)]
+	return r.version, exists
+}
+
+func (txn *Txn) getDeadline() int32 {
+	txn.mutex.Lock()
+	defer txn.mutex.Unlock()
+	return txn.deadline
+}
+
+// prepare checks the command can join the transaction. Before the first
+// write of a record, the record is added to the monitor record, so the
+// server can roll the write back if the transaction is never finished.
+func (txn *Txn) prepare(ifc command) error {
+	cmd, ok := ifc.(txnCommand)
+	if !ok {
+		return NewAerospikeError(PARAMETER_ERROR, "Only single record commands can be part of a transaction")
+	}
+	key := cmd.getKey()
+
+	txn.mutex.Lock()
+	if txn.state != TXN_OPEN {
+		txn.mutex.Unlock()
+		return NewAerospikeError(TXN_FAILED, "Transaction is not open")
+	}
+	if txn.namespace == "" {
+		txn.namespace = key.namespace
+	} else if txn.namespace != key.namespace {
+		txn.mutex.Unlock()
+		return NewAerospikeError(PARAMETER_ERROR, "Transaction namespace `"+txn.namespace+"` does not match the key namespace `"+key.namespace+"`")
+	}
+	_, written := txn.writes[string(key.digest)]
+	firstWrite := len(txn.writes) == 0
+	txn.mutex.Unlock()
+
+	if !isWriteCommand(ifc) || written {
+		return nil
+	}
+
+	policy := *ifc.getPolicy(ifc).GetBasePolicy()
+	policy.Txn = nil
+	return txn.addMonitorKey(cmd.getCluster(), &policy, key, firstWrite)
+}
+
+// addMonitorKey adds the digest of the key to the monitor record, and
+// keeps the deadline of the transaction assigned by the server.
+func (txn *Txn) addMonitorKey(cluster *Cluster, policy *BasePolicy, key *Key, firstWrite bool) error {
+	monitorKey, err := txn.monitorKey()
+	if err != nil {
+		return err
+	}
+
+	ops := make([]*Operation, 0, 2)
+	if firstWrite {
+		ops = append(ops, PutOp(NewBin(txnMonitorIDBin, txn.id)))
+	}
+	// append to an ordered list, ignoring digests already in the list
+	ops = append(ops, newCDTOperation(CDT_MODIFY, txnMonitorDigestsBin, _CDT_LIST_APPEND, key.digest, _CDT_LIST_ORDERED, _CDT_LIST_ADD_UNIQUE|_CDT_LIST_NO_FAIL))
+
+	writePolicy := NewWritePolicy(0, 0)
+	writePolicy.BasePolicy = *policy
+
+	cmd := newOperateCommand(cluster, writePolicy, monitorKey, ops)
+	if err := cmd.Execute(); err != nil {
+		return err
+	}
+
+	if cmd.txnDeadline != 0 {
+		txn.mutex.Lock()
+		txn.deadline = cmd.txnDeadline
+		txn.mutex.Unlock()
+	}
+	return nil
+}
+
+// onResult tracks the record of a finished command in the transaction.
+func (txn *Txn) onResult(ifc command, err error) {
+	cmd, ok := ifc.(txnCommand)
+	if !ok {
+		return
+	}
+	// the caller may reuse the key for other records with SetValue,
+	// so the transaction keeps its own copy
+	key, version := cmd.getKey().clone(), cmd.getRecordVersion()
+	digest := string(key.digest)
+
+	txn.mutex.Lock()
+	defer txn.mutex.Unlock()
+
+	switch {
+	case !isWriteCommand(ifc):
+		if err == nil && version != nil {
+			txn.reads[digest] = txnRead{key: key, version: *version}
+		}
+	case err == nil && version != nil:
+		// the server did not apply the write, but reports the version it holds
+		txn.reads[digest] = txnRead{key: key, version: *version}
+	case err == nil || isInDoubt(err):
+		delete(txn.reads, digest)
+		txn.writes[digest] = key
+	}
+}
+
+// Commit verifies the versions of the records read in the transaction,
+// and commits its writes. If a read record has changed since it was read,
+// the transaction is aborted and an MRT_VERSION_MISMATCH error is returned.
+//
+// Once the transaction is marked as committed on the server, Commit can
+// not fail anymore: if rolling forward the writes or removing the monitor
+// record fails, the server completes the commit on its own and the error
+// is only returned for information, with the transaction in TXN_COMMITTED state.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *Client) Commit(policy *WritePolicy, txn *Txn) error {
+	policy = clnt.getUsableWritePolicy(policy)
+
+	txn.mutex.Lock()
+	switch txn.state {
+	case TXN_COMMITTED:
+		txn.mutex.Unlock()
+		return nil
+	case TXN_ABORTED:
+		txn.mutex.Unlock()
+		return NewAerospikeError(TXN_FAILED, "Transaction was already aborted")
+	}
+	reads := make([]txnRead, 0, len(txn.reads))
+	for _, r := range txn.reads {
+		reads = append(reads, r)
+	}
+	writes := txn.writeKeys()
+	verified := txn.state == TXN_VERIFIED
+	txn.state = TXN_VERIFIED
+	txn.mutex.Unlock()
+
+	if !verified {
+		for _, r := range reads {
+			cmd := newTxnRecordCommand(clnt.cluster, policy, txn, r.key, txnVerify)
+			cmd.version = r.version
+			if err := cmd.Execute(); err != nil {
+				clnt.abort(policy, txn, writes)
+
+				resultCode := TXN_FAILED
+				if !isInDoubt(err) {
+					resultCode = MRT_VERSION_MISMATCH
+				}
+				return NewAerospikeError(resultCode, "Transaction aborted; failed to verify the read of a record: "+err.Error())
+			}
+		}
+	}
+
+	if len(writes) == 0 {
+		txn.setState(TXN_COMMITTED)
+		return nil
+	}
+
+	monitorKey, err := txn.monitorKey()
+	if err != nil {
+		return err
+	}
+
+	// from here on, the server rolls the transaction forward if the client fails
+	if err := newTxnRecordCommand(clnt.cluster, policy, txn, monitorKey, txnMarkRollForward).Execute(); err != nil {
+		if isInDoubt(err) {
+			// the transaction may already be marked; Commit can be called again
+			return err
+		}
+		clnt.abort(policy, txn, writes)
+		return NewAerospikeError(TXN_FAILED, "Transaction aborted; failed to mark it as committed: "+err.Error())
+	}
+	txn.setState(TXN_COMMITTED)
+
+	return clnt.rollTxn(policy, txn, writes, txnRollForward)
+}
+
+// Abort rolls back the writes of the transaction.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *Client) Abort(policy *WritePolicy, txn *Txn) error {
+	policy = clnt.getUsableWritePolicy(policy)
+
+	txn.mutex.Lock()
+	switch txn.state {
+	case TXN_ABORTED:
+		txn.mutex.Unlock()
+		return nil
+	case TXN_COMMITTED:
+		txn.mutex.Unlock()
+		return NewAerospikeError(TXN_FAILED, "Transaction was already committed")
+	}
+	writes := txn.writeKeys()
+	txn.mutex.Unlock()
+
+	return clnt.abort(policy, txn, writes)
+}
+
+func (clnt *Client) abort(policy *WritePolicy, txn *Txn, writes []*Key) error {
+	txn.setState(TXN_ABORTED)
+	if len(writes) == 0 {
+		return nil
+	}
+	return clnt.rollTxn(policy, txn, writes, txnRollBack)
+}
+
+// rollTxn rolls the writes forward or back, and removes the monitor record.
+func (clnt *Client) rollTxn(policy *WritePolicy, txn *Txn, writes []*Key, kind txnCommandKind) error {
+	var firstErr error
+	for _, key := range writes {
+		if err := newTxnRecordCommand(clnt.cluster, policy, txn, key, kind).Execute(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	// keep the monitor record for the server to finish the transaction
+	if firstErr != nil {
+		return NewAerospikeError(TXN_FAILED, "Transaction finished, but its records could not all be released; the server releases them after the deadline: "+firstErr.Error())
+	}
+
+	monitorKey, err := txn.monitorKey()
+	if err != nil {
+		return err
+	}
+	return newTxnRecordCommand(clnt.cluster, policy, txn, monitorKey, txnClose).Execute()
+}
+
+func (txn *Txn) writeKeys() []*Key {
+	res := make([]*Key, 0, len(txn.writes))
+	for _, key := range txn.writes {
+		res = append(res, key)
+	}
+	return res
+}
+
+func (txn *Txn) setState(state TxnState) {
+	txn.mutex.Lock()
+	txn.state = state
+	txn.mutex.Unlock()
+}
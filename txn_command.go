@@ -0,0 +1,292 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"encoding/binary"
+
+	. "github.com/THE108/aerospike-client-go/types"
+	Buffer "github.com/THE108/aerospike-client-go/utils/buffer"
+)
+
+// txnCommand is implemented by the single record commands,
+// which can be part of a transaction.
+type txnCommand interface {
+	getCluster() *Cluster
+	getKey() *Key
+	getRecordVersion() *uint64
+}
+
+func (cmd *singleCommand) getKey() *Key {
+	return cmd.key
+}
+
+func (cmd *singleCommand) getRecordVersion() *uint64 {
+	return cmd.recordVersion
+}
+
+const (
+	_MRT_ID_SIZE         = 8
+	_RECORD_VERSION_SIZE = 7
+	_MRT_DEADLINE_SIZE   = 4
+)
+
+// parseTxnFields reads the record version and transaction deadline from the
+// fields of a response, which start at the beginning of dataBuffer.
+func (cmd *singleCommand) parseTxnFields(fieldCount int) {
+	cmd.recordVersion = nil
+
+	offset := 0
+	for i := 0; i < fieldCount; i++ {
+		if offset+int(_FIELD_HEADER_SIZE) > len(cmd.dataBuffer) {
+			return
+		}
+		size := int(Buffer.BytesToUint32(cmd.dataBuffer, offset)) - 1
+		ftype := FieldType(cmd.dataBuffer[offset+4])
+		offset += int(_FIELD_HEADER_SIZE)
+		if size < 0 || offset+size > len(cmd.dataBuffer) {
+			return
+		}
+
+		switch {
+		case ftype == RECORD_VERSION && size == _RECORD_VERSION_SIZE:
+			version := versionBytesToUint64(cmd.dataBuffer[offset:])
+			cmd.recordVersion = &version
+		case ftype == MRT_DEADLINE && size == _MRT_DEADLINE_SIZE:
+			cmd.txnDeadline = int32(binary.LittleEndian.Uint32(cmd.dataBuffer[offset:]))
+		}
+		offset += size
+	}
+}
+
+// versionBytesToUint64 decodes a 7 byte little endian record version.
+func versionBytesToUint64(b []byte) uint64 {
+	var res uint64
+	for i := _RECORD_VERSION_SIZE - 1; i >= 0; i-- {
+		res = res<<8 | uint64(b[i])
+	}
+	return res
+}
+
+// txnFields are the transaction fields sent with a command.
+type txnFields struct {
+	txn      *Txn
+	version  *uint64
+	deadline int32
+}
+
+func (f *txnFields) count() int {
+	if f.txn == nil {
+		return 0
+	}
+
+	count := 1
+	if f.version != nil {
+		count++
+	}
+	if f.deadline != 0 {
+		count++
+	}
+	return count
+}
+
+// estimateTxnSize sizes the transaction fields of a command on the key:
+// the transaction id, the version of the record if it was read in the
+// transaction, and the deadline of the transaction for writes.
+func (cmd *baseCommand) estimateTxnSize(policy *BasePolicy, key *Key, write bool) txnFields {
+	f := txnFields{txn: policy.Txn}
+	if f.txn == nil {
+		return f
+	}
+
+	cmd.dataOffset += _MRT_ID_SIZE + int(_FIELD_HEADER_SIZE)
+	if version, exists := f.txn.readVersion(key); exists {
+		f.version = &version
+		cmd.dataOffset += _RECORD_VERSION_SIZE + int(_FIELD_HEADER_SIZE)
+	}
+	if write {
+		if f.deadline = f.txn.getDeadline(); f.deadline != 0 {
+			cmd.dataOffset += _MRT_DEADLINE_SIZE + int(_FIELD_HEADER_SIZE)
+		}
+	}
+	return f
+}
+
+func (cmd *baseCommand) writeTxn(f txnFields) {
+	if f.txn == nil {
+		return
+	}
+
+	cmd.writeTxnID(f.txn)
+	if f.version != nil {
+		cmd.writeRecordVersion(*f.version)
+	}
+	if f.deadline != 0 {
+		cmd.writeFieldHeader(_MRT_DEADLINE_SIZE, MRT_DEADLINE)
+		binary.LittleEndian.PutUint32(cmd.dataBuffer[cmd.dataOffset:], uint32(f.deadline))
+		cmd.dataOffset += _MRT_DEADLINE_SIZE
+	}
+}
+
+func (cmd *baseCommand) writeTxnID(txn *Txn) {
+	cmd.writeFieldHeader(_MRT_ID_SIZE, MRT_ID)
+	binary.LittleEndian.PutUint64(cmd.dataBuffer[cmd.dataOffset:], uint64(txn.id))
+	cmd.dataOffset += _MRT_ID_SIZE
+}
+
+func (cmd *baseCommand) writeRecordVersion(version uint64) {
+	cmd.writeFieldHeader(_RECORD_VERSION_SIZE, RECORD_VERSION)
+	for i := 0; i < _RECORD_VERSION_SIZE; i++ {
+		cmd.dataBuffer[cmd.dataOffset+i] = byte(version >> uint(8*i))
+	}
+	cmd.dataOffset += _RECORD_VERSION_SIZE
+}
+
+// txnCommandKind is the kind of a command finishing a transaction.
+type txnCommandKind int
+
+const (
+	// verify the version of a record read in the transaction
+	txnVerify txnCommandKind = iota
+	// mark the monitor record, so the server rolls the transaction forward
+	txnMarkRollForward
+	// commit the write of a record
+	txnRollForward
+	// undo the write of a record
+	txnRollBack
+	// remove the monitor record
+	txnClose
+)
+
+// txnRecordCommand runs a step of the commit or abort of a transaction on a record.
+type txnRecordCommand struct {
+	singleCommand
+
+	policy  *WritePolicy
+	txn     *Txn
+	kind    txnCommandKind
+	version uint64
+}
+
+func newTxnRecordCommand(cluster *Cluster, policy *WritePolicy, txn *Txn, key *Key, kind txnCommandKind) *txnRecordCommand {
+	// the command is not part of the transaction itself
+	p := *policy
+	p.Txn = nil
+
+	return &txnRecordCommand{
+		singleCommand: *newSingleCommand(cluster, key),
+		policy:        &p,
+		txn:           txn,
+		kind:          kind,
+	}
+}
+
+func (cmd *txnRecordCommand) getPolicy(ifc command) Policy {
+	return cmd.policy
+}
+
+func (cmd *txnRecordCommand) writeBuffer(ifc command) error {
+	return cmd.setTxnRecord(cmd.txn, cmd.key, cmd.kind, cmd.version)
+}
+
+func (cmd *baseCommand) setTxnRecord(txn *Txn, key *Key, kind txnCommandKind, version uint64) error {
+	cmd.begin()
+	fieldCount := cmd.estimateKeySize(key, false)
+
+	var readAttr, writeAttr, infoAttr, txnAttr int
+	var bin *Bin
+	switch kind {
+	case txnVerify:
+		readAttr = _INFO1_READ | _INFO1_NOBINDATA
+		infoAttr = _INFO3_SC_READ_TYPE
+		txnAttr = _INFO4_MRT_VERIFY_READ
+		cmd.dataOffset += _RECORD_VERSION_SIZE + int(_FIELD_HEADER_SIZE)
+		fieldCount++
+	case txnMarkRollForward:
+		writeAttr = _INFO2_WRITE
+		bin = NewBin(txnMonitorForwardBin, 1)
+		cmd.estimateOperationSizeForBin(bin)
+	case txnRollForward, txnRollBack:
+		writeAttr = _INFO2_WRITE | _INFO2_DURABLE_DELETE
+		txnAttr = _INFO4_MRT_ROLL_FORWARD
+		if kind == txnRollBack {
+			txnAttr = _INFO4_MRT_ROLL_BACK
+		}
+		cmd.dataOffset += _MRT_ID_SIZE + int(_FIELD_HEADER_SIZE)
+		fieldCount++
+	case txnClose:
+		writeAttr = _INFO2_WRITE | _INFO2_DELETE | _INFO2_DURABLE_DELETE
+	}
+
+	if err := cmd.sizeBuffer(); err != nil {
+		return err
+	}
+
+	cmd.dataBuffer[8] = _MSG_REMAINING_HEADER_SIZE
+	cmd.dataBuffer[9] = byte(readAttr)
+	cmd.dataBuffer[10] = byte(writeAttr)
+	cmd.dataBuffer[11] = byte(infoAttr)
+	cmd.dataBuffer[12] = byte(txnAttr)
+	for i := 13; i < 26; i++ {
+		cmd.dataBuffer[i] = 0
+	}
+	opCount := 0
+	if bin != nil {
+		opCount = 1
+	}
+	Buffer.Int16ToBytes(int16(fieldCount), cmd.dataBuffer, 26)
+	Buffer.Int16ToBytes(int16(opCount), cmd.dataBuffer, 28)
+	cmd.dataOffset = int(_MSG_TOTAL_HEADER_SIZE)
+
+	cmd.writeKey(key, false)
+	switch kind {
+	case txnVerify:
+		cmd.writeRecordVersion(version)
+	case txnRollForward, txnRollBack:
+		cmd.writeTxnID(txn)
+	}
+	if bin != nil {
+		if err := cmd.writeOperationForBin(bin, WRITE); err != nil {
+			return err
+		}
+	}
+	cmd.end()
+	return nil
+}
+
+func (cmd *txnRecordCommand) parseResult(ifc command, conn *Connection) error {
+	// Read header.
+	if _, err := conn.Read(cmd.dataBuffer, int(_MSG_TOTAL_HEADER_SIZE)); err != nil {
+		return err
+	}
+
+	resultCode := ResultCode(cmd.dataBuffer[13] & 0xFF)
+	if err := cmd.emptySocket(conn); err != nil {
+		return err
+	}
+
+	switch {
+	case resultCode == OK:
+		return nil
+	case resultCode == KEY_NOT_FOUND_ERROR && cmd.kind != txnVerify:
+		// the record was already released
+		return nil
+	}
+	return NewAerospikeError(resultCode)
+}
+
+func (cmd *txnRecordCommand) Execute() error {
+	return cmd.execute(cmd)
+}
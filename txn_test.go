@@ -0,0 +1,151 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	. "github.com/THE108/aerospike-client-go/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Transaction Test", func() {
+
+	var txn *Txn
+	var key *Key
+
+	BeforeEach(func() {
+		txn = NewTxn()
+		key, _ = NewKey("test", "test", 1)
+	})
+
+	resultCode := func(err error) ResultCode {
+		Expect(err).To(HaveOccurred())
+		return err.(AerospikeError).ResultCode()
+	}
+
+	It("should send and parse transaction fields", func() {
+		version := uint64(0x01020304050607)
+		txn.reads[string(key.digest)] = txnRead{key: key, version: version}
+		txn.deadline = 42
+
+		policy := NewWritePolicy(0, 0)
+		policy.Txn = txn
+
+		cmd := newWriteCommand(nil, policy, key, []*Bin{NewBin("a", 1)}, WRITE)
+		Expect(cmd.setWrite(policy, WRITE, key, cmd.bins)).ToNot(HaveOccurred())
+
+		// namespace, set, digest, and the transaction id, version and deadline
+		Expect(int(cmd.dataBuffer[26])<<8 | int(cmd.dataBuffer[27])).To(Equal(6))
+
+		// reads don't send the deadline
+		Expect(cmd.setRead(&policy.BasePolicy, key, []string{"a"})).ToNot(HaveOccurred())
+		Expect(int(cmd.dataBuffer[26])<<8 | int(cmd.dataBuffer[27])).To(Equal(5))
+
+		// echo the transaction fields back as a response
+		c := &baseCommand{dataBuffer: make([]byte, 64)}
+		c.writeRecordVersion(version)
+		f := txnFields{txn: txn, deadline: 42}
+		c.writeTxn(f)
+
+		cmd.dataBuffer = c.dataBuffer
+		cmd.parseTxnFields(3)
+		Expect(*cmd.getRecordVersion()).To(Equal(version))
+		Expect(cmd.txnDeadline).To(Equal(int32(42)))
+	})
+
+	It("should track reads and writes", func() {
+		version := uint64(7)
+
+		read := newReadCommand(nil, NewPolicy(), key, nil)
+		read.recordVersion = &version
+		txn.onResult(read, nil)
+		v, exists := txn.readVersion(key)
+		Expect(exists).To(BeTrue())
+		Expect(v).To(Equal(version))
+
+		write := newWriteCommand(nil, NewWritePolicy(0, 0), key, nil, WRITE)
+		txn.onResult(write, NewAerospikeError(MRT_BLOCKED))
+		Expect(txn.writes).To(BeEmpty())
+
+		txn.onResult(write, NewAerospikeError(TIMEOUT))
+		_, exists = txn.readVersion(key)
+		Expect(exists).To(BeFalse())
+		Expect(txn.writes).To(HaveKey(string(key.digest)))
+	})
+
+	It("should keep the writes of a key reused with SetValue", func() {
+		var digests []string
+		for i := 1; i <= 3; i++ {
+			Expect(key.SetValue(i)).ToNot(HaveOccurred())
+			digests = append(digests, string(key.Digest()))
+
+			txn.onResult(newWriteCommand(nil, NewWritePolicy(0, 0), key, nil, WRITE), nil)
+		}
+
+		Expect(txn.writes).To(HaveLen(3))
+		for _, digest := range digests {
+			Expect(txn.writes).To(HaveKey(digest))
+			Expect(string(txn.writes[digest].Digest())).To(Equal(digest))
+		}
+	})
+
+	It("should only accept single record commands of one namespace", func() {
+		policy := NewPolicy()
+		policy.Txn = txn
+
+		Expect(txn.prepare(newExistsCommand(nil, policy, key))).ToNot(HaveOccurred())
+		Expect(txn.Namespace()).To(Equal("test"))
+
+		other, _ := NewKey("other", "test", 1)
+		Expect(resultCode(txn.prepare(newExistsCommand(nil, policy, other)))).To(Equal(PARAMETER_ERROR))
+
+		Expect(resultCode(txn.prepare(&scanCommand{}))).To(Equal(PARAMETER_ERROR))
+	})
+
+	It("should write the commands finishing transactions", func() {
+		cmd := &baseCommand{}
+		Expect(cmd.setTxnRecord(txn, key, txnVerify, 1)).ToNot(HaveOccurred())
+		Expect(int(cmd.dataBuffer[9])).To(Equal(_INFO1_READ | _INFO1_NOBINDATA))
+		Expect(int(cmd.dataBuffer[12])).To(Equal(_INFO4_MRT_VERIFY_READ))
+
+		Expect(cmd.setTxnRecord(txn, key, txnRollBack, 0)).ToNot(HaveOccurred())
+		Expect(int(cmd.dataBuffer[10])).To(Equal(_INFO2_WRITE | _INFO2_DURABLE_DELETE))
+		Expect(int(cmd.dataBuffer[12])).To(Equal(_INFO4_MRT_ROLL_BACK))
+
+		Expect(cmd.setTxnRecord(txn, key, txnClose, 0)).ToNot(HaveOccurred())
+		Expect(int(cmd.dataBuffer[10])).To(Equal(_INFO2_WRITE | _INFO2_DELETE | _INFO2_DURABLE_DELETE))
+		Expect(int(cmd.dataBuffer[12])).To(Equal(0))
+	})
+
+	It("should finish transactions only once", func() {
+		client := &Client{}
+
+		// nothing to verify or roll
+		Expect(client.Commit(NewWritePolicy(0, 0), txn)).ToNot(HaveOccurred())
+		Expect(txn.State()).To(Equal(TXN_COMMITTED))
+		Expect(client.Commit(NewWritePolicy(0, 0), txn)).ToNot(HaveOccurred())
+		Expect(resultCode(client.Abort(NewWritePolicy(0, 0), txn))).To(Equal(TXN_FAILED))
+
+		policy := NewPolicy()
+		policy.Txn = txn
+		Expect(resultCode(txn.prepare(newExistsCommand(nil, policy, key)))).To(Equal(TXN_FAILED))
+
+		txn = NewTxn()
+		Expect(client.Abort(NewWritePolicy(0, 0), txn)).ToNot(HaveOccurred())
+		Expect(resultCode(client.Commit(NewWritePolicy(0, 0), txn))).To(Equal(TXN_FAILED))
+	})
+
+})
@@ -79,3 +79,39 @@ func (q *AtomicQueue) Poll() (res interface{}) {
 	q.mutex.Unlock()
 	return res
 }
+
+// Resize changes the capacity of the queue, keeping its items in order.
+// If the queue holds more items than the new capacity, the newest items
+// are removed and returned, so the caller can release them.
+func (q *AtomicQueue) Resize(size int) (removed []interface{}) {
+	if size <= 0 {
+		panic("Queue size cannot be less than 1")
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	data := make([]interface{}, uint32(size))
+	count := 0
+	for q.wrapped || (q.tail != q.head) {
+		if q.tail+1 == q.size {
+			q.wrapped = false
+		}
+		q.tail = (q.tail + 1) % q.size
+
+		// items are stored from index 1, the same as offered to an empty queue
+		if count < size {
+			count++
+			data[count%size] = q.data[q.tail]
+		} else {
+			removed = append(removed, q.data[q.tail])
+		}
+	}
+
+	q.data = data
+	q.size = uint32(size)
+	q.tail = 0
+	q.head = uint32(count % size)
+	q.wrapped = count == size
+	return removed
+}
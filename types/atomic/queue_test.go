@@ -69,4 +69,24 @@ var _ = Describe("Atomic Queue", func() {
 		}
 	})
 
+	It("must keep the elements in order when resized", func() {
+		for i := 0; i < qcap; i++ {
+			q.Offer(&testStruct{i})
+		}
+
+		removed := q.Resize(4)
+		Expect(len(removed)).To(Equal(qcap - 4))
+		Expect(removed[0].(*testStruct).i).To(Equal(4))
+
+		// the queue is full at its new capacity
+		Expect(q.Offer(&testStruct{})).To(BeFalse())
+
+		Expect(q.Resize(8)).To(BeNil())
+		Expect(q.Offer(&testStruct{4})).To(BeTrue())
+		for i := 0; i < 5; i++ {
+			Expect(q.Poll().(*testStruct).i).To(Equal(i))
+		}
+		Expect(q.Poll()).To(BeNil())
+	})
+
 })
@@ -10,6 +10,12 @@ const (
 )
 
 // TTL converts an Expiration time from citrusleaf epoc to TTL in seconds.
+// Records which never expire have no expiration time, and a TTL of 0.
+// Records past their expiration time which were not removed yet have a
+// negative TTL.
 func TTL(secsFromCitrusLeafEpoc int) int {
+	if secsFromCitrusLeafEpoc == 0 {
+		return 0
+	}
 	return int(int64(CITRUSLEAF_EPOCH+secsFromCitrusLeafEpoc) - time.Now().Unix())
 }
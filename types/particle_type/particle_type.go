@@ -18,9 +18,9 @@ const (
 	// Server particle types. Unsupported types are commented out.
 	NULL    = 0
 	INTEGER = 1
-	// BIGNUM          = 2
-	STRING = 3
-	BLOB   = 4
+	FLOAT   = 2
+	STRING  = 3
+	BLOB    = 4
 	// TIMESTAMP       = 5
 	DIGEST = 6
 	// JBLOB  = 7
@@ -33,7 +33,7 @@ const (
 	// RTA_LIST        = 14
 	// RTA_DICT        = 15
 	// RTA_APPEND_DICT = 16
-	// RTA_APPEND_LIST = 17
+	BOOL = 17
 	// LUA_BLOB        = 18
 	MAP  = 19
 	LIST = 20
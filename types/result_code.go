@@ -21,6 +21,12 @@ import "fmt"
 type ResultCode int
 
 const (
+	// The checksum stored with a record does not match its bins.
+	RECORD_CHECKSUM_MISMATCH ResultCode = -10
+
+	// A multi-record transaction could not be committed, or was already committed or aborted.
+	TXN_FAILED ResultCode = -9
+
 	// There were no connections available to the node in the pool, and the pool was limited
 	NO_AVAILABLE_CONNECTIONS_TO_NODE ResultCode = -8
 
@@ -117,6 +123,10 @@ const (
 	// Operation not allowed at this time.
 	FAIL_FORBIDDEN ResultCode = 22
 
+	// The command was not applied because the record did not match
+	// the filter expression of the policy.
+	FILTERED_OUT ResultCode = 27
+
 	// There are no more records left for query.
 	QUERY_END ResultCode = 50
 
@@ -150,6 +160,9 @@ const (
 	// Security credential is invalid.
 	INVALID_CREDENTIAL ResultCode = 65
 
+	// Login session expired.
+	EXPIRED_SESSION ResultCode = 66
+
 	// Role name is invalid.
 	INVALID_ROLE ResultCode = 70
 
@@ -168,6 +181,15 @@ const (
 	// A user defined function returned an error code.
 	UDF_BAD_RESPONSE ResultCode = 100
 
+	// The record is locked by another multi-record transaction.
+	MRT_BLOCKED ResultCode = 120
+
+	// The version of a record read in a multi-record transaction has changed.
+	MRT_VERSION_MISMATCH ResultCode = 121
+
+	// The deadline of a multi-record transaction has passed.
+	MRT_EXPIRED ResultCode = 122
+
 	// The requested item in a large collection was not found.
 	LARGE_ITEM_NOT_FOUND ResultCode = 125
 
@@ -238,6 +260,12 @@ func KeepConnection(err error) bool {
 // Return result code as a string.
 func ResultCodeToString(resultCode ResultCode) string {
 	switch ResultCode(resultCode) {
+	case RECORD_CHECKSUM_MISMATCH:
+		return "Record checksum mismatch"
+
+	case TXN_FAILED:
+		return "Multi-record transaction failed"
+
 	case NO_AVAILABLE_CONNECTIONS_TO_NODE:
 		return "No available connections to the node. Connection Pool was empty, and limited to certain number of connections."
 
@@ -331,6 +359,9 @@ func ResultCodeToString(resultCode ResultCode) string {
 	case FAIL_FORBIDDEN:
 		return "Operation not allowed at this time"
 
+	case FILTERED_OUT:
+		return "Command filtered out by the filter expression"
+
 	case QUERY_END:
 		return "Query end"
 
@@ -370,6 +401,9 @@ func ResultCodeToString(resultCode ResultCode) string {
 	case INVALID_CREDENTIAL:
 		return "Invalid credential"
 
+	case EXPIRED_SESSION:
+		return "Login session expired"
+
 	case INVALID_ROLE:
 		return "Invalid role"
 
@@ -388,6 +422,15 @@ func ResultCodeToString(resultCode ResultCode) string {
 	case UDF_BAD_RESPONSE:
 		return "UDF returned error"
 
+	case MRT_BLOCKED:
+		return "Record is locked by another multi-record transaction"
+
+	case MRT_VERSION_MISMATCH:
+		return "Record version changed during the multi-record transaction"
+
+	case MRT_EXPIRED:
+		return "Multi-record transaction deadline reached"
+
 	case LARGE_ITEM_NOT_FOUND:
 		return "Large collection item not found"
 
@@ -0,0 +1,220 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+
+	. "github.com/THE108/aerospike-client-go/types"
+)
+
+// ExecuteObject works the same as Execute, and decodes the value returned by
+// the UDF into result, which must be a non-nil pointer. See DecodeUDFResult
+// for the supported types.
+// If the policy is nil, the default relevant policy will be used.
+func (clnt *Client) ExecuteObject(policy *WritePolicy, key *Key, result interface{}, packageName string, functionName string, args ...Value) error {
+	rv := reflect.ValueOf(result)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return NewAerospikeError(PARAMETER_ERROR, "Result must be a non-nil pointer")
+	}
+
+	value, err := clnt.Execute(policy, key, packageName, functionName, args...)
+	if err != nil {
+		return err
+	}
+	return DecodeUDFResult(value, result)
+}
+
+// DecodeUDFResult decodes a value returned by a UDF, e.g. by Execute or
+// BatchUDF, into result, which must be a non-nil pointer.
+//
+// Lua lists are decoded into slices and arrays, and Lua maps into maps and
+// structs, whose fields are matched to the map keys by their `as` tag or
+// their name. Numbers are decoded into any numeric type which holds them
+// without loss, and nested values are decoded recursively. Values decode into
+// interfaces as they are, and nil values leave the result at its zero value.
+// A BIN_TYPE_ERROR is returned if the value does not fit the result.
+func DecodeUDFResult(value interface{}, result interface{}) error {
+	rv := reflect.ValueOf(result)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return NewAerospikeError(PARAMETER_ERROR, "Result must be a non-nil pointer")
+	}
+	return decodeUDFValue(rv.Elem(), value)
+}
+
+func udfTypeError(value interface{}, t reflect.Type) error {
+	return NewAerospikeError(BIN_TYPE_ERROR, fmt.Sprintf("UDF result of type %T can not be decoded into %s", value, t))
+}
+
+func decodeUDFValue(f reflect.Value, value interface{}) error {
+	if value == nil {
+		f.Set(reflect.Zero(f.Type()))
+		return nil
+	}
+
+	switch f.Kind() {
+	case reflect.Interface:
+		rv := reflect.ValueOf(value)
+		if !rv.Type().AssignableTo(f.Type()) {
+			return udfTypeError(value, f.Type())
+		}
+		f.Set(rv)
+		return nil
+
+	case reflect.Ptr:
+		if f.IsNil() {
+			f.Set(reflect.New(f.Type().Elem()))
+		}
+		return decodeUDFValue(f.Elem(), value)
+
+	case reflect.Bool:
+		// booleans are returned as integers by older servers
+		switch v := value.(type) {
+		case bool:
+			f.SetBool(v)
+			return nil
+		case int:
+			if v == 0 || v == 1 {
+				f.SetBool(v == 1)
+				return nil
+			}
+		}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n, ok := udfInt64(value); ok && !f.OverflowInt(n) {
+			f.SetInt(n)
+			return nil
+		}
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if v, ok := value.(uint64); ok && !f.OverflowUint(v) {
+			f.SetUint(v)
+			return nil
+		}
+		if n, ok := udfInt64(value); ok && n >= 0 && !f.OverflowUint(uint64(n)) {
+			f.SetUint(uint64(n))
+			return nil
+		}
+
+	case reflect.Float32, reflect.Float64:
+		switch v := value.(type) {
+		case float64:
+			f.SetFloat(v)
+			return nil
+		case float32:
+			f.SetFloat(float64(v))
+			return nil
+		}
+		if n, ok := udfInt64(value); ok {
+			f.SetFloat(float64(n))
+			return nil
+		}
+
+	case reflect.String:
+		if s, ok := value.(string); ok {
+			f.SetString(s)
+			return nil
+		}
+
+	case reflect.Slice:
+		if b, ok := value.([]byte); ok && f.Type().Elem().Kind() == reflect.Uint8 {
+			f.SetBytes(append([]byte(nil), b...))
+			return nil
+		}
+		if list, ok := value.([]interface{}); ok {
+			f.Set(reflect.MakeSlice(f.Type(), len(list), len(list)))
+			for i := range list {
+				if err := decodeUDFValue(f.Index(i), list[i]); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+	case reflect.Array:
+		if list, ok := value.([]interface{}); ok && len(list) <= f.Len() {
+			for i := range list {
+				if err := decodeUDFValue(f.Index(i), list[i]); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+	case reflect.Map:
+		if m, ok := value.(map[interface{}]interface{}); ok {
+			res := reflect.MakeMap(f.Type())
+			for k, v := range m {
+				key := reflect.New(f.Type().Key()).Elem()
+				if err := decodeUDFValue(key, k); err != nil {
+					return err
+				}
+				elem := reflect.New(f.Type().Elem()).Elem()
+				if err := decodeUDFValue(elem, v); err != nil {
+					return err
+				}
+				res.SetMapIndex(key, elem)
+			}
+			f.Set(res)
+			return nil
+		}
+
+	case reflect.Struct:
+		if m, ok := value.(map[interface{}]interface{}); ok {
+			t := f.Type()
+			for i := 0; i < t.NumField(); i++ {
+				// skip unexported fields
+				if t.Field(i).PkgPath != "" {
+					continue
+				}
+
+				alias := fieldAlias(t.Field(i))
+				if alias == "" {
+					continue
+				}
+				if v, exists := m[alias]; exists {
+					if err := decodeUDFValue(f.Field(i), v); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		}
+	}
+
+	return udfTypeError(value, f.Type())
+}
+
+// udfInt64 returns the integer value of UDF numbers, including floats
+// without a fractional part.
+func udfInt64(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint64:
+		if v <= math.MaxInt64 {
+			return int64(v), true
+		}
+	case float64:
+		if v == math.Trunc(v) && v >= math.MinInt64 && v < math.MaxInt64 {
+			return int64(v), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,108 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	. "github.com/THE108/aerospike-client-go/types"
+	ParticleType "github.com/THE108/aerospike-client-go/types/particle_type"
+	Buffer "github.com/THE108/aerospike-client-go/utils/buffer"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("UDF Result Decoding Test", func() {
+
+	resultCode := func(err error) ResultCode {
+		Expect(err).To(HaveOccurred())
+		return err.(AerospikeError).ResultCode()
+	}
+
+	It("should decode float and bool particles", func() {
+		buf := make([]byte, 8)
+		Buffer.Float64ToBytes(1.5, buf, 0)
+		Expect(bytesToParticle(ParticleType.FLOAT, buf, 0, 8)).To(Equal(1.5))
+		Expect(bytesToParticle(ParticleType.BOOL, []byte{1}, 0, 1)).To(Equal(true))
+	})
+
+	It("should unpack str8 and bin8 values and skip extensions", func() {
+		// [ordered-flag ext, "ab" as str8, 0x01 as bin8]
+		buf := []byte{0x93, 0xd4, 0, 1, 0xd9, 3, ParticleType.STRING, 'a', 'b', 0xc4, 2, ParticleType.BLOB, 1}
+		list, err := newUnpacker(buf, 0, len(buf)).UnpackList()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(list).To(Equal([]interface{}{"ab", []byte{1}}))
+
+		// {ext: nil, 1: 2}
+		buf = []byte{0x82, 0xc7, 1, 0, 1, 0xc0, 1, 2}
+		m, err := newUnpacker(buf, 0, len(buf)).UnpackMap()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(m).To(Equal(map[interface{}]interface{}{1: 2}))
+	})
+
+	It("should decode nested lists and maps into Go types", func() {
+		type item struct {
+			Name  string `as:"name"`
+			Price float64
+			Tags  []string `as:"tags"`
+		}
+
+		value := map[interface{}]interface{}{
+			"total": 3,
+			"items": []interface{}{
+				map[interface{}]interface{}{"name": "a", "Price": 1.5, "tags": []interface{}{"x"}},
+				map[interface{}]interface{}{"name": "b", "Price": 2},
+			},
+			"counts": map[interface{}]interface{}{"a": 1, "b": 2},
+		}
+
+		var res struct {
+			Total  int64           `as:"total"`
+			Items  []*item         `as:"items"`
+			Counts map[string]uint `as:"counts"`
+		}
+		Expect(DecodeUDFResult(value, &res)).ToNot(HaveOccurred())
+		Expect(res.Total).To(Equal(int64(3)))
+		Expect(res.Items).To(Equal([]*item{{Name: "a", Price: 1.5, Tags: []string{"x"}}, {Name: "b", Price: 2}}))
+		Expect(res.Counts).To(Equal(map[string]uint{"a": 1, "b": 2}))
+
+		var list []interface{}
+		Expect(DecodeUDFResult([]interface{}{1, "a"}, &list)).ToNot(HaveOccurred())
+		Expect(list).To(Equal([]interface{}{1, "a"}))
+
+		var n int
+		Expect(DecodeUDFResult(4.0, &n)).ToNot(HaveOccurred())
+		Expect(n).To(Equal(4))
+
+		b := true
+		Expect(DecodeUDFResult(nil, &b)).ToNot(HaveOccurred())
+		Expect(b).To(BeFalse())
+	})
+
+	It("should reject values which do not fit the result", func() {
+		var n int8
+		Expect(resultCode(DecodeUDFResult(300, &n))).To(Equal(BIN_TYPE_ERROR))
+		Expect(resultCode(DecodeUDFResult(1.5, &n))).To(Equal(BIN_TYPE_ERROR))
+
+		var u uint
+		Expect(resultCode(DecodeUDFResult(-1, &u))).To(Equal(BIN_TYPE_ERROR))
+
+		var m map[string]int
+		Expect(resultCode(DecodeUDFResult(map[interface{}]interface{}{1: 1}, &m))).To(Equal(BIN_TYPE_ERROR))
+
+		var s string
+		Expect(resultCode(DecodeUDFResult("a", s))).To(Equal(PARAMETER_ERROR))
+	})
+
+})
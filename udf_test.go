@@ -106,6 +106,22 @@ var _ = Describe("UDF/Query tests", func() {
 		Expect(len(udfList)).To(BeNumerically(">", 0))
 	})
 
+	It("must retrieve the content of a udf from the server", func() {
+		regTask, err := client.RegisterUDF(wpolicy, []byte(udfBody), "udf1.lua", LUA)
+		Expect(err).ToNot(HaveOccurred())
+
+		// wait until UDF is created
+		err = <-regTask.OnComplete()
+		Expect(err).ToNot(HaveOccurred())
+
+		content, err := client.GetUDF(nil, "udf1.lua")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(content)).To(Equal(udfBody))
+
+		_, err = client.GetUDF(nil, "udfNotExisting.lua")
+		Expect(err).To(HaveOccurred())
+	})
+
 	It("must drop a udf on the server", func() {
 		regTask, err := client.RegisterUDF(wpolicy, []byte(udfBody), "udfToBeDropped.lua", LUA)
 		Expect(err).ToNot(HaveOccurred())
@@ -22,6 +22,10 @@ import (
 	Buffer "github.com/THE108/aerospike-client-go/utils/buffer"
 )
 
+// msgpackExt is returned for msgpack extension values. The server uses them
+// for metadata of lists and maps, like their order, which are skipped.
+type msgpackExt struct{}
+
 type unpacker struct {
 	buffer []byte
 	offset int
@@ -68,6 +72,9 @@ func (upckr *unpacker) unpackList(count int) ([]interface{}, error) {
 		if err != nil {
 			return nil, err
 		}
+		if _, ok := obj.(msgpackExt); ok {
+			continue
+		}
 		out = append(out, obj)
 	}
 	return out, nil
@@ -108,6 +115,9 @@ func (upckr *unpacker) unpackMap(count int) (map[interface{}]interface{}, error)
 		if err != nil {
 			return nil, err
 		}
+		if _, ok := key.(msgpackExt); ok {
+			continue
+		}
 		out[key] = val
 	}
 	return out, nil
@@ -124,6 +134,15 @@ func (upckr *unpacker) unpackBlob(count int) (interface{}, error) {
 		val = string(upckr.buffer[upckr.offset : upckr.offset+count])
 
 	case ParticleType.BLOB:
+		v, ok, err := decodeCustomValue(upckr.buffer[upckr.offset : upckr.offset+count])
+		if ok {
+			if err != nil {
+				return nil, err
+			}
+			val = v
+			break
+		}
+
 		b := make([]byte, count)
 		copy(b, upckr.buffer[upckr.offset:upckr.offset+count])
 		val = b
@@ -207,16 +226,38 @@ func (upckr *unpacker) unpackObject() (interface{}, error) {
 		}
 		return int64(val), nil
 
-	case 0xda:
+	case 0xd9, 0xc4:
+		count := int(upckr.buffer[upckr.offset])
+		upckr.offset++
+		return upckr.unpackBlob(count)
+
+	case 0xda, 0xc5:
 		count := int(Buffer.BytesToUint16(upckr.buffer, upckr.offset))
 		upckr.offset += 2
 		return upckr.unpackBlob(count)
 
-	case 0xdb:
+	case 0xdb, 0xc6:
 		count := int(Buffer.BytesToUint32(upckr.buffer, upckr.offset))
 		upckr.offset += 4
 		return upckr.unpackBlob(count)
 
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		// fixed size extensions: a type byte and 1 to 16 bytes of data
+		upckr.offset += 1 + 1<<(theType-0xd4)
+		return msgpackExt{}, nil
+
+	case 0xc7:
+		upckr.offset += 2 + int(upckr.buffer[upckr.offset])
+		return msgpackExt{}, nil
+
+	case 0xc8:
+		upckr.offset += 3 + int(Buffer.BytesToUint16(upckr.buffer, upckr.offset))
+		return msgpackExt{}, nil
+
+	case 0xc9:
+		upckr.offset += 5 + int(Buffer.BytesToUint32(upckr.buffer, upckr.offset))
+		return msgpackExt{}, nil
+
 	case 0xdc:
 		count := int(Buffer.BytesToUint16(upckr.buffer, upckr.offset))
 		upckr.offset += 2
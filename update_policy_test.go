@@ -0,0 +1,100 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike_test
+
+import (
+	"sync"
+
+	. "github.com/THE108/aerospike-client-go"
+	"github.com/THE108/aerospike-client-go/aerotest"
+	. "github.com/THE108/aerospike-client-go/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Client policy updates", func() {
+
+	var srv *aerotest.Server
+	var client *Client
+	var policy *ClientPolicy
+	var key *Key
+
+	BeforeEach(func() {
+		var err error
+		policy = NewClientPolicy()
+		policy.ConnectionQueueSize = 8
+		client, srv, err = aerotest.NewClientAndServerWithPolicy(policy, "test")
+		Expect(err).ToNot(HaveOccurred())
+
+		key, err = NewKey("test", "set", 1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(client.Put(nil, key, BinMap{"a": 1})).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		client.Close()
+		srv.Close()
+	})
+
+	It("must shrink the connection pools", func() {
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 20; j++ {
+					client.Get(nil, key)
+				}
+			}()
+		}
+		wg.Wait()
+
+		update := policy.Clone()
+		update.ConnectionQueueSize = 2
+		Expect(client.UpdatePolicy(update)).ToNot(HaveOccurred())
+		Expect(client.GetConnectionCount()).To(BeNumerically("<=", 2))
+
+		_, err := client.Get(nil, key)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("must enable command observers", func() {
+		var events []*CommandEvent
+		update := policy.Clone()
+		update.CommandObserver = func(event *CommandEvent) {
+			events = append(events, event)
+		}
+		Expect(client.UpdatePolicy(update)).ToNot(HaveOccurred())
+
+		_, err := client.Get(nil, key)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(len(events)).To(Equal(1))
+
+		Expect(client.UpdatePolicy(policy)).ToNot(HaveOccurred())
+		_, err = client.Get(nil, key)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(len(events)).To(Equal(1))
+	})
+
+	It("must reject invalid settings", func() {
+		update := policy.Clone()
+		update.ConnectionQueueSize = 0
+		err := client.UpdatePolicy(update)
+		Expect(err).To(HaveOccurred())
+		Expect(err.(AerospikeError).ResultCode()).To(Equal(PARAMETER_ERROR))
+	})
+
+})
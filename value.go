@@ -107,6 +107,11 @@ func NewValue(v interface{}) Value {
 		return NewBlobValue(val)
 	}
 
+	// check for user registered types
+	if cv := newCustomValue(v); cv != nil {
+		return cv
+	}
+
 	// check for array and map
 	rv := reflect.ValueOf(v)
 	switch rv.Kind() {
@@ -579,7 +584,20 @@ func bytesToParticle(ptype int, buf []byte, offset int, length int) (interface{}
 	case ParticleType.STRING:
 		return string(buf[offset : offset+length]), nil
 
+	case ParticleType.FLOAT:
+		return Buffer.BytesToFloat64(buf, offset), nil
+
+	case ParticleType.BOOL:
+		return buf[offset] != 0, nil
+
 	case ParticleType.BLOB:
+		if v, ok, err := decodeCustomValue(buf[offset : offset+length]); ok {
+			return v, err
+		}
+		if v, ok, err := decodeCompressedValue(buf[offset : offset+length]); ok {
+			return v, err
+		}
+
 		newObj := make([]byte, length)
 		copy(newObj, buf[offset:offset+length])
 		return newObj, nil
@@ -0,0 +1,329 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike
+
+import (
+	"sync"
+	"time"
+
+	. "github.com/THE108/aerospike-client-go/types"
+	Buffer "github.com/THE108/aerospike-client-go/utils/buffer"
+)
+
+// WriteBufferPolicy encapsulates parameters of a WriteBuffer.
+type WriteBufferPolicy struct {
+	// WritePolicy is used for the buffered writes. If nil, the default
+	// relevant write policy of the client is used.
+	WritePolicy *WritePolicy
+
+	// FlushInterval is the longest time a write is buffered before it is sent.
+	FlushInterval time.Duration //= 1 millisecond
+
+	// MaxBatchSize is the number of writes to a node which are sent at once
+	// without waiting for FlushInterval.
+	MaxBatchSize int //= 128
+
+	// ErrorHandler, if set, is called with each failed write, from the
+	// goroutine sending it. Otherwise, errors are returned by Flush and Close.
+	ErrorHandler func(key *Key, err error)
+}
+
+// NewWriteBufferPolicy generates a new WriteBufferPolicy instance with default values.
+func NewWriteBufferPolicy() *WriteBufferPolicy {
+	return &WriteBufferPolicy{
+		FlushInterval: time.Millisecond,
+		MaxBatchSize:  128,
+	}
+}
+
+// WriteBuffer coalesces individual writes into batches per node, for
+// workloads with many small writes like telemetry. The writes of a batch are
+// pipelined on a single connection: they are sent with one system call, and
+// their responses are read afterwards, saving a round trip per write.
+//
+// Put returns once the write is buffered; its outcome is only known after
+// Flush, or through the ErrorHandler of the policy. Writes which fail after
+// they were sent are not retried, since they may have been applied. Writes
+// of a batch which could not be sent are executed one by one.
+// CommandObserver and the slow command log do not see pipelined writes.
+// WriteBuffer is safe for concurrent use.
+type WriteBuffer struct {
+	client *Client
+	policy WriteBufferPolicy
+
+	mutex   sync.Mutex
+	batches map[*Node]*writeBatch
+	errs    []error
+	closed  bool
+
+	// sends in progress
+	wg sync.WaitGroup
+}
+
+// writeBatch is the buffered writes to a node.
+type writeBatch struct {
+	cmds  []*writeCommand
+	timer *time.Timer
+}
+
+// NewWriteBuffer returns a buffer for writes, using the policy if it is not nil.
+// Commands in a transaction can not be buffered.
+func (clnt *Client) NewWriteBuffer(policy *WriteBufferPolicy) (*WriteBuffer, error) {
+	if policy == nil {
+		policy = NewWriteBufferPolicy()
+	}
+	if policy.WritePolicy != nil && policy.WritePolicy.Txn != nil {
+		return nil, NewAerospikeError(PARAMETER_ERROR, "Writes in a transaction can not be buffered")
+	}
+	if policy.FlushInterval < 0 || policy.MaxBatchSize <= 0 {
+		return nil, NewAerospikeError(PARAMETER_ERROR, "Invalid write buffer flush interval or batch size")
+	}
+
+	return &WriteBuffer{
+		client:  clnt,
+		policy:  *policy,
+		batches: map[*Node]*writeBatch{},
+	}, nil
+}
+
+// Put buffers a write of the bins to the record.
+// The bin map must not be modified until the write has been flushed.
+func (wb *WriteBuffer) Put(key *Key, binMap BinMap) error {
+	bins := make([]*Bin, 0, len(binMap))
+	for name, value := range binMap {
+		bins = append(bins, NewBin(name, value))
+	}
+	return wb.PutBins(key, bins...)
+}
+
+// PutBins buffers a write of the bins to the record.
+// The bins must not be modified until the write has been flushed. The key
+// is copied, and can be reused right away with Key.SetValue.
+func (wb *WriteBuffer) PutBins(key *Key, bins ...*Bin) error {
+	clnt := wb.client
+	policy := clnt.getUsableWritePolicyFor(wb.policy.WritePolicy, key.namespace, key.setName)
+	cmd := newWriteCommand(clnt.cluster, policy, key.clone(), bins, WRITE)
+
+	node, err := clnt.cluster.GetNode(cmd.partition)
+	if err != nil {
+		return err
+	}
+
+	wb.mutex.Lock()
+	defer wb.mutex.Unlock()
+
+	if wb.closed {
+		return NewAerospikeError(PARAMETER_ERROR, "Write buffer is closed")
+	}
+
+	batch := wb.batches[node]
+	if batch == nil {
+		batch = &writeBatch{}
+		wb.batches[node] = batch
+		batch.timer = time.AfterFunc(wb.policy.FlushInterval, func() {
+			wb.mutex.Lock()
+			defer wb.mutex.Unlock()
+			if wb.batches[node] == batch {
+				wb.sendLocked(node)
+			}
+		})
+	}
+
+	batch.cmds = append(batch.cmds, cmd)
+	if len(batch.cmds) >= wb.policy.MaxBatchSize {
+		wb.sendLocked(node)
+	}
+	return nil
+}
+
+// sendLocked sends the buffered writes to the node in the background.
+// The mutex must be held.
+func (wb *WriteBuffer) sendLocked(node *Node) {
+	batch := wb.batches[node]
+	delete(wb.batches, node)
+	batch.timer.Stop()
+
+	wb.wg.Add(1)
+	go func() {
+		defer wb.wg.Done()
+		wb.send(node, batch.cmds)
+	}()
+}
+
+func (wb *WriteBuffer) send(node *Node, cmds []*writeCommand) {
+	var errs []error
+	for i, err := range wb.client.cluster.pipelineWrites(node, cmds) {
+		if err == nil {
+			continue
+		}
+
+		if wb.policy.ErrorHandler != nil {
+			wb.policy.ErrorHandler(cmds[i].key, err)
+		} else {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		wb.mutex.Lock()
+		wb.errs = append(wb.errs, errs...)
+		wb.mutex.Unlock()
+	}
+}
+
+// Flush sends the buffered writes, and waits until all writes sent so far
+// have completed. It returns the errors of the writes which failed since the
+// last Flush, unless the policy has an ErrorHandler.
+func (wb *WriteBuffer) Flush() error {
+	wb.mutex.Lock()
+	for node := range wb.batches {
+		wb.sendLocked(node)
+	}
+	wb.mutex.Unlock()
+
+	wb.wg.Wait()
+
+	wb.mutex.Lock()
+	errs := wb.errs
+	wb.errs = nil
+	wb.mutex.Unlock()
+
+	return mergeErrors(errs)
+}
+
+// Close flushes the buffer. Writes can not be buffered anymore afterwards.
+func (wb *WriteBuffer) Close() error {
+	wb.mutex.Lock()
+	wb.closed = true
+	wb.mutex.Unlock()
+
+	return wb.Flush()
+}
+
+// pipelineWrites sends the write commands to the node on a single connection,
+// and then reads their responses. The error of each command is returned.
+// If the commands could not be sent, they are executed one by one instead;
+// commands are not retried once they were sent.
+func (clstr *Cluster) pipelineWrites(node *Node, cmds []*writeCommand) []error {
+	errs := make([]error, len(cmds))
+	if len(cmds) == 1 || !node.IsActive() {
+		return executeWrites(cmds, errs)
+	}
+
+	// the batch times out when the longest timeout of its commands elapses
+	var timeout time.Duration
+	for _, cmd := range cmds {
+		t := cmd.policy.timeout()
+		if t <= 0 {
+			timeout = 0
+			break
+		}
+		if t > timeout {
+			timeout = t
+		}
+	}
+
+	var conn *Connection
+	var err error
+	if timeout > 0 {
+		conn, err = node.getConnectionWithDeadline(time.Now().Add(timeout))
+	} else {
+		conn, err = node.GetConnection(0)
+	}
+	if err != nil {
+		return executeWrites(cmds, errs)
+	}
+
+	node.pendingCommands.AddAndGet(len(cmds))
+	defer node.pendingCommands.AddAndGet(-len(cmds))
+
+	var buf []byte
+	for _, cmd := range cmds {
+		cmd.node = node
+		cmd.dataBuffer = bufPool.Get()
+		if err := cmd.writeBuffer(cmd); err != nil {
+			node.PutConnection(conn)
+			releaseWriteBuffers(cmds)
+			return executeWrites(cmds, errs)
+		}
+		Buffer.Int32ToBytes(serverTimeout(cmd.policy.timeout()), cmd.dataBuffer, 22)
+		buf = append(buf, cmd.dataBuffer[:cmd.dataOffset]...)
+	}
+
+	start := time.Now()
+	if _, err := conn.Write(buf); err != nil {
+		// nothing was applied, unless the server read some of the commands
+		node.InvalidateConnection(conn)
+		node.DecreaseHealth()
+		for i := range errs {
+			errs[i] = err
+		}
+		releaseWriteBuffers(cmds)
+		clstr.invalidateWrites(cmds)
+		return errs
+	}
+
+	for i, cmd := range cmds {
+		err := cmd.parseResult(cmd, conn)
+		if err != nil && !KeepConnection(err) {
+			// the responses of the remaining commands are lost
+			node.InvalidateConnection(conn)
+			for j := i; j < len(cmds); j++ {
+				errs[j] = err
+			}
+			releaseWriteBuffers(cmds)
+			clstr.invalidateWrites(cmds)
+			return errs
+		}
+		errs[i] = err
+	}
+
+	node.RestoreHealth()
+	node.recordLatency(time.Since(start) / time.Duration(len(cmds)))
+	node.PutConnection(conn)
+
+	releaseWriteBuffers(cmds)
+	clstr.invalidateWrites(cmds)
+	return errs
+}
+
+// releaseWriteBuffers returns the buffers of the pipelined commands to the
+// pool. The buffers are cleared, so commands executed one by one afterwards
+// draw their own.
+func releaseWriteBuffers(cmds []*writeCommand) {
+	for _, cmd := range cmds {
+		if cmd.dataBuffer != nil {
+			bufPool.Put(cmd.dataBuffer)
+			cmd.dataBuffer = nil
+		}
+	}
+}
+
+// executeWrites executes the commands one by one, and sets their errors.
+func executeWrites(cmds []*writeCommand, errs []error) []error {
+	for i, cmd := range cmds {
+		errs[i] = cmd.Execute()
+	}
+	return errs
+}
+
+// invalidateWrites drops the records of the commands from the record cache
+// and the reads in flight, as command execution does for single writes.
+func (clstr *Cluster) invalidateWrites(cmds []*writeCommand) {
+	for _, cmd := range cmds {
+		clstr.invalidateCachedRecord(cmd.key)
+		clstr.reads.forget(cmd.key)
+	}
+}
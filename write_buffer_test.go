@@ -0,0 +1,133 @@
+// Copyright 2013-2015 Aerospike, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aerospike_test
+
+import (
+	"sync"
+	"time"
+
+	. "github.com/THE108/aerospike-client-go"
+	"github.com/THE108/aerospike-client-go/aerotest"
+	. "github.com/THE108/aerospike-client-go/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Write buffer", func() {
+
+	var srv *aerotest.Server
+	var client *Client
+
+	BeforeEach(func() {
+		var err error
+		client, srv, err = aerotest.NewClientAndServer("test")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		client.Close()
+		Expect(srv.Close()).ToNot(HaveOccurred())
+	})
+
+	It("must write all buffered records", func() {
+		policy := NewWriteBufferPolicy()
+		policy.MaxBatchSize = 50
+		wb, err := client.NewWriteBuffer(policy)
+		Expect(err).ToNot(HaveOccurred())
+
+		var wg sync.WaitGroup
+		for g := 0; g < 4; g++ {
+			wg.Add(1)
+			go func(g int) {
+				defer GinkgoRecover()
+				defer wg.Done()
+				for i := 0; i < 75; i++ {
+					key, _ := NewKey("test", "aerotest", g*1000+i)
+					Expect(wb.Put(key, BinMap{"i": i})).ToNot(HaveOccurred())
+				}
+			}(g)
+		}
+		wg.Wait()
+
+		Expect(wb.Flush()).ToNot(HaveOccurred())
+		Expect(srv.Len("test")).To(Equal(300))
+
+		rec, err := client.Get(nil, mustKey(3074))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rec.Bins).To(Equal(BinMap{"i": 74}))
+	})
+
+	It("must write the records of a key reused with SetValue", func() {
+		wb, err := client.NewWriteBuffer(nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		key := mustKey(1)
+		Expect(wb.Put(key, BinMap{"i": 1})).ToNot(HaveOccurred())
+		Expect(key.SetValue(2)).ToNot(HaveOccurred())
+		Expect(wb.Put(key, BinMap{"i": 2})).ToNot(HaveOccurred())
+		Expect(wb.Flush()).ToNot(HaveOccurred())
+
+		Expect(srv.Len("test")).To(Equal(2))
+		for i := 1; i <= 2; i++ {
+			rec, err := client.Get(nil, mustKey(i))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rec.Bins).To(Equal(BinMap{"i": i}))
+		}
+	})
+
+	It("must send writes after the flush interval", func() {
+		policy := NewWriteBufferPolicy()
+		policy.FlushInterval = 10 * time.Millisecond
+		wb, err := client.NewWriteBuffer(policy)
+		Expect(err).ToNot(HaveOccurred())
+		defer wb.Close()
+
+		Expect(wb.Put(mustKey(1), BinMap{"i": 1})).ToNot(HaveOccurred())
+		Expect(srv.Len("test")).To(Equal(0))
+
+		time.Sleep(100 * time.Millisecond)
+		Expect(srv.Len("test")).To(Equal(1))
+	})
+
+	It("must report the errors of the writes", func() {
+		Expect(client.Put(nil, mustKey(2), BinMap{"i": 0})).ToNot(HaveOccurred())
+
+		var mutex sync.Mutex
+		var failed []*Key
+
+		policy := NewWriteBufferPolicy()
+		policy.WritePolicy = NewWritePolicy(0, 0)
+		policy.WritePolicy.RecordExistsAction = CREATE_ONLY
+		policy.ErrorHandler = func(key *Key, err error) {
+			Expect(err.(AerospikeError).ResultCode()).To(Equal(KEY_EXISTS_ERROR))
+			mutex.Lock()
+			failed = append(failed, key)
+			mutex.Unlock()
+		}
+		wb, err := client.NewWriteBuffer(policy)
+		Expect(err).ToNot(HaveOccurred())
+
+		for i := 1; i <= 3; i++ {
+			Expect(wb.Put(mustKey(i), BinMap{"i": i})).ToNot(HaveOccurred())
+		}
+		Expect(wb.Close()).ToNot(HaveOccurred())
+		Expect(failed).To(Equal([]*Key{mustKey(2)}))
+		Expect(srv.Len("test")).To(Equal(3))
+
+		Expect(wb.Put(mustKey(4), BinMap{"i": 4})).To(HaveOccurred())
+	})
+
+})
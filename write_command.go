@@ -25,6 +25,10 @@ type writeCommand struct {
 	policy    *WritePolicy
 	bins      []*Bin
 	operation OperationType
+
+	// bins are checksummed and compressed on the first attempt,
+	// see Client.SetRecordChecksums and Client.SetCompression
+	prepared bool
 }
 
 func newWriteCommand(cluster *Cluster,
@@ -48,9 +52,39 @@ func (cmd *writeCommand) getPolicy(ifc command) Policy {
 }
 
 func (cmd *writeCommand) writeBuffer(ifc command) error {
+	if !cmd.prepared {
+		if err := cmd.prepareBins(); err != nil {
+			return err
+		}
+		cmd.prepared = true
+	}
 	return cmd.setWrite(cmd.policy, cmd.operation, cmd.key, cmd.bins)
 }
 
+func (cmd *writeCommand) prepareBins() error {
+	if err := cmd.cluster.checksumWrite(cmd.policy, cmd.key, cmd.operation); err != nil {
+		return err
+	}
+	if cmd.operation != WRITE {
+		return nil
+	}
+
+	bins := cmd.bins
+	if cmd.cluster.hasRecordChecksum(cmd.key) {
+		var err error
+		if bins, err = addRecordChecksum(cmd.key, bins); err != nil {
+			return err
+		}
+	}
+
+	bins, err := cmd.cluster.compressBins(cmd.key, bins)
+	if err != nil {
+		return err
+	}
+	cmd.bins = bins
+	return nil
+}
+
 func (cmd *writeCommand) parseResult(ifc command, conn *Connection) error {
 	// Read header.
 	if _, err := conn.Read(cmd.dataBuffer, int(_MSG_TOTAL_HEADER_SIZE)); err != nil {
@@ -63,3 +63,9 @@ func NewWritePolicy(generation, expiration int32) *WritePolicy {
 		SendKey:            false,
 	}
 }
+
+// Clone returns a copy of the policy. The Context and Txn are shared.
+func (p *WritePolicy) Clone() *WritePolicy {
+	res := *p
+	return &res
+}